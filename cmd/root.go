@@ -5,11 +5,33 @@ import (
 	"os"
 
 	"github.com/DGarbs51/lcmigrate/db"
+	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/data"
+	"github.com/DGarbs51/lcmigrate/internal/engine"
 	"github.com/DGarbs51/lcmigrate/internal/migrator"
+	"github.com/DGarbs51/lcmigrate/internal/ui"
 	"github.com/spf13/cobra"
 )
 
-var dryRun bool
+var (
+	dryRun           bool
+	schemas          []string
+	excludeSchemas   []string
+	refreshMatviews  bool
+	definer          string
+	sqlSecurity      string
+	partitioning     string
+	partitionWorkers int
+	enums            string
+	convertCharset   string
+	convertCollation string
+	repairMojibake   bool
+	rulesFile        string
+	mappingFile      string
+	includeTables    []string
+	excludeTables    []string
+	schemaOnlyTables []string
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "lcmigrate",
@@ -22,18 +44,7 @@ var analyzeCmd = &cobra.Command{
 	Short: "Analyze a database and display statistics",
 	Long:  `Connect to a MySQL or PostgreSQL database and display comprehensive statistics including table counts, sizes, schema details, and more.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		config := db.PromptConnectionDetails()
-
-		conn, err := db.Connect(config)
-		if err != nil {
-			fmt.Printf("Error connecting to database: %v\n", err)
-			os.Exit(1)
-		}
-		defer conn.Close()
-
-		fmt.Println("\n=== Database Analysis ===")
-
-		if err := db.Analyze(conn, config); err != nil {
+		if err := analyze(db.PromptConnectionDetails()); err != nil {
 			fmt.Printf("Error analyzing database: %v\n", err)
 			os.Exit(1)
 		}
@@ -43,24 +54,78 @@ var analyzeCmd = &cobra.Command{
 var migrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Migrate a database from source to destination",
-	Long: `Migrate a MySQL or PostgreSQL database from one server to another.
+	Long: `Migrate a MySQL, MariaDB or PostgreSQL database from one server to another,
+between MySQL and PostgreSQL, from MariaDB into MySQL or PostgreSQL, or from a
+SQLite database file into any of them.
 
 This command will:
   1. Prompt for source and destination database credentials
   2. Run pre-flight validation checks
   3. Migrate schema (tables, indexes, constraints)
   4. Transfer data in batches
-  5. Migrate views and sequences
+  5. Migrate views, materialized views and sequences
   6. Verify the migration
 
 Use --dry-run to see what would be migrated without making changes.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runMigrate(dryRun)
+		runMigrate(migrator.Options{
+			DryRun:                   dryRun,
+			Schemas:                  schemas,
+			ExcludeSchemas:           excludeSchemas,
+			RefreshMaterializedViews: refreshMatviews,
+			Definer:                  definer,
+			SQLSecurity:              sqlSecurity,
+			Partitioning:             partitioning,
+			PartitionWorkers:         partitionWorkers,
+			Enums:                    enums,
+			ConvertCharset:           convertCharset,
+			ConvertCollation:         convertCollation,
+			RepairMojibake:           repairMojibake,
+			Rules:                    rulesFile,
+			Mapping:                  mappingFile,
+			Include:                  includeTables,
+			Exclude:                  excludeTables,
+			SchemaOnlyTables:         schemaOnlyTables,
+		})
 	},
 }
 
-func runMigrate(dryRun bool) {
-	if err := migrator.Run(dryRun); err != nil {
+// analyze connects and prints statistics with the engine registered for the database
+func analyze(cfg db.Config) error {
+	e, err := engine.Get(cfg.Engine)
+	if err != nil {
+		return err
+	}
+	if e.Analyze == nil {
+		return fmt.Errorf("analyze doesn't support %s databases", e.Name)
+	}
+
+	result, err := e.Connect(config.DatabaseConfig{
+		Engine:   cfg.Engine,
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Database: cfg.Database,
+		User:     cfg.User,
+		Password: cfg.Password,
+	})
+	if err != nil {
+		ui.Error(err.Error())
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer result.DB.Close()
+
+	sslInfo := ""
+	if result.SSLMode != "" && result.SSLMode != "require" {
+		sslInfo = fmt.Sprintf(" (SSL: %s)", result.SSLMode)
+	}
+	ui.Success(fmt.Sprintf("Connected to %s @ %s:%s/%s%s", cfg.Engine, cfg.Host, cfg.Port, cfg.Database, sslInfo))
+
+	fmt.Println("\n=== Database Analysis ===")
+	return e.Analyze(result.DB, cfg.Database)
+}
+
+func runMigrate(opts migrator.Options) {
+	if err := migrator.Run(opts); err != nil {
 		fmt.Printf("Migration failed: %v\n", err)
 		os.Exit(1)
 	}
@@ -72,6 +137,22 @@ func init() {
 
 	// Add flags to migrate command
 	migrateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be migrated without making changes")
+	migrateCmd.Flags().StringSliceVar(&schemas, "schema", nil, "PostgreSQL schemas to migrate (default: all non-system schemas)")
+	migrateCmd.Flags().StringSliceVar(&excludeSchemas, "exclude-schema", nil, "PostgreSQL schemas to skip")
+	migrateCmd.Flags().BoolVar(&refreshMatviews, "refresh-matviews", true, "Refresh PostgreSQL materialized views after the data load (--refresh-matviews=false to leave them empty)")
+	migrateCmd.Flags().StringVar(&definer, "definer", "strip", "MySQL DEFINER for views, routines, triggers and events: strip, current-user, keep or user@host")
+	migrateCmd.Flags().StringVar(&sqlSecurity, "sql-security", "", "MySQL SQL SECURITY for views and routines: definer or invoker (default: keep the source's)")
+	migrateCmd.Flags().StringVar(&partitioning, "partitioning", "keep", "MySQL partitioned tables: keep or drop the partitioning on the destination")
+	migrateCmd.Flags().StringSliceVar(&includeTables, "include", nil, "Tables to migrate, as globs such as 'orders*' (default: all tables)")
+	migrateCmd.Flags().StringSliceVar(&excludeTables, "exclude", nil, "Tables to leave out, as globs such as 'telescope_*'")
+	migrateCmd.Flags().StringSliceVar(&schemaOnlyTables, "schema-only-tables", nil, "Tables to create without copying their rows, as globs such as 'sessions,cache*,jobs'")
+	migrateCmd.Flags().IntVar(&partitionWorkers, "partition-workers", data.DefaultPartitionWorkers, "Partitions of one MySQL table to copy at once")
+	migrateCmd.Flags().StringVar(&enums, "enums", "check", "MySQL ENUM columns on a PostgreSQL destination: check (varchar with a CHECK constraint) or type (an enum type per column)")
+	migrateCmd.Flags().StringVar(&convertCharset, "convert-charset", "", "Convert latin1 and utf8 MySQL tables and columns to this charset (utf8mb4)")
+	migrateCmd.Flags().StringVar(&convertCollation, "convert-collation", "", "Collation for --convert-charset (default utf8mb4_unicode_ci; binary collations become utf8mb4_bin)")
+	migrateCmd.Flags().BoolVar(&repairMojibake, "repair-mojibake", false, "Repair double-encoded UTF-8 found in converted columns while copying")
+	migrateCmd.Flags().StringVar(&rulesFile, "rules", "", "JSON file of rules rewriting tables, indexes, foreign keys and views before they're created")
+	migrateCmd.Flags().StringVar(&mappingFile, "mapping", "", "JSON file renaming tables and columns, or leaving columns out, on the destination")
 }
 
 func Execute() {
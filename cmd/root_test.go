@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"database/sql"
+	"errors"
 	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/DGarbs51/lcmigrate/db"
+	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/engine"
 )
 
 func TestRootCmd(t *testing.T) {
@@ -44,6 +51,86 @@ func TestAnalyzeCmd(t *testing.T) {
 	}
 }
 
+func TestAnalyze_UnsupportedEngine(t *testing.T) {
+	for _, engine := range []string{"unsupported", "sqlite"} {
+		if err := analyze(db.Config{Engine: engine}); err == nil {
+			t.Errorf("analyze() with engine %q expected error, got nil", engine)
+		}
+	}
+}
+
+// fakeEngine is registered for the analyze tests; connect and analyze stand in for its hooks
+var fakeEngine = struct {
+	connect func(cfg config.DatabaseConfig) (*engine.ConnectResult, error)
+	analyze func(db *sql.DB, database string) error
+}{}
+
+func init() {
+	engine.Register(&engine.Engine{
+		Name: "fake",
+		Connect: func(cfg config.DatabaseConfig) (*engine.ConnectResult, error) {
+			return fakeEngine.connect(cfg)
+		},
+		Analyze: func(db *sql.DB, database string) error {
+			return fakeEngine.analyze(db, database)
+		},
+	})
+}
+
+func TestAnalyze_ConnectsThroughEngine(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	mock.ExpectClose()
+
+	var connected config.DatabaseConfig
+	fakeEngine.connect = func(cfg config.DatabaseConfig) (*engine.ConnectResult, error) {
+		connected = cfg
+		return &engine.ConnectResult{DB: mockDB, SSLMode: "prefer"}, nil
+	}
+	var analyzed string
+	fakeEngine.analyze = func(conn *sql.DB, database string) error {
+		if conn != mockDB {
+			t.Error("Analyze() got a different connection than Connect() returned")
+		}
+		analyzed = database
+		return nil
+	}
+
+	cfg := db.Config{Engine: "fake", Host: "db.internal", Port: "3307", Database: "shop", User: "app", Password: "secret"}
+	if err := analyze(cfg); err != nil {
+		t.Fatalf("analyze() error = %v", err)
+	}
+
+	want := config.DatabaseConfig{Engine: "fake", Host: "db.internal", Port: "3307", Database: "shop", User: "app", Password: "secret"}
+	if connected != want {
+		t.Errorf("Connect() got %+v, want %+v", connected, want)
+	}
+	if analyzed != "shop" {
+		t.Errorf("Analyze() database = %q, want shop", analyzed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("connection wasn't closed: %v", err)
+	}
+}
+
+func TestAnalyze_ConnectError(t *testing.T) {
+	fakeEngine.connect = func(cfg config.DatabaseConfig) (*engine.ConnectResult, error) {
+		return nil, &engine.DatabaseNotExistsError{Database: cfg.Database}
+	}
+	fakeEngine.analyze = func(*sql.DB, string) error {
+		t.Error("Analyze() called without a connection")
+		return nil
+	}
+
+	err := analyze(db.Config{Engine: "fake", Database: "missing"})
+	var notExists *engine.DatabaseNotExistsError
+	if !errors.As(err, &notExists) {
+		t.Errorf("analyze() error = %v, want the connect error", err)
+	}
+}
+
 func TestMigrateCmd(t *testing.T) {
 	// Test that migrateCmd is initialized
 	if migrateCmd == nil {
@@ -111,3 +198,66 @@ func TestDryRunFlag(t *testing.T) {
 		t.Errorf("flag.Name = %q, want %q", flag.Name, "dry-run")
 	}
 }
+
+func TestSchemaFlags(t *testing.T) {
+	for _, name := range []string{"schema", "exclude-schema"} {
+		flag := migrateCmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Errorf("migrateCmd should have --%s flag", name)
+			continue
+		}
+		if flag.Value.Type() != "stringSlice" {
+			t.Errorf("--%s type = %q, want stringSlice", name, flag.Value.Type())
+		}
+	}
+}
+
+func TestRefreshMatviewsFlag(t *testing.T) {
+	flag := migrateCmd.Flags().Lookup("refresh-matviews")
+	if flag == nil {
+		t.Fatalf("--refresh-matviews flag not found")
+	}
+	if flag.DefValue != "true" {
+		t.Errorf("--refresh-matviews default = %q, want %q", flag.DefValue, "true")
+	}
+}
+
+func TestDefinerFlags(t *testing.T) {
+	flag := migrateCmd.Flags().Lookup("definer")
+	if flag == nil {
+		t.Fatalf("--definer flag not found")
+	}
+	if flag.DefValue != "strip" {
+		t.Errorf("--definer default = %q, want %q", flag.DefValue, "strip")
+	}
+	if migrateCmd.Flags().Lookup("sql-security") == nil {
+		t.Errorf("migrateCmd should have --sql-security flag")
+	}
+}
+
+func TestPartitioningFlags(t *testing.T) {
+	flag := migrateCmd.Flags().Lookup("partitioning")
+	if flag == nil {
+		t.Fatalf("--partitioning flag not found")
+	}
+	if flag.DefValue != "keep" {
+		t.Errorf("--partitioning default = %q, want %q", flag.DefValue, "keep")
+	}
+	workers := migrateCmd.Flags().Lookup("partition-workers")
+	if workers == nil {
+		t.Fatalf("--partition-workers flag not found")
+	}
+	if workers.DefValue != "4" {
+		t.Errorf("--partition-workers default = %q, want %q", workers.DefValue, "4")
+	}
+}
+
+func TestEnumsFlag(t *testing.T) {
+	flag := migrateCmd.Flags().Lookup("enums")
+	if flag == nil {
+		t.Fatalf("--enums flag not found")
+	}
+	if flag.DefValue != "check" {
+		t.Errorf("--enums default = %q, want %q", flag.DefValue, "check")
+	}
+}
@@ -2,16 +2,12 @@ package db
 
 import (
 	"bufio"
-	"database/sql"
 	"fmt"
-	"net/url"
 	"os"
 	"strings"
 
 	"github.com/fatih/color"
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
 )
 
 type Config struct {
@@ -95,7 +91,7 @@ func PromptConnectionDetails() Config {
 	if engineDefault == "" {
 		engineDefault = "mysql"
 	}
-	engine := promptWithDefault(reader, "Database engine (mysql/pgsql)", engineDefault)
+	engine := promptWithDefault(reader, "Database engine (mysql/mariadb/pgsql)", engineDefault)
 
 	// Host
 	hostDefault := defaults.Host
@@ -147,87 +143,3 @@ func PromptConnectionDetails() Config {
 		Password: password,
 	}
 }
-
-func Connect(config Config) (*sql.DB, error) {
-	bold := color.New(color.Bold).SprintFunc()
-	green := color.New(color.FgGreen).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
-
-	var db *sql.DB
-	var sslMode string
-	var lastErr error
-
-	switch config.Engine {
-	case "mysql":
-		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
-			config.User, config.Password, config.Host, config.Port, config.Database)
-		var err error
-		db, err = sql.Open("mysql", dsn)
-		if err != nil {
-			fmt.Printf("  %s %s\n", red("✗"), err)
-			return nil, err
-		}
-		if err := db.Ping(); err != nil {
-			fmt.Printf("  %s %s\n", red("✗"), err)
-			return nil, err
-		}
-
-	case "pgsql":
-		// Try SSL modes in order: require -> prefer -> disable
-		sslModes := []string{"require", "prefer", "disable"}
-		for _, mode := range sslModes {
-			dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-				url.QueryEscape(config.User), url.QueryEscape(config.Password),
-				config.Host, config.Port, config.Database, mode)
-
-			var err error
-			db, err = sql.Open("postgres", dsn)
-			if err != nil {
-				lastErr = err
-				continue
-			}
-			if err := db.Ping(); err != nil {
-				db.Close()
-				lastErr = err
-				continue
-			}
-			sslMode = mode
-			break
-		}
-		if db == nil || sslMode == "" {
-			fmt.Printf("  %s %s\n", red("✗"), lastErr)
-			return nil, fmt.Errorf("failed to connect with any SSL mode: %w", lastErr)
-		}
-
-	default:
-		return nil, fmt.Errorf("unsupported database engine: %s", config.Engine)
-	}
-
-	// Show connection info
-	sslInfo := ""
-	if sslMode != "" && sslMode != "require" {
-		sslInfo = fmt.Sprintf(" (SSL: %s)", sslMode)
-	}
-	fmt.Printf("  %s %s %s @ %s:%s/%s%s\n",
-		green("✓"),
-		bold("Connected to"),
-		config.Engine,
-		config.Host,
-		config.Port,
-		config.Database,
-		sslInfo,
-	)
-
-	return db, nil
-}
-
-func Analyze(conn *sql.DB, config Config) error {
-	switch config.Engine {
-	case "mysql":
-		return AnalyzeMySQL(conn, config.Database)
-	case "pgsql":
-		return AnalyzePostgres(conn, config.Database)
-	default:
-		return fmt.Errorf("unsupported database engine: %s", config.Engine)
-	}
-}
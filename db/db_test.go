@@ -165,28 +165,6 @@ func TestConfig_Fields(t *testing.T) {
 	}
 }
 
-func TestConnect_UnsupportedEngine(t *testing.T) {
-	cfg := Config{
-		Engine: "unsupported",
-	}
-
-	_, err := Connect(cfg)
-	if err == nil {
-		t.Errorf("Connect() with unsupported engine expected error, got nil")
-	}
-}
-
-func TestAnalyze_UnsupportedEngine(t *testing.T) {
-	cfg := Config{
-		Engine: "unsupported",
-	}
-
-	err := Analyze(nil, cfg)
-	if err == nil {
-		t.Errorf("Analyze() with unsupported engine expected error, got nil")
-	}
-}
-
 func TestEnvDefaults_Fields(t *testing.T) {
 	defaults := envDefaults{
 		Engine:   "mysql",
@@ -271,38 +249,6 @@ func TestConfig_ZeroValue(t *testing.T) {
 	}
 }
 
-func TestConnect_MySQL_InvalidHost(t *testing.T) {
-	cfg := Config{
-		Engine:   "mysql",
-		Host:     "invalid-host-that-does-not-exist",
-		Port:     "3306",
-		Database: "testdb",
-		User:     "testuser",
-		Password: "testpass",
-	}
-
-	_, err := Connect(cfg)
-	if err == nil {
-		t.Logf("Connect() succeeded unexpectedly (may have valid host)")
-	}
-}
-
-func TestConnect_Postgres_InvalidHost(t *testing.T) {
-	cfg := Config{
-		Engine:   "pgsql",
-		Host:     "invalid-host-that-does-not-exist",
-		Port:     "5432",
-		Database: "testdb",
-		User:     "testuser",
-		Password: "testpass",
-	}
-
-	_, err := Connect(cfg)
-	if err == nil {
-		t.Logf("Connect() succeeded unexpectedly (may have valid host)")
-	}
-}
-
 func TestAnalyzeMySQL(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -345,6 +291,13 @@ func TestAnalyzeMySQL(t *testing.T) {
 			AddRow("users", "InnoDB", int64(100), float64(50000)).
 			AddRow("orders", "InnoDB", int64(200), float64(75000)))
 
+	// Mock partition details
+	mock.ExpectQuery("SELECT.*partition_name.*FROM information_schema.partitions").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "partition_name", "partition_method", "partition_description", "table_rows", "total_size"}).
+			AddRow("orders", "p2024", "RANGE", "2025", int64(150), float64(49152)).
+			AddRow("orders", "pmax", "RANGE", "MAXVALUE", int64(50), float64(16384)))
+
 	// Mock indexes
 	mock.ExpectQuery("SELECT.*table_name.*index_name.*FROM information_schema.statistics").
 		WithArgs("testdb").
@@ -522,39 +475,3 @@ func TestAnalyzePostgres_TableCountError(t *testing.T) {
 		t.Errorf("AnalyzePostgres() expected error, got nil")
 	}
 }
-
-func TestAnalyze_MySQL(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
-	}
-	defer db.Close()
-
-	// Mock enough for the Analyze function to call AnalyzeMySQL
-	mock.ExpectQuery("SELECT VERSION\\(\\)").
-		WillReturnError(sqlmock.ErrCancelled) // Will fail quickly
-
-	cfg := Config{Engine: "mysql"}
-	err = Analyze(db, cfg)
-	if err == nil {
-		t.Logf("Analyze() returned error as expected")
-	}
-}
-
-func TestAnalyze_Postgres(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
-	}
-	defer db.Close()
-
-	// Mock enough for the Analyze function to call AnalyzePostgres
-	mock.ExpectQuery("SELECT version\\(\\)").
-		WillReturnError(sqlmock.ErrCancelled) // Will fail quickly
-
-	cfg := Config{Engine: "pgsql"}
-	err = Analyze(db, cfg)
-	if err == nil {
-		t.Logf("Analyze() returned error as expected")
-	}
-}
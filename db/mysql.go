@@ -143,6 +143,44 @@ func AnalyzeMySQL(db *sql.DB, database string) error {
 	}
 	fmt.Printf("\n  %s %s\n", yellow("Total Estimated Rows:"), green(format.Number(totalRows)))
 
+	// Partitions, with subpartitions folded into their partition
+	partRows, err := db.Query(`
+		SELECT
+			table_name,
+			partition_name,
+			partition_method,
+			COALESCE(partition_description, ''),
+			SUM(table_rows),
+			SUM(data_length + index_length) as total_size
+		FROM information_schema.partitions
+		WHERE table_schema = ? AND partition_name IS NOT NULL
+		GROUP BY table_name, partition_name, partition_ordinal_position, partition_method, partition_description
+		ORDER BY table_name, partition_ordinal_position
+	`, database)
+	if err != nil {
+		return fmt.Errorf("failed to get partition details: %w", err)
+	}
+	defer partRows.Close()
+
+	hasPartitions := false
+	for partRows.Next() {
+		var tableName, partitionName, method, bound string
+		var partitionRows int64
+		var size float64
+		if err := partRows.Scan(&tableName, &partitionName, &method, &bound, &partitionRows, &size); err != nil {
+			return err
+		}
+		if !hasPartitions {
+			hasPartitions = true
+			fmt.Println()
+			fmt.Printf("  %s\n\n", bold("🧩 Partitions"))
+			fmt.Printf("  %-25s %-20s %-12s %-15s %12s %12s\n", bold("TABLE"), bold("PARTITION"), bold("METHOD"), bold("BOUND"), bold("EST. ROWS"), bold("SIZE"))
+			fmt.Printf("  %s\n", dim(strings.Repeat("─", 101)))
+		}
+		fmt.Printf("  %-25s %-20s %-12s %-15s %12s %12s\n", format.Truncate(tableName, 25), format.Truncate(partitionName, 20), method,
+			format.Truncate(bound, 15), format.Number(partitionRows), format.Bytes(size))
+	}
+
 	// Index information
 	fmt.Println()
 	fmt.Printf("  %s\n\n", bold("🔑 Indexes"))
@@ -11,16 +11,39 @@ type DatabaseConfig struct {
 	Engine   string
 	Host     string
 	Port     string
-	Database string
+	Database string // Database name, or the path of the database file for SQLite
 	User     string
 	Password string
 }
 
 // MigrationConfig holds both source and destination configurations
 type MigrationConfig struct {
-	Source      DatabaseConfig
-	Destination DatabaseConfig
-	DryRun      bool
+	Source         DatabaseConfig
+	Destination    DatabaseConfig
+	DryRun         bool
+	Schemas        []string // PostgreSQL schemas to migrate (all non-system schemas when empty)
+	ExcludeSchemas []string // PostgreSQL schemas to skip
+
+	RefreshMaterializedViews bool // Populate materialized views at the end of the migration
+
+	Definer     string // MySQL definer policy: strip, current-user, keep or user@host
+	SQLSecurity string // MySQL SQL SECURITY override for views and routines: definer, invoker or empty to keep
+
+	Partitioning     string // MySQL partitioned tables: keep or drop the partitioning on the destination
+	PartitionWorkers int    // Partitions of one table copied at once
+
+	Enums string // MySQL ENUM columns on a PostgreSQL destination: check or type
+
+	ConvertCharset   string // Charset latin1 and utf8 MySQL tables and columns are converted to; empty to keep them
+	ConvertCollation string // Collation for the converted tables and columns
+	RepairMojibake   bool   // Repair double-encoded UTF-8 in converted columns while copying
+
+	Rules   string // JSON file of rules rewriting the DDL sent to the destination
+	Mapping string // JSON file renaming tables and columns and leaving columns out on the destination
+
+	Include          []string // Table globs to migrate (every table when empty)
+	Exclude          []string // Table globs to leave out
+	SchemaOnlyTables []string // Table globs created without their rows
 }
 
 // getEnvWithFallback checks multiple environment variable keys and returns the first non-empty value
@@ -70,6 +93,9 @@ func HasEnvDefaults() bool {
 	return defaults.Host != "" || defaults.User != "" || defaults.Database != ""
 }
 
+// DefaultSQLitePath is where Laravel keeps its SQLite database when DB_DATABASE isn't set
+const DefaultSQLitePath = "database/database.sqlite"
+
 // DefaultPort returns the default port for a given database engine
 func DefaultPort(engine string) string {
 	switch engine {
@@ -85,8 +111,12 @@ func NormalizeEngine(engine string) string {
 	switch engine {
 	case "postgres", "postgresql":
 		return "pgsql"
-	case "mysql", "mariadb":
+	case "mysql":
 		return "mysql"
+	case "mariadb":
+		return "mariadb"
+	case "sqlite", "sqlite3":
+		return "sqlite"
 	default:
 		return engine
 	}
@@ -11,6 +11,7 @@ func TestDefaultPort(t *testing.T) {
 		want   string
 	}{
 		{"mysql", "3306"},
+		{"mariadb", "3306"},
 		{"pgsql", "5432"},
 		{"postgres", "5432"},
 		{"postgresql", "5432"},
@@ -32,10 +33,12 @@ func TestNormalizeEngine(t *testing.T) {
 		want   string
 	}{
 		{"mysql", "mysql"},
-		{"mariadb", "mysql"},
+		{"mariadb", "mariadb"},
 		{"pgsql", "pgsql"},
 		{"postgres", "pgsql"},
 		{"postgresql", "pgsql"},
+		{"sqlite", "sqlite"},
+		{"sqlite3", "sqlite"},
 		{"unknown", "unknown"},
 		{"", ""},
 	}
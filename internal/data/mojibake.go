@@ -0,0 +1,127 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+// MojibakeRepairer is implemented by transferers that can find and repair double-encoded
+// UTF-8: text that was stored as UTF-8 bytes in a latin1 column, or read as latin1 and
+// written back as UTF-8, so that "café" reads "cafÃ©"
+type MojibakeRepairer interface {
+	// SampleMojibake reads up to limit non-ASCII values of a column and counts the double-encoded ones
+	SampleMojibake(db *sql.DB, table schema.TableSchema, column string, limit int) (sampled, suspect int, err error)
+
+	// SetMojibakeRepair has double-encoded values of the given columns repaired while they're copied
+	SetMojibakeRepair(table schema.TableSchema, columns []string)
+}
+
+// SampleMojibake reads up to limit values of a column with characters outside ASCII and counts
+// those that look double-encoded
+func (t *MySQLTransferer) SampleMojibake(db *sql.DB, table schema.TableSchema, column string, limit int) (int, int, error) {
+	col := t.Dialect.QuoteIdentifier(column)
+	// A value has a character outside ASCII when it takes more bytes than characters as utf8mb4
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE LENGTH(CONVERT(%s USING utf8mb4)) <> CHAR_LENGTH(%s) LIMIT %d",
+		col, t.quoteSourceTable(table), col, col, limit)
+	rows, err := db.Query(query)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to sample %s.%s: %w", table.QualifiedName(), column, err)
+	}
+	defer rows.Close()
+
+	sampled, suspect := 0, 0
+	for rows.Next() {
+		var value []byte
+		if err := rows.Scan(&value); err != nil {
+			return sampled, suspect, err
+		}
+		sampled++
+		if _, ok := undoDoubleEncoding(string(value)); ok {
+			suspect++
+		}
+	}
+	return sampled, suspect, rows.Err()
+}
+
+// SetMojibakeRepair has double-encoded values of the given columns repaired while they're copied
+func (t *MySQLTransferer) SetMojibakeRepair(table schema.TableSchema, columns []string) {
+	if t.repair == nil {
+		t.repair = make(map[string]map[string]bool)
+	}
+	set := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		set[col] = true
+	}
+	t.repair[table.SourceQualifiedName()] = set
+}
+
+// repairMojibake returns a text value with its double encoding undone, or the value unchanged
+// when it isn't double-encoded
+func repairMojibake(value interface{}) interface{} {
+	var s string
+	switch v := value.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return value
+	}
+	repaired, ok := undoDoubleEncoding(s)
+	if !ok {
+		return value
+	}
+	if _, isString := value.(string); isString {
+		return string(repaired)
+	}
+	return repaired
+}
+
+// undoDoubleEncoding encodes s back to the latin1 bytes MySQL decoded it from, and returns them
+// when they're valid UTF-8 with at least one multi-byte character
+// Correctly encoded text outside ASCII almost never survives this: a lone "é" is the byte 0xE9,
+// which can't stand on its own in UTF-8.
+func undoDoubleEncoding(s string) ([]byte, bool) {
+	out := make([]byte, 0, len(s))
+	multibyte := false
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+			continue
+		}
+		b, ok := latin1Byte(r)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, b)
+		multibyte = true
+	}
+	if !multibyte || !utf8.Valid(out) {
+		return nil, false
+	}
+	return out, true
+}
+
+// cp1252Runes maps the characters MySQL's latin1 (Windows-1252) puts at 0x80-0x9F to their bytes
+var cp1252Runes = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// latin1Byte returns the byte MySQL's latin1 encodes a character as
+// The five bytes Windows-1252 leaves undefined map to the C1 control characters of the same value
+func latin1Byte(r rune) (byte, bool) {
+	if b, ok := cp1252Runes[r]; ok {
+		return b, true
+	}
+	switch {
+	case r >= 0xA0 && r <= 0xFF, r == 0x81, r == 0x8D, r == 0x8F, r == 0x90, r == 0x9D:
+		return byte(r), true
+	}
+	return 0, false
+}
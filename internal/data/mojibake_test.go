@@ -0,0 +1,91 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+func TestUndoDoubleEncoding(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"cafÃ©", "café", true},
+		{"Ã¼ber â€” naÃ¯ve", "über — naïve", true},
+		{"â‚¬5", "€5", true},
+		{"café", "", false},        // correctly encoded
+		{"plain ascii", "", false}, // nothing to repair
+		{"日本", "", false},          // outside latin1
+		{"Ã", "", false},           // a lone lead byte isn't UTF-8
+	}
+	for _, tt := range tests {
+		got, ok := undoDoubleEncoding(tt.in)
+		if ok != tt.wantOK || string(got) != tt.want {
+			t.Errorf("undoDoubleEncoding(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestMySQLTransferer_SampleMojibake(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT `name` FROM `users` WHERE LENGTH\\(CONVERT\\(`name` USING utf8mb4\\)\\) <> CHAR_LENGTH\\(`name`\\) LIMIT 100").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow([]byte("JosÃ©")).AddRow([]byte("Zoë")).AddRow([]byte("RenÃ©e")))
+
+	tr := NewMySQLTransferer()
+	sampled, suspect, err := tr.SampleMojibake(db, schema.TableSchema{Name: "users"}, "name", 100)
+	if err != nil {
+		t.Fatalf("SampleMojibake() error = %v", err)
+	}
+	if sampled != 3 || suspect != 2 {
+		t.Errorf("SampleMojibake() = %d, %d, want 3, 2", sampled, suspect)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLTransferer_TransferTable_RepairsMojibake(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, destMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	table := schema.TableSchema{Name: "users"}
+	tr := NewMySQLTransferer()
+	tr.SetMojibakeRepair(table, []string{"name"})
+
+	sourceMock.ExpectQuery("SELECT \\* FROM `users` LIMIT 0").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "bio"}))
+	sourceMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `users`").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(2)))
+	sourceMock.ExpectQuery("SELECT `id`, `name`, `bio` FROM `users` LIMIT 100 OFFSET 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "bio"}).
+			AddRow(1, []byte("JosÃ©"), []byte("Ã©")).
+			AddRow(2, []byte("Zoë"), nil))
+	destMock.ExpectExec("INSERT INTO `users`").
+		WithArgs(1, []byte("José"), []byte("Ã©"), 2, []byte("Zoë"), nil).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if _, err := tr.TransferTable(sourceDB, destDB, table, 100, false, nil); err != nil {
+		t.Fatalf("TransferTable() error = %v", err)
+	}
+	if err := sourceMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("source expectations not met: %v", err)
+	}
+	if err := destMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("dest expectations not met: %v", err)
+	}
+}
@@ -15,3 +15,12 @@ func NewMySQLTransferer() *MySQLTransferer {
 		},
 	}
 }
+
+// NewMariaDBTransferer creates a data transferer for MariaDB databases, which copy rows as MySQL does
+func NewMariaDBTransferer() *MySQLTransferer {
+	return &MySQLTransferer{
+		BaseTransferer: BaseTransferer{
+			Dialect: &dialect.MariaDBDialect{},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+// MySQLToPostgresTransferer copies MySQL rows into tables translated for PostgreSQL
+type MySQLToPostgresTransferer struct {
+	BaseTransferer
+}
+
+// NewMySQLToPostgresTransferer creates a new MySQL to PostgreSQL data transferer
+func NewMySQLToPostgresTransferer() *MySQLToPostgresTransferer {
+	return &MySQLToPostgresTransferer{
+		BaseTransferer: BaseTransferer{
+			Dialect:       &dialect.PostgresDialect{},
+			SourceDialect: &dialect.MySQLDialect{},
+			ConvertValue:  convertMySQLValue,
+		},
+	}
+}
+
+// convertMySQLValue converts a value read from MySQL for a column translated to PostgreSQL
+// The MySQL driver returns most values as []byte in their text form, which PostgreSQL parses
+// as is; booleans, bit strings, SET members and zero dates need rewriting
+func convertMySQLValue(column schema.ColumnDef, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch {
+	case column.DataType == "boolean":
+		switch v := value.(type) {
+		case []byte:
+			return string(v) != "0"
+		case int64:
+			return v != 0
+		}
+	case column.DataType == "bytea":
+		return value
+	case column.DataType == "text[]":
+		if v, ok := value.([]byte); ok {
+			return schema.SetArrayLiteral(string(v))
+		}
+	case strings.HasPrefix(column.DataType, "bit("):
+		if v, ok := value.([]byte); ok {
+			var width int
+			fmt.Sscanf(column.DataType, "bit(%d)", &width)
+			return bitString(v, width)
+		}
+	case column.DataType == "date" || strings.HasPrefix(column.DataType, "timestamp"):
+		if v, ok := value.([]byte); ok && schema.IsZeroDate(string(v)) {
+			// PostgreSQL has no zero date; NOT NULL columns get the earliest possible value
+			if column.IsNullable {
+				return nil
+			}
+			return "-infinity"
+		}
+	}
+
+	if v, ok := value.([]byte); ok {
+		return string(v)
+	}
+	return value
+}
+
+// bitString formats a MySQL BIT value, which the driver returns as big-endian bytes, as a
+// PostgreSQL bit string literal of the given width
+func bitString(value []byte, width int) string {
+	var sb strings.Builder
+	for _, b := range value {
+		fmt.Fprintf(&sb, "%08b", b)
+	}
+	bits := sb.String()
+	if len(bits) > width {
+		return bits[len(bits)-width:]
+	}
+	return strings.Repeat("0", width-len(bits)) + bits
+}
@@ -0,0 +1,89 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+func TestConvertMySQLValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		column schema.ColumnDef
+		value  interface{}
+		want   interface{}
+	}{
+		{"null", schema.ColumnDef{DataType: "text"}, nil, nil},
+		{"text", schema.ColumnDef{DataType: "varchar(255)"}, []byte("héllo"), "héllo"},
+		{"boolean false", schema.ColumnDef{DataType: "boolean"}, []byte("0"), false},
+		{"boolean other", schema.ColumnDef{DataType: "boolean"}, []byte("2"), true},
+		{"boolean binary protocol", schema.ColumnDef{DataType: "boolean"}, int64(1), true},
+		{"bytea", schema.ColumnDef{DataType: "bytea"}, []byte{0x00, 0xff}, []byte{0x00, 0xff}},
+		{"set", schema.ColumnDef{DataType: "text[]"}, []byte("gift,rush"), `{"gift","rush"}`},
+		{"bit", schema.ColumnDef{DataType: "bit(10)"}, []byte{0x02, 0x05}, "1000000101"},
+		{"zero date nullable", schema.ColumnDef{DataType: "timestamp(0)", IsNullable: true}, []byte("0000-00-00 00:00:00"), nil},
+		{"zero date not null", schema.ColumnDef{DataType: "date"}, []byte("0000-00-00"), "-infinity"},
+		{"date", schema.ColumnDef{DataType: "date"}, []byte("2024-02-29"), "2024-02-29"},
+		{"integer", schema.ColumnDef{DataType: "bigint"}, int64(42), int64(42)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertMySQLValue(tt.column, tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertMySQLValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMySQLToPostgresTransferer_TransferTable(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, destMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	table := schema.TableSchema{
+		Name: "users",
+		Columns: []schema.ColumnDef{
+			{Name: "id", DataType: "bigint", Extra: "IDENTITY BY DEFAULT"},
+			{Name: "active", DataType: "boolean", SourceType: "tinyint(1)"},
+			{Name: "verified_at", DataType: "timestamp(0)", IsNullable: true},
+		},
+	}
+
+	// Reads use MySQL quoting, writes PostgreSQL quoting and placeholders
+	sourceMock.ExpectQuery("SELECT \\* FROM `users` LIMIT 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "active", "verified_at"}))
+	sourceMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(2)))
+	sourceMock.ExpectQuery("SELECT `id`, `active`, `verified_at` FROM `users` LIMIT 100 OFFSET 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "active", "verified_at"}).
+			AddRow([]byte("1"), []byte("1"), []byte("0000-00-00 00:00:00")).
+			AddRow([]byte("2"), []byte("0"), []byte("2024-01-02 03:04:05")))
+	destMock.ExpectExec(`INSERT INTO "users" \("id", "active", "verified_at"\) VALUES \(\$1, \$2, \$3\), \(\$4, \$5, \$6\)`).
+		WithArgs("1", true, nil, "2", false, "2024-01-02 03:04:05").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	stats, err := NewMySQLToPostgresTransferer().TransferTable(sourceDB, destDB, table, 100, false, nil)
+	if err != nil {
+		t.Fatalf("TransferTable() error = %v", err)
+	}
+	if stats.RowsCopied != 2 {
+		t.Errorf("stats.RowsCopied = %d, want 2", stats.RowsCopied)
+	}
+
+	if err := sourceMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("source expectations not met: %v", err)
+	}
+	if err := destMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("dest expectations not met: %v", err)
+	}
+}
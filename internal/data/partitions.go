@@ -0,0 +1,111 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+// DefaultPartitionWorkers is how many partitions of a table are copied at once by default
+const DefaultPartitionWorkers = 4
+
+// PartitionTransferer is implemented by transferers that copy partitions concurrently
+type PartitionTransferer interface {
+	SetPartitionWorkers(n int)
+}
+
+// SetPartitionWorkers sets how many partitions of a table are copied at once
+func (t *BaseTransferer) SetPartitionWorkers(n int) {
+	t.PartitionWorkers = n
+}
+
+// transferPartitions copies a partitioned table partition by partition, several at a time
+// It stops handing out partitions after the first failure and returns that error; the
+// partitions that finished stay in place, and a retry in the same run skips them. Nothing is
+// recorded for a later run, which starts from a wiped destination.
+func (t *BaseTransferer) transferPartitions(source, dest *sql.DB, table schema.TableSchema, columns []string, batchSize int, progressFn func(rows int64)) (int64, error) {
+	workers := t.PartitionWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		mu       sync.Mutex
+		copied   int64
+		firstErr error
+	)
+	report := func(rows int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		copied += rows
+		if progressFn != nil {
+			progressFn(copied)
+		}
+	}
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	work := make(chan schema.PartitionDef)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				if failed() {
+					continue
+				}
+				if err := t.transferPartition(source, dest, table, p, columns, batchSize, report); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("partition %s: %w", p.Name, err)
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, p := range table.Partitions {
+		work <- p
+	}
+	close(work)
+	wg.Wait()
+
+	return copied, firstErr
+}
+
+// transferPartition copies one partition with SELECT ... PARTITION (p)
+// When the destination keeps the partitioning, a partition that's already complete there is
+// skipped and a partially copied one is truncated and copied again
+func (t *BaseTransferer) transferPartition(source, dest *sql.DB, table schema.TableSchema, p schema.PartitionDef, columns []string, batchSize int, report func(rows int64)) error {
+	name := t.Dialect.QuoteIdentifier(p.Name)
+	partition := fmt.Sprintf("PARTITION (%s)", name)
+	from := fmt.Sprintf("%s PARTITION (%s)", t.quoteSourceTable(table), t.sourceDialect().QuoteIdentifier(p.Name))
+
+	if !table.PartitioningDropped {
+		var want, have int64
+		if err := source.QueryRow("SELECT COUNT(*) FROM " + from).Scan(&want); err != nil {
+			return fmt.Errorf("failed to count source rows: %w", err)
+		}
+		if err := dest.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s %s", t.quoteTable(table), partition)).Scan(&have); err != nil {
+			return fmt.Errorf("failed to count destination rows: %w", err)
+		}
+		if have == want {
+			report(have)
+			return nil
+		}
+		if have > 0 {
+			if _, err := dest.Exec(fmt.Sprintf("ALTER TABLE %s TRUNCATE PARTITION %s", t.quoteTable(table), name)); err != nil {
+				return fmt.Errorf("failed to truncate partially copied partition: %w", err)
+			}
+		}
+	}
+
+	_, err := t.copyRows(source, dest, table, columns, from, batchSize, report)
+	return err
+}
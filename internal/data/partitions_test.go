@@ -0,0 +1,115 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+func TestBaseTransferer_TransferTable_Partitions_Retry(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, destMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	bt := &BaseTransferer{Dialect: &dialect.MySQLDialect{}}
+
+	sourceMock.ExpectQuery("SELECT \\* FROM `logs` LIMIT 0").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	sourceMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `logs`$").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(5)))
+
+	// p2023 finished in an earlier attempt
+	sourceMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `logs` PARTITION \\(`p2023`\\)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(3)))
+	destMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `logs` PARTITION \\(`p2023`\\)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(3)))
+
+	// p2024 was partially copied, so it's emptied and copied again
+	sourceMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `logs` PARTITION \\(`p2024`\\)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(2)))
+	destMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `logs` PARTITION \\(`p2024`\\)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	destMock.ExpectExec("ALTER TABLE `logs` TRUNCATE PARTITION `p2024`").WillReturnResult(sqlmock.NewResult(0, 0))
+	sourceMock.ExpectQuery("SELECT `id` FROM `logs` PARTITION \\(`p2024`\\) LIMIT 100 OFFSET 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(5))
+	destMock.ExpectExec("INSERT INTO `logs` \\(`id`\\) VALUES \\(\\?\\), \\(\\?\\)").
+		WithArgs(4, 5).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	table := schema.TableSchema{
+		Name:       "logs",
+		Partitions: []schema.PartitionDef{{Name: "p2023"}, {Name: "p2024"}},
+	}
+
+	var progressCalls []int64
+	stats, err := bt.TransferTable(sourceDB, destDB, table, 100, false, func(rows int64) {
+		progressCalls = append(progressCalls, rows)
+	})
+	if err != nil {
+		t.Fatalf("TransferTable() error = %v", err)
+	}
+	if stats.RowsCopied != 5 {
+		t.Errorf("stats.RowsCopied = %d, want 5", stats.RowsCopied)
+	}
+	if len(progressCalls) != 2 || progressCalls[1] != 5 {
+		t.Errorf("progressFn calls = %v, want [3 5]", progressCalls)
+	}
+
+	if err := sourceMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("source expectations not met: %v", err)
+	}
+	if err := destMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("dest expectations not met: %v", err)
+	}
+}
+
+func TestBaseTransferer_TransferTable_Partitions_Dropped(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, destMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	bt := &BaseTransferer{Dialect: &dialect.MySQLDialect{}}
+	bt.SetPartitionWorkers(1)
+
+	sourceMock.ExpectQuery("SELECT \\* FROM `logs` LIMIT 0").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	sourceMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `logs`$").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+
+	// Without partitions on the destination there's nothing to skip, so every partition is read
+	sourceMock.ExpectQuery("SELECT `id` FROM `logs` PARTITION \\(`p0`\\) LIMIT 100 OFFSET 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	destMock.ExpectExec("INSERT INTO `logs` \\(`id`\\) VALUES \\(\\?\\)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sourceMock.ExpectQuery("SELECT `id` FROM `logs` PARTITION \\(`p1`\\) LIMIT 100 OFFSET 0").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	table := schema.TableSchema{
+		Name:                "logs",
+		Partitions:          []schema.PartitionDef{{Name: "p0"}, {Name: "p1"}},
+		PartitioningDropped: true,
+	}
+
+	_, err = bt.TransferTable(sourceDB, destDB, table, 100, false, nil)
+	if err == nil || err.Error() != "partition p1: failed to read from source: canceling query due to user request" {
+		t.Errorf("TransferTable() error = %v, want the p1 read error", err)
+	}
+
+	if err := sourceMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("source expectations not met: %v", err)
+	}
+	if err := destMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("dest expectations not met: %v", err)
+	}
+}
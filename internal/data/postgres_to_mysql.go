@@ -0,0 +1,92 @@
+package data
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+// mysqlDatetimeLayout formats a time as a MySQL DATETIME literal, with as many fractional
+// digits as it has
+const mysqlDatetimeLayout = "2006-01-02 15:04:05.999999"
+
+// PostgresToMySQLTransferer copies PostgreSQL rows into tables translated for MySQL
+// Tables from every source schema are written to the one destination database
+type PostgresToMySQLTransferer struct {
+	BaseTransferer
+}
+
+// NewPostgresToMySQLTransferer creates a new PostgreSQL to MySQL data transferer
+func NewPostgresToMySQLTransferer() *PostgresToMySQLTransferer {
+	return &PostgresToMySQLTransferer{
+		BaseTransferer: BaseTransferer{
+			Dialect:        &dialect.MySQLDialect{},
+			SourceDialect:  &dialect.PostgresDialect{},
+			FlattenSchemas: true,
+			ConvertValue:   convertPostgresValue,
+		},
+	}
+}
+
+// convertPostgresValue converts a value read from PostgreSQL for a column translated to MySQL
+// lib/pq returns timestamps as time.Time and most other types as []byte in their text form;
+// arrays, time zones, infinite dates and bit strings need rewriting
+func convertPostgresValue(column schema.ColumnDef, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	sourceType := column.SourceType
+
+	switch v := value.(type) {
+	case time.Time:
+		switch {
+		case sourceType == "date":
+			return v.Format("2006-01-02")
+		case strings.HasSuffix(sourceType, "with time zone"):
+			return v.UTC().Format(mysqlDatetimeLayout)
+		default:
+			return v.Format(mysqlDatetimeLayout)
+		}
+	case []byte:
+		text := string(v)
+		switch {
+		case strings.HasSuffix(sourceType, "[]"):
+			if array, err := schema.PostgresArrayJSON(text, strings.TrimSuffix(sourceType, "[]")); err == nil {
+				return array
+			}
+		case column.DataType == "longblob":
+			return v
+		case strings.HasPrefix(column.DataType, "bit("):
+			if bits, err := strconv.ParseUint(text, 2, 64); err == nil {
+				return bits
+			}
+		case (text == "infinity" || text == "-infinity") && (sourceType == "date" || strings.HasPrefix(sourceType, "timestamp")):
+			// MySQL has no infinite dates; NOT NULL columns get the furthest value it can store
+			if column.IsNullable {
+				return nil
+			}
+			if text == "infinity" {
+				return "9999-12-31 23:59:59"
+			}
+			return "1000-01-01 00:00:00"
+		case strings.HasPrefix(sourceType, "time") && strings.HasSuffix(sourceType, "with time zone"):
+			// timetz values carry an offset, e.g. 12:00:00+02, which TIME can't hold
+			if i := strings.IndexAny(text, "+-"); i > 0 {
+				return text[:i]
+			}
+		case sourceType == "money":
+			// money is formatted for lc_monetary, e.g. $1,234.50
+			return strings.Map(func(r rune) rune {
+				if (r >= '0' && r <= '9') || r == '.' || r == '-' {
+					return r
+				}
+				return -1
+			}, text)
+		}
+		return text
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+func TestConvertPostgresValue(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+	tests := []struct {
+		name   string
+		column schema.ColumnDef
+		value  interface{}
+		want   interface{}
+	}{
+		{"null", schema.ColumnDef{DataType: "longtext", SourceType: "text"}, nil, nil},
+		{"text", schema.ColumnDef{DataType: "longtext", SourceType: "text"}, []byte("héllo"), "héllo"},
+		{"boolean", schema.ColumnDef{DataType: "tinyint(1)", SourceType: "boolean"}, true, true},
+		{"integer", schema.ColumnDef{DataType: "bigint", SourceType: "bigint"}, int64(42), int64(42)},
+		{"bytea", schema.ColumnDef{DataType: "longblob", SourceType: "bytea"}, []byte{0x00, 0xff}, []byte{0x00, 0xff}},
+		{"uuid", schema.ColumnDef{DataType: "char(36)", SourceType: "uuid"}, []byte("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"), "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{"text array", schema.ColumnDef{DataType: "json", SourceType: "text[]"}, []byte(`{gift,"rush order",NULL}`), `["gift","rush order",null]`},
+		{"integer array", schema.ColumnDef{DataType: "json", SourceType: "integer[]"}, []byte("{{1,2},{3,4}}"), "[[1,2],[3,4]]"},
+		{"timestamptz", schema.ColumnDef{DataType: "datetime(6)", SourceType: "timestamp with time zone"},
+			time.Date(2024, 3, 1, 10, 30, 0, 500000000, berlin), "2024-03-01 08:30:00.5"},
+		{"timestamp", schema.ColumnDef{DataType: "datetime(0)", SourceType: "timestamp(0) without time zone"},
+			time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC), "2024-03-01 10:30:00"},
+		{"date", schema.ColumnDef{DataType: "date", SourceType: "date"}, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), "2024-02-29"},
+		{"infinity nullable", schema.ColumnDef{DataType: "datetime(6)", SourceType: "timestamp without time zone", IsNullable: true}, []byte("infinity"), nil},
+		{"infinity not null", schema.ColumnDef{DataType: "datetime(6)", SourceType: "timestamp without time zone"}, []byte("-infinity"), "1000-01-01 00:00:00"},
+		{"infinity as text", schema.ColumnDef{DataType: "longtext", SourceType: "text", IsNullable: true}, []byte("infinity"), "infinity"},
+		{"timetz", schema.ColumnDef{DataType: "time(6)", SourceType: "time with time zone"}, []byte("12:00:00+02"), "12:00:00"},
+		{"bit", schema.ColumnDef{DataType: "bit(10)", SourceType: "bit(10)"}, []byte("1000000101"), uint64(517)},
+		{"money", schema.ColumnDef{DataType: "decimal(19,2)", SourceType: "money"}, []byte("-$1,234.50"), "-1234.50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertPostgresValue(tt.column, tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertPostgresValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostgresToMySQLTransferer_TransferTable(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, destMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	table := schema.TableSchema{
+		Schema: "audit",
+		Name:   "events",
+		Columns: []schema.ColumnDef{
+			{Name: "id", DataType: "bigint", Extra: "auto_increment", SourceType: "bigint"},
+			{Name: "tags", DataType: "json", IsNullable: true, SourceType: "text[]"},
+			{Name: "created_at", DataType: "datetime(6)", SourceType: "timestamp with time zone"},
+		},
+	}
+	created := time.Date(2024, 1, 2, 5, 4, 5, 0, time.FixedZone("EST", -5*60*60))
+
+	// Reads use PostgreSQL quoting with the source schema; writes go to the one MySQL database
+	sourceMock.ExpectQuery(`SELECT \* FROM "audit"."events" LIMIT 0`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "created_at"}))
+	sourceMock.ExpectQuery(`SELECT COUNT\(\*\) FROM "audit"."events"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	sourceMock.ExpectQuery(`SELECT "id", "tags", "created_at" FROM "audit"."events" LIMIT 100 OFFSET 0`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "created_at"}).
+			AddRow(int64(1), []byte("{login,admin}"), created))
+	destMock.ExpectExec("INSERT INTO `events` \\(`id`, `tags`, `created_at`\\) VALUES \\(\\?, \\?, \\?\\)").
+		WithArgs(int64(1), `["login","admin"]`, "2024-01-02 10:04:05").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	stats, err := NewPostgresToMySQLTransferer().TransferTable(sourceDB, destDB, table, 100, false, nil)
+	if err != nil {
+		t.Fatalf("TransferTable() error = %v", err)
+	}
+	if stats.RowsCopied != 1 {
+		t.Errorf("stats.RowsCopied = %d, want 1", stats.RowsCopied)
+	}
+
+	if err := sourceMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("source expectations not met: %v", err)
+	}
+	if err := destMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("dest expectations not met: %v", err)
+	}
+}
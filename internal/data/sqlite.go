@@ -0,0 +1,95 @@
+package data
+
+import (
+	"strings"
+	"time"
+
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+// SQLiteTransferer reads rows from a SQLite database file
+type SQLiteTransferer struct {
+	BaseTransferer
+}
+
+// NewSQLiteTransferer creates a new SQLite data transferer
+func NewSQLiteTransferer() *SQLiteTransferer {
+	return &SQLiteTransferer{
+		BaseTransferer: BaseTransferer{
+			Dialect: &dialect.SQLiteDialect{},
+		},
+	}
+}
+
+// NewSQLiteToMySQLTransferer creates a transferer that copies SQLite rows into tables
+// translated for MySQL
+func NewSQLiteToMySQLTransferer() *SQLiteTransferer {
+	return &SQLiteTransferer{
+		BaseTransferer: BaseTransferer{
+			Dialect:       &dialect.MySQLDialect{},
+			SourceDialect: &dialect.SQLiteDialect{},
+			ConvertValue:  convertSQLiteValue,
+		},
+	}
+}
+
+// NewSQLiteToPostgresTransferer creates a transferer that copies SQLite rows into tables
+// translated for PostgreSQL
+func NewSQLiteToPostgresTransferer() *SQLiteTransferer {
+	return &SQLiteTransferer{
+		BaseTransferer: BaseTransferer{
+			Dialect:       &dialect.PostgresDialect{},
+			SourceDialect: &dialect.SQLiteDialect{},
+			ConvertValue:  convertSQLiteValue,
+		},
+	}
+}
+
+// convertSQLiteValue converts a value read from SQLite for a column translated to MySQL or PostgreSQL
+// The driver returns int64, float64, string or []byte by storage class, and time.Time for
+// columns declared as date, datetime or timestamp; any column can hold any storage class,
+// so values are converted by the destination type
+func convertSQLiteValue(column schema.ColumnDef, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	binary := column.DataType == "bytea" || column.DataType == "longblob"
+
+	switch v := value.(type) {
+	case time.Time:
+		switch {
+		case column.DataType == "date":
+			return v.Format("2006-01-02")
+		case strings.HasPrefix(column.DataType, "time("):
+			return v.Format("15:04:05.999999")
+		default:
+			return v.Format(mysqlDatetimeLayout)
+		}
+	case int64:
+		if column.DataType == "boolean" {
+			return v != 0
+		}
+	case float64:
+		if column.DataType == "boolean" {
+			return v != 0
+		}
+	case string:
+		switch {
+		case column.DataType == "boolean":
+			switch strings.ToLower(v) {
+			case "", "0", "f", "false", "n", "no", "off":
+				return false
+			default:
+				return true
+			}
+		case binary:
+			return []byte(v)
+		}
+	case []byte:
+		if !binary {
+			return string(v)
+		}
+	}
+	return value
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+func TestConvertSQLiteValue(t *testing.T) {
+	created := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		column schema.ColumnDef
+		value  interface{}
+		want   interface{}
+	}{
+		{"null", schema.ColumnDef{DataType: "text"}, nil, nil},
+		{"text", schema.ColumnDef{DataType: "varchar(255)"}, "héllo", "héllo"},
+		{"integer", schema.ColumnDef{DataType: "bigint"}, int64(42), int64(42)},
+		{"real", schema.ColumnDef{DataType: "double precision"}, 1.5, 1.5},
+		{"boolean integer", schema.ColumnDef{DataType: "boolean"}, int64(1), true},
+		{"boolean zero", schema.ColumnDef{DataType: "boolean"}, int64(0), false},
+		{"boolean text", schema.ColumnDef{DataType: "boolean"}, "false", false},
+		{"tinyint(1) stays numeric", schema.ColumnDef{DataType: "tinyint(1)"}, int64(1), int64(1)},
+		{"blob", schema.ColumnDef{DataType: "bytea"}, []byte{0x00, 0xff}, []byte{0x00, 0xff}},
+		{"text in blob column", schema.ColumnDef{DataType: "longblob"}, "abc", []byte("abc")},
+		{"blob in text column", schema.ColumnDef{DataType: "longtext"}, []byte("abc"), "abc"},
+		{"datetime", schema.ColumnDef{DataType: "datetime(0)"}, created, "2024-03-01 10:30:00"},
+		{"timestamp", schema.ColumnDef{DataType: "timestamp(0) without time zone"}, created.Add(250 * time.Millisecond), "2024-03-01 10:30:00.25"},
+		{"date", schema.ColumnDef{DataType: "date"}, created, "2024-03-01"},
+		{"unparsed datetime", schema.ColumnDef{DataType: "datetime(0)"}, "2024-03-01 10:30:00", "2024-03-01 10:30:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertSQLiteValue(tt.column, tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertSQLiteValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteToPostgresTransferer_TransferTable(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, destMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	table := schema.TableSchema{
+		Name: "users",
+		Columns: []schema.ColumnDef{
+			{Name: "id", DataType: "bigint", Extra: "IDENTITY BY DEFAULT", SourceType: "INTEGER"},
+			{Name: "active", DataType: "boolean", SourceType: "tinyint(1)"},
+			{Name: "created_at", DataType: "timestamp(0) without time zone", IsNullable: true, SourceType: "datetime"},
+		},
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Reads use SQLite quoting; writes go to the destination's default schema
+	sourceMock.ExpectQuery(`SELECT \* FROM "users" LIMIT 0`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "active", "created_at"}))
+	sourceMock.ExpectQuery(`SELECT COUNT\(\*\) FROM "users"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	sourceMock.ExpectQuery(`SELECT "id", "active", "created_at" FROM "users" LIMIT 100 OFFSET 0`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "active", "created_at"}).
+			AddRow(int64(1), int64(1), created))
+	destMock.ExpectExec(`INSERT INTO "users" \("id", "active", "created_at"\) VALUES \(\$1, \$2, \$3\)`).
+		WithArgs(int64(1), true, "2024-01-02 03:04:05").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	stats, err := NewSQLiteToPostgresTransferer().TransferTable(sourceDB, destDB, table, 100, false, nil)
+	if err != nil {
+		t.Fatalf("TransferTable() error = %v", err)
+	}
+	if stats.RowsCopied != 1 {
+		t.Errorf("stats.RowsCopied = %d, want 1", stats.RowsCopied)
+	}
+
+	if err := sourceMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("source expectations not met: %v", err)
+	}
+	if err := destMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("dest expectations not met: %v", err)
+	}
+}
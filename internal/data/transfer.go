@@ -29,24 +29,34 @@ type Transferer interface {
 	TransferTable(source, dest *sql.DB, table schema.TableSchema, batchSize int, dryRun bool, progressFn func(rows int64)) (*TransferStats, error)
 
 	// EstimateRows returns the estimated row count for a table
-	EstimateRows(db *sql.DB, table string) (int64, error)
+	EstimateRows(db *sql.DB, table schema.TableSchema) (int64, error)
 }
 
-// NewTransferer creates a data transferer for the given engine
-func NewTransferer(engine string) Transferer {
-	switch engine {
-	case "mysql":
-		return NewMySQLTransferer()
-	case "pgsql":
-		return NewPostgresTransferer()
-	default:
-		return nil
-	}
+// DestinationCounter is implemented by transferers that can count the rows copied into a
+// table, for verifying a migration whose destination is another engine than its source
+type DestinationCounter interface {
+	CountDestinationRows(dest *sql.DB, table schema.TableSchema) (int64, error)
 }
 
 // BaseTransferer contains shared transfer logic that works with any dialect
 type BaseTransferer struct {
-	Dialect dialect.Dialect
+	Dialect          dialect.Dialect
+	SourceDialect    dialect.Dialect // Dialect of the source when it's another engine; Dialect when nil
+	PartitionWorkers int             // Partitions of one table copied at once; one at a time when zero
+	FlattenSchemas   bool            // Destination tables are written unqualified, for PostgreSQL schemas migrated into one MySQL database
+
+	// ConvertValue, if set, converts each value read from the source for the destination column
+	ConvertValue func(column schema.ColumnDef, value interface{}) interface{}
+
+	repair map[string]map[string]bool // Columns per table whose double-encoded UTF-8 is repaired while copying
+}
+
+// sourceDialect returns the dialect queries against the source are written in
+func (t *BaseTransferer) sourceDialect() dialect.Dialect {
+	if t.SourceDialect != nil {
+		return t.SourceDialect
+	}
+	return t.Dialect
 }
 
 // DisableForeignKeyChecks executes the dialect-specific FK disable command
@@ -61,32 +71,84 @@ func (t *BaseTransferer) EnableForeignKeyChecks(dest *sql.DB) error {
 	return err
 }
 
+// quoteTable returns the schema-qualified, quoted name of a table on the destination
+func (t *BaseTransferer) quoteTable(table schema.TableSchema) string {
+	if t.FlattenSchemas {
+		return t.Dialect.QuoteIdentifier(table.Name)
+	}
+	return t.Dialect.QuoteQualifiedIdentifier(table.Schema, table.Name)
+}
+
+// quoteSourceTable returns the schema-qualified name of a table, quoted for the source
+func (t *BaseTransferer) quoteSourceTable(table schema.TableSchema) string {
+	return t.sourceDialect().QuoteQualifiedIdentifier(table.Schema, table.SourceTableName())
+}
+
+// sourceTable returns the table reference to read a table's own rows from
+// Inheritance parents (PostgreSQL) would otherwise include their children's rows
+func (t *BaseTransferer) sourceTable(table schema.TableSchema) string {
+	if table.HasChildren {
+		return "ONLY " + t.quoteSourceTable(table)
+	}
+	return t.quoteSourceTable(table)
+}
+
 // EstimateRows counts rows in a table
-func (t *BaseTransferer) EstimateRows(db *sql.DB, table string) (int64, error) {
+func (t *BaseTransferer) EstimateRows(db *sql.DB, table schema.TableSchema) (int64, error) {
 	var count int64
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", t.Dialect.QuoteIdentifier(table))
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", t.sourceTable(table))
 	err := db.QueryRow(query).Scan(&count)
 	return count, err
 }
 
-// GetColumns returns column names for a table
-func (t *BaseTransferer) GetColumns(db *sql.DB, table string) ([]string, error) {
-	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 0", t.Dialect.QuoteIdentifier(table)))
+// CountDestinationRows counts the rows copied into a table on the destination
+func (t *BaseTransferer) CountDestinationRows(dest *sql.DB, table schema.TableSchema) (int64, error) {
+	var count int64
+	err := dest.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", t.quoteTable(table))).Scan(&count)
+	return count, err
+}
+
+// GetColumns returns the source names of a table's columns that can be copied
+// Generated columns are skipped since the destination computes them itself, and so are
+// columns the migration leaves out
+func (t *BaseTransferer) GetColumns(db *sql.DB, table schema.TableSchema) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 0", t.quoteSourceTable(table)))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return rows.Columns()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	generated := make(map[string]bool)
+	for _, col := range table.Columns {
+		if col.IsGenerated() {
+			generated[col.Name] = true
+		}
+	}
+	if len(generated) == 0 && len(table.OmittedColumns) == 0 {
+		return columns, nil
+	}
+
+	var copyable []string
+	for _, col := range columns {
+		if !generated[table.DestinationColumn(col)] && !table.IsOmitted(col) {
+			copyable = append(copyable, col)
+		}
+	}
+	return copyable, nil
 }
 
 // TransferTable copies all data from source to destination
 func (t *BaseTransferer) TransferTable(source, dest *sql.DB, table schema.TableSchema, batchSize int, dryRun bool, progressFn func(rows int64)) (*TransferStats, error) {
 	startTime := time.Now()
 	stats := &TransferStats{
-		TableName: table.Name,
+		TableName: table.QualifiedName(),
 	}
 
-	columns, err := t.GetColumns(source, table.Name)
+	columns, err := t.GetColumns(source, table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns: %w", err)
 	}
@@ -95,7 +157,7 @@ func (t *BaseTransferer) TransferTable(source, dest *sql.DB, table schema.TableS
 		return stats, nil
 	}
 
-	totalRows, err := t.EstimateRows(source, table.Name)
+	totalRows, err := t.EstimateRows(source, table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to estimate rows: %w", err)
 	}
@@ -106,43 +168,108 @@ func (t *BaseTransferer) TransferTable(source, dest *sql.DB, table schema.TableS
 		return stats, nil
 	}
 
+	// Partitioned tables (MySQL) are copied one partition at a time
+	if len(table.Partitions) > 0 {
+		copied, err := t.transferPartitions(source, dest, table, columns, batchSize, progressFn)
+		if err != nil {
+			return nil, err
+		}
+		stats.RowsCopied = copied
+		stats.Duration = time.Since(startTime)
+		return stats, nil
+	}
+
+	_, err = t.copyRows(source, dest, table, columns, t.sourceTable(table), batchSize, func(rows int64) {
+		stats.RowsCopied += rows
+		if progressFn != nil {
+			progressFn(stats.RowsCopied)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Duration = time.Since(startTime)
+	return stats, nil
+}
+
+// copyRows copies the rows of from (a table reference) into the destination table in batches
+// columns are source names; renamed columns are inserted under their destination names
+// onBatch is called with the number of rows in each batch once it's inserted
+func (t *BaseTransferer) copyRows(source, dest *sql.DB, table schema.TableSchema, columns []string, from string, batchSize int, onBatch func(rows int64)) (int64, error) {
 	// Build quoted column list
 	quotedCols := make([]string, len(columns))
 	for i, col := range columns {
-		quotedCols[i] = t.Dialect.QuoteIdentifier(col)
+		quotedCols[i] = t.sourceDialect().QuoteIdentifier(col)
 	}
 	colList := strings.Join(quotedCols, ", ")
-	quotedTable := t.Dialect.QuoteIdentifier(table.Name)
+
+	destColumns := make([]string, len(columns))
+	for i, col := range columns {
+		destColumns[i] = table.DestinationColumn(col)
+	}
+
+	// Destination columns in SELECT order, for converting values
+	var columnDefs []schema.ColumnDef
+	if t.ConvertValue != nil {
+		byName := make(map[string]schema.ColumnDef, len(table.Columns))
+		for _, col := range table.Columns {
+			byName[col.Name] = col
+		}
+		columnDefs = make([]schema.ColumnDef, len(columns))
+		for i, col := range destColumns {
+			columnDefs[i] = byName[col]
+		}
+	}
 
 	// Transfer in batches
+	var copied int64
 	offset := int64(0)
 	for {
 		query := fmt.Sprintf("SELECT %s FROM %s LIMIT %d OFFSET %d",
-			colList, quotedTable, batchSize, offset)
+			colList, from, batchSize, offset)
 		rows, err := source.Query(query)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read from source: %w", err)
+			return copied, fmt.Errorf("failed to read from source: %w", err)
 		}
 
 		batch, err := t.collectBatch(rows, len(columns))
 		rows.Close()
 		if err != nil {
-			return nil, err
+			return copied, err
 		}
 
 		if len(batch) == 0 {
 			break
 		}
 
-		if err := t.InsertBatch(dest, table.Name, columns, batch); err != nil {
-			return nil, fmt.Errorf("failed to insert batch: %w", err)
+		if t.ConvertValue != nil {
+			for _, row := range batch {
+				for i, value := range row {
+					row[i] = t.ConvertValue(columnDefs[i], value)
+				}
+			}
+		}
+
+		if repair := t.repair[table.SourceQualifiedName()]; len(repair) > 0 {
+			for _, row := range batch {
+				for i, col := range columns {
+					if repair[col] {
+						row[i] = repairMojibake(row[i])
+					}
+				}
+			}
+		}
+
+		if err := t.InsertBatch(dest, table, destColumns, batch); err != nil {
+			return copied, fmt.Errorf("failed to insert batch: %w", err)
 		}
 
-		stats.RowsCopied += int64(len(batch))
+		copied += int64(len(batch))
 		offset += int64(len(batch))
 
-		if progressFn != nil {
-			progressFn(stats.RowsCopied)
+		if onBatch != nil {
+			onBatch(int64(len(batch)))
 		}
 
 		if int64(len(batch)) < int64(batchSize) {
@@ -150,8 +277,7 @@ func (t *BaseTransferer) TransferTable(source, dest *sql.DB, table schema.TableS
 		}
 	}
 
-	stats.Duration = time.Since(startTime)
-	return stats, nil
+	return copied, nil
 }
 
 func (t *BaseTransferer) collectBatch(rows *sql.Rows, numCols int) ([][]interface{}, error) {
@@ -171,7 +297,7 @@ func (t *BaseTransferer) collectBatch(rows *sql.Rows, numCols int) ([][]interfac
 }
 
 // InsertBatch inserts a batch of rows into the destination table
-func (t *BaseTransferer) InsertBatch(dest *sql.DB, table string, columns []string, batch [][]interface{}) error {
+func (t *BaseTransferer) InsertBatch(dest *sql.DB, table schema.TableSchema, columns []string, batch [][]interface{}) error {
 	if len(batch) == 0 {
 		return nil
 	}
@@ -195,9 +321,10 @@ func (t *BaseTransferer) InsertBatch(dest *sql.DB, table string, columns []strin
 		allPlaceholders = append(allPlaceholders, "("+strings.Join(rowPlaceholders, ", ")+")")
 	}
 
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
-		t.Dialect.QuoteIdentifier(table),
+	query := fmt.Sprintf("INSERT INTO %s (%s)%s VALUES %s",
+		t.quoteTable(table),
 		strings.Join(quotedCols, ", "),
+		overridingClause(table),
 		strings.Join(allPlaceholders, ", "))
 
 	var args []interface{}
@@ -208,3 +335,14 @@ func (t *BaseTransferer) InsertBatch(dest *sql.DB, table string, columns []strin
 	_, err := dest.Exec(query, args...)
 	return err
 }
+
+// overridingClause lets source values into GENERATED ALWAYS AS IDENTITY columns (PostgreSQL),
+// which otherwise reject explicit values
+func overridingClause(table schema.TableSchema) string {
+	for _, col := range table.Columns {
+		if col.Extra == "IDENTITY ALWAYS" {
+			return " OVERRIDING SYSTEM VALUE"
+		}
+	}
+	return ""
+}
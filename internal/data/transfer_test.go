@@ -10,32 +10,6 @@ import (
 	"github.com/DGarbs51/lcmigrate/internal/schema"
 )
 
-func TestNewTransferer(t *testing.T) {
-	tests := []struct {
-		engine   string
-		wantNil  bool
-		wantType string
-	}{
-		{"mysql", false, "*data.MySQLTransferer"},
-		{"pgsql", false, "*data.PostgresTransferer"},
-		{"unknown", true, ""},
-		{"", true, ""},
-	}
-
-	for _, tt := range tests {
-		tr := NewTransferer(tt.engine)
-		if tt.wantNil {
-			if tr != nil {
-				t.Errorf("NewTransferer(%q) = %v, want nil", tt.engine, tr)
-			}
-		} else {
-			if tr == nil {
-				t.Errorf("NewTransferer(%q) = nil, want non-nil", tt.engine)
-			}
-		}
-	}
-}
-
 func TestBaseTransferer_DisableForeignKeyChecks_MySQL(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -132,7 +106,7 @@ func TestBaseTransferer_EstimateRows(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"count"}).AddRow(int64(1000))
 	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `users`").WillReturnRows(rows)
 
-	count, err := bt.EstimateRows(db, "users")
+	count, err := bt.EstimateRows(db, schema.TableSchema{Name: "users"})
 	if err != nil {
 		t.Errorf("EstimateRows() error = %v", err)
 	}
@@ -157,7 +131,7 @@ func TestBaseTransferer_GetColumns(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "name", "email"})
 	mock.ExpectQuery("SELECT \\* FROM `users` LIMIT 0").WillReturnRows(rows)
 
-	columns, err := bt.GetColumns(db, "users")
+	columns, err := bt.GetColumns(db, schema.TableSchema{Name: "users"})
 	if err != nil {
 		t.Errorf("GetColumns() error = %v", err)
 	}
@@ -176,6 +150,59 @@ func TestBaseTransferer_GetColumns(t *testing.T) {
 	}
 }
 
+func TestBaseTransferer_GetColumns_SkipsGenerated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	bt := &BaseTransferer{Dialect: &dialect.PostgresDialect{}}
+
+	rows := sqlmock.NewRows([]string{"id", "price", "qty", "total"})
+	mock.ExpectQuery(`SELECT \* FROM "public"."items" LIMIT 0`).WillReturnRows(rows)
+
+	table := schema.TableSchema{
+		Schema: "public",
+		Name:   "items",
+		Columns: []schema.ColumnDef{
+			{Name: "id"},
+			{Name: "price"},
+			{Name: "qty"},
+			{Name: "total", Extra: "STORED GENERATED"},
+		},
+	}
+	columns, err := bt.GetColumns(db, table)
+	if err != nil {
+		t.Fatalf("GetColumns() error = %v", err)
+	}
+	if strings.Join(columns, ",") != "id,price,qty" {
+		t.Errorf("GetColumns() = %v, want generated column skipped", columns)
+	}
+}
+
+func TestBaseTransferer_EstimateRows_InheritanceParent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	bt := &BaseTransferer{Dialect: &dialect.PostgresDialect{}}
+
+	// Children's rows are copied with the children, so only the parent's own rows count
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM ONLY "public"."cities"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := bt.EstimateRows(db, schema.TableSchema{Schema: "public", Name: "cities", HasChildren: true})
+	if err != nil {
+		t.Fatalf("EstimateRows() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("EstimateRows() = %d, want 3", count)
+	}
+}
+
 func TestBaseTransferer_InsertBatch_MySQL(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -196,7 +223,7 @@ func TestBaseTransferer_InsertBatch_MySQL(t *testing.T) {
 		WithArgs(1, "Alice", 2, "Bob").
 		WillReturnResult(sqlmock.NewResult(2, 2))
 
-	err = bt.InsertBatch(db, "users", columns, batch)
+	err = bt.InsertBatch(db, schema.TableSchema{Name: "users"}, columns, batch)
 	if err != nil {
 		t.Errorf("InsertBatch() error = %v", err)
 	}
@@ -226,8 +253,35 @@ func TestBaseTransferer_InsertBatch_Postgres(t *testing.T) {
 		WithArgs(1, "Alice", 2, "Bob").
 		WillReturnResult(sqlmock.NewResult(2, 2))
 
-	err = bt.InsertBatch(db, "users", columns, batch)
+	err = bt.InsertBatch(db, schema.TableSchema{Name: "users"}, columns, batch)
+	if err != nil {
+		t.Errorf("InsertBatch() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestBaseTransferer_InsertBatch_IdentityAlways(t *testing.T) {
+	db, mock, err := sqlmock.New()
 	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	bt := &BaseTransferer{Dialect: &dialect.PostgresDialect{}}
+	table := schema.TableSchema{
+		Name:    "orders",
+		Columns: []schema.ColumnDef{{Name: "id", Extra: "IDENTITY ALWAYS"}, {Name: "code"}},
+	}
+
+	// GENERATED ALWAYS identity columns only accept source values with OVERRIDING SYSTEM VALUE
+	mock.ExpectExec(`INSERT INTO "orders" \("id", "code"\) OVERRIDING SYSTEM VALUE VALUES \(\$1, \$2\)`).
+		WithArgs(7, "A-7").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := bt.InsertBatch(db, table, []string{"id", "code"}, [][]interface{}{{7, "A-7"}}); err != nil {
 		t.Errorf("InsertBatch() error = %v", err)
 	}
 
@@ -246,7 +300,7 @@ func TestBaseTransferer_InsertBatch_EmptyBatch(t *testing.T) {
 	bt := &BaseTransferer{Dialect: &dialect.MySQLDialect{}}
 
 	// Empty batch should return nil without executing anything
-	err = bt.InsertBatch(db, "users", []string{"id"}, [][]interface{}{})
+	err = bt.InsertBatch(db, schema.TableSchema{Name: "users"}, []string{"id"}, [][]interface{}{})
 	if err != nil {
 		t.Errorf("InsertBatch() with empty batch error = %v", err)
 	}
@@ -387,6 +441,54 @@ func TestBaseTransferer_TransferTable_WithData(t *testing.T) {
 	}
 }
 
+func TestBaseTransferer_TransferTable_MappedTable(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, destMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	bt := &BaseTransferer{Dialect: &dialect.MySQLDialect{}}
+
+	sourceMock.ExpectQuery("SELECT \\* FROM `tbl_users` LIMIT 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "fullname", "legacy_flag"}))
+	sourceMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `tbl_users`").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	sourceMock.ExpectQuery("SELECT `id`, `fullname` FROM `tbl_users` LIMIT 100 OFFSET 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "fullname"}).AddRow(1, "Alice"))
+	destMock.ExpectExec("INSERT INTO `users` \\(`id`, `name`\\) VALUES \\(\\?, \\?\\)").
+		WithArgs(1, "Alice").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	table := schema.TableSchema{
+		Name:           "users",
+		SourceName:     "tbl_users",
+		ColumnRenames:  map[string]string{"fullname": "name"},
+		OmittedColumns: []string{"legacy_flag"},
+	}
+
+	stats, err := bt.TransferTable(sourceDB, destDB, table, 100, false, nil)
+	if err != nil {
+		t.Fatalf("TransferTable() error = %v", err)
+	}
+	if stats.RowsCopied != 1 {
+		t.Errorf("stats.RowsCopied = %d, want 1", stats.RowsCopied)
+	}
+
+	if err := sourceMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("source expectations not met: %v", err)
+	}
+	if err := destMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("dest expectations not met: %v", err)
+	}
+}
+
 func TestBaseTransferer_TransferTable_MultipleBatches(t *testing.T) {
 	sourceDB, sourceMock, err := sqlmock.New()
 	if err != nil {
@@ -553,7 +655,7 @@ func TestBaseTransferer_EstimateRows_Postgres(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"count"}).AddRow(int64(500))
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "users"`).WillReturnRows(rows)
 
-	count, err := bt.EstimateRows(db, "users")
+	count, err := bt.EstimateRows(db, schema.TableSchema{Name: "users"})
 	if err != nil {
 		t.Errorf("EstimateRows() error = %v", err)
 	}
@@ -578,7 +680,7 @@ func TestBaseTransferer_GetColumns_Postgres(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "email", "created_at"})
 	mock.ExpectQuery(`SELECT \* FROM "users" LIMIT 0`).WillReturnRows(rows)
 
-	columns, err := bt.GetColumns(db, "users")
+	columns, err := bt.GetColumns(db, schema.TableSchema{Name: "users"})
 	if err != nil {
 		t.Errorf("GetColumns() error = %v", err)
 	}
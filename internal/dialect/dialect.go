@@ -12,13 +12,17 @@ const (
 
 // Dialect defines engine-specific SQL syntax rules
 type Dialect interface {
-	// Name returns the dialect identifier ("mysql" or "pgsql")
+	// Name returns the dialect identifier ("mysql", "mariadb", "pgsql" or "sqlite")
 	Name() string
 
 	// QuoteIdentifier quotes a table/column name for the engine
 	// MySQL: `identifier`, PostgreSQL: "identifier"
 	QuoteIdentifier(name string) string
 
+	// QuoteQualifiedIdentifier quotes a schema-qualified name
+	// An empty schema returns the quoted name alone
+	QuoteQualifiedIdentifier(schema, name string) string
+
 	// QuoteLiteral quotes a string literal value
 	QuoteLiteral(value string) string
 
@@ -42,15 +46,3 @@ type Dialect interface {
 	// MySQL: "RESTRICT", PostgreSQL: "NO ACTION"
 	DefaultFKAction() string
 }
-
-// New returns the appropriate dialect for the engine name
-func New(engine string) Dialect {
-	switch engine {
-	case "mysql":
-		return &MySQLDialect{}
-	case "pgsql":
-		return &PostgresDialect{}
-	default:
-		return nil
-	}
-}
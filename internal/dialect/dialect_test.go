@@ -2,34 +2,6 @@ package dialect
 
 import "testing"
 
-func TestNew(t *testing.T) {
-	tests := []struct {
-		engine   string
-		wantName string
-		wantNil  bool
-	}{
-		{"mysql", "mysql", false},
-		{"pgsql", "pgsql", false},
-		{"unknown", "", true},
-		{"", "", true},
-	}
-
-	for _, tt := range tests {
-		d := New(tt.engine)
-		if tt.wantNil {
-			if d != nil {
-				t.Errorf("New(%q) = %v, want nil", tt.engine, d)
-			}
-		} else {
-			if d == nil {
-				t.Errorf("New(%q) = nil, want non-nil", tt.engine)
-			} else if d.Name() != tt.wantName {
-				t.Errorf("New(%q).Name() = %q, want %q", tt.engine, d.Name(), tt.wantName)
-			}
-		}
-	}
-}
-
 func TestMySQLDialect_QuoteIdentifier(t *testing.T) {
 	d := &MySQLDialect{}
 	tests := []struct {
@@ -198,6 +170,16 @@ func TestPostgresDialect_DefaultFKAction(t *testing.T) {
 	}
 }
 
+func TestSQLiteDialect_FKChecksSQL(t *testing.T) {
+	d := &SQLiteDialect{}
+	if d.DisableFKChecksSQL() != "PRAGMA foreign_keys = OFF" {
+		t.Errorf("DisableFKChecksSQL() = %q, want PRAGMA foreign_keys = OFF", d.DisableFKChecksSQL())
+	}
+	if d.EnableFKChecksSQL() != "PRAGMA foreign_keys = ON" {
+		t.Errorf("EnableFKChecksSQL() = %q, want PRAGMA foreign_keys = ON", d.EnableFKChecksSQL())
+	}
+}
+
 func TestMySQLDialect_Name(t *testing.T) {
 	d := &MySQLDialect{}
 	if d.Name() != "mysql" {
@@ -205,9 +187,45 @@ func TestMySQLDialect_Name(t *testing.T) {
 	}
 }
 
+func TestMariaDBDialect(t *testing.T) {
+	d := &MariaDBDialect{}
+	if d.Name() != "mariadb" {
+		t.Errorf("Name() = %q, want mariadb", d.Name())
+	}
+	if got := d.QuoteIdentifier("order"); got != "`order`" {
+		t.Errorf("QuoteIdentifier() = %q, want `order`", got)
+	}
+	if got := d.Placeholder(2); got != "?" {
+		t.Errorf("Placeholder(2) = %q, want ?", got)
+	}
+}
+
 func TestPostgresDialect_Name(t *testing.T) {
 	d := &PostgresDialect{}
 	if d.Name() != "pgsql" {
 		t.Errorf("Name() = %q, want pgsql", d.Name())
 	}
 }
+
+func TestQuoteQualifiedIdentifier(t *testing.T) {
+	tests := []struct {
+		d      Dialect
+		schema string
+		name   string
+		want   string
+	}{
+		{&MySQLDialect{}, "", "users", "`users`"},
+		{&MySQLDialect{}, "app", "users", "`app`.`users`"},
+		{&PostgresDialect{}, "", "users", `"users"`},
+		{&PostgresDialect{}, "audit", "log", `"audit"."log"`},
+		{&PostgresDialect{}, `we"ird`, "t", `"we""ird"."t"`},
+		{&SQLiteDialect{}, "", "users", `"users"`},
+		{&SQLiteDialect{}, "main", "users", `"main"."users"`},
+	}
+	for _, tt := range tests {
+		got := tt.d.QuoteQualifiedIdentifier(tt.schema, tt.name)
+		if got != tt.want {
+			t.Errorf("%s.QuoteQualifiedIdentifier(%q, %q) = %q, want %q", tt.d.Name(), tt.schema, tt.name, got, tt.want)
+		}
+	}
+}
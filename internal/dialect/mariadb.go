@@ -0,0 +1,12 @@
+package dialect
+
+// MariaDBDialect implements Dialect for MariaDB databases
+// MariaDB quotes, binds parameters and toggles foreign key checks as MySQL does
+type MariaDBDialect struct {
+	MySQLDialect
+}
+
+// Name returns "mariadb"
+func (d *MariaDBDialect) Name() string {
+	return "mariadb"
+}
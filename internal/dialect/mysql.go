@@ -15,6 +15,14 @@ func (d *MySQLDialect) QuoteIdentifier(name string) string {
 	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
 }
 
+// QuoteQualifiedIdentifier quotes each part of a schema.name pair
+func (d *MySQLDialect) QuoteQualifiedIdentifier(schema, name string) string {
+	if schema == "" {
+		return d.QuoteIdentifier(name)
+	}
+	return d.QuoteIdentifier(schema) + "." + d.QuoteIdentifier(name)
+}
+
 // QuoteLiteral wraps the value in single quotes with escaping
 func (d *MySQLDialect) QuoteLiteral(value string) string {
 	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
@@ -0,0 +1,60 @@
+package dialect
+
+import "strings"
+
+// SQLiteDialect implements Dialect for SQLite databases, which are only read from
+type SQLiteDialect struct{}
+
+// Name returns "sqlite"
+func (d *SQLiteDialect) Name() string {
+	return "sqlite"
+}
+
+// QuoteIdentifier wraps the identifier in double quotes
+func (d *SQLiteDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteQualifiedIdentifier quotes each part of a schema.name pair
+// The schema is an attached database name, such as main
+func (d *SQLiteDialect) QuoteQualifiedIdentifier(schema, name string) string {
+	if schema == "" {
+		return d.QuoteIdentifier(name)
+	}
+	return d.QuoteIdentifier(schema) + "." + d.QuoteIdentifier(name)
+}
+
+// QuoteLiteral wraps the value in single quotes with escaping
+func (d *SQLiteDialect) QuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// Placeholder always returns "?" for SQLite
+func (d *SQLiteDialect) Placeholder(position int) string {
+	return "?"
+}
+
+// PlaceholderStyle returns PlaceholderQuestion for SQLite
+func (d *SQLiteDialect) PlaceholderStyle() PlaceholderStyle {
+	return PlaceholderQuestion
+}
+
+// DisableFKChecksSQL returns the SQLite pragma to disable FK enforcement
+func (d *SQLiteDialect) DisableFKChecksSQL() string {
+	return "PRAGMA foreign_keys = OFF"
+}
+
+// EnableFKChecksSQL returns the SQLite pragma to enable FK enforcement
+func (d *SQLiteDialect) EnableFKChecksSQL() string {
+	return "PRAGMA foreign_keys = ON"
+}
+
+// SupportsSequences returns false for SQLite (rowid aliases and AUTOINCREMENT instead)
+func (d *SQLiteDialect) SupportsSequences() bool {
+	return false
+}
+
+// DefaultFKAction returns "NO ACTION" for SQLite
+func (d *SQLiteDialect) DefaultFKAction() string {
+	return "NO ACTION"
+}
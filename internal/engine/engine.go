@@ -0,0 +1,230 @@
+// Package engine is the registry of the database engines lcmigrate migrates from and to
+// Each engine registers how it connects, describes and wipes a database, which dialect,
+// extractor, applier and transferer the migration stages use for it, and how its objects and
+// rows are carried into the other engines it migrates to
+package engine
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/data"
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+// Engine is a registered database engine
+// Dialect, NewExtractor, NewTransferer, Connect and Info are required; the destination hooks
+// (NewApplier, CreateDatabase and Wipe) are nil for engines that can only be migrated from,
+// and Analyze is nil when the analyze command doesn't support the engine
+type Engine struct {
+	Name   string // Engine identifier, as config.NormalizeEngine returns it
+	Family string // Engine whose protocol and SQL dialect this one speaks, e.g. mysql for mariadb
+	File   bool   // The database is a local file rather than a server
+
+	// Sequences is set when the engine has sequences of its own for the sequences stage to set
+	Sequences bool
+
+	Dialect       dialect.Dialect
+	NewExtractor  func() schema.Extractor
+	NewTransferer func() data.Transferer
+
+	// Connect opens a connection, returning a *DatabaseNotExistsError when the server is
+	// reachable but the database is missing
+	Connect func(cfg config.DatabaseConfig) (*ConnectResult, error)
+
+	// Info reads the metadata pre-flight checks compare between source and destination
+	Info func(db *sql.DB, database string) (DatabaseInfo, error)
+
+	NewApplier     func() schema.Applier
+	CreateDatabase func(cfg config.DatabaseConfig) error
+	Wipe           func(db *sql.DB) error // Drops every object from the connected database
+
+	Analyze func(db *sql.DB, database string) error
+
+	// NewUpgrader returns the rewrites between two major versions of the engine, or nil when
+	// there are none; it's nil for engines without upgrade rules
+	NewUpgrader func(from, to int) *schema.Upgrader
+
+	// Targets are the other engines this one migrates into, by name
+	Targets map[string]Target
+}
+
+// Target builds what carries an engine's objects and rows into another engine
+type Target struct {
+	NewTranslator func(enumMode string) schema.Translator // nil when the DDL is copied as written
+	NewTransferer func() data.Transferer
+}
+
+// IsDestination reports whether the engine can be migrated into
+func (e *Engine) IsDestination() bool {
+	return e.NewApplier != nil
+}
+
+// ConnectResult contains the connection and metadata about how it was established
+type ConnectResult struct {
+	DB      *sql.DB
+	SSLMode string // For PostgreSQL: which SSL mode was used
+}
+
+// DatabaseInfo contains database metadata
+type DatabaseInfo struct {
+	Version      string
+	MajorVersion int
+	TableCount   int
+	ViewCount    int
+	TotalSize    int64
+	Tables       []string
+}
+
+// DatabaseNotExistsError indicates the target database doesn't exist
+type DatabaseNotExistsError struct {
+	Database string
+}
+
+func (e *DatabaseNotExistsError) Error() string {
+	return fmt.Sprintf("database %q does not exist", e.Database)
+}
+
+// registry holds the registered engines by name
+var registry = make(map[string]*Engine)
+
+// Register makes an engine available by its name
+// It panics when the name is empty or already registered, as database/sql.Register does
+func Register(e *Engine) {
+	if e == nil || e.Name == "" {
+		panic("engine: Register of an engine without a name")
+	}
+	if _, dup := registry[e.Name]; dup {
+		panic("engine: Register called twice for " + e.Name)
+	}
+	registry[e.Name] = e
+}
+
+// Get returns the registered engine with the given name
+func Get(name string) (*Engine, error) {
+	e, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database engine: %s", name)
+	}
+	return e, nil
+}
+
+// Names returns the names of the registered engines in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CanMigrate reports whether lcmigrate can migrate from one engine to another
+// An engine migrates into itself when it's a destination, and into the engines it targets
+func CanMigrate(source, dest string) bool {
+	e, ok := registry[source]
+	if !ok {
+		return false
+	}
+	if source == dest {
+		return e.IsDestination()
+	}
+	_, ok = e.Targets[dest]
+	return ok
+}
+
+// SameFamily reports whether two engines speak the same protocol and SQL dialect
+func SameFamily(a, b string) bool {
+	ea, ok := registry[a]
+	if !ok {
+		return false
+	}
+	eb, ok := registry[b]
+	if !ok {
+		return false
+	}
+	return ea.family() == eb.family()
+}
+
+// IsFile reports whether an engine's database is a local file rather than a server
+func IsFile(name string) bool {
+	e, ok := registry[name]
+	return ok && e.File
+}
+
+// NewTranslator returns the translator between two engines, or nil when they're the same
+// engine or the source's DDL is copied into the destination as written
+func NewTranslator(source, dest, enumMode string) schema.Translator {
+	t, ok := target(source, dest)
+	if !ok || t.NewTranslator == nil {
+		return nil
+	}
+	return t.NewTranslator(enumMode)
+}
+
+// NewTransferer returns the transferer that reads rows from one engine and writes them to
+// another, or nil when the engines can't be migrated between
+func NewTransferer(source, dest string) data.Transferer {
+	if source == dest {
+		if e, ok := registry[source]; ok && e.IsDestination() {
+			return e.NewTransferer()
+		}
+		return nil
+	}
+	t, ok := target(source, dest)
+	if !ok {
+		return nil
+	}
+	return t.NewTransferer()
+}
+
+// NewUpgrader returns the upgrader from one major version of the source engine to another of
+// the destination, or nil when there are no rewrites between them
+// Only versions of the same engine compare: MySQL 5.7 to MariaDB 10 isn't an upgrade to MySQL 8
+func NewUpgrader(source, dest string, from, to int) *schema.Upgrader {
+	e, ok := registry[source]
+	if !ok || source != dest || e.NewUpgrader == nil {
+		return nil
+	}
+	return e.NewUpgrader(from, to)
+}
+
+// target returns what the source engine registered for migrating into the destination
+func target(source, dest string) (Target, bool) {
+	e, ok := registry[source]
+	if !ok {
+		return Target{}, false
+	}
+	t, ok := e.Targets[dest]
+	return t, ok
+}
+
+// family returns the engine's family, which is its own name unless it joined another's
+func (e *Engine) family() string {
+	if e.Family != "" {
+		return e.Family
+	}
+	return e.Name
+}
+
+// extractMajorVersion extracts the major version number from a version string
+func extractMajorVersion(version string) int {
+	// MariaDB may report behind the "5.5.5-" prefix it gives old MySQL clients
+	if IsMariaDB(version) {
+		version = strings.TrimPrefix(version, "5.5.5-")
+	}
+	// Match patterns like "8.0.35", "PostgreSQL 16.2", "5.7.44-log"
+	re := regexp.MustCompile(`(\d+)\.(\d+)`)
+	matches := re.FindStringSubmatch(version)
+	if len(matches) >= 2 {
+		major, _ := strconv.Atoi(matches[1])
+		return major
+	}
+	return 0
+}
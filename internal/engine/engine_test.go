@@ -0,0 +1,527 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+// TestConformance checks every registered engine against the contract the migration stages
+// rely on; a new engine must pass it before it can be selected
+func TestConformance(t *testing.T) {
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			e, err := Get(name)
+			if err != nil {
+				t.Fatalf("Get(%q) error = %v", name, err)
+			}
+			if e.Name != name || config.NormalizeEngine(name) != name {
+				t.Errorf("engine %q is registered as %q, want the normalized name", e.Name, name)
+			}
+
+			// Required hooks
+			if e.Dialect == nil || e.NewExtractor == nil || e.NewTransferer == nil || e.Connect == nil || e.Info == nil {
+				t.Fatalf("engine %q is missing a required hook: %+v", name, e)
+			}
+			if e.Dialect.Name() != name {
+				t.Errorf("Dialect.Name() = %q, want %q", e.Dialect.Name(), name)
+			}
+			if e.NewExtractor() == nil {
+				t.Error("NewExtractor() = nil")
+			}
+			if e.NewTransferer() == nil {
+				t.Error("NewTransferer() = nil")
+			}
+
+			// Destination hooks come together, and a destination can be migrated into from itself
+			if e.IsDestination() {
+				if e.NewApplier() == nil || e.CreateDatabase == nil || e.Wipe == nil {
+					t.Errorf("destination engine %q is missing CreateDatabase or Wipe, or its applier is nil", name)
+				}
+				if !CanMigrate(name, name) {
+					t.Errorf("CanMigrate(%q, %q) = false for a destination engine", name, name)
+				}
+			} else if e.CreateDatabase != nil || e.Wipe != nil {
+				t.Errorf("engine %q has destination hooks without an applier", name)
+			}
+
+			// Every target is a registered destination with a transferer
+			for dest, target := range e.Targets {
+				d, err := Get(dest)
+				if err != nil || !d.IsDestination() || dest == name {
+					t.Errorf("engine %q targets %q, which isn't another destination engine", name, dest)
+				}
+				if target.NewTransferer == nil || target.NewTransferer() == nil {
+					t.Errorf("engine %q has no transferer for %q", name, dest)
+				}
+			}
+			if e.Family != "" {
+				if _, err := Get(e.Family); err != nil {
+					t.Errorf("engine %q joins the unregistered family %q", name, e.Family)
+				}
+			}
+
+			// Server engines have a default port to prompt with
+			if !e.File && config.DefaultPort(name) == "" {
+				t.Errorf("config.DefaultPort(%q) is empty", name)
+			}
+
+			// Failures are returned instead of partial results
+			db, _, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock: %v", err)
+			}
+			defer db.Close()
+			if _, err := e.Info(db, "testdb"); err == nil {
+				t.Error("Info() on a failing connection error = nil")
+			}
+			if _, err := e.NewExtractor().ExtractTables(db, "testdb"); err == nil {
+				t.Error("ExtractTables() on a failing connection error = nil")
+			}
+			if e.Wipe != nil {
+				if err := e.Wipe(db); err == nil {
+					t.Error("Wipe() on a failing connection error = nil")
+				}
+			}
+			if e.Analyze != nil {
+				if err := e.Analyze(db, "testdb"); err == nil {
+					t.Error("Analyze() on a failing connection error = nil")
+				}
+			}
+
+			unreachable := config.DatabaseConfig{
+				Engine:   name,
+				Host:     "127.0.0.1",
+				Port:     "1",
+				Database: filepath.Join(t.TempDir(), "missing"),
+				User:     "lcmigrate",
+			}
+			if result, err := e.Connect(unreachable); err == nil {
+				result.DB.Close()
+				t.Error("Connect() to an unreachable database error = nil")
+			}
+		})
+	}
+}
+
+func TestCanMigrate(t *testing.T) {
+	tests := []struct {
+		source, dest string
+		want         bool
+	}{
+		{"mysql", "mysql", true},
+		{"pgsql", "pgsql", true},
+		{"mysql", "pgsql", true},
+		{"pgsql", "mysql", true},
+		{"mariadb", "mariadb", true},
+		{"mysql", "mariadb", true},
+		{"mariadb", "mysql", true},
+		{"mariadb", "pgsql", true},
+		{"pgsql", "mariadb", true},
+		{"sqlite", "mysql", true},
+		{"sqlite", "mariadb", true},
+		{"sqlite", "pgsql", true},
+		{"sqlite", "sqlite", false},
+		{"mysql", "sqlite", false},
+		{"pgsql", "sqlite", false},
+		{"mysql", "oracle", false},
+		{"oracle", "mysql", false},
+	}
+	for _, tt := range tests {
+		if got := CanMigrate(tt.source, tt.dest); got != tt.want {
+			t.Errorf("CanMigrate(%q, %q) = %v, want %v", tt.source, tt.dest, got, tt.want)
+		}
+	}
+}
+
+func TestSameFamily(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"mysql", "mysql", true},
+		{"mysql", "mariadb", true},
+		{"mariadb", "mysql", true},
+		{"pgsql", "pgsql", true},
+		{"mysql", "pgsql", false},
+		{"sqlite", "mysql", false},
+		{"oracle", "oracle", false},
+	}
+	for _, tt := range tests {
+		if got := SameFamily(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameFamily(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIsFile(t *testing.T) {
+	if !IsFile("sqlite") {
+		t.Error("IsFile(sqlite) = false, want true")
+	}
+	for _, name := range []string{"mysql", "mariadb", "pgsql", "oracle"} {
+		if IsFile(name) {
+			t.Errorf("IsFile(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestNewTranslator(t *testing.T) {
+	tests := []struct {
+		source, dest string
+		want         string
+	}{
+		{"mysql", "mysql", "<nil>"},
+		{"pgsql", "pgsql", "<nil>"},
+		{"mysql", "pgsql", "*schema.MySQLToPostgresTranslator"},
+		{"pgsql", "mysql", "*schema.PostgresToMySQLTranslator"},
+		{"sqlite", "mysql", "*schema.SQLiteTranslator"},
+		{"sqlite", "pgsql", "*schema.SQLiteTranslator"},
+		{"mariadb", "mariadb", "<nil>"},
+		{"mysql", "mariadb", "<nil>"},
+		{"mariadb", "mysql", "*schema.MariaDBToMySQLTranslator"},
+		{"mariadb", "pgsql", "*schema.MySQLToPostgresTranslator"},
+		{"pgsql", "mariadb", "*schema.PostgresToMySQLTranslator"},
+		{"sqlite", "mariadb", "*schema.SQLiteTranslator"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%T", NewTranslator(tt.source, tt.dest, "")); got != tt.want {
+			t.Errorf("NewTranslator(%q, %q) = %s, want %s", tt.source, tt.dest, got, tt.want)
+		}
+	}
+
+	if tr, ok := NewTranslator("mariadb", "pgsql", "").(*schema.MySQLToPostgresTranslator); !ok || !tr.MariaDB {
+		t.Error("NewTranslator(mariadb, pgsql) should translate MariaDB's column defaults")
+	}
+	for _, dest := range []string{"mysql", "mariadb", "pgsql"} {
+		want := dest
+		if dest == "mariadb" {
+			want = "mysql"
+		}
+		if tr, ok := NewTranslator("sqlite", dest, "").(*schema.SQLiteTranslator); !ok || tr.Dest != want {
+			t.Errorf("NewTranslator(sqlite, %s) should return a *SQLiteTranslator for %s", dest, want)
+		}
+	}
+}
+
+func TestNewTransferer(t *testing.T) {
+	tests := []struct {
+		source, dest string
+		want         string
+	}{
+		{"mysql", "mysql", "*data.MySQLTransferer"},
+		{"pgsql", "pgsql", "*data.PostgresTransferer"},
+		{"mysql", "pgsql", "*data.MySQLToPostgresTransferer"},
+		{"pgsql", "mysql", "*data.PostgresToMySQLTransferer"},
+		{"sqlite", "mysql", "*data.SQLiteTransferer"},
+		{"sqlite", "pgsql", "*data.SQLiteTransferer"},
+		{"mysql", "sqlite", "<nil>"},
+		{"sqlite", "sqlite", "<nil>"},
+		{"mariadb", "mariadb", "*data.MySQLTransferer"},
+		{"mariadb", "mysql", "*data.MySQLTransferer"},
+		{"mysql", "mariadb", "*data.MySQLTransferer"},
+		{"mariadb", "pgsql", "*data.MySQLToPostgresTransferer"},
+		{"pgsql", "mariadb", "*data.PostgresToMySQLTransferer"},
+		{"sqlite", "mariadb", "*data.SQLiteTransferer"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%T", NewTransferer(tt.source, tt.dest)); got != tt.want {
+			t.Errorf("NewTransferer(%q, %q) = %s, want %s", tt.source, tt.dest, got, tt.want)
+		}
+	}
+}
+
+func TestNewUpgrader(t *testing.T) {
+	tests := []struct {
+		source, dest string
+		from, to     int
+		want         bool
+	}{
+		{"mysql", "mysql", 5, 8, true},
+		{"mysql", "mysql", 8, 8, false},
+		{"pgsql", "pgsql", 11, 16, true},
+		{"mariadb", "mariadb", 10, 11, false},
+		{"sqlite", "sqlite", 3, 3, false},
+		{"oracle", "oracle", 11, 19, false},
+		// MariaDB's versions don't follow MySQL's, and it has no utf8mb4_0900 collations
+		{"mysql", "mariadb", 5, 10, false},
+		{"mysql", "mariadb", 5, 11, false},
+		{"mariadb", "mysql", 5, 8, false},
+		{"mysql", "pgsql", 5, 16, false},
+	}
+	for _, tt := range tests {
+		if got := NewUpgrader(tt.source, tt.dest, tt.from, tt.to) != nil; got != tt.want {
+			t.Errorf("NewUpgrader(%q, %q, %d, %d) != nil = %v, want %v", tt.source, tt.dest, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestGet_UnsupportedEngine(t *testing.T) {
+	for _, name := range []string{"unsupported", "", "postgres"} {
+		if _, err := Get(name); err == nil {
+			t.Errorf("Get(%q) error = nil, want an error", name)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	want := []string{"mariadb", "mysql", "pgsql", "sqlite"}
+	if got := Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() of a registered name didn't panic")
+		}
+	}()
+	Register(&Engine{Name: "mysql"})
+}
+
+func TestRegister_NoName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() without a name didn't panic")
+		}
+	}()
+	Register(&Engine{})
+}
+
+func TestExtractMajorVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"8.0.35", 8},
+		{"5.7.44-log", 5},
+		{"PostgreSQL 16.2 (Ubuntu 16.2-1.pgdg22.04+1)", 16},
+		{"PostgreSQL 15.4", 15},
+		{"10.6.0-MariaDB", 10},
+		{"invalid", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		got := extractMajorVersion(tt.version)
+		if got != tt.want {
+			t.Errorf("extractMajorVersion(%q) = %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestExtractMajorVersion_MySQL(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"8.0.35-0ubuntu0.22.04.1", 8},
+		{"5.7.44-log", 5},
+		{"10.6.14-MariaDB-1:10.6.14+maria~ubu2204", 10},
+		{"5.5.5-10.11.6-MariaDB", 10},
+	}
+
+	for _, tt := range tests {
+		got := extractMajorVersion(tt.version)
+		if got != tt.want {
+			t.Errorf("extractMajorVersion(%q) = %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestExtractMajorVersion_Postgres(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"PostgreSQL 16.2 (Ubuntu 16.2-1.pgdg22.04+1) on x86_64-pc-linux-gnu", 16},
+		{"PostgreSQL 15.4 on x86_64-pc-linux-gnu", 15},
+		{"PostgreSQL 14.8", 14},
+	}
+
+	for _, tt := range tests {
+		got := extractMajorVersion(tt.version)
+		if got != tt.want {
+			t.Errorf("extractMajorVersion(%q) = %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestExtractMajorVersion_EdgeCases(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"", 0},
+		{"invalid", 0},
+		{"no version here", 0},
+		{"v1.2.3", 1},
+		{"1", 0}, // Need at least major.minor
+		{"1.", 0},
+		{"abc.def", 0},
+	}
+
+	for _, tt := range tests {
+		got := extractMajorVersion(tt.version)
+		if got != tt.want {
+			t.Errorf("extractMajorVersion(%q) = %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestDatabaseNotExistsError(t *testing.T) {
+	err := &DatabaseNotExistsError{Database: "mydb"}
+	expected := `database "mydb" does not exist`
+
+	if err.Error() != expected {
+		t.Errorf("DatabaseNotExistsError.Error() = %q, want %q", err.Error(), expected)
+	}
+}
+
+func TestDatabaseNotExistsError_ErrorMethod(t *testing.T) {
+	tests := []struct {
+		database string
+		want     string
+	}{
+		{"mydb", `database "mydb" does not exist`},
+		{"test_database", `database "test_database" does not exist`},
+		{"", `database "" does not exist`},
+	}
+
+	for _, tt := range tests {
+		err := &DatabaseNotExistsError{Database: tt.database}
+		if err.Error() != tt.want {
+			t.Errorf("DatabaseNotExistsError{%q}.Error() = %q, want %q", tt.database, err.Error(), tt.want)
+		}
+	}
+}
+
+func TestDatabaseNotExistsError_Types(t *testing.T) {
+	err := &DatabaseNotExistsError{Database: "testdb"}
+
+	// Test that it implements error interface
+	var e error = err
+	if e.Error() != `database "testdb" does not exist` {
+		t.Errorf("Error() = %q, want %q", e.Error(), `database "testdb" does not exist`)
+	}
+
+	// Test errors.As
+	var dbErr *DatabaseNotExistsError
+	if !errors.As(err, &dbErr) {
+		t.Errorf("errors.As() should match DatabaseNotExistsError")
+	}
+	if dbErr.Database != "testdb" {
+		t.Errorf("dbErr.Database = %q, want %q", dbErr.Database, "testdb")
+	}
+}
+
+func TestDatabaseInfo_Fields(t *testing.T) {
+	info := DatabaseInfo{
+		Version:      "8.0.35",
+		MajorVersion: 8,
+		TableCount:   10,
+		ViewCount:    2,
+		TotalSize:    1024 * 1024,
+		Tables:       []string{"users", "orders"},
+	}
+
+	if info.Version != "8.0.35" {
+		t.Errorf("DatabaseInfo.Version = %q, want %q", info.Version, "8.0.35")
+	}
+	if info.MajorVersion != 8 {
+		t.Errorf("DatabaseInfo.MajorVersion = %d, want %d", info.MajorVersion, 8)
+	}
+	if info.TableCount != 10 {
+		t.Errorf("DatabaseInfo.TableCount = %d, want %d", info.TableCount, 10)
+	}
+	if info.ViewCount != 2 {
+		t.Errorf("DatabaseInfo.ViewCount = %d, want %d", info.ViewCount, 2)
+	}
+	if len(info.Tables) != 2 {
+		t.Errorf("len(DatabaseInfo.Tables) = %d, want %d", len(info.Tables), 2)
+	}
+}
+
+func TestDatabaseInfo_TablesList(t *testing.T) {
+	info := DatabaseInfo{
+		Tables: []string{"users", "orders", "products"},
+	}
+
+	if len(info.Tables) != 3 {
+		t.Errorf("len(DatabaseInfo.Tables) = %d, want 3", len(info.Tables))
+	}
+
+	expectedTables := []string{"users", "orders", "products"}
+	for i, table := range info.Tables {
+		if table != expectedTables[i] {
+			t.Errorf("Tables[%d] = %q, want %q", i, table, expectedTables[i])
+		}
+	}
+}
+
+func TestDatabaseInfo_EmptyState(t *testing.T) {
+	info := DatabaseInfo{}
+
+	if info.Version != "" {
+		t.Errorf("DatabaseInfo.Version = %q, want empty", info.Version)
+	}
+	if info.MajorVersion != 0 {
+		t.Errorf("DatabaseInfo.MajorVersion = %d, want 0", info.MajorVersion)
+	}
+	if info.TableCount != 0 {
+		t.Errorf("DatabaseInfo.TableCount = %d, want 0", info.TableCount)
+	}
+	if info.ViewCount != 0 {
+		t.Errorf("DatabaseInfo.ViewCount = %d, want 0", info.ViewCount)
+	}
+	if info.TotalSize != 0 {
+		t.Errorf("DatabaseInfo.TotalSize = %d, want 0", info.TotalSize)
+	}
+	if len(info.Tables) != 0 {
+		t.Errorf("len(DatabaseInfo.Tables) = %d, want 0", len(info.Tables))
+	}
+}
+
+func TestConnectResult_Fields(t *testing.T) {
+	result := ConnectResult{
+		DB:      nil,
+		SSLMode: "prefer",
+	}
+
+	if result.SSLMode != "prefer" {
+		t.Errorf("ConnectResult.SSLMode = %q, want %q", result.SSLMode, "prefer")
+	}
+}
+
+func TestConnectResult_SSLModeField(t *testing.T) {
+	result := ConnectResult{
+		DB:      nil,
+		SSLMode: "require",
+	}
+
+	if result.SSLMode != "require" {
+		t.Errorf("ConnectResult.SSLMode = %q, want %q", result.SSLMode, "require")
+	}
+
+	result.SSLMode = "disable"
+	if result.SSLMode != "disable" {
+		t.Errorf("ConnectResult.SSLMode = %q, want %q", result.SSLMode, "disable")
+	}
+}
+
+func TestConnectResult_ZeroValue(t *testing.T) {
+	result := ConnectResult{}
+
+	if result.DB != nil {
+		t.Errorf("ConnectResult.DB = %v, want nil", result.DB)
+	}
+	if result.SSLMode != "" {
+		t.Errorf("ConnectResult.SSLMode = %q, want empty", result.SSLMode)
+	}
+}
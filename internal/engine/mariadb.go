@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/DGarbs51/lcmigrate/db"
+	"github.com/DGarbs51/lcmigrate/internal/data"
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+)
+
+// MariaDB connects, describes and creates databases as MySQL does; its extractor, applier and
+// wipe also handle sequences and system-versioned tables
+// It's translated for MySQL, whose DDL MariaDB takes as written, and as MySQL for PostgreSQL
+func init() {
+	Register(&Engine{
+		Name:           "mariadb",
+		Family:         "mysql",
+		Sequences:      true,
+		Dialect:        &dialect.MariaDBDialect{},
+		NewExtractor:   func() schema.Extractor { return schema.NewMariaDBExtractor() },
+		NewTransferer:  func() data.Transferer { return data.NewMariaDBTransferer() },
+		Connect:        connectMySQL,
+		Info:           mysqlDatabaseInfo,
+		NewApplier:     func() schema.Applier { return schema.NewMariaDBApplier() },
+		CreateDatabase: createMySQLDatabase,
+		Wipe:           wipeMariaDB,
+		Analyze:        db.AnalyzeMySQL,
+		Targets: map[string]Target{
+			"mysql": {
+				NewTranslator: func(string) schema.Translator { return schema.NewMariaDBToMySQLTranslator() },
+				NewTransferer: func() data.Transferer { return data.NewMySQLTransferer() },
+			},
+			"pgsql": {
+				NewTranslator: func(enumMode string) schema.Translator {
+					t := schema.NewMySQLToPostgresTranslator(enumMode)
+					t.MariaDB = true
+					return t
+				},
+				NewTransferer: func() data.Transferer { return data.NewMySQLToPostgresTransferer() },
+			},
+		},
+	})
+}
+
+// IsMariaDB reports whether a MySQL-protocol server's VERSION() is MariaDB's,
+// e.g. "10.11.6-MariaDB-1:10.11.6+maria~ubu2204"
+func IsMariaDB(version string) bool {
+	return strings.Contains(version, "MariaDB")
+}
+
+// Resolve returns the engine a server actually runs, given the engine it was configured as
+// and the version it reports
+// MySQL and MariaDB accept each other's clients, so either is often configured for the other
+func Resolve(name, version string) string {
+	switch {
+	case name == "mysql" && IsMariaDB(version):
+		return "mariadb"
+	case name == "mariadb" && version != "" && !IsMariaDB(version):
+		return "mysql"
+	default:
+		return name
+	}
+}
+
+// wipeMariaDB drops all tables, views, routines, events and sequences from the database
+func wipeMariaDB(db *sql.DB) error {
+	if err := wipeMySQL(db); err != nil {
+		return err
+	}
+
+	// Sequences go last, once no table default calls NEXTVAL on them
+	rows, err := db.Query(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_type = 'SEQUENCE'
+	`)
+	if err != nil {
+		return err
+	}
+	var sequences []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		sequences = append(sequences, name)
+	}
+	rows.Close()
+
+	for _, seq := range sequences {
+		if _, err := db.Exec(fmt.Sprintf("DROP SEQUENCE IF EXISTS `%s`", seq)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
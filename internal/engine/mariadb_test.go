@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestIsMariaDB(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"10.11.6-MariaDB-1:10.11.6+maria~ubu2204", true},
+		{"5.5.5-10.6.14-MariaDB", true},
+		{"8.0.35", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsMariaDB(tt.version); got != tt.want {
+			t.Errorf("IsMariaDB(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name, version, want string
+	}{
+		{"mysql", "8.0.35", "mysql"},
+		{"mysql", "10.11.6-MariaDB", "mariadb"},
+		{"mariadb", "10.11.6-MariaDB", "mariadb"},
+		{"mariadb", "8.0.35", "mysql"},
+		{"mariadb", "", "mariadb"},
+		{"pgsql", "PostgreSQL 16.2", "pgsql"},
+	}
+	for _, tt := range tests {
+		if got := Resolve(tt.name, tt.version); got != tt.want {
+			t.Errorf("Resolve(%q, %q) = %q, want %q", tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestWipeMariaDB(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables.*table_type IN \\('BASE TABLE', 'SYSTEM VERSIONED'\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("orders"))
+	mock.ExpectExec("DROP TABLE IF EXISTS `orders`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.views").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
+	mock.ExpectQuery("SELECT routine_type, routine_name.*FROM information_schema.routines").
+		WillReturnRows(sqlmock.NewRows([]string{"routine_type", "routine_name"}))
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Sequences are dropped after the tables whose defaults use them
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables.*table_type = 'SEQUENCE'").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("order_seq"))
+	mock.ExpectExec("DROP SEQUENCE IF EXISTS `order_seq`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := wipeMariaDB(db); err != nil {
+		t.Errorf("wipeMariaDB() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package engine
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/DGarbs51/lcmigrate/db"
+	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/data"
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func init() {
+	Register(&Engine{
+		Name:           "mysql",
+		Dialect:        &dialect.MySQLDialect{},
+		NewExtractor:   func() schema.Extractor { return schema.NewMySQLExtractor() },
+		NewTransferer:  func() data.Transferer { return data.NewMySQLTransferer() },
+		Connect:        connectMySQL,
+		Info:           mysqlDatabaseInfo,
+		NewApplier:     func() schema.Applier { return schema.NewMySQLApplier() },
+		CreateDatabase: createMySQLDatabase,
+		Wipe:           wipeMySQL,
+		Analyze:        db.AnalyzeMySQL,
+		NewUpgrader:    func(from, to int) *schema.Upgrader { return schema.NewMySQLUpgrader(from, to) },
+		Targets: map[string]Target{
+			"mariadb": {
+				NewTransferer: func() data.Transferer { return data.NewMariaDBTransferer() },
+			},
+			"pgsql": {
+				NewTranslator: func(enumMode string) schema.Translator { return schema.NewMySQLToPostgresTranslator(enumMode) },
+				NewTransferer: func() data.Transferer { return data.NewMySQLToPostgresTransferer() },
+			},
+		},
+	})
+}
+
+// isMySQLDatabaseNotExists checks if a MySQL error says the database doesn't exist
+func isMySQLDatabaseNotExists(err error) bool {
+	if err == nil {
+		return false
+	}
+	// MySQL error 1049: Unknown database
+	errStr := err.Error()
+	return strings.Contains(errStr, "1049") || strings.Contains(errStr, "Unknown database")
+}
+
+// connectMySQL establishes a MySQL connection
+func connectMySQL(cfg config.DatabaseConfig) (*ConnectResult, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		if isMySQLDatabaseNotExists(err) {
+			return nil, &DatabaseNotExistsError{Database: cfg.Database}
+		}
+		return nil, err
+	}
+
+	return &ConnectResult{DB: db}, nil
+}
+
+// createMySQLDatabase creates a MySQL database
+func createMySQLDatabase(cfg config.DatabaseConfig) error {
+	// Connect without specifying a database
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port)
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping server: %w", err)
+	}
+
+	// Create the database
+	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE `%s`", cfg.Database))
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+
+	return nil
+}
+
+// mysqlDatabaseInfo retrieves MySQL database metadata
+func mysqlDatabaseInfo(db *sql.DB, database string) (DatabaseInfo, error) {
+	info := DatabaseInfo{}
+
+	// Get version
+	var version string
+	if err := db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return info, fmt.Errorf("failed to get version: %w", err)
+	}
+	info.Version = version
+	info.MajorVersion = extractMajorVersion(version)
+
+	// Get table count and size
+	var tableCount int
+	var totalSize sql.NullFloat64
+	err := db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(data_length + index_length), 0)
+		FROM information_schema.tables
+		WHERE table_schema = ? AND table_type IN ('BASE TABLE', 'SYSTEM VERSIONED')
+	`, database).Scan(&tableCount, &totalSize)
+	if err != nil {
+		return info, fmt.Errorf("failed to get table info: %w", err)
+	}
+	info.TableCount = tableCount
+	info.TotalSize = int64(totalSize.Float64)
+
+	// Get view count
+	var viewCount int
+	err = db.QueryRow(`
+		SELECT COUNT(*)
+		FROM information_schema.views
+		WHERE table_schema = ?
+	`, database).Scan(&viewCount)
+	if err != nil {
+		return info, fmt.Errorf("failed to get view count: %w", err)
+	}
+	info.ViewCount = viewCount
+
+	// Get table names
+	rows, err := db.Query(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = ? AND table_type IN ('BASE TABLE', 'SYSTEM VERSIONED')
+		ORDER BY table_name
+	`, database)
+	if err != nil {
+		return info, fmt.Errorf("failed to get table names: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return info, err
+		}
+		info.Tables = append(info.Tables, name)
+	}
+
+	return info, nil
+}
+
+// wipeMySQL drops all tables, views, routines and events from the database
+func wipeMySQL(db *sql.DB) error {
+	// Disable foreign key checks
+	if _, err := db.Exec("SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return err
+	}
+
+	// Get all tables
+	rows, err := db.Query(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_type IN ('BASE TABLE', 'SYSTEM VERSIONED')
+	`)
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	// Drop tables
+	for _, table := range tables {
+		if _, err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS `%s`", table)); err != nil {
+			return err
+		}
+	}
+
+	// Get all views
+	rows, err = db.Query(`
+		SELECT table_name
+		FROM information_schema.views
+		WHERE table_schema = DATABASE()
+	`)
+	if err != nil {
+		return err
+	}
+	var views []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		views = append(views, name)
+	}
+	rows.Close()
+
+	// Drop views
+	for _, view := range views {
+		if _, err := db.Exec(fmt.Sprintf("DROP VIEW IF EXISTS `%s`", view)); err != nil {
+			return err
+		}
+	}
+
+	// Drop stored routines and events (triggers went with their tables)
+	rows, err = db.Query(`
+		SELECT routine_type, routine_name
+		FROM information_schema.routines
+		WHERE routine_schema = DATABASE()
+		UNION ALL
+		SELECT 'EVENT', event_name
+		FROM information_schema.events
+		WHERE event_schema = DATABASE()
+	`)
+	if err != nil {
+		return err
+	}
+	var routineDrops []string
+	for rows.Next() {
+		var kind, name string
+		if err := rows.Scan(&kind, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		routineDrops = append(routineDrops, fmt.Sprintf("DROP %s IF EXISTS `%s`", kind, name))
+	}
+	rows.Close()
+
+	for _, stmt := range routineDrops {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	// Re-enable foreign key checks
+	if _, err := db.Exec("SET FOREIGN_KEY_CHECKS = 1"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/DGarbs51/lcmigrate/internal/config"
+)
+
+func TestIsMySQLDatabaseNotExists(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("Error 1049: Unknown database 'testdb'"), true},
+		{errors.New("Unknown database 'testdb'"), true},
+		{errors.New("Error 1045: Access denied for user 'root'@'localhost'"), false},
+		{errors.New("connection refused"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := isMySQLDatabaseNotExists(tt.err); got != tt.want {
+			t.Errorf("isMySQLDatabaseNotExists(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestConnectMySQL(t *testing.T) {
+	cfg := config.DatabaseConfig{
+		Engine:   "mysql",
+		Host:     "localhost",
+		Port:     "3306",
+		Database: "testdb",
+		User:     "testuser",
+		Password: "testpass",
+	}
+
+	// This will fail to connect but we can test that it tries
+	_, err := connectMySQL(cfg)
+	// We expect an error since there's no actual database
+	if err == nil {
+		t.Logf("connectMySQL() succeeded (unexpected in test environment)")
+	}
+}
+
+func TestCreateMySQLDatabase(t *testing.T) {
+	cfg := config.DatabaseConfig{
+		Engine:   "mysql",
+		Host:     "localhost",
+		Port:     "3306",
+		Database: "newdb",
+		User:     "testuser",
+		Password: "testpass",
+	}
+
+	// This will fail but tests the code path
+	err := createMySQLDatabase(cfg)
+	if err == nil {
+		t.Logf("createMySQLDatabase() succeeded (unexpected in test environment)")
+	}
+}
+
+func TestMySQLDatabaseInfo(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// Mock VERSION() query
+	mock.ExpectQuery("SELECT VERSION\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.35"))
+
+	// Mock table count and size query
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE\\(SUM\\(data_length \\+ index_length\\), 0\\)").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "size"}).AddRow(5, 1024000))
+
+	// Mock view count query
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.views").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	// Mock table names query
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("users").AddRow("orders"))
+
+	info, err := mysqlDatabaseInfo(db, "testdb")
+	if err != nil {
+		t.Errorf("mysqlDatabaseInfo() error = %v", err)
+	}
+
+	if info.Version != "8.0.35" {
+		t.Errorf("info.Version = %q, want %q", info.Version, "8.0.35")
+	}
+	if info.MajorVersion != 8 {
+		t.Errorf("info.MajorVersion = %d, want 8", info.MajorVersion)
+	}
+	if info.TableCount != 5 {
+		t.Errorf("info.TableCount = %d, want 5", info.TableCount)
+	}
+	if info.ViewCount != 2 {
+		t.Errorf("info.ViewCount = %d, want 2", info.ViewCount)
+	}
+	if info.TotalSize != 1024000 {
+		t.Errorf("info.TotalSize = %d, want 1024000", info.TotalSize)
+	}
+	if len(info.Tables) != 2 {
+		t.Errorf("len(info.Tables) = %d, want 2", len(info.Tables))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLDatabaseInfo_VersionError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT VERSION\\(\\)").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	_, err = mysqlDatabaseInfo(db, "testdb")
+	if err == nil {
+		t.Errorf("mysqlDatabaseInfo() expected error, got nil")
+	}
+}
+
+func TestMySQLDatabaseInfo_TableCountError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT VERSION\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.35"))
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE").
+		WithArgs("testdb").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	_, err = mysqlDatabaseInfo(db, "testdb")
+	if err == nil {
+		t.Errorf("mysqlDatabaseInfo() expected error, got nil")
+	}
+}
+
+func TestMySQLDatabaseInfo_ViewCountError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT VERSION\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.35"))
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "size"}).AddRow(5, 1024000))
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.views").
+		WithArgs("testdb").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	_, err = mysqlDatabaseInfo(db, "testdb")
+	if err == nil {
+		t.Errorf("mysqlDatabaseInfo() expected error, got nil")
+	}
+}
+
+func TestMySQLDatabaseInfo_TableNamesError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT VERSION\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.35"))
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "size"}).AddRow(5, 1024000))
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.views").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
+		WithArgs("testdb").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	_, err = mysqlDatabaseInfo(db, "testdb")
+	if err == nil {
+		t.Errorf("mysqlDatabaseInfo() expected error, got nil")
+	}
+}
+
+func TestWipeMySQL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// Disable FK checks
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Query tables
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables.*table_type IN \\('BASE TABLE', 'SYSTEM VERSIONED'\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("users").AddRow("orders"))
+
+	// Drop tables
+	mock.ExpectExec("DROP TABLE IF EXISTS `users`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DROP TABLE IF EXISTS `orders`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Query views
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.views").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("user_stats"))
+
+	// Drop views
+	mock.ExpectExec("DROP VIEW IF EXISTS `user_stats`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Query routines and events
+	mock.ExpectQuery("SELECT routine_type, routine_name.*FROM information_schema.routines.*information_schema.events").
+		WillReturnRows(sqlmock.NewRows([]string{"routine_type", "routine_name"}).
+			AddRow("PROCEDURE", "archive_orders").
+			AddRow("EVENT", "nightly_archive"))
+
+	// Drop routines and events
+	mock.ExpectExec("DROP PROCEDURE IF EXISTS `archive_orders`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DROP EVENT IF EXISTS `nightly_archive`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Re-enable FK checks
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = wipeMySQL(db)
+	if err != nil {
+		t.Errorf("wipeMySQL() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestWipeMySQL_DisableFKError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	err = wipeMySQL(db)
+	if err == nil {
+		t.Errorf("wipeMySQL() expected error, got nil")
+	}
+}
+
+func TestWipeMySQL_QueryTablesError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	err = wipeMySQL(db)
+	if err == nil {
+		t.Errorf("wipeMySQL() expected error, got nil")
+	}
+}
+
+func TestWipeMySQL_DropTableError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("users"))
+
+	mock.ExpectExec("DROP TABLE IF EXISTS `users`").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	err = wipeMySQL(db)
+	if err == nil {
+		t.Errorf("wipeMySQL() expected error, got nil")
+	}
+}
+
+func TestWipeMySQL_QueryViewsError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
+
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.views").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	err = wipeMySQL(db)
+	if err == nil {
+		t.Errorf("wipeMySQL() expected error, got nil")
+	}
+}
@@ -0,0 +1,342 @@
+package engine
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/DGarbs51/lcmigrate/db"
+	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/data"
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+	_ "github.com/lib/pq"
+)
+
+func init() {
+	Register(&Engine{
+		Name:           "pgsql",
+		Sequences:      true,
+		Dialect:        &dialect.PostgresDialect{},
+		NewExtractor:   func() schema.Extractor { return schema.NewPostgresExtractor() },
+		NewTransferer:  func() data.Transferer { return data.NewPostgresTransferer() },
+		Connect:        connectPostgres,
+		Info:           postgresDatabaseInfo,
+		NewApplier:     func() schema.Applier { return schema.NewPostgresApplier() },
+		CreateDatabase: createPostgresDatabase,
+		Wipe:           wipePostgres,
+		Analyze:        db.AnalyzePostgres,
+		NewUpgrader:    func(from, to int) *schema.Upgrader { return schema.NewPostgresUpgrader(from, to) },
+		Targets: map[string]Target{
+			"mysql":   toMySQL,
+			"mariadb": toMySQL,
+		},
+	})
+}
+
+// toMySQL translates PostgreSQL for MySQL, and for MariaDB, which takes MySQL's DDL as written
+var toMySQL = Target{
+	NewTranslator: func(string) schema.Translator { return schema.NewPostgresToMySQLTranslator() },
+	NewTransferer: func() data.Transferer { return data.NewPostgresToMySQLTransferer() },
+}
+
+// isPostgresDatabaseNotExists checks if a PostgreSQL error says the database doesn't exist
+func isPostgresDatabaseNotExists(err error) bool {
+	if err == nil {
+		return false
+	}
+	// PostgreSQL error code 3D000: invalid_catalog_name (database does not exist)
+	errStr := err.Error()
+	return strings.Contains(errStr, "3D000") || strings.Contains(errStr, "does not exist")
+}
+
+// connectPostgres establishes a PostgreSQL connection with SSL fallback
+// Tries: require -> prefer -> disable
+func connectPostgres(cfg config.DatabaseConfig) (*ConnectResult, error) {
+	sslModes := []string{"require", "prefer", "disable"}
+	var lastErr error
+	var dbNotExists bool
+
+	for _, sslMode := range sslModes {
+		dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+			url.QueryEscape(cfg.User), url.QueryEscape(cfg.Password),
+			cfg.Host, cfg.Port, cfg.Database, sslMode)
+
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if err := db.Ping(); err != nil {
+			db.Close()
+			lastErr = err
+			// Check if database doesn't exist - this error won't be fixed by changing SSL mode
+			if isPostgresDatabaseNotExists(err) {
+				dbNotExists = true
+				break
+			}
+			continue
+		}
+
+		// Connection successful
+		return &ConnectResult{DB: db, SSLMode: sslMode}, nil
+	}
+
+	if dbNotExists {
+		return nil, &DatabaseNotExistsError{Database: cfg.Database}
+	}
+	return nil, fmt.Errorf("failed to connect with any SSL mode: %w", lastErr)
+}
+
+// createPostgresDatabase creates a PostgreSQL database
+func createPostgresDatabase(cfg config.DatabaseConfig) error {
+	// Connect to the default 'postgres' database
+	sslModes := []string{"require", "prefer", "disable"}
+	var db *sql.DB
+	var lastErr error
+
+	for _, sslMode := range sslModes {
+		dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/postgres?sslmode=%s",
+			url.QueryEscape(cfg.User), url.QueryEscape(cfg.Password),
+			cfg.Host, cfg.Port, sslMode)
+
+		conn, err := sql.Open("postgres", dsn)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if err := conn.Ping(); err != nil {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+
+		db = conn
+		break
+	}
+
+	if db == nil {
+		return fmt.Errorf("failed to connect to server: %w", lastErr)
+	}
+	defer db.Close()
+
+	// Create the database (use quoted identifier to handle special chars)
+	_, err := db.Exec(fmt.Sprintf("CREATE DATABASE %s", quoteIdentifier(cfg.Database)))
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+
+	return nil
+}
+
+// postgresDatabaseInfo retrieves PostgreSQL database metadata
+func postgresDatabaseInfo(db *sql.DB, database string) (DatabaseInfo, error) {
+	info := DatabaseInfo{}
+
+	// Get version
+	var version string
+	if err := db.QueryRow("SELECT version()").Scan(&version); err != nil {
+		return info, fmt.Errorf("failed to get version: %w", err)
+	}
+	info.Version = version
+	info.MajorVersion = extractMajorVersion(version)
+
+	// Get table count
+	var tableCount int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM information_schema.tables
+		WHERE ` + pgUserSchemaCondition("table_schema") + ` AND table_type = 'BASE TABLE'
+	`).Scan(&tableCount)
+	if err != nil {
+		return info, fmt.Errorf("failed to get table count: %w", err)
+	}
+	info.TableCount = tableCount
+
+	// Get database size
+	var totalSize int64
+	err = db.QueryRow("SELECT pg_database_size(current_database())").Scan(&totalSize)
+	if err != nil {
+		return info, fmt.Errorf("failed to get database size: %w", err)
+	}
+	info.TotalSize = totalSize
+
+	// Get view count
+	var viewCount int
+	err = db.QueryRow(`
+		SELECT COUNT(*)
+		FROM information_schema.views
+		WHERE ` + pgUserSchemaCondition("table_schema") + `
+	`).Scan(&viewCount)
+	if err != nil {
+		return info, fmt.Errorf("failed to get view count: %w", err)
+	}
+	info.ViewCount = viewCount
+
+	// Get table names (schema-qualified outside public)
+	rows, err := db.Query(`
+		SELECT CASE WHEN table_schema = 'public' THEN table_name
+			ELSE table_schema || '.' || table_name END AS table_name
+		FROM information_schema.tables
+		WHERE ` + pgUserSchemaCondition("table_schema") + ` AND table_type = 'BASE TABLE'
+		ORDER BY table_schema, table_name
+	`)
+	if err != nil {
+		return info, fmt.Errorf("failed to get table names: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return info, err
+		}
+		info.Tables = append(info.Tables, name)
+	}
+
+	return info, nil
+}
+
+// wipePostgres drops all tables, views, sequences, types and schemas from the database
+func wipePostgres(db *sql.DB) error {
+	// Drop all tables with CASCADE
+	tables, err := queryQualifiedNames(db, `
+		SELECT schemaname, tablename
+		FROM pg_tables
+		WHERE `+pgUserSchemaCondition("schemaname"))
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if _, err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", table)); err != nil {
+			return err
+		}
+	}
+
+	// Drop all views
+	views, err := queryQualifiedNames(db, `
+		SELECT schemaname, viewname
+		FROM pg_views
+		WHERE `+pgUserSchemaCondition("schemaname"))
+	if err != nil {
+		return err
+	}
+
+	for _, view := range views {
+		if _, err := db.Exec(fmt.Sprintf("DROP VIEW IF EXISTS %s CASCADE", view)); err != nil {
+			return err
+		}
+	}
+
+	// Drop all sequences
+	sequences, err := queryQualifiedNames(db, `
+		SELECT schemaname, sequencename
+		FROM pg_sequences
+		WHERE `+pgUserSchemaCondition("schemaname"))
+	if err != nil {
+		return err
+	}
+
+	for _, seq := range sequences {
+		if _, err := db.Exec(fmt.Sprintf("DROP SEQUENCE IF EXISTS %s CASCADE", seq)); err != nil {
+			return err
+		}
+	}
+
+	// Drop user-defined types (enums, domains, standalone composites and ranges)
+	rows, err := db.Query(`
+		SELECT n.nspname, t.typname, t.typtype
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		LEFT JOIN pg_class c ON c.oid = t.typrelid
+		WHERE ` + pgUserSchemaCondition("n.nspname") + `
+			AND (t.typtype IN ('e', 'd', 'r') OR (t.typtype = 'c' AND c.relkind = 'c'))
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_depend dep
+				WHERE dep.classid = 'pg_type'::regclass AND dep.objid = t.oid AND dep.deptype = 'e'
+			)
+	`)
+	if err != nil {
+		return err
+	}
+	var typeDrops []string
+	for rows.Next() {
+		var schema, name, typtype string
+		if err := rows.Scan(&schema, &name, &typtype); err != nil {
+			rows.Close()
+			return err
+		}
+		kind := "TYPE"
+		if typtype == "d" {
+			kind = "DOMAIN"
+		}
+		typeDrops = append(typeDrops, fmt.Sprintf("DROP %s IF EXISTS %s.%s CASCADE", kind, quoteIdentifier(schema), quoteIdentifier(name)))
+	}
+	rows.Close()
+
+	for _, stmt := range typeDrops {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	// Drop user schemas other than public, along with anything left in them
+	rows, err = db.Query(`
+		SELECT nspname
+		FROM pg_namespace
+		WHERE ` + pgUserSchemaCondition("nspname") + ` AND nspname != 'public'
+	`)
+	if err != nil {
+		return err
+	}
+	var schemas []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		schemas = append(schemas, name)
+	}
+	rows.Close()
+
+	for _, schema := range schemas {
+		if _, err := db.Exec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", quoteIdentifier(schema))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryQualifiedNames runs a query returning (schema, name) pairs and quotes each as "schema"."name"
+func queryQualifiedNames(db *sql.DB, query string) ([]string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var schema, name string
+		if err := rows.Scan(&schema, &name); err != nil {
+			return nil, err
+		}
+		names = append(names, quoteIdentifier(schema)+"."+quoteIdentifier(name))
+	}
+	return names, nil
+}
+
+// pgUserSchemaCondition returns a WHERE fragment excluding PostgreSQL system schemas for the given column
+func pgUserSchemaCondition(column string) string {
+	return fmt.Sprintf(`%s NOT IN ('pg_catalog', 'information_schema') AND %s NOT LIKE 'pg\_%%'`, column, column)
+}
+
+// quoteIdentifier quotes a PostgreSQL identifier
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/data"
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
+	_ "modernc.org/sqlite"
+)
+
+// SQLite is only migrated from, and the analyze command doesn't support it
+// Its tables are translated for MySQL, MariaDB and PostgreSQL
+func init() {
+	toMySQL := Target{
+		NewTranslator: func(string) schema.Translator { return schema.NewSQLiteTranslator("mysql") },
+		NewTransferer: func() data.Transferer { return data.NewSQLiteToMySQLTransferer() },
+	}
+	Register(&Engine{
+		Name:          "sqlite",
+		File:          true,
+		Dialect:       &dialect.SQLiteDialect{},
+		NewExtractor:  func() schema.Extractor { return schema.NewSQLiteExtractor() },
+		NewTransferer: func() data.Transferer { return data.NewSQLiteTransferer() },
+		Connect:       connectSQLite,
+		Info:          sqliteDatabaseInfo,
+		Targets: map[string]Target{
+			"mysql":   toMySQL,
+			"mariadb": toMySQL,
+			"pgsql": {
+				NewTranslator: func(string) schema.Translator { return schema.NewSQLiteTranslator("pgsql") },
+				NewTransferer: func() data.Transferer { return data.NewSQLiteToPostgresTransferer() },
+			},
+		},
+	})
+}
+
+// connectSQLite opens a SQLite database file read-only
+// The file must exist; opening a missing path would otherwise create an empty database
+func connectSQLite(cfg config.DatabaseConfig) (*ConnectResult, error) {
+	if _, err := os.Stat(cfg.Database); err != nil {
+		return nil, fmt.Errorf("cannot open database file: %w", err)
+	}
+
+	dsn := "file:" + (&url.URL{Path: cfg.Database}).EscapedPath() + "?mode=ro"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &ConnectResult{DB: db}, nil
+}
+
+// sqliteDatabaseInfo retrieves SQLite database metadata
+func sqliteDatabaseInfo(db *sql.DB, database string) (DatabaseInfo, error) {
+	info := DatabaseInfo{}
+
+	var version string
+	if err := db.QueryRow("SELECT sqlite_version()").Scan(&version); err != nil {
+		return info, fmt.Errorf("failed to get version: %w", err)
+	}
+	info.Version = "SQLite " + version
+	info.MajorVersion = extractMajorVersion(version)
+
+	// Get file size
+	var totalSize int64
+	err := db.QueryRow("SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()").Scan(&totalSize)
+	if err != nil {
+		return info, fmt.Errorf("failed to get database size: %w", err)
+	}
+	info.TotalSize = totalSize
+
+	// Get view count
+	var viewCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'view'").Scan(&viewCount); err != nil {
+		return info, fmt.Errorf("failed to get view count: %w", err)
+	}
+	info.ViewCount = viewCount
+
+	// Get table names, without SQLite's internal and virtual tables
+	rows, err := db.Query(`
+		SELECT name
+		FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\'
+			AND sql NOT LIKE 'CREATE VIRTUAL TABLE%'
+		ORDER BY name
+	`)
+	if err != nil {
+		return info, fmt.Errorf("failed to get table names: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return info, err
+		}
+		info.Tables = append(info.Tables, name)
+	}
+	info.TableCount = len(info.Tables)
+
+	return info, nil
+}
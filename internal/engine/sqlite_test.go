@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/DGarbs51/lcmigrate/internal/config"
+)
+
+func TestConnectSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data base.sqlite")
+	seed, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to create database file: %v", err)
+	}
+	if _, err := seed.Exec("CREATE TABLE users (id integer primary key)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	seed.Close()
+
+	result, err := connectSQLite(config.DatabaseConfig{Engine: "sqlite", Database: path})
+	if err != nil {
+		t.Fatalf("connectSQLite() error = %v", err)
+	}
+	defer result.DB.Close()
+
+	// The file is opened read-only
+	if _, err := result.DB.Exec("CREATE TABLE posts (id integer primary key)"); err == nil {
+		t.Error("connectSQLite() opened the database file writable")
+	}
+}
+
+func TestConnectSQLite_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.sqlite")
+	if _, err := connectSQLite(config.DatabaseConfig{Engine: "sqlite", Database: path}); err == nil {
+		t.Fatal("connectSQLite() error = nil, want an error for a missing file")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("connectSQLite() created %s", path)
+	}
+}
+
+func TestSQLiteDatabaseInfo(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT sqlite_version\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("3.45.1"))
+	mock.ExpectQuery("SELECT page_count \\* page_size").
+		WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(int64(4096000)))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM sqlite_master WHERE type = 'view'").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT name.*FROM sqlite_master.*type = 'table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("migrations").AddRow("users"))
+
+	info, err := sqliteDatabaseInfo(db, "database/database.sqlite")
+	if err != nil {
+		t.Errorf("sqliteDatabaseInfo() error = %v", err)
+	}
+
+	if info.Version != "SQLite 3.45.1" {
+		t.Errorf("info.Version = %q, want %q", info.Version, "SQLite 3.45.1")
+	}
+	if info.MajorVersion != 3 {
+		t.Errorf("info.MajorVersion = %d, want 3", info.MajorVersion)
+	}
+	if info.TableCount != 2 {
+		t.Errorf("info.TableCount = %d, want 2", info.TableCount)
+	}
+	if info.TotalSize != 4096000 {
+		t.Errorf("info.TotalSize = %d, want 4096000", info.TotalSize)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
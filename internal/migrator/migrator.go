@@ -3,10 +3,12 @@ package migrator
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/DGarbs51/lcmigrate/internal/config"
 	"github.com/DGarbs51/lcmigrate/internal/data"
+	"github.com/DGarbs51/lcmigrate/internal/engine"
 	"github.com/DGarbs51/lcmigrate/internal/preflight"
 	"github.com/DGarbs51/lcmigrate/internal/prompt"
 	"github.com/DGarbs51/lcmigrate/internal/schema"
@@ -15,7 +17,10 @@ import (
 
 const (
 	DefaultBatchSize = 10000
-	TotalStages      = 6
+	TotalStages      = 10
+
+	// MojibakeSampleSize is how many non-ASCII values of each converted column are checked for double encoding
+	MojibakeSampleSize = 1000
 )
 
 // Migrator handles the migration process
@@ -27,19 +32,117 @@ type Migrator struct {
 	applier    schema.Applier
 	transferer data.Transferer
 
+	// Cross-engine migrations
+	translator schema.Translator // Rewrites tables for the destination engine
+
+	// Same-engine migrations to a later major version
+	upgrader *schema.Upgrader // Rewrites objects for the destination version
+
+	// MySQL-family migrations converting legacy charsets
+	charsets *schema.CharsetConverter // Rewrites latin1 and utf8 tables and columns; nil when not asked for
+
+	tableFilter schema.TableFilter // Tables left out or created empty, from --include, --exclude and --schema-only-tables
+	excluded    []string           // Schema-qualified source names of the tables the filter left out
+
+	mapping *schema.Mapping // Renames tables and columns and leaves columns out from --mapping; nil without one
+	rules   *schema.RuleSet // Rewrites tables, indexes, foreign keys and views from --rules; nil without one
+
 	// Migration results
-	tables    []schema.TableSchema
-	views     []schema.ViewDef
-	sequences []schema.SequenceDef
-	totalRows int64
+	extensions []schema.ExtensionDef
+	schemas    []string
+	types      []schema.TypeDef
+	tables     []schema.TableSchema
+	views      []schema.ViewDef
+	routines   []schema.RoutineDef
+	triggers   []schema.TriggerDef
+	sequences  []schema.SequenceDef
+	totalRows  int64
+
+	routinesExtracted bool             // routines holds what the source has, read once
+	createdFunctions  map[string]bool  // Signatures of the functions created along with the schema
+	deferredFunctions map[string]error // Why functions failed along with the schema, by signature
+	disabledEvents    []string         // Events the source runs that were created disabled
+
+	unresolvedReported int // How many of the mapping's unresolved references were listed already
+}
+
+// Options holds the command-line options for a migration run
+type Options struct {
+	DryRun                   bool
+	Schemas                  []string
+	ExcludeSchemas           []string
+	RefreshMaterializedViews bool
+	Definer                  string
+	SQLSecurity              string
+	Partitioning             string
+	PartitionWorkers         int
+	Enums                    string
+	ConvertCharset           string
+	ConvertCollation         string
+	RepairMojibake           bool
+	Rules                    string
+	Mapping                  string
+	Include                  []string
+	Exclude                  []string
+	SchemaOnlyTables         []string
 }
 
 // Run executes the complete migration workflow
-func Run(dryRun bool) error {
+func Run(opts Options) error {
 	startTime := time.Now()
+	dryRun := opts.DryRun
+
+	// Reject a bad --definer or --sql-security before prompting for anything
+	definerPolicy, err := schema.ParseDefinerPolicy(opts.Definer, opts.SQLSecurity)
+	if err != nil {
+		return err
+	}
+	partitioning, err := schema.ParsePartitioning(opts.Partitioning)
+	if err != nil {
+		return err
+	}
+	enums, err := schema.ParseEnumMode(opts.Enums)
+	if err != nil {
+		return err
+	}
+	charsets, err := schema.ParseCharsetConversion(opts.ConvertCharset, opts.ConvertCollation)
+	if err != nil {
+		return err
+	}
+	if opts.RepairMojibake && !charsets.Enabled() {
+		return fmt.Errorf("--repair-mojibake needs --convert-charset")
+	}
+	rules, err := schema.LoadRewriteRules(opts.Rules)
+	if err != nil {
+		return err
+	}
+	mapping, err := schema.LoadMapping(opts.Mapping)
+	if err != nil {
+		return err
+	}
+	tableFilter, err := schema.ParseTableFilter(opts.Include, opts.Exclude, opts.SchemaOnlyTables)
+	if err != nil {
+		return err
+	}
 
 	// 1. Prompt for configuration
 	cfg := prompt.PromptMigrationConfig(dryRun)
+	cfg.Schemas = opts.Schemas
+	cfg.ExcludeSchemas = opts.ExcludeSchemas
+	cfg.RefreshMaterializedViews = opts.RefreshMaterializedViews
+	cfg.Definer = opts.Definer
+	cfg.SQLSecurity = opts.SQLSecurity
+	cfg.Partitioning = partitioning
+	cfg.PartitionWorkers = opts.PartitionWorkers
+	cfg.Enums = enums
+	cfg.ConvertCharset = charsets.Charset
+	cfg.ConvertCollation = charsets.Collation
+	cfg.RepairMojibake = opts.RepairMojibake
+	cfg.Rules = opts.Rules
+	cfg.Mapping = opts.Mapping
+	cfg.Include = opts.Include
+	cfg.Exclude = opts.Exclude
+	cfg.SchemaOnlyTables = opts.SchemaOnlyTables
 
 	// 2. Run pre-flight checks
 	preflightResult, err := preflight.Run(cfg)
@@ -57,6 +160,14 @@ func Run(dryRun bool) error {
 		return nil
 	}
 
+	// Pre-flight tells MySQL and MariaDB servers apart, whichever each was configured as
+	if preflightResult.SourceEngine != "" {
+		cfg.Source.Engine = preflightResult.SourceEngine
+	}
+	if preflightResult.DestEngine != "" {
+		cfg.Destination.Engine = preflightResult.DestEngine
+	}
+
 	// 3. Ask for final confirmation
 	if !dryRun {
 		fmt.Println()
@@ -66,14 +177,53 @@ func Run(dryRun bool) error {
 		}
 	}
 
-	// 4. Create migrator
+	// 4. Create migrator; pre-flight checked that the destination engine can take the source's
+	source, err := engine.Get(cfg.Source.Engine)
+	if err != nil {
+		return err
+	}
+	dest, err := engine.Get(cfg.Destination.Engine)
+	if err != nil {
+		return err
+	}
 	m := &Migrator{
 		config:     cfg,
 		sourceConn: preflightResult.SourceConn,
 		destConn:   preflightResult.DestConn,
-		extractor:  schema.NewExtractor(cfg.Source.Engine),
-		applier:    schema.NewApplier(cfg.Source.Engine),
-		transferer: data.NewTransferer(cfg.Source.Engine),
+		extractor:  source.NewExtractor(),
+		applier:    dest.NewApplier(),
+		transferer: engine.NewTransferer(source.Name, dest.Name),
+		translator: engine.NewTranslator(source.Name, dest.Name, cfg.Enums),
+		upgrader:   engine.NewUpgrader(source.Name, dest.Name, preflightResult.SourceInfo.MajorVersion, preflightResult.DestInfo.MajorVersion),
+	}
+	if charsets.Enabled() {
+		if engine.SameFamily(source.Name, "mysql") && engine.SameFamily(dest.Name, "mysql") {
+			m.charsets = schema.NewCharsetConverter(charsets)
+		} else {
+			ui.Warning("--convert-charset only applies between MySQL and MariaDB databases; ignoring it")
+		}
+	}
+	m.tableFilter = tableFilter
+	if mapping != nil {
+		m.mapping = mapping.ForEngine(dest.Name)
+	}
+	if rules != nil {
+		m.rules = rules.ForEngine(dest.Name)
+	}
+	if f, ok := m.extractor.(schema.SchemaFilterer); ok {
+		f.SetSchemaFilter(schema.SchemaFilter{Include: cfg.Schemas, Exclude: cfg.ExcludeSchemas})
+	}
+	if d, ok := m.extractor.(schema.DefinerRewriter); ok {
+		d.SetDefinerPolicy(definerPolicy)
+	}
+	if p, ok := m.extractor.(schema.Partitioner); ok {
+		p.SetPartitioning(cfg.Partitioning)
+	}
+	if p, ok := m.transferer.(data.PartitionTransferer); ok {
+		p.SetPartitionWorkers(cfg.PartitionWorkers)
+	}
+	if r, ok := m.applier.(schema.DatabaseRenamer); ok {
+		r.SetDatabaseRename(cfg.Source.Database, cfg.Destination.Database)
 	}
 
 	// Ensure connections are closed when done
@@ -102,32 +252,53 @@ func Run(dryRun bool) error {
 
 // runMigration executes all migration stages
 func (m *Migrator) runMigration() error {
-	// Stage 1: Schema Migration
+	// Stage 1: Create Extensions (PostgreSQL only)
+	if err := m.createExtensions(); err != nil {
+		return err
+	}
+
+	// Stage 2: Schema Migration
 	if err := m.migrateSchema(); err != nil {
 		return err
 	}
 
-	// Stage 2: Data Migration
+	// Stage 3: Data Migration
 	if err := m.migrateData(); err != nil {
 		return err
 	}
 
-	// Stage 3: Create Indexes and Constraints
+	// Stage 4: Create Indexes and Constraints
 	if err := m.createIndexesAndConstraints(); err != nil {
 		return err
 	}
 
-	// Stage 4: Create Views
+	// Stage 5: Create Procedures and the functions that needed the tables (before the views
+	// that call them); other functions are created with the schema
+	if err := m.createRoutines(); err != nil {
+		return err
+	}
+
+	// Stage 6: Create Views
 	if err := m.createViews(); err != nil {
 		return err
 	}
 
-	// Stage 5: Migrate Sequences (PostgreSQL only)
+	// Stage 7: Create Triggers (after the data load so they don't fire during the copy)
+	if err := m.createTriggers(); err != nil {
+		return err
+	}
+
+	// Stage 8: Migrate Sequences (PostgreSQL only)
 	if err := m.migrateSequences(); err != nil {
 		return err
 	}
 
-	// Stage 6: Finalization
+	// Stage 9: Refresh Materialized Views (once all their inputs are loaded)
+	if err := m.refreshMaterializedViews(); err != nil {
+		return err
+	}
+
+	// Stage 10: Finalization
 	if err := m.finalize(); err != nil {
 		return err
 	}
@@ -135,28 +306,204 @@ func (m *Migrator) runMigration() error {
 	return nil
 }
 
+// createExtensions creates the source's extensions before any table can use their types
+func (m *Migrator) createExtensions() error {
+	ui.Phase(1, TotalStages, "Creating extensions...")
+	startTime := time.Now()
+
+	extensions, err := m.extractor.ExtractExtensions(m.sourceConn, m.config.Source.Database)
+	if err != nil {
+		ui.PhaseFailed(err)
+		return fmt.Errorf("failed to extract extensions: %w", err)
+	}
+	m.extensions = extensions
+
+	if len(extensions) == 0 {
+		ui.PhaseSkipped("no extensions")
+		return nil
+	}
+
+	if m.translator != nil {
+		ui.PhaseSkipped(fmt.Sprintf("%d extensions have no %s equivalent", len(extensions), m.config.Destination.Engine))
+		return nil
+	}
+
+	if m.config.DryRun {
+		ui.DryRun(fmt.Sprintf("Would create %d extensions", len(extensions)))
+		for _, ext := range extensions {
+			ui.DryRun(fmt.Sprintf("  CREATE EXTENSION %s (%s)", ext.Name, ext.Version))
+		}
+		ui.PhaseDone(time.Since(startTime))
+		return nil
+	}
+
+	for _, ext := range extensions {
+		if err := m.applier.CreateExtension(m.destConn, ext); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+	}
+
+	ui.PhaseDone(time.Since(startTime))
+	return nil
+}
+
 // migrateSchema extracts and creates table schemas (without indexes/FKs)
 func (m *Migrator) migrateSchema() error {
-	ui.Phase(1, TotalStages, "Migrating schema...")
+	ui.Phase(2, TotalStages, "Migrating schema...")
 	startTime := time.Now()
 
+	// Extract schemas (namespaces) from source
+	schemas, err := m.extractor.ExtractSchemas(m.sourceConn, m.config.Source.Database)
+	if err != nil {
+		ui.PhaseFailed(err)
+		return fmt.Errorf("failed to extract schemas: %w", err)
+	}
+	m.schemas = schemas
+
+	// Extract user-defined types (already in dependency order)
+	types, err := m.extractor.ExtractTypes(m.sourceConn, m.config.Source.Database)
+	if err != nil {
+		ui.PhaseFailed(err)
+		return fmt.Errorf("failed to extract types: %w", err)
+	}
+	m.types = types
+
+	// Extract sequences, which column defaults reference by name
+	sequences, err := m.extractor.ExtractSequences(m.sourceConn, m.config.Source.Database)
+	if err != nil {
+		ui.PhaseFailed(err)
+		return fmt.Errorf("failed to extract sequences: %w", err)
+	}
+	m.sequences = sequences
+
 	// Extract tables from source
 	tables, err := m.extractor.ExtractTables(m.sourceConn, m.config.Source.Database)
 	if err != nil {
 		ui.PhaseFailed(err)
 		return fmt.Errorf("failed to extract schema: %w", err)
 	}
+	if m.tableFilter.Enabled() {
+		tables = m.filterTables(tables)
+		sequences = m.sequences
+	}
 	m.tables = tables
 
+	// Rewrite the tables for the destination version; pre-flight listed what changes
+	if m.upgrader != nil {
+		for i, t := range tables {
+			tables[i] = m.upgrader.UpgradeTable(t)
+		}
+	}
+
+	// Rewrite the tables for the destination engine
+	if m.translator != nil {
+		translated, translatedTypes, err := m.translator.TranslateTables(m.sourceConn, m.config.Source.Database, tables)
+		if err != nil {
+			ui.PhaseFailed(err)
+			return fmt.Errorf("failed to translate schema: %w", err)
+		}
+		// Schemas, types and sequences are the source engine's; the translated tables carry
+		// what they need
+		tables, types = translated, translatedTypes
+		schemas, sequences = nil, nil
+		m.tables, m.types, m.schemas, m.sequences = tables, types, schemas, sequences
+
+		if lossy := m.translator.LossyConversions(); len(lossy) > 0 {
+			ui.Warning(fmt.Sprintf("%d conversions to %s are lossy:", len(lossy), m.config.Destination.Engine))
+			for _, c := range lossy {
+				ui.Warning("  " + c.String())
+			}
+		}
+	}
+
+	// Convert legacy charsets, reporting the affected columns and anything that needs attention
+	if m.charsets != nil {
+		if err := m.convertCharsets(tables); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+	}
+
+	// Rename tables and columns and leave columns out, everywhere the DDL refers to them
+	if m.mapping != nil {
+		if err := m.mapTables(tables); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+		tables, sequences = m.tables, m.sequences
+	}
+
+	// Apply the rewrite rules last, to the DDL as the destination will get it
+	if m.rules != nil {
+		before := len(m.rules.Changes())
+		for i, t := range tables {
+			table, err := m.rules.RewriteTable(t)
+			if err != nil {
+				ui.PhaseFailed(err)
+				return fmt.Errorf("failed to apply rewrite rules: %w", err)
+			}
+			tables[i] = table
+		}
+		m.reportRuleChanges(before)
+	}
+
 	if m.config.DryRun {
+		for _, s := range schemas {
+			ui.DryRun(fmt.Sprintf("  CREATE SCHEMA %s", s))
+		}
+		if len(types) > 0 {
+			ui.DryRun(fmt.Sprintf("Would create %d types", len(types)))
+			for _, t := range types {
+				ui.DryRun(fmt.Sprintf("  CREATE TYPE %s (%s)", t.QualifiedName(), t.Kind))
+			}
+		}
+		for _, seq := range sequences {
+			if !seq.Identity {
+				ui.DryRun(fmt.Sprintf("  CREATE SEQUENCE %s", seq.QualifiedName()))
+			}
+		}
 		ui.DryRun(fmt.Sprintf("Would create %d tables", len(tables)))
 		for _, t := range tables {
-			ui.DryRun(fmt.Sprintf("  CREATE TABLE %s", t.Name))
+			ui.DryRun(fmt.Sprintf("  CREATE TABLE %s", t.QualifiedName()))
 		}
 		ui.PhaseDone(time.Since(startTime))
 		return nil
 	}
 
+	// Create schemas before the tables that live in them
+	for _, s := range schemas {
+		if err := m.applier.CreateSchema(m.destConn, s); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+	}
+
+	// Create types before the tables whose columns use them
+	for _, t := range types {
+		if err := m.applier.CreateType(m.destConn, t); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+	}
+
+	// Create sequences before the tables whose defaults call nextval on them
+	for _, seq := range sequences {
+		if err := m.applier.CreateSequence(m.destConn, seq); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+	}
+
+	// Create PostgreSQL functions before the column defaults, CHECK constraints and expression
+	// indexes that call them; MySQL routines wait for the data load
+	if m.translator == nil && m.config.Source.Engine == "pgsql" {
+		if err := m.createFunctions(); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+	}
+
 	// Create tables in destination
 	for _, table := range tables {
 		if err := m.applier.CreateTable(m.destConn, table); err != nil {
@@ -165,20 +512,227 @@ func (m *Migrator) migrateSchema() error {
 		}
 	}
 
+	// Restore OWNED BY now that the owning columns exist
+	for _, seq := range sequences {
+		if err := m.applier.SetSequenceOwner(m.destConn, seq); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+	}
+
 	ui.PhaseDone(time.Since(startTime))
 	return nil
 }
 
+// reportRuleChanges lists the statements the rewrite rules changed, starting at the given change
+// A dry run shows a diff of each statement
+func (m *Migrator) reportRuleChanges(from int) {
+	changes := m.rules.Changes()[from:]
+	if len(changes) == 0 {
+		return
+	}
+	if !m.config.DryRun {
+		ui.Info(fmt.Sprintf("Rewrite rules changed %d statements:", len(changes)))
+		for _, c := range changes {
+			ui.Info(fmt.Sprintf("  %s (%s)", c.Object, strings.Join(c.Rules, ", ")))
+		}
+		return
+	}
+	ui.DryRun(fmt.Sprintf("Rewrite rules would change %d statements:", len(changes)))
+	for _, c := range changes {
+		ui.DryRun(fmt.Sprintf("  %s (%s)", c.Object, strings.Join(c.Rules, ", ")))
+		for _, line := range c.Diff() {
+			ui.DryRun("    " + line)
+		}
+	}
+}
+
+// filterTables leaves out the tables --include and --exclude don't allow, along with their
+// sequences and the foreign keys referencing them, and marks the --schema-only-tables
+func (m *Migrator) filterTables(tables []schema.TableSchema) []schema.TableSchema {
+	filtered := m.tableFilter.FilterTables(tables)
+	m.excluded = filtered.Excluded
+	m.sequences = m.tableFilter.FilterSequences(m.sequences, filtered.Excluded)
+
+	if len(filtered.Excluded) > 0 {
+		ui.Info(fmt.Sprintf("Excluding %d tables: %s", len(filtered.Excluded), strings.Join(filtered.Excluded, ", ")))
+	}
+	var empty []string
+	for _, t := range filtered.Tables {
+		if t.SchemaOnly {
+			empty = append(empty, t.QualifiedName())
+		}
+	}
+	if len(empty) > 0 {
+		ui.Info(fmt.Sprintf("Creating %d tables without their rows: %s", len(empty), strings.Join(empty, ", ")))
+	}
+	if len(filtered.DroppedForeignKeys) > 0 {
+		ui.Warning(fmt.Sprintf("%d foreign keys reference tables whose rows aren't copied and won't be created:", len(filtered.DroppedForeignKeys)))
+		for _, fk := range filtered.DroppedForeignKeys {
+			ui.Warning("  " + fk)
+		}
+	}
+	return filtered.Tables
+}
+
+// reportExcludedReferences warns about the views or triggers left out because they use a
+// table the filter excludes
+func (m *Migrator) reportExcludedReferences(kind string, skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	ui.Warning(fmt.Sprintf("%d %s use excluded tables and won't be created:", len(skipped), kind))
+	for _, s := range skipped {
+		ui.Warning("  " + s)
+	}
+}
+
+// mapTables renames the tables and columns in m.tables and m.sequences as the mapping says,
+// reporting what it renamed and the indexes, constraints and statements it had to leave out
+func (m *Migrator) mapTables(tables []schema.TableSchema) error {
+	mapped, err := m.mapping.MapTables(tables)
+	if err != nil {
+		return fmt.Errorf("failed to apply mapping: %w", err)
+	}
+	m.tables = mapped
+	m.sequences = m.mapping.MapSequences(m.sequences)
+
+	renamed := 0
+	for _, t := range mapped {
+		if t.SourceName != "" {
+			renamed++
+		}
+	}
+	if renamed > 0 {
+		ui.Info(fmt.Sprintf("Mapping renames %d tables", renamed))
+	}
+	for _, w := range m.mapping.Warnings() {
+		ui.Warning(w)
+	}
+	return nil
+}
+
+// reportUnresolved lists the view and trigger references the mapping couldn't rewrite since
+// the last report
+func (m *Migrator) reportUnresolved() {
+	unresolved := m.mapping.Unresolved()[m.unresolvedReported:]
+	m.unresolvedReported += len(unresolved)
+	if len(unresolved) == 0 {
+		return
+	}
+	ui.Warning(fmt.Sprintf("%d references couldn't be mapped; check these views, and recreate these triggers, by hand:", len(unresolved)))
+	for _, u := range unresolved {
+		ui.Warning("  " + u)
+	}
+}
+
+// mapTriggers points triggers at the renamed tables, leaving out the ones the mapping can't
+// rewrite rather than creating them with stale names
+func (m *Migrator) mapTriggers(triggers []schema.TriggerDef) []schema.TriggerDef {
+	if m.mapping == nil {
+		return triggers
+	}
+	var mapped []schema.TriggerDef
+	for _, t := range triggers {
+		if trigger, ok := m.mapping.MapTrigger(t); ok {
+			mapped = append(mapped, trigger)
+		}
+	}
+	m.reportUnresolved()
+	return mapped
+}
+
+// convertCharsets rewrites the tables' legacy charsets, reports the converted columns and the
+// indexes that no longer fit, and samples the converted columns for double-encoded text
+func (m *Migrator) convertCharsets(tables []schema.TableSchema) error {
+	converted := make(map[string][]schema.ConvertedColumn)
+	before := 0
+	for i, t := range tables {
+		table, err := m.charsets.ConvertTable(t)
+		if err != nil {
+			return fmt.Errorf("failed to convert charsets: %w", err)
+		}
+		tables[i] = table
+		columns := m.charsets.ConvertedColumns()
+		converted[table.QualifiedName()] = columns[before:]
+		before = len(columns)
+	}
+
+	if before == 0 {
+		ui.Info(fmt.Sprintf("No latin1 or utf8 columns to convert to %s", m.config.ConvertCharset))
+		return nil
+	}
+	ui.Info(fmt.Sprintf("Converting %d columns to %s (%s):", before, m.config.ConvertCharset, m.config.ConvertCollation))
+	for _, table := range tables {
+		var names []string
+		for _, c := range converted[table.QualifiedName()] {
+			names = append(names, fmt.Sprintf("%s (%s)", c.Column, c.From))
+		}
+		if len(names) > 0 {
+			ui.Info(fmt.Sprintf("  %s: %s", table.QualifiedName(), strings.Join(names, ", ")))
+		}
+	}
+
+	if overflows := m.charsets.KeyOverflows(); len(overflows) > 0 {
+		ui.Warning(fmt.Sprintf("%d indexes are too long for InnoDB once converted and will fail to create:", len(overflows)))
+		for _, o := range overflows {
+			ui.Warning("  " + o.String())
+		}
+	}
+
+	repairer, ok := m.transferer.(data.MojibakeRepairer)
+	if !ok {
+		return nil
+	}
+	suspects := 0
+	for _, table := range tables {
+		var repair []string
+		for _, c := range converted[table.QualifiedName()] {
+			sampled, suspect, err := repairer.SampleMojibake(m.sourceConn, table, c.Column, MojibakeSampleSize)
+			if err != nil {
+				return err
+			}
+			if suspect > 0 {
+				ui.Warning(fmt.Sprintf("  %s.%s: %d of %d sampled values look double-encoded", table.QualifiedName(), c.Column, suspect, sampled))
+				repair = append(repair, c.Column)
+				suspects++
+			}
+		}
+		if len(repair) > 0 && m.config.RepairMojibake {
+			repairer.SetMojibakeRepair(table, repair)
+		}
+	}
+	switch {
+	case suspects == 0:
+	case m.config.RepairMojibake:
+		ui.Info(fmt.Sprintf("Double-encoded values in %d columns will be repaired while copying", suspects))
+	default:
+		ui.Warning(fmt.Sprintf("%d columns hold double-encoded text; rerun with --repair-mojibake to repair it while copying", suspects))
+	}
+	return nil
+}
+
 // migrateData transfers data from source to destination
 func (m *Migrator) migrateData() error {
-	ui.Phase(2, TotalStages, "Migrating data...")
+	ui.Phase(3, TotalStages, "Migrating data...")
 	startTime := time.Now()
 
 	if m.config.DryRun {
 		// Show what would be transferred
 		for _, table := range m.tables {
-			rows, _ := m.transferer.EstimateRows(m.sourceConn, table.Name)
-			ui.DryRun(fmt.Sprintf("Would copy %s rows from %s", ui.FormatNumber(rows), table.Name))
+			if table.IsPartitioned() {
+				continue
+			}
+			if table.SchemaOnly {
+				ui.DryRun(fmt.Sprintf("Would create %s empty", table.QualifiedName()))
+				continue
+			}
+			rows, _ := m.transferer.EstimateRows(m.sourceConn, table)
+			if len(table.Partitions) > 0 {
+				ui.DryRun(fmt.Sprintf("Would copy %s rows from %s in %d partitions", ui.FormatNumber(rows), table.QualifiedName(), len(table.Partitions)))
+			} else {
+				ui.DryRun(fmt.Sprintf("Would copy %s rows from %s", ui.FormatNumber(rows), table.QualifiedName()))
+			}
 			m.totalRows += rows
 		}
 		ui.PhaseDone(time.Since(startTime))
@@ -193,22 +747,32 @@ func (m *Migrator) migrateData() error {
 
 	fmt.Println() // newline for table progress
 
-	// Transfer each table
+	// Transfer each table; partitioned parents hold no rows, so data goes through the leaves
 	for _, table := range m.tables {
-		totalRows, _ := m.transferer.EstimateRows(m.sourceConn, table.Name)
-
-		stats, err := m.transferer.TransferTable(
-			m.sourceConn, m.destConn, table, DefaultBatchSize, false,
-			func(rows int64) {
-				ui.TableProgress(table.Name, rows, totalRows)
-			},
-		)
+		if table.IsPartitioned() || table.SchemaOnly {
+			continue
+		}
+		totalRows, _ := m.transferer.EstimateRows(m.sourceConn, table)
+
+		progress := func(rows int64) {
+			ui.TableProgress(table.QualifiedName(), rows, totalRows)
+		}
+		stats, err := m.transferer.TransferTable(m.sourceConn, m.destConn, table, DefaultBatchSize, false, progress)
+		if err != nil && len(table.Partitions) > 0 {
+			if table.PartitioningDropped {
+				ui.Warning(fmt.Sprintf("Copying %s failed; it was created without its partitioning, so the partitions already copied can't be told apart and the copy isn't retried", table.QualifiedName()))
+			} else {
+				// One retry in this run: partitions that already match the source are skipped
+				ui.Warning(fmt.Sprintf("Copying %s failed (%s), retrying once and skipping the partitions already copied", table.QualifiedName(), err))
+				stats, err = m.transferer.TransferTable(m.sourceConn, m.destConn, table, DefaultBatchSize, false, progress)
+			}
+		}
 		if err != nil {
 			ui.PhaseFailed(err)
 			return err
 		}
 
-		ui.TableDone(table.Name, stats.RowsCopied, stats.Duration)
+		ui.TableDone(table.QualifiedName(), stats.RowsCopied, stats.Duration)
 		m.totalRows += stats.RowsCopied
 	}
 
@@ -218,7 +782,7 @@ func (m *Migrator) migrateData() error {
 
 // createIndexesAndConstraints creates indexes and foreign keys
 func (m *Migrator) createIndexesAndConstraints() error {
-	ui.Phase(3, TotalStages, "Creating indexes and constraints...")
+	ui.Phase(4, TotalStages, "Creating indexes and constraints...")
 	startTime := time.Now()
 
 	// Count total indexes and FKs
@@ -236,11 +800,9 @@ func (m *Migrator) createIndexesAndConstraints() error {
 
 	// Create indexes
 	for _, table := range m.tables {
-		for _, idx := range table.Indexes {
-			if err := m.applier.CreateIndex(m.destConn, idx); err != nil {
-				ui.PhaseFailed(err)
-				return err
-			}
+		if err := m.applier.CreateTableIndexes(m.destConn, table); err != nil {
+			ui.PhaseFailed(err)
+			return err
 		}
 	}
 
@@ -258,9 +820,157 @@ func (m *Migrator) createIndexesAndConstraints() error {
 	return nil
 }
 
+// createRoutines creates stored functions and procedures
+func (m *Migrator) createRoutines() error {
+	ui.Phase(5, TotalStages, "Creating functions and procedures...")
+	startTime := time.Now()
+
+	routines, err := m.extractRoutines()
+	if err != nil {
+		ui.PhaseFailed(err)
+		return err
+	}
+
+	if len(routines) == 0 {
+		ui.PhaseSkipped("no functions or procedures")
+		return nil
+	}
+
+	if m.translator != nil {
+		if rt, ok := m.translator.(schema.RoutineTranslator); ok {
+			return m.createTranslatedRoutines(rt, routines, startTime)
+		}
+		names := make([]string, len(routines))
+		for i, r := range routines {
+			names[i] = r.Signature()
+		}
+		m.skipUntranslated("functions and procedures", names)
+		return nil
+	}
+
+	if m.config.DryRun {
+		ui.DryRun(fmt.Sprintf("Would create %d functions and procedures", len(routines)))
+		for _, r := range routines {
+			disabled := ""
+			if r.Disabled {
+				disabled = " (disabled)"
+			}
+			ui.DryRun(fmt.Sprintf("  CREATE %s %s%s", strings.ToUpper(r.Kind), r.Signature(), disabled))
+		}
+		ui.PhaseDone(time.Since(startTime))
+		return nil
+	}
+
+	for _, routine := range routines {
+		if m.createdFunctions[routine.Signature()] {
+			continue
+		}
+		if err := m.applier.CreateRoutine(m.destConn, routine); err != nil {
+			if early, ok := m.deferredFunctions[routine.Signature()]; ok {
+				err = fmt.Errorf("%w (and before the tables: %v)", err, early)
+			}
+			ui.PhaseFailed(err)
+			return err
+		}
+		if routine.Disabled {
+			m.disabledEvents = append(m.disabledEvents, routine.Name)
+		}
+	}
+
+	ui.PhaseDone(time.Since(startTime))
+	return nil
+}
+
+// extractRoutines reads the functions and procedures from the source once, rewritten for the
+// destination version
+func (m *Migrator) extractRoutines() ([]schema.RoutineDef, error) {
+	if m.routinesExtracted {
+		return m.routines, nil
+	}
+	routines, err := m.extractor.ExtractRoutines(m.sourceConn, m.config.Source.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract routines: %w", err)
+	}
+	if m.upgrader != nil {
+		for i, r := range routines {
+			routines[i] = m.upgrader.UpgradeRoutine(r)
+		}
+	}
+	m.routines = routines
+	m.routinesExtracted = true
+	return routines, nil
+}
+
+// createFunctions creates the functions once the types they use exist, ahead of the tables
+// Functions that can't be created yet, such as ones taking a table's row type, are collected
+// for createRoutines to retry once the tables exist
+func (m *Migrator) createFunctions() error {
+	routines, err := m.extractRoutines()
+	if err != nil {
+		return err
+	}
+	m.createdFunctions = make(map[string]bool)
+	m.deferredFunctions = make(map[string]error)
+	for _, routine := range routines {
+		if routine.Kind != "function" {
+			continue
+		}
+		if err := m.applier.CreateRoutine(m.destConn, routine); err != nil {
+			m.deferredFunctions[routine.Signature()] = err
+			continue
+		}
+		m.createdFunctions[routine.Signature()] = true
+	}
+	if len(m.deferredFunctions) > 0 {
+		ui.Info(fmt.Sprintf("%d functions couldn't be created before the tables; retrying them with the routines", len(m.deferredFunctions)))
+	}
+	return nil
+}
+
+// createTranslatedRoutines creates the functions and procedures a translator can rewrite for
+// the destination engine, listing the rest for recreating by hand as createTranslatedViews does
+func (m *Migrator) createTranslatedRoutines(rt schema.RoutineTranslator, routines []schema.RoutineDef, startTime time.Time) error {
+	m.routines = nil
+	var translated []schema.RoutineDef
+	var manual []string
+	for _, r := range routines {
+		routine, err := rt.TranslateRoutine(r)
+		if err != nil {
+			manual = append(manual, fmt.Sprintf("%s (%s)", r.Signature(), err))
+			continue
+		}
+		translated = append(translated, routine)
+	}
+
+	if m.config.DryRun {
+		ui.DryRun(fmt.Sprintf("Would create %d functions and procedures", len(translated)))
+		for _, r := range translated {
+			ui.DryRun(fmt.Sprintf("  CREATE %s %s", strings.ToUpper(r.Kind), r.Signature()))
+		}
+	} else {
+		for _, routine := range translated {
+			if err := m.applier.CreateRoutine(m.destConn, routine); err != nil {
+				manual = append(manual, fmt.Sprintf("%s (%s)", routine.Signature(), err))
+				continue
+			}
+			m.routines = append(m.routines, routine)
+		}
+	}
+
+	if len(manual) > 0 {
+		ui.Warning(fmt.Sprintf("%d functions and procedures weren't translated to %s; recreate them by hand:", len(manual), m.config.Destination.Engine))
+		for _, r := range manual {
+			ui.Warning("  " + r)
+		}
+	}
+
+	ui.PhaseDone(time.Since(startTime))
+	return nil
+}
+
 // createViews creates views in dependency order
 func (m *Migrator) createViews() error {
-	ui.Phase(4, TotalStages, "Creating views...")
+	ui.Phase(6, TotalStages, "Creating views...")
 	startTime := time.Now()
 
 	views, err := m.extractor.ExtractViews(m.sourceConn, m.config.Source.Database)
@@ -268,6 +978,26 @@ func (m *Migrator) createViews() error {
 		ui.PhaseFailed(err)
 		return fmt.Errorf("failed to extract views: %w", err)
 	}
+	views, skipped := m.tableFilter.FilterViews(views, m.excluded)
+	m.reportExcludedReferences("views", skipped)
+	if m.upgrader != nil {
+		for i, v := range views {
+			views[i] = m.upgrader.UpgradeView(v)
+		}
+	}
+	if m.mapping != nil && m.translator == nil {
+		for i, v := range views {
+			views[i] = m.mapping.MapView(v)
+		}
+		m.reportUnresolved()
+	}
+	if m.rules != nil && m.translator == nil {
+		before := len(m.rules.Changes())
+		for i, v := range views {
+			views[i] = m.rules.RewriteView(v)
+		}
+		m.reportRuleChanges(before)
+	}
 	m.views = views
 
 	if len(views) == 0 {
@@ -275,10 +1005,27 @@ func (m *Migrator) createViews() error {
 		return nil
 	}
 
+	if m.translator != nil {
+		if vt, ok := m.translator.(schema.ViewTranslator); ok {
+			return m.createTranslatedViews(vt, views, startTime)
+		}
+		names := make([]string, len(views))
+		for i, v := range views {
+			names[i] = v.QualifiedName()
+		}
+		m.views = nil
+		m.skipUntranslated("views", names)
+		return nil
+	}
+
 	if m.config.DryRun {
 		ui.DryRun(fmt.Sprintf("Would create %d views", len(views)))
 		for _, v := range views {
-			ui.DryRun(fmt.Sprintf("  CREATE VIEW %s", v.Name))
+			if v.Materialized {
+				ui.DryRun(fmt.Sprintf("  CREATE MATERIALIZED VIEW %s WITH NO DATA", v.QualifiedName()))
+				continue
+			}
+			ui.DryRun(fmt.Sprintf("  CREATE VIEW %s", v.QualifiedName()))
 		}
 		ui.PhaseDone(time.Since(startTime))
 		return nil
@@ -295,21 +1042,194 @@ func (m *Migrator) createViews() error {
 	return nil
 }
 
-// migrateSequences migrates sequences (PostgreSQL only)
+// createTranslatedViews creates the views a translator can rewrite for the destination engine
+// Views it can't rewrite, or that the destination rejects, are listed for recreating by hand
+// rather than failing the migration
+func (m *Migrator) createTranslatedViews(vt schema.ViewTranslator, views []schema.ViewDef, startTime time.Time) error {
+	m.views = nil
+	var translated []schema.ViewDef
+	var manual []string
+	rewritten := 0
+	if m.rules != nil {
+		rewritten = len(m.rules.Changes())
+	}
+	for _, v := range views {
+		view, err := vt.TranslateView(v)
+		if err != nil {
+			manual = append(manual, fmt.Sprintf("%s (%s)", v.QualifiedName(), err))
+			continue
+		}
+		if m.mapping != nil {
+			view = m.mapping.MapView(view)
+		}
+		if m.rules != nil {
+			view = m.rules.RewriteView(view)
+		}
+		translated = append(translated, view)
+	}
+	if m.mapping != nil {
+		m.reportUnresolved()
+	}
+	if m.rules != nil {
+		m.reportRuleChanges(rewritten)
+	}
+
+	if m.config.DryRun {
+		ui.DryRun(fmt.Sprintf("Would create %d views", len(translated)))
+		for _, v := range translated {
+			ui.DryRun(fmt.Sprintf("  CREATE VIEW %s", v.QualifiedName()))
+		}
+	} else {
+		for _, view := range translated {
+			if err := m.applier.CreateView(m.destConn, view); err != nil {
+				manual = append(manual, fmt.Sprintf("%s (%s)", view.QualifiedName(), err))
+				continue
+			}
+			m.views = append(m.views, view)
+		}
+	}
+
+	if len(manual) > 0 {
+		ui.Warning(fmt.Sprintf("%d views weren't translated to %s; recreate them by hand:", len(manual), m.config.Destination.Engine))
+		for _, v := range manual {
+			ui.Warning("  " + v)
+		}
+	}
+
+	ui.PhaseDone(time.Since(startTime))
+	return nil
+}
+
+// createTriggers creates triggers once the data is in place
+func (m *Migrator) createTriggers() error {
+	ui.Phase(7, TotalStages, "Creating triggers...")
+	startTime := time.Now()
+
+	triggers, err := m.extractor.ExtractTriggers(m.sourceConn, m.config.Source.Database)
+	if err != nil {
+		ui.PhaseFailed(err)
+		return fmt.Errorf("failed to extract triggers: %w", err)
+	}
+	triggers, skipped := m.tableFilter.FilterTriggers(triggers, m.excluded)
+	m.reportExcludedReferences("triggers", skipped)
+	if m.upgrader != nil {
+		for i, t := range triggers {
+			triggers[i] = m.upgrader.UpgradeTrigger(t)
+		}
+	}
+	if m.translator == nil {
+		triggers = m.mapTriggers(triggers)
+	}
+	m.triggers = triggers
+
+	if len(triggers) == 0 {
+		ui.PhaseSkipped("no triggers")
+		return nil
+	}
+
+	if m.translator != nil {
+		if rt, ok := m.translator.(schema.RoutineTranslator); ok {
+			return m.createTranslatedTriggers(rt, triggers, startTime)
+		}
+		names := make([]string, len(triggers))
+		for i, t := range triggers {
+			names[i] = fmt.Sprintf("%s ON %s", t.Name, t.Table)
+		}
+		m.skipUntranslated("triggers", names)
+		return nil
+	}
+
+	if m.config.DryRun {
+		ui.DryRun(fmt.Sprintf("Would create %d triggers", len(triggers)))
+		for _, t := range triggers {
+			ui.DryRun(fmt.Sprintf("  CREATE TRIGGER %s ON %s", t.Name, t.Table))
+		}
+		ui.PhaseDone(time.Since(startTime))
+		return nil
+	}
+
+	for _, trigger := range triggers {
+		if err := m.applier.CreateTrigger(m.destConn, trigger); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+	}
+
+	ui.PhaseDone(time.Since(startTime))
+	return nil
+}
+
+// createTranslatedTriggers creates the triggers a translator can rewrite for the destination
+// engine, listing the rest for recreating by hand
+func (m *Migrator) createTranslatedTriggers(rt schema.RoutineTranslator, triggers []schema.TriggerDef, startTime time.Time) error {
+	m.triggers = nil
+	var translated []schema.TriggerDef
+	var manual []string
+	for _, t := range triggers {
+		trigger, err := rt.TranslateTrigger(t)
+		if err != nil {
+			manual = append(manual, fmt.Sprintf("%s ON %s (%s)", t.Name, t.Table, err))
+			continue
+		}
+		translated = append(translated, trigger)
+	}
+	translated = m.mapTriggers(translated)
+
+	if m.config.DryRun {
+		ui.DryRun(fmt.Sprintf("Would create %d triggers", len(translated)))
+		for _, t := range translated {
+			ui.DryRun(fmt.Sprintf("  CREATE TRIGGER %s ON %s", t.Name, t.Table))
+		}
+	} else {
+		for _, trigger := range translated {
+			if err := m.applier.CreateTrigger(m.destConn, trigger); err != nil {
+				manual = append(manual, fmt.Sprintf("%s ON %s (%s)", trigger.Name, trigger.Table, err))
+				continue
+			}
+			m.triggers = append(m.triggers, trigger)
+		}
+	}
+
+	if len(manual) > 0 {
+		ui.Warning(fmt.Sprintf("%d triggers weren't translated to %s; recreate them by hand:", len(manual), m.config.Destination.Engine))
+		for _, t := range manual {
+			ui.Warning("  " + t)
+		}
+	}
+
+	ui.PhaseDone(time.Since(startTime))
+	return nil
+}
+
+// skipUntranslated skips a stage whose objects are written in the source engine's SQL, which
+// isn't translated, and lists them for recreating by hand
+func (m *Migrator) skipUntranslated(kind string, names []string) {
+	ui.PhaseSkipped(fmt.Sprintf("%d %s not translated to %s", len(names), kind, m.config.Destination.Engine))
+	ui.Warning(fmt.Sprintf("Recreate these %s by hand: %s", kind, strings.Join(names, ", ")))
+}
+
+// migrateSequences migrates sequences (PostgreSQL and MariaDB)
 func (m *Migrator) migrateSequences() error {
-	ui.Phase(5, TotalStages, "Migrating sequences...")
+	ui.Phase(8, TotalStages, "Migrating sequences...")
 	startTime := time.Now()
 
-	if m.config.Source.Engine != "pgsql" {
-		ui.PhaseSkipped("MySQL")
+	// Identity columns translated from AUTO_INCREMENT continue after the copied ids
+	if m.translator != nil {
+		return m.syncIdentities(startTime)
+	}
+
+	if source, err := engine.Get(m.config.Source.Engine); err == nil && !source.Sequences {
+		ui.PhaseSkipped(source.Dialect.Name() + " has no sequences")
 		return nil
 	}
 
+	// Re-read after the data copy so values reflect rows written meanwhile
 	sequences, err := m.extractor.ExtractSequences(m.sourceConn, m.config.Source.Database)
 	if err != nil {
 		ui.PhaseFailed(err)
 		return fmt.Errorf("failed to extract sequences: %w", err)
 	}
+	sequences = m.tableFilter.FilterSequences(sequences, m.excluded)
 	m.sequences = sequences
 
 	if len(sequences) == 0 {
@@ -335,9 +1255,104 @@ func (m *Migrator) migrateSequences() error {
 	return nil
 }
 
+// syncIdentities moves the sequence of each translated identity column past the copied rows
+func (m *Migrator) syncIdentities(startTime time.Time) error {
+	syncer, ok := m.applier.(schema.IdentitySyncer)
+	if !ok {
+		ui.PhaseSkipped(fmt.Sprintf("%s moves AUTO_INCREMENT past the copied rows itself", m.config.Destination.Engine))
+		return nil
+	}
+
+	var count int
+	for _, table := range m.tables {
+		for _, col := range table.Columns {
+			if col.Extra == "IDENTITY BY DEFAULT" {
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		ui.PhaseSkipped("no identity columns")
+		return nil
+	}
+
+	if m.config.DryRun {
+		ui.DryRun(fmt.Sprintf("Would set %d identity values", count))
+		ui.PhaseDone(time.Since(startTime))
+		return nil
+	}
+
+	for _, table := range m.tables {
+		for _, col := range table.Columns {
+			if col.Extra != "IDENTITY BY DEFAULT" {
+				continue
+			}
+			if err := syncer.SyncIdentity(m.destConn, table, col); err != nil {
+				ui.PhaseFailed(err)
+				return err
+			}
+		}
+	}
+
+	ui.PhaseDone(time.Since(startTime))
+	return nil
+}
+
+// refreshMaterializedViews populates materialized views, in dependency order, that held data on the source
+func (m *Migrator) refreshMaterializedViews() error {
+	ui.Phase(9, TotalStages, "Refreshing materialized views...")
+	startTime := time.Now()
+
+	var matviews []schema.ViewDef
+	for _, v := range m.views {
+		if v.Materialized && v.Populated {
+			matviews = append(matviews, v)
+		}
+	}
+
+	if len(matviews) == 0 {
+		ui.PhaseSkipped("no materialized views")
+		return nil
+	}
+
+	if !m.config.RefreshMaterializedViews {
+		ui.PhaseSkipped("disabled, run REFRESH MATERIALIZED VIEW manually")
+		return nil
+	}
+
+	if m.config.DryRun {
+		ui.DryRun(fmt.Sprintf("Would refresh %d materialized views", len(matviews)))
+		for _, v := range matviews {
+			ui.DryRun(fmt.Sprintf("  REFRESH MATERIALIZED VIEW %s", v.QualifiedName()))
+		}
+		ui.PhaseDone(time.Since(startTime))
+		return nil
+	}
+
+	for _, view := range matviews {
+		if err := m.applier.RefreshMaterializedView(m.destConn, view); err != nil {
+			ui.PhaseFailed(err)
+			return err
+		}
+	}
+
+	ui.PhaseDone(time.Since(startTime))
+	return nil
+}
+
+// countDestinationRows counts a table's rows on the destination
+// The transferer's own queries are written for the source, which is another engine in a
+// cross-engine migration and has another name for a renamed table
+func (m *Migrator) countDestinationRows(table schema.TableSchema) (int64, error) {
+	if c, ok := m.transferer.(data.DestinationCounter); ok && (m.translator != nil || table.SourceName != "") {
+		return c.CountDestinationRows(m.destConn, table)
+	}
+	return m.transferer.EstimateRows(m.destConn, table)
+}
+
 // finalize performs final verification
 func (m *Migrator) finalize() error {
-	ui.Phase(6, TotalStages, "Finalizing...")
+	ui.Phase(10, TotalStages, "Finalizing...")
 	startTime := time.Now()
 
 	if m.config.DryRun {
@@ -346,20 +1361,39 @@ func (m *Migrator) finalize() error {
 		return nil
 	}
 
+	// Routines and triggers that use databases missing on the destination are created
+	// anyway, and only fail when they run
+	if r, ok := m.applier.(schema.DatabaseRenamer); ok {
+		for _, ref := range r.UnresolvedReferences() {
+			ui.Warning(ref)
+		}
+	}
+
+	// The source keeps running its events until the switch-over, so their copies wait disabled
+	if len(m.disabledEvents) > 0 {
+		ui.Warning(fmt.Sprintf("%d events were created disabled so they don't run on both servers; enable them once you switch over:", len(m.disabledEvents)))
+		for _, name := range m.disabledEvents {
+			ui.Warning(fmt.Sprintf("  ALTER EVENT `%s` ENABLE", strings.ReplaceAll(name, "`", "``")))
+		}
+	}
+
 	// Re-enable foreign key checks
 	if err := m.transferer.EnableForeignKeyChecks(m.destConn); err != nil {
 		ui.PhaseFailed(err)
 		return fmt.Errorf("failed to enable FK checks: %w", err)
 	}
 
-	// Verify row counts
+	// Verify row counts; tables created empty have nothing to compare
 	for _, table := range m.tables {
-		sourceRows, _ := m.transferer.EstimateRows(m.sourceConn, table.Name)
-		destRows, _ := m.transferer.EstimateRows(m.destConn, table.Name)
+		if table.SchemaOnly {
+			continue
+		}
+		sourceRows, _ := m.transferer.EstimateRows(m.sourceConn, table)
+		destRows, _ := m.countDestinationRows(table)
 
 		if sourceRows != destRows {
 			err := fmt.Errorf("row count mismatch for %s: source=%d, dest=%d",
-				table.Name, sourceRows, destRows)
+				table.QualifiedName(), sourceRows, destRows)
 			ui.PhaseFailed(err)
 			return err
 		}
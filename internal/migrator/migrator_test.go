@@ -2,6 +2,10 @@ package migrator
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,10 +17,27 @@ import (
 
 // MockExtractor implements schema.Extractor for testing
 type MockExtractor struct {
-	Tables    []schema.TableSchema
-	Views     []schema.ViewDef
-	Sequences []schema.SequenceDef
-	Err       error
+	Extensions []schema.ExtensionDef
+	Schemas    []string
+	Types      []schema.TypeDef
+	Tables     []schema.TableSchema
+	Views      []schema.ViewDef
+	Sequences  []schema.SequenceDef
+	Routines   []schema.RoutineDef
+	Triggers   []schema.TriggerDef
+	Err        error
+}
+
+func (m *MockExtractor) ExtractExtensions(db *sql.DB, database string) ([]schema.ExtensionDef, error) {
+	return m.Extensions, m.Err
+}
+
+func (m *MockExtractor) ExtractTypes(db *sql.DB, database string) ([]schema.TypeDef, error) {
+	return m.Types, m.Err
+}
+
+func (m *MockExtractor) ExtractSchemas(db *sql.DB, database string) ([]string, error) {
+	return m.Schemas, m.Err
 }
 
 func (m *MockExtractor) ExtractTables(db *sql.DB, database string) ([]schema.TableSchema, error) {
@@ -31,14 +52,46 @@ func (m *MockExtractor) ExtractSequences(db *sql.DB, database string) ([]schema.
 	return m.Sequences, m.Err
 }
 
+func (m *MockExtractor) ExtractRoutines(db *sql.DB, database string) ([]schema.RoutineDef, error) {
+	return m.Routines, m.Err
+}
+
+func (m *MockExtractor) ExtractTriggers(db *sql.DB, database string) ([]schema.TriggerDef, error) {
+	return m.Triggers, m.Err
+}
+
 // MockApplier implements schema.Applier for testing
 type MockApplier struct {
-	TablesCreated   int
-	IndexesCreated  int
-	FKsCreated      int
-	ViewsCreated    int
-	SequencesSet    int
-	Err             error
+	ExtensionsCreated int
+	SchemasCreated    int
+	TypesCreated      int
+	TablesCreated     int
+	IndexesCreated    int
+	FKsCreated        int
+	ViewsCreated      int
+	MatviewsRefreshed int
+	SequencesCreated  int
+	SequenceOwnersSet int
+	SequencesSet      int
+	RoutinesCreated   int
+	TriggersCreated   int
+	IdentitiesSynced  int
+	Err               error
+}
+
+func (m *MockApplier) CreateExtension(db *sql.DB, ext schema.ExtensionDef) error {
+	m.ExtensionsCreated++
+	return m.Err
+}
+
+func (m *MockApplier) CreateType(db *sql.DB, typ schema.TypeDef) error {
+	m.TypesCreated++
+	return m.Err
+}
+
+func (m *MockApplier) CreateSchema(db *sql.DB, name string) error {
+	m.SchemasCreated++
+	return m.Err
 }
 
 func (m *MockApplier) CreateTable(db *sql.DB, table schema.TableSchema) error {
@@ -51,6 +104,11 @@ func (m *MockApplier) CreateIndex(db *sql.DB, index schema.IndexDef) error {
 	return m.Err
 }
 
+func (m *MockApplier) CreateTableIndexes(db *sql.DB, table schema.TableSchema) error {
+	m.IndexesCreated += len(table.Indexes)
+	return m.Err
+}
+
 func (m *MockApplier) CreateForeignKey(db *sql.DB, fk schema.ForeignKeyDef) error {
 	m.FKsCreated++
 	return m.Err
@@ -61,7 +119,18 @@ func (m *MockApplier) CreateView(db *sql.DB, view schema.ViewDef) error {
 	return m.Err
 }
 
+func (m *MockApplier) RefreshMaterializedView(db *sql.DB, view schema.ViewDef) error {
+	m.MatviewsRefreshed++
+	return m.Err
+}
+
 func (m *MockApplier) CreateSequence(db *sql.DB, seq schema.SequenceDef) error {
+	m.SequencesCreated++
+	return m.Err
+}
+
+func (m *MockApplier) SetSequenceOwner(db *sql.DB, seq schema.SequenceDef) error {
+	m.SequenceOwnersSet++
 	return m.Err
 }
 
@@ -70,6 +139,36 @@ func (m *MockApplier) SetSequenceValue(db *sql.DB, seq schema.SequenceDef) error
 	return m.Err
 }
 
+func (m *MockApplier) CreateRoutine(db *sql.DB, routine schema.RoutineDef) error {
+	m.RoutinesCreated++
+	return m.Err
+}
+
+func (m *MockApplier) CreateTrigger(db *sql.DB, trigger schema.TriggerDef) error {
+	m.TriggersCreated++
+	return m.Err
+}
+
+func (m *MockApplier) SyncIdentity(db *sql.DB, table schema.TableSchema, column schema.ColumnDef) error {
+	m.IdentitiesSynced++
+	return m.Err
+}
+
+// MockTranslator implements schema.Translator for testing
+type MockTranslator struct {
+	Tables []schema.TableSchema
+	Types  []schema.TypeDef
+	Lossy  []schema.LossyConversion
+}
+
+func (m *MockTranslator) TranslateTables(db *sql.DB, database string, tables []schema.TableSchema) ([]schema.TableSchema, []schema.TypeDef, error) {
+	return m.Tables, m.Types, nil
+}
+
+func (m *MockTranslator) LossyConversions() []schema.LossyConversion {
+	return m.Lossy
+}
+
 // MockTransferer implements data.Transferer for testing
 type MockTransferer struct {
 	DisableFKCalls int
@@ -77,6 +176,7 @@ type MockTransferer struct {
 	TransferCalls  int
 	RowsCopied     int64
 	Err            error
+	TransferErrs   []error // Returned by the first TransferTable calls, before Err
 }
 
 func (m *MockTransferer) DisableForeignKeyChecks(dest *sql.DB) error {
@@ -91,6 +191,9 @@ func (m *MockTransferer) EnableForeignKeyChecks(dest *sql.DB) error {
 
 func (m *MockTransferer) TransferTable(source, dest *sql.DB, table schema.TableSchema, batchSize int, dryRun bool, progressFn func(rows int64)) (*data.TransferStats, error) {
 	m.TransferCalls++
+	if m.TransferCalls <= len(m.TransferErrs) {
+		return nil, m.TransferErrs[m.TransferCalls-1]
+	}
 	return &data.TransferStats{
 		TableName:  table.Name,
 		RowsCopied: m.RowsCopied,
@@ -98,7 +201,7 @@ func (m *MockTransferer) TransferTable(source, dest *sql.DB, table schema.TableS
 	}, m.Err
 }
 
-func (m *MockTransferer) EstimateRows(db *sql.DB, table string) (int64, error) {
+func (m *MockTransferer) EstimateRows(db *sql.DB, table schema.TableSchema) (int64, error) {
 	return m.RowsCopied, m.Err
 }
 
@@ -106,8 +209,8 @@ func TestMigrator_Constants(t *testing.T) {
 	if DefaultBatchSize != 10000 {
 		t.Errorf("DefaultBatchSize = %d, want 10000", DefaultBatchSize)
 	}
-	if TotalStages != 6 {
-		t.Errorf("TotalStages = %d, want 6", TotalStages)
+	if TotalStages != 10 {
+		t.Errorf("TotalStages = %d, want 10", TotalStages)
 	}
 }
 
@@ -201,7 +304,7 @@ func TestMigrator_MigrateSchema_CreateTables(t *testing.T) {
 	}
 }
 
-func TestMigrator_CreateIndexesAndConstraints(t *testing.T) {
+func TestMigrator_MigrateSchema_UpgradesTables(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -214,83 +317,87 @@ func TestMigrator_CreateIndexesAndConstraints(t *testing.T) {
 	}
 	defer destDB.Close()
 
+	extractor := &MockExtractor{
+		Tables: []schema.TableSchema{
+			{Name: "users", CreateStmt: "CREATE TABLE `users` (\n  `id` int(11) NOT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=utf8"},
+		},
+	}
 	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			DryRun: false,
-		},
-		destConn: destDB,
-		applier:  applier,
-		tables: []schema.TableSchema{
-			{
-				Name: "users",
-				Indexes: []schema.IndexDef{
-					{Name: "idx_email"},
-				},
-				ForeignKeys: []schema.ForeignKeyDef{
-					{Name: "fk_user"},
-				},
-			},
-			{
-				Name: "orders",
-				Indexes: []schema.IndexDef{
-					{Name: "idx_date"},
-					{Name: "idx_user"},
-				},
-				ForeignKeys: []schema.ForeignKeyDef{},
-			},
+			Source:      config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "mysql", Database: "testdb_dest"},
 		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    applier,
+		upgrader:   schema.NewMySQLUpgrader(5, 8),
 	}
 
-	err = m.createIndexesAndConstraints()
-	if err != nil {
-		t.Errorf("createIndexesAndConstraints() error = %v", err)
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
 	}
 
-	if applier.IndexesCreated != 3 {
-		t.Errorf("IndexesCreated = %d, want 3", applier.IndexesCreated)
-	}
-	if applier.FKsCreated != 1 {
-		t.Errorf("FKsCreated = %d, want 1", applier.FKsCreated)
+	want := "CREATE TABLE `users` (\n  `id` int NOT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3"
+	if got := m.tables[0].CreateStmt; got != want {
+		t.Errorf("CreateStmt = %q, want %q", got, want)
 	}
 }
 
-func TestMigrator_CreateIndexesAndConstraints_DryRun(t *testing.T) {
+func TestMigrator_MigrateSchema_ConvertsCharsets(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
 	destDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create dest mock: %v", err)
 	}
 	defer destDB.Close()
 
-	applier := &MockApplier{}
+	extractor := &MockExtractor{
+		Tables: []schema.TableSchema{
+			{Name: "posts", CreateStmt: "CREATE TABLE `posts` (\n  `id` int NOT NULL,\n  `title` varchar(100) NOT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"},
+		},
+	}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			DryRun: true,
-		},
-		destConn: destDB,
-		applier:  applier,
-		tables: []schema.TableSchema{
-			{
-				Name:    "users",
-				Indexes: []schema.IndexDef{{Name: "idx_email"}},
-			},
+			Source:           config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
+			Destination:      config.DatabaseConfig{Engine: "mysql", Database: "testdb_dest"},
+			ConvertCharset:   "utf8mb4",
+			ConvertCollation: "utf8mb4_unicode_ci",
+			RepairMojibake:   true,
 		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    &MockApplier{},
+		transferer: data.NewMySQLTransferer(),
+		charsets:   schema.NewCharsetConverter(schema.CharsetConversion{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"}),
 	}
 
-	err = m.createIndexesAndConstraints()
-	if err != nil {
-		t.Errorf("createIndexesAndConstraints() error = %v", err)
+	sourceMock.ExpectQuery("SELECT `title` FROM `posts` WHERE").
+		WillReturnRows(sqlmock.NewRows([]string{"title"}).AddRow([]byte("CafÃ© menu")))
+
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
 	}
 
-	// Dry run should not create anything
-	if applier.IndexesCreated != 0 {
-		t.Errorf("IndexesCreated in dry run = %d, want 0", applier.IndexesCreated)
+	want := "CREATE TABLE `posts` (\n  `id` int NOT NULL,\n  `title` varchar(100) NOT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci"
+	if got := m.tables[0].CreateStmt; got != want {
+		t.Errorf("CreateStmt = %q, want %q", got, want)
+	}
+	if err := sourceMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("source expectations not met: %v", err)
 	}
 }
 
-func TestMigrator_CreateViews(t *testing.T) {
+func TestMigrator_MigrateSchema_AppliesRewriteRules(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -303,36 +410,44 @@ func TestMigrator_CreateViews(t *testing.T) {
 	}
 	defer destDB.Close()
 
+	rules, err := schema.ParseRewriteRules([]byte(`{"rules": [{"name": "innodb", "match": "ENGINE=MyISAM", "replace": "ENGINE=InnoDB"}]}`))
+	if err != nil {
+		t.Fatalf("ParseRewriteRules() error = %v", err)
+	}
+
 	extractor := &MockExtractor{
-		Views: []schema.ViewDef{
-			{Name: "user_stats"},
-			{Name: "order_summary"},
+		Tables: []schema.TableSchema{
+			{Name: "logs", CreateStmt: "CREATE TABLE `logs` (\n  `id` int NOT NULL\n) ENGINE=MyISAM"},
+			{Name: "users", CreateStmt: "CREATE TABLE `users` (\n  `id` int NOT NULL\n) ENGINE=InnoDB"},
 		},
 	}
 	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			Source: config.DatabaseConfig{Database: "testdb"},
-			DryRun: false,
+			Source:      config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "mysql", Database: "testdb_dest"},
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
 		extractor:  extractor,
 		applier:    applier,
+		rules:      rules.ForEngine("mysql"),
 	}
 
-	err = m.createViews()
-	if err != nil {
-		t.Errorf("createViews() error = %v", err)
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
 	}
 
-	if applier.ViewsCreated != 2 {
-		t.Errorf("ViewsCreated = %d, want 2", applier.ViewsCreated)
+	if want := "CREATE TABLE `logs` (\n  `id` int NOT NULL\n) ENGINE=InnoDB"; m.tables[0].CreateStmt != want {
+		t.Errorf("CreateStmt = %q, want %q", m.tables[0].CreateStmt, want)
+	}
+	if changes := rules.Changes(); len(changes) != 1 || changes[0].Object != "table logs" {
+		t.Errorf("Changes() = %v, want only table logs", changes)
 	}
 }
 
-func TestMigrator_CreateViews_NoViews(t *testing.T) {
+func TestMigrator_MigrateSchema_AppliesMapping(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -345,63 +460,116 @@ func TestMigrator_CreateViews_NoViews(t *testing.T) {
 	}
 	defer destDB.Close()
 
+	mapping, err := schema.ParseMapping([]byte(`{"remove_prefix": "tbl_", "columns": {"tbl_users.fullname": "name"}}`))
+	if err != nil {
+		t.Fatalf("ParseMapping() error = %v", err)
+	}
+
 	extractor := &MockExtractor{
-		Views: []schema.ViewDef{},
+		Tables: []schema.TableSchema{
+			{
+				Name:       "tbl_users",
+				CreateStmt: "CREATE TABLE `tbl_users` (\n  `id` int NOT NULL,\n  `fullname` varchar(100) NOT NULL\n) ENGINE=InnoDB",
+				Columns:    []schema.ColumnDef{{Name: "id"}, {Name: "fullname"}},
+			},
+		},
 	}
-	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			Source: config.DatabaseConfig{Database: "testdb"},
-			DryRun: false,
+			Source:      config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "mysql", Database: "testdb_dest"},
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
 		extractor:  extractor,
-		applier:    applier,
+		applier:    &MockApplier{},
+		mapping:    mapping.ForEngine("mysql"),
 	}
 
-	err = m.createViews()
-	if err != nil {
-		t.Errorf("createViews() error = %v", err)
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
 	}
 
-	// No views to create
-	if applier.ViewsCreated != 0 {
-		t.Errorf("ViewsCreated = %d, want 0", applier.ViewsCreated)
+	table := m.tables[0]
+	if table.Name != "users" || table.SourceName != "tbl_users" {
+		t.Errorf("Name, SourceName = %q, %q, want users, tbl_users", table.Name, table.SourceName)
+	}
+	if want := "CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `name` varchar(100) NOT NULL\n) ENGINE=InnoDB"; table.CreateStmt != want {
+		t.Errorf("CreateStmt = %q, want %q", table.CreateStmt, want)
 	}
 }
 
-func TestMigrator_MigrateSequences_MySQL(t *testing.T) {
-	sourceDB, _, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create source mock: %v", err)
-	}
-	defer sourceDB.Close()
+// triggerApplier records the triggers it creates
+type triggerApplier struct {
+	*MockApplier
+	created []schema.TriggerDef
+}
 
-	destDB, _, err := sqlmock.New()
+func (a *triggerApplier) CreateTrigger(db *sql.DB, trigger schema.TriggerDef) error {
+	a.created = append(a.created, trigger)
+	return a.MockApplier.CreateTrigger(db, trigger)
+}
+
+func TestMigrator_CreateTriggers_AppliesMapping(t *testing.T) {
+	mapping, err := schema.ParseMapping([]byte(`{"tables": {"tbl_users": "users"}, "columns": {"tbl_users.fullname": "name"}}`))
 	if err != nil {
-		t.Fatalf("failed to create dest mock: %v", err)
+		t.Fatalf("ParseMapping() error = %v", err)
+	}
+	mapping.ForEngine("mysql")
+	if _, err := mapping.MapTables([]schema.TableSchema{
+		{Name: "tbl_users", CreateStmt: "CREATE TABLE `tbl_users` (\n  `id` int NOT NULL,\n  `fullname` varchar(100) NOT NULL\n)", Columns: []schema.ColumnDef{{Name: "id"}, {Name: "fullname"}}},
+		{Name: "audit", CreateStmt: "CREATE TABLE `audit` (\n  `id` int NOT NULL,\n  `note` text\n)", Columns: []schema.ColumnDef{{Name: "id"}, {Name: "note"}}},
+	}); err != nil {
+		t.Fatalf("MapTables() error = %v", err)
 	}
-	defer destDB.Close()
 
+	extractor := &MockExtractor{
+		Triggers: []schema.TriggerDef{
+			{Table: "tbl_users", Name: "users_trim", CreateStmt: "CREATE TRIGGER `users_trim` BEFORE INSERT ON `tbl_users` FOR EACH ROW SET NEW.fullname = TRIM(NEW.fullname)"},
+			{Table: "audit", Name: "audit_copy", CreateStmt: "CREATE TRIGGER `audit_copy` AFTER INSERT ON `audit` FOR EACH ROW INSERT INTO tbl_users (fullname) VALUES (NEW.note)"},
+		},
+	}
+	applier := &triggerApplier{MockApplier: &MockApplier{}}
 	m := &Migrator{
 		config: config.MigrationConfig{
-			Source: config.DatabaseConfig{Engine: "mysql"},
-			DryRun: false,
+			Source: config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
 		},
-		sourceConn: sourceDB,
-		destConn:   destDB,
+		extractor: extractor,
+		applier:   applier,
+		mapping:   mapping,
 	}
 
-	// MySQL should skip sequences
-	err = m.migrateSequences()
-	if err != nil {
-		t.Errorf("migrateSequences() error = %v", err)
+	if err := m.createTriggers(); err != nil {
+		t.Fatalf("createTriggers() error = %v", err)
+	}
+
+	// audit_copy writes to the renamed table in its body, so it's listed instead of created
+	if len(applier.created) != 1 {
+		t.Fatalf("created %d triggers, want 1: %+v", len(applier.created), applier.created)
+	}
+	trigger := applier.created[0]
+	want := "CREATE TRIGGER `users_trim` BEFORE INSERT ON `users` FOR EACH ROW SET NEW.name = TRIM(NEW.name)"
+	if trigger.Table != "users" || trigger.CreateStmt != want {
+		t.Errorf("trigger = %q on %q, want %q on users", trigger.CreateStmt, trigger.Table, want)
+	}
+	if unresolved := mapping.Unresolved(); len(unresolved) != 1 || !strings.Contains(unresolved[0], "audit_copy") {
+		t.Errorf("Unresolved() = %v, want audit_copy", unresolved)
 	}
 }
 
-func TestMigrator_MigrateSequences_Postgres(t *testing.T) {
+// countingTransferer records the tables whose rows are counted
+type countingTransferer struct {
+	*MockTransferer
+	counted []string
+}
+
+func (c *countingTransferer) EstimateRows(db *sql.DB, table schema.TableSchema) (int64, error) {
+	c.counted = append(c.counted, table.Name)
+	return c.MockTransferer.EstimateRows(db, table)
+}
+
+func TestMigrator_FiltersTables(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -414,36 +582,85 @@ func TestMigrator_MigrateSequences_Postgres(t *testing.T) {
 	}
 	defer destDB.Close()
 
+	filter, err := schema.ParseTableFilter(nil, []string{"telescope_*"}, []string{"sessions"})
+	if err != nil {
+		t.Fatalf("ParseTableFilter() error = %v", err)
+	}
+
 	extractor := &MockExtractor{
-		Sequences: []schema.SequenceDef{
-			{Name: "users_id_seq", CurrentVal: 100},
-			{Name: "orders_id_seq", CurrentVal: 50},
+		Tables: []schema.TableSchema{
+			{Name: "users"},
+			{Name: "sessions"},
+			{Name: "telescope_entries"},
+			{Name: "audits", ForeignKeys: []schema.ForeignKeyDef{{Name: "audits_entry_id_foreign", RefTable: "telescope_entries"}}},
+		},
+		Views: []schema.ViewDef{
+			{Name: "user_list", Dependencies: []string{"users"}},
+			{Name: "recent_entries", Dependencies: []string{"users", "telescope_entries"}},
+		},
+		Triggers: []schema.TriggerDef{
+			{Table: "telescope_entries", Name: "telescope_touch"},
+			{Table: "users", Name: "users_log", CreateStmt: "CREATE TRIGGER users_log AFTER UPDATE ON users FOR EACH ROW INSERT INTO telescope_entries (content) VALUES (NEW.id)"},
 		},
 	}
 	applier := &MockApplier{}
+	transferer := &countingTransferer{MockTransferer: &MockTransferer{RowsCopied: 10}}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
-			DryRun: false,
+			Source:      config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "mysql", Database: "testdb_dest"},
 		},
-		sourceConn: sourceDB,
-		destConn:   destDB,
-		extractor:  extractor,
-		applier:    applier,
+		sourceConn:  sourceDB,
+		destConn:    destDB,
+		extractor:   extractor,
+		applier:     applier,
+		transferer:  transferer,
+		tableFilter: filter,
 	}
 
-	err = m.migrateSequences()
-	if err != nil {
-		t.Errorf("migrateSequences() error = %v", err)
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
+	}
+	if applier.TablesCreated != 3 {
+		t.Errorf("TablesCreated = %d, want 3", applier.TablesCreated)
+	}
+	if fks := m.tables[2].ForeignKeys; len(fks) != 0 {
+		t.Errorf("audits foreign keys = %v, want the one to telescope_entries dropped", fks)
 	}
 
-	if applier.SequencesSet != 2 {
-		t.Errorf("SequencesSet = %d, want 2", applier.SequencesSet)
+	if err := m.migrateData(); err != nil {
+		t.Fatalf("migrateData() error = %v", err)
+	}
+	if transferer.TransferCalls != 2 {
+		t.Errorf("TransferCalls = %d, want 2 (sessions is created empty)", transferer.TransferCalls)
+	}
+
+	// Views and triggers that use an excluded table are left out
+	if err := m.createViews(); err != nil {
+		t.Fatalf("createViews() error = %v", err)
+	}
+	if applier.ViewsCreated != 1 || len(m.views) != 1 || m.views[0].Name != "user_list" {
+		t.Errorf("ViewsCreated = %d, views = %v, want only user_list", applier.ViewsCreated, m.views)
+	}
+
+	if err := m.createTriggers(); err != nil {
+		t.Fatalf("createTriggers() error = %v", err)
+	}
+	if applier.TriggersCreated != 0 {
+		t.Errorf("TriggersCreated = %d, want 0", applier.TriggersCreated)
+	}
+
+	transferer.counted = nil
+	if err := m.finalize(); err != nil {
+		t.Fatalf("finalize() error = %v", err)
+	}
+	if want := []string{"users", "users", "audits", "audits"}; !reflect.DeepEqual(transferer.counted, want) {
+		t.Errorf("finalize() counted %v, want %v", transferer.counted, want)
 	}
 }
 
-func TestMigrator_MigrateSequences_NoSequences(t *testing.T) {
+func TestMigrator_MigrateSchema_CreatesSchemas(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -457,14 +674,20 @@ func TestMigrator_MigrateSequences_NoSequences(t *testing.T) {
 	defer destDB.Close()
 
 	extractor := &MockExtractor{
-		Sequences: []schema.SequenceDef{},
+		Schemas: []string{"audit", "public"},
+		Types: []schema.TypeDef{
+			{Schema: "public", Name: "order_status", Kind: "enum"},
+		},
+		Tables: []schema.TableSchema{
+			{Schema: "public", Name: "users"},
+			{Schema: "audit", Name: "log"},
+		},
 	}
 	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
 			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
-			DryRun: false,
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
@@ -472,13 +695,22 @@ func TestMigrator_MigrateSequences_NoSequences(t *testing.T) {
 		applier:    applier,
 	}
 
-	err = m.migrateSequences()
-	if err != nil {
-		t.Errorf("migrateSequences() error = %v", err)
+	if err := m.migrateSchema(); err != nil {
+		t.Errorf("migrateSchema() error = %v", err)
+	}
+
+	if applier.SchemasCreated != 2 {
+		t.Errorf("SchemasCreated = %d, want 2", applier.SchemasCreated)
+	}
+	if applier.TypesCreated != 1 {
+		t.Errorf("TypesCreated = %d, want 1", applier.TypesCreated)
+	}
+	if applier.TablesCreated != 2 {
+		t.Errorf("TablesCreated = %d, want 2", applier.TablesCreated)
 	}
 }
 
-func TestMigrator_MigrateSequences_DryRun(t *testing.T) {
+func TestMigrator_MigrateSchema_CreatesSequencesAroundTables(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -492,8 +724,10 @@ func TestMigrator_MigrateSequences_DryRun(t *testing.T) {
 	defer destDB.Close()
 
 	extractor := &MockExtractor{
+		Tables: []schema.TableSchema{{Schema: "public", Name: "orders"}},
 		Sequences: []schema.SequenceDef{
-			{Name: "users_id_seq", CurrentVal: 100},
+			{Schema: "public", Name: "order_number_seq", OwnedByStmt: "ALTER SEQUENCE ..."},
+			{Schema: "public", Name: "orders_id_seq", Identity: true},
 		},
 	}
 	applier := &MockApplier{}
@@ -501,7 +735,6 @@ func TestMigrator_MigrateSequences_DryRun(t *testing.T) {
 	m := &Migrator{
 		config: config.MigrationConfig{
 			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
-			DryRun: true,
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
@@ -509,18 +742,23 @@ func TestMigrator_MigrateSequences_DryRun(t *testing.T) {
 		applier:    applier,
 	}
 
-	err = m.migrateSequences()
-	if err != nil {
-		t.Errorf("migrateSequences() error = %v", err)
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
 	}
 
-	// Dry run should not set sequence values
-	if applier.SequencesSet != 0 {
-		t.Errorf("SequencesSet in dry run = %d, want 0", applier.SequencesSet)
+	// The applier skips identity sequences itself, so every sequence is offered to it
+	if applier.SequencesCreated != 2 {
+		t.Errorf("SequencesCreated = %d, want 2", applier.SequencesCreated)
+	}
+	if applier.SequenceOwnersSet != 2 {
+		t.Errorf("SequenceOwnersSet = %d, want 2", applier.SequenceOwnersSet)
+	}
+	if len(m.sequences) != 2 {
+		t.Errorf("len(m.sequences) = %d, want 2", len(m.sequences))
 	}
 }
 
-func TestMigrator_CreateViews_DryRun(t *testing.T) {
+func TestMigrator_CreateExtensions(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -534,16 +772,16 @@ func TestMigrator_CreateViews_DryRun(t *testing.T) {
 	defer destDB.Close()
 
 	extractor := &MockExtractor{
-		Views: []schema.ViewDef{
-			{Name: "user_stats"},
+		Extensions: []schema.ExtensionDef{
+			{Name: "citext", Schema: "public", Version: "1.6"},
+			{Name: "pgcrypto", Schema: "public", Version: "1.3"},
 		},
 	}
 	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			Source: config.DatabaseConfig{Database: "testdb"},
-			DryRun: true,
+			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
@@ -551,18 +789,25 @@ func TestMigrator_CreateViews_DryRun(t *testing.T) {
 		applier:    applier,
 	}
 
-	err = m.createViews()
-	if err != nil {
-		t.Errorf("createViews() error = %v", err)
+	if err := m.createExtensions(); err != nil {
+		t.Errorf("createExtensions() error = %v", err)
+	}
+	if applier.ExtensionsCreated != 2 {
+		t.Errorf("ExtensionsCreated = %d, want 2", applier.ExtensionsCreated)
 	}
 
-	// Dry run should not create views
-	if applier.ViewsCreated != 0 {
-		t.Errorf("ViewsCreated in dry run = %d, want 0", applier.ViewsCreated)
+	// Dry run lists extensions without creating them
+	applier.ExtensionsCreated = 0
+	m.config.DryRun = true
+	if err := m.createExtensions(); err != nil {
+		t.Errorf("createExtensions() dry run error = %v", err)
+	}
+	if applier.ExtensionsCreated != 0 {
+		t.Errorf("ExtensionsCreated in dry run = %d, want 0", applier.ExtensionsCreated)
 	}
 }
 
-func TestMigrator_MigrateData_DryRun(t *testing.T) {
+func TestMigrator_CreateIndexesAndConstraints(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -575,42 +820,83 @@ func TestMigrator_MigrateData_DryRun(t *testing.T) {
 	}
 	defer destDB.Close()
 
-	transferer := &MockTransferer{
-		RowsCopied: 100,
-	}
+	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			DryRun: true,
+			DryRun: false,
 		},
-		sourceConn: sourceDB,
-		destConn:   destDB,
-		transferer: transferer,
+		destConn: destDB,
+		applier:  applier,
 		tables: []schema.TableSchema{
-			{Name: "users"},
-			{Name: "orders"},
+			{
+				Name: "users",
+				Indexes: []schema.IndexDef{
+					{Name: "idx_email"},
+				},
+				ForeignKeys: []schema.ForeignKeyDef{
+					{Name: "fk_user"},
+				},
+			},
+			{
+				Name: "orders",
+				Indexes: []schema.IndexDef{
+					{Name: "idx_date"},
+					{Name: "idx_user"},
+				},
+				ForeignKeys: []schema.ForeignKeyDef{},
+			},
 		},
 	}
 
-	err = m.migrateData()
+	err = m.createIndexesAndConstraints()
 	if err != nil {
-		t.Errorf("migrateData() error = %v", err)
+		t.Errorf("createIndexesAndConstraints() error = %v", err)
 	}
 
-	// Dry run should not disable FK checks or transfer data
-	if transferer.DisableFKCalls != 0 {
-		t.Errorf("DisableFKCalls in dry run = %d, want 0", transferer.DisableFKCalls)
+	if applier.IndexesCreated != 3 {
+		t.Errorf("IndexesCreated = %d, want 3", applier.IndexesCreated)
 	}
-	if transferer.TransferCalls != 0 {
-		t.Errorf("TransferCalls in dry run = %d, want 0", transferer.TransferCalls)
+	if applier.FKsCreated != 1 {
+		t.Errorf("FKsCreated = %d, want 1", applier.FKsCreated)
 	}
-	// But totalRows should be estimated
-	if m.totalRows != 200 { // 2 tables * 100 rows each
-		t.Errorf("totalRows in dry run = %d, want 200", m.totalRows)
+}
+
+func TestMigrator_CreateIndexesAndConstraints_DryRun(t *testing.T) {
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	applier := &MockApplier{}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			DryRun: true,
+		},
+		destConn: destDB,
+		applier:  applier,
+		tables: []schema.TableSchema{
+			{
+				Name:    "users",
+				Indexes: []schema.IndexDef{{Name: "idx_email"}},
+			},
+		},
+	}
+
+	err = m.createIndexesAndConstraints()
+	if err != nil {
+		t.Errorf("createIndexesAndConstraints() error = %v", err)
+	}
+
+	// Dry run should not create anything
+	if applier.IndexesCreated != 0 {
+		t.Errorf("IndexesCreated in dry run = %d, want 0", applier.IndexesCreated)
 	}
 }
 
-func TestMigrator_MigrateData_Transfer(t *testing.T) {
+func TestMigrator_CreateViews(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -623,40 +909,36 @@ func TestMigrator_MigrateData_Transfer(t *testing.T) {
 	}
 	defer destDB.Close()
 
-	transferer := &MockTransferer{
-		RowsCopied: 50,
+	extractor := &MockExtractor{
+		Views: []schema.ViewDef{
+			{Name: "user_stats"},
+			{Name: "order_summary"},
+		},
 	}
+	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
+			Source: config.DatabaseConfig{Database: "testdb"},
 			DryRun: false,
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
-		transferer: transferer,
-		tables: []schema.TableSchema{
-			{Name: "users"},
-			{Name: "orders"},
-		},
+		extractor:  extractor,
+		applier:    applier,
 	}
 
-	err = m.migrateData()
+	err = m.createViews()
 	if err != nil {
-		t.Errorf("migrateData() error = %v", err)
+		t.Errorf("createViews() error = %v", err)
 	}
 
-	if transferer.DisableFKCalls != 1 {
-		t.Errorf("DisableFKCalls = %d, want 1", transferer.DisableFKCalls)
-	}
-	if transferer.TransferCalls != 2 {
-		t.Errorf("TransferCalls = %d, want 2", transferer.TransferCalls)
-	}
-	if m.totalRows != 100 { // 2 tables * 50 rows each
-		t.Errorf("totalRows = %d, want 100", m.totalRows)
+	if applier.ViewsCreated != 2 {
+		t.Errorf("ViewsCreated = %d, want 2", applier.ViewsCreated)
 	}
 }
 
-func TestMigrator_Finalize_DryRun(t *testing.T) {
+func TestMigrator_CreateViews_NoViews(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -669,32 +951,34 @@ func TestMigrator_Finalize_DryRun(t *testing.T) {
 	}
 	defer destDB.Close()
 
-	transferer := &MockTransferer{}
+	extractor := &MockExtractor{
+		Views: []schema.ViewDef{},
+	}
+	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			DryRun: true,
+			Source: config.DatabaseConfig{Database: "testdb"},
+			DryRun: false,
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
-		transferer: transferer,
-		tables: []schema.TableSchema{
-			{Name: "users"},
-		},
+		extractor:  extractor,
+		applier:    applier,
 	}
 
-	err = m.finalize()
+	err = m.createViews()
 	if err != nil {
-		t.Errorf("finalize() error = %v", err)
+		t.Errorf("createViews() error = %v", err)
 	}
 
-	// Dry run should not enable FK checks
-	if transferer.EnableFKCalls != 0 {
-		t.Errorf("EnableFKCalls in dry run = %d, want 0", transferer.EnableFKCalls)
+	// No views to create
+	if applier.ViewsCreated != 0 {
+		t.Errorf("ViewsCreated = %d, want 0", applier.ViewsCreated)
 	}
 }
 
-func TestMigrator_Finalize_EnableFKAndVerify(t *testing.T) {
+func TestMigrator_MigrateSequences_MySQL(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -707,33 +991,23 @@ func TestMigrator_Finalize_EnableFKAndVerify(t *testing.T) {
 	}
 	defer destDB.Close()
 
-	transferer := &MockTransferer{
-		RowsCopied: 100, // Same count for source and dest
-	}
-
 	m := &Migrator{
 		config: config.MigrationConfig{
+			Source: config.DatabaseConfig{Engine: "mysql"},
 			DryRun: false,
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
-		transferer: transferer,
-		tables: []schema.TableSchema{
-			{Name: "users"},
-		},
 	}
 
-	err = m.finalize()
+	// MySQL should skip sequences
+	err = m.migrateSequences()
 	if err != nil {
-		t.Errorf("finalize() error = %v", err)
-	}
-
-	if transferer.EnableFKCalls != 1 {
-		t.Errorf("EnableFKCalls = %d, want 1", transferer.EnableFKCalls)
+		t.Errorf("migrateSequences() error = %v", err)
 	}
 }
 
-func TestMigrator_MigrateSchema_Error(t *testing.T) {
+func TestMigrator_MigrateSequences_Postgres(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -747,13 +1021,16 @@ func TestMigrator_MigrateSchema_Error(t *testing.T) {
 	defer destDB.Close()
 
 	extractor := &MockExtractor{
-		Err: sqlmock.ErrCancelled,
+		Sequences: []schema.SequenceDef{
+			{Name: "users_id_seq", CurrentVal: 100},
+			{Name: "orders_id_seq", CurrentVal: 50},
+		},
 	}
 	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			Source: config.DatabaseConfig{Database: "testdb"},
+			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
 			DryRun: false,
 		},
 		sourceConn: sourceDB,
@@ -762,13 +1039,17 @@ func TestMigrator_MigrateSchema_Error(t *testing.T) {
 		applier:    applier,
 	}
 
-	err = m.migrateSchema()
-	if err == nil {
-		t.Errorf("migrateSchema() expected error, got nil")
+	err = m.migrateSequences()
+	if err != nil {
+		t.Errorf("migrateSequences() error = %v", err)
+	}
+
+	if applier.SequencesSet != 2 {
+		t.Errorf("SequencesSet = %d, want 2", applier.SequencesSet)
 	}
 }
 
-func TestMigrator_CreateViews_ExtractError(t *testing.T) {
+func TestMigrator_MigrateSequences_NoSequences(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -782,13 +1063,13 @@ func TestMigrator_CreateViews_ExtractError(t *testing.T) {
 	defer destDB.Close()
 
 	extractor := &MockExtractor{
-		Err: sqlmock.ErrCancelled,
+		Sequences: []schema.SequenceDef{},
 	}
 	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			Source: config.DatabaseConfig{Database: "testdb"},
+			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
 			DryRun: false,
 		},
 		sourceConn: sourceDB,
@@ -797,13 +1078,13 @@ func TestMigrator_CreateViews_ExtractError(t *testing.T) {
 		applier:    applier,
 	}
 
-	err = m.createViews()
-	if err == nil {
-		t.Errorf("createViews() expected error, got nil")
+	err = m.migrateSequences()
+	if err != nil {
+		t.Errorf("migrateSequences() error = %v", err)
 	}
 }
 
-func TestMigrator_MigrateSequences_ExtractError(t *testing.T) {
+func TestMigrator_MigrateSequences_DryRun(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -817,14 +1098,16 @@ func TestMigrator_MigrateSequences_ExtractError(t *testing.T) {
 	defer destDB.Close()
 
 	extractor := &MockExtractor{
-		Err: sqlmock.ErrCancelled,
+		Sequences: []schema.SequenceDef{
+			{Name: "users_id_seq", CurrentVal: 100},
+		},
 	}
 	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
 			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
-			DryRun: false,
+			DryRun: true,
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
@@ -833,12 +1116,17 @@ func TestMigrator_MigrateSequences_ExtractError(t *testing.T) {
 	}
 
 	err = m.migrateSequences()
-	if err == nil {
-		t.Errorf("migrateSequences() expected error, got nil")
+	if err != nil {
+		t.Errorf("migrateSequences() error = %v", err)
+	}
+
+	// Dry run should not set sequence values
+	if applier.SequencesSet != 0 {
+		t.Errorf("SequencesSet in dry run = %d, want 0", applier.SequencesSet)
 	}
 }
 
-func TestMigrator_CreateTable_Error(t *testing.T) {
+func TestMigrator_CreateViews_DryRun(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -852,19 +1140,16 @@ func TestMigrator_CreateTable_Error(t *testing.T) {
 	defer destDB.Close()
 
 	extractor := &MockExtractor{
-		Tables: []schema.TableSchema{
-			{Name: "users"},
+		Views: []schema.ViewDef{
+			{Name: "user_stats"},
 		},
 	}
-	applier := &MockApplier{
-		Err: sqlmock.ErrCancelled,
-	}
+	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			Source:      config.DatabaseConfig{Database: "testdb"},
-			Destination: config.DatabaseConfig{Database: "testdb_dest"},
-			DryRun:      false,
+			Source: config.DatabaseConfig{Database: "testdb"},
+			DryRun: true,
 		},
 		sourceConn: sourceDB,
 		destConn:   destDB,
@@ -872,79 +1157,112 @@ func TestMigrator_CreateTable_Error(t *testing.T) {
 		applier:    applier,
 	}
 
-	err = m.migrateSchema()
-	if err == nil {
-		t.Errorf("migrateSchema() with CreateTable error expected error, got nil")
+	err = m.createViews()
+	if err != nil {
+		t.Errorf("createViews() error = %v", err)
+	}
+
+	// Dry run should not create views
+	if applier.ViewsCreated != 0 {
+		t.Errorf("ViewsCreated in dry run = %d, want 0", applier.ViewsCreated)
 	}
 }
 
-func TestMigrator_CreateIndex_Error(t *testing.T) {
+func TestMigrator_MigrateData_DryRun(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
 	destDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create dest mock: %v", err)
 	}
 	defer destDB.Close()
 
-	applier := &MockApplier{
-		Err: sqlmock.ErrCancelled,
+	transferer := &MockTransferer{
+		RowsCopied: 100,
 	}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			DryRun: false,
+			DryRun: true,
 		},
-		destConn: destDB,
-		applier:  applier,
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		transferer: transferer,
 		tables: []schema.TableSchema{
-			{
-				Name:    "users",
-				Indexes: []schema.IndexDef{{Name: "idx_email"}},
-			},
+			{Name: "users"},
+			{Name: "orders"},
 		},
 	}
 
-	err = m.createIndexesAndConstraints()
-	if err == nil {
-		t.Errorf("createIndexesAndConstraints() with CreateIndex error expected error, got nil")
+	err = m.migrateData()
+	if err != nil {
+		t.Errorf("migrateData() error = %v", err)
+	}
+
+	// Dry run should not disable FK checks or transfer data
+	if transferer.DisableFKCalls != 0 {
+		t.Errorf("DisableFKCalls in dry run = %d, want 0", transferer.DisableFKCalls)
+	}
+	if transferer.TransferCalls != 0 {
+		t.Errorf("TransferCalls in dry run = %d, want 0", transferer.TransferCalls)
+	}
+	// But totalRows should be estimated
+	if m.totalRows != 200 { // 2 tables * 100 rows each
+		t.Errorf("totalRows in dry run = %d, want 200", m.totalRows)
 	}
 }
 
-func TestMigrator_CreateForeignKey_Error(t *testing.T) {
+func TestMigrator_MigrateData_Transfer(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
 	destDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create dest mock: %v", err)
 	}
 	defer destDB.Close()
 
-	// Applier that succeeds on indexes but fails on FKs
-	applier := &MockApplier{}
-	applier.Err = nil
+	transferer := &MockTransferer{
+		RowsCopied: 50,
+	}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
 			DryRun: false,
 		},
-		destConn: destDB,
-		applier:  applier,
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		transferer: transferer,
 		tables: []schema.TableSchema{
-			{
-				Name:        "users",
-				Indexes:     []schema.IndexDef{},
-				ForeignKeys: []schema.ForeignKeyDef{{Name: "fk_user"}},
-			},
+			{Name: "users"},
+			{Name: "orders"},
 		},
 	}
 
-	// Set error after init
-	applier.Err = sqlmock.ErrCancelled
+	err = m.migrateData()
+	if err != nil {
+		t.Errorf("migrateData() error = %v", err)
+	}
 
-	err = m.createIndexesAndConstraints()
-	if err == nil {
-		t.Errorf("createIndexesAndConstraints() with CreateForeignKey error expected error, got nil")
+	if transferer.DisableFKCalls != 1 {
+		t.Errorf("DisableFKCalls = %d, want 1", transferer.DisableFKCalls)
+	}
+	if transferer.TransferCalls != 2 {
+		t.Errorf("TransferCalls = %d, want 2", transferer.TransferCalls)
+	}
+	if m.totalRows != 100 { // 2 tables * 50 rows each
+		t.Errorf("totalRows = %d, want 100", m.totalRows)
 	}
 }
 
-func TestMigrator_CreateView_Error(t *testing.T) {
+func TestMigrator_MigrateData_SkipsPartitionedParents(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -957,14 +1275,177 @@ func TestMigrator_CreateView_Error(t *testing.T) {
 	}
 	defer destDB.Close()
 
-	extractor := &MockExtractor{
-		Views: []schema.ViewDef{
-			{Name: "user_stats"},
+	transferer := &MockTransferer{
+		RowsCopied: 10,
+	}
+
+	m := &Migrator{
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		transferer: transferer,
+		tables: []schema.TableSchema{
+			{Schema: "public", Name: "events", PartitionKey: "RANGE (created_at)"},
+			{Schema: "public", Name: "events_2023", PartitionOf: "public.events"},
+			{Schema: "public", Name: "events_2024", PartitionOf: "public.events"},
 		},
 	}
-	applier := &MockApplier{
+
+	if err := m.migrateData(); err != nil {
+		t.Fatalf("migrateData() error = %v", err)
+	}
+
+	// Rows are copied once, through the leaf partitions
+	if transferer.TransferCalls != 2 {
+		t.Errorf("TransferCalls = %d, want 2", transferer.TransferCalls)
+	}
+	if m.totalRows != 20 {
+		t.Errorf("totalRows = %d, want 20", m.totalRows)
+	}
+}
+
+func TestMigrator_MigrateData_RetriesPartitionedTable(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	partitions := []schema.PartitionDef{{Name: "p2023"}, {Name: "p2024"}}
+	tests := []struct {
+		name      string
+		table     schema.TableSchema
+		wantCalls int
+		wantErr   bool
+	}{
+		{"kept partitioning is retried", schema.TableSchema{Name: "logs", Partitions: partitions}, 2, false},
+		{"dropped partitioning isn't", schema.TableSchema{Name: "logs", Partitions: partitions, PartitioningDropped: true}, 1, true},
+		{"unpartitioned table isn't", schema.TableSchema{Name: "users"}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transferer := &MockTransferer{
+				RowsCopied:   10,
+				TransferErrs: []error{errors.New("partition p2024: connection reset")},
+			}
+			m := &Migrator{
+				sourceConn: sourceDB,
+				destConn:   destDB,
+				transferer: transferer,
+				tables:     []schema.TableSchema{tt.table},
+			}
+
+			err := m.migrateData()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("migrateData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if transferer.TransferCalls != tt.wantCalls {
+				t.Errorf("TransferCalls = %d, want %d", transferer.TransferCalls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestMigrator_Finalize_DryRun(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	transferer := &MockTransferer{}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			DryRun: true,
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		transferer: transferer,
+		tables: []schema.TableSchema{
+			{Name: "users"},
+		},
+	}
+
+	err = m.finalize()
+	if err != nil {
+		t.Errorf("finalize() error = %v", err)
+	}
+
+	// Dry run should not enable FK checks
+	if transferer.EnableFKCalls != 0 {
+		t.Errorf("EnableFKCalls in dry run = %d, want 0", transferer.EnableFKCalls)
+	}
+}
+
+func TestMigrator_Finalize_EnableFKAndVerify(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	transferer := &MockTransferer{
+		RowsCopied: 100, // Same count for source and dest
+	}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			DryRun: false,
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		transferer: transferer,
+		tables: []schema.TableSchema{
+			{Name: "users"},
+		},
+	}
+
+	err = m.finalize()
+	if err != nil {
+		t.Errorf("finalize() error = %v", err)
+	}
+
+	if transferer.EnableFKCalls != 1 {
+		t.Errorf("EnableFKCalls = %d, want 1", transferer.EnableFKCalls)
+	}
+}
+
+func TestMigrator_MigrateSchema_Error(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	extractor := &MockExtractor{
 		Err: sqlmock.ErrCancelled,
 	}
+	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
@@ -977,13 +1458,13 @@ func TestMigrator_CreateView_Error(t *testing.T) {
 		applier:    applier,
 	}
 
-	err = m.createViews()
+	err = m.migrateSchema()
 	if err == nil {
-		t.Errorf("createViews() with CreateView error expected error, got nil")
+		t.Errorf("migrateSchema() expected error, got nil")
 	}
 }
 
-func TestMigrator_SetSequenceValue_Error(t *testing.T) {
+func TestMigrator_CreateViews_ExtractError(t *testing.T) {
 	sourceDB, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create source mock: %v", err)
@@ -997,17 +1478,13 @@ func TestMigrator_SetSequenceValue_Error(t *testing.T) {
 	defer destDB.Close()
 
 	extractor := &MockExtractor{
-		Sequences: []schema.SequenceDef{
-			{Name: "users_id_seq", CurrentVal: 100},
-		},
-	}
-	applier := &MockApplier{
 		Err: sqlmock.ErrCancelled,
 	}
+	applier := &MockApplier{}
 
 	m := &Migrator{
 		config: config.MigrationConfig{
-			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
+			Source: config.DatabaseConfig{Database: "testdb"},
 			DryRun: false,
 		},
 		sourceConn: sourceDB,
@@ -1016,34 +1493,253 @@ func TestMigrator_SetSequenceValue_Error(t *testing.T) {
 		applier:    applier,
 	}
 
-	err = m.migrateSequences()
+	err = m.createViews()
 	if err == nil {
-		t.Errorf("migrateSequences() with SetSequenceValue error expected error, got nil")
+		t.Errorf("createViews() expected error, got nil")
 	}
 }
 
-func TestMigrator_Struct_Fields(t *testing.T) {
+func TestMigrator_MigrateSequences_ExtractError(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	extractor := &MockExtractor{
+		Err: sqlmock.ErrCancelled,
+	}
+	applier := &MockApplier{}
+
 	m := &Migrator{
 		config: config.MigrationConfig{
-			DryRun: true,
-		},
-		tables: []schema.TableSchema{
-			{Name: "users"},
-		},
-		views: []schema.ViewDef{
-			{Name: "user_stats"},
-		},
-		sequences: []schema.SequenceDef{
-			{Name: "users_id_seq"},
+			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
+			DryRun: false,
 		},
-		totalRows: 1000,
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    applier,
 	}
 
-	if !m.config.DryRun {
-		t.Errorf("Migrator.config.DryRun = %v, want true", m.config.DryRun)
+	err = m.migrateSequences()
+	if err == nil {
+		t.Errorf("migrateSequences() expected error, got nil")
 	}
-	if len(m.tables) != 1 {
-		t.Errorf("len(Migrator.tables) = %d, want 1", len(m.tables))
+}
+
+func TestMigrator_CreateTable_Error(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	extractor := &MockExtractor{
+		Tables: []schema.TableSchema{
+			{Name: "users"},
+		},
+	}
+	applier := &MockApplier{
+		Err: sqlmock.ErrCancelled,
+	}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source:      config.DatabaseConfig{Database: "testdb"},
+			Destination: config.DatabaseConfig{Database: "testdb_dest"},
+			DryRun:      false,
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    applier,
+	}
+
+	err = m.migrateSchema()
+	if err == nil {
+		t.Errorf("migrateSchema() with CreateTable error expected error, got nil")
+	}
+}
+
+func TestMigrator_CreateIndex_Error(t *testing.T) {
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	applier := &MockApplier{
+		Err: sqlmock.ErrCancelled,
+	}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			DryRun: false,
+		},
+		destConn: destDB,
+		applier:  applier,
+		tables: []schema.TableSchema{
+			{
+				Name:    "users",
+				Indexes: []schema.IndexDef{{Name: "idx_email"}},
+			},
+		},
+	}
+
+	err = m.createIndexesAndConstraints()
+	if err == nil {
+		t.Errorf("createIndexesAndConstraints() with CreateIndex error expected error, got nil")
+	}
+}
+
+func TestMigrator_CreateForeignKey_Error(t *testing.T) {
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	// Applier that succeeds on indexes but fails on FKs
+	applier := &MockApplier{}
+	applier.Err = nil
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			DryRun: false,
+		},
+		destConn: destDB,
+		applier:  applier,
+		tables: []schema.TableSchema{
+			{
+				Name:        "users",
+				Indexes:     []schema.IndexDef{},
+				ForeignKeys: []schema.ForeignKeyDef{{Name: "fk_user"}},
+			},
+		},
+	}
+
+	// Set error after init
+	applier.Err = sqlmock.ErrCancelled
+
+	err = m.createIndexesAndConstraints()
+	if err == nil {
+		t.Errorf("createIndexesAndConstraints() with CreateForeignKey error expected error, got nil")
+	}
+}
+
+func TestMigrator_CreateView_Error(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	extractor := &MockExtractor{
+		Views: []schema.ViewDef{
+			{Name: "user_stats"},
+		},
+	}
+	applier := &MockApplier{
+		Err: sqlmock.ErrCancelled,
+	}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source: config.DatabaseConfig{Database: "testdb"},
+			DryRun: false,
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    applier,
+	}
+
+	err = m.createViews()
+	if err == nil {
+		t.Errorf("createViews() with CreateView error expected error, got nil")
+	}
+}
+
+func TestMigrator_SetSequenceValue_Error(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	extractor := &MockExtractor{
+		Sequences: []schema.SequenceDef{
+			{Name: "users_id_seq", CurrentVal: 100},
+		},
+	}
+	applier := &MockApplier{
+		Err: sqlmock.ErrCancelled,
+	}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
+			DryRun: false,
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    applier,
+	}
+
+	err = m.migrateSequences()
+	if err == nil {
+		t.Errorf("migrateSequences() with SetSequenceValue error expected error, got nil")
+	}
+}
+
+func TestMigrator_Struct_Fields(t *testing.T) {
+	m := &Migrator{
+		config: config.MigrationConfig{
+			DryRun: true,
+		},
+		tables: []schema.TableSchema{
+			{Name: "users"},
+		},
+		views: []schema.ViewDef{
+			{Name: "user_stats"},
+		},
+		sequences: []schema.SequenceDef{
+			{Name: "users_id_seq"},
+		},
+		totalRows: 1000,
+	}
+
+	if !m.config.DryRun {
+		t.Errorf("Migrator.config.DryRun = %v, want true", m.config.DryRun)
+	}
+	if len(m.tables) != 1 {
+		t.Errorf("len(Migrator.tables) = %d, want 1", len(m.tables))
 	}
 	if len(m.views) != 1 {
 		t.Errorf("len(Migrator.views) = %d, want 1", len(m.views))
@@ -1055,3 +1751,546 @@ func TestMigrator_Struct_Fields(t *testing.T) {
 		t.Errorf("Migrator.totalRows = %d, want 1000", m.totalRows)
 	}
 }
+
+func TestMigrator_CreateRoutinesAndTriggers(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	extractor := &MockExtractor{
+		Routines: []schema.RoutineDef{
+			{Schema: "public", Name: "touch_updated_at", Kind: "function"},
+		},
+		Triggers: []schema.TriggerDef{
+			{Schema: "public", Table: "users", Name: "users_touch"},
+			{Schema: "public", Table: "orders", Name: "orders_touch"},
+		},
+	}
+	applier := &MockApplier{}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    applier,
+	}
+
+	if err := m.createRoutines(); err != nil {
+		t.Errorf("createRoutines() error = %v", err)
+	}
+	if err := m.createTriggers(); err != nil {
+		t.Errorf("createTriggers() error = %v", err)
+	}
+
+	if applier.RoutinesCreated != 1 {
+		t.Errorf("RoutinesCreated = %d, want 1", applier.RoutinesCreated)
+	}
+	if applier.TriggersCreated != 2 {
+		t.Errorf("TriggersCreated = %d, want 2", applier.TriggersCreated)
+	}
+}
+
+func TestMigrator_CreateRoutines_RecordsDisabledEvents(t *testing.T) {
+	extractor := &MockExtractor{
+		Routines: []schema.RoutineDef{
+			{Name: "archive_orders", Kind: "procedure"},
+			{Name: "nightly_archive", Kind: "event", Disabled: true},
+			{Name: "paused_report", Kind: "event"},
+		},
+	}
+	applier := &MockApplier{}
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source: config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
+		},
+		extractor: extractor,
+		applier:   applier,
+	}
+
+	if err := m.createRoutines(); err != nil {
+		t.Fatalf("createRoutines() error = %v", err)
+	}
+	if applier.RoutinesCreated != 3 {
+		t.Errorf("RoutinesCreated = %d, want 3", applier.RoutinesCreated)
+	}
+	if want := []string{"nightly_archive"}; !reflect.DeepEqual(m.disabledEvents, want) {
+		t.Errorf("disabledEvents = %v, want %v", m.disabledEvents, want)
+	}
+}
+
+// orderingApplier records the order objects are created in, and rejects the routines named in
+// needsTables until a table exists
+type orderingApplier struct {
+	*MockApplier
+	created     []string
+	needsTables map[string]bool
+}
+
+func (a *orderingApplier) CreateTable(db *sql.DB, table schema.TableSchema) error {
+	a.created = append(a.created, "table "+table.Name)
+	return a.MockApplier.CreateTable(db, table)
+}
+
+func (a *orderingApplier) CreateRoutine(db *sql.DB, routine schema.RoutineDef) error {
+	if a.needsTables[routine.Name] && a.TablesCreated == 0 {
+		return errors.New("type users does not exist")
+	}
+	a.created = append(a.created, routine.Kind+" "+routine.Name)
+	return a.MockApplier.CreateRoutine(db, routine)
+}
+
+func TestMigrator_CreatesFunctionsBeforeTables(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	extractor := &MockExtractor{
+		Tables: []schema.TableSchema{{Schema: "public", Name: "users"}},
+		Routines: []schema.RoutineDef{
+			{Schema: "public", Name: "slugify", Kind: "function"},
+			{Schema: "public", Name: "archive_users", Kind: "procedure"},
+			{Schema: "public", Name: "user_label", Kind: "function"},
+		},
+	}
+	applier := &orderingApplier{MockApplier: &MockApplier{}, needsTables: map[string]bool{"user_label": true}}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source:      config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "pgsql", Database: "testdb_dest"},
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    applier,
+	}
+
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
+	}
+	if err := m.createRoutines(); err != nil {
+		t.Fatalf("createRoutines() error = %v", err)
+	}
+
+	// Functions come before the tables whose defaults may call them; procedures and functions
+	// that need the tables come after
+	want := []string{"function slugify", "table users", "procedure archive_users", "function user_label"}
+	if !reflect.DeepEqual(applier.created, want) {
+		t.Errorf("created = %v, want %v", applier.created, want)
+	}
+}
+
+func TestMigrator_CreateFunctions_OnlyForPostgres(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	extractor := &MockExtractor{
+		Tables:   []schema.TableSchema{{Name: "users"}},
+		Routines: []schema.RoutineDef{{Name: "slugify", Kind: "function"}},
+	}
+	applier := &orderingApplier{MockApplier: &MockApplier{}}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source:      config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "mysql", Database: "testdb_dest"},
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    applier,
+	}
+
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
+	}
+	if err := m.createRoutines(); err != nil {
+		t.Fatalf("createRoutines() error = %v", err)
+	}
+
+	// MySQL routines are all created after the data load
+	want := []string{"table users", "function slugify"}
+	if !reflect.DeepEqual(applier.created, want) {
+		t.Errorf("created = %v, want %v", applier.created, want)
+	}
+}
+
+// failingRoutineApplier rejects a routine every time, with a different error each time
+type failingRoutineApplier struct {
+	*MockApplier
+	attempts int
+}
+
+func (a *failingRoutineApplier) CreateRoutine(db *sql.DB, routine schema.RoutineDef) error {
+	a.attempts++
+	return fmt.Errorf("attempt %d: function %s failed", a.attempts, routine.Name)
+}
+
+func TestMigrator_CreateRoutines_ReportsEarlyFunctionFailure(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	extractor := &MockExtractor{
+		Tables:   []schema.TableSchema{{Schema: "public", Name: "users"}},
+		Routines: []schema.RoutineDef{{Schema: "public", Name: "slugify", Kind: "function"}},
+	}
+	applier := &failingRoutineApplier{MockApplier: &MockApplier{}}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source:      config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "pgsql", Database: "testdb_dest"},
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  extractor,
+		applier:    applier,
+	}
+
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
+	}
+	if applier.TablesCreated != 1 {
+		t.Errorf("TablesCreated = %d, want 1", applier.TablesCreated)
+	}
+
+	// The retry fails too, and both failures are reported
+	err = m.createRoutines()
+	if err == nil {
+		t.Fatal("createRoutines() error = nil, want the retry's error")
+	}
+	for _, want := range []string{"attempt 2: function slugify failed", "attempt 1: function slugify failed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("createRoutines() error = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestMigrator_CreateTriggers_DryRun(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	extractor := &MockExtractor{
+		Triggers: []schema.TriggerDef{{Schema: "public", Table: "users", Name: "users_touch"}},
+	}
+	applier := &MockApplier{}
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source: config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
+			DryRun: true,
+		},
+		sourceConn: sourceDB,
+		extractor:  extractor,
+		applier:    applier,
+	}
+
+	if err := m.createTriggers(); err != nil {
+		t.Errorf("createTriggers() error = %v", err)
+	}
+	if applier.TriggersCreated != 0 {
+		t.Errorf("TriggersCreated in dry run = %d, want 0", applier.TriggersCreated)
+	}
+}
+
+func TestMigrator_RefreshMaterializedViews(t *testing.T) {
+	applier := &MockApplier{}
+	m := &Migrator{
+		config:  config.MigrationConfig{RefreshMaterializedViews: true},
+		applier: applier,
+		views: []schema.ViewDef{
+			{Name: "plain_view"},
+			{Name: "daily_totals", Materialized: true, Populated: true},
+			{Name: "never_loaded", Materialized: true, Populated: false},
+		},
+	}
+
+	if err := m.refreshMaterializedViews(); err != nil {
+		t.Fatalf("refreshMaterializedViews() error = %v", err)
+	}
+
+	// Only matviews populated on the source are refreshed
+	if applier.MatviewsRefreshed != 1 {
+		t.Errorf("MatviewsRefreshed = %d, want 1", applier.MatviewsRefreshed)
+	}
+}
+
+func TestMigrator_RefreshMaterializedViews_Disabled(t *testing.T) {
+	applier := &MockApplier{}
+	m := &Migrator{
+		config:  config.MigrationConfig{RefreshMaterializedViews: false},
+		applier: applier,
+		views:   []schema.ViewDef{{Name: "daily_totals", Materialized: true, Populated: true}},
+	}
+
+	if err := m.refreshMaterializedViews(); err != nil {
+		t.Fatalf("refreshMaterializedViews() error = %v", err)
+	}
+	if applier.MatviewsRefreshed != 0 {
+		t.Errorf("MatviewsRefreshed = %d, want 0 when disabled", applier.MatviewsRefreshed)
+	}
+}
+
+func TestMigrator_RefreshMaterializedViews_Error(t *testing.T) {
+	applier := &MockApplier{Err: sqlmock.ErrCancelled}
+	m := &Migrator{
+		config:  config.MigrationConfig{RefreshMaterializedViews: true},
+		applier: applier,
+		views:   []schema.ViewDef{{Name: "daily_totals", Materialized: true, Populated: true}},
+	}
+
+	if err := m.refreshMaterializedViews(); err == nil {
+		t.Error("refreshMaterializedViews() expected error, got nil")
+	}
+}
+
+func TestMigrator_MigrateSchema_Translates(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	translated := []schema.TableSchema{{Name: "users", CreateStmt: `CREATE TABLE "users" ("id" bigint)`}}
+	applier := &MockApplier{}
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source:      config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "pgsql", Database: "testdb_dest"},
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		extractor:  &MockExtractor{Tables: []schema.TableSchema{{Name: "users"}}},
+		applier:    applier,
+		translator: &MockTranslator{
+			Tables: translated,
+			Types:  []schema.TypeDef{{Name: "users_role", Kind: "enum"}},
+			Lossy:  []schema.LossyConversion{{Object: "users.active", Detail: "tinyint(1) becomes boolean"}},
+		},
+	}
+
+	if err := m.migrateSchema(); err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
+	}
+	if applier.TablesCreated != 1 || applier.TypesCreated != 1 {
+		t.Errorf("created %d tables and %d types, want 1 and 1", applier.TablesCreated, applier.TypesCreated)
+	}
+	if len(m.tables) != 1 || m.tables[0].CreateStmt != translated[0].CreateStmt {
+		t.Errorf("tables = %+v, want the translated tables", m.tables)
+	}
+}
+
+func TestMigrator_CrossEngine_SkipsViewsRoutinesAndTriggers(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	extractor := &MockExtractor{
+		Views:    []schema.ViewDef{{Name: "user_stats"}},
+		Routines: []schema.RoutineDef{{Name: "total", Kind: "function"}},
+		Triggers: []schema.TriggerDef{{Name: "audit", Table: "users"}},
+	}
+	applier := &MockApplier{}
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source:      config.DatabaseConfig{Engine: "mysql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "pgsql", Database: "testdb_dest"},
+		},
+		sourceConn: sourceDB,
+		extractor:  extractor,
+		applier:    applier,
+		translator: &MockTranslator{},
+	}
+
+	if err := m.createRoutines(); err != nil {
+		t.Errorf("createRoutines() error = %v", err)
+	}
+	if err := m.createViews(); err != nil {
+		t.Errorf("createViews() error = %v", err)
+	}
+	if err := m.createTriggers(); err != nil {
+		t.Errorf("createTriggers() error = %v", err)
+	}
+	if applier.RoutinesCreated+applier.ViewsCreated+applier.TriggersCreated != 0 {
+		t.Errorf("created %d routines, %d views and %d triggers, want none", applier.RoutinesCreated, applier.ViewsCreated, applier.TriggersCreated)
+	}
+}
+
+func TestMigrator_MigrateSequences_SyncsIdentities(t *testing.T) {
+	applier := &MockApplier{}
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source:      config.DatabaseConfig{Engine: "mysql"},
+			Destination: config.DatabaseConfig{Engine: "pgsql"},
+		},
+		applier:    applier,
+		translator: &MockTranslator{},
+		tables: []schema.TableSchema{
+			{Name: "users", Columns: []schema.ColumnDef{{Name: "id", Extra: "IDENTITY BY DEFAULT"}, {Name: "email"}}},
+			{Name: "tags", Columns: []schema.ColumnDef{{Name: "name"}}},
+		},
+	}
+
+	if err := m.migrateSequences(); err != nil {
+		t.Fatalf("migrateSequences() error = %v", err)
+	}
+	if applier.IdentitiesSynced != 1 {
+		t.Errorf("IdentitiesSynced = %d, want 1", applier.IdentitiesSynced)
+	}
+}
+
+// MockViewTranslator implements schema.Translator and schema.ViewTranslator for testing
+type MockViewTranslator struct {
+	MockTranslator
+	Untranslatable map[string]bool
+}
+
+func (m *MockViewTranslator) TranslateView(view schema.ViewDef) (schema.ViewDef, error) {
+	if m.Untranslatable[view.Name] {
+		return schema.ViewDef{}, errors.New("cast to interval isn't translated")
+	}
+	view.CreateStmt = "CREATE VIEW `" + view.Name + "` AS SELECT 1"
+	return view, nil
+}
+
+// viewRejectingApplier fails to create one view, like a destination rejecting a translated definition
+type viewRejectingApplier struct {
+	*MockApplier
+	reject string
+}
+
+func (a *viewRejectingApplier) CreateView(db *sql.DB, view schema.ViewDef) error {
+	if view.Name == a.reject {
+		return errors.New("failed to create view " + view.Name + ": FUNCTION date_trunc does not exist")
+	}
+	return a.MockApplier.CreateView(db, view)
+}
+
+func TestMigrator_CrossEngine_TranslatesViews(t *testing.T) {
+	sourceDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	extractor := &MockExtractor{
+		Views: []schema.ViewDef{
+			{Schema: "public", Name: "active_users"},
+			{Schema: "public", Name: "overdue"},
+			{Schema: "public", Name: "daily_totals"},
+		},
+	}
+	applier := &viewRejectingApplier{MockApplier: &MockApplier{}, reject: "daily_totals"}
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source:      config.DatabaseConfig{Engine: "pgsql", Database: "testdb"},
+			Destination: config.DatabaseConfig{Engine: "mysql", Database: "testdb_dest"},
+		},
+		sourceConn: sourceDB,
+		extractor:  extractor,
+		applier:    applier,
+		translator: &MockViewTranslator{Untranslatable: map[string]bool{"overdue": true}},
+	}
+
+	// Views that can't be translated or created are listed instead of failing the run
+	if err := m.createViews(); err != nil {
+		t.Fatalf("createViews() error = %v", err)
+	}
+	if applier.ViewsCreated != 1 {
+		t.Errorf("ViewsCreated = %d, want 1", applier.ViewsCreated)
+	}
+	if len(m.views) != 1 || m.views[0].Name != "active_users" {
+		t.Errorf("views = %+v, want only active_users", m.views)
+	}
+}
+
+func TestMigrator_Finalize_CountsCrossEngineDestination(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, destMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	// Rows are counted with PostgreSQL quoting on the source and MySQL quoting on the destination
+	destMock.ExpectExec("SET FOREIGN_KEY_CHECKS = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	sourceMock.ExpectQuery(`SELECT COUNT\(\*\) FROM "audit"."events"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(3)))
+	destMock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `events`").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(3)))
+
+	m := &Migrator{
+		config: config.MigrationConfig{
+			Source:      config.DatabaseConfig{Engine: "pgsql"},
+			Destination: config.DatabaseConfig{Engine: "mysql"},
+		},
+		sourceConn: sourceDB,
+		destConn:   destDB,
+		transferer: data.NewPostgresToMySQLTransferer(),
+		translator: &MockTranslator{},
+		tables:     []schema.TableSchema{{Schema: "audit", Name: "events"}},
+	}
+
+	if err := m.finalize(); err != nil {
+		t.Errorf("finalize() error = %v", err)
+	}
+	if err := sourceMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("source expectations not met: %v", err)
+	}
+	if err := destMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("dest expectations not met: %v", err)
+	}
+}
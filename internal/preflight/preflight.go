@@ -4,16 +4,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"net/url"
 	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/engine"
 	"github.com/DGarbs51/lcmigrate/internal/prompt"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
 	"github.com/DGarbs51/lcmigrate/internal/ui"
-	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/lib/pq"
 )
 
 // CheckResult represents the result of a single pre-flight check
@@ -28,204 +26,31 @@ type CheckResult struct {
 type PreflightResult struct {
 	SourceConn   *sql.DB
 	DestConn     *sql.DB
-	SourceInfo   DatabaseInfo
-	DestInfo     DatabaseInfo
+	SourceInfo   engine.DatabaseInfo
+	DestInfo     engine.DatabaseInfo
+	SourceEngine string // Engine the source server runs, which may differ from the configured one
+	DestEngine   string // Engine the destination server runs
 	Checks       []CheckResult
 	Passed       bool
 	Aborted      bool
 }
 
-// DatabaseInfo contains database metadata
-type DatabaseInfo struct {
-	Version      string
-	MajorVersion int
-	TableCount   int
-	ViewCount    int
-	TotalSize    int64
-	Tables       []string
-}
-
-// ConnectResult contains the connection and metadata about how it was established
-type ConnectResult struct {
-	DB      *sql.DB
-	SSLMode string // For PostgreSQL: which SSL mode was used
-}
-
-// DatabaseNotExistsError indicates the target database doesn't exist
-type DatabaseNotExistsError struct {
-	Database string
-}
-
-func (e *DatabaseNotExistsError) Error() string {
-	return fmt.Sprintf("database %q does not exist", e.Database)
-}
-
-// isDatabaseNotExistsError checks if the error indicates the database doesn't exist
-func isDatabaseNotExistsError(err error, engine string) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	switch engine {
-	case "pgsql":
-		// PostgreSQL error code 3D000: invalid_catalog_name (database does not exist)
-		return strings.Contains(errStr, "3D000") || strings.Contains(errStr, "does not exist")
-	case "mysql":
-		// MySQL error 1049: Unknown database
-		return strings.Contains(errStr, "1049") || strings.Contains(errStr, "Unknown database")
-	}
-	return false
-}
-
-// Connect establishes a database connection
-func Connect(cfg config.DatabaseConfig) (*ConnectResult, error) {
-	switch cfg.Engine {
-	case "mysql":
-		return connectMySQL(cfg)
-	case "pgsql":
-		return connectPostgres(cfg)
-	default:
-		return nil, fmt.Errorf("unsupported database engine: %s", cfg.Engine)
-	}
-}
-
-// connectMySQL establishes a MySQL connection
-func connectMySQL(cfg config.DatabaseConfig) (*ConnectResult, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
-
-	db, err := sql.Open("mysql", dsn)
+// connect opens a connection with the engine registered for the configuration
+func connect(cfg config.DatabaseConfig) (*engine.Engine, *engine.ConnectResult, error) {
+	e, err := engine.Get(cfg.Engine)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-
-	if err := db.Ping(); err != nil {
-		db.Close()
-		if isDatabaseNotExistsError(err, "mysql") {
-			return nil, &DatabaseNotExistsError{Database: cfg.Database}
-		}
-		return nil, err
-	}
-
-	return &ConnectResult{DB: db}, nil
+	result, err := e.Connect(cfg)
+	return e, result, err
 }
 
-// connectPostgres establishes a PostgreSQL connection with SSL fallback
-// Tries: require -> prefer -> disable
-func connectPostgres(cfg config.DatabaseConfig) (*ConnectResult, error) {
-	sslModes := []string{"require", "prefer", "disable"}
-	var lastErr error
-	var dbNotExists bool
-
-	for _, sslMode := range sslModes {
-		dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-			url.QueryEscape(cfg.User), url.QueryEscape(cfg.Password),
-			cfg.Host, cfg.Port, cfg.Database, sslMode)
-
-		db, err := sql.Open("postgres", dsn)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-
-		if err := db.Ping(); err != nil {
-			db.Close()
-			lastErr = err
-			// Check if database doesn't exist - this error won't be fixed by changing SSL mode
-			if isDatabaseNotExistsError(err, "pgsql") {
-				dbNotExists = true
-				break
-			}
-			continue
-		}
-
-		// Connection successful
-		return &ConnectResult{DB: db, SSLMode: sslMode}, nil
+// describeConnection formats a connection for the pre-flight report, without its password
+func describeConnection(cfg config.DatabaseConfig) string {
+	if engine.IsFile(cfg.Engine) {
+		return fmt.Sprintf("%s:%s", cfg.Engine, cfg.Database)
 	}
-
-	if dbNotExists {
-		return nil, &DatabaseNotExistsError{Database: cfg.Database}
-	}
-	return nil, fmt.Errorf("failed to connect with any SSL mode: %w", lastErr)
-}
-
-// CreateDatabase creates the specified database on the target server
-func CreateDatabase(cfg config.DatabaseConfig) error {
-	switch cfg.Engine {
-	case "mysql":
-		return createMySQLDatabase(cfg)
-	case "pgsql":
-		return createPostgresDatabase(cfg)
-	default:
-		return fmt.Errorf("unsupported database engine: %s", cfg.Engine)
-	}
-}
-
-// createMySQLDatabase creates a MySQL database
-func createMySQLDatabase(cfg config.DatabaseConfig) error {
-	// Connect without specifying a database
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port)
-
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return fmt.Errorf("failed to connect to server: %w", err)
-	}
-	defer db.Close()
-
-	if err := db.Ping(); err != nil {
-		return fmt.Errorf("failed to ping server: %w", err)
-	}
-
-	// Create the database
-	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE `%s`", cfg.Database))
-	if err != nil {
-		return fmt.Errorf("failed to create database: %w", err)
-	}
-
-	return nil
-}
-
-// createPostgresDatabase creates a PostgreSQL database
-func createPostgresDatabase(cfg config.DatabaseConfig) error {
-	// Connect to the default 'postgres' database
-	sslModes := []string{"require", "prefer", "disable"}
-	var db *sql.DB
-	var lastErr error
-
-	for _, sslMode := range sslModes {
-		dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/postgres?sslmode=%s",
-			url.QueryEscape(cfg.User), url.QueryEscape(cfg.Password),
-			cfg.Host, cfg.Port, sslMode)
-
-		conn, err := sql.Open("postgres", dsn)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-
-		if err := conn.Ping(); err != nil {
-			conn.Close()
-			lastErr = err
-			continue
-		}
-
-		db = conn
-		break
-	}
-
-	if db == nil {
-		return fmt.Errorf("failed to connect to server: %w", lastErr)
-	}
-	defer db.Close()
-
-	// Create the database (use quoted identifier to handle special chars)
-	_, err := db.Exec(fmt.Sprintf("CREATE DATABASE %s", quoteIdentifier(cfg.Database)))
-	if err != nil {
-		return fmt.Errorf("failed to create database: %w", err)
-	}
-
-	return nil
+	return fmt.Sprintf("%s://%s@%s:%s/%s", cfg.Engine, cfg.User, cfg.Host, cfg.Port, cfg.Database)
 }
 
 // Run executes all pre-flight checks
@@ -237,7 +62,7 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 	ui.Header("Pre-flight Checks")
 
 	// 1. Connect to source
-	sourceConnResult, err := Connect(cfg.Source)
+	sourceEngine, sourceConnResult, err := connect(cfg.Source)
 	if err != nil {
 		result.Checks = append(result.Checks, CheckResult{
 			Name:    "Source connection",
@@ -252,7 +77,7 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 	result.Checks = append(result.Checks, CheckResult{
 		Name:    "Source connection",
 		Passed:  true,
-		Message: fmt.Sprintf("Connected to %s://%s@%s:%s/%s", cfg.Source.Engine, cfg.Source.User, cfg.Source.Host, cfg.Source.Port, cfg.Source.Database),
+		Message: "Connected to " + describeConnection(cfg.Source),
 	})
 	sourceSSLInfo := ""
 	if sourceConnResult.SSLMode != "" && sourceConnResult.SSLMode != "require" {
@@ -261,7 +86,7 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 	ui.Success(fmt.Sprintf("Source connection successful%s", sourceSSLInfo))
 
 	// Get source database info early (needed for dry-run database creation)
-	sourceInfo, err := getDatabaseInfo(result.SourceConn, cfg.Source.Engine, cfg.Source.Database)
+	sourceInfo, err := sourceEngine.Info(result.SourceConn, cfg.Source.Database)
 	if err != nil {
 		ui.Error(fmt.Sprintf("Failed to get source database info: %s", err))
 		result.Passed = false
@@ -269,12 +94,19 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 	}
 	result.SourceInfo = sourceInfo
 
+	// MySQL and MariaDB servers are told apart by their version rather than trusted to be configured right
+	if resolved := engine.Resolve(cfg.Source.Engine, sourceInfo.Version); resolved != cfg.Source.Engine {
+		ui.Info(fmt.Sprintf("Source server is %s; migrating it as %s", sourceInfo.Version, resolved))
+		cfg.Source.Engine = resolved
+	}
+	result.SourceEngine = cfg.Source.Engine
+
 	// 2. Connect to destination
-	destConnResult, err := Connect(cfg.Destination)
+	destEngine, destConnResult, err := connect(cfg.Destination)
 	if err != nil {
 		// Check if database doesn't exist and offer to create it
-		var dbNotExists *DatabaseNotExistsError
-		if errors.As(err, &dbNotExists) {
+		var dbNotExists *engine.DatabaseNotExistsError
+		if errors.As(err, &dbNotExists) && destEngine.CreateDatabase != nil {
 			ui.Warning(fmt.Sprintf("Database %q does not exist on destination server", cfg.Destination.Database))
 			if cfg.DryRun {
 				// For dry runs, just pretend we created it and skip the actual connection
@@ -285,7 +117,7 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 					Message: fmt.Sprintf("Would create database %q", cfg.Destination.Database),
 				})
 				// Create a mock destination info for dry run
-				result.DestInfo = DatabaseInfo{
+				result.DestInfo = engine.DatabaseInfo{
 					Version:      result.SourceInfo.Version,
 					MajorVersion: result.SourceInfo.MajorVersion,
 					TableCount:   0,
@@ -308,7 +140,7 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 			}
 
 			// Create the database
-			if err := CreateDatabase(cfg.Destination); err != nil {
+			if err := destEngine.CreateDatabase(cfg.Destination); err != nil {
 				ui.Error(fmt.Sprintf("Failed to create database: %s", err))
 				result.Passed = false
 				return result, nil
@@ -316,7 +148,7 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 			ui.Success(fmt.Sprintf("Created database %q", cfg.Destination.Database))
 
 			// Try connecting again
-			destConnResult, err = Connect(cfg.Destination)
+			destConnResult, err = destEngine.Connect(cfg.Destination)
 			if err != nil {
 				result.Checks = append(result.Checks, CheckResult{
 					Name:    "Destination connection",
@@ -342,7 +174,7 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 	result.Checks = append(result.Checks, CheckResult{
 		Name:    "Destination connection",
 		Passed:  true,
-		Message: fmt.Sprintf("Connected to %s://%s@%s:%s/%s", cfg.Destination.Engine, cfg.Destination.User, cfg.Destination.Host, cfg.Destination.Port, cfg.Destination.Database),
+		Message: "Connected to " + describeConnection(cfg.Destination),
 	})
 	destSSLInfo := ""
 	if destConnResult.SSLMode != "" && destConnResult.SSLMode != "require" {
@@ -350,30 +182,87 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 	}
 	ui.Success(fmt.Sprintf("Destination connection successful%s", destSSLInfo))
 
-	// 3. Check engine matching
-	if cfg.Source.Engine != cfg.Destination.Engine {
+	// 3. Get destination database info, which tells MySQL and MariaDB apart, and check engine matching
+	destInfo, err := destEngine.Info(result.DestConn, cfg.Destination.Database)
+	if err != nil {
+		ui.Error(fmt.Sprintf("Failed to get destination database info: %s", err))
+		result.Passed = false
+		return result, nil
+	}
+	result.DestInfo = destInfo
+	if resolved := engine.Resolve(cfg.Destination.Engine, destInfo.Version); resolved != cfg.Destination.Engine {
+		ui.Info(fmt.Sprintf("Destination server is %s; migrating to it as %s", destInfo.Version, resolved))
+		cfg.Destination.Engine = resolved
+		if destEngine, err = engine.Get(resolved); err != nil {
+			return nil, err
+		}
+	}
+	result.DestEngine = cfg.Destination.Engine
+
+	crossEngine := cfg.Source.Engine != cfg.Destination.Engine
+	if !engine.CanMigrate(cfg.Source.Engine, cfg.Destination.Engine) {
 		result.Checks = append(result.Checks, CheckResult{
 			Name:    "Engine matching",
 			Passed:  false,
-			Message: fmt.Sprintf("Source engine (%s) does not match destination (%s)", cfg.Source.Engine, cfg.Destination.Engine),
+			Message: fmt.Sprintf("Cannot migrate from %s to %s", cfg.Source.Engine, cfg.Destination.Engine),
 		})
 		ui.Error(fmt.Sprintf("Engine mismatch: %s -> %s", cfg.Source.Engine, cfg.Destination.Engine))
 		result.Passed = false
 		return result, nil
 	}
-	ui.Success(fmt.Sprintf("Database engines match (%s -> %s)", cfg.Source.Engine, cfg.Destination.Engine))
-
-	// 4. Get destination database info and check versions
-	destInfo, err := getDatabaseInfo(result.DestConn, cfg.Destination.Engine, cfg.Destination.Database)
-	if err != nil {
-		ui.Error(fmt.Sprintf("Failed to get destination database info: %s", err))
-		result.Passed = false
-		return result, nil
+	if crossEngine {
+		ui.Success(fmt.Sprintf("Cross-engine migration (%s -> %s)", cfg.Source.Engine, cfg.Destination.Engine))
+		result.Checks = append(result.Checks, CheckResult{
+			Name:    "Engine matching",
+			Passed:  true,
+			Message: fmt.Sprintf("Cross-engine: %s -> %s", cfg.Source.Engine, cfg.Destination.Engine),
+		})
+	} else {
+		ui.Success(fmt.Sprintf("Database engines match (%s -> %s)", cfg.Source.Engine, cfg.Destination.Engine))
 	}
-	result.DestInfo = destInfo
 
-	// Version check
-	if result.SourceInfo.MajorVersion != destInfo.MajorVersion {
+	// 4. Version check; versions of different engines aren't comparable
+	if crossEngine {
+		ui.Success(fmt.Sprintf("Versions: %s -> %s", result.SourceInfo.Version, destInfo.Version))
+		result.Checks = append(result.Checks, CheckResult{
+			Name:    "Version check",
+			Passed:  true,
+			Message: fmt.Sprintf("%s -> %s", result.SourceInfo.Version, destInfo.Version),
+		})
+	} else if upgrader := engine.NewUpgrader(cfg.Source.Engine, cfg.Destination.Engine, result.SourceInfo.MajorVersion, destInfo.MajorVersion); upgrader != nil {
+		ui.Warning(fmt.Sprintf("Major version upgrade: %s -> %s", result.SourceInfo.Version, destInfo.Version))
+		if err := checkUpgrade(sourceEngine, result.SourceConn, cfg, upgrader); err != nil {
+			ui.Error(fmt.Sprintf("Failed to check the upgrade: %s", err))
+			result.Passed = false
+			return result, nil
+		}
+		issues := upgrader.Issues()
+		for _, f := range upgrader.Findings() {
+			if f.Rewritten {
+				ui.Info("         Rewritten: " + f.String())
+			}
+		}
+		for _, f := range issues {
+			ui.Warning("  " + f.String())
+		}
+		check := CheckResult{
+			Name:    "Version check",
+			Passed:  true,
+			Message: fmt.Sprintf("%s upgrade: %d rewrites, no issues", upgrader, len(upgrader.Findings())),
+		}
+		if len(issues) > 0 {
+			if !prompt.Confirm(fmt.Sprintf("%d upgrade issues need attention by hand. Continue anyway?", len(issues))) {
+				result.Aborted = true
+				return result, nil
+			}
+			check.Warning = true
+			check.Message = fmt.Sprintf("%s upgrade: %d rewrites, %d issues (user confirmed)",
+				upgrader, len(upgrader.Findings())-len(issues), len(issues))
+		} else {
+			ui.Success(fmt.Sprintf("No upgrade issues found (%d objects rewritten for %s)", len(upgrader.Findings()), upgrader))
+		}
+		result.Checks = append(result.Checks, check)
+	} else if result.SourceInfo.MajorVersion != destInfo.MajorVersion {
 		ui.Warning(fmt.Sprintf("Version mismatch: %s -> %s", result.SourceInfo.Version, destInfo.Version))
 		ui.Info("         This tool cannot detect breaking changes between major versions.")
 		ui.Info("         Migration will be attempted, but please verify the result.")
@@ -396,6 +285,123 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 		})
 	}
 
+	// Check the destination can install the source's extensions
+	if cfg.Source.Engine == "pgsql" && !crossEngine {
+		missing, err := checkExtensions(result.SourceConn, result.DestConn)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Failed to check extensions: %s", err))
+			result.Passed = false
+			return result, nil
+		}
+		if len(missing) > 0 {
+			for _, ext := range missing {
+				ui.Warning(fmt.Sprintf("Extension %q is not available on the destination", ext))
+			}
+			result.Checks = append(result.Checks, CheckResult{
+				Name:    "Extensions",
+				Passed:  true,
+				Warning: true,
+				Message: fmt.Sprintf("Not available on destination: %s", strings.Join(missing, ", ")),
+			})
+		} else {
+			ui.Success("All source extensions are available on the destination")
+			result.Checks = append(result.Checks, CheckResult{
+				Name:    "Extensions",
+				Passed:  true,
+				Message: "All source extensions available",
+			})
+		}
+	}
+
+	// MySQL and MariaDB share routines, definers and partitioning, so these checks apply between them
+	mysqlFamily := engine.SameFamily(cfg.Source.Engine, "mysql") && engine.SameFamily(cfg.Destination.Engine, "mysql")
+
+	// Check the destination user can recreate the source's routines, triggers and events
+	if mysqlFamily {
+		warnings, err := checkRoutinePrivileges(result.SourceConn, result.DestConn, cfg.Destination.Database)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Failed to check routine privileges: %s", err))
+			result.Passed = false
+			return result, nil
+		}
+		if len(warnings) > 0 {
+			for _, w := range warnings {
+				ui.Warning(w)
+			}
+			result.Checks = append(result.Checks, CheckResult{
+				Name:    "Routine privileges",
+				Passed:  true,
+				Warning: true,
+				Message: strings.Join(warnings, "; "),
+			})
+		}
+	}
+
+	// Check the definer policy can be honored for the source's views, routines, triggers and events
+	if mysqlFamily {
+		policy, err := schema.ParseDefinerPolicy(cfg.Definer, cfg.SQLSecurity)
+		if err != nil {
+			return nil, err
+		}
+		warnings, err := checkDefiners(result.SourceConn, result.DestConn, cfg.Destination.Database, policy)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Failed to check definers: %s", err))
+			result.Passed = false
+			return result, nil
+		}
+		if len(warnings) > 0 {
+			for _, w := range warnings {
+				ui.Warning(w)
+			}
+			result.Checks = append(result.Checks, CheckResult{
+				Name:    "Definers",
+				Passed:  true,
+				Warning: true,
+				Message: strings.Join(warnings, "; "),
+			})
+		}
+	}
+
+	// Check the destination can recreate the source's partitioned tables
+	if mysqlFamily && cfg.Partitioning != schema.PartitioningDrop {
+		warnings, err := checkPartitioning(result.SourceConn, result.DestConn, destInfo)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Failed to check partitioning: %s", err))
+			result.Passed = false
+			return result, nil
+		}
+		if len(warnings) > 0 {
+			for _, w := range warnings {
+				ui.Warning(w)
+			}
+			result.Checks = append(result.Checks, CheckResult{
+				Name:    "Partitioning",
+				Passed:  true,
+				Warning: true,
+				Message: strings.Join(warnings, "; "),
+			})
+		}
+	}
+
+	// Check for what MariaDB and the other engine don't share
+	warnings, err := checkMariaDBCompatibility(result.SourceConn, cfg, destInfo)
+	if err != nil {
+		ui.Error(fmt.Sprintf("Failed to check MariaDB compatibility: %s", err))
+		result.Passed = false
+		return result, nil
+	}
+	if len(warnings) > 0 {
+		for _, w := range warnings {
+			ui.Warning(w)
+		}
+		result.Checks = append(result.Checks, CheckResult{
+			Name:    "MariaDB compatibility",
+			Passed:  true,
+			Warning: true,
+			Message: strings.Join(warnings, "; "),
+		})
+	}
+
 	// 5. Check if destination is empty
 	if destInfo.TableCount > 0 {
 		ui.Warning(fmt.Sprintf("Destination database is not empty (%d tables)", destInfo.TableCount))
@@ -405,7 +411,7 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 		}
 		// Wipe destination
 		if !cfg.DryRun {
-			if err := wipeDatabase(result.DestConn, cfg.Destination.Engine); err != nil {
+			if err := destEngine.Wipe(result.DestConn); err != nil {
 				ui.Error(fmt.Sprintf("Failed to wipe destination: %s", err))
 				result.Passed = false
 				return result, nil
@@ -429,293 +435,489 @@ func Run(cfg config.MigrationConfig) (*PreflightResult, error) {
 	return result, nil
 }
 
-// getDatabaseInfo retrieves database metadata
-func getDatabaseInfo(db *sql.DB, engine, database string) (DatabaseInfo, error) {
-	info := DatabaseInfo{}
-
-	switch engine {
-	case "mysql":
-		// Get version
-		var version string
-		if err := db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
-			return info, fmt.Errorf("failed to get version: %w", err)
-		}
-		info.Version = version
-		info.MajorVersion = extractMajorVersion(version)
-
-		// Get table count and size
-		var tableCount int
-		var totalSize sql.NullFloat64
-		err := db.QueryRow(`
-			SELECT COUNT(*), COALESCE(SUM(data_length + index_length), 0)
-			FROM information_schema.tables
-			WHERE table_schema = ? AND table_type = 'BASE TABLE'
-		`, database).Scan(&tableCount, &totalSize)
-		if err != nil {
-			return info, fmt.Errorf("failed to get table info: %w", err)
-		}
-		info.TableCount = tableCount
-		info.TotalSize = int64(totalSize.Float64)
-
-		// Get view count
-		var viewCount int
-		err = db.QueryRow(`
-			SELECT COUNT(*)
-			FROM information_schema.views
-			WHERE table_schema = ?
-		`, database).Scan(&viewCount)
-		if err != nil {
-			return info, fmt.Errorf("failed to get view count: %w", err)
-		}
-		info.ViewCount = viewCount
+// checkUpgrade runs the source's tables, views, routines and triggers through the upgrader, which
+// collects what it rewrites and what's left for the user
+func checkUpgrade(source *engine.Engine, conn *sql.DB, cfg config.MigrationConfig, upgrader *schema.Upgrader) error {
+	extractor := source.NewExtractor()
+	if f, ok := extractor.(schema.SchemaFilterer); ok {
+		f.SetSchemaFilter(schema.SchemaFilter{Include: cfg.Schemas, Exclude: cfg.ExcludeSchemas})
+	}
+	database := cfg.Source.Database
 
-		// Get table names
-		rows, err := db.Query(`
-			SELECT table_name
-			FROM information_schema.tables
-			WHERE table_schema = ? AND table_type = 'BASE TABLE'
-			ORDER BY table_name
-		`, database)
-		if err != nil {
-			return info, fmt.Errorf("failed to get table names: %w", err)
-		}
-		defer rows.Close()
-		for rows.Next() {
-			var name string
-			if err := rows.Scan(&name); err != nil {
-				return info, err
-			}
-			info.Tables = append(info.Tables, name)
-		}
+	tables, err := extractor.ExtractTables(conn, database)
+	if err != nil {
+		return fmt.Errorf("failed to extract tables: %w", err)
+	}
+	for _, t := range tables {
+		upgrader.UpgradeTable(t)
+	}
+	views, err := extractor.ExtractViews(conn, database)
+	if err != nil {
+		return fmt.Errorf("failed to extract views: %w", err)
+	}
+	for _, v := range views {
+		upgrader.UpgradeView(v)
+	}
+	routines, err := extractor.ExtractRoutines(conn, database)
+	if err != nil {
+		return fmt.Errorf("failed to extract routines: %w", err)
+	}
+	for _, r := range routines {
+		upgrader.UpgradeRoutine(r)
+	}
+	triggers, err := extractor.ExtractTriggers(conn, database)
+	if err != nil {
+		return fmt.Errorf("failed to extract triggers: %w", err)
+	}
+	for _, t := range triggers {
+		upgrader.UpgradeTrigger(t)
+	}
+	return nil
+}
 
-	case "pgsql":
-		// Get version
-		var version string
-		if err := db.QueryRow("SELECT version()").Scan(&version); err != nil {
-			return info, fmt.Errorf("failed to get version: %w", err)
-		}
-		info.Version = version
-		info.MajorVersion = extractMajorVersion(version)
+// checkExtensions returns the source extensions that the destination's pg_available_extensions doesn't offer
+func checkExtensions(source, dest *sql.DB) ([]string, error) {
+	rows, err := source.Query(`
+		SELECT extname
+		FROM pg_extension
+		WHERE extname != 'plpgsql'
+		ORDER BY extname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source extensions: %w", err)
+	}
+	var installed []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		installed = append(installed, name)
+	}
+	rows.Close()
 
-		// Get table count
-		var tableCount int
-		err := db.QueryRow(`
-			SELECT COUNT(*)
-			FROM information_schema.tables
-			WHERE table_schema = 'public' AND table_type = 'BASE TABLE'
-		`).Scan(&tableCount)
-		if err != nil {
-			return info, fmt.Errorf("failed to get table count: %w", err)
-		}
-		info.TableCount = tableCount
+	if len(installed) == 0 {
+		return nil, nil
+	}
 
-		// Get database size
-		var totalSize int64
-		err = db.QueryRow("SELECT pg_database_size(current_database())").Scan(&totalSize)
-		if err != nil {
-			return info, fmt.Errorf("failed to get database size: %w", err)
+	rows, err = dest.Query(`SELECT name FROM pg_available_extensions`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list destination extensions: %w", err)
+	}
+	available := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		available[name] = true
+	}
+	rows.Close()
+
+	var missing []string
+	for _, name := range installed {
+		if !available[name] {
+			missing = append(missing, name)
 		}
-		info.TotalSize = totalSize
+	}
+	return missing, nil
+}
 
-		// Get view count
-		var viewCount int
-		err = db.QueryRow(`
-			SELECT COUNT(*)
-			FROM information_schema.views
-			WHERE table_schema = 'public'
-		`).Scan(&viewCount)
-		if err != nil {
-			return info, fmt.Errorf("failed to get view count: %w", err)
+// checkRoutinePrivileges returns warnings when the destination user is likely unable to
+// create the source's stored routines, triggers or events
+func checkRoutinePrivileges(source, dest *sql.DB, destDatabase string) ([]string, error) {
+	var routines, triggers, events int
+	err := source.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM information_schema.routines WHERE routine_schema = DATABASE()),
+			(SELECT COUNT(*) FROM information_schema.triggers WHERE trigger_schema = DATABASE()),
+			(SELECT COUNT(*) FROM information_schema.events WHERE event_schema = DATABASE())
+	`).Scan(&routines, &triggers, &events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count source routines: %w", err)
+	}
+	if routines == 0 && triggers == 0 && events == 0 {
+		return nil, nil
+	}
+
+	privileges, err := destinationPrivileges(dest, destDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	required := []struct {
+		count     int
+		privilege string
+		objects   string
+	}{
+		{routines, "CREATE ROUTINE", "stored procedures and functions"},
+		{triggers, "TRIGGER", "triggers"},
+		{events, "EVENT", "events"},
+	}
+	for _, r := range required {
+		if r.count > 0 && !privileges[r.privilege] && !privileges["ALL PRIVILEGES"] {
+			warnings = append(warnings, fmt.Sprintf("Destination user lacks %s; %d %s will fail to migrate", r.privilege, r.count, r.objects))
 		}
-		info.ViewCount = viewCount
+	}
 
-		// Get table names
-		rows, err := db.Query(`
-			SELECT table_name
-			FROM information_schema.tables
-			WHERE table_schema = 'public' AND table_type = 'BASE TABLE'
-			ORDER BY table_name
-		`)
-		if err != nil {
-			return info, fmt.Errorf("failed to get table names: %w", err)
+	// With binary logging on, creating functions and triggers needs SUPER unless
+	// log_bin_trust_function_creators is enabled (managed servers rarely grant SUPER)
+	if routines > 0 || triggers > 0 {
+		var logBin, trustCreators int
+		if err := dest.QueryRow("SELECT @@GLOBAL.log_bin, @@GLOBAL.log_bin_trust_function_creators").Scan(&logBin, &trustCreators); err != nil {
+			return nil, fmt.Errorf("failed to read binary log settings: %w", err)
 		}
-		defer rows.Close()
-		for rows.Next() {
-			var name string
-			if err := rows.Scan(&name); err != nil {
-				return info, err
-			}
-			info.Tables = append(info.Tables, name)
+		if logBin == 1 && trustCreators == 0 && !privileges["SUPER"] {
+			warnings = append(warnings, "Destination has binary logging on and log_bin_trust_function_creators=0; "+
+				"functions and triggers not declared DETERMINISTIC, NO SQL or READS SQL DATA will fail without SUPER")
 		}
 	}
 
-	return info, nil
+	return warnings, nil
 }
 
-// extractMajorVersion extracts the major version number from a version string
-func extractMajorVersion(version string) int {
-	// Match patterns like "8.0.35", "PostgreSQL 16.2", "5.7.44-log"
-	re := regexp.MustCompile(`(\d+)\.(\d+)`)
-	matches := re.FindStringSubmatch(version)
-	if len(matches) >= 2 {
-		major, _ := strconv.Atoi(matches[1])
-		return major
+// checkDefiners returns warnings for source views, routines, triggers and events whose
+// definer won't be honored on the destination under the given definer policy
+func checkDefiners(source, dest *sql.DB, destDatabase string, policy schema.DefinerPolicy) ([]string, error) {
+	rows, err := source.Query(`
+		SELECT 'view', table_name, definer, security_type
+			FROM information_schema.views WHERE table_schema = DATABASE()
+		UNION ALL
+		SELECT LOWER(routine_type), routine_name, definer, security_type
+			FROM information_schema.routines WHERE routine_schema = DATABASE()
+		UNION ALL
+		SELECT 'trigger', trigger_name, definer, 'DEFINER'
+			FROM information_schema.triggers WHERE trigger_schema = DATABASE()
+		UNION ALL
+		SELECT 'event', event_name, definer, 'DEFINER'
+			FROM information_schema.events WHERE event_schema = DATABASE()
+		ORDER BY 1, 2
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source definers: %w", err)
 	}
-	return 0
-}
-
-// wipeDatabase drops all tables, views, and other objects from the database
-func wipeDatabase(db *sql.DB, engine string) error {
-	switch engine {
-	case "mysql":
-		// Disable foreign key checks
-		if _, err := db.Exec("SET FOREIGN_KEY_CHECKS = 0"); err != nil {
-			return err
-		}
-
-		// Get all tables
-		rows, err := db.Query(`
-			SELECT table_name
-			FROM information_schema.tables
-			WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'
-		`)
-		if err != nil {
-			return err
-		}
-		var tables []string
-		for rows.Next() {
-			var name string
-			if err := rows.Scan(&name); err != nil {
-				rows.Close()
-				return err
-			}
-			tables = append(tables, name)
+	type definedObject struct {
+		kind, name, definer, security string
+	}
+	var objects []definedObject
+	for rows.Next() {
+		var o definedObject
+		if err := rows.Scan(&o.kind, &o.name, &o.definer, &o.security); err != nil {
+			rows.Close()
+			return nil, err
 		}
-		rows.Close()
-
-		// Drop tables
-		for _, table := range tables {
-			if _, err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS `%s`", table)); err != nil {
-				return err
-			}
+		if policy.SQLSecurity != "" && (o.kind == "view" || o.kind == "procedure" || o.kind == "function") {
+			o.security = policy.SQLSecurity
 		}
+		objects = append(objects, o)
+	}
+	rows.Close()
+	if len(objects) == 0 {
+		return nil, nil
+	}
 
-		// Get all views
-		rows, err = db.Query(`
-			SELECT table_name
-			FROM information_schema.views
-			WHERE table_schema = DATABASE()
-		`)
-		if err != nil {
-			return err
-		}
-		var views []string
-		for rows.Next() {
-			var name string
-			if err := rows.Scan(&name); err != nil {
-				rows.Close()
-				return err
+	var currentUser string
+	if err := dest.QueryRow("SELECT CURRENT_USER()").Scan(&currentUser); err != nil {
+		return nil, fmt.Errorf("failed to read destination user: %w", err)
+	}
+	privileges, err := destinationPrivileges(dest, destDatabase)
+	if err != nil {
+		return nil, err
+	}
+	// SET_USER_ID (8.0) and SET_ANY_DEFINER (8.2+) replace SUPER for assigning other definers
+	canSetDefiner := privileges["SUPER"] || privileges["SET_USER_ID"] || privileges["SET_ANY_DEFINER"]
+
+	var warnings []string
+	switch policy.Mode {
+	case schema.DefinerKeep:
+		var foreign []string
+		for _, o := range objects {
+			if !sameAccount(o.definer, currentUser) {
+				foreign = append(foreign, fmt.Sprintf("%s %s (%s)", o.kind, o.name, o.definer))
 			}
-			views = append(views, name)
 		}
-		rows.Close()
-
-		// Drop views
-		for _, view := range views {
-			if _, err := db.Exec(fmt.Sprintf("DROP VIEW IF EXISTS `%s`", view)); err != nil {
-				return err
-			}
+		if len(foreign) > 0 && !canSetDefiner {
+			warnings = append(warnings, fmt.Sprintf("Destination user lacks SET_USER_ID or SUPER; %d objects keep a definer it can't assign and will fail: %s",
+				len(foreign), summarizeNames(foreign)))
 		}
-
-		// Re-enable foreign key checks
-		if _, err := db.Exec("SET FOREIGN_KEY_CHECKS = 1"); err != nil {
-			return err
+	case schema.DefinerAccount:
+		if !sameAccount(policy.Account, currentUser) && !canSetDefiner {
+			warnings = append(warnings, fmt.Sprintf("Destination user lacks SET_USER_ID or SUPER; %d objects can't be assigned to %s",
+				len(objects), policy.Account))
 		}
-
-	case "pgsql":
-		// Drop all tables with CASCADE
-		rows, err := db.Query(`
-			SELECT tablename
-			FROM pg_tables
-			WHERE schemaname = 'public'
-		`)
-		if err != nil {
-			return err
-		}
-		var tables []string
-		for rows.Next() {
-			var name string
-			if err := rows.Scan(&name); err != nil {
-				rows.Close()
-				return err
+	default:
+		// The migrating user becomes the definer, so anything that runs with its
+		// definer's privileges now runs with the destination user's instead
+		var changed []string
+		for _, o := range objects {
+			if o.security == "DEFINER" && !sameAccount(o.definer, currentUser) {
+				changed = append(changed, fmt.Sprintf("%s %s (%s)", o.kind, o.name, o.definer))
 			}
-			tables = append(tables, name)
 		}
-		rows.Close()
+		if len(changed) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%d objects run with their definer's privileges and will be redefined by %s: %s",
+				len(changed), currentUser, summarizeNames(changed)))
+		}
+	}
 
-		for _, table := range tables {
-			if _, err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", quoteIdentifier(table))); err != nil {
-				return err
-			}
+	return warnings, nil
+}
+
+// checkPartitioning returns warnings for source partitioned tables the destination can't partition
+// MySQL 8.0 only partitions InnoDB and NDB tables natively; older servers and MariaDB need the
+// partition plugin
+func checkPartitioning(source, dest *sql.DB, destInfo engine.DatabaseInfo) ([]string, error) {
+	rows, err := source.Query(`
+		SELECT DISTINCT p.table_name, t.engine
+		FROM information_schema.partitions p
+		JOIN information_schema.tables t ON t.table_schema = p.table_schema AND t.table_name = p.table_name
+		WHERE p.table_schema = DATABASE() AND p.partition_name IS NOT NULL
+		ORDER BY p.table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source partitioned tables: %w", err)
+	}
+	var tables, nonNative []string
+	for rows.Next() {
+		var name, engine string
+		if err := rows.Scan(&name, &engine); err != nil {
+			rows.Close()
+			return nil, err
 		}
+		tables = append(tables, name)
+		if !strings.EqualFold(engine, "InnoDB") && !strings.EqualFold(engine, "ndbcluster") {
+			nonNative = append(nonNative, fmt.Sprintf("%s (%s)", name, engine))
+		}
+	}
+	rows.Close()
+	if len(tables) == 0 {
+		return nil, nil
+	}
 
-		// Drop all views
-		rows, err = db.Query(`
-			SELECT viewname
-			FROM pg_views
-			WHERE schemaname = 'public'
-		`)
-		if err != nil {
-			return err
-		}
-		var views []string
-		for rows.Next() {
-			var name string
-			if err := rows.Scan(&name); err != nil {
-				rows.Close()
-				return err
-			}
-			views = append(views, name)
+	if destInfo.MajorVersion >= 8 && !engine.IsMariaDB(destInfo.Version) {
+		if len(nonNative) == 0 {
+			return nil, nil
 		}
-		rows.Close()
+		return []string{fmt.Sprintf("MySQL %s only partitions InnoDB tables; %d partitioned tables will fail (use --partitioning drop): %s",
+			destInfo.Version, len(nonNative), summarizeNames(nonNative))}, nil
+	}
 
-		for _, view := range views {
-			if _, err := db.Exec(fmt.Sprintf("DROP VIEW IF EXISTS %s CASCADE", quoteIdentifier(view))); err != nil {
-				return err
-			}
+	var status string
+	err = dest.QueryRow("SELECT plugin_status FROM information_schema.plugins WHERE plugin_name = 'partition'").Scan(&status)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to read destination partition plugin: %w", err)
+	}
+	if status != "ACTIVE" {
+		return []string{fmt.Sprintf("Destination doesn't have partitioning enabled; %d partitioned tables will fail (use --partitioning drop): %s",
+			len(tables), summarizeNames(tables))}, nil
+	}
+	return nil, nil
+}
+
+// checkMariaDB returns warnings for MariaDB objects and types that the destination engine lacks
+// and lcmigrate converts or drops
+func checkMariaDB(source *sql.DB, destEngine string, destInfo engine.DatabaseInfo) ([]string, error) {
+	rows, err := source.Query(`
+		SELECT 'sequence', table_name FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_type = 'SEQUENCE'
+		UNION ALL
+		SELECT 'versioned', table_name FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_type = 'SYSTEM VERSIONED'
+		UNION ALL
+		SELECT 'aria', table_name FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_type <> 'SEQUENCE' AND engine = 'Aria'
+		UNION ALL
+		SELECT 'type', CONCAT(table_name, '.', column_name, ' (', data_type, ')') FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND data_type IN ('uuid', 'inet4', 'inet6')
+		UNION ALL
+		SELECT 'json', CONCAT(table_name, '.', constraint_name) FROM information_schema.check_constraints
+		WHERE constraint_schema = DATABASE() AND check_clause LIKE 'json_valid(%'
+		ORDER BY 1, 2
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MariaDB-specific objects: %w", err)
+	}
+	found := map[string][]string{}
+	for rows.Next() {
+		var kind, name string
+		if err := rows.Scan(&kind, &name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		found[kind] = append(found[kind], name)
+	}
+	rows.Close()
+
+	var warnings []string
+	if names := found["sequence"]; len(names) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d sequences aren't migrated to %s; NEXTVAL defaults are dropped: %s",
+			len(names), destEngine, summarizeNames(names)))
+	}
+	if names := found["versioned"]; len(names) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d system-versioned tables lose their history; only current rows are copied: %s",
+			len(names), summarizeNames(names)))
+	}
+	if destEngine != "mysql" {
+		return warnings, nil
+	}
+	if names := found["aria"]; len(names) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d Aria tables become InnoDB: %s", len(names), summarizeNames(names)))
+	}
+	if names := found["type"]; len(names) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d uuid and inet columns become char and varchar: %s", len(names), summarizeNames(names)))
+	}
+	// JSON columns, expression defaults and utf8mb4_0900 collations all need MySQL 8
+	if destInfo.MajorVersion < 8 {
+		warnings = append(warnings, fmt.Sprintf("MySQL %s is older than 8.0, which MariaDB tables are translated for", destInfo.Version))
+		if names := found["json"]; len(names) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%d JSON columns need MySQL 8: %s", len(names), summarizeNames(names)))
 		}
+	}
+	return warnings, nil
+}
 
-		// Drop all sequences
-		rows, err = db.Query(`
-			SELECT sequencename
-			FROM pg_sequences
-			WHERE schemaname = 'public'
-		`)
-		if err != nil {
-			return err
-		}
-		var sequences []string
-		for rows.Next() {
-			var name string
-			if err := rows.Scan(&name); err != nil {
-				rows.Close()
-				return err
-			}
-			sequences = append(sequences, name)
+// checkMariaDBCompatibility runs the checks for migrations from MariaDB to another engine and
+// from MySQL to MariaDB, whose versions aren't comparable enough for the upgrade checks
+func checkMariaDBCompatibility(source *sql.DB, cfg config.MigrationConfig, destInfo engine.DatabaseInfo) ([]string, error) {
+	switch {
+	case cfg.Source.Engine == "mariadb" && cfg.Destination.Engine != "mariadb":
+		return checkMariaDB(source, cfg.Destination.Engine, destInfo)
+	case cfg.Source.Engine == "mysql" && cfg.Destination.Engine == "mariadb":
+		return checkMySQLForMariaDB(source)
+	default:
+		return nil, nil
+	}
+}
+
+// checkMySQLForMariaDB lists the MySQL columns and tables a MariaDB destination stores or
+// accepts differently; MySQL DDL is copied into MariaDB as written
+func checkMySQLForMariaDB(source *sql.DB) ([]string, error) {
+	rows, err := source.Query(`
+		SELECT 'json', CONCAT(table_name, '.', column_name) FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND data_type = 'json'
+		UNION ALL
+		SELECT 'collation', CONCAT(table_name, '.', column_name, ' (', collation_name, ')') FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND collation_name LIKE '%\_0900\_%'
+		UNION ALL
+		SELECT 'collation', CONCAT(table_name, ' (', table_collation, ')') FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' AND table_collation LIKE '%\_0900\_%'
+		ORDER BY 1, 2
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MySQL-specific columns: %w", err)
+	}
+	found := map[string][]string{}
+	for rows.Next() {
+		var kind, name string
+		if err := rows.Scan(&kind, &name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		found[kind] = append(found[kind], name)
+	}
+	rows.Close()
+
+	var warnings []string
+	if names := found["json"]; len(names) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d JSON columns become LONGTEXT with a json_valid CHECK on MariaDB, and their values are compared as text: %s",
+			len(names), summarizeNames(names)))
+	}
+	if names := found["collation"]; len(names) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d tables and columns use MySQL 8's utf8mb4_0900 collations, which MariaDB only accepts from 11.4.5: %s",
+			len(names), summarizeNames(names)))
+	}
+	return warnings, nil
+}
+
+// destinationPrivileges reads the destination user's privileges on the database from SHOW GRANTS
+func destinationPrivileges(dest *sql.DB, database string) (map[string]bool, error) {
+	rows, err := dest.Query("SHOW GRANTS FOR CURRENT_USER()")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list destination grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []string
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return nil, err
 		}
-		rows.Close()
+		grants = append(grants, grant)
+	}
+	return grantedPrivileges(grants, database), rows.Err()
+}
+
+// sameAccount compares two user@host accounts, ignoring quoting
+func sameAccount(a, b string) bool {
+	normalize := func(s string) string {
+		return strings.NewReplacer("`", "", "'", "", `"`, "").Replace(s)
+	}
+	return normalize(a) == normalize(b)
+}
+
+// summarizeNames joins up to five names, noting how many more there are
+func summarizeNames(names []string) string {
+	const shown = 5
+	if len(names) <= shown {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:shown], ", "), len(names)-shown)
+}
 
-		for _, seq := range sequences {
-			if _, err := db.Exec(fmt.Sprintf("DROP SEQUENCE IF EXISTS %s CASCADE", quoteIdentifier(seq))); err != nil {
-				return err
+// grantedPrivileges collects privileges from SHOW GRANTS lines that apply to the given database
+// Only global (*.*) and database-level grants are considered
+func grantedPrivileges(grants []string, database string) map[string]bool {
+	re := regexp.MustCompile(`(?i)^GRANT\s+(.+?)\s+ON\s+(\S+)\s+TO\s`)
+	privileges := make(map[string]bool)
+	for _, grant := range grants {
+		m := re.FindStringSubmatch(grant)
+		if m == nil {
+			continue
+		}
+		target := strings.ReplaceAll(m[2], "`", "")
+		global := target == "*.*"
+		if !global && !(strings.HasSuffix(target, ".*") && grantMatchesDatabase(strings.TrimSuffix(target, ".*"), database)) {
+			continue
+		}
+		for _, p := range strings.Split(m[1], ",") {
+			p = strings.ToUpper(strings.TrimSpace(p))
+			// SUPER is only meaningful as a global privilege
+			if p == "ALL PRIVILEGES" && global {
+				privileges["SUPER"] = true
 			}
+			privileges[p] = true
 		}
 	}
+	return privileges
+}
 
-	return nil
+// grantMatchesDatabase reports whether a database name from a grant covers the database
+// Grants store \_ and \% for literal characters; unescaped _ and % are wildcards as in LIKE
+func grantMatchesDatabase(pattern, database string) bool {
+	return matchGrantPattern([]rune(pattern), []rune(database))
 }
 
-// quoteIdentifier quotes a PostgreSQL identifier
-func quoteIdentifier(name string) string {
-	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+func matchGrantPattern(pattern, name []rune) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	switch c := pattern[0]; {
+	case c == '%':
+		for i := 0; i <= len(name); i++ {
+			if matchGrantPattern(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	case c == '_':
+		return len(name) > 0 && matchGrantPattern(pattern[1:], name[1:])
+	case c == '\\' && len(pattern) > 1:
+		return len(name) > 0 && name[0] == pattern[1] && matchGrantPattern(pattern[2:], name[1:])
+	default:
+		return len(name) > 0 && name[0] == c && matchGrantPattern(pattern[1:], name[1:])
+	}
 }
@@ -1,125 +1,17 @@
 package preflight
 
 import (
-	"errors"
+	"database/sql/driver"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/engine"
+	"github.com/DGarbs51/lcmigrate/internal/schema"
 )
 
-func TestExtractMajorVersion(t *testing.T) {
-	tests := []struct {
-		version string
-		want    int
-	}{
-		{"8.0.35", 8},
-		{"5.7.44-log", 5},
-		{"PostgreSQL 16.2 (Ubuntu 16.2-1.pgdg22.04+1)", 16},
-		{"PostgreSQL 15.4", 15},
-		{"10.6.0-MariaDB", 10},
-		{"invalid", 0},
-		{"", 0},
-	}
-
-	for _, tt := range tests {
-		got := extractMajorVersion(tt.version)
-		if got != tt.want {
-			t.Errorf("extractMajorVersion(%q) = %d, want %d", tt.version, got, tt.want)
-		}
-	}
-}
-
-func TestQuoteIdentifier(t *testing.T) {
-	tests := []struct {
-		input string
-		want  string
-	}{
-		{"users", `"users"`},
-		{"my_table", `"my_table"`},
-		{`table"name`, `"table""name"`},
-		{"", `""`},
-	}
-
-	for _, tt := range tests {
-		got := quoteIdentifier(tt.input)
-		if got != tt.want {
-			t.Errorf("quoteIdentifier(%q) = %q, want %q", tt.input, got, tt.want)
-		}
-	}
-}
-
-func TestIsDatabaseNotExistsError_MySQL(t *testing.T) {
-	tests := []struct {
-		err    error
-		engine string
-		want   bool
-	}{
-		{errors.New("Error 1049: Unknown database 'testdb'"), "mysql", true},
-		{errors.New("Unknown database 'testdb'"), "mysql", true},
-		{errors.New("Access denied for user"), "mysql", false},
-		{nil, "mysql", false},
-	}
-
-	for _, tt := range tests {
-		got := isDatabaseNotExistsError(tt.err, tt.engine)
-		if got != tt.want {
-			t.Errorf("isDatabaseNotExistsError(%v, %q) = %v, want %v", tt.err, tt.engine, got, tt.want)
-		}
-	}
-}
-
-func TestIsDatabaseNotExistsError_Postgres(t *testing.T) {
-	tests := []struct {
-		err    error
-		engine string
-		want   bool
-	}{
-		{errors.New("FATAL: database \"testdb\" does not exist"), "pgsql", true},
-		{errors.New("pq: 3D000: database \"testdb\" does not exist"), "pgsql", true},
-		{errors.New("permission denied"), "pgsql", false},
-		{nil, "pgsql", false},
-	}
-
-	for _, tt := range tests {
-		got := isDatabaseNotExistsError(tt.err, tt.engine)
-		if got != tt.want {
-			t.Errorf("isDatabaseNotExistsError(%v, %q) = %v, want %v", tt.err, tt.engine, got, tt.want)
-		}
-	}
-}
-
-func TestDatabaseNotExistsError(t *testing.T) {
-	err := &DatabaseNotExistsError{Database: "mydb"}
-	expected := `database "mydb" does not exist`
-
-	if err.Error() != expected {
-		t.Errorf("DatabaseNotExistsError.Error() = %q, want %q", err.Error(), expected)
-	}
-}
-
-func TestConnect_UnsupportedEngine(t *testing.T) {
-	cfg := config.DatabaseConfig{
-		Engine: "unsupported",
-	}
-
-	_, err := Connect(cfg)
-	if err == nil {
-		t.Errorf("Connect() with unsupported engine expected error, got nil")
-	}
-}
-
-func TestCreateDatabase_UnsupportedEngine(t *testing.T) {
-	cfg := config.DatabaseConfig{
-		Engine: "unsupported",
-	}
-
-	err := CreateDatabase(cfg)
-	if err == nil {
-		t.Errorf("CreateDatabase() with unsupported engine expected error, got nil")
-	}
-}
-
 func TestCheckResult_Fields(t *testing.T) {
 	result := CheckResult{
 		Name:    "Test check",
@@ -156,266 +48,17 @@ func TestPreflightResult_Fields(t *testing.T) {
 	}
 }
 
-func TestDatabaseInfo_Fields(t *testing.T) {
-	info := DatabaseInfo{
-		Version:      "8.0.35",
-		MajorVersion: 8,
-		TableCount:   10,
-		ViewCount:    2,
-		TotalSize:    1024 * 1024,
-		Tables:       []string{"users", "orders"},
-	}
-
-	if info.Version != "8.0.35" {
-		t.Errorf("DatabaseInfo.Version = %q, want %q", info.Version, "8.0.35")
-	}
-	if info.MajorVersion != 8 {
-		t.Errorf("DatabaseInfo.MajorVersion = %d, want %d", info.MajorVersion, 8)
-	}
-	if info.TableCount != 10 {
-		t.Errorf("DatabaseInfo.TableCount = %d, want %d", info.TableCount, 10)
-	}
-	if info.ViewCount != 2 {
-		t.Errorf("DatabaseInfo.ViewCount = %d, want %d", info.ViewCount, 2)
-	}
-	if len(info.Tables) != 2 {
-		t.Errorf("len(DatabaseInfo.Tables) = %d, want %d", len(info.Tables), 2)
-	}
-}
-
-func TestConnectResult_Fields(t *testing.T) {
-	result := ConnectResult{
-		DB:      nil,
-		SSLMode: "prefer",
-	}
-
-	if result.SSLMode != "prefer" {
-		t.Errorf("ConnectResult.SSLMode = %q, want %q", result.SSLMode, "prefer")
-	}
-}
-
-func TestIsDatabaseNotExistsError_UnknownEngine(t *testing.T) {
-	err := errors.New("some error")
-	got := isDatabaseNotExistsError(err, "unknown")
-	if got {
-		t.Errorf("isDatabaseNotExistsError() with unknown engine = %v, want false", got)
-	}
-}
-
-func TestExtractMajorVersion_MySQL(t *testing.T) {
-	tests := []struct {
-		version string
-		want    int
-	}{
-		{"8.0.35-0ubuntu0.22.04.1", 8},
-		{"5.7.44-log", 5},
-		{"10.6.14-MariaDB-1:10.6.14+maria~ubu2204", 10},
-	}
-
-	for _, tt := range tests {
-		got := extractMajorVersion(tt.version)
-		if got != tt.want {
-			t.Errorf("extractMajorVersion(%q) = %d, want %d", tt.version, got, tt.want)
-		}
-	}
-}
-
-func TestExtractMajorVersion_Postgres(t *testing.T) {
-	tests := []struct {
-		version string
-		want    int
-	}{
-		{"PostgreSQL 16.2 (Ubuntu 16.2-1.pgdg22.04+1) on x86_64-pc-linux-gnu", 16},
-		{"PostgreSQL 15.4 on x86_64-pc-linux-gnu", 15},
-		{"PostgreSQL 14.8", 14},
-	}
-
-	for _, tt := range tests {
-		got := extractMajorVersion(tt.version)
-		if got != tt.want {
-			t.Errorf("extractMajorVersion(%q) = %d, want %d", tt.version, got, tt.want)
-		}
-	}
-}
-
-func TestQuoteIdentifier_SpecialCharacters(t *testing.T) {
-	tests := []struct {
-		input string
-		want  string
-	}{
-		{"normal_name", `"normal_name"`},
-		{"table with spaces", `"table with spaces"`},
-		{`table"with"quotes`, `"table""with""quotes"`},
-		{"", `""`},
-		{"CamelCase", `"CamelCase"`},
-		{"123numeric", `"123numeric"`},
-	}
-
-	for _, tt := range tests {
-		got := quoteIdentifier(tt.input)
-		if got != tt.want {
-			t.Errorf("quoteIdentifier(%q) = %q, want %q", tt.input, got, tt.want)
-		}
-	}
-}
-
-func TestIsDatabaseNotExistsError_MySQLVariations(t *testing.T) {
+func TestDescribeConnection(t *testing.T) {
 	tests := []struct {
-		errMsg string
-		want   bool
+		cfg  config.DatabaseConfig
+		want string
 	}{
-		{"Error 1049: Unknown database 'mydb'", true},
-		{"Unknown database 'mydb'", true},
-		{"Error 1045: Access denied for user 'root'@'localhost'", false},
-		{"connection refused", false},
+		{config.DatabaseConfig{Engine: "mysql", Host: "db", Port: "3306", Database: "app", User: "root", Password: "secret"}, "mysql://root@db:3306/app"},
+		{config.DatabaseConfig{Engine: "sqlite", Database: "database/database.sqlite"}, "sqlite:database/database.sqlite"},
 	}
-
 	for _, tt := range tests {
-		err := errors.New(tt.errMsg)
-		got := isDatabaseNotExistsError(err, "mysql")
-		if got != tt.want {
-			t.Errorf("isDatabaseNotExistsError(%q, mysql) = %v, want %v", tt.errMsg, got, tt.want)
-		}
-	}
-}
-
-func TestIsDatabaseNotExistsError_PostgresVariations(t *testing.T) {
-	tests := []struct {
-		errMsg string
-		want   bool
-	}{
-		{`pq: FATAL: database "mydb" does not exist`, true},
-		{"3D000: database does not exist", true},
-		{"permission denied for database", false},
-		{"connection timeout", false},
-	}
-
-	for _, tt := range tests {
-		err := errors.New(tt.errMsg)
-		got := isDatabaseNotExistsError(err, "pgsql")
-		if got != tt.want {
-			t.Errorf("isDatabaseNotExistsError(%q, pgsql) = %v, want %v", tt.errMsg, got, tt.want)
-		}
-	}
-}
-
-func TestDatabaseNotExistsError_ErrorMethod(t *testing.T) {
-	tests := []struct {
-		database string
-		want     string
-	}{
-		{"mydb", `database "mydb" does not exist`},
-		{"test_database", `database "test_database" does not exist`},
-		{"", `database "" does not exist`},
-	}
-
-	for _, tt := range tests {
-		err := &DatabaseNotExistsError{Database: tt.database}
-		if err.Error() != tt.want {
-			t.Errorf("DatabaseNotExistsError{%q}.Error() = %q, want %q", tt.database, err.Error(), tt.want)
-		}
-	}
-}
-
-func TestConnect_MySQLConfig(t *testing.T) {
-	cfg := config.DatabaseConfig{
-		Engine:   "mysql",
-		Host:     "localhost",
-		Port:     "3306",
-		Database: "testdb",
-		User:     "testuser",
-		Password: "testpass",
-	}
-
-	// This will fail to connect but we can test that it tries
-	_, err := Connect(cfg)
-	// We expect an error since there's no actual database
-	if err == nil {
-		t.Logf("Connect() succeeded (unexpected in test environment)")
-	}
-}
-
-func TestConnect_PostgresConfig(t *testing.T) {
-	cfg := config.DatabaseConfig{
-		Engine:   "pgsql",
-		Host:     "localhost",
-		Port:     "5432",
-		Database: "testdb",
-		User:     "testuser",
-		Password: "testpass",
-	}
-
-	// This will fail to connect but we can test the code path
-	_, err := Connect(cfg)
-	// We expect an error since there's no actual database
-	if err == nil {
-		t.Logf("Connect() succeeded (unexpected in test environment)")
-	}
-}
-
-func TestCreateDatabase_MySQLConfig(t *testing.T) {
-	cfg := config.DatabaseConfig{
-		Engine:   "mysql",
-		Host:     "localhost",
-		Port:     "3306",
-		Database: "newdb",
-		User:     "testuser",
-		Password: "testpass",
-	}
-
-	// This will fail but tests the code path
-	err := CreateDatabase(cfg)
-	if err == nil {
-		t.Logf("CreateDatabase() succeeded (unexpected in test environment)")
-	}
-}
-
-func TestCreateDatabase_PostgresConfig(t *testing.T) {
-	cfg := config.DatabaseConfig{
-		Engine:   "pgsql",
-		Host:     "localhost",
-		Port:     "5432",
-		Database: "newdb",
-		User:     "testuser",
-		Password: "testpass",
-	}
-
-	// This will fail but tests the code path
-	err := CreateDatabase(cfg)
-	if err == nil {
-		t.Logf("CreateDatabase() succeeded (unexpected in test environment)")
-	}
-}
-
-func TestConnectResult_SSLModeField(t *testing.T) {
-	result := ConnectResult{
-		DB:      nil,
-		SSLMode: "require",
-	}
-
-	if result.SSLMode != "require" {
-		t.Errorf("ConnectResult.SSLMode = %q, want %q", result.SSLMode, "require")
-	}
-
-	result.SSLMode = "disable"
-	if result.SSLMode != "disable" {
-		t.Errorf("ConnectResult.SSLMode = %q, want %q", result.SSLMode, "disable")
-	}
-}
-
-func TestDatabaseInfo_TablesList(t *testing.T) {
-	info := DatabaseInfo{
-		Tables: []string{"users", "orders", "products"},
-	}
-
-	if len(info.Tables) != 3 {
-		t.Errorf("len(DatabaseInfo.Tables) = %d, want 3", len(info.Tables))
-	}
-
-	expectedTables := []string{"users", "orders", "products"}
-	for i, table := range info.Tables {
-		if table != expectedTables[i] {
-			t.Errorf("Tables[%d] = %q, want %q", i, table, expectedTables[i])
+		if got := describeConnection(tt.cfg); got != tt.want {
+			t.Errorf("describeConnection() = %q, want %q", got, tt.want)
 		}
 	}
 }
@@ -477,71 +120,11 @@ func TestCheckResult_AllFields(t *testing.T) {
 	}
 }
 
-func TestExtractMajorVersion_EdgeCases(t *testing.T) {
-	tests := []struct {
-		version string
-		want    int
-	}{
-		{"", 0},
-		{"invalid", 0},
-		{"no version here", 0},
-		{"v1.2.3", 1},
-		{"1", 0}, // Need at least major.minor
-		{"1.", 0},
-		{"abc.def", 0},
-	}
-
-	for _, tt := range tests {
-		got := extractMajorVersion(tt.version)
-		if got != tt.want {
-			t.Errorf("extractMajorVersion(%q) = %d, want %d", tt.version, got, tt.want)
-		}
-	}
-}
-
-func TestIsDatabaseNotExistsError_EmptyEngine(t *testing.T) {
-	err := errors.New("some error")
-	got := isDatabaseNotExistsError(err, "")
-	if got {
-		t.Errorf("isDatabaseNotExistsError() with empty engine = %v, want false", got)
-	}
-}
-
-func TestDatabaseNotExistsError_Types(t *testing.T) {
-	err := &DatabaseNotExistsError{Database: "testdb"}
-
-	// Test that it implements error interface
-	var e error = err
-	if e.Error() != `database "testdb" does not exist` {
-		t.Errorf("Error() = %q, want %q", e.Error(), `database "testdb" does not exist`)
-	}
-
-	// Test errors.As
-	var dbErr *DatabaseNotExistsError
-	if !errors.As(err, &dbErr) {
-		t.Errorf("errors.As() should match DatabaseNotExistsError")
-	}
-	if dbErr.Database != "testdb" {
-		t.Errorf("dbErr.Database = %q, want %q", dbErr.Database, "testdb")
-	}
-}
-
-func TestConnectResult_ZeroValue(t *testing.T) {
-	result := ConnectResult{}
-
-	if result.DB != nil {
-		t.Errorf("ConnectResult.DB = %v, want nil", result.DB)
-	}
-	if result.SSLMode != "" {
-		t.Errorf("ConnectResult.SSLMode = %q, want empty", result.SSLMode)
-	}
-}
-
 func TestPreflightResult_AllFields(t *testing.T) {
 	result := PreflightResult{
 		Passed:  true,
 		Aborted: false,
-		SourceInfo: DatabaseInfo{
+		SourceInfo: engine.DatabaseInfo{
 			Version:      "8.0.35",
 			MajorVersion: 8,
 			TableCount:   5,
@@ -549,7 +132,7 @@ func TestPreflightResult_AllFields(t *testing.T) {
 			TotalSize:    1024,
 			Tables:       []string{"users", "orders"},
 		},
-		DestInfo: DatabaseInfo{
+		DestInfo: engine.DatabaseInfo{
 			Version:      "8.0.36",
 			MajorVersion: 8,
 			TableCount:   0,
@@ -582,651 +165,484 @@ func TestPreflightResult_AllFields(t *testing.T) {
 	}
 }
 
-func TestDatabaseInfo_EmptyState(t *testing.T) {
-	info := DatabaseInfo{}
-
-	if info.Version != "" {
-		t.Errorf("DatabaseInfo.Version = %q, want empty", info.Version)
-	}
-	if info.MajorVersion != 0 {
-		t.Errorf("DatabaseInfo.MajorVersion = %d, want 0", info.MajorVersion)
-	}
-	if info.TableCount != 0 {
-		t.Errorf("DatabaseInfo.TableCount = %d, want 0", info.TableCount)
-	}
-	if info.ViewCount != 0 {
-		t.Errorf("DatabaseInfo.ViewCount = %d, want 0", info.ViewCount)
-	}
-	if info.TotalSize != 0 {
-		t.Errorf("DatabaseInfo.TotalSize = %d, want 0", info.TotalSize)
-	}
-	if len(info.Tables) != 0 {
-		t.Errorf("len(DatabaseInfo.Tables) = %d, want 0", len(info.Tables))
-	}
-}
-
-func TestIsDatabaseNotExistsError_AllCases(t *testing.T) {
-	tests := []struct {
-		name   string
-		err    error
-		engine string
-		want   bool
-	}{
-		{"nil error mysql", nil, "mysql", false},
-		{"nil error pgsql", nil, "pgsql", false},
-		{"mysql 1049 error", errors.New("Error 1049: Unknown database"), "mysql", true},
-		{"mysql unknown database", errors.New("Unknown database 'foo'"), "mysql", true},
-		{"mysql other error", errors.New("Connection refused"), "mysql", false},
-		{"pgsql 3D000 error", errors.New("pq: 3D000: database does not exist"), "pgsql", true},
-		{"pgsql does not exist", errors.New("database \"foo\" does not exist"), "pgsql", true},
-		{"pgsql other error", errors.New("password authentication failed"), "pgsql", false},
-		{"unknown engine", errors.New("any error"), "sqlite", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := isDatabaseNotExistsError(tt.err, tt.engine)
-			if got != tt.want {
-				t.Errorf("isDatabaseNotExistsError(%v, %q) = %v, want %v", tt.err, tt.engine, got, tt.want)
-			}
-		})
-	}
-}
-
-func TestQuoteIdentifier_EdgeCases(t *testing.T) {
-	tests := []struct {
-		input string
-		want  string
-	}{
-		{"simple", `"simple"`},
-		{"with space", `"with space"`},
-		{`with"quote`, `"with""quote"`},
-		{`many""quotes""here`, `"many""""quotes""""here"`},
-		{"", `""`},
-		{"MixedCase", `"MixedCase"`},
-		{"123", `"123"`},
-		{"a-b-c", `"a-b-c"`},
-	}
-
-	for _, tt := range tests {
-		got := quoteIdentifier(tt.input)
-		if got != tt.want {
-			t.Errorf("quoteIdentifier(%q) = %q, want %q", tt.input, got, tt.want)
-		}
+func TestCheckExtensions(t *testing.T) {
+	source, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
 	}
-}
+	defer source.Close()
 
-func TestGetDatabaseInfo_MySQL(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	dest, destMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	// Mock VERSION() query
-	mock.ExpectQuery("SELECT VERSION\\(\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.35"))
+	defer dest.Close()
 
-	// Mock table count and size query
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE\\(SUM\\(data_length \\+ index_length\\), 0\\)").
-		WithArgs("testdb").
-		WillReturnRows(sqlmock.NewRows([]string{"count", "size"}).AddRow(5, 1024000))
+	sourceMock.ExpectQuery("SELECT extname.*FROM pg_extension").
+		WillReturnRows(sqlmock.NewRows([]string{"extname"}).
+			AddRow("citext").AddRow("postgis"))
+	destMock.ExpectQuery("SELECT name FROM pg_available_extensions").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow("citext").AddRow("pgcrypto"))
 
-	// Mock view count query
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.views").
-		WithArgs("testdb").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
-
-	// Mock table names query
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
-		WithArgs("testdb").
-		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("users").AddRow("orders"))
-
-	info, err := getDatabaseInfo(db, "mysql", "testdb")
+	missing, err := checkExtensions(source, dest)
 	if err != nil {
-		t.Errorf("getDatabaseInfo() error = %v", err)
-	}
-
-	if info.Version != "8.0.35" {
-		t.Errorf("info.Version = %q, want %q", info.Version, "8.0.35")
-	}
-	if info.MajorVersion != 8 {
-		t.Errorf("info.MajorVersion = %d, want 8", info.MajorVersion)
-	}
-	if info.TableCount != 5 {
-		t.Errorf("info.TableCount = %d, want 5", info.TableCount)
+		t.Fatalf("checkExtensions() error = %v", err)
 	}
-	if info.ViewCount != 2 {
-		t.Errorf("info.ViewCount = %d, want 2", info.ViewCount)
-	}
-	if info.TotalSize != 1024000 {
-		t.Errorf("info.TotalSize = %d, want 1024000", info.TotalSize)
-	}
-	if len(info.Tables) != 2 {
-		t.Errorf("len(info.Tables) = %d, want 2", len(info.Tables))
-	}
-
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("expectations not met: %v", err)
+	if len(missing) != 1 || missing[0] != "postgis" {
+		t.Errorf("checkExtensions() = %v, want [postgis]", missing)
 	}
 }
 
-func TestGetDatabaseInfo_Postgres(t *testing.T) {
-	db, mock, err := sqlmock.New()
+func TestCheckExtensions_NoneInstalled(t *testing.T) {
+	source, sourceMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
+	defer source.Close()
 
-	// Mock version() query
-	mock.ExpectQuery("SELECT version\\(\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.2"))
-
-	// Mock table count query
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.tables.*table_type = 'BASE TABLE'").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
-
-	// Mock database size query
-	mock.ExpectQuery("SELECT pg_database_size\\(current_database\\(\\)\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(int64(2048000)))
-
-	// Mock view count query
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.views").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
-
-	// Mock table names query
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables.*ORDER BY table_name").
-		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("products").AddRow("users"))
-
-	info, err := getDatabaseInfo(db, "pgsql", "testdb")
+	dest, _, err := sqlmock.New()
 	if err != nil {
-		t.Errorf("getDatabaseInfo() error = %v", err)
-	}
-
-	if info.Version != "PostgreSQL 16.2" {
-		t.Errorf("info.Version = %q, want %q", info.Version, "PostgreSQL 16.2")
-	}
-	if info.MajorVersion != 16 {
-		t.Errorf("info.MajorVersion = %d, want 16", info.MajorVersion)
-	}
-	if info.TableCount != 3 {
-		t.Errorf("info.TableCount = %d, want 3", info.TableCount)
-	}
-	if info.ViewCount != 1 {
-		t.Errorf("info.ViewCount = %d, want 1", info.ViewCount)
+		t.Fatalf("failed to create mock: %v", err)
 	}
+	defer dest.Close()
 
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("expectations not met: %v", err)
-	}
-}
+	sourceMock.ExpectQuery("SELECT extname.*FROM pg_extension").
+		WillReturnRows(sqlmock.NewRows([]string{"extname"}))
 
-func TestGetDatabaseInfo_MySQL_VersionError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	missing, err := checkExtensions(source, dest)
 	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
+		t.Fatalf("checkExtensions() error = %v", err)
 	}
-	defer db.Close()
-
-	mock.ExpectQuery("SELECT VERSION\\(\\)").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	_, err = getDatabaseInfo(db, "mysql", "testdb")
-	if err == nil {
-		t.Errorf("getDatabaseInfo() expected error, got nil")
+	if len(missing) != 0 {
+		t.Errorf("checkExtensions() = %v, want none", missing)
 	}
 }
 
-func TestGetDatabaseInfo_MySQL_TableCountError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+func TestCheckRoutinePrivileges_NoRoutines(t *testing.T) {
+	source, sourceMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	mock.ExpectQuery("SELECT VERSION\\(\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.35"))
-
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE").
-		WithArgs("testdb").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	_, err = getDatabaseInfo(db, "mysql", "testdb")
-	if err == nil {
-		t.Errorf("getDatabaseInfo() expected error, got nil")
-	}
-}
-
-func TestGetDatabaseInfo_MySQL_ViewCountError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	defer source.Close()
+	dest, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
+	defer dest.Close()
 
-	mock.ExpectQuery("SELECT VERSION\\(\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.35"))
+	sourceMock.ExpectQuery("SELECT.*information_schema.routines.*information_schema.triggers.*information_schema.events").
+		WillReturnRows(sqlmock.NewRows([]string{"routines", "triggers", "events"}).AddRow(0, 0, 0))
 
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE").
-		WithArgs("testdb").
-		WillReturnRows(sqlmock.NewRows([]string{"count", "size"}).AddRow(5, 1024000))
-
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.views").
-		WithArgs("testdb").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	_, err = getDatabaseInfo(db, "mysql", "testdb")
-	if err == nil {
-		t.Errorf("getDatabaseInfo() expected error, got nil")
+	warnings, err := checkRoutinePrivileges(source, dest, "app")
+	if err != nil {
+		t.Fatalf("checkRoutinePrivileges() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("checkRoutinePrivileges() = %v, want no warnings", warnings)
 	}
 }
 
-func TestGetDatabaseInfo_MySQL_TableNamesError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+func TestCheckRoutinePrivileges_ManagedServer(t *testing.T) {
+	source, sourceMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	mock.ExpectQuery("SELECT VERSION\\(\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.35"))
-
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE").
-		WithArgs("testdb").
-		WillReturnRows(sqlmock.NewRows([]string{"count", "size"}).AddRow(5, 1024000))
-
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.views").
-		WithArgs("testdb").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
-
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
-		WithArgs("testdb").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	_, err = getDatabaseInfo(db, "mysql", "testdb")
-	if err == nil {
-		t.Errorf("getDatabaseInfo() expected error, got nil")
-	}
-}
-
-func TestWipeDatabase_MySQL(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	defer source.Close()
+	dest, destMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
+	defer dest.Close()
 
-	// Disable FK checks
-	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
-		WillReturnResult(sqlmock.NewResult(0, 0))
+	sourceMock.ExpectQuery("SELECT.*information_schema.routines.*information_schema.triggers.*information_schema.events").
+		WillReturnRows(sqlmock.NewRows([]string{"routines", "triggers", "events"}).AddRow(2, 1, 1))
+	destMock.ExpectQuery("SHOW GRANTS FOR CURRENT_USER\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"grants"}).
+			AddRow("GRANT USAGE ON *.* TO `app`@`%`").
+			AddRow("GRANT SELECT, INSERT, UPDATE, DELETE, CREATE, DROP, CREATE ROUTINE, TRIGGER ON `app`.* TO `app`@`%`"))
+	destMock.ExpectQuery("SELECT @@GLOBAL.log_bin, @@GLOBAL.log_bin_trust_function_creators").
+		WillReturnRows(sqlmock.NewRows([]string{"log_bin", "trust"}).AddRow(1, 0))
 
-	// Query tables
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables.*table_type = 'BASE TABLE'").
-		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("users").AddRow("orders"))
-
-	// Drop tables
-	mock.ExpectExec("DROP TABLE IF EXISTS `users`").
-		WillReturnResult(sqlmock.NewResult(0, 0))
-	mock.ExpectExec("DROP TABLE IF EXISTS `orders`").
-		WillReturnResult(sqlmock.NewResult(0, 0))
-
-	// Query views
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.views").
-		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("user_stats"))
-
-	// Drop views
-	mock.ExpectExec("DROP VIEW IF EXISTS `user_stats`").
-		WillReturnResult(sqlmock.NewResult(0, 0))
-
-	// Re-enable FK checks
-	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 1").
-		WillReturnResult(sqlmock.NewResult(0, 0))
-
-	err = wipeDatabase(db, "mysql")
+	warnings, err := checkRoutinePrivileges(source, dest, "app")
 	if err != nil {
-		t.Errorf("wipeDatabase() error = %v", err)
+		t.Fatalf("checkRoutinePrivileges() error = %v", err)
 	}
-
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("expectations not met: %v", err)
+	if len(warnings) != 2 {
+		t.Fatalf("checkRoutinePrivileges() returned %d warnings, want 2: %v", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[0], "EVENT") {
+		t.Errorf("warnings[0] = %q, want missing EVENT privilege", warnings[0])
+	}
+	if !strings.Contains(warnings[1], "log_bin_trust_function_creators") {
+		t.Errorf("warnings[1] = %q, want log_bin_trust_function_creators warning", warnings[1])
 	}
 }
 
-func TestWipeDatabase_Postgres(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
+func TestGrantedPrivileges(t *testing.T) {
+	grants := []string{
+		"GRANT ALL PRIVILEGES ON *.* TO `root`@`localhost` WITH GRANT OPTION",
+		"GRANT EVENT ON `other`.* TO `root`@`localhost`",
 	}
-	defer db.Close()
-
-	// Query tables
-	mock.ExpectQuery("SELECT tablename.*FROM pg_tables").
-		WillReturnRows(sqlmock.NewRows([]string{"tablename"}).AddRow("users").AddRow("orders"))
-
-	// Drop tables
-	mock.ExpectExec(`DROP TABLE IF EXISTS "users" CASCADE`).
-		WillReturnResult(sqlmock.NewResult(0, 0))
-	mock.ExpectExec(`DROP TABLE IF EXISTS "orders" CASCADE`).
-		WillReturnResult(sqlmock.NewResult(0, 0))
-
-	// Query views
-	mock.ExpectQuery("SELECT viewname.*FROM pg_views").
-		WillReturnRows(sqlmock.NewRows([]string{"viewname"}).AddRow("user_stats"))
-
-	// Drop views
-	mock.ExpectExec(`DROP VIEW IF EXISTS "user_stats" CASCADE`).
-		WillReturnResult(sqlmock.NewResult(0, 0))
-
-	// Query sequences
-	mock.ExpectQuery("SELECT sequencename.*FROM pg_sequences").
-		WillReturnRows(sqlmock.NewRows([]string{"sequencename"}).AddRow("users_id_seq"))
-
-	// Drop sequences
-	mock.ExpectExec(`DROP SEQUENCE IF EXISTS "users_id_seq" CASCADE`).
-		WillReturnResult(sqlmock.NewResult(0, 0))
-
-	err = wipeDatabase(db, "pgsql")
-	if err != nil {
-		t.Errorf("wipeDatabase() error = %v", err)
+	privileges := grantedPrivileges(grants, "app")
+	if !privileges["ALL PRIVILEGES"] || !privileges["SUPER"] {
+		t.Errorf("grantedPrivileges() = %v, want ALL PRIVILEGES and SUPER", privileges)
 	}
 
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("expectations not met: %v", err)
+	privileges = grantedPrivileges([]string{"GRANT ALL PRIVILEGES ON `app`.* TO `app`@`%`"}, "app")
+	if privileges["SUPER"] {
+		t.Errorf("database-level ALL PRIVILEGES should not imply SUPER")
 	}
 }
 
-func TestWipeDatabase_MySQL_DisableFKError(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
+func TestGrantedPrivileges_EscapedDatabaseNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		grant    string
+		database string
+		want     bool
+	}{
+		{"escaped underscore", "GRANT ALL PRIVILEGES ON `my\\_app`.* TO `app`@`%`", "my_app", true},
+		{"escaped underscore is literal", "GRANT ALL PRIVILEGES ON `my\\_app`.* TO `app`@`%`", "myXapp", false},
+		{"unescaped underscore is a wildcard", "GRANT ALL PRIVILEGES ON `my_app`.* TO `app`@`%`", "myXapp", true},
+		{"percent wildcard", "GRANT ALL PRIVILEGES ON `app\\_%`.* TO `app`@`%`", "app_staging", true},
+		{"escaped percent", "GRANT ALL PRIVILEGES ON `app\\%`.* TO `app`@`%`", "app_staging", false},
+		{"other database", "GRANT ALL PRIVILEGES ON `other`.* TO `app`@`%`", "my_app", false},
 	}
-	defer db.Close()
-
-	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	err = wipeDatabase(db, "mysql")
-	if err == nil {
-		t.Errorf("wipeDatabase() expected error, got nil")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privileges := grantedPrivileges([]string{tt.grant}, tt.database)
+			if got := privileges["ALL PRIVILEGES"]; got != tt.want {
+				t.Errorf("grantedPrivileges(%q, %q) has ALL PRIVILEGES = %v, want %v", tt.grant, tt.database, got, tt.want)
+			}
+		})
 	}
 }
 
-func TestWipeDatabase_MySQL_QueryTablesError(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
-	}
-	defer db.Close()
-
-	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
-		WillReturnResult(sqlmock.NewResult(0, 0))
-
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	err = wipeDatabase(db, "mysql")
-	if err == nil {
-		t.Errorf("wipeDatabase() expected error, got nil")
+func expectSourceDefiners(mock sqlmock.Sqlmock, rows ...[]driver.Value) {
+	result := sqlmock.NewRows([]string{"kind", "name", "definer", "security_type"})
+	for _, r := range rows {
+		result.AddRow(r...)
 	}
+	mock.ExpectQuery("SELECT 'view', table_name, definer, security_type.*information_schema.views").
+		WillReturnRows(result)
 }
 
-func TestWipeDatabase_MySQL_DropTableError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+func TestCheckDefiners_Strip(t *testing.T) {
+	source, sourceMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
-		WillReturnResult(sqlmock.NewResult(0, 0))
-
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
-		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("users"))
-
-	mock.ExpectExec("DROP TABLE IF EXISTS `users`").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	err = wipeDatabase(db, "mysql")
-	if err == nil {
-		t.Errorf("wipeDatabase() expected error, got nil")
-	}
-}
-
-func TestWipeDatabase_Postgres_QueryTablesError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	defer source.Close()
+	dest, destMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
+	defer dest.Close()
 
-	mock.ExpectQuery("SELECT tablename.*FROM pg_tables").
-		WillReturnError(sqlmock.ErrCancelled)
+	expectSourceDefiners(sourceMock,
+		[]driver.Value{"function", "order_total", "olduser@%", "DEFINER"},
+		[]driver.Value{"procedure", "report", "olduser@%", "INVOKER"},
+		[]driver.Value{"view", "user_stats", "app@%", "DEFINER"})
+	destMock.ExpectQuery("SELECT CURRENT_USER\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"user"}).AddRow("app@%"))
+	destMock.ExpectQuery("SHOW GRANTS FOR CURRENT_USER\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"grants"}).AddRow("GRANT ALL PRIVILEGES ON `app`.* TO `app`@`%`"))
 
-	err = wipeDatabase(db, "pgsql")
-	if err == nil {
-		t.Errorf("wipeDatabase() expected error, got nil")
+	warnings, err := checkDefiners(source, dest, "app", schema.DefinerPolicy{Mode: schema.DefinerStrip})
+	if err != nil {
+		t.Fatalf("checkDefiners() error = %v", err)
+	}
+	// Only order_total runs as a definer other than the migrating user
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "1 objects") || !strings.Contains(warnings[0], "function order_total (olduser@%)") {
+		t.Errorf("checkDefiners() = %v, want one warning about function order_total", warnings)
 	}
 }
 
-func TestWipeDatabase_Postgres_DropTableError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+func TestCheckDefiners_KeepWithoutSetUserID(t *testing.T) {
+	source, sourceMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	mock.ExpectQuery("SELECT tablename.*FROM pg_tables").
-		WillReturnRows(sqlmock.NewRows([]string{"tablename"}).AddRow("users"))
-
-	mock.ExpectExec(`DROP TABLE IF EXISTS "users" CASCADE`).
-		WillReturnError(sqlmock.ErrCancelled)
-
-	err = wipeDatabase(db, "pgsql")
-	if err == nil {
-		t.Errorf("wipeDatabase() expected error, got nil")
-	}
-}
-
-func TestWipeDatabase_MySQL_QueryViewsError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	defer source.Close()
+	dest, destMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
+	defer dest.Close()
 
-	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").
-		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectSourceDefiners(sourceMock,
+		[]driver.Value{"trigger", "orders_audit", "olduser@%", "DEFINER"},
+		[]driver.Value{"view", "user_stats", "app@%", "DEFINER"})
+	destMock.ExpectQuery("SELECT CURRENT_USER\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"user"}).AddRow("app@%"))
+	destMock.ExpectQuery("SHOW GRANTS FOR CURRENT_USER\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"grants"}).AddRow("GRANT ALL PRIVILEGES ON `app`.* TO `app`@`%`"))
 
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables").
-		WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
-
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.views").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	err = wipeDatabase(db, "mysql")
-	if err == nil {
-		t.Errorf("wipeDatabase() expected error, got nil")
+	warnings, err := checkDefiners(source, dest, "app", schema.DefinerPolicy{Mode: schema.DefinerKeep})
+	if err != nil {
+		t.Fatalf("checkDefiners() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "SET_USER_ID") || !strings.Contains(warnings[0], "trigger orders_audit") {
+		t.Errorf("checkDefiners() = %v, want SET_USER_ID warning for trigger orders_audit", warnings)
 	}
 }
 
-func TestWipeDatabase_Postgres_QueryViewsError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+func TestCheckDefiners_AccountWithSetUserID(t *testing.T) {
+	source, sourceMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	mock.ExpectQuery("SELECT tablename.*FROM pg_tables").
-		WillReturnRows(sqlmock.NewRows([]string{"tablename"}))
-
-	mock.ExpectQuery("SELECT viewname.*FROM pg_views").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	err = wipeDatabase(db, "pgsql")
-	if err == nil {
-		t.Errorf("wipeDatabase() expected error, got nil")
-	}
-}
-
-func TestWipeDatabase_Postgres_QuerySequencesError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	defer source.Close()
+	dest, destMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	mock.ExpectQuery("SELECT tablename.*FROM pg_tables").
-		WillReturnRows(sqlmock.NewRows([]string{"tablename"}))
-
-	mock.ExpectQuery("SELECT viewname.*FROM pg_views").
-		WillReturnRows(sqlmock.NewRows([]string{"viewname"}))
+	defer dest.Close()
 
-	mock.ExpectQuery("SELECT sequencename.*FROM pg_sequences").
-		WillReturnError(sqlmock.ErrCancelled)
+	expectSourceDefiners(sourceMock, []driver.Value{"view", "user_stats", "olduser@%", "DEFINER"})
+	destMock.ExpectQuery("SELECT CURRENT_USER\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"user"}).AddRow("admin@%"))
+	destMock.ExpectQuery("SHOW GRANTS FOR CURRENT_USER\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"grants"}).
+			AddRow("GRANT SELECT, INSERT ON *.* TO `admin`@`%`").
+			AddRow("GRANT SET_USER_ID ON *.* TO `admin`@`%`"))
 
-	err = wipeDatabase(db, "pgsql")
-	if err == nil {
-		t.Errorf("wipeDatabase() expected error, got nil")
+	warnings, err := checkDefiners(source, dest, "app", schema.DefinerPolicy{Mode: schema.DefinerAccount, Account: "`app`@`%`"})
+	if err != nil {
+		t.Fatalf("checkDefiners() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("checkDefiners() = %v, want no warnings", warnings)
 	}
 }
 
-func TestWipeDatabase_UnknownEngine(t *testing.T) {
-	db, _, err := sqlmock.New()
+func TestCheckDefiners_NoObjects(t *testing.T) {
+	source, sourceMock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	// Unknown engine should return nil (no-op)
-	err = wipeDatabase(db, "sqlite")
-	if err != nil {
-		t.Errorf("wipeDatabase() with unknown engine error = %v, want nil", err)
-	}
-}
-
-func TestGetDatabaseInfo_Postgres_VersionError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	defer source.Close()
+	dest, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
+	defer dest.Close()
 
-	mock.ExpectQuery("SELECT version\\(\\)").
-		WillReturnError(sqlmock.ErrCancelled)
+	expectSourceDefiners(sourceMock)
 
-	_, err = getDatabaseInfo(db, "pgsql", "testdb")
-	if err == nil {
-		t.Errorf("getDatabaseInfo() expected error, got nil")
+	warnings, err := checkDefiners(source, dest, "app", schema.DefinerPolicy{})
+	if err != nil {
+		t.Fatalf("checkDefiners() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("checkDefiners() = %v, want no warnings", warnings)
 	}
 }
 
-func TestGetDatabaseInfo_Postgres_TableCountError(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
+func TestCheckPartitioning(t *testing.T) {
+	tests := []struct {
+		name         string
+		destInfo     engine.DatabaseInfo
+		tables       [][]driver.Value
+		pluginStatus string // Empty when the plugin query isn't expected, "-" for no row
+		wantWarning  string
+	}{
+		{
+			name:     "MySQL 8 with InnoDB tables",
+			destInfo: engine.DatabaseInfo{Version: "8.0.36", MajorVersion: 8},
+			tables:   [][]driver.Value{{"logs", "InnoDB"}},
+		},
+		{
+			name:        "MySQL 8 with MyISAM tables",
+			destInfo:    engine.DatabaseInfo{Version: "8.0.36", MajorVersion: 8},
+			tables:      [][]driver.Value{{"archive", "MyISAM"}, {"logs", "InnoDB"}},
+			wantWarning: "1 partitioned tables will fail (use --partitioning drop): archive (MyISAM)",
+		},
+		{
+			name:         "MySQL 5.7 without the partition plugin",
+			destInfo:     engine.DatabaseInfo{Version: "5.7.44-log", MajorVersion: 5},
+			tables:       [][]driver.Value{{"logs", "InnoDB"}},
+			pluginStatus: "-",
+			wantWarning:  "Destination doesn't have partitioning enabled; 1 partitioned tables will fail",
+		},
+		{
+			name:         "MariaDB with the partition plugin",
+			destInfo:     engine.DatabaseInfo{Version: "10.11.6-MariaDB", MajorVersion: 10},
+			tables:       [][]driver.Value{{"archive", "Aria"}},
+			pluginStatus: "ACTIVE",
+		},
+		{
+			name:     "no partitioned tables",
+			destInfo: engine.DatabaseInfo{Version: "5.7.44", MajorVersion: 5},
+		},
 	}
-	defer db.Close()
 
-	mock.ExpectQuery("SELECT version\\(\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.2"))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, sourceMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock: %v", err)
+			}
+			defer source.Close()
+			dest, destMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock: %v", err)
+			}
+			defer dest.Close()
 
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.tables").
-		WillReturnError(sqlmock.ErrCancelled)
+			rows := sqlmock.NewRows([]string{"table_name", "engine"})
+			for _, r := range tt.tables {
+				rows.AddRow(r...)
+			}
+			sourceMock.ExpectQuery("SELECT DISTINCT p.table_name, t.engine.*FROM information_schema.partitions").WillReturnRows(rows)
+			switch tt.pluginStatus {
+			case "":
+			case "-":
+				destMock.ExpectQuery("SELECT plugin_status FROM information_schema.plugins").
+					WillReturnRows(sqlmock.NewRows([]string{"plugin_status"}))
+			default:
+				destMock.ExpectQuery("SELECT plugin_status FROM information_schema.plugins").
+					WillReturnRows(sqlmock.NewRows([]string{"plugin_status"}).AddRow(tt.pluginStatus))
+			}
 
-	_, err = getDatabaseInfo(db, "pgsql", "testdb")
-	if err == nil {
-		t.Errorf("getDatabaseInfo() expected error, got nil")
+			warnings, err := checkPartitioning(source, dest, tt.destInfo)
+			if err != nil {
+				t.Fatalf("checkPartitioning() error = %v", err)
+			}
+			if tt.wantWarning == "" && len(warnings) > 0 {
+				t.Errorf("checkPartitioning() = %v, want no warnings", warnings)
+			}
+			if tt.wantWarning != "" && (len(warnings) != 1 || !strings.Contains(warnings[0], tt.wantWarning)) {
+				t.Errorf("checkPartitioning() = %v, want a warning containing %q", warnings, tt.wantWarning)
+			}
+			if err := destMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("dest expectations not met: %v", err)
+			}
+		})
 	}
 }
 
-func TestGetDatabaseInfo_Postgres_SizeError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+func TestCheckMariaDB(t *testing.T) {
+	source, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	mock.ExpectQuery("SELECT version\\(\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.2"))
+	defer source.Close()
 
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.tables").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT 'sequence', table_name.*table_type = 'SEQUENCE'.*'SYSTEM VERSIONED'.*engine = 'Aria'.*'uuid', 'inet4', 'inet6'.*information_schema.check_constraints").
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "name"}).
+			AddRow("aria", "logs").
+			AddRow("json", "events.payload").
+			AddRow("sequence", "order_seq").
+			AddRow("type", "events.id (uuid)").
+			AddRow("versioned", "prices"))
 
-	mock.ExpectQuery("SELECT pg_database_size").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	_, err = getDatabaseInfo(db, "pgsql", "testdb")
-	if err == nil {
-		t.Errorf("getDatabaseInfo() expected error, got nil")
+	warnings, err := checkMariaDB(source, "mysql", engine.DatabaseInfo{Version: "5.7.44", MajorVersion: 5})
+	if err != nil {
+		t.Fatalf("checkMariaDB() error = %v", err)
+	}
+	want := []string{
+		"1 sequences aren't migrated to mysql; NEXTVAL defaults are dropped: order_seq",
+		"1 system-versioned tables lose their history; only current rows are copied: prices",
+		"1 Aria tables become InnoDB: logs",
+		"1 uuid and inet columns become char and varchar: events.id (uuid)",
+		"MySQL 5.7.44 is older than 8.0, which MariaDB tables are translated for",
+		"1 JSON columns need MySQL 8: events.payload",
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("checkMariaDB() = %q, want %q", warnings, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
 	}
 }
 
-func TestGetDatabaseInfo_Postgres_ViewCountError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+func TestCheckMariaDB_Postgres(t *testing.T) {
+	source, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
-
-	mock.ExpectQuery("SELECT version\\(\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.2"))
-
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.tables").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	defer source.Close()
 
-	mock.ExpectQuery("SELECT pg_database_size").
-		WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(int64(2048000)))
+	// PostgreSQL has uuid, inet and JSON types and no storage engines, so only sequences and
+	// system versioning are reported
+	mock.ExpectQuery("SELECT 'sequence', table_name").
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "name"}).
+			AddRow("aria", "logs").
+			AddRow("type", "events.id (uuid)"))
 
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.views").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	_, err = getDatabaseInfo(db, "pgsql", "testdb")
-	if err == nil {
-		t.Errorf("getDatabaseInfo() expected error, got nil")
+	warnings, err := checkMariaDB(source, "pgsql", engine.DatabaseInfo{Version: "PostgreSQL 16.2", MajorVersion: 16})
+	if err != nil {
+		t.Fatalf("checkMariaDB() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("checkMariaDB() = %q, want none", warnings)
 	}
 }
 
-func TestGetDatabaseInfo_Postgres_TableNamesError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+func TestCheckMySQLForMariaDB(t *testing.T) {
+	source, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create mock: %v", err)
 	}
-	defer db.Close()
+	defer source.Close()
 
-	mock.ExpectQuery("SELECT version\\(\\)").
-		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.2"))
+	mock.ExpectQuery("SELECT 'json'.*data_type = 'json'.*collation_name LIKE.*_0900.*table_collation LIKE").
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "name"}).
+			AddRow("collation", "orders (utf8mb4_0900_ai_ci)").
+			AddRow("collation", "users.email (utf8mb4_0900_as_cs)").
+			AddRow("json", "events.payload"))
 
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.tables").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
-
-	mock.ExpectQuery("SELECT pg_database_size").
-		WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(int64(2048000)))
-
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\).*FROM information_schema.views").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
-
-	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables.*ORDER BY table_name").
-		WillReturnError(sqlmock.ErrCancelled)
-
-	_, err = getDatabaseInfo(db, "pgsql", "testdb")
-	if err == nil {
-		t.Errorf("getDatabaseInfo() expected error, got nil")
+	warnings, err := checkMySQLForMariaDB(source)
+	if err != nil {
+		t.Fatalf("checkMySQLForMariaDB() error = %v", err)
+	}
+	want := []string{
+		"1 JSON columns become LONGTEXT with a json_valid CHECK on MariaDB, and their values are compared as text: events.payload",
+		"2 tables and columns use MySQL 8's utf8mb4_0900 collations, which MariaDB only accepts from 11.4.5: orders (utf8mb4_0900_ai_ci), users.email (utf8mb4_0900_as_cs)",
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("checkMySQLForMariaDB() = %q, want %q", warnings, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
 	}
 }
 
-func TestGetDatabaseInfo_UnknownEngine(t *testing.T) {
-	db, _, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("failed to create mock: %v", err)
+func TestCheckMariaDBCompatibility(t *testing.T) {
+	tests := []struct {
+		source, dest string
+		query        string
+	}{
+		{"mysql", "mariadb", "SELECT 'json'"},
+		{"mariadb", "mysql", "SELECT 'sequence'"},
+		{"mariadb", "pgsql", "SELECT 'sequence'"},
+		{"mysql", "mysql", ""},
+		{"mariadb", "mariadb", ""},
+		{"mysql", "pgsql", ""},
 	}
-	defer db.Close()
+	for _, tt := range tests {
+		t.Run(tt.source+"->"+tt.dest, func(t *testing.T) {
+			source, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock: %v", err)
+			}
+			defer source.Close()
+			if tt.query != "" {
+				mock.ExpectQuery(tt.query).WillReturnRows(sqlmock.NewRows([]string{"kind", "name"}))
+			}
 
-	// Unknown engine should return empty info
-	info, err := getDatabaseInfo(db, "sqlite", "testdb")
-	if err != nil {
-		t.Errorf("getDatabaseInfo() with unknown engine error = %v, want nil", err)
-	}
-	if info.Version != "" {
-		t.Errorf("info.Version = %q, want empty", info.Version)
+			cfg := config.MigrationConfig{
+				Source:      config.DatabaseConfig{Engine: tt.source},
+				Destination: config.DatabaseConfig{Engine: tt.dest},
+			}
+			if _, err := checkMariaDBCompatibility(source, cfg, engine.DatabaseInfo{MajorVersion: 8}); err != nil {
+				t.Fatalf("checkMariaDBCompatibility() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("expectations not met: %v", err)
+			}
+		})
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"syscall"
 
 	"github.com/DGarbs51/lcmigrate/internal/config"
+	"github.com/DGarbs51/lcmigrate/internal/engine"
 	"github.com/DGarbs51/lcmigrate/internal/io"
 	"github.com/DGarbs51/lcmigrate/internal/ui"
 	"github.com/fatih/color"
@@ -94,8 +95,20 @@ func (p *Prompter) PromptSourceDatabase() config.DatabaseConfig {
 	if engineDefault == "" {
 		engineDefault = "mysql"
 	}
-	engine := p.PromptWithDefault("Database engine (mysql/pgsql)", engineDefault)
-	engine = config.NormalizeEngine(engine)
+	engineName := p.PromptWithDefault("Database engine (mysql/mariadb/pgsql/sqlite)", engineDefault)
+	engineName = config.NormalizeEngine(engineName)
+
+	// A SQLite source is a database file, with no server to log in to
+	if engine.IsFile(engineName) {
+		fileDefault := defaults.Database
+		if fileDefault == "" {
+			fileDefault = config.DefaultSQLitePath
+		}
+		return config.DatabaseConfig{
+			Engine:   engineName,
+			Database: p.PromptWithDefault("Database file", fileDefault),
+		}
+	}
 
 	// Host
 	hostDefault := defaults.Host
@@ -107,7 +120,7 @@ func (p *Prompter) PromptSourceDatabase() config.DatabaseConfig {
 	// Port
 	portDefault := defaults.Port
 	if portDefault == "" {
-		portDefault = config.DefaultPort(engine)
+		portDefault = config.DefaultPort(engineName)
 	}
 	port := p.PromptWithDefault("Port", portDefault)
 
@@ -125,7 +138,7 @@ func (p *Prompter) PromptSourceDatabase() config.DatabaseConfig {
 	password := p.ReadPassword("Password", defaults.Password)
 
 	return config.DatabaseConfig{
-		Engine:   engine,
+		Engine:   engineName,
 		Host:     host,
 		Port:     port,
 		Database: database,
@@ -140,8 +153,20 @@ func (p *Prompter) PromptDestinationDatabase(sourceEngine string) config.Databas
 
 	ui.Header("Destination Database")
 
-	// Engine is locked to match source
-	p.console.Printf("  %s %s\n", cyan("Database engine:"), bold(sourceEngine)+" (must match source)")
+	// Engine defaults to the source's; MySQL can also migrate into PostgreSQL, and a SQLite
+	// file into either server
+	engineDefault := defaults.Engine
+	if engineDefault == "" {
+		engineDefault = sourceEngine
+		if engine.IsFile(sourceEngine) {
+			engineDefault = "mysql"
+		}
+	}
+	engineName := p.PromptWithDefault("Database engine (mysql/mariadb/pgsql)", engineDefault)
+	engineName = config.NormalizeEngine(engineName)
+	if engineName != sourceEngine {
+		p.console.Printf("  %s\n", cyan(fmt.Sprintf("Cross-engine migration: %s to %s", sourceEngine, engineName)))
+	}
 
 	// Host
 	hostDefault := defaults.Host
@@ -153,7 +178,7 @@ func (p *Prompter) PromptDestinationDatabase(sourceEngine string) config.Databas
 	// Port
 	portDefault := defaults.Port
 	if portDefault == "" {
-		portDefault = config.DefaultPort(sourceEngine)
+		portDefault = config.DefaultPort(engineName)
 	}
 	port := p.PromptWithDefault("Port", portDefault)
 
@@ -171,7 +196,7 @@ func (p *Prompter) PromptDestinationDatabase(sourceEngine string) config.Databas
 	password := p.ReadPassword("Password", defaults.Password)
 
 	return config.DatabaseConfig{
-		Engine:   sourceEngine,
+		Engine:   engineName,
 		Host:     host,
 		Port:     port,
 		Database: database,
@@ -280,8 +305,20 @@ func PromptSourceDatabase() config.DatabaseConfig {
 	if engineDefault == "" {
 		engineDefault = "mysql"
 	}
-	engine := promptWithDefault(reader, "Database engine (mysql/pgsql)", engineDefault)
-	engine = config.NormalizeEngine(engine)
+	engineName := promptWithDefault(reader, "Database engine (mysql/mariadb/pgsql/sqlite)", engineDefault)
+	engineName = config.NormalizeEngine(engineName)
+
+	// A SQLite source is a database file, with no server to log in to
+	if engine.IsFile(engineName) {
+		fileDefault := defaults.Database
+		if fileDefault == "" {
+			fileDefault = config.DefaultSQLitePath
+		}
+		return config.DatabaseConfig{
+			Engine:   engineName,
+			Database: promptWithDefault(reader, "Database file", fileDefault),
+		}
+	}
 
 	// Host
 	hostDefault := defaults.Host
@@ -293,7 +330,7 @@ func PromptSourceDatabase() config.DatabaseConfig {
 	// Port
 	portDefault := defaults.Port
 	if portDefault == "" {
-		portDefault = config.DefaultPort(engine)
+		portDefault = config.DefaultPort(engineName)
 	}
 	port := promptWithDefault(reader, "Port", portDefault)
 
@@ -311,7 +348,7 @@ func PromptSourceDatabase() config.DatabaseConfig {
 	password := readPassword("Password", defaults.Password)
 
 	return config.DatabaseConfig{
-		Engine:   engine,
+		Engine:   engineName,
 		Host:     host,
 		Port:     port,
 		Database: database,
@@ -321,15 +358,27 @@ func PromptSourceDatabase() config.DatabaseConfig {
 }
 
 // PromptDestinationDatabase prompts for destination database credentials
-// The engine is locked to match the source database
+// The engine defaults to the source's
 func PromptDestinationDatabase(sourceEngine string) config.DatabaseConfig {
 	reader := bufio.NewReader(os.Stdin)
 	defaults := config.LoadDestinationDefaults()
 
 	ui.Header("Destination Database")
 
-	// Engine is locked to match source
-	fmt.Printf("  %s %s\n", cyan("Database engine:"), bold(sourceEngine)+" (must match source)")
+	// Engine defaults to the source's; MySQL can also migrate into PostgreSQL, and a SQLite
+	// file into either server
+	engineDefault := defaults.Engine
+	if engineDefault == "" {
+		engineDefault = sourceEngine
+		if engine.IsFile(sourceEngine) {
+			engineDefault = "mysql"
+		}
+	}
+	engineName := promptWithDefault(reader, "Database engine (mysql/mariadb/pgsql)", engineDefault)
+	engineName = config.NormalizeEngine(engineName)
+	if engineName != sourceEngine {
+		fmt.Printf("  %s\n", cyan(fmt.Sprintf("Cross-engine migration: %s to %s", sourceEngine, engineName)))
+	}
 
 	// Host
 	hostDefault := defaults.Host
@@ -341,7 +390,7 @@ func PromptDestinationDatabase(sourceEngine string) config.DatabaseConfig {
 	// Port
 	portDefault := defaults.Port
 	if portDefault == "" {
-		portDefault = config.DefaultPort(sourceEngine)
+		portDefault = config.DefaultPort(engineName)
 	}
 	port := promptWithDefault(reader, "Port", portDefault)
 
@@ -359,7 +408,7 @@ func PromptDestinationDatabase(sourceEngine string) config.DatabaseConfig {
 	password := readPassword("Password", defaults.Password)
 
 	return config.DatabaseConfig{
-		Engine:   sourceEngine,
+		Engine:   engineName,
 		Host:     host,
 		Port:     port,
 		Database: database,
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/DGarbs51/lcmigrate/internal/config"
 	"github.com/DGarbs51/lcmigrate/internal/io"
 )
 
@@ -310,15 +311,44 @@ func TestPrompter_PromptSourceDatabase_PostgreSQL(t *testing.T) {
 	})
 }
 
+func TestPrompter_PromptSourceDatabase_SQLite(t *testing.T) {
+	// Only the engine and the database file are asked for
+	inputs := []string{"sqlite3", ""}
+	mock := io.NewMockConsole(inputs, "")
+	p := NewPrompter(mock)
+
+	envVars := []string{
+		"SOURCE_DB_ENGINE", "SOURCE_DB_CONNECTION", "DB_ENGINE", "DB_CONNECTION",
+		"SOURCE_DB_DATABASE", "SOURCE_DB_NAME", "DB_DATABASE", "DB_NAME",
+	}
+	for _, v := range envVars {
+		os.Unsetenv(v)
+	}
+
+	captureStdout(func() {
+		cfg := p.PromptSourceDatabase()
+
+		if cfg.Engine != "sqlite" {
+			t.Errorf("Engine = %q, want %q", cfg.Engine, "sqlite")
+		}
+		if cfg.Database != config.DefaultSQLitePath {
+			t.Errorf("Database = %q, want %q", cfg.Database, config.DefaultSQLitePath)
+		}
+		if cfg.Host != "" || cfg.User != "" {
+			t.Errorf("Host/User = %q/%q, want empty", cfg.Host, cfg.User)
+		}
+	})
+}
+
 func TestPrompter_PromptDestinationDatabase(t *testing.T) {
-	// Provide inputs for: host, port, database, user, password
-	inputs := []string{"dest-host", "5433", "destdb", "destuser"}
+	// Provide inputs for: engine, host, port, database, user, password
+	inputs := []string{"", "dest-host", "5433", "destdb", "destuser"}
 	mock := io.NewMockConsole(inputs, "destpass")
 	p := NewPrompter(mock)
 
 	// Clear env vars
 	envVars := []string{
-		"DESTINATION_DB_HOST", "DESTINATION_DB_PORT",
+		"DESTINATION_DB_ENGINE", "DESTINATION_DB_CONNECTION", "DESTINATION_DB_HOST", "DESTINATION_DB_PORT",
 		"DESTINATION_DB_DATABASE", "DESTINATION_DB_USER", "DESTINATION_DB_PASSWORD",
 	}
 	for _, v := range envVars {
@@ -328,7 +358,7 @@ func TestPrompter_PromptDestinationDatabase(t *testing.T) {
 	captureStdout(func() {
 		cfg := p.PromptDestinationDatabase("pgsql")
 
-		// Engine should be locked to source engine
+		// Engine defaults to the source engine
 		if cfg.Engine != "pgsql" {
 			t.Errorf("Engine = %q, want %q", cfg.Engine, "pgsql")
 		}
@@ -350,11 +380,66 @@ func TestPrompter_PromptDestinationDatabase(t *testing.T) {
 	})
 }
 
+func TestPrompter_PromptDestinationDatabase_CrossEngine(t *testing.T) {
+	inputs := []string{"postgres", "", "", "destdb", ""}
+	mock := io.NewMockConsole(inputs, "")
+	p := NewPrompter(mock)
+
+	envVars := []string{
+		"DESTINATION_DB_ENGINE", "DESTINATION_DB_CONNECTION", "DESTINATION_DB_HOST", "DESTINATION_DB_PORT",
+		"DESTINATION_DB_DATABASE", "DESTINATION_DB_USER", "DESTINATION_DB_PASSWORD",
+	}
+	for _, v := range envVars {
+		os.Unsetenv(v)
+	}
+
+	captureStdout(func() {
+		cfg := p.PromptDestinationDatabase("mysql")
+
+		if cfg.Engine != "pgsql" {
+			t.Errorf("Engine = %q, want %q", cfg.Engine, "pgsql")
+		}
+		// The port default follows the destination engine
+		if cfg.Port != "5432" {
+			t.Errorf("Port = %q, want %q", cfg.Port, "5432")
+		}
+		if output := mock.GetOutput(); !strings.Contains(output, "Cross-engine migration: mysql to pgsql") {
+			t.Errorf("output should announce the cross-engine migration, got %q", output)
+		}
+	})
+}
+
+func TestPrompter_PromptDestinationDatabase_FromSQLite(t *testing.T) {
+	inputs := []string{"", "", "", "destdb", ""}
+	mock := io.NewMockConsole(inputs, "")
+	p := NewPrompter(mock)
+
+	envVars := []string{
+		"DESTINATION_DB_ENGINE", "DESTINATION_DB_CONNECTION", "DESTINATION_DB_HOST", "DESTINATION_DB_PORT",
+		"DESTINATION_DB_DATABASE", "DESTINATION_DB_USER", "DESTINATION_DB_PASSWORD",
+	}
+	for _, v := range envVars {
+		os.Unsetenv(v)
+	}
+
+	captureStdout(func() {
+		cfg := p.PromptDestinationDatabase("sqlite")
+
+		// A SQLite source defaults to a MySQL destination
+		if cfg.Engine != "mysql" {
+			t.Errorf("Engine = %q, want %q", cfg.Engine, "mysql")
+		}
+		if cfg.Port != "3306" {
+			t.Errorf("Port = %q, want %q", cfg.Port, "3306")
+		}
+	})
+}
+
 func TestPrompter_PromptMigrationConfig(t *testing.T) {
-	// Source inputs (5) + destination inputs (4)
+	// Source inputs (5) + destination inputs (5)
 	inputs := []string{
 		"mysql", "src-host", "3306", "srcdb", "srcuser",
-		"dest-host", "3307", "destdb", "destuser",
+		"", "dest-host", "3307", "destdb", "destuser",
 	}
 	mock := io.NewMockConsole(inputs, "password")
 	p := NewPrompter(mock)
@@ -364,7 +449,7 @@ func TestPrompter_PromptMigrationConfig(t *testing.T) {
 		"SOURCE_DB_ENGINE", "DB_ENGINE", "SOURCE_DB_HOST", "DB_HOST",
 		"SOURCE_DB_PORT", "DB_PORT", "SOURCE_DB_DATABASE", "DB_DATABASE",
 		"SOURCE_DB_USER", "DB_USER", "SOURCE_DB_PASSWORD", "DB_PASSWORD",
-		"DESTINATION_DB_HOST", "DESTINATION_DB_PORT",
+		"DESTINATION_DB_ENGINE", "DESTINATION_DB_CONNECTION", "DESTINATION_DB_HOST", "DESTINATION_DB_PORT",
 		"DESTINATION_DB_DATABASE", "DESTINATION_DB_USER", "DESTINATION_DB_PASSWORD",
 	}
 	for _, v := range envVars {
@@ -389,7 +474,7 @@ func TestPrompter_PromptMigrationConfig(t *testing.T) {
 func TestPrompter_PromptMigrationConfig_DryRun(t *testing.T) {
 	inputs := []string{
 		"mysql", "", "", "db", "",
-		"", "", "destdb", "",
+		"", "", "", "destdb", "",
 	}
 	mock := io.NewMockConsole(inputs, "")
 	p := NewPrompter(mock)
@@ -399,7 +484,7 @@ func TestPrompter_PromptMigrationConfig_DryRun(t *testing.T) {
 		"SOURCE_DB_ENGINE", "DB_ENGINE", "SOURCE_DB_HOST", "DB_HOST",
 		"SOURCE_DB_PORT", "DB_PORT", "SOURCE_DB_DATABASE", "DB_DATABASE",
 		"SOURCE_DB_USER", "DB_USER", "SOURCE_DB_PASSWORD", "DB_PASSWORD",
-		"DESTINATION_DB_HOST", "DESTINATION_DB_PORT",
+		"DESTINATION_DB_ENGINE", "DESTINATION_DB_CONNECTION", "DESTINATION_DB_HOST", "DESTINATION_DB_PORT",
 		"DESTINATION_DB_DATABASE", "DESTINATION_DB_USER", "DESTINATION_DB_PASSWORD",
 	}
 	for _, v := range envVars {
@@ -0,0 +1,326 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ddlClauseKind classifies an entry in a CREATE TABLE definition list
+type ddlClauseKind int
+
+const (
+	clauseColumn     ddlClauseKind = iota // Column definition
+	clausePrimaryKey                      // PRIMARY KEY (...), named or not
+	clauseKey                             // KEY, INDEX, UNIQUE, FULLTEXT or SPATIAL index or constraint
+	clauseForeignKey                      // FOREIGN KEY (...) REFERENCES ...
+	clauseCheck                           // CHECK (...)
+	clauseOther                           // Anything else: LIKE, EXCLUDE, ...
+)
+
+// ddlClause is one entry of a CREATE TABLE definition list
+// The separating comma isn't part of any clause; whitespace and comments after a comma
+// belong to the clause that follows it.
+type ddlClause struct {
+	Kind ddlClauseKind
+	Name string // Column, index or constraint name, unquoted; empty for unnamed keys and constraints
+	Text string // The clause exactly as written, including surrounding whitespace and comments
+
+	tokens        []sqlToken // Significant tokens, with offsets relative to Text
+	endsInComment bool       // Text ends in a -- or # comment, which needs the newline after it
+}
+
+// createTableDDL is a CREATE TABLE statement split around its definition list
+// String reassembles it byte for byte: Prefix, the clauses joined by commas, then Suffix.
+type createTableDDL struct {
+	Prefix  string // Everything up to and including the opening parenthesis
+	Clauses []ddlClause
+	Suffix  string // The closing parenthesis and everything after it (table options, partitioning)
+}
+
+// parseCreateTable splits a MySQL or PostgreSQL CREATE TABLE statement into its clauses
+// Statements without a definition list (CREATE TABLE ... AS, PARTITION OF without constraints)
+// are returned with no clauses and the whole statement as Prefix.
+func parseCreateTable(stmt string, syntax sqlSyntax) (*createTableDDL, error) {
+	tokens := tokenizeSQL(stmt, syntax)
+
+	open, err := definitionListStart(stmt, tokens)
+	if err != nil {
+		return nil, err
+	}
+	if open < 0 {
+		return &createTableDDL{Prefix: stmt}, nil
+	}
+
+	ddl := &createTableDDL{Prefix: stmt[:tokens[open].End]}
+	clauseStart := tokens[open].End
+	var clauseTokens []sqlToken
+	endsInComment := false
+	depth := 0
+	for _, t := range tokens[open+1:] {
+		switch {
+		case t.isPunct(stmt, '('):
+			depth++
+		case t.isPunct(stmt, ')') && depth > 0:
+			depth--
+		case t.isPunct(stmt, ')'), t.isPunct(stmt, ',') && depth == 0:
+			c := newDDLClause(stmt[clauseStart:t.Start], clauseStart, clauseTokens, syntax)
+			c.endsInComment = endsInComment
+			ddl.Clauses = append(ddl.Clauses, c)
+			clauseTokens = nil
+			endsInComment = false
+			clauseStart = t.End
+			if stmt[t.Start] == ')' {
+				ddl.Suffix = stmt[t.Start:]
+				return ddl, nil
+			}
+			continue
+		}
+		switch t.Kind {
+		case tokSpace:
+		case tokComment:
+			endsInComment = !strings.HasPrefix(stmt[t.Start:t.End], "/*")
+		default:
+			clauseTokens = append(clauseTokens, t)
+			endsInComment = false
+		}
+	}
+	return nil, fmt.Errorf("unterminated definition list in CREATE TABLE")
+}
+
+// definitionListStart returns the index of the token opening the definition list, or -1 if there is none
+// The list directly follows the table name, or PARTITION OF parent / OF type on PostgreSQL.
+func definitionListStart(stmt string, tokens []sqlToken) (int, error) {
+	var sig []int
+	for i, t := range tokens {
+		if t.Kind != tokSpace && t.Kind != tokComment {
+			sig = append(sig, i)
+		}
+	}
+	at := func(n int) sqlToken {
+		if n >= len(sig) {
+			return sqlToken{Kind: tokSpace}
+		}
+		return tokens[sig[n]]
+	}
+
+	n := 0
+	for n < len(sig) && at(0).isKeyword("CREATE") && !at(n).isKeyword("TABLE") {
+		n++
+	}
+	if n == len(sig) || !at(n).isKeyword("TABLE") {
+		return 0, fmt.Errorf("not a CREATE TABLE statement")
+	}
+	n++
+	if at(n).isKeyword("IF") && at(n+1).isKeyword("NOT") && at(n+2).isKeyword("EXISTS") {
+		n += 3
+	}
+
+	// skipName steps over a possibly qualified name
+	skipName := func() error {
+		if !at(n).isIdent() {
+			return fmt.Errorf("expected a table name in CREATE TABLE")
+		}
+		n++
+		for at(n).isPunct(stmt, '.') && at(n+1).isIdent() {
+			n += 2
+		}
+		return nil
+	}
+	if err := skipName(); err != nil {
+		return 0, err
+	}
+	switch {
+	case at(n).isKeyword("PARTITION") && at(n+1).isKeyword("OF"):
+		n += 2
+		if err := skipName(); err != nil {
+			return 0, err
+		}
+	case at(n).isKeyword("OF"):
+		n++
+		if err := skipName(); err != nil {
+			return 0, err
+		}
+	}
+
+	if at(n).isPunct(stmt, '(') {
+		return sig[n], nil
+	}
+	return -1, nil
+}
+
+// newDDLClause classifies a clause from its leading keywords
+// offset is where text starts in the statement the tokens came from
+func newDDLClause(text string, offset int, tokens []sqlToken, syntax sqlSyntax) ddlClause {
+	c := ddlClause{Kind: clauseOther, Text: text}
+	for _, t := range tokens {
+		t.Start -= offset
+		t.End -= offset
+		c.tokens = append(c.tokens, t)
+	}
+	if len(c.tokens) == 0 {
+		return c
+	}
+
+	at := func(n int) sqlToken {
+		if n >= len(c.tokens) {
+			return sqlToken{Kind: tokSpace}
+		}
+		return c.tokens[n]
+	}
+	// indexName returns the optional index name at n, which MySQL allows before the column list
+	indexName := func(n int) string {
+		if at(n).isIdent() && !at(n).isKeyword("USING") {
+			return at(n).Value
+		}
+		return ""
+	}
+
+	n := 0
+	if at(0).isKeyword("CONSTRAINT") {
+		if at(1).isIdent() && !isConstraintKeyword(at(1)) {
+			c.Name = at(1).Value
+			n = 2
+		} else {
+			n = 1
+		}
+	}
+
+	first := at(n)
+	switch {
+	case first.isKeyword("PRIMARY") && at(n+1).isKeyword("KEY"):
+		c.Kind = clausePrimaryKey
+	case first.isKeyword("FOREIGN") && at(n+1).isKeyword("KEY"):
+		c.Kind = clauseForeignKey
+		if c.Name == "" {
+			c.Name = indexName(n + 2)
+		}
+	case first.isKeyword("CHECK"):
+		c.Kind = clauseCheck
+	case first.isKeyword("UNIQUE"):
+		c.Kind = clauseKey
+		n++
+		if at(n).isKeyword("KEY") || at(n).isKeyword("INDEX") {
+			n++
+		}
+		if c.Name == "" {
+			c.Name = indexName(n)
+		}
+	case (first.isKeyword("FULLTEXT") || first.isKeyword("SPATIAL")) && syntax.backticks:
+		c.Kind = clauseKey
+		n++
+		if at(n).isKeyword("KEY") || at(n).isKeyword("INDEX") {
+			n++
+		}
+		c.Name = indexName(n)
+	case (first.isKeyword("KEY") || first.isKeyword("INDEX")) && n == 0 && syntax.backticks:
+		// Only MySQL has inline indexes; on PostgreSQL these are column names
+		c.Kind = clauseKey
+		c.Name = indexName(1)
+	case n > 0 || first.isKeyword("LIKE") || (first.isKeyword("EXCLUDE") && (at(1).isKeyword("USING") || at(1).isPunct(c.Text, '('))):
+		c.Kind = clauseOther
+	case first.isIdent():
+		c.Kind = clauseColumn
+		c.Name = first.Value
+	}
+	return c
+}
+
+// isConstraintKeyword reports whether a token starts a constraint body, so it can't be a constraint name
+func isConstraintKeyword(t sqlToken) bool {
+	for _, k := range []string{"PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE"} {
+		if t.isKeyword(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// String reassembles the statement
+func (d *createTableDDL) String() string {
+	var b strings.Builder
+	b.WriteString(d.Prefix)
+	for i, c := range d.Clauses {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(c.Text)
+	}
+	b.WriteString(d.Suffix)
+	return b.String()
+}
+
+// removeClauses drops the clauses for which remove returns true
+// The whitespace before the closing parenthesis is kept when the last clause is dropped,
+// so SHOW CREATE TABLE layouts stay intact.
+func (d *createTableDDL) removeClauses(remove func(ddlClause) bool) {
+	if len(d.Clauses) == 0 {
+		return
+	}
+	last := d.Clauses[len(d.Clauses)-1]
+	kept := d.Clauses[:0:0]
+	for _, c := range d.Clauses {
+		if !remove(c) {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) > 0 && len(kept) < len(d.Clauses) && !kept[len(kept)-1].endsInComment {
+		k := &kept[len(kept)-1]
+		k.Text = strings.TrimRight(k.Text, " \t\r\n") + trailingSpace(last.Text)
+	}
+	d.Clauses = kept
+}
+
+// trailingSpace returns the whitespace at the end of s
+func trailingSpace(s string) string {
+	return s[len(strings.TrimRight(s, " \t\r\n")):]
+}
+
+// firstKeyColumn returns the first column of a key clause's column list
+func (c ddlClause) firstKeyColumn() string {
+	if columns := c.keyColumns(); len(columns) > 0 {
+		return columns[0]
+	}
+	return ""
+}
+
+// keyColumns returns the columns of a key clause's column list, without prefix lengths or
+// ordering; functional key parts are left out
+func (c ddlClause) keyColumns() []string {
+	var columns []string
+	depth := 0
+	expectColumn := false
+	for _, t := range c.tokens {
+		switch {
+		case t.isPunct(c.Text, '('):
+			depth++
+			if depth == 1 {
+				expectColumn = true
+				continue
+			}
+		case t.isPunct(c.Text, ')'):
+			depth--
+			if depth == 0 {
+				return columns
+			}
+		case t.isPunct(c.Text, ',') && depth == 1:
+			expectColumn = true
+			continue
+		case t.Kind == tokSpace || t.Kind == tokComment:
+			continue
+		case expectColumn && depth == 1 && t.isIdent():
+			columns = append(columns, t.Value)
+		}
+		expectColumn = false
+	}
+	return columns
+}
+
+// hasKeyword reports whether the clause contains the given unquoted keyword outside strings and comments
+func (c ddlClause) hasKeyword(keyword string) bool {
+	for _, t := range c.tokens {
+		if t.isKeyword(keyword) {
+			return true
+		}
+	}
+	return false
+}
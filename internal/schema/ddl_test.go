@@ -0,0 +1,214 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Real-world CREATE TABLE statements, used as unit test inputs and fuzz seeds
+var (
+	mysqlOrdersDDL = "CREATE TABLE `orders` (\n" +
+		"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` bigint unsigned DEFAULT NULL,\n" +
+		"  `status` enum('new','paid, shipped','void (refunded)') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'new',\n" +
+		"  `a,b` decimal(10,2) NOT NULL COMMENT 'total (incl. tax), in cents',\n" +
+		"  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `orders_ref_unique` (`user_id`,`a,b`),\n" +
+		"  KEY `orders_status_index` (`status`(10)),\n" +
+		"  FULLTEXT KEY `orders_search` (`status`),\n" +
+		"  KEY `orders_expr` ((cast(`created_at` as date))),\n" +
+		"  CONSTRAINT `orders_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE CASCADE,\n" +
+		"  CONSTRAINT `orders_self` FOREIGN KEY (`id`) REFERENCES `other_db`.`orders, old` (`id`),\n" +
+		"  CONSTRAINT `orders_chk_1` CHECK (((`a,b` > 0) and (`status` <> _utf8mb4'x)')))\n" +
+		") ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='orders (legacy)'"
+
+	postgresItemsDDL = "CREATE TABLE IF NOT EXISTS \"public\".\"items\" (\n" +
+		"    id bigint NOT NULL, -- surrogate key, see ADR (12)\n" +
+		"    \"key\" text DEFAULT 'a,b' NOT NULL,\n" +
+		"    body text DEFAULT $$it's (fine), really$$,\n" +
+		"    /* parent, if any ( */ parent_id bigint,\n" +
+		"    CONSTRAINT items_pkey PRIMARY KEY (id),\n" +
+		"    CONSTRAINT items_key_unique UNIQUE (\"key\"),\n" +
+		"    CONSTRAINT items_parent_fk FOREIGN KEY (parent_id) REFERENCES public.items(id) ON DELETE SET NULL ON UPDATE CASCADE DEFERRABLE,\n" +
+		"    CHECK (length(\"key\") > 0 AND body <> E'\\')')\n" +
+		") PARTITION BY RANGE (id)"
+)
+
+func TestParseCreateTable_MySQL(t *testing.T) {
+	ddl, err := parseCreateTable(mysqlOrdersDDL, mysqlSyntax(""))
+	if err != nil {
+		t.Fatalf("parseCreateTable() error = %v", err)
+	}
+	if got := ddl.String(); got != mysqlOrdersDDL {
+		t.Errorf("String() = %q, want the input back", got)
+	}
+
+	type clause struct {
+		Kind ddlClauseKind
+		Name string
+	}
+	var got []clause
+	for _, c := range ddl.Clauses {
+		got = append(got, clause{c.Kind, c.Name})
+	}
+	want := []clause{
+		{clauseColumn, "id"},
+		{clauseColumn, "user_id"},
+		{clauseColumn, "status"},
+		{clauseColumn, "a,b"},
+		{clauseColumn, "created_at"},
+		{clausePrimaryKey, ""},
+		{clauseKey, "orders_ref_unique"},
+		{clauseKey, "orders_status_index"},
+		{clauseKey, "orders_search"},
+		{clauseKey, "orders_expr"},
+		{clauseForeignKey, "orders_user_id_foreign"},
+		{clauseForeignKey, "orders_self"},
+		{clauseCheck, "orders_chk_1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clauses = %v, want %v", got, want)
+	}
+	if !strings.HasPrefix(ddl.Suffix, ") ENGINE=InnoDB") {
+		t.Errorf("Suffix = %q, want the table options", ddl.Suffix)
+	}
+}
+
+func TestParseCreateTable_Postgres(t *testing.T) {
+	ddl, err := parseCreateTable(postgresItemsDDL, postgresSyntax)
+	if err != nil {
+		t.Fatalf("parseCreateTable() error = %v", err)
+	}
+	if got := ddl.String(); got != postgresItemsDDL {
+		t.Errorf("String() = %q, want the input back", got)
+	}
+
+	var kinds []ddlClauseKind
+	var names []string
+	for _, c := range ddl.Clauses {
+		kinds = append(kinds, c.Kind)
+		names = append(names, c.Name)
+	}
+	wantKinds := []ddlClauseKind{clauseColumn, clauseColumn, clauseColumn, clauseColumn, clausePrimaryKey, clauseKey, clauseForeignKey, clauseCheck}
+	wantNames := []string{"id", "key", "body", "parent_id", "items_pkey", "items_key_unique", "items_parent_fk", ""}
+	if !reflect.DeepEqual(kinds, wantKinds) || !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("clauses = %v %v, want %v %v", kinds, names, wantKinds, wantNames)
+	}
+}
+
+func TestParseCreateTable_NoDefinitionList(t *testing.T) {
+	tests := []string{
+		"CREATE TABLE public.items_2024 PARTITION OF public.items FOR VALUES FROM (0) TO (100)",
+		"CREATE TABLE copy AS SELECT * FROM items",
+	}
+	for _, stmt := range tests {
+		ddl, err := parseCreateTable(stmt, postgresSyntax)
+		if err != nil {
+			t.Errorf("parseCreateTable(%q) error = %v", stmt, err)
+			continue
+		}
+		if len(ddl.Clauses) != 0 || ddl.String() != stmt {
+			t.Errorf("parseCreateTable(%q) = %+v, want no clauses", stmt, ddl)
+		}
+	}
+
+	// A partition can add its own constraints
+	ddl, err := parseCreateTable("CREATE TABLE p1 PARTITION OF items (CONSTRAINT p1_chk CHECK (id > 0)) FOR VALUES IN (1)", postgresSyntax)
+	if err != nil || len(ddl.Clauses) != 1 || ddl.Clauses[0].Kind != clauseCheck {
+		t.Errorf("parseCreateTable(PARTITION OF with constraints) = %+v, %v", ddl, err)
+	}
+
+	for _, stmt := range []string{"DROP TABLE t", "CREATE TABLE t (id int", "CREATE TABLE (id int)"} {
+		if _, err := parseCreateTable(stmt, mysqlSyntax("")); err == nil {
+			t.Errorf("parseCreateTable(%q) expected error", stmt)
+		}
+	}
+}
+
+func TestRemoveForeignKeysFromCreateTable_Layout(t *testing.T) {
+	got := removeForeignKeysFromCreateTable(mysqlOrdersDDL)
+	if strings.Contains(got, "FOREIGN KEY") {
+		t.Fatalf("removeForeignKeysFromCreateTable() still contains a foreign key: %q", got)
+	}
+	// The CHECK constraint after the foreign keys and everything before them are untouched
+	want := strings.Replace(mysqlOrdersDDL,
+		"  CONSTRAINT `orders_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE CASCADE,\n"+
+			"  CONSTRAINT `orders_self` FOREIGN KEY (`id`) REFERENCES `other_db`.`orders, old` (`id`),\n", "", 1)
+	if got != want {
+		t.Errorf("removeForeignKeysFromCreateTable() = %q, want %q", got, want)
+	}
+
+	// Dropping the last clause moves the closing parenthesis's line break to the new last clause
+	stmt := "CREATE TABLE `t` (\n  `id` int NOT NULL,\n  CONSTRAINT `fk` FOREIGN KEY (`id`) REFERENCES `u` (`id`)\n) ENGINE=InnoDB"
+	want = "CREATE TABLE `t` (\n  `id` int NOT NULL\n) ENGINE=InnoDB"
+	if got := removeForeignKeysFromCreateTable(stmt); got != want {
+		t.Errorf("removeForeignKeysFromCreateTable() = %q, want %q", got, want)
+	}
+}
+
+func TestAutoIncrementColumn(t *testing.T) {
+	tests := []struct {
+		stmt            string
+		column          string
+		leadsPrimaryKey bool
+	}{
+		{mysqlOrdersDDL, "id", true},
+		{"CREATE TABLE `t` (\n  `tenant` int NOT NULL,\n  `seq` int NOT NULL AUTO_INCREMENT,\n  `note` varchar(20) DEFAULT 'AUTO_INCREMENT',\n  PRIMARY KEY (`tenant`,`seq`),\n  KEY `seq` (`seq`)\n)", "seq", false},
+		{"CREATE TABLE `t` (\n  `note` varchar(20) DEFAULT 'AUTO_INCREMENT'\n)", "", false},
+	}
+	for _, tt := range tests {
+		column, leads := autoIncrementColumn(tt.stmt)
+		if column != tt.column || leads != tt.leadsPrimaryKey {
+			t.Errorf("autoIncrementColumn(%q) = %q, %v, want %q, %v", tt.stmt, column, leads, tt.column, tt.leadsPrimaryKey)
+		}
+	}
+}
+
+func FuzzParseCreateTable(f *testing.F) {
+	f.Add(mysqlOrdersDDL, false)
+	f.Add(postgresItemsDDL, true)
+	f.Add("CREATE TABLE `t` (`id` int, `fk` int, CONSTRAINT `x` FOREIGN KEY (`fk`) REFERENCES `t` (`id`) ON DELETE CASCADE)", false)
+	f.Add("CREATE TABLE t (a int # trailing, comment\n, CONSTRAINT f FOREIGN KEY (a) REFERENCES u (a))", false)
+	f.Add("CREATE TABLE t (a int /*!80023 INVISIBLE */, KEY k (a) /*!50100 COMMENT 'x' */)", false)
+	f.Add("CREATE TABLE t (a text DEFAULT $x$),($x$, FOREIGN KEY (a) REFERENCES u)", true)
+
+	f.Fuzz(func(t *testing.T, stmt string, postgres bool) {
+		syntax := mysqlSyntax("")
+		if postgres {
+			syntax = postgresSyntax
+		}
+		ddl, err := parseCreateTable(stmt, syntax)
+		if err != nil {
+			return
+		}
+		if got := ddl.String(); got != stmt {
+			t.Fatalf("String() = %q, want %q", got, stmt)
+		}
+
+		kept := 0
+		for _, c := range ddl.Clauses {
+			if c.Kind != clauseForeignKey {
+				kept++
+			}
+		}
+		if kept == 0 || kept == len(ddl.Clauses) {
+			return
+		}
+		ddl.removeClauses(func(c ddlClause) bool { return c.Kind == clauseForeignKey })
+		removed := ddl.String()
+		again, err := parseCreateTable(removed, syntax)
+		if err != nil {
+			t.Fatalf("parseCreateTable(%q) after removing foreign keys: %v", removed, err)
+		}
+		if len(again.Clauses) != kept {
+			t.Fatalf("parseCreateTable(%q) found %d clauses, want %d", removed, len(again.Clauses), kept)
+		}
+		for _, c := range again.Clauses {
+			if c.Kind == clauseForeignKey {
+				t.Fatalf("parseCreateTable(%q) still has foreign key %q", removed, c.Text)
+			}
+		}
+	})
+}
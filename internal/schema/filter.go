@@ -0,0 +1,267 @@
+package schema
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// TableFilter picks the tables a migration creates, and the ones it creates without their rows
+// Patterns are globs such as pulse_* and match a table's name or its schema-qualified name
+type TableFilter struct {
+	Include    []string // Only tables matching one of these (every table when empty)
+	Exclude    []string // Never tables matching these
+	SchemaOnly []string // Tables created empty, such as sessions and cache
+}
+
+// ParseTableFilter checks the patterns of --include, --exclude and --schema-only-tables
+func ParseTableFilter(include, exclude, schemaOnly []string) (TableFilter, error) {
+	flags := []struct {
+		name     string
+		patterns []string
+	}{{"--include", include}, {"--exclude", exclude}, {"--schema-only-tables", schemaOnly}}
+	for _, f := range flags {
+		for _, p := range f.patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return TableFilter{}, fmt.Errorf("invalid %s pattern %q: %w", f.name, p, err)
+			}
+		}
+	}
+	return TableFilter{Include: include, Exclude: exclude, SchemaOnly: schemaOnly}, nil
+}
+
+// Enabled reports whether the filter leaves out or empties any table
+func (f TableFilter) Enabled() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0 || len(f.SchemaOnly) > 0
+}
+
+// Allows reports whether a table is migrated at all
+func (f TableFilter) Allows(schema, name string) bool {
+	if matchesTable(f.Exclude, schema, name) {
+		return false
+	}
+	return len(f.Include) == 0 || matchesTable(f.Include, schema, name)
+}
+
+// IsSchemaOnly reports whether a table is created without its rows
+func (f TableFilter) IsSchemaOnly(schema, name string) bool {
+	return matchesTable(f.SchemaOnly, schema, name)
+}
+
+// matchesTable reports whether any pattern matches a table's name or schema-qualified name
+func matchesTable(patterns []string, schema, name string) bool {
+	for _, p := range patterns {
+		if globMatch(p, name) || (schema != "" && globMatch(p, qualifiedName(schema, name))) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilteredTables is what's left of the extracted tables once a TableFilter is applied
+type FilteredTables struct {
+	Tables             []TableSchema // Tables to create, with SchemaOnly set on those created empty
+	Excluded           []string      // Schema-qualified names of the tables left out
+	DroppedForeignKeys []string      // Foreign keys left out because they reference a table without rows
+}
+
+// FilterTables applies the filter to extracted tables
+// Partitions and inheritance children follow their parents, and foreign keys referencing an
+// excluded or schema-only table are left out, since the rows they point at aren't copied
+func (f TableFilter) FilterTables(tables []TableSchema) FilteredTables {
+	byName := make(map[string]TableSchema, len(tables))
+	for _, t := range tables {
+		byName[t.QualifiedName()] = t
+	}
+
+	// Statuses are worked out parents first, whatever order the tables come in
+	const (
+		keep = iota + 1
+		empty
+		exclude
+	)
+	status := make(map[string]int, len(tables))
+	var resolve func(t TableSchema) int
+	resolve = func(t TableSchema) int {
+		name := t.QualifiedName()
+		if s, ok := status[name]; ok {
+			return s
+		}
+		status[name] = keep // Guards against cycles
+		s := keep
+		switch {
+		case !f.Allows(t.Schema, t.Name):
+			s = exclude
+		case f.IsSchemaOnly(t.Schema, t.Name):
+			s = empty
+		}
+		for _, parent := range t.Parents() {
+			if p, ok := byName[parent]; ok {
+				if ps := resolve(p); ps > s {
+					s = ps
+				}
+			}
+		}
+		status[name] = s
+		return s
+	}
+
+	var result FilteredTables
+	for _, t := range tables {
+		switch resolve(t) {
+		case exclude:
+			result.Excluded = append(result.Excluded, t.QualifiedName())
+			continue
+		case empty:
+			t.SchemaOnly = true
+		}
+		if len(t.ForeignKeys) > 0 {
+			var kept []ForeignKeyDef
+			for _, fk := range t.ForeignKeys {
+				ref := qualifiedName(fk.RefSchema, fk.RefTable)
+				switch status[ref] {
+				case exclude:
+					result.DroppedForeignKeys = append(result.DroppedForeignKeys,
+						fmt.Sprintf("%s on %s references %s, which is excluded", fk.Name, t.QualifiedName(), ref))
+					continue
+				case empty:
+					if !t.SchemaOnly {
+						result.DroppedForeignKeys = append(result.DroppedForeignKeys,
+							fmt.Sprintf("%s on %s references %s, which is created empty", fk.Name, t.QualifiedName(), ref))
+						continue
+					}
+				}
+				kept = append(kept, fk)
+			}
+			t.ForeignKeys = kept
+		}
+		result.Tables = append(result.Tables, t)
+	}
+	return result
+}
+
+// FilterSequences leaves out the sequences owned by tables the filter excludes
+func (f TableFilter) FilterSequences(sequences []SequenceDef, excluded []string) []SequenceDef {
+	if len(excluded) == 0 {
+		return sequences
+	}
+	var kept []SequenceDef
+	for _, seq := range sequences {
+		if owner := ownerTable(seq.OwnedBy); owner != "" && containsString(excluded, owner) {
+			continue
+		}
+		kept = append(kept, seq)
+	}
+	return kept
+}
+
+// FilterViews leaves out the views that read a table the filter excludes, directly or through
+// another view left out, and describes each one it left out
+// Views come in dependency order, as the extractors return them
+func (f TableFilter) FilterViews(views []ViewDef, excluded []string) ([]ViewDef, []string) {
+	if len(excluded) == 0 {
+		return views, nil
+	}
+	behind := make(map[string]string, len(excluded)) // Relations left out, to the excluded table behind each
+	for _, name := range excluded {
+		behind[name] = name
+	}
+
+	var kept []ViewDef
+	var skipped []string
+	for _, v := range views {
+		reason := ""
+		for _, dep := range v.Dependencies {
+			table, ok := behind[dep]
+			if !ok {
+				continue
+			}
+			behind[v.QualifiedName()] = table
+			if table == dep {
+				reason = fmt.Sprintf("view %s reads %s, which is excluded", v.QualifiedName(), table)
+			} else {
+				reason = fmt.Sprintf("view %s reads %s, which reads the excluded table %s", v.QualifiedName(), dep, table)
+			}
+			break
+		}
+		if reason != "" {
+			skipped = append(skipped, reason)
+			continue
+		}
+		kept = append(kept, v)
+	}
+	return kept, skipped
+}
+
+// FilterTriggers leaves out the triggers on tables the filter excludes, and the ones whose
+// statement names an excluded table, describing each of the latter
+// Only triggers without a schema (MySQL, MariaDB and SQLite) carry their body in the statement;
+// a PostgreSQL trigger's statement names nothing but its table and function
+func (f TableFilter) FilterTriggers(triggers []TriggerDef, excluded []string) ([]TriggerDef, []string) {
+	if len(excluded) == 0 {
+		return triggers, nil
+	}
+	var kept []TriggerDef
+	var skipped []string
+	for _, t := range triggers {
+		table := qualifiedName(t.Schema, t.Table)
+		if containsString(excluded, table) {
+			continue
+		}
+		reason := ""
+		if t.Schema == "" {
+			for _, ref := range tableReferences(t.CreateStmt, t.SQLMode) {
+				if containsString(excluded, ref) {
+					reason = fmt.Sprintf("trigger %s on %s uses %s, which is excluded", t.Name, table, ref)
+					break
+				}
+			}
+		}
+		if reason != "" {
+			skipped = append(skipped, reason)
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept, skipped
+}
+
+// tableReferences lists the tables a MySQL statement names in a table's position: after FROM,
+// JOIN, INTO, UPDATE or TABLE, and in the comma-separated tables of a FROM or UPDATE
+// Database qualifiers are dropped
+func tableReferences(text, sqlMode string) []string {
+	tokens := significantTokens(tokenizeMySQL(text, sqlMode))
+	var refs []string
+	tableList := false
+	for i, t := range tokens {
+		keyword := ""
+		if t.Kind == tokIdent {
+			keyword = strings.ToUpper(t.Value)
+		}
+		switch {
+		case keyword == "FROM" || keyword == "UPDATE":
+			tableList = true
+		case tableListEnds[keyword], t.isPunct(text, ')'), t.isPunct(text, ';'):
+			tableList = false
+		}
+		inContext := databaseContextKeywords[keyword] && keyword != "CALL"
+		listed := tableList && t.isPunct(text, ',')
+		if (!inContext && !listed) || i+1 >= len(tokens) || !tokens[i+1].isIdent() {
+			continue
+		}
+		name := i + 1
+		for name+2 < len(tokens) && tokens[name+1].isPunct(text, '.') && tokens[name+2].isIdent() {
+			name += 2
+		}
+		refs = append(refs, tokens[name].Value)
+	}
+	return refs
+}
+
+// ownerTable returns the schema.table part of a sequence's schema.table.column owner
+func ownerTable(ownedBy string) string {
+	if i := strings.LastIndex(ownedBy, "."); i >= 0 {
+		return ownedBy[:i]
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTableFilter_Invalid(t *testing.T) {
+	if _, err := ParseTableFilter(nil, []string{"[cache"}, nil); err == nil {
+		t.Error("ParseTableFilter() error = nil, want an invalid pattern error")
+	}
+	f, err := ParseTableFilter(nil, nil, nil)
+	if err != nil || f.Enabled() {
+		t.Errorf("ParseTableFilter() = %+v, %v, want a disabled filter", f, err)
+	}
+}
+
+func TestTableFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  TableFilter
+		schema  string
+		table   string
+		allowed bool
+	}{
+		{"no filter", TableFilter{}, "", "users", true},
+		{"excluded glob", TableFilter{Exclude: []string{"pulse_*"}}, "", "pulse_entries", false},
+		{"not excluded", TableFilter{Exclude: []string{"pulse_*"}}, "", "users", true},
+		{"included", TableFilter{Include: []string{"users", "orders*"}}, "", "order_items", false},
+		{"included glob", TableFilter{Include: []string{"users", "orders*"}}, "", "orders_archive", true},
+		{"exclude wins", TableFilter{Include: []string{"*"}, Exclude: []string{"jobs"}}, "", "jobs", false},
+		{"qualified", TableFilter{Exclude: []string{"audit.*"}}, "audit", "events", false},
+		{"other schema", TableFilter{Exclude: []string{"audit.*"}}, "public", "events", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Allows(tt.schema, tt.table); got != tt.allowed {
+				t.Errorf("Allows(%q, %q) = %v, want %v", tt.schema, tt.table, got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestTableFilter_FilterTables(t *testing.T) {
+	filter := TableFilter{Exclude: []string{"telescope_*"}, SchemaOnly: []string{"sessions", "cache*"}}
+	tables := []TableSchema{
+		{Name: "users"},
+		{Name: "sessions", ForeignKeys: []ForeignKeyDef{{Name: "sessions_user_id_foreign", RefTable: "users"}}},
+		{Name: "cache"},
+		{Name: "telescope_entries"},
+		{Name: "telescope_entries_tags", ForeignKeys: []ForeignKeyDef{{Name: "telescope_entries_tags_entry_uuid_foreign", RefTable: "telescope_entries"}}},
+		{Name: "logins", ForeignKeys: []ForeignKeyDef{
+			{Name: "logins_user_id_foreign", RefTable: "users"},
+			{Name: "logins_session_id_foreign", RefTable: "sessions"},
+			{Name: "logins_entry_id_foreign", RefTable: "telescope_entries"},
+		}},
+	}
+
+	got := filter.FilterTables(tables)
+
+	if want := []string{"telescope_entries", "telescope_entries_tags"}; !reflect.DeepEqual(got.Excluded, want) {
+		t.Errorf("Excluded = %v, want %v", got.Excluded, want)
+	}
+	var names, empty []string
+	for _, table := range got.Tables {
+		names = append(names, table.Name)
+		if table.SchemaOnly {
+			empty = append(empty, table.Name)
+		}
+	}
+	if want := []string{"users", "sessions", "cache", "logins"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Tables = %v, want %v", names, want)
+	}
+	if want := []string{"sessions", "cache"}; !reflect.DeepEqual(empty, want) {
+		t.Errorf("schema-only tables = %v, want %v", empty, want)
+	}
+	if fks := got.Tables[1].ForeignKeys; len(fks) != 1 {
+		t.Errorf("sessions foreign keys = %v, want its users foreign key kept", fks)
+	}
+	if fks := got.Tables[3].ForeignKeys; len(fks) != 1 || fks[0].Name != "logins_user_id_foreign" {
+		t.Errorf("logins foreign keys = %v, want only logins_user_id_foreign", fks)
+	}
+	want := []string{
+		"logins_session_id_foreign on logins references sessions, which is created empty",
+		"logins_entry_id_foreign on logins references telescope_entries, which is excluded",
+	}
+	if !reflect.DeepEqual(got.DroppedForeignKeys, want) {
+		t.Errorf("DroppedForeignKeys = %v, want %v", got.DroppedForeignKeys, want)
+	}
+}
+
+func TestTableFilter_FilterTables_Partitions(t *testing.T) {
+	filter := TableFilter{Exclude: []string{"public.measurements"}}
+	got := filter.FilterTables([]TableSchema{
+		{Schema: "public", Name: "measurements_2024", PartitionOf: "public.measurements"},
+		{Schema: "public", Name: "measurements", PartitionKey: "RANGE (logged_at)"},
+		{Schema: "public", Name: "users"},
+	})
+	if want := []string{"public.measurements_2024", "public.measurements"}; !reflect.DeepEqual(got.Excluded, want) {
+		t.Errorf("Excluded = %v, want %v", got.Excluded, want)
+	}
+	if len(got.Tables) != 1 || got.Tables[0].Name != "users" {
+		t.Errorf("Tables = %v, want only users", got.Tables)
+	}
+}
+
+func TestTableFilter_FilterSequencesAndTriggers(t *testing.T) {
+	var filter TableFilter
+	excluded := []string{"public.jobs"}
+
+	seqs := filter.FilterSequences([]SequenceDef{
+		{Schema: "public", Name: "jobs_id_seq", OwnedBy: "public.jobs.id"},
+		{Schema: "public", Name: "users_id_seq", OwnedBy: "public.users.id"},
+		{Schema: "public", Name: "invoice_numbers"},
+	}, excluded)
+	if len(seqs) != 2 || seqs[0].Name != "users_id_seq" {
+		t.Errorf("FilterSequences() = %v, want users_id_seq and invoice_numbers", seqs)
+	}
+
+	triggers, skipped := filter.FilterTriggers([]TriggerDef{
+		{Schema: "public", Table: "jobs", Name: "jobs_touch"},
+		{Schema: "public", Table: "users", Name: "users_touch"},
+	}, excluded)
+	if len(triggers) != 1 || triggers[0].Name != "users_touch" || len(skipped) != 0 {
+		t.Errorf("FilterTriggers() = %v, %v, want users_touch", triggers, skipped)
+	}
+}
+
+func TestTableFilter_FilterViews(t *testing.T) {
+	var filter TableFilter
+	excluded := []string{"sessions"}
+
+	views, skipped := filter.FilterViews([]ViewDef{
+		{Name: "active_sessions", Dependencies: []string{"sessions", "users"}},
+		{Name: "user_counts", Dependencies: []string{"users"}},
+		{Name: "session_report", Dependencies: []string{"active_sessions"}},
+	}, excluded)
+	if len(views) != 1 || views[0].Name != "user_counts" {
+		t.Errorf("FilterViews() = %v, want user_counts", views)
+	}
+	want := []string{
+		"view active_sessions reads sessions, which is excluded",
+		"view session_report reads active_sessions, which reads the excluded table sessions",
+	}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("FilterViews() skipped = %q, want %q", skipped, want)
+	}
+}
+
+func TestTableFilter_FilterTriggers_BodyReferences(t *testing.T) {
+	var filter TableFilter
+	excluded := []string{"jobs", "cache"}
+
+	triggers, skipped := filter.FilterTriggers([]TriggerDef{
+		{Table: "jobs", Name: "jobs_touch", CreateStmt: "CREATE TRIGGER `jobs_touch` BEFORE UPDATE ON `jobs` FOR EACH ROW SET NEW.updated_at = NOW()"},
+		{Table: "orders", Name: "orders_queue", CreateStmt: "CREATE TRIGGER `orders_queue` AFTER INSERT ON `orders` FOR EACH ROW INSERT INTO `shop`.`jobs` (payload) VALUES (NEW.id)"},
+		{Table: "users", Name: "users_forget", CreateStmt: "CREATE TRIGGER users_forget AFTER DELETE ON users FOR EACH ROW DELETE FROM audit, cache WHERE cache.user_id = OLD.id"},
+		{Table: "users", Name: "users_audit", CreateStmt: "CREATE TRIGGER users_audit AFTER UPDATE ON users FOR EACH ROW INSERT INTO audit (note) VALUES ('jobs from cache')"},
+	}, excluded)
+	if len(triggers) != 1 || triggers[0].Name != "users_audit" {
+		t.Errorf("FilterTriggers() = %v, want users_audit", triggers)
+	}
+	want := []string{
+		"trigger orders_queue on orders uses jobs, which is excluded",
+		"trigger users_forget on users uses cache, which is excluded",
+	}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("FilterTriggers() skipped = %q, want %q", skipped, want)
+	}
+}
@@ -0,0 +1,731 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Mapping renames tables and columns, and leaves columns out, on the way to the destination
+// Table and column names in the file are the source's; a table can be given as name or
+// schema.name. An explicit table rename wins over the prefixes.
+type Mapping struct {
+	Tables       map[string]string `json:"tables"`        // Source table names to destination names
+	Columns      map[string]string `json:"columns"`       // Source table.column to the column's destination name
+	Omit         []string          `json:"omit"`          // Source table.column left out of the destination
+	RemovePrefix string            `json:"remove_prefix"` // Prefix removed from every table name, such as an old Laravel DB_PREFIX
+	AddPrefix    string            `json:"add_prefix"`    // Prefix added to every table name
+
+	syntax     sqlSyntax
+	byName     map[string]*mappedTable // Every extracted table by source name and qualified source name
+	tables     []*mappedTable          // Every extracted table, in extraction order
+	used       map[string]bool         // Entries of the file that matched a table or column
+	warnings   []string
+	unresolved []string
+}
+
+// mappedTable is how one source table is mapped
+type mappedTable struct {
+	from, to string
+	columns  map[string]string // Renamed columns, source name to destination name
+	omit     map[string]bool
+	source   map[string]bool // Every column of the source table
+}
+
+// column returns the destination name of a source column and whether it's left out
+func (t *mappedTable) column(name string) (string, bool) {
+	if t == nil {
+		return name, false
+	}
+	if t.omit[name] {
+		return name, true
+	}
+	if to, ok := t.columns[name]; ok {
+		return to, false
+	}
+	return name, false
+}
+
+// LoadMapping reads a JSON mapping file, or returns nil when path is empty
+func LoadMapping(path string) (*Mapping, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping file: %w", err)
+	}
+	m, err := ParseMapping(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mapping file %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// ParseMapping validates a JSON mapping file
+func ParseMapping(content []byte) (*Mapping, error) {
+	m := &Mapping{syntax: mysqlSyntax("")}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, err
+	}
+	for from, to := range m.Tables {
+		if to == "" {
+			return nil, fmt.Errorf("table %s has no destination name", from)
+		}
+	}
+	for key, to := range m.Columns {
+		if _, _, ok := splitColumnKey(key); !ok || to == "" {
+			return nil, fmt.Errorf("invalid column rename %q: %q; use \"table.column\": \"new_name\"", key, to)
+		}
+	}
+	for _, key := range m.Omit {
+		if _, _, ok := splitColumnKey(key); !ok {
+			return nil, fmt.Errorf("invalid omitted column %q; use \"table.column\"", key)
+		}
+	}
+	return m, nil
+}
+
+// splitColumnKey splits a table.column entry at its last dot
+func splitColumnKey(key string) (string, string, bool) {
+	dot := strings.LastIndex(key, ".")
+	if dot <= 0 || dot == len(key)-1 {
+		return "", "", false
+	}
+	return key[:dot], key[dot+1:], true
+}
+
+// ForEngine sets the engine whose DDL the mapping rewrites
+func (m *Mapping) ForEngine(engine string) *Mapping {
+	if engine == "pgsql" {
+		m.syntax = postgresSyntax
+	} else {
+		m.syntax = mysqlSyntax("")
+	}
+	return m
+}
+
+// Warnings lists the indexes, constraints and other objects left out because they use an
+// omitted column, and the entries of the mapping that matched nothing
+func (m *Mapping) Warnings() []string {
+	return m.warnings
+}
+
+// Unresolved lists the view and trigger references the mapping couldn't rewrite
+func (m *Mapping) Unresolved() []string {
+	return m.unresolved
+}
+
+// lookup returns how the named source table is mapped, or nil if it isn't an extracted table
+func (m *Mapping) lookup(name string) *mappedTable {
+	return m.byName[name]
+}
+
+// entry returns a file entry for a table by its qualified or plain name, marking it used
+func (m *Mapping) entry(entries map[string]string, qualified, name, suffix string) (string, bool) {
+	for _, key := range []string{qualified + suffix, name + suffix} {
+		if to, ok := entries[key]; ok {
+			m.used[key] = true
+			return to, true
+		}
+	}
+	return "", false
+}
+
+// tableName returns the destination name of a source table
+func (m *Mapping) tableName(qualified, name string) string {
+	if to, ok := m.entry(m.Tables, qualified, name, ""); ok {
+		return to
+	}
+	return m.AddPrefix + strings.TrimPrefix(name, m.RemovePrefix)
+}
+
+// MapTables renames and trims every table, and their indexes and foreign keys
+// Indexes, foreign keys and checks using an omitted column are left out and listed in Warnings;
+// omitting a primary key column is an error.
+func (m *Mapping) MapTables(tables []TableSchema) ([]TableSchema, error) {
+	m.byName = make(map[string]*mappedTable)
+	m.tables = nil
+	m.used = make(map[string]bool)
+	m.warnings, m.unresolved = nil, nil
+
+	taken := make(map[string]string)
+	for _, table := range tables {
+		qualified := table.QualifiedName()
+		mt := &mappedTable{
+			from:    table.Name,
+			to:      m.tableName(qualified, table.Name),
+			columns: make(map[string]string),
+			omit:    make(map[string]bool),
+			source:  make(map[string]bool),
+		}
+		for _, col := range table.Columns {
+			mt.source[col.Name] = true
+			if to, ok := m.entry(m.Columns, qualified, table.Name, "."+col.Name); ok && to != col.Name {
+				mt.columns[col.Name] = to
+			}
+			for _, key := range []string{qualified + "." + col.Name, table.Name + "." + col.Name} {
+				if containsString(m.Omit, key) {
+					m.used[key] = true
+					mt.omit[col.Name] = true
+				}
+			}
+		}
+		dest := qualifiedName(table.Schema, mt.to)
+		if other, ok := taken[dest]; ok {
+			return nil, fmt.Errorf("tables %s and %s would both be named %s", other, qualified, dest)
+		}
+		taken[dest] = qualified
+		m.byName[table.Name] = mt
+		m.byName[qualified] = mt
+		m.tables = append(m.tables, mt)
+	}
+
+	mapped := make([]TableSchema, 0, len(tables))
+	for _, table := range tables {
+		t, err := m.mapTable(table)
+		if err != nil {
+			return nil, err
+		}
+		mapped = append(mapped, t)
+	}
+
+	// Entries that matched nothing are most likely typos
+	var unused []string
+	for from := range m.Tables {
+		if !m.used[from] {
+			unused = append(unused, "table "+from)
+		}
+	}
+	for key := range m.Columns {
+		if !m.used[key] {
+			unused = append(unused, "column "+key)
+		}
+	}
+	for _, key := range m.Omit {
+		if !m.used[key] {
+			unused = append(unused, "omitted column "+key)
+		}
+	}
+	sort.Strings(unused)
+	for _, u := range unused {
+		m.warnings = append(m.warnings, fmt.Sprintf("mapping entry for %s matches nothing on the source", u))
+	}
+	return mapped, nil
+}
+
+// mapTable applies the mapping to one table
+func (m *Mapping) mapTable(table TableSchema) (TableSchema, error) {
+	mt := m.lookup(table.QualifiedName())
+	object := table.QualifiedName()
+
+	for _, col := range table.PrimaryKey {
+		if mt.omit[col] {
+			return table, fmt.Errorf("can't omit %s.%s: it's part of the primary key", object, col)
+		}
+	}
+
+	ddl, err := parseCreateTable(table.CreateStmt, m.syntax)
+	if err != nil {
+		return table, fmt.Errorf("failed to parse CREATE TABLE %s: %w", object, err)
+	}
+	ddl.Prefix, _ = m.renameIdentifiers(ddl.Prefix, mt, false)
+	var kept []ddlClause
+	for _, c := range ddl.Clauses {
+		if c.Kind == clauseColumn && mt.omit[c.Name] {
+			continue
+		}
+		text, omitted := m.renameIdentifiers(c.Text, mt, c.Kind == clauseColumn)
+		if omitted {
+			m.warn(object, "%s left out: it uses an omitted column", strings.TrimSpace(c.Text))
+			continue
+		}
+		c.Text = text
+		kept = append(kept, c)
+	}
+	ddl.Clauses = kept
+	ddl.Suffix, _ = m.renameIdentifiers(ddl.Suffix, mt, false)
+
+	t := table
+	t.CreateStmt = ddl.String()
+	if mt.to != table.Name {
+		t.Name = mt.to
+		t.SourceName = table.SourceTableName()
+	}
+	if len(mt.columns) > 0 {
+		t.ColumnRenames = mt.columns
+	}
+	for col := range mt.omit {
+		t.OmittedColumns = append(t.OmittedColumns, col)
+	}
+	sort.Strings(t.OmittedColumns)
+
+	t.Columns = nil
+	for _, col := range table.Columns {
+		if to, omitted := mt.column(col.Name); !omitted {
+			col.Name = to
+			t.Columns = append(t.Columns, col)
+		}
+	}
+	t.PrimaryKey = m.mapColumns(mt, table.PrimaryKey)
+
+	t.PostCreateStmts = nil
+	for _, stmt := range table.PostCreateStmts {
+		if text, omitted := m.renameIdentifiers(stmt, mt, false); !omitted {
+			t.PostCreateStmts = append(t.PostCreateStmts, text)
+		}
+	}
+
+	t.Indexes = nil
+	for _, ix := range table.Indexes {
+		if m.anyOmitted(mt, ix.Columns) {
+			m.warn(object, "index %s left out: it uses an omitted column", ix.Name)
+			continue
+		}
+		ix.Columns = m.mapColumns(mt, ix.Columns)
+		ix.CreateStmt, _ = m.renameIdentifiers(ix.CreateStmt, mt, false)
+		ix.Definition, _ = m.renameIdentifiers(ix.Definition, mt, false)
+		t.Indexes = append(t.Indexes, ix)
+	}
+
+	t.ForeignKeys = nil
+	for _, fk := range table.ForeignKeys {
+		ref := m.lookup(qualifiedName(fk.RefSchema, fk.RefTable))
+		if ref == nil {
+			ref = m.lookup(fk.RefTable)
+		}
+		if m.anyOmitted(mt, fk.Columns) || m.anyOmitted(ref, fk.RefColumns) {
+			m.warn(object, "foreign key %s left out: it uses an omitted column", fk.Name)
+			continue
+		}
+		fk.Columns = m.mapColumns(mt, fk.Columns)
+		fk.RefColumns = m.mapColumns(ref, fk.RefColumns)
+		if ref != nil {
+			fk.RefTable = ref.to
+		}
+		fk.ConstraintStmt, _ = m.renameIdentifiers(fk.ConstraintStmt, mt, false)
+		t.ForeignKeys = append(t.ForeignKeys, fk)
+	}
+
+	t.PartitionOf = m.mapQualified(table.PartitionOf)
+	if len(table.Inherits) > 0 {
+		t.Inherits = make([]string, len(table.Inherits))
+		for i, parent := range table.Inherits {
+			t.Inherits[i] = m.mapQualified(parent)
+		}
+	}
+	return t, nil
+}
+
+// MapSequences points sequence owners at renamed tables and columns
+// A sequence owned by an omitted column is left without an owner.
+func (m *Mapping) MapSequences(sequences []SequenceDef) []SequenceDef {
+	mapped := make([]SequenceDef, len(sequences))
+	for i, seq := range sequences {
+		if seq.OwnedBy != "" {
+			tableName, column, _ := splitColumnKey(seq.OwnedBy)
+			if mt := m.lookup(tableName); mt != nil {
+				to, omitted := mt.column(column)
+				if omitted {
+					m.warn(seq.QualifiedName(), "sequence left without an owner: %s is omitted", seq.OwnedBy)
+					seq.OwnedBy, seq.OwnedByStmt = "", ""
+				} else {
+					seq.OwnedBy = m.mapQualified(tableName) + "." + to
+					seq.OwnedByStmt, _ = m.renameIdentifiers(seq.OwnedByStmt, mt, false)
+				}
+			}
+		}
+		mapped[i] = seq
+	}
+	return mapped
+}
+
+// mapQualified returns the destination name of a possibly schema-qualified source table
+func (m *Mapping) mapQualified(name string) string {
+	mt := m.lookup(name)
+	if mt == nil {
+		return name
+	}
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		return name[:dot+1] + mt.to
+	}
+	return mt.to
+}
+
+// mapColumns returns the destination names of source columns
+func (m *Mapping) mapColumns(mt *mappedTable, columns []string) []string {
+	if len(columns) == 0 {
+		return columns
+	}
+	mapped := make([]string, len(columns))
+	for i, col := range columns {
+		mapped[i], _ = mt.column(col)
+	}
+	return mapped
+}
+
+// anyOmitted reports whether any of the columns is omitted
+func (m *Mapping) anyOmitted(mt *mappedTable, columns []string) bool {
+	for _, col := range columns {
+		if _, omitted := mt.column(col); omitted {
+			return true
+		}
+	}
+	return false
+}
+
+// warn records an object left out of the destination
+func (m *Mapping) warn(object, format string, args ...interface{}) {
+	m.warnings = append(m.warnings, object+": "+fmt.Sprintf(format, args...))
+}
+
+// renameIdentifiers rewrites the table and column names in a statement or clause about table
+// Outside parentheses, names of extracted tables are renamed. Inside parentheses, names are
+// columns: of table, or of the table after REFERENCES. A name after a table name and a dot, or
+// after COLUMN, is a column of that table; firstIsColumn says the text starts with a column name.
+// It also reports whether the text uses an omitted column.
+func (m *Mapping) renameIdentifiers(text string, table *mappedTable, firstIsColumn bool) (string, bool) {
+	if text == "" {
+		return text, false
+	}
+	tokens := significantTokens(tokenizeSQL(text, m.syntax))
+	e := &ddlEditor{text: text, tokens: tokens}
+	current := table
+	omitted := false
+	depth := 0
+
+	renameColumn := func(i int, mt *mappedTable) {
+		to, isOmitted := mt.column(tokens[i].Value)
+		omitted = omitted || isOmitted
+		if to != tokens[i].Value {
+			e.replace(i, i, m.quote(text, tokens[i], to))
+		}
+	}
+
+	for i, t := range tokens {
+		switch {
+		case t.isPunct(text, '('):
+			depth++
+			continue
+		case t.isPunct(text, ')'):
+			// The table options start with the definition list's closing parenthesis
+			if depth > 0 {
+				depth--
+			}
+			continue
+		case t.isKeyword("REFERENCES"):
+			j := i + 1
+			for j+2 < len(tokens) && tokens[j].isIdent() && tokens[j+1].isPunct(text, '.') && tokens[j+2].isIdent() {
+				j += 2
+			}
+			if j < len(tokens) && tokens[j].isIdent() {
+				current = m.lookup(tokens[j].Value)
+			}
+			continue
+		case !t.isIdent():
+			continue
+		}
+
+		call := i+1 < len(tokens) && tokens[i+1].isPunct(text, '(')
+		qualifier := i+1 < len(tokens) && tokens[i+1].isPunct(text, '.')
+		afterDot := i >= 2 && tokens[i-1].isPunct(text, '.')
+		switch {
+		case afterDot && !qualifier && m.lookup(tokens[i-2].Value) != nil:
+			renameColumn(i, m.lookup(tokens[i-2].Value))
+		case i == 0 && firstIsColumn, i > 0 && tokens[i-1].isKeyword("COLUMN"):
+			renameColumn(i, table)
+		case depth > 0 && !qualifier && !afterDot && !call:
+			renameColumn(i, current)
+		case depth == 0 || qualifier:
+			if mt := m.lookup(t.Value); mt != nil && mt.to != t.Value {
+				e.replace(i, i, m.quote(text, t, mt.to))
+			}
+		}
+	}
+	return e.String(), omitted
+}
+
+// fromClauseEnds are the keywords that end a FROM clause's list of tables
+var fromClauseEnds = map[string]bool{
+	"where": true, "group": true, "order": true, "having": true, "limit": true, "union": true, "except": true,
+	"intersect": true, "window": true, "on": true, "using": true, "for": true, "lock": true, "offset": true,
+	"fetch": true, "select": true,
+}
+
+// joinWords can follow a table reference in a FROM clause, so they're never its alias
+var joinWords = map[string]bool{
+	"join": true, "inner": true, "left": true, "right": true, "full": true, "cross": true, "natural": true,
+	"straight_join": true, "outer": true, "lateral": true, "tablesample": true,
+}
+
+// MapView renames the tables and columns a view's definition refers to
+// Columns are resolved through the tables of its FROM and JOIN clauses and their aliases. A column
+// that's omitted, or that could belong to more than one of the tables, is listed in
+// Unresolved. The view's own column names, after AS, are kept.
+func (m *Mapping) MapView(view ViewDef) ViewDef {
+	text := view.CreateStmt
+	tokens := significantTokens(tokenizeSQL(text, m.syntax))
+	object := "view " + view.QualifiedName()
+
+	// The definition starts after the first AS; before it are the view's name and options
+	body := len(tokens)
+	for i, t := range tokens {
+		if t.isKeyword("AS") {
+			body = i + 1
+			break
+		}
+	}
+
+	// Find the tables of FROM and JOIN clauses, by the names and aliases the view uses for them
+	refs := make(map[string]*mappedTable)
+	var referenced []*mappedTable
+	tableAt := make(map[int]*mappedTable)
+	aliasAt := make(map[int]bool)
+	inFrom := false
+	for i := body; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.isKeyword("FROM"), t.isKeyword("JOIN"):
+			inFrom = true
+			continue
+		case t.Kind == tokIdent && fromClauseEnds[strings.ToLower(t.Value)]:
+			inFrom = false
+			continue
+		}
+		prev := tokens[i-1]
+		startsRef := prev.isKeyword("FROM") || prev.isKeyword("JOIN") || inFrom && (prev.isPunct(text, ',') || prev.isPunct(text, '('))
+		if !startsRef || !t.isIdent() {
+			continue
+		}
+		j := i
+		for j+2 < len(tokens) && tokens[j+1].isPunct(text, '.') && tokens[j+2].isIdent() {
+			j += 2
+		}
+		mt := m.lookup(tokens[j].Value)
+		if mt == nil {
+			continue
+		}
+		tableAt[j] = mt
+		refs[tokens[j].Value] = mt
+		referenced = append(referenced, mt)
+		k := j + 1
+		if k < len(tokens) && tokens[k].isKeyword("AS") {
+			k++
+		}
+		if k < len(tokens) && tokens[k].isIdent() && !(tokens[k].Kind == tokIdent && (fromClauseEnds[strings.ToLower(tokens[k].Value)] || joinWords[strings.ToLower(tokens[k].Value)])) {
+			refs[tokens[k].Value] = mt
+			aliasAt[k] = true
+		}
+		i = j
+	}
+
+	e := &ddlEditor{text: text, tokens: tokens}
+	resolve := func(i int, mt *mappedTable) {
+		to, omitted := mt.column(tokens[i].Value)
+		switch {
+		case omitted:
+			m.unresolve(object, "column %s is omitted from %s", tokens[i].Value, mt.from)
+		case to != tokens[i].Value:
+			e.replace(i, i, m.quote(text, tokens[i], to))
+		}
+	}
+	for i := body; i < len(tokens); i++ {
+		t := tokens[i]
+		if !t.isIdent() || aliasAt[i] || tokens[i-1].isKeyword("AS") {
+			continue
+		}
+		qualifier := i+1 < len(tokens) && tokens[i+1].isPunct(text, '.')
+		afterDot := tokens[i-1].isPunct(text, '.')
+		call := i+1 < len(tokens) && tokens[i+1].isPunct(text, '(')
+		switch {
+		case tableAt[i] != nil:
+			if mt := tableAt[i]; mt.to != t.Value {
+				e.replace(i, i, m.quote(text, t, mt.to))
+			}
+		case qualifier:
+			// A table name qualifying a column, unless it's an alias
+			if mt := refs[t.Value]; mt != nil && mt.from == t.Value && mt.to != t.Value {
+				e.replace(i, i, m.quote(text, t, mt.to))
+			}
+		case afterDot && i >= 2:
+			if mt := refs[tokens[i-2].Value]; mt != nil {
+				resolve(i, mt)
+			}
+		case !call:
+			var owners []*mappedTable
+			for _, mt := range referenced {
+				if mt.source[t.Value] && !containsTable(owners, mt) {
+					owners = append(owners, mt)
+				}
+			}
+			switch {
+			case len(owners) == 1:
+				resolve(i, owners[0])
+			case len(owners) > 1 && m.anyMapped(owners, t.Value):
+				names := make([]string, len(owners))
+				for n, mt := range owners {
+					names[n] = mt.from
+				}
+				m.unresolve(object, "column %s could belong to %s; qualify it and rename it by hand", t.Value, strings.Join(names, " or "))
+			}
+		}
+	}
+	view.CreateStmt = e.String()
+	return view
+}
+
+// MapTrigger points a trigger at its table's destination name, and renames the columns of an
+// UPDATE OF list and those read through NEW and OLD
+// It reports false, listing the trigger in Unresolved, when the trigger uses an omitted column
+// or its body names another renamed table or column, since the body can't be rewritten safely.
+func (m *Mapping) MapTrigger(trigger TriggerDef) (TriggerDef, bool) {
+	table := qualifiedName(trigger.Schema, trigger.Table)
+	mt := m.lookup(table)
+	text := trigger.CreateStmt
+	tokens := significantTokens(tokenizeSQL(text, m.syntax))
+	object := "trigger " + trigger.Name + " on " + table
+
+	// The table follows the first ON; before it, OF starts the list of columns an UPDATE fires on
+	on := len(tokens)
+	for i, t := range tokens {
+		if t.isKeyword("ON") {
+			on = i
+			break
+		}
+	}
+	if on+1 >= len(tokens) {
+		return trigger, true
+	}
+	target := on + 1
+	for target+2 < len(tokens) && tokens[target+1].isPunct(text, '.') && tokens[target+2].isIdent() {
+		target += 2
+	}
+
+	e := &ddlEditor{text: text, tokens: tokens}
+	resolved := true
+	rename := func(i int) {
+		to, omitted := mt.column(tokens[i].Value)
+		switch {
+		case omitted:
+			m.unresolve(object, "column %s is omitted from %s", tokens[i].Value, mt.from)
+			resolved = false
+		case to != tokens[i].Value:
+			e.replace(i, i, m.quote(text, tokens[i], to))
+		}
+	}
+
+	inOf := false
+	for i := 0; i < on; i++ {
+		if tokens[i].isKeyword("OF") {
+			inOf = true
+		} else if inOf && tokens[i].isIdent() {
+			rename(i)
+		}
+	}
+	if mt != nil && mt.to != tokens[target].Value {
+		e.replace(target, target, m.quote(text, tokens[target], mt.to))
+	}
+	for i := target + 1; i < len(tokens); i++ {
+		t := tokens[i]
+		if !t.isIdent() {
+			continue
+		}
+		if (t.isKeyword("NEW") || t.isKeyword("OLD")) && i+2 < len(tokens) && tokens[i+1].isPunct(text, '.') && tokens[i+2].isIdent() {
+			rename(i + 2)
+			i += 2
+			continue
+		}
+		if ref := m.mappedReference(t.Value); ref != "" {
+			m.unresolve(object, "its body uses %s; recreate it by hand", ref)
+			resolved = false
+			break
+		}
+	}
+
+	if !resolved {
+		return trigger, false
+	}
+	if mt != nil {
+		trigger.Table = mt.to
+	}
+	trigger.CreateStmt = e.String()
+	return trigger, true
+}
+
+// mappedReference describes the renamed table, or the renamed or omitted column, a name could
+// refer to, or returns "" when the mapping changes nothing by that name
+func (m *Mapping) mappedReference(name string) string {
+	for _, mt := range m.tables {
+		if mt.from == name && mt.to != name {
+			return fmt.Sprintf("table %s, renamed to %s", name, mt.to)
+		}
+	}
+	for _, mt := range m.tables {
+		switch to, omitted := mt.column(name); {
+		case omitted:
+			return fmt.Sprintf("column %s.%s, which is omitted", mt.from, name)
+		case to != name:
+			return fmt.Sprintf("column %s.%s, renamed to %s", mt.from, name, to)
+		}
+	}
+	return ""
+}
+
+// anyMapped reports whether a column is renamed or omitted in any of the tables
+func (m *Mapping) anyMapped(tables []*mappedTable, column string) bool {
+	for _, mt := range tables {
+		if to, omitted := mt.column(column); omitted || to != column {
+			return true
+		}
+	}
+	return false
+}
+
+// unresolve records a view reference the mapping couldn't rewrite, once
+func (m *Mapping) unresolve(object, format string, args ...interface{}) {
+	ref := object + ": " + fmt.Sprintf(format, args...)
+	if !containsString(m.unresolved, ref) {
+		m.unresolved = append(m.unresolved, ref)
+	}
+}
+
+// containsTable reports whether list holds mt
+func containsTable(list []*mappedTable, mt *mappedTable) bool {
+	for _, item := range list {
+		if item == mt {
+			return true
+		}
+	}
+	return false
+}
+
+// plainIdentRe matches names that need no quoting
+var plainIdentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// quote writes a new name in place of an identifier token, quoted the way the token was
+func (m *Mapping) quote(text string, t sqlToken, name string) string {
+	if t.Kind == tokQuotedIdent {
+		q := text[t.Start : t.Start+1]
+		return q + strings.ReplaceAll(name, q, q+q) + q
+	}
+	if plainIdentRe.MatchString(name) && (m.syntax.backticks || name == strings.ToLower(name)) {
+		return name
+	}
+	if m.syntax.backticks {
+		return quoteMySQLIdent(name)
+	}
+	return quoteIdentifier(name)
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,289 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMapping = `{
+  "tables": {"tbl_users": "users"},
+  "columns": {"tbl_users.fullname": "name", "tbl_posts.author": "user_id"},
+  "omit": ["tbl_users.legacy_flag"],
+  "remove_prefix": "tbl_"
+}`
+
+func testMappedTables() []TableSchema {
+	return []TableSchema{
+		{
+			Name: "tbl_users",
+			CreateStmt: "CREATE TABLE `tbl_users` (\n" +
+				"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+				"  `fullname` varchar(100) NOT NULL,\n" +
+				"  `legacy_flag` tinyint(1) NOT NULL DEFAULT '0',\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB",
+			Columns:    []ColumnDef{{Name: "id"}, {Name: "fullname"}, {Name: "legacy_flag"}},
+			PrimaryKey: []string{"id"},
+			Indexes: []IndexDef{
+				{Name: "tbl_users_fullname_index", Columns: []string{"fullname"}, CreateStmt: "CREATE INDEX `tbl_users_fullname_index` ON `tbl_users` (`fullname`)", Definition: "ADD INDEX `tbl_users_fullname_index` (`fullname`)"},
+				{Name: "tbl_users_legacy_flag_index", Columns: []string{"legacy_flag"}, Definition: "ADD INDEX `tbl_users_legacy_flag_index` (`legacy_flag`)"},
+			},
+		},
+		{
+			Name: "tbl_posts",
+			CreateStmt: "CREATE TABLE `tbl_posts` (\n" +
+				"  `id` int NOT NULL,\n" +
+				"  `author` int NOT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB",
+			Columns:    []ColumnDef{{Name: "id"}, {Name: "author"}},
+			PrimaryKey: []string{"id"},
+			ForeignKeys: []ForeignKeyDef{{
+				Name:           "tbl_posts_author_foreign",
+				Columns:        []string{"author"},
+				RefTable:       "tbl_users",
+				RefColumns:     []string{"id"},
+				ConstraintStmt: "ALTER TABLE `tbl_posts` ADD CONSTRAINT `tbl_posts_author_foreign` FOREIGN KEY (`author`) REFERENCES `tbl_users` (`id`) ON DELETE CASCADE",
+			}},
+		},
+	}
+}
+
+func TestParseMapping_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty table name", `{"tables": {"a": ""}}`},
+		{"column without table", `{"columns": {"name": "full_name"}}`},
+		{"empty column name", `{"columns": {"users.name": ""}}`},
+		{"omit without table", `{"omit": ["legacy"]}`},
+		{"not json", `tables:`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseMapping([]byte(tt.content)); err == nil {
+				t.Error("ParseMapping() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestMapping_MapTables(t *testing.T) {
+	m, err := ParseMapping([]byte(testMapping))
+	if err != nil {
+		t.Fatalf("ParseMapping() error: %v", err)
+	}
+	tables, err := m.ForEngine("mysql").MapTables(testMappedTables())
+	if err != nil {
+		t.Fatalf("MapTables() error: %v", err)
+	}
+
+	users := tables[0]
+	if users.Name != "users" || users.SourceName != "tbl_users" {
+		t.Errorf("Name, SourceName = %q, %q, want users, tbl_users", users.Name, users.SourceName)
+	}
+	wantStmt := "CREATE TABLE `users` (\n" +
+		"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+		"  `name` varchar(100) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB"
+	if users.CreateStmt != wantStmt {
+		t.Errorf("CreateStmt =\n%s\nwant\n%s", users.CreateStmt, wantStmt)
+	}
+	var columns []string
+	for _, c := range users.Columns {
+		columns = append(columns, c.Name)
+	}
+	if !reflect.DeepEqual(columns, []string{"id", "name"}) {
+		t.Errorf("Columns = %v, want [id name]", columns)
+	}
+	if !reflect.DeepEqual(users.OmittedColumns, []string{"legacy_flag"}) || users.DestinationColumn("fullname") != "name" {
+		t.Errorf("OmittedColumns = %v, DestinationColumn(fullname) = %q", users.OmittedColumns, users.DestinationColumn("fullname"))
+	}
+	if len(users.Indexes) != 1 {
+		t.Fatalf("Indexes = %v, want the legacy_flag index left out", users.Indexes)
+	}
+	ix := users.Indexes[0]
+	if ix.CreateStmt != "CREATE INDEX `tbl_users_fullname_index` ON `users` (`name`)" || ix.Definition != "ADD INDEX `tbl_users_fullname_index` (`name`)" {
+		t.Errorf("index = %q / %q", ix.CreateStmt, ix.Definition)
+	}
+
+	// tbl_posts loses the prefix and its foreign key follows both renames
+	posts := tables[1]
+	if posts.Name != "posts" || !strings.Contains(posts.CreateStmt, "`user_id` int NOT NULL") {
+		t.Errorf("posts = %q:\n%s", posts.Name, posts.CreateStmt)
+	}
+	fk := posts.ForeignKeys[0]
+	wantFK := "ALTER TABLE `posts` ADD CONSTRAINT `tbl_posts_author_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE"
+	if fk.ConstraintStmt != wantFK || fk.RefTable != "users" || !reflect.DeepEqual(fk.Columns, []string{"user_id"}) {
+		t.Errorf("foreign key = %+v, want %s", fk, wantFK)
+	}
+
+	if len(m.Warnings()) != 1 || !strings.Contains(m.Warnings()[0], "tbl_users_legacy_flag_index") {
+		t.Errorf("Warnings() = %v, want the left out index", m.Warnings())
+	}
+}
+
+func TestMapping_MapTables_Errors(t *testing.T) {
+	m, _ := ParseMapping([]byte(`{"omit": ["tbl_users.id"]}`))
+	if _, err := m.MapTables(testMappedTables()); err == nil || !strings.Contains(err.Error(), "primary key") {
+		t.Errorf("MapTables() error = %v, want a primary key error", err)
+	}
+
+	m, _ = ParseMapping([]byte(`{"tables": {"tbl_posts": "tbl_users"}}`))
+	if _, err := m.MapTables(testMappedTables()); err == nil {
+		t.Error("MapTables() with two tables named the same should fail")
+	}
+}
+
+func TestMapping_MapTables_UnusedEntries(t *testing.T) {
+	m, _ := ParseMapping([]byte(`{"tables": {"tbl_user": "users"}, "add_prefix": "app_"}`))
+	tables, err := m.MapTables(testMappedTables())
+	if err != nil {
+		t.Fatalf("MapTables() error: %v", err)
+	}
+	if tables[0].Name != "app_tbl_users" {
+		t.Errorf("Name = %q, want app_tbl_users", tables[0].Name)
+	}
+	if want := []string{"mapping entry for table tbl_user matches nothing on the source"}; !reflect.DeepEqual(m.Warnings(), want) {
+		t.Errorf("Warnings() = %v, want %v", m.Warnings(), want)
+	}
+}
+
+func TestMapping_MapView(t *testing.T) {
+	m, _ := ParseMapping([]byte(testMapping))
+	if _, err := m.MapTables(testMappedTables()); err != nil {
+		t.Fatalf("MapTables() error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		stmt   string
+		want   string
+		issues int
+	}{
+		{
+			"qualified",
+			"CREATE VIEW `v` AS select `tbl_users`.`id` AS `id`,`tbl_users`.`fullname` AS `fullname` from `tbl_users`",
+			"CREATE VIEW `v` AS select `users`.`id` AS `id`,`users`.`name` AS `fullname` from `users`",
+			0,
+		},
+		{
+			"aliases",
+			"CREATE VIEW `v` AS select `u`.`fullname` AS `n`,`p`.`id` AS `post` from (`tbl_users` `u` join `tbl_posts` `p` on((`p`.`author` = `u`.`id`)))",
+			"CREATE VIEW `v` AS select `u`.`name` AS `n`,`p`.`id` AS `post` from (`users` `u` join `posts` `p` on((`p`.`user_id` = `u`.`id`)))",
+			0,
+		},
+		{
+			"unqualified",
+			"CREATE VIEW v AS SELECT fullname, count(*) FROM tbl_users GROUP BY fullname",
+			"CREATE VIEW v AS SELECT name, count(*) FROM users GROUP BY name",
+			0,
+		},
+		{
+			"omitted",
+			"CREATE VIEW v AS SELECT legacy_flag FROM tbl_users",
+			"CREATE VIEW v AS SELECT legacy_flag FROM users",
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(m.Unresolved())
+			got := m.MapView(ViewDef{Name: "v", CreateStmt: tt.stmt})
+			if got.CreateStmt != tt.want {
+				t.Errorf("MapView() =\n%s\nwant\n%s", got.CreateStmt, tt.want)
+			}
+			if issues := len(m.Unresolved()) - before; issues != tt.issues {
+				t.Errorf("Unresolved() added %d, want %d: %v", issues, tt.issues, m.Unresolved())
+			}
+		})
+	}
+}
+
+func TestMapping_PostgresTablesAndSequences(t *testing.T) {
+	m, _ := ParseMapping([]byte(`{"tables": {"public.accounts": "users"}, "columns": {"accounts.login": "email"}}`))
+	m.ForEngine("pgsql")
+	tables, err := m.MapTables([]TableSchema{{
+		Schema:          "public",
+		Name:            "accounts",
+		CreateStmt:      "CREATE TABLE public.accounts (\n    id bigint NOT NULL,\n    login text NOT NULL,\n    CONSTRAINT accounts_login_check CHECK ((login <> ''::text))\n)",
+		Columns:         []ColumnDef{{Name: "id"}, {Name: "login"}},
+		PostCreateStmts: []string{"COMMENT ON COLUMN public.accounts.login IS 'sign-in name'"},
+	}})
+	if err != nil {
+		t.Fatalf("MapTables() error: %v", err)
+	}
+	want := "CREATE TABLE public.users (\n    id bigint NOT NULL,\n    email text NOT NULL,\n    CONSTRAINT accounts_login_check CHECK ((email <> ''::text))\n)"
+	if tables[0].CreateStmt != want {
+		t.Errorf("CreateStmt =\n%s\nwant\n%s", tables[0].CreateStmt, want)
+	}
+	if want := "COMMENT ON COLUMN public.users.email IS 'sign-in name'"; tables[0].PostCreateStmts[0] != want {
+		t.Errorf("PostCreateStmts[0] = %q, want %q", tables[0].PostCreateStmts[0], want)
+	}
+
+	seqs := m.MapSequences([]SequenceDef{{
+		Schema:      "public",
+		Name:        "accounts_id_seq",
+		OwnedBy:     "public.accounts.id",
+		OwnedByStmt: `ALTER SEQUENCE "public"."accounts_id_seq" OWNED BY "public"."accounts"."id"`,
+	}})
+	if seqs[0].OwnedBy != "public.users.id" || seqs[0].OwnedByStmt != `ALTER SEQUENCE "public"."accounts_id_seq" OWNED BY "public"."users"."id"` {
+		t.Errorf("sequence = %+v", seqs[0])
+	}
+}
+
+func TestMapping_MapTrigger(t *testing.T) {
+	m, _ := ParseMapping([]byte(`{"tables": {"public.accounts": "users"}, "columns": {"accounts.login": "email"}, "omit": ["accounts.legacy"]}`))
+	m.ForEngine("pgsql")
+	if _, err := m.MapTables([]TableSchema{
+		{
+			Schema:     "public",
+			Name:       "accounts",
+			CreateStmt: "CREATE TABLE public.accounts (\n    id bigint NOT NULL,\n    login text NOT NULL,\n    legacy boolean\n)",
+			Columns:    []ColumnDef{{Name: "id"}, {Name: "login"}, {Name: "legacy"}},
+		},
+		{Schema: "public", Name: "orders", CreateStmt: "CREATE TABLE public.orders (\n    id bigint NOT NULL\n)", Columns: []ColumnDef{{Name: "id"}}},
+	}); err != nil {
+		t.Fatalf("MapTables() error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		trigger TriggerDef
+		want    string
+		ok      bool
+	}{
+		{
+			"renamed table and columns",
+			TriggerDef{Schema: "public", Table: "accounts", Name: "accounts_login", CreateStmt: "CREATE TRIGGER accounts_login BEFORE UPDATE OF login ON public.accounts FOR EACH ROW WHEN ((old.login IS DISTINCT FROM new.login)) EXECUTE FUNCTION public.lower_login()"},
+			"CREATE TRIGGER accounts_login BEFORE UPDATE OF email ON public.users FOR EACH ROW WHEN ((old.email IS DISTINCT FROM new.email)) EXECUTE FUNCTION public.lower_login()",
+			true,
+		},
+		{
+			"omitted column",
+			TriggerDef{Schema: "public", Table: "accounts", Name: "accounts_legacy", CreateStmt: "CREATE TRIGGER accounts_legacy BEFORE UPDATE OF legacy ON public.accounts FOR EACH ROW EXECUTE FUNCTION public.touch()"},
+			"",
+			false,
+		},
+		{
+			"unmapped table",
+			TriggerDef{Schema: "public", Table: "orders", Name: "orders_touch", CreateStmt: "CREATE TRIGGER orders_touch BEFORE UPDATE ON public.orders FOR EACH ROW EXECUTE FUNCTION public.touch()"},
+			"CREATE TRIGGER orders_touch BEFORE UPDATE ON public.orders FOR EACH ROW EXECUTE FUNCTION public.touch()",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.MapTrigger(tt.trigger)
+			if ok != tt.ok {
+				t.Fatalf("MapTrigger() ok = %v, want %v (unresolved %v)", ok, tt.ok, m.Unresolved())
+			}
+			if ok && got.CreateStmt != tt.want {
+				t.Errorf("MapTrigger() =\n%s\nwant\n%s", got.CreateStmt, tt.want)
+			}
+		})
+	}
+}
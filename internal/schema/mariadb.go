@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+)
+
+// MariaDBExtractor extracts schema from MariaDB databases
+// Tables, views, routines and triggers are read as on MySQL; MariaDB adds sequences, and the
+// row start and end columns of system-versioned tables, which the server fills in itself
+type MariaDBExtractor struct {
+	*MySQLExtractor
+}
+
+// NewMariaDBExtractor creates a new MariaDB schema extractor
+func NewMariaDBExtractor() *MariaDBExtractor {
+	e := NewMySQLExtractor()
+	e.Dialect = &dialect.MariaDBDialect{}
+	return &MariaDBExtractor{MySQLExtractor: e}
+}
+
+// ExtractTables extracts all table schemas from the database, marking system-versioning columns
+// so the data copy leaves them to the destination server
+func (e *MariaDBExtractor) ExtractTables(db *sql.DB, database string) ([]TableSchema, error) {
+	tables, err := e.MySQLExtractor.ExtractTables(db, database)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		tables[i].Columns = append(tables[i].Columns, systemTimeColumns(tables[i].CreateStmt)...)
+	}
+	return tables, nil
+}
+
+// systemTimeColumns returns the explicit row start and end columns of a system-versioned table
+// Tables versioned without them get hidden row_start and row_end columns that SELECT * leaves out
+func systemTimeColumns(createStmt string) []ColumnDef {
+	ddl, err := parseCreateTable(createStmt, mysqlSyntax(""))
+	if err != nil {
+		return nil
+	}
+	var columns []ColumnDef
+	for _, c := range ddl.Clauses {
+		if c.Kind != clauseColumn {
+			continue
+		}
+		for i := 0; i+2 < len(c.tokens); i++ {
+			if c.tokens[i].isKeyword("AS") && c.tokens[i+1].isKeyword("ROW") &&
+				(c.tokens[i+2].isKeyword("START") || c.tokens[i+2].isKeyword("END")) {
+				columns = append(columns, ColumnDef{Name: c.Name, Extra: "ROW " + c.tokens[i+2].Value})
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// ExtractSequences extracts MariaDB sequences with the next value each would hand out
+func (e *MariaDBExtractor) ExtractSequences(db *sql.DB, database string) ([]SequenceDef, error) {
+	rows, err := db.Query(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = ? AND table_type = 'SEQUENCE'
+		ORDER BY table_name
+	`, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sequences: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+
+	var sequences []SequenceDef
+	for _, name := range names {
+		seq := SequenceDef{Name: name}
+		var seqName string
+		if err := db.QueryRow(fmt.Sprintf("SHOW CREATE SEQUENCE %s.%s", quoteMySQLIdent(database), quoteMySQLIdent(name))).Scan(&seqName, &seq.CreateStmt); err != nil {
+			return nil, fmt.Errorf("failed to get CREATE SEQUENCE for %s: %w", name, err)
+		}
+		// Values below next_not_cached_value may already be cached by a session, so the
+		// destination starts from it; it hasn't been handed out yet
+		if err := db.QueryRow(fmt.Sprintf("SELECT next_not_cached_value FROM %s.%s", quoteMySQLIdent(database), quoteMySQLIdent(name))).Scan(&seq.CurrentVal); err != nil {
+			return nil, fmt.Errorf("failed to get value of sequence %s: %w", name, err)
+		}
+		sequences = append(sequences, seq)
+	}
+	return sequences, nil
+}
+
+// MariaDBApplier applies schema to MariaDB databases
+type MariaDBApplier struct {
+	*MySQLApplier
+}
+
+// NewMariaDBApplier creates a new MariaDB schema applier
+func NewMariaDBApplier() *MariaDBApplier {
+	a := NewMySQLApplier()
+	a.Dialect = &dialect.MariaDBDialect{}
+	return &MariaDBApplier{MySQLApplier: a}
+}
+
+// CreateTable creates a table, pointing NEXTVAL defaults that SHOW CREATE TABLE qualifies with
+// the source database at the destination database
+func (a *MariaDBApplier) CreateTable(db *sql.DB, table TableSchema) error {
+	if a.sourceDatabase != "" && a.sourceDatabase != a.destDatabase {
+		table.CreateStmt, _ = rewriteDatabaseQualifiers(table.CreateStmt, "", a.sourceDatabase, a.destDatabase)
+	}
+	return a.MySQLApplier.CreateTable(db, table)
+}
+
+// CreateSequence creates a sequence in the database
+func (a *MariaDBApplier) CreateSequence(db *sql.DB, seq SequenceDef) error {
+	if _, err := db.Exec(seq.CreateStmt); err != nil {
+		return fmt.Errorf("failed to create sequence %s: %w", seq.Name, err)
+	}
+	return nil
+}
+
+// SetSequenceValue sets the value a sequence hands out next
+func (a *MariaDBApplier) SetSequenceValue(db *sql.DB, seq SequenceDef) error {
+	used := 0
+	if seq.IsCalled {
+		used = 1
+	}
+	if _, err := db.Exec(fmt.Sprintf("SELECT SETVAL(%s, %d, %d)", quoteMySQLIdent(seq.Name), seq.CurrentVal, used)); err != nil {
+		return fmt.Errorf("failed to set sequence %s: %w", seq.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMariaDBExtractor_ExtractSequences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT table_name.*FROM information_schema.tables.*table_type = 'SEQUENCE'").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("order_seq"))
+	stmt := "CREATE SEQUENCE `order_seq` start with 1 minvalue 1 maxvalue 9223372036854775806 increment by 1 cache 1000 nocycle ENGINE=InnoDB"
+	mock.ExpectQuery("SHOW CREATE SEQUENCE `shop`.`order_seq`").
+		WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow("order_seq", stmt))
+	mock.ExpectQuery("SELECT next_not_cached_value FROM `shop`.`order_seq`").
+		WillReturnRows(sqlmock.NewRows([]string{"next_not_cached_value"}).AddRow(1001))
+
+	sequences, err := NewMariaDBExtractor().ExtractSequences(db, "shop")
+	if err != nil {
+		t.Fatalf("ExtractSequences() error = %v", err)
+	}
+	want := []SequenceDef{{Name: "order_seq", CreateStmt: stmt, CurrentVal: 1001}}
+	if !reflect.DeepEqual(sequences, want) {
+		t.Errorf("ExtractSequences() = %+v, want %+v", sequences, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestSystemTimeColumns(t *testing.T) {
+	stmt := "CREATE TABLE `prices` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `valid_from` timestamp(6) GENERATED ALWAYS AS ROW START INVISIBLE,\n" +
+		"  `valid_to` timestamp(6) GENERATED ALWAYS AS ROW END INVISIBLE,\n" +
+		"  PRIMARY KEY (`id`,`valid_to`),\n" +
+		"  PERIOD FOR SYSTEM_TIME (`valid_from`, `valid_to`)\n" +
+		") ENGINE=InnoDB WITH SYSTEM VERSIONING"
+
+	got := systemTimeColumns(stmt)
+	want := []ColumnDef{{Name: "valid_from", Extra: "ROW START"}, {Name: "valid_to", Extra: "ROW END"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("systemTimeColumns() = %+v, want %+v", got, want)
+	}
+	for _, c := range got {
+		if !c.IsGenerated() {
+			t.Errorf("%s.IsGenerated() = false, want true so the data copy skips it", c.Name)
+		}
+	}
+
+	if got := systemTimeColumns("CREATE TABLE `t` (`id` int(11) NOT NULL) ENGINE=InnoDB"); got != nil {
+		t.Errorf("systemTimeColumns() of an unversioned table = %+v, want nil", got)
+	}
+}
+
+func TestMariaDBApplier_SetSequenceValue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("SELECT SETVAL\\(`order_seq`, 1001, 0\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := NewMariaDBApplier().SetSequenceValue(db, SequenceDef{Name: "order_seq", CurrentVal: 1001}); err != nil {
+		t.Errorf("SetSequenceValue() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
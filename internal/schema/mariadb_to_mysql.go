@@ -0,0 +1,548 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RoutineTranslator is implemented by translators that can rewrite stored routines, events and
+// triggers for the destination engine
+type RoutineTranslator interface {
+	TranslateRoutine(routine RoutineDef) (RoutineDef, error)
+	TranslateTrigger(trigger TriggerDef) (TriggerDef, error)
+}
+
+// mysqlSQLModes are the sql_mode flags MySQL 8 accepts; MariaDB's own flags, and those MySQL 8
+// removed such as NO_AUTO_CREATE_USER, make SET sql_mode fail
+var mysqlSQLModes = map[string]bool{
+	"ALLOW_INVALID_DATES": true, "ANSI_QUOTES": true, "ERROR_FOR_DIVISION_BY_ZERO": true,
+	"HIGH_NOT_PRECEDENCE": true, "IGNORE_SPACE": true, "NO_AUTO_VALUE_ON_ZERO": true,
+	"NO_BACKSLASH_ESCAPES": true, "NO_DIR_IN_CREATE": true, "NO_ENGINE_SUBSTITUTION": true,
+	"NO_UNSIGNED_SUBTRACTION": true, "NO_ZERO_DATE": true, "NO_ZERO_IN_DATE": true,
+	"ONLY_FULL_GROUP_BY": true, "PAD_CHAR_TO_FULL_LENGTH": true, "PIPES_AS_CONCAT": true,
+	"REAL_AS_FLOAT": true, "STRICT_ALL_TABLES": true, "STRICT_TRANS_TABLES": true,
+	"TIME_TRUNCATE_FRACTIONAL": true, "ANSI": true, "TRADITIONAL": true,
+}
+
+// MariaDBToMySQLTranslator rewrites MariaDB tables for MySQL 8
+// MariaDB's DDL is MySQL's but for a few types, defaults, collations, table options and system
+// versioning, so each CREATE TABLE statement is edited in place rather than rebuilt; views,
+// routines and triggers carry over with their collations and sql_mode adjusted
+type MariaDBToMySQLTranslator struct {
+	lossy []LossyConversion
+}
+
+// NewMariaDBToMySQLTranslator creates a MariaDB to MySQL translator
+func NewMariaDBToMySQLTranslator() *MariaDBToMySQLTranslator {
+	return &MariaDBToMySQLTranslator{}
+}
+
+// LossyConversions lists what the last translation couldn't carry over exactly
+func (t *MariaDBToMySQLTranslator) LossyConversions() []LossyConversion {
+	return t.lossy
+}
+
+// report records a lossy conversion
+func (t *MariaDBToMySQLTranslator) report(object, format string, args ...interface{}) {
+	t.lossy = append(t.lossy, LossyConversion{Object: object, Detail: fmt.Sprintf(format, args...)})
+}
+
+// ddlEdit replaces text[start:end] of a clause or of the table options
+type ddlEdit struct {
+	start, end int
+	text       string
+}
+
+// applyDDLEdits applies edits that don't overlap to text
+func applyDDLEdits(text string, edits []ddlEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		b.WriteString(text[last:e.start])
+		b.WriteString(e.text)
+		last = e.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// ddlEditor collects edits to a run of significant tokens from text
+type ddlEditor struct {
+	text   string
+	tokens []sqlToken
+	edits  []ddlEdit
+}
+
+// replace replaces tokens from through to, inclusive
+func (e *ddlEditor) replace(from, to int, text string) {
+	e.edits = append(e.edits, ddlEdit{e.tokens[from].Start, e.tokens[to].End, text})
+}
+
+// remove drops tokens from through to, inclusive, with the whitespace before them
+func (e *ddlEditor) remove(from, to int) {
+	start := e.tokens[from].Start
+	if from > 0 {
+		start = e.tokens[from-1].End
+	}
+	e.edits = append(e.edits, ddlEdit{start, e.tokens[to].End, ""})
+}
+
+// String returns the edited text
+func (e *ddlEditor) String() string {
+	return applyDDLEdits(e.text, e.edits)
+}
+
+// TranslateTables rewrites MariaDB tables for MySQL
+func (t *MariaDBToMySQLTranslator) TranslateTables(db *sql.DB, database string, tables []TableSchema) ([]TableSchema, []TypeDef, error) {
+	t.lossy = nil
+	translated := make([]TableSchema, len(tables))
+	for i, table := range tables {
+		out, err := t.translateTable(table)
+		if err != nil {
+			return nil, nil, err
+		}
+		translated[i] = out
+	}
+	return translated, nil, nil
+}
+
+// translateTable rewrites a table's CREATE TABLE statement
+// Row start and end columns become plain columns, so they're copied like any other
+func (t *MariaDBToMySQLTranslator) translateTable(table TableSchema) (TableSchema, error) {
+	ddl, err := parseCreateTable(table.CreateStmt, mysqlSyntax(""))
+	if err != nil {
+		return table, fmt.Errorf("failed to parse CREATE TABLE %s: %w", table.Name, err)
+	}
+
+	// MariaDB's JSON is LONGTEXT with a json_valid CHECK, inline or at the table level
+	longtext := make(map[string]bool)
+	for _, c := range ddl.Clauses {
+		if c.Kind == clauseColumn && len(c.tokens) > 1 && c.tokens[1].isKeyword("LONGTEXT") {
+			longtext[c.Name] = true
+		}
+	}
+	jsonColumns := make(map[string]bool)
+	for _, c := range ddl.Clauses {
+		for i := range c.tokens {
+			if column, _, ok := jsonValidCheck(c.Text, c.tokens, i); ok && longtext[column] {
+				jsonColumns[column] = true
+			}
+		}
+	}
+
+	collations := make(map[string]bool)
+	for i, c := range ddl.Clauses {
+		if c.Kind == clauseColumn && !isPeriodClause(c) {
+			ddl.Clauses[i].Text = t.translateColumn(table.Name, c, jsonColumns, collations)
+		}
+	}
+	ddl.removeClauses(func(c ddlClause) bool {
+		if column, ok := tableJSONCheck(c); ok {
+			return jsonColumns[column]
+		}
+		if isPeriodClause(c) {
+			t.report(table.Name, "%s has no MySQL equivalent and is dropped", periodName(c))
+			return true
+		}
+		return false
+	})
+	ddl.Suffix = t.translateTableOptions(table.Name, ddl.Suffix, collations)
+
+	out := table
+	out.CreateStmt = ddl.String()
+	out.Columns = nil
+	for _, col := range table.Columns {
+		if col.Extra != "ROW START" && col.Extra != "ROW END" {
+			out.Columns = append(out.Columns, col)
+		}
+	}
+	return out, nil
+}
+
+// isPeriodClause reports whether a clause is PERIOD FOR SYSTEM_TIME (start, end) or an
+// application-time period, which the parser takes for a column named PERIOD
+func isPeriodClause(c ddlClause) bool {
+	return len(c.tokens) > 2 && c.tokens[0].isKeyword("PERIOD") && c.tokens[1].isKeyword("FOR")
+}
+
+// periodName describes a period clause for the migration report
+func periodName(c ddlClause) string {
+	if c.tokens[2].isKeyword("SYSTEM_TIME") {
+		return "PERIOD FOR SYSTEM_TIME"
+	}
+	return "application-time period " + c.tokens[2].Value
+}
+
+// jsonValidCheck matches CHECK (json_valid(column)) at tokens[i], returning the column and the
+// index of the closing parenthesis
+func jsonValidCheck(text string, tokens []sqlToken, i int) (string, int, bool) {
+	if i+6 >= len(tokens) || !tokens[i].isKeyword("CHECK") {
+		return "", 0, false
+	}
+	ok := tokens[i+1].isPunct(text, '(') && tokens[i+2].isKeyword("json_valid") &&
+		tokens[i+3].isPunct(text, '(') && tokens[i+4].isIdent() &&
+		tokens[i+5].isPunct(text, ')') && tokens[i+6].isPunct(text, ')')
+	if !ok {
+		return "", 0, false
+	}
+	return tokens[i+4].Value, i + 6, true
+}
+
+// tableJSONCheck matches a table-level CHECK (json_valid(column)) constraint, named or not
+func tableJSONCheck(c ddlClause) (string, bool) {
+	if c.Kind != clauseCheck && c.Kind != clauseOther {
+		return "", false
+	}
+	start := 0
+	if len(c.tokens) > 0 && c.tokens[0].isKeyword("CONSTRAINT") {
+		start = 2
+	}
+	column, end, ok := jsonValidCheck(c.Text, c.tokens, start)
+	if !ok || end != len(c.tokens)-1 {
+		return "", false
+	}
+	return column, true
+}
+
+// closingParen returns the index of the parenthesis closing the one at tokens[open]
+func closingParen(text string, tokens []sqlToken, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isPunct(text, '('):
+			depth++
+		case tokens[i].isPunct(text, ')'):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens) - 1
+}
+
+// adjacent reports whether tokens[i+1] directly follows tokens[i], with no space between
+func adjacent(tokens []sqlToken, i int) bool {
+	return i+1 < len(tokens) && tokens[i+1].Start == tokens[i].End
+}
+
+// defaultExpressionEnd returns the index of the last token of the default starting at tokens[i]:
+// a parenthesized expression, a function call, or a possibly signed literal
+func defaultExpressionEnd(text string, tokens []sqlToken, i int) int {
+	if tokens[i].isPunct(text, '(') {
+		return closingParen(text, tokens, i)
+	}
+	if (tokens[i].isPunct(text, '-') || tokens[i].isPunct(text, '+')) && i+1 < len(tokens) {
+		i++
+	}
+	switch {
+	case adjacent(tokens, i) && tokens[i+1].isPunct(text, '('):
+		return closingParen(text, tokens, i+1)
+	case adjacent(tokens, i) && tokens[i+1].Kind == tokString:
+		// b'1010', x'ff' and charset introducers such as _utf8mb4'text'
+		return i + 1
+	case adjacent(tokens, i) && tokens[i+1].isPunct(text, '.') && adjacent(tokens, i+1):
+		// Decimal number
+		return i + 2
+	}
+	return i
+}
+
+// isMySQLBlobType reports whether a MySQL type only takes expression defaults, which are
+// written in parentheses
+func isMySQLBlobType(typeName string) bool {
+	switch strings.ToLower(typeName) {
+	case "tinytext", "text", "mediumtext", "longtext", "tinyblob", "blob", "mediumblob", "longblob", "json",
+		"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		return true
+	}
+	return false
+}
+
+// isMySQLTimestampDefault reports whether a default is CURRENT_TIMESTAMP or a synonym, which
+// MySQL takes without parentheses
+func isMySQLTimestampDefault(token sqlToken) bool {
+	for _, k := range []string{"CURRENT_TIMESTAMP", "NOW", "LOCALTIME", "LOCALTIMESTAMP"} {
+		if token.isKeyword(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// translateColumn rewrites a column definition for MySQL
+func (t *MariaDBToMySQLTranslator) translateColumn(table string, c ddlClause, jsonColumns, collations map[string]bool) string {
+	object := table + "." + c.Name
+	e := &ddlEditor{text: c.Text, tokens: c.tokens}
+	tokens := c.tokens
+	if len(tokens) < 2 {
+		return c.Text
+	}
+
+	typeName := strings.ToLower(tokens[1].Value)
+	typeEnd := 1
+	if adjacent(tokens, 1) && tokens[2].isPunct(c.Text, '(') {
+		typeEnd = closingParen(c.Text, tokens, 2)
+	}
+	isJSON := jsonColumns[c.Name]
+	switch {
+	case isJSON:
+		e.replace(1, typeEnd, "json")
+		typeName = "json"
+		t.report(object, "LONGTEXT with a json_valid check becomes JSON, which normalizes documents (key order, whitespace, duplicate keys)")
+	case typeName == "uuid":
+		e.replace(1, typeEnd, "char(36)")
+		t.report(object, "uuid becomes char(36), which sorts as text rather than by timestamp")
+	case typeName == "inet6":
+		e.replace(1, typeEnd, "varchar(45)")
+		t.report(object, "inet6 becomes varchar(45), which compares addresses as text")
+	case typeName == "inet4":
+		e.replace(1, typeEnd, "varchar(15)")
+		t.report(object, "inet4 becomes varchar(15), which compares addresses as text")
+	}
+
+	for i := typeEnd + 1; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.isKeyword("CHARACTER") && i+2 < len(tokens) && tokens[i+1].isKeyword("SET"):
+			if isJSON {
+				e.remove(i, i+2)
+			}
+			i += 2
+		case tok.isKeyword("CHARSET") && i+1 < len(tokens):
+			if isJSON {
+				e.remove(i, i+1)
+			}
+			i++
+		case tok.isKeyword("COLLATE") && i+1 < len(tokens):
+			if isJSON {
+				e.remove(i, i+1)
+			} else if mapped, ok := mysqlCollation(tokens[i+1].Value); ok {
+				e.replace(i+1, i+1, mapped)
+				t.reportCollation(table, tokens[i+1].Value, mapped, collations)
+			}
+			i++
+		case tok.isKeyword("DEFAULT") && i+1 < len(tokens):
+			end := defaultExpressionEnd(c.Text, tokens, i+1)
+			t.translateDefault(object, e, i, end, typeName)
+			i = end
+		case tok.isKeyword("PERSISTENT"):
+			e.replace(i, i, "STORED")
+		case tok.isKeyword("GENERATED") && i+4 < len(tokens) && tokens[i+1].isKeyword("ALWAYS") &&
+			tokens[i+2].isKeyword("AS") && tokens[i+3].isKeyword("ROW"):
+			t.translateRowPeriodColumn(object, e, typeName, tokens[i+4].Value)
+			e.remove(i, i+4)
+			i += 4
+		case tok.isKeyword("AS") && i+2 < len(tokens) && tokens[i+1].isKeyword("ROW"):
+			t.translateRowPeriodColumn(object, e, typeName, tokens[i+2].Value)
+			e.remove(i, i+2)
+			i += 2
+		case (tok.isKeyword("WITH") || tok.isKeyword("WITHOUT")) && i+2 < len(tokens) &&
+			tokens[i+1].isKeyword("SYSTEM") && tokens[i+2].isKeyword("VERSIONING"):
+			e.remove(i, i+2)
+			i += 2
+		case tok.isKeyword("CHECK"):
+			if column, end, ok := jsonValidCheck(c.Text, tokens, i); ok && column == c.Name && isJSON {
+				e.remove(i, end)
+				i = end
+			} else if i+1 < len(tokens) && tokens[i+1].isPunct(c.Text, '(') {
+				i = closingParen(c.Text, tokens, i+1)
+			}
+		case tok.isPunct(c.Text, '('):
+			// Generation expressions are copied as written
+			i = closingParen(c.Text, tokens, i)
+		}
+	}
+	return e.String()
+}
+
+// translateRowPeriodColumn turns a system-versioning row start or end column into a plain column
+// MariaDB 11.5 ends current rows at 2106, past MySQL's TIMESTAMP range, so TIMESTAMP becomes DATETIME
+func (t *MariaDBToMySQLTranslator) translateRowPeriodColumn(object string, e *ddlEditor, typeName, bound string) {
+	if typeName == "timestamp" {
+		e.replace(1, 1, "datetime")
+	}
+	t.report(object, "system-versioning ROW %s column becomes a plain column that MySQL doesn't maintain", strings.ToUpper(bound))
+}
+
+// translateDefault rewrites the default between tokens[at] (DEFAULT) and tokens[end]
+// MySQL takes expressions, and literals for TEXT, BLOB and JSON columns, only in parentheses;
+// defaults that draw from a sequence are dropped as MySQL has no sequences
+func (t *MariaDBToMySQLTranslator) translateDefault(object string, e *ddlEditor, at, end int, typeName string) {
+	tokens := e.tokens
+	expr := e.text[tokens[at+1].Start:tokens[end].End]
+	for _, tok := range tokens[at+1 : end+1] {
+		if tok.isKeyword("nextval") || tok.isKeyword("lastval") {
+			e.remove(at, end)
+			t.report(object, "default %s draws from a sequence; MySQL has no sequences, so the default is dropped", expr)
+			return
+		}
+	}
+
+	first := tokens[at+1]
+	switch {
+	case first.isPunct(e.text, '('), first.isKeyword("NULL"):
+		return
+	case isMySQLTimestampDefault(first):
+		return
+	case end == at+1 && (first.Kind == tokString || (first.Kind == tokIdent && isNumber(first.Value))),
+		end > at+1 && (first.Kind == tokOther || adjacentLiteral(tokens, at+1, end)):
+		// A literal, which only TEXT, BLOB and JSON columns need in parentheses
+		if !isMySQLBlobType(typeName) {
+			return
+		}
+	}
+	e.replace(at+1, end, "("+expr+")")
+}
+
+// adjacentLiteral reports whether tokens[from] through tokens[to] form one literal with no
+// parenthesis, such as 1.5 or _utf8mb4'text'
+func adjacentLiteral(tokens []sqlToken, from, to int) bool {
+	for i := from; i < to; i++ {
+		if !adjacent(tokens, i) {
+			return false
+		}
+	}
+	return tokens[to].Kind == tokString || (tokens[to].Kind == tokIdent && isNumber(tokens[to].Value))
+}
+
+// reportCollation records a collation change once per table
+func (t *MariaDBToMySQLTranslator) reportCollation(table, from, to string, seen map[string]bool) {
+	if seen[from] {
+		return
+	}
+	seen[from] = true
+	t.report(table, "collation %s becomes %s", from, to)
+}
+
+// mysqlCollation maps a MariaDB-only collation to the closest MySQL 8 collation
+// The UCA 14.0.0 collations of MariaDB 10.10 and later map to MySQL's UCA 9.0.0 ones, and the
+// NO PAD variants to their PAD SPACE counterparts
+func mysqlCollation(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	charset, _, _ := strings.Cut(lower, "_")
+	switch {
+	case strings.Contains(lower, "_uca1400_"):
+		if charset != "utf8mb4" {
+			return charset + "_unicode_ci", true
+		}
+		switch {
+		case strings.HasSuffix(lower, "_as_cs"):
+			return "utf8mb4_0900_as_cs", true
+		case strings.HasSuffix(lower, "_as_ci"):
+			return "utf8mb4_0900_as_ci", true
+		default:
+			return "utf8mb4_0900_ai_ci", true
+		}
+	case strings.Contains(lower, "_nopad_"):
+		return strings.Replace(lower, "nopad_", "", 1), true
+	}
+	return name, false
+}
+
+// translateTableOptions rewrites the table options after the definition list
+// Aria tables become InnoDB, MariaDB-only options are dropped and system versioning is removed
+func (t *MariaDBToMySQLTranslator) translateTableOptions(table, suffix string, collations map[string]bool) string {
+	var tokens []sqlToken
+	for _, tok := range tokenizeSQL(suffix, mysqlSyntax("")) {
+		if tok.Kind != tokSpace && tok.Kind != tokComment {
+			tokens = append(tokens, tok)
+		}
+	}
+	e := &ddlEditor{text: suffix, tokens: tokens}
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		hasValue := i+2 < len(tokens) && tokens[i+1].isPunct(suffix, '=')
+		switch {
+		case tok.isKeyword("WITH") && i+2 < len(tokens) && tokens[i+1].isKeyword("SYSTEM") && tokens[i+2].isKeyword("VERSIONING"):
+			e.remove(i, i+2)
+			t.report(table, "system versioning is dropped; only current rows are copied, not history")
+			i += 2
+		case tok.isKeyword("ENGINE") && hasValue && tokens[i+2].isKeyword("Aria"):
+			e.replace(i+2, i+2, "InnoDB")
+			t.report(table, "Aria table becomes InnoDB")
+			i += 2
+		case (tok.isKeyword("PAGE_CHECKSUM") || tok.isKeyword("TRANSACTIONAL")) && hasValue:
+			e.remove(i, i+2)
+			i += 2
+		case tok.isKeyword("COLLATE") && i+1 < len(tokens):
+			value := i + 1
+			if hasValue {
+				value = i + 2
+			}
+			if mapped, ok := mysqlCollation(tokens[value].Value); ok {
+				e.replace(value, value, mapped)
+				t.reportCollation(table, tokens[value].Value, mapped, collations)
+			}
+			i = value
+		}
+	}
+	return e.String()
+}
+
+// rewriteCollations maps MariaDB-only collations named in a view, routine or trigger body
+func rewriteCollations(stmt, sqlMode string) string {
+	var tokens []sqlToken
+	for _, tok := range tokenizeSQL(stmt, mysqlSyntax(sqlMode)) {
+		if tok.Kind != tokSpace && tok.Kind != tokComment {
+			tokens = append(tokens, tok)
+		}
+	}
+	e := &ddlEditor{text: stmt, tokens: tokens}
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].isKeyword("COLLATE") && tokens[i+1].Kind == tokIdent {
+			if mapped, ok := mysqlCollation(tokens[i+1].Value); ok {
+				e.replace(i+1, i+1, mapped)
+			}
+		}
+	}
+	return e.String()
+}
+
+// mysqlSQLMode keeps the sql_mode flags MySQL 8 accepts
+// Routines written under sql_mode=ORACLE are PL/SQL, which MySQL can't run
+func mysqlSQLMode(sqlMode string) (string, error) {
+	var kept []string
+	for _, mode := range strings.Split(sqlMode, ",") {
+		mode = strings.ToUpper(strings.TrimSpace(mode))
+		if mode == "ORACLE" {
+			return "", fmt.Errorf("written for sql_mode=ORACLE, which MySQL doesn't have")
+		}
+		if mysqlSQLModes[mode] {
+			kept = append(kept, mode)
+		}
+	}
+	return strings.Join(kept, ","), nil
+}
+
+// TranslateView rewrites a MariaDB view for MySQL, which runs MariaDB's view definitions as
+// written apart from collations it doesn't have
+func (t *MariaDBToMySQLTranslator) TranslateView(view ViewDef) (ViewDef, error) {
+	view.CreateStmt = rewriteCollations(view.CreateStmt, "")
+	return view, nil
+}
+
+// TranslateRoutine rewrites a MariaDB procedure, function or event for MySQL
+func (t *MariaDBToMySQLTranslator) TranslateRoutine(routine RoutineDef) (RoutineDef, error) {
+	mode, err := mysqlSQLMode(routine.SQLMode)
+	if err != nil {
+		return RoutineDef{}, err
+	}
+	routine.CreateStmt = rewriteCollations(routine.CreateStmt, routine.SQLMode)
+	routine.SQLMode = mode
+	return routine, nil
+}
+
+// TranslateTrigger rewrites a MariaDB trigger for MySQL
+func (t *MariaDBToMySQLTranslator) TranslateTrigger(trigger TriggerDef) (TriggerDef, error) {
+	mode, err := mysqlSQLMode(trigger.SQLMode)
+	if err != nil {
+		return TriggerDef{}, err
+	}
+	trigger.CreateStmt = rewriteCollations(trigger.CreateStmt, trigger.SQLMode)
+	trigger.SQLMode = mode
+	return trigger, nil
+}
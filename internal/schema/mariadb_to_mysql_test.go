@@ -0,0 +1,150 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMariaDBToMySQLTranslator_TranslateTables(t *testing.T) {
+	tables := []TableSchema{
+		{
+			Name: "events",
+			CreateStmt: "CREATE TABLE `events` (\n" +
+				"  `id` uuid NOT NULL DEFAULT uuid(),\n" +
+				"  `ip` inet6 DEFAULT NULL,\n" +
+				"  `payload` longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL CHECK (json_valid(`payload`)),\n" +
+				"  `meta` longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,\n" +
+				"  `body` text NOT NULL DEFAULT 'none',\n" +
+				"  `title` varchar(255) COLLATE utf8mb4_uca1400_ai_ci DEFAULT 'untitled',\n" +
+				"  `total` int(11) GENERATED ALWAYS AS (`id` + 1) PERSISTENT,\n" +
+				"  `seq_no` bigint(20) DEFAULT nextval(`shop`.`event_seq`),\n" +
+				"  `created_at` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  CONSTRAINT `meta` CHECK (json_valid(`meta`))\n" +
+				") ENGINE=Aria DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_uca1400_ai_ci PAGE_CHECKSUM=1 TRANSACTIONAL=1",
+		},
+		{
+			Name: "prices",
+			CreateStmt: "CREATE TABLE `prices` (\n" +
+				"  `id` int(11) NOT NULL,\n" +
+				"  `amount` decimal(10,2) DEFAULT 0.00,\n" +
+				"  `valid_from` timestamp(6) GENERATED ALWAYS AS ROW START INVISIBLE,\n" +
+				"  `valid_to` timestamp(6) GENERATED ALWAYS AS ROW END INVISIBLE,\n" +
+				"  `note` varchar(50) DEFAULT NULL WITHOUT SYSTEM VERSIONING,\n" +
+				"  PRIMARY KEY (`id`,`valid_to`),\n" +
+				"  PERIOD FOR SYSTEM_TIME (`valid_from`, `valid_to`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci WITH SYSTEM VERSIONING",
+			Columns: []ColumnDef{{Name: "valid_from", Extra: "ROW START"}, {Name: "valid_to", Extra: "ROW END"}},
+		},
+	}
+
+	tr := NewMariaDBToMySQLTranslator()
+	translated, types, err := tr.TranslateTables(nil, "shop", tables)
+	if err != nil {
+		t.Fatalf("TranslateTables() error = %v", err)
+	}
+	if len(translated) != 2 || types != nil {
+		t.Fatalf("TranslateTables() = %d tables, %d types", len(translated), len(types))
+	}
+
+	wantEvents := "CREATE TABLE `events` (\n" +
+		"  `id` char(36) NOT NULL DEFAULT (uuid()),\n" +
+		"  `ip` varchar(45) DEFAULT NULL,\n" +
+		"  `payload` json DEFAULT NULL,\n" +
+		"  `meta` json NOT NULL,\n" +
+		"  `body` text NOT NULL DEFAULT ('none'),\n" +
+		"  `title` varchar(255) COLLATE utf8mb4_0900_ai_ci DEFAULT 'untitled',\n" +
+		"  `total` int(11) GENERATED ALWAYS AS (`id` + 1) STORED,\n" +
+		"  `seq_no` bigint(20),\n" +
+		"  `created_at` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci"
+	if got := translated[0].CreateStmt; got != wantEvents {
+		t.Errorf("events CreateStmt =\n%s\nwant\n%s", got, wantEvents)
+	}
+
+	wantPrices := "CREATE TABLE `prices` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `amount` decimal(10,2) DEFAULT 0.00,\n" +
+		"  `valid_from` datetime(6) INVISIBLE,\n" +
+		"  `valid_to` datetime(6) INVISIBLE,\n" +
+		"  `note` varchar(50) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`,`valid_to`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci"
+	if got := translated[1].CreateStmt; got != wantPrices {
+		t.Errorf("prices CreateStmt =\n%s\nwant\n%s", got, wantPrices)
+	}
+	// The row start and end columns are plain columns now, so they're copied
+	if len(translated[1].Columns) != 0 {
+		t.Errorf("prices Columns = %+v, want none", translated[1].Columns)
+	}
+
+	var report []string
+	for _, c := range tr.LossyConversions() {
+		report = append(report, c.String())
+	}
+	joined := strings.Join(report, "\n")
+	for _, want := range []string{
+		"events.id: uuid becomes char(36)",
+		"events.ip: inet6 becomes varchar(45)",
+		"events.payload: LONGTEXT with a json_valid check becomes JSON",
+		"events.seq_no: default nextval(`shop`.`event_seq`) draws from a sequence",
+		"events: collation utf8mb4_uca1400_ai_ci becomes utf8mb4_0900_ai_ci",
+		"events: Aria table becomes InnoDB",
+		"prices.valid_from: system-versioning ROW START column becomes a plain column",
+		"prices: PERIOD FOR SYSTEM_TIME has no MySQL equivalent",
+		"prices: system versioning is dropped",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("LossyConversions() missing %q in:\n%s", want, joined)
+		}
+	}
+	if strings.Count(joined, "collation utf8mb4_uca1400_ai_ci") != 1 {
+		t.Errorf("collation change should be reported once per table:\n%s", joined)
+	}
+}
+
+func TestMySQLCollation(t *testing.T) {
+	tests := []struct {
+		name, want string
+		changed    bool
+	}{
+		{"utf8mb4_uca1400_ai_ci", "utf8mb4_0900_ai_ci", true},
+		{"utf8mb4_uca1400_as_cs", "utf8mb4_0900_as_cs", true},
+		{"utf8mb4_uca1400_swedish_ai_ci", "utf8mb4_0900_ai_ci", true},
+		{"utf8mb3_uca1400_ai_ci", "utf8mb3_unicode_ci", true},
+		{"utf8mb4_general_nopad_ci", "utf8mb4_general_ci", true},
+		{"utf8mb4_nopad_bin", "utf8mb4_bin", true},
+		{"utf8mb4_unicode_ci", "utf8mb4_unicode_ci", false},
+	}
+	for _, tt := range tests {
+		got, changed := mysqlCollation(tt.name)
+		if got != tt.want || changed != tt.changed {
+			t.Errorf("mysqlCollation(%q) = %q, %v, want %q, %v", tt.name, got, changed, tt.want, tt.changed)
+		}
+	}
+}
+
+func TestMariaDBToMySQLTranslator_TranslateRoutine(t *testing.T) {
+	tr := NewMariaDBToMySQLTranslator()
+
+	routine, err := tr.TranslateRoutine(RoutineDef{
+		Name:       "tag",
+		Kind:       "procedure",
+		SQLMode:    "STRICT_TRANS_TABLES,ERROR_FOR_DIVISION_BY_ZERO,NO_AUTO_CREATE_USER,SIMULTANEOUS_ASSIGNMENT",
+		CreateStmt: "CREATE PROCEDURE `tag`() SELECT 'a' COLLATE utf8mb4_uca1400_ai_ci",
+	})
+	if err != nil {
+		t.Fatalf("TranslateRoutine() error = %v", err)
+	}
+	if routine.SQLMode != "STRICT_TRANS_TABLES,ERROR_FOR_DIVISION_BY_ZERO" {
+		t.Errorf("SQLMode = %q", routine.SQLMode)
+	}
+	if routine.CreateStmt != "CREATE PROCEDURE `tag`() SELECT 'a' COLLATE utf8mb4_0900_ai_ci" {
+		t.Errorf("CreateStmt = %q", routine.CreateStmt)
+	}
+
+	if _, err := tr.TranslateTrigger(TriggerDef{Name: "audit", SQLMode: "PIPES_AS_CONCAT,ORACLE"}); err == nil {
+		t.Error("TranslateTrigger() should reject sql_mode=ORACLE triggers")
+	}
+}
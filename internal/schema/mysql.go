@@ -11,7 +11,11 @@ import (
 
 // MySQLExtractor extracts schema from MySQL databases
 type MySQLExtractor struct {
-	Dialect dialect.Dialect
+	Dialect      dialect.Dialect
+	Definer      DefinerPolicy
+	Partitioning string // PartitioningKeep or PartitioningDrop; keep when empty
+
+	legacyStatistics bool // information_schema.statistics lacks IS_VISIBLE and EXPRESSION (MySQL < 8.0.13)
 }
 
 // NewMySQLExtractor creates a new MySQL schema extractor
@@ -21,13 +25,18 @@ func NewMySQLExtractor() *MySQLExtractor {
 	}
 }
 
+// SetDefinerPolicy sets how DEFINER and SQL SECURITY clauses are rewritten
+func (e *MySQLExtractor) SetDefinerPolicy(policy DefinerPolicy) {
+	e.Definer = policy
+}
+
 // ExtractTables extracts all table schemas from the database
 func (e *MySQLExtractor) ExtractTables(db *sql.DB, database string) ([]TableSchema, error) {
-	// Get table names
+	// Get table names; MariaDB lists system-versioned tables under their own type
 	rows, err := db.Query(`
 		SELECT table_name
 		FROM information_schema.tables
-		WHERE table_schema = ? AND table_type = 'BASE TABLE'
+		WHERE table_schema = ? AND table_type IN ('BASE TABLE', 'SYSTEM VERSIONED')
 		ORDER BY table_name
 	`, database)
 	if err != nil {
@@ -67,33 +76,51 @@ func (e *MySQLExtractor) extractTable(db *sql.DB, database, tableName string) (T
 	}
 	table.CreateStmt = createStmt
 
-	// Extract indexes (excluding primary key - handled separately)
-	rows, err := db.Query(`
-		SELECT index_name, GROUP_CONCAT(column_name ORDER BY seq_in_index) as columns, non_unique
-		FROM information_schema.statistics
-		WHERE table_schema = ? AND table_name = ? AND index_name != 'PRIMARY'
-		GROUP BY index_name, non_unique
-	`, database, tableName)
-	if err != nil {
-		return table, fmt.Errorf("failed to get indexes: %w", err)
+	// Partitioned tables are copied partition by partition
+	if start, _ := partitionClause(createStmt); start >= 0 {
+		partitions, err := e.extractPartitions(db, database, tableName)
+		if err != nil {
+			return table, err
+		}
+		table.Partitions = partitions
+		if e.Partitioning == PartitioningDrop {
+			table.CreateStmt = removePartitioning(createStmt)
+			table.PartitioningDropped = true
+		}
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var idx IndexDef
-		var columns string
-		var nonUnique int
-		if err := rows.Scan(&idx.Name, &columns, &nonUnique); err != nil {
-			return table, err
+	// Extract secondary indexes; CREATE TABLE keeps only the primary key and they're added after the data load
+	indexes, err := e.extractIndexes(db, database, tableName)
+	if err != nil {
+		return table, err
+	}
+	// An AUTO_INCREMENT column must be the first column of some key when the table is
+	// created, so an index that exists only for that stays in CREATE TABLE
+	autoIncrement, leadsPrimaryKey := autoIncrementColumn(createStmt)
+	inline := ""
+	if autoIncrement != "" && !leadsPrimaryKey {
+		for _, ix := range indexes {
+			if len(ix.columns) > 0 && ix.columns[0] == autoIncrement {
+				inline = ix.name
+				break
+			}
 		}
-		idx.Columns = strings.Split(columns, ",")
-		idx.IsUnique = nonUnique == 0
-		idx.CreateStmt = e.buildCreateIndexStmt(tableName, idx)
-		table.Indexes = append(table.Indexes, idx)
+	}
+	for _, ix := range indexes {
+		if ix.name == inline {
+			continue
+		}
+		table.Indexes = append(table.Indexes, IndexDef{
+			Name:       ix.name,
+			Columns:    ix.columns,
+			IsUnique:   ix.unique,
+			CreateStmt: ix.createStmt(tableName),
+			Definition: ix.definition(),
+		})
 	}
 
 	// Extract foreign keys
-	rows, err = db.Query(`
+	rows, err := db.Query(`
 		SELECT
 			kcu.constraint_name,
 			GROUP_CONCAT(kcu.column_name ORDER BY kcu.ordinal_position) as columns,
@@ -130,18 +157,140 @@ func (e *MySQLExtractor) extractTable(db *sql.DB, database, tableName string) (T
 	return table, nil
 }
 
-// buildCreateIndexStmt builds a CREATE INDEX statement
-func (e *MySQLExtractor) buildCreateIndexStmt(tableName string, idx IndexDef) string {
-	uniqueStr := ""
-	if idx.IsUnique {
-		uniqueStr = "UNIQUE "
+// mysqlIndex is a secondary index read from information_schema.statistics
+type mysqlIndex struct {
+	name      string
+	unique    bool
+	indexType string // BTREE, HASH, FULLTEXT or SPATIAL
+	comment   string
+	visible   bool
+	parts     []string // Key parts as written in DDL: `col`, `col`(10), `col` DESC or (expression)
+	columns   []string // Column of each key part (the expression for functional key parts)
+}
+
+// extractIndexes reads a table's secondary indexes, keeping prefix lengths, ordering,
+// functional key parts, index types, comments and visibility
+func (e *MySQLExtractor) extractIndexes(db *sql.DB, database, tableName string) ([]mysqlIndex, error) {
+	rows, err := e.queryIndexStatistics(db, database, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes: %w", err)
 	}
-	quotedCols := make([]string, len(idx.Columns))
-	for i, col := range idx.Columns {
-		quotedCols[i] = "`" + col + "`"
+	defer rows.Close()
+
+	var indexes []mysqlIndex
+	for rows.Next() {
+		var name, indexType, comment, visible string
+		var column, collation, expression sql.NullString
+		var subPart sql.NullInt64
+		var nonUnique int
+		if err := rows.Scan(&name, &column, &subPart, &collation, &nonUnique, &indexType, &comment, &visible, &expression); err != nil {
+			return nil, err
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].name != name {
+			indexes = append(indexes, mysqlIndex{
+				name:      name,
+				unique:    nonUnique == 0,
+				indexType: indexType,
+				comment:   comment,
+				visible:   visible != "NO",
+			})
+		}
+		ix := &indexes[len(indexes)-1]
+
+		var part string
+		if expression.Valid && expression.String != "" {
+			// Functional key parts need their own parentheses
+			part = "(" + expression.String + ")"
+			ix.columns = append(ix.columns, expression.String)
+		} else {
+			part = quoteMySQLIdent(column.String)
+			if subPart.Valid {
+				part += fmt.Sprintf("(%d)", subPart.Int64)
+			}
+			ix.columns = append(ix.columns, column.String)
+		}
+		if collation.String == "D" {
+			part += " DESC"
+		}
+		ix.parts = append(ix.parts, part)
+	}
+	return indexes, rows.Err()
+}
+
+// queryIndexStatistics queries the key parts of a table's secondary indexes
+// IS_VISIBLE and EXPRESSION only exist on MySQL 8.0.13+, so older servers get defaults
+func (e *MySQLExtractor) queryIndexStatistics(db *sql.DB, database, tableName string) (*sql.Rows, error) {
+	const query = `
+		SELECT index_name, column_name, sub_part, collation, non_unique, index_type, index_comment, %s
+		FROM information_schema.statistics
+		WHERE table_schema = ? AND table_name = ? AND index_name != 'PRIMARY'
+		ORDER BY index_name, seq_in_index
+	`
+	if !e.legacyStatistics {
+		rows, err := db.Query(fmt.Sprintf(query, "is_visible, expression"), database, tableName)
+		if err == nil {
+			return rows, nil
+		}
+		e.legacyStatistics = true
+	}
+	return db.Query(fmt.Sprintf(query, "'YES' AS is_visible, NULL AS expression"), database, tableName)
+}
+
+// keyword returns the index kind keyword for CREATE ... INDEX and ADD ... INDEX
+func (ix mysqlIndex) keyword() string {
+	switch {
+	case ix.indexType == "FULLTEXT" || ix.indexType == "SPATIAL":
+		return ix.indexType + " INDEX"
+	case ix.unique:
+		return "UNIQUE INDEX"
+	default:
+		return "INDEX"
+	}
+}
+
+// options returns the index options that follow the key parts
+func (ix mysqlIndex) options() string {
+	var opts string
+	if ix.indexType == "HASH" {
+		opts += " USING HASH"
+	}
+	if ix.comment != "" {
+		opts += " COMMENT '" + strings.ReplaceAll(strings.ReplaceAll(ix.comment, `\`, `\\`), "'", "''") + "'"
+	}
+	if !ix.visible {
+		opts += " INVISIBLE"
+	}
+	return opts
+}
+
+// createStmt builds a standalone CREATE INDEX statement
+func (ix mysqlIndex) createStmt(tableName string) string {
+	return fmt.Sprintf("CREATE %s %s ON %s (%s)%s",
+		ix.keyword(), quoteMySQLIdent(ix.name), quoteMySQLIdent(tableName), strings.Join(ix.parts, ", "), ix.options())
+}
+
+// definition builds the ADD clause used to create all of a table's indexes in one ALTER TABLE
+func (ix mysqlIndex) definition() string {
+	return fmt.Sprintf("ADD %s %s (%s)%s", ix.keyword(), quoteMySQLIdent(ix.name), strings.Join(ix.parts, ", "), ix.options())
+}
+
+// autoIncrementColumn returns the AUTO_INCREMENT column of a SHOW CREATE TABLE statement, if any,
+// and whether it leads the primary key
+func autoIncrementColumn(createStmt string) (string, bool) {
+	ddl, err := parseCreateTable(createStmt, mysqlSyntax(""))
+	if err != nil {
+		return "", false
+	}
+	column, primaryKeyColumn := "", ""
+	for _, c := range ddl.Clauses {
+		switch {
+		case c.Kind == clauseColumn && column == "" && c.hasKeyword("AUTO_INCREMENT"):
+			column = c.Name
+		case c.Kind == clausePrimaryKey:
+			primaryKeyColumn = c.firstKeyColumn()
+		}
 	}
-	return fmt.Sprintf("CREATE %sINDEX `%s` ON `%s` (%s)",
-		uniqueStr, idx.Name, tableName, strings.Join(quotedCols, ", "))
+	return column, column != "" && column == primaryKeyColumn
 }
 
 // buildAddForeignKeyStmt builds an ALTER TABLE ADD CONSTRAINT statement for a foreign key
@@ -194,15 +343,64 @@ func (e *MySQLExtractor) ExtractViews(db *sql.DB, database string) ([]ViewDef, e
 		if err != nil {
 			return nil, fmt.Errorf("failed to get CREATE VIEW for %s: %w", view.Name, err)
 		}
-		view.CreateStmt = createStmt
+		view.CreateStmt = e.Definer.rewriteView(createStmt)
 		view.Dependencies = extractViewDependencies(viewDef)
 		views = append(views, view)
 	}
+	rows.Close()
+
+	// Prefer the server's own dependency list over parsing the definitions
+	if deps, ok := e.extractViewTableUsage(db, database); ok {
+		for i := range views {
+			views[i].Dependencies = deps[views[i].Name]
+		}
+	}
 
 	// Sort views by dependency order
-	views = sortViewsByDependency(views)
+	return sortViewsByDependency(views)
+}
+
+// extractViewTableUsage reads the tables and views each view references from the catalog
+// VIEW_TABLE_USAGE only exists on MySQL 8.0.13+; ok is false when it can't be read
+func (e *MySQLExtractor) extractViewTableUsage(db *sql.DB, database string) (map[string][]string, bool) {
+	rows, err := db.Query(`
+		SELECT view_name, table_name
+		FROM information_schema.view_table_usage
+		WHERE view_schema = ? AND table_schema = ?
+		ORDER BY view_name, table_name
+	`, database, database)
+	if err != nil {
+		return nil, false
+	}
+	defer rows.Close()
 
-	return views, nil
+	deps := make(map[string][]string)
+	for rows.Next() {
+		var viewName, tableName string
+		if err := rows.Scan(&viewName, &tableName); err != nil {
+			return nil, false
+		}
+		deps[viewName] = append(deps[viewName], tableName)
+	}
+	if rows.Err() != nil {
+		return nil, false
+	}
+	return deps, true
+}
+
+// ExtractSchemas is a no-op for MySQL (a MySQL schema is the database itself)
+func (e *MySQLExtractor) ExtractSchemas(db *sql.DB, database string) ([]string, error) {
+	return nil, nil
+}
+
+// ExtractExtensions is a no-op for MySQL (MySQL has no extensions)
+func (e *MySQLExtractor) ExtractExtensions(db *sql.DB, database string) ([]ExtensionDef, error) {
+	return nil, nil
+}
+
+// ExtractTypes is a no-op for MySQL (ENUM and SET are column-level types)
+func (e *MySQLExtractor) ExtractTypes(db *sql.DB, database string) ([]TypeDef, error) {
+	return nil, nil
 }
 
 // ExtractSequences is a no-op for MySQL (MySQL doesn't have sequences)
@@ -213,6 +411,12 @@ func (e *MySQLExtractor) ExtractSequences(db *sql.DB, database string) ([]Sequen
 // MySQLApplier applies schema to MySQL databases
 type MySQLApplier struct {
 	BaseApplier
+
+	// Database rename applied to view, routine and trigger bodies (see SetDatabaseRename)
+	sourceDatabase string
+	destDatabase   string
+	databaseExists map[string]bool // Other referenced databases, looked up on the destination once
+	unresolved     []string
 }
 
 // NewMySQLApplier creates a new MySQL schema applier
@@ -225,16 +429,70 @@ func NewMySQLApplier() *MySQLApplier {
 }
 
 // CreateTable creates a table in the database
-// MySQL-specific: removes foreign key constraints from CREATE TABLE statement
+// MySQL-specific: removes foreign keys and deferred secondary indexes from the CREATE TABLE statement
 func (a *MySQLApplier) CreateTable(db *sql.DB, table TableSchema) error {
-	// Remove foreign key constraints from CREATE TABLE statement
-	// We'll add them later after all tables are created
-	createStmt := removeForeignKeysFromCreateTable(table.CreateStmt)
+	// Remove secondary indexes and foreign key constraints from CREATE TABLE statement
+	// We'll add them later, after the data load and once all tables are created
+	createStmt := removeForeignKeysFromCreateTable(removeKeysFromCreateTable(table.CreateStmt, table.Indexes))
 
 	_, err := db.Exec(createStmt)
 	if err != nil {
-		return fmt.Errorf("failed to create table %s: %w", table.Name, err)
+		return fmt.Errorf("failed to create table %s: %w", table.DisplayName(), err)
+	}
+	return nil
+}
+
+// CreateTableIndexes adds a table's secondary indexes with a single ALTER TABLE, so the table
+// is rebuilt once rather than once per index
+// FULLTEXT indexes each get their own ALTER TABLE, since InnoDB creates only one at a time
+func (a *MySQLApplier) CreateTableIndexes(db *sql.DB, table TableSchema) error {
+	var clauses []string
+	var stmts []string
+	for _, idx := range table.Indexes {
+		if isFulltextDefinition(idx.Definition) {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s %s", quoteMySQLIdent(table.Name), idx.Definition))
+			continue
+		}
+		clauses = append(clauses, idx.Definition)
+	}
+	if len(clauses) > 0 {
+		stmts = append([]string{fmt.Sprintf("ALTER TABLE %s %s", quoteMySQLIdent(table.Name), strings.Join(clauses, ", "))}, stmts...)
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create indexes on %s: %w", table.DisplayName(), err)
+		}
+	}
+	return nil
+}
+
+// isFulltextDefinition reports whether an index's ADD clause creates a FULLTEXT index
+func isFulltextDefinition(definition string) bool {
+	return strings.HasPrefix(strings.ToUpper(definition), "ADD FULLTEXT")
+}
+
+// CreateView creates a view with its database qualifiers pointing at the destination database
+func (a *MySQLApplier) CreateView(db *sql.DB, view ViewDef) error {
+	stmt, err := a.qualifyForDestination(db, "view "+view.Name, "", view.CreateStmt)
+	if err != nil {
+		return err
 	}
+	view.CreateStmt = stmt
+	return a.BaseApplier.CreateView(db, view)
+}
+
+// CreateSchema is a no-op for MySQL (tables live in the destination database)
+func (a *MySQLApplier) CreateSchema(db *sql.DB, name string) error {
+	return nil
+}
+
+// CreateExtension is a no-op for MySQL
+func (a *MySQLApplier) CreateExtension(db *sql.DB, ext ExtensionDef) error {
+	return nil
+}
+
+// RefreshMaterializedView is a no-op for MySQL (MySQL has no materialized views)
+func (a *MySQLApplier) RefreshMaterializedView(db *sql.DB, view ViewDef) error {
 	return nil
 }
 
@@ -243,26 +501,52 @@ func (a *MySQLApplier) CreateSequence(db *sql.DB, seq SequenceDef) error {
 	return nil
 }
 
+// SetSequenceOwner is a no-op for MySQL
+func (a *MySQLApplier) SetSequenceOwner(db *sql.DB, seq SequenceDef) error {
+	return nil
+}
+
 // SetSequenceValue is a no-op for MySQL
 func (a *MySQLApplier) SetSequenceValue(db *sql.DB, seq SequenceDef) error {
 	return nil
 }
 
-// removeForeignKeysFromCreateTable removes FOREIGN KEY constraints from a CREATE TABLE statement
-func removeForeignKeysFromCreateTable(createStmt string) string {
-	// This regex matches CONSTRAINT ... FOREIGN KEY ... REFERENCES ... lines
-	// Including ON DELETE and ON UPDATE clauses
-	re := regexp.MustCompile(`,?\s*CONSTRAINT\s+`+"`[^`]+`"+`\s+FOREIGN KEY[^,)]+(?:ON DELETE [^,)]+)?(?:ON UPDATE [^,)]+)?`)
-	result := re.ReplaceAllString(createStmt, "")
+// removeKeysFromCreateTable removes the given secondary indexes from a SHOW CREATE TABLE statement
+// The statement is returned unchanged if it can't be parsed
+func removeKeysFromCreateTable(createStmt string, indexes []IndexDef) string {
+	if len(indexes) == 0 {
+		return createStmt
+	}
+	deferred := make(map[string]bool, len(indexes))
+	for _, idx := range indexes {
+		deferred[idx.Name] = true
+	}
 
-	// Also handle FOREIGN KEY without CONSTRAINT name
-	re2 := regexp.MustCompile(`,?\s*FOREIGN KEY[^,)]+(?:ON DELETE [^,)]+)?(?:ON UPDATE [^,)]+)?`)
-	result = re2.ReplaceAllString(result, "")
+	ddl, err := parseCreateTable(createStmt, mysqlSyntax(""))
+	if err != nil {
+		return createStmt
+	}
+	ddl.removeClauses(func(c ddlClause) bool {
+		return c.Kind == clauseKey && deferred[c.Name]
+	})
+	return ddl.String()
+}
 
-	return result
+// removeForeignKeysFromCreateTable removes FOREIGN KEY constraints from a CREATE TABLE statement
+// including their ON DELETE and ON UPDATE clauses
+func removeForeignKeysFromCreateTable(createStmt string) string {
+	ddl, err := parseCreateTable(createStmt, mysqlSyntax(""))
+	if err != nil {
+		return createStmt
+	}
+	ddl.removeClauses(func(c ddlClause) bool {
+		return c.Kind == clauseForeignKey
+	})
+	return ddl.String()
 }
 
 // extractViewDependencies extracts table/view names referenced in a view definition
+// It's only a fallback for servers without information_schema.VIEW_TABLE_USAGE
 func extractViewDependencies(viewDef string) []string {
 	// Simple extraction - look for FROM and JOIN clauses
 	re := regexp.MustCompile(`(?i)(?:FROM|JOIN)\s+` + "`?([a-zA-Z_][a-zA-Z0-9_]*)`?")
@@ -283,40 +567,73 @@ func extractViewDependencies(viewDef string) []string {
 }
 
 // sortViewsByDependency sorts views so that dependencies come before dependents
-func sortViewsByDependency(views []ViewDef) []ViewDef {
-	// Build dependency graph
-	viewSet := make(map[string]bool)
+// Dependencies that aren't views (tables, or views outside the migration) are ignored
+// A circular dependency can't be created in any order, so it's reported as an error
+func sortViewsByDependency(views []ViewDef) ([]ViewDef, error) {
+	byName := make(map[string]ViewDef, len(views))
 	for _, v := range views {
-		viewSet[v.Name] = true
+		byName[v.QualifiedName()] = v
 	}
 
-	// Simple topological sort
 	sorted := make([]ViewDef, 0, len(views))
 	added := make(map[string]bool)
 
+	// Repeatedly take the first view whose view dependencies are all in place
 	for len(sorted) < len(views) {
+		progress := false
 		for _, v := range views {
-			if added[v.Name] {
+			name := v.QualifiedName()
+			if added[name] || !viewDependenciesMet(v, byName, added) {
 				continue
 			}
-			// Check if all view dependencies are either not views or already added
-			canAdd := true
-			for _, dep := range v.Dependencies {
-				if viewSet[dep] && !added[dep] {
-					canAdd = false
-					break
-				}
-			}
-			if canAdd {
-				sorted = append(sorted, v)
-				added[v.Name] = true
-			}
+			sorted = append(sorted, v)
+			added[name] = true
+			progress = true
+			break
 		}
-		// Prevent infinite loop if there's a circular dependency
-		if len(sorted) == 0 {
-			return views
+		if !progress {
+			return nil, fmt.Errorf("circular view dependency: %s", strings.Join(findViewCycle(views, byName, added), " -> "))
 		}
 	}
 
-	return sorted
+	return sorted, nil
+}
+
+// viewDependenciesMet reports whether every view a view depends on has been added
+func viewDependenciesMet(v ViewDef, byName map[string]ViewDef, added map[string]bool) bool {
+	for _, dep := range v.Dependencies {
+		if _, isView := byName[dep]; isView && !added[dep] && dep != v.QualifiedName() {
+			return false
+		}
+	}
+	return true
+}
+
+// findViewCycle returns one dependency cycle among the views not yet added, first view repeated at the end
+func findViewCycle(views []ViewDef, byName map[string]ViewDef, added map[string]bool) []string {
+	// Every remaining view has an unmet dependency, so following them must revisit a view
+	var path []string
+	onPath := make(map[string]int)
+	for _, v := range views {
+		if !added[v.QualifiedName()] {
+			path = append(path, v.QualifiedName())
+			break
+		}
+	}
+	for len(path) > 0 {
+		current := path[len(path)-1]
+		onPath[current] = len(path) - 1
+		var next string
+		for _, dep := range byName[current].Dependencies {
+			if _, isView := byName[dep]; isView && !added[dep] && dep != current {
+				next = dep
+				break
+			}
+		}
+		if start, seen := onPath[next]; seen {
+			return append(path[start:], next)
+		}
+		path = append(path, next)
+	}
+	return path
 }
@@ -0,0 +1,363 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// legacyCharsets are the MySQL character sets a charset conversion rewrites
+// ascii and binary columns (hashes, UUIDs, tokens) are left alone
+var legacyCharsets = map[string]bool{"latin1": true, "utf8": true, "utf8mb3": true}
+
+// mysqlTextTypes are the column types that have a character set
+var mysqlTextTypes = map[string]bool{
+	"char": true, "varchar": true, "tinytext": true, "text": true, "mediumtext": true, "longtext": true,
+	"enum": true, "set": true,
+}
+
+// InnoDB's index key limits in bytes: per key part for COMPACT and REDUNDANT tables, and per
+// key part and per index for DYNAMIC and COMPRESSED ones
+const (
+	compactKeyPartLimit = 767
+	indexKeyLimit       = 3072
+)
+
+// utf8mb4MaxBytes is the most bytes a utf8mb4 character takes, which MySQL reserves per character in keys
+const utf8mb4MaxBytes = 4
+
+// CharsetConversion converts legacy MySQL character sets in CREATE TABLE statements
+type CharsetConversion struct {
+	Charset   string // Character set to convert to; conversion is off when empty
+	Collation string // Collation to convert to; binary and case-sensitive collations become <charset>_bin
+}
+
+// ParseCharsetConversion validates the --convert-charset and --convert-collation values
+// Only utf8mb4 is a target; the collation defaults to utf8mb4_unicode_ci, which Laravel uses
+func ParseCharsetConversion(charset, collation string) (CharsetConversion, error) {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	collation = strings.ToLower(strings.TrimSpace(collation))
+	switch charset {
+	case "":
+		if collation != "" {
+			return CharsetConversion{}, fmt.Errorf("--convert-collation needs --convert-charset")
+		}
+		return CharsetConversion{}, nil
+	case "utf8mb4":
+	default:
+		return CharsetConversion{}, fmt.Errorf("invalid charset %q: only utf8mb4 is supported", charset)
+	}
+	if collation == "" {
+		collation = "utf8mb4_unicode_ci"
+	}
+	if !strings.HasPrefix(collation, charset+"_") {
+		return CharsetConversion{}, fmt.Errorf("collation %q isn't a %s collation", collation, charset)
+	}
+	return CharsetConversion{Charset: charset, Collation: collation}, nil
+}
+
+// Enabled reports whether charsets are converted
+func (c CharsetConversion) Enabled() bool {
+	return c.Charset != ""
+}
+
+// collationFor returns the target collation replacing a legacy one, keeping binary comparison
+func (c CharsetConversion) collationFor(legacy string) string {
+	legacy = strings.ToLower(legacy)
+	if strings.HasSuffix(legacy, "_bin") || strings.HasSuffix(legacy, "_cs") {
+		return c.Charset + "_bin"
+	}
+	return c.Collation
+}
+
+// ConvertedColumn is a text column whose character set a conversion changes
+type ConvertedColumn struct {
+	Table  string
+	Column string
+	From   string // Character set on the source
+}
+
+// KeyOverflow is an index whose key no longer fits InnoDB's limit once its columns are converted
+type KeyOverflow struct {
+	Table string
+	Index string
+	Bytes int // Bytes the converted key parts take
+	Limit int
+}
+
+// String formats the overflow for the migration report, suggesting a prefix length that fits
+func (o KeyOverflow) String() string {
+	return fmt.Sprintf("%s: index %s needs %d bytes as utf8mb4, over the %d byte limit; index a prefix of at most %d characters",
+		o.Table, o.Index, o.Bytes, o.Limit, o.Limit/utf8mb4MaxBytes)
+}
+
+// CharsetConverter rewrites table and column character sets and collations, and checks the
+// converted tables' index keys still fit
+type CharsetConverter struct {
+	conv      CharsetConversion
+	columns   []ConvertedColumn
+	overflows []KeyOverflow
+}
+
+// NewCharsetConverter returns a converter for the conversion, or nil when conversion is off
+func NewCharsetConverter(conv CharsetConversion) *CharsetConverter {
+	if !conv.Enabled() {
+		return nil
+	}
+	return &CharsetConverter{conv: conv}
+}
+
+// ConvertedColumns lists the text columns converted so far, in table order
+func (c *CharsetConverter) ConvertedColumns() []ConvertedColumn {
+	return c.columns
+}
+
+// KeyOverflows lists the indexes that are too long once converted
+func (c *CharsetConverter) KeyOverflows() []KeyOverflow {
+	return c.overflows
+}
+
+// ConvertTable rewrites a table's CREATE TABLE statement to the target character set
+// Columns that inherit a legacy table default are converted along with the default
+func (c *CharsetConverter) ConvertTable(table TableSchema) (TableSchema, error) {
+	ddl, err := parseCreateTable(table.CreateStmt, mysqlSyntax(""))
+	if err != nil {
+		return table, fmt.Errorf("failed to parse CREATE TABLE %s: %w", table.Name, err)
+	}
+
+	options := &ddlEditor{text: ddl.Suffix, tokens: significantTokens(tokenizeSQL(ddl.Suffix, mysqlSyntax("")))}
+	tableCharset := c.convertTableOptions(options)
+	ddl.Suffix = options.String()
+
+	// Characters each converted column can hold, for checking index keys
+	lengths := make(map[string]int)
+	for i, clause := range ddl.Clauses {
+		if clause.Kind != clauseColumn || len(clause.tokens) < 2 {
+			continue
+		}
+		e := &ddlEditor{text: clause.Text, tokens: clause.tokens}
+		from, ok := c.convertColumn(e, tableCharset)
+		if !ok {
+			continue
+		}
+		ddl.Clauses[i].Text = e.String()
+		c.columns = append(c.columns, ConvertedColumn{Table: table.Name, Column: clause.Name, From: from})
+		lengths[clause.Name] = columnCharLength(clause)
+	}
+	if len(lengths) == 0 && tableCharset == "" {
+		return table, nil
+	}
+
+	partLimit := indexKeyLimit
+	if rowFormat := tableOption(options, "ROW_FORMAT"); strings.EqualFold(rowFormat, "COMPACT") || strings.EqualFold(rowFormat, "REDUNDANT") {
+		partLimit = compactKeyPartLimit
+	}
+	for _, clause := range ddl.Clauses {
+		if clause.Kind == clausePrimaryKey || (clause.Kind == clauseKey && !clause.hasKeyword("FULLTEXT") && !clause.hasKeyword("SPATIAL")) {
+			name := clause.Name
+			if clause.Kind == clausePrimaryKey {
+				name = "PRIMARY"
+			}
+			c.checkKey(table.Name, name, keyParts(clause.Text, clause.tokens), lengths, partLimit)
+		}
+	}
+	for _, ix := range table.Indexes {
+		if ix.Definition == "" || strings.Contains(ix.Definition, "FULLTEXT") || strings.Contains(ix.Definition, "SPATIAL") {
+			continue
+		}
+		tokens := significantTokens(tokenizeSQL(ix.Definition, mysqlSyntax("")))
+		c.checkKey(table.Name, ix.Name, keyParts(ix.Definition, tokens), lengths, partLimit)
+	}
+
+	table.CreateStmt = ddl.String()
+	return table, nil
+}
+
+// convertTableOptions rewrites a legacy DEFAULT CHARSET and COLLATE in the table options,
+// returning the legacy charset it replaced, if any
+func (c *CharsetConverter) convertTableOptions(e *ddlEditor) string {
+	tokens := e.tokens
+	charsetAt, collateAt := -1, -1
+	for i := 0; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isKeyword("CHARSET"), tokens[i].isKeyword("SET") && i > 0 && tokens[i-1].isKeyword("CHARACTER"):
+			charsetAt = optionValue(e.text, tokens, i)
+		case tokens[i].isKeyword("COLLATE"):
+			collateAt = optionValue(e.text, tokens, i)
+		}
+	}
+
+	legacy := ""
+	switch {
+	case charsetAt >= 0:
+		legacy = strings.ToLower(tokens[charsetAt].Value)
+	case collateAt >= 0:
+		legacy, _, _ = strings.Cut(strings.ToLower(tokens[collateAt].Value), "_")
+	}
+	if !legacyCharsets[legacy] {
+		return ""
+	}
+
+	switch {
+	case charsetAt >= 0 && collateAt >= 0:
+		e.replace(charsetAt, charsetAt, c.conv.Charset)
+		e.replace(collateAt, collateAt, c.conv.collationFor(tokens[collateAt].Value))
+	case charsetAt >= 0:
+		e.replace(charsetAt, charsetAt, c.conv.Charset+" COLLATE="+c.conv.Collation)
+	default:
+		e.replace(collateAt, collateAt, c.conv.collationFor(tokens[collateAt].Value))
+	}
+	return legacy
+}
+
+// optionValue returns the index of the value of the option keyword at tokens[i], which may be
+// followed by an equals sign, or -1 if there is none
+func optionValue(text string, tokens []sqlToken, i int) int {
+	i++
+	if i < len(tokens) && tokens[i].isPunct(text, '=') {
+		i++
+	}
+	if i < len(tokens) && tokens[i].isIdent() {
+		return i
+	}
+	return -1
+}
+
+// tableOption returns the value of a table option such as ROW_FORMAT, or "" if it isn't set
+func tableOption(e *ddlEditor, name string) string {
+	for i, t := range e.tokens {
+		if t.isKeyword(name) {
+			if value := optionValue(e.text, e.tokens, i); value >= 0 {
+				return e.tokens[value].Value
+			}
+		}
+	}
+	return ""
+}
+
+// convertColumn rewrites the character set and collation of a text column whose charset is
+// legacy, explicitly or through the table default, returning the charset it had
+func (c *CharsetConverter) convertColumn(e *ddlEditor, tableCharset string) (string, bool) {
+	tokens := e.tokens
+	if !mysqlTextTypes[strings.ToLower(tokens[1].Value)] {
+		return "", false
+	}
+	charsetAt, collateAt := -1, -1
+	for i := 2; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isPunct(e.text, '('):
+			// Type lengths, ENUM values and generation expressions
+			i = closingParen(e.text, tokens, i)
+		case tokens[i].isKeyword("CHARSET"), tokens[i].isKeyword("SET") && tokens[i-1].isKeyword("CHARACTER"):
+			charsetAt = optionValue(e.text, tokens, i)
+		case tokens[i].isKeyword("COLLATE"):
+			collateAt = optionValue(e.text, tokens, i)
+		}
+	}
+
+	charset := tableCharset
+	switch {
+	case charsetAt >= 0:
+		charset = strings.ToLower(tokens[charsetAt].Value)
+	case collateAt >= 0:
+		charset, _, _ = strings.Cut(strings.ToLower(tokens[collateAt].Value), "_")
+	}
+	if !legacyCharsets[charset] {
+		return "", false
+	}
+
+	switch {
+	case charsetAt >= 0 && collateAt >= 0:
+		e.replace(charsetAt, charsetAt, c.conv.Charset)
+		e.replace(collateAt, collateAt, c.conv.collationFor(tokens[collateAt].Value))
+	case charsetAt >= 0:
+		e.replace(charsetAt, charsetAt, c.conv.Charset+" COLLATE "+c.conv.Collation)
+	case collateAt >= 0:
+		e.replace(collateAt, collateAt, c.conv.collationFor(tokens[collateAt].Value))
+	}
+	return charset, true
+}
+
+// columnCharLength returns the length of a char or varchar column, or 0 for types that can only
+// be indexed by prefix
+func columnCharLength(c ddlClause) int {
+	tokens := c.tokens
+	if len(tokens) < 5 || !tokens[2].isPunct(c.Text, '(') {
+		return 0
+	}
+	switch strings.ToLower(tokens[1].Value) {
+	case "char", "varchar":
+		n, _ := strconv.Atoi(tokens[3].Value)
+		return n
+	}
+	return 0
+}
+
+// keyPart is one column of an index key with its prefix length, if any
+type keyPart struct {
+	column string
+	prefix int
+}
+
+// keyParts returns the column key parts of the first parenthesized key list in tokens
+// Functional key parts are left out
+func keyParts(text string, tokens []sqlToken) []keyPart {
+	open := -1
+	for i, t := range tokens {
+		if t.isPunct(text, '(') {
+			open = i
+			break
+		}
+	}
+	if open < 0 {
+		return nil
+	}
+	var parts []keyPart
+	end := closingParen(text, tokens, open)
+	start := open + 1
+	for i := start; i <= end; i++ {
+		if i < end && tokens[i].isPunct(text, '(') {
+			i = closingParen(text, tokens, i)
+			continue
+		}
+		if i < end && !tokens[i].isPunct(text, ',') {
+			continue
+		}
+		part := tokens[start:i]
+		start = i + 1
+		if len(part) == 0 || !part[0].isIdent() {
+			continue
+		}
+		p := keyPart{column: part[0].Value}
+		if len(part) >= 4 && part[1].isPunct(text, '(') && part[3].isPunct(text, ')') {
+			p.prefix, _ = strconv.Atoi(part[2].Value)
+		}
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+// checkKey records an index whose converted key parts exceed the per-part or per-index limit
+func (c *CharsetConverter) checkKey(table, index string, parts []keyPart, lengths map[string]int, partLimit int) {
+	total, longest := 0, 0
+	for _, p := range parts {
+		chars, converted := lengths[p.column]
+		if !converted {
+			continue
+		}
+		if p.prefix > 0 {
+			chars = p.prefix
+		}
+		bytes := chars * utf8mb4MaxBytes
+		total += bytes
+		if bytes > longest {
+			longest = bytes
+		}
+	}
+	switch {
+	case longest > partLimit:
+		c.overflows = append(c.overflows, KeyOverflow{Table: table, Index: index, Bytes: longest, Limit: partLimit})
+	case total > indexKeyLimit:
+		c.overflows = append(c.overflows, KeyOverflow{Table: table, Index: index, Bytes: total, Limit: indexKeyLimit})
+	}
+}
@@ -0,0 +1,133 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCharsetConversion(t *testing.T) {
+	tests := []struct {
+		charset, collation string
+		want               CharsetConversion
+		wantErr            bool
+	}{
+		{"", "", CharsetConversion{}, false},
+		{"utf8mb4", "", CharsetConversion{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"}, false},
+		{"UTF8MB4", "utf8mb4_0900_ai_ci", CharsetConversion{Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"}, false},
+		{"utf8mb4", "latin1_swedish_ci", CharsetConversion{}, true},
+		{"latin1", "", CharsetConversion{}, true},
+		{"", "utf8mb4_bin", CharsetConversion{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCharsetConversion(tt.charset, tt.collation)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCharsetConversion(%q, %q) error = %v, wantErr %v", tt.charset, tt.collation, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCharsetConversion(%q, %q) = %+v, want %+v", tt.charset, tt.collation, got, tt.want)
+		}
+	}
+}
+
+func TestCharsetConverter_ConvertTable(t *testing.T) {
+	table := TableSchema{
+		Name: "users",
+		CreateStmt: "CREATE TABLE `users` (\n" +
+			"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+			"  `email` varchar(255) NOT NULL,\n" +
+			"  `token` char(64) CHARACTER SET ascii NOT NULL,\n" +
+			"  `name` varchar(100) CHARACTER SET utf8 DEFAULT NULL,\n" +
+			"  `code` varchar(20) COLLATE latin1_bin DEFAULT NULL,\n" +
+			"  `bio` text,\n" +
+			"  `role` enum('admin','user') NOT NULL DEFAULT 'user',\n" +
+			"  PRIMARY KEY (`id`)\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=latin1 ROW_FORMAT=COMPACT",
+		Indexes: []IndexDef{
+			{Name: "users_email_unique", Definition: "ADD UNIQUE INDEX `users_email_unique` (`email`)"},
+			{Name: "users_bio_index", Definition: "ADD INDEX `users_bio_index` (`bio`(100))"},
+			{Name: "users_token_index", Definition: "ADD INDEX `users_token_index` (`token`)"},
+		},
+	}
+
+	c := NewCharsetConverter(CharsetConversion{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"})
+	got, err := c.ConvertTable(table)
+	if err != nil {
+		t.Fatalf("ConvertTable() error: %v", err)
+	}
+
+	want := "CREATE TABLE `users` (\n" +
+		"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `email` varchar(255) NOT NULL,\n" +
+		"  `token` char(64) CHARACTER SET ascii NOT NULL,\n" +
+		"  `name` varchar(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci DEFAULT NULL,\n" +
+		"  `code` varchar(20) COLLATE utf8mb4_bin DEFAULT NULL,\n" +
+		"  `bio` text,\n" +
+		"  `role` enum('admin','user') NOT NULL DEFAULT 'user',\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=COMPACT"
+	if got.CreateStmt != want {
+		t.Errorf("ConvertTable() =\n%s\nwant\n%s", got.CreateStmt, want)
+	}
+
+	wantColumns := []ConvertedColumn{
+		{Table: "users", Column: "email", From: "latin1"},
+		{Table: "users", Column: "name", From: "utf8"},
+		{Table: "users", Column: "code", From: "latin1"},
+		{Table: "users", Column: "bio", From: "latin1"},
+		{Table: "users", Column: "role", From: "latin1"},
+	}
+	if !reflect.DeepEqual(c.ConvertedColumns(), wantColumns) {
+		t.Errorf("ConvertedColumns() = %+v, want %+v", c.ConvertedColumns(), wantColumns)
+	}
+
+	// varchar(255) takes 1020 bytes as utf8mb4, over COMPACT's 767 byte key part limit
+	wantOverflows := []KeyOverflow{{Table: "users", Index: "users_email_unique", Bytes: 1020, Limit: 767}}
+	if !reflect.DeepEqual(c.KeyOverflows(), wantOverflows) {
+		t.Errorf("KeyOverflows() = %+v, want %+v", c.KeyOverflows(), wantOverflows)
+	}
+}
+
+func TestCharsetConverter_IndexLimit(t *testing.T) {
+	table := TableSchema{
+		Name: "pages",
+		CreateStmt: "CREATE TABLE `pages` (\n" +
+			"  `site` varchar(500) NOT NULL,\n" +
+			"  `path` varchar(500) NOT NULL,\n" +
+			"  PRIMARY KEY (`site`,`path`)\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8mb3_general_ci",
+	}
+
+	c := NewCharsetConverter(CharsetConversion{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"})
+	got, err := c.ConvertTable(table)
+	if err != nil {
+		t.Fatalf("ConvertTable() error: %v", err)
+	}
+	if want := ") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci"; got.CreateStmt[len(got.CreateStmt)-len(want):] != want {
+		t.Errorf("ConvertTable() = %s, want it to end in %s", got.CreateStmt, want)
+	}
+
+	// Each part fits DYNAMIC's 3072 bytes but together they don't
+	wantOverflows := []KeyOverflow{{Table: "pages", Index: "PRIMARY", Bytes: 4000, Limit: 3072}}
+	if !reflect.DeepEqual(c.KeyOverflows(), wantOverflows) {
+		t.Errorf("KeyOverflows() = %+v, want %+v", c.KeyOverflows(), wantOverflows)
+	}
+}
+
+func TestCharsetConverter_LeavesUTF8MB4Alone(t *testing.T) {
+	stmt := "CREATE TABLE `t` (\n  `a` varchar(10) DEFAULT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci"
+	c := NewCharsetConverter(CharsetConversion{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"})
+	got, err := c.ConvertTable(TableSchema{Name: "t", CreateStmt: stmt})
+	if err != nil {
+		t.Fatalf("ConvertTable() error: %v", err)
+	}
+	if got.CreateStmt != stmt || len(c.ConvertedColumns()) != 0 {
+		t.Errorf("ConvertTable() = %s with %v converted, want it unchanged", got.CreateStmt, c.ConvertedColumns())
+	}
+}
+
+func TestNewCharsetConverter_Disabled(t *testing.T) {
+	if NewCharsetConverter(CharsetConversion{}) != nil {
+		t.Error("NewCharsetConverter() with no charset should be nil")
+	}
+}
@@ -0,0 +1,219 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Definer modes for MySQL views, routines, triggers and events
+const (
+	DefinerStrip       = "strip"        // Drop the clause, so the migrating user becomes the definer
+	DefinerCurrentUser = "current-user" // Write DEFINER=CURRENT_USER
+	DefinerKeep        = "keep"         // Keep the source's definer (needs SET_USER_ID or SUPER)
+	DefinerAccount     = "account"      // Assign every object to one named account
+)
+
+// routineNameRe matches a routine's keyword and (possibly quoted and qualified) name, up to the opening parenthesis
+var routineNameRe = regexp.MustCompile("(?i)\\b(?:PROCEDURE|FUNCTION)\\s+(?:`(?:[^`]|``)*`|[^\\s(.`]+)(?:\\.(?:`(?:[^`]|``)*`|[^\\s(.`]+))?\\s*\\(")
+
+// sqlSecurityRe matches the SQL SECURITY characteristic of views and routines
+var sqlSecurityRe = regexp.MustCompile(`(?i)\bSQL\s+SECURITY\s+(?:DEFINER|INVOKER)\b`)
+
+// DefinerPolicy controls how the DEFINER and SQL SECURITY clauses from SHOW CREATE are
+// written on the destination. The zero value strips the definer and keeps SQL SECURITY.
+type DefinerPolicy struct {
+	Mode        string // One of the Definer* modes, DefinerStrip when empty
+	Account     string // Quoted `user`@`host` used with DefinerAccount
+	SQLSecurity string // "DEFINER" or "INVOKER" to override the source's, empty to keep it
+}
+
+// DefinerRewriter is implemented by extractors that honor a definer policy
+type DefinerRewriter interface {
+	SetDefinerPolicy(policy DefinerPolicy)
+}
+
+// ParseDefinerPolicy builds a policy from the --definer and --sql-security values
+// definer is "strip", "current-user", "keep" or an account such as app@% or 'app'@'%'
+func ParseDefinerPolicy(definer, sqlSecurity string) (DefinerPolicy, error) {
+	var policy DefinerPolicy
+
+	switch d := strings.TrimSpace(definer); strings.ToLower(d) {
+	case "", DefinerStrip:
+		policy.Mode = DefinerStrip
+	case DefinerCurrentUser:
+		policy.Mode = DefinerCurrentUser
+	case DefinerKeep:
+		policy.Mode = DefinerKeep
+	default:
+		user, host, ok := strings.Cut(d, "@")
+		user, host = unquoteAccountPart(user), unquoteAccountPart(host)
+		if !ok || user == "" || host == "" {
+			return policy, fmt.Errorf("invalid definer %q: want strip, current-user, keep or user@host", definer)
+		}
+		policy.Mode = DefinerAccount
+		policy.Account = fmt.Sprintf("`%s`@`%s`", strings.ReplaceAll(user, "`", "``"), strings.ReplaceAll(host, "`", "``"))
+	}
+
+	switch s := strings.ToUpper(strings.TrimSpace(sqlSecurity)); s {
+	case "", "DEFINER", "INVOKER":
+		policy.SQLSecurity = s
+	default:
+		return policy, fmt.Errorf("invalid SQL SECURITY %q: want definer or invoker", sqlSecurity)
+	}
+
+	return policy, nil
+}
+
+// unquoteAccountPart removes backticks or quotes around the user or host of an account
+func unquoteAccountPart(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '`' || s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+// rewriteDefiner applies the definer mode to a SHOW CREATE statement
+func (p DefinerPolicy) rewriteDefiner(stmt string) string {
+	switch p.Mode {
+	case DefinerKeep:
+		return stmt
+	case DefinerCurrentUser:
+		return replaceDefiner(stmt, "CURRENT_USER")
+	case DefinerAccount:
+		return replaceDefiner(stmt, p.Account)
+	default:
+		// The migrating user becomes the definer, since it usually can't create objects
+		// on behalf of the source's accounts
+		return replaceDefiner(stmt, "")
+	}
+}
+
+// definedObjectKeywords end the header of a CREATE statement, where its DEFINER clause is
+var definedObjectKeywords = map[string]bool{
+	"VIEW": true, "PROCEDURE": true, "FUNCTION": true, "TRIGGER": true, "EVENT": true,
+}
+
+// replaceDefiner swaps the DEFINER clause in a CREATE statement's header for the given
+// account, or removes it when account is empty
+// The body is never touched, so DEFINER in a string, comment or column name stays as it is
+func replaceDefiner(stmt, account string) string {
+	tokens := tokenizeMySQL(stmt, "")
+	for n, t := range tokens {
+		if t.Kind == tokIdent && definedObjectKeywords[strings.ToUpper(t.Value)] {
+			return stmt
+		}
+		if !t.isKeyword("DEFINER") {
+			continue
+		}
+
+		// DEFINER = account, where the account runs up to the next space or comment
+		next := n + 1
+		for next < len(tokens) && tokens[next].Kind == tokSpace {
+			next++
+		}
+		if next == len(tokens) || !tokens[next].isPunct(stmt, '=') {
+			continue
+		}
+		next++
+		for next < len(tokens) && tokens[next].Kind == tokSpace {
+			next++
+		}
+		end := next
+		for end < len(tokens) && tokens[end].Kind != tokSpace && tokens[end].Kind != tokComment {
+			end++
+		}
+		if end == next {
+			return stmt
+		}
+
+		// The clause takes the whitespace before it along, as SHOW CREATE writes one space
+		start := t.Start
+		if n > 0 && tokens[n-1].Kind == tokSpace {
+			start = tokens[n-1].Start
+		}
+		clause := ""
+		if account != "" {
+			clause = " DEFINER=" + account
+		}
+		return stmt[:start] + clause + stmt[tokens[end-1].End:]
+	}
+	return stmt
+}
+
+// rewriteView applies the policy to a SHOW CREATE VIEW statement
+// SHOW CREATE VIEW always spells out SQL SECURITY, so overriding it is a replacement
+func (p DefinerPolicy) rewriteView(stmt string) string {
+	stmt = p.rewriteDefiner(stmt)
+	if p.SQLSecurity != "" {
+		stmt = replaceFirst(sqlSecurityRe, stmt, "SQL SECURITY "+p.SQLSecurity)
+	}
+	return stmt
+}
+
+// rewriteRoutine applies the policy to a SHOW CREATE PROCEDURE/FUNCTION/EVENT statement
+// SHOW CREATE omits SQL SECURITY DEFINER (the default), so an INVOKER override is inserted
+// as the first characteristic, on the line after the parameter list and RETURNS clause
+func (p DefinerPolicy) rewriteRoutine(kind, stmt string) string {
+	stmt = p.rewriteDefiner(stmt)
+	if p.SQLSecurity == "" || kind == "event" {
+		return stmt
+	}
+	if sqlSecurityRe.MatchString(stmt) {
+		return replaceFirst(sqlSecurityRe, stmt, "SQL SECURITY "+p.SQLSecurity)
+	}
+	if p.SQLSecurity == "DEFINER" {
+		return stmt
+	}
+
+	end := routineSignatureEnd(stmt)
+	if end < 0 {
+		return stmt
+	}
+	return stmt[:end] + "\n    SQL SECURITY " + p.SQLSecurity + stmt[end:]
+}
+
+// routineSignatureEnd returns the end of the line holding the closing parenthesis of a
+// routine's parameter list, or -1 if the list can't be found
+func routineSignatureEnd(stmt string) int {
+	loc := routineNameRe.FindStringIndex(stmt)
+	if loc == nil {
+		return -1
+	}
+	start := loc[1] - 1
+
+	depth := 0
+	var quote byte
+	for i := start; i < len(stmt); i++ {
+		c := stmt[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				if nl := strings.IndexByte(stmt[i:], '\n'); nl >= 0 {
+					return i + nl
+				}
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// replaceFirst replaces the first match of re in s
+func replaceFirst(re *regexp.Regexp, s, repl string) string {
+	loc := re.FindStringIndex(s)
+	if loc == nil {
+		return s
+	}
+	return s[:loc[0]] + repl + s[loc[1]:]
+}
@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestParseDefinerPolicy(t *testing.T) {
+	tests := []struct {
+		definer     string
+		sqlSecurity string
+		want        DefinerPolicy
+		wantErr     bool
+	}{
+		{"", "", DefinerPolicy{Mode: DefinerStrip}, false},
+		{"current-user", "invoker", DefinerPolicy{Mode: DefinerCurrentUser, SQLSecurity: "INVOKER"}, false},
+		{"KEEP", "Definer", DefinerPolicy{Mode: DefinerKeep, SQLSecurity: "DEFINER"}, false},
+		{"app@%", "", DefinerPolicy{Mode: DefinerAccount, Account: "`app`@`%`"}, false},
+		{"'laravel'@'10.0.%'", "", DefinerPolicy{Mode: DefinerAccount, Account: "`laravel`@`10.0.%`"}, false},
+		{"nobody", "", DefinerPolicy{}, true},
+		{"app@", "", DefinerPolicy{}, true},
+		{"strip", "sometimes", DefinerPolicy{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDefinerPolicy(tt.definer, tt.sqlSecurity)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDefinerPolicy(%q, %q) error = %v, wantErr %v", tt.definer, tt.sqlSecurity, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseDefinerPolicy(%q, %q) = %+v, want %+v", tt.definer, tt.sqlSecurity, got, tt.want)
+		}
+	}
+}
+
+func TestDefinerPolicy_RewriteView(t *testing.T) {
+	stmt := "CREATE ALGORITHM=UNDEFINED DEFINER=`olduser`@`%` SQL SECURITY DEFINER VIEW `v` AS select 1 AS `1`"
+
+	tests := []struct {
+		policy DefinerPolicy
+		want   string
+	}{
+		{DefinerPolicy{}, "CREATE ALGORITHM=UNDEFINED SQL SECURITY DEFINER VIEW `v` AS select 1 AS `1`"},
+		{DefinerPolicy{Mode: DefinerKeep}, stmt},
+		{DefinerPolicy{Mode: DefinerCurrentUser, SQLSecurity: "INVOKER"}, "CREATE ALGORITHM=UNDEFINED DEFINER=CURRENT_USER SQL SECURITY INVOKER VIEW `v` AS select 1 AS `1`"},
+		{DefinerPolicy{Mode: DefinerAccount, Account: "`app`@`%`"}, "CREATE ALGORITHM=UNDEFINED DEFINER=`app`@`%` SQL SECURITY DEFINER VIEW `v` AS select 1 AS `1`"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.rewriteView(stmt); got != tt.want {
+			t.Errorf("%+v.rewriteView() = %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestDefinerPolicy_RewriteRoutine(t *testing.T) {
+	invoker := DefinerPolicy{Mode: DefinerCurrentUser, SQLSecurity: "INVOKER"}
+
+	tests := []struct {
+		name   string
+		policy DefinerPolicy
+		kind   string
+		stmt   string
+		want   string
+	}{
+		{
+			name:   "function gains SQL SECURITY after RETURNS",
+			policy: invoker,
+			kind:   "function",
+			stmt:   "CREATE DEFINER=`root`@`localhost` FUNCTION `total`(a DECIMAL(10,2), b INT) RETURNS decimal(10,2)\n    DETERMINISTIC\nRETURN a * b",
+			want:   "CREATE DEFINER=CURRENT_USER FUNCTION `total`(a DECIMAL(10,2), b INT) RETURNS decimal(10,2)\n    SQL SECURITY INVOKER\n    DETERMINISTIC\nRETURN a * b",
+		},
+		{
+			name:   "procedure name with parenthesis",
+			policy: invoker,
+			kind:   "procedure",
+			stmt:   "CREATE DEFINER=`root`@`localhost` PROCEDURE `odd(name)`(IN x INT)\nBEGIN SELECT x; END",
+			want:   "CREATE DEFINER=CURRENT_USER PROCEDURE `odd(name)`(IN x INT)\n    SQL SECURITY INVOKER\nBEGIN SELECT x; END",
+		},
+		{
+			name:   "existing clause is replaced",
+			policy: DefinerPolicy{SQLSecurity: "DEFINER"},
+			kind:   "procedure",
+			stmt:   "CREATE DEFINER=`root`@`localhost` PROCEDURE `p`()\n    SQL SECURITY INVOKER\nBEGIN END",
+			want:   "CREATE PROCEDURE `p`()\n    SQL SECURITY DEFINER\nBEGIN END",
+		},
+		{
+			name:   "events have no SQL SECURITY",
+			policy: invoker,
+			kind:   "event",
+			stmt:   "CREATE DEFINER=`root`@`localhost` EVENT `e` ON SCHEDULE EVERY 1 DAY DO CALL p()",
+			want:   "CREATE DEFINER=CURRENT_USER EVENT `e` ON SCHEDULE EVERY 1 DAY DO CALL p()",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.rewriteRoutine(tt.kind, tt.stmt); got != tt.want {
+				t.Errorf("rewriteRoutine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMySQLExtractor_ExtractViews_DefinerPolicy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewMySQLExtractor()
+	ext.SetDefinerPolicy(DefinerPolicy{Mode: DefinerAccount, Account: "`laravel`@`%`", SQLSecurity: "INVOKER"})
+
+	mock.ExpectQuery("SELECT table_name, view_definition FROM information_schema.views").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "view_definition"}).
+			AddRow("user_stats", "select 1"))
+	mock.ExpectQuery("SHOW CREATE VIEW `testdb`.`user_stats`").
+		WillReturnRows(sqlmock.NewRows([]string{"View", "Create View", "character_set_client", "collation_connection"}).
+			AddRow("user_stats", "CREATE ALGORITHM=UNDEFINED DEFINER=`olduser`@`%` SQL SECURITY DEFINER VIEW `user_stats` AS select 1", "utf8mb4", "utf8mb4_unicode_ci"))
+	mock.ExpectQuery("SELECT view_name, table_name.*FROM information_schema.view_table_usage").
+		WillReturnRows(sqlmock.NewRows([]string{"view_name", "table_name"}))
+
+	views, err := ext.ExtractViews(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractViews() error = %v", err)
+	}
+	want := "CREATE ALGORITHM=UNDEFINED DEFINER=`laravel`@`%` SQL SECURITY INVOKER VIEW `user_stats` AS select 1"
+	if len(views) != 1 || views[0].CreateStmt != want {
+		t.Errorf("ExtractViews() = %+v, want CreateStmt %q", views, want)
+	}
+}
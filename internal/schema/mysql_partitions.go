@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Partitioning modes for partitioned MySQL tables
+const (
+	PartitioningKeep = "keep" // Recreate the source's partitioning on the destination
+	PartitioningDrop = "drop" // Create the destination table without partitioning
+)
+
+// PartitionDef is one partition of a partitioned MySQL table; subpartitions are folded into it
+type PartitionDef struct {
+	Name        string
+	Method      string // RANGE, LIST, HASH, KEY, RANGE COLUMNS, LIST COLUMNS, LINEAR HASH or LINEAR KEY
+	Expression  string // Partitioning expression or column list
+	Description string // VALUES LESS THAN or VALUES IN bound, empty for HASH and KEY
+	Rows        int64  // Estimated row count
+	DataLength  int64
+	IndexLength int64
+}
+
+// Partitioner is implemented by extractors that can drop partitioning from the tables they extract
+type Partitioner interface {
+	SetPartitioning(mode string)
+}
+
+// ParsePartitioning validates a --partitioning value, defaulting to PartitioningKeep
+func ParsePartitioning(mode string) (string, error) {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case "":
+		return PartitioningKeep, nil
+	case PartitioningKeep, PartitioningDrop:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid partitioning %q: want keep or drop", mode)
+	}
+}
+
+// SetPartitioning sets whether partitioned tables keep their partitioning on the destination
+func (e *MySQLExtractor) SetPartitioning(mode string) {
+	e.Partitioning = mode
+}
+
+// extractPartitions reads a table's partitions from information_schema.partitions
+func (e *MySQLExtractor) extractPartitions(db *sql.DB, database, tableName string) ([]PartitionDef, error) {
+	rows, err := db.Query(`
+		SELECT
+			partition_name,
+			partition_method,
+			COALESCE(partition_expression, ''),
+			COALESCE(partition_description, ''),
+			SUM(table_rows),
+			SUM(data_length),
+			SUM(index_length)
+		FROM information_schema.partitions
+		WHERE table_schema = ? AND table_name = ? AND partition_name IS NOT NULL
+		GROUP BY partition_name, partition_ordinal_position, partition_method, partition_expression, partition_description
+		ORDER BY partition_ordinal_position
+	`, database, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []PartitionDef
+	for rows.Next() {
+		var p PartitionDef
+		if err := rows.Scan(&p.Name, &p.Method, &p.Expression, &p.Description, &p.Rows, &p.DataLength, &p.IndexLength); err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, p)
+	}
+	return partitions, rows.Err()
+}
+
+// partitionClause returns the offsets of the PARTITION BY clause of a SHOW CREATE TABLE
+// statement, including the /*!50100 ... */ wrapper MySQL puts around it, or -1, -1 if there's none
+func partitionClause(createStmt string) (int, int) {
+	ddl, err := parseCreateTable(createStmt, mysqlSyntax(""))
+	if err != nil || ddl.Suffix == "" {
+		return -1, -1
+	}
+	offset := len(createStmt) - len(ddl.Suffix)
+	tokens := tokenizeSQL(ddl.Suffix, mysqlSyntax(""))
+
+	marker := -1 // Start of an executable comment holding the clause
+	for i, t := range tokens {
+		switch {
+		case t.Kind == tokOther && strings.HasPrefix(ddl.Suffix[t.Start:t.End], "/*!"):
+			marker = t.Start
+		case t.isKeyword("PARTITION") && nextKeyword(tokens, i, "BY"):
+			if marker < 0 {
+				return offset + t.Start, len(createStmt)
+			}
+			// The comment ends at the first */ outside strings and quoted names
+			for j := i + 1; j+1 < len(tokens); j++ {
+				if tokens[j].isPunct(ddl.Suffix, '*') && tokens[j+1].isPunct(ddl.Suffix, '/') && tokens[j].End == tokens[j+1].Start {
+					return offset + marker, offset + tokens[j+1].End
+				}
+			}
+			return offset + marker, len(createStmt)
+		case t.Kind != tokSpace && t.Kind != tokComment:
+			marker = -1
+		}
+	}
+	return -1, -1
+}
+
+// nextKeyword reports whether the next significant token after tokens[i] is the given keyword
+func nextKeyword(tokens []sqlToken, i int, keyword string) bool {
+	for _, t := range tokens[i+1:] {
+		if t.Kind != tokSpace && t.Kind != tokComment {
+			return t.isKeyword(keyword)
+		}
+	}
+	return false
+}
+
+// removePartitioning drops the PARTITION BY clause from a SHOW CREATE TABLE statement
+func removePartitioning(createStmt string) string {
+	start, end := partitionClause(createStmt)
+	if start < 0 {
+		return createStmt
+	}
+	return strings.TrimRight(createStmt[:start], " \t\r\n") + createStmt[end:]
+}
@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const mysqlLogsDDL = "CREATE TABLE `logs` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `created_at` datetime NOT NULL,\n" +
+	"  PRIMARY KEY (`id`,`created_at`)\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='PARTITION BY day */'\n" +
+	"/*!50100 PARTITION BY RANGE (year(`created_at`))\n" +
+	"(PARTITION p2023 VALUES LESS THAN (2024) ENGINE = InnoDB,\n" +
+	" PARTITION `p*/` VALUES LESS THAN MAXVALUE ENGINE = InnoDB) */"
+
+func TestParsePartitioning(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    string
+		wantErr bool
+	}{
+		{"", PartitioningKeep, false},
+		{"keep", PartitioningKeep, false},
+		{" DROP ", PartitioningDrop, false},
+		{"merge", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePartitioning(tt.mode)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParsePartitioning(%q) = %q, %v, want %q, wantErr %v", tt.mode, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRemovePartitioning(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "executable comment",
+			input: mysqlLogsDDL,
+			want: "CREATE TABLE `logs` (\n" +
+				"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+				"  `created_at` datetime NOT NULL,\n" +
+				"  PRIMARY KEY (`id`,`created_at`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='PARTITION BY day */'",
+		},
+		{
+			name:  "plain clause",
+			input: "CREATE TABLE `t` (`id` int) ENGINE=InnoDB PARTITION BY HASH (`id`) PARTITIONS 4",
+			want:  "CREATE TABLE `t` (`id` int) ENGINE=InnoDB",
+		},
+		{
+			name:  "not partitioned",
+			input: "CREATE TABLE `t` (`partition` int) ENGINE=InnoDB COMMENT='PARTITION BY nothing'",
+			want:  "CREATE TABLE `t` (`partition` int) ENGINE=InnoDB COMMENT='PARTITION BY nothing'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := removePartitioning(tt.input); got != tt.want {
+				t.Errorf("removePartitioning() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMySQLExtractor_ExtractTables_Partitioned(t *testing.T) {
+	partitionColumns := []string{"partition_name", "partition_method", "partition_expression", "partition_description", "table_rows", "data_length", "index_length"}
+
+	for _, mode := range []string{PartitioningKeep, PartitioningDrop} {
+		t.Run(mode, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock: %v", err)
+			}
+			defer db.Close()
+
+			ext := NewMySQLExtractor()
+			ext.SetPartitioning(mode)
+
+			mock.ExpectQuery("SELECT table_name FROM information_schema.tables").
+				WithArgs("testdb").
+				WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("logs"))
+			mock.ExpectQuery("SHOW CREATE TABLE `testdb`.`logs`").
+				WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow("logs", mysqlLogsDDL))
+			mock.ExpectQuery("SELECT.*partition_name.*FROM information_schema.partitions").
+				WithArgs("testdb", "logs").
+				WillReturnRows(sqlmock.NewRows(partitionColumns).
+					AddRow("p2023", "RANGE", "year(`created_at`)", "2024", 1200, 16384, 0).
+					AddRow("p*/", "RANGE", "year(`created_at`)", "MAXVALUE", 30, 16384, 0))
+			mock.ExpectQuery("SELECT index_name, column_name.*FROM information_schema.statistics").
+				WithArgs("testdb", "logs").
+				WillReturnRows(sqlmock.NewRows(mysqlStatisticsColumns))
+			mock.ExpectQuery("SELECT.*kcu.constraint_name.*FROM information_schema.key_column_usage").
+				WithArgs("testdb", "logs").
+				WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "referenced_table_name", "ref_columns", "delete_rule", "update_rule"}))
+
+			tables, err := ext.ExtractTables(db, "testdb")
+			if err != nil {
+				t.Fatalf("ExtractTables() error = %v", err)
+			}
+			if len(tables) != 1 {
+				t.Fatalf("ExtractTables() returned %d tables, want 1", len(tables))
+			}
+
+			table := tables[0]
+			want := []PartitionDef{
+				{Name: "p2023", Method: "RANGE", Expression: "year(`created_at`)", Description: "2024", Rows: 1200, DataLength: 16384},
+				{Name: "p*/", Method: "RANGE", Expression: "year(`created_at`)", Description: "MAXVALUE", Rows: 30, DataLength: 16384},
+			}
+			if !reflect.DeepEqual(table.Partitions, want) {
+				t.Errorf("Partitions = %+v, want %+v", table.Partitions, want)
+			}
+			dropped := mode == PartitioningDrop
+			if table.PartitioningDropped != dropped || (table.CreateStmt == mysqlLogsDDL) == dropped {
+				t.Errorf("PartitioningDropped = %v with CreateStmt %q, want dropped %v", table.PartitioningDropped, table.CreateStmt, dropped)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("expectations not met: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// databaseContextKeywords are keywords after which a two-part name is database.object
+var databaseContextKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "STRAIGHT_JOIN": true, "INTO": true, "UPDATE": true, "TABLE": true, "CALL": true,
+}
+
+// tableListEnds are keywords that end the comma-separated table list of a FROM or UPDATE
+var tableListEnds = map[string]bool{
+	"WHERE": true, "ON": true, "USING": true, "SET": true, "GROUP": true, "ORDER": true, "HAVING": true,
+	"LIMIT": true, "WINDOW": true, "UNION": true, "SELECT": true, "VALUES": true,
+}
+
+// rewriteDatabaseQualifiers replaces database qualifiers naming source with dest
+// Only names in a database's position are rewritten: the first part of a three-part name,
+// database.object after FROM, JOIN, INTO, UPDATE, TABLE or CALL or in the table list of a FROM
+// or UPDATE, and database.function before a parenthesis. A two-part table.column elsewhere is
+// left alone even when the table is named like the database, and so are strings and comments.
+// It also returns the other databases the text references in those positions, for reporting.
+func rewriteDatabaseQualifiers(text, sqlMode, source, dest string) (string, []string) {
+	tokens := tokenizeMySQL(text, sqlMode)
+
+	// significant lists the indexes of tokens that aren't whitespace or comments
+	var significant []int
+	for i, t := range tokens {
+		if t.Kind != tokSpace && t.Kind != tokComment {
+			significant = append(significant, i)
+		}
+	}
+	tok := func(n int) (sqlToken, bool) {
+		if n < 0 || n >= len(significant) {
+			return sqlToken{}, false
+		}
+		return tokens[significant[n]], true
+	}
+	isDot := func(n int) bool {
+		t, ok := tok(n)
+		return ok && t.Kind == tokOther && text[t.Start:t.End] == "."
+	}
+
+	var b strings.Builder
+	others := make(map[string]bool)
+	last := 0
+	tableList := false // Inside the comma-separated tables of a FROM or UPDATE
+	for n := range significant {
+		t, _ := tok(n)
+		if t.Kind == tokIdent {
+			switch keyword := strings.ToUpper(t.Value); {
+			case keyword == "FROM" || keyword == "UPDATE":
+				tableList = true
+			case tableListEnds[keyword]:
+				tableList = false
+			}
+		} else if t.Kind == tokOther && strings.ContainsAny(text[t.Start:t.End], ");") {
+			tableList = false
+		}
+
+		// A qualifier is an identifier followed by a dot and another identifier,
+		// and not itself preceded by a dot
+		if !t.isIdent() || isDot(n-1) || !isDot(n+1) {
+			continue
+		}
+		next, ok := tok(n + 2)
+		if !ok || !next.isIdent() {
+			continue
+		}
+
+		prev, _ := tok(n - 1)
+		after, _ := tok(n + 3)
+		threePart := isDot(n + 3)
+		call := after.Kind == tokOther && text[after.Start:after.End] == "("
+		inContext := prev.Kind == tokIdent && databaseContextKeywords[strings.ToUpper(prev.Value)]
+		listed := tableList && prev.Kind == tokOther && strings.ContainsAny(text[prev.Start:prev.End], ",(")
+		if !threePart && !call && !inContext && !listed {
+			continue
+		}
+
+		if t.Value == source {
+			if source != dest {
+				b.WriteString(text[last:t.Start])
+				b.WriteString(quoteMySQLIdent(dest))
+				last = t.End
+			}
+			continue
+		}
+		others[t.Value] = true
+	}
+	b.WriteString(text[last:])
+
+	var names []string
+	for name := range others {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return b.String(), names
+}
+
+// quoteMySQLIdent quotes a MySQL identifier with backticks
+func quoteMySQLIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// DatabaseRenamer is implemented by appliers that rewrite references to the source database
+// in view, routine and trigger bodies, and report references they can't resolve
+type DatabaseRenamer interface {
+	SetDatabaseRename(source, dest string)
+	UnresolvedReferences() []string
+}
+
+// SetDatabaseRename makes the applier rewrite source database qualifiers to dest
+func (a *MySQLApplier) SetDatabaseRename(source, dest string) {
+	a.sourceDatabase = source
+	a.destDatabase = dest
+}
+
+// UnresolvedReferences lists objects that reference databases missing on the destination
+func (a *MySQLApplier) UnresolvedReferences() []string {
+	return a.unresolved
+}
+
+// qualifyForDestination rewrites database qualifiers in an object's statement and records
+// references to other databases that don't exist on the destination
+func (a *MySQLApplier) qualifyForDestination(db *sql.DB, object, sqlMode, stmt string) (string, error) {
+	if a.sourceDatabase == "" {
+		return stmt, nil
+	}
+
+	stmt, others := rewriteDatabaseQualifiers(stmt, sqlMode, a.sourceDatabase, a.destDatabase)
+	for _, other := range others {
+		if other == a.destDatabase {
+			continue
+		}
+		exists, ok := a.databaseExists[other]
+		if !ok {
+			var count int
+			if err := db.QueryRow("SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = ?", other).Scan(&count); err != nil {
+				return "", fmt.Errorf("failed to look up database %s referenced by %s: %w", other, object, err)
+			}
+			exists = count > 0
+			if a.databaseExists == nil {
+				a.databaseExists = make(map[string]bool)
+			}
+			a.databaseExists[other] = exists
+		}
+		if !exists {
+			a.unresolved = append(a.unresolved, fmt.Sprintf("%s references database %s, which doesn't exist on the destination", object, other))
+		}
+	}
+	return stmt, nil
+}
@@ -0,0 +1,117 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestTokenizeMySQL_Lossless(t *testing.T) {
+	text := "SELECT `a``b`, 'it''s', \"x\\\"y\" -- note\n/* c */ /*!50001 x */ # end"
+	var b strings.Builder
+	for _, tok := range tokenizeMySQL(text, "") {
+		b.WriteString(text[tok.Start:tok.End])
+	}
+	if b.String() != text {
+		t.Errorf("reassembled tokens = %q, want %q", b.String(), text)
+	}
+}
+
+func TestRewriteDatabaseQualifiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		sqlMode string
+		want    string
+		others  []string
+	}{
+		{
+			name: "view from SHOW CREATE VIEW",
+			text: "CREATE VIEW `v` AS select `shop`.`users`.`id` AS `id` from `shop`.`users` where `shop`.`users`.`name` <> 'shop.users'",
+			want: "CREATE VIEW `v` AS select `shop_prod`.`users`.`id` AS `id` from `shop_prod`.`users` where `shop_prod`.`users`.`name` <> 'shop.users'",
+		},
+		{
+			name: "unquoted names and comments",
+			text: "BEGIN\n  -- copy from shop.orders\n  INSERT INTO shop.audit SELECT * FROM shop . orders;\nEND",
+			want: "BEGIN\n  -- copy from shop.orders\n  INSERT INTO `shop_prod`.audit SELECT * FROM `shop_prod` . orders;\nEND",
+		},
+		{
+			name: "column of a table named like the database is left alone",
+			text: "SELECT `shop`.`shop`.`id`, `x`.`shop` FROM `shop`.`shop` `x`",
+			want: "SELECT `shop_prod`.`shop`.`id`, `x`.`shop` FROM `shop_prod`.`shop` `x`",
+		},
+		{
+			name: "table named like the database",
+			text: "CREATE VIEW `v` AS select `shop`.`id` AS `id`,`o`.`total` AS `total` from (`shop`.`shop` join `shop`.`orders` `o` on((`o`.`shop_id` = `shop`.`id`))) where `shop`.`id` > 0",
+			want: "CREATE VIEW `v` AS select `shop`.`id` AS `id`,`o`.`total` AS `total` from (`shop_prod`.`shop` join `shop_prod`.`orders` `o` on((`o`.`shop_id` = `shop`.`id`))) where `shop`.`id` > 0",
+		},
+		{
+			name: "table lists and routines",
+			text: "UPDATE shop.shop, shop.orders SET shop.total = orders.total WHERE shop.id = orders.shop_id; CALL shop.recount(shop.id); SELECT shop.slug(shop.name) FROM shop",
+			want: "UPDATE `shop_prod`.shop, `shop_prod`.orders SET shop.total = orders.total WHERE shop.id = orders.shop_id; CALL `shop_prod`.recount(shop.id); SELECT `shop_prod`.slug(shop.name) FROM shop",
+		},
+		{
+			name: "double quotes are strings by default",
+			text: `SELECT "shop".x FROM shop.t`,
+			want: "SELECT \"shop\".x FROM `shop_prod`.t",
+		},
+		{
+			name:    "double quotes are identifiers with ANSI_QUOTES",
+			text:    `SELECT "shop"."t"."x" FROM "shop"."t"`,
+			sqlMode: "ANSI_QUOTES,STRICT_TRANS_TABLES",
+			want:    "SELECT `shop_prod`.\"t\".\"x\" FROM `shop_prod`.\"t\"",
+		},
+		{
+			name:   "other databases are reported",
+			text:   "SELECT u.id, analytics.score(u.id), legacy.t.c FROM shop.users u JOIN crm.accounts a ON a.id = u.id",
+			want:   "SELECT u.id, analytics.score(u.id), legacy.t.c FROM `shop_prod`.users u JOIN crm.accounts a ON a.id = u.id",
+			others: []string{"analytics", "crm", "legacy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, others := rewriteDatabaseQualifiers(tt.text, tt.sqlMode, "shop", "shop_prod")
+			if got != tt.want {
+				t.Errorf("rewriteDatabaseQualifiers() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(others, tt.others) {
+				t.Errorf("other databases = %v, want %v", others, tt.others)
+			}
+		})
+	}
+}
+
+func TestMySQLApplier_CreateView_RewritesDatabase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewMySQLApplier()
+	app.SetDatabaseRename("shop", "shop_prod")
+
+	view := ViewDef{Name: "order_report", CreateStmt: "CREATE VIEW `order_report` AS select `o`.`id` AS `id` from (`shop`.`orders` `o` join `reports`.`totals` `t`)"}
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.schemata").
+		WithArgs("reports").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("CREATE VIEW `order_report` AS select `o`.`id` AS `id` from \\(`shop_prod`.`orders` `o` join `reports`.`totals` `t`\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.CreateView(db, view); err != nil {
+		t.Fatalf("CreateView() error = %v", err)
+	}
+
+	unresolved := app.UnresolvedReferences()
+	if len(unresolved) != 1 || !strings.Contains(unresolved[0], "view order_report references database reports") {
+		t.Errorf("UnresolvedReferences() = %v, want view order_report -> reports", unresolved)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ExtractRoutines extracts stored procedures, functions and events using SHOW CREATE
+// Events are returned last so any procedures they call already exist when they're created
+func (e *MySQLExtractor) ExtractRoutines(db *sql.DB, database string) ([]RoutineDef, error) {
+	rows, err := db.Query(`
+		SELECT routine_name, routine_type
+		FROM information_schema.routines
+		WHERE routine_schema = ?
+		ORDER BY routine_type, routine_name
+	`, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routines: %w", err)
+	}
+
+	var routines []RoutineDef
+	for rows.Next() {
+		var routine RoutineDef
+		var routineType string
+		if err := rows.Scan(&routine.Name, &routineType); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		routine.Kind = "function"
+		if routineType == "PROCEDURE" {
+			routine.Kind = "procedure"
+		}
+		routines = append(routines, routine)
+	}
+	rows.Close()
+
+	for i := range routines {
+		r := &routines[i]
+		// SHOW CREATE PROCEDURE/FUNCTION: name, sql_mode, statement, character_set_client, collation_connection, database collation
+		var name, charSet, collation, dbCollation string
+		var createStmt sql.NullString
+		query := fmt.Sprintf("SHOW CREATE %s `%s`.`%s`", routineKeyword(r.Kind), database, r.Name)
+		if err := db.QueryRow(query).Scan(&name, &r.SQLMode, &createStmt, &charSet, &collation, &dbCollation); err != nil {
+			return nil, fmt.Errorf("failed to get CREATE %s for %s: %w", routineKeyword(r.Kind), r.Name, err)
+		}
+		if !createStmt.Valid {
+			return nil, fmt.Errorf("definition of %s %s is hidden (the source user needs SHOW_ROUTINE or SELECT on mysql.proc)", r.Kind, r.Name)
+		}
+		r.CreateStmt = e.Definer.rewriteRoutine(r.Kind, createStmt.String)
+	}
+
+	events, err := e.extractEvents(db, database)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(routines, events...), nil
+}
+
+// extractEvents extracts scheduled events using SHOW CREATE EVENT
+func (e *MySQLExtractor) extractEvents(db *sql.DB, database string) ([]RoutineDef, error) {
+	rows, err := db.Query(`
+		SELECT event_name
+		FROM information_schema.events
+		WHERE event_schema = ?
+		ORDER BY event_name
+	`, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	var events []RoutineDef
+	for rows.Next() {
+		event := RoutineDef{Kind: "event"}
+		if err := rows.Scan(&event.Name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+
+	for i := range events {
+		ev := &events[i]
+		// SHOW CREATE EVENT: name, sql_mode, time_zone, statement, character_set_client, collation_connection, database collation
+		var name, timeZone, createStmt, charSet, collation, dbCollation string
+		query := fmt.Sprintf("SHOW CREATE EVENT `%s`.`%s`", database, ev.Name)
+		if err := db.QueryRow(query).Scan(&name, &ev.SQLMode, &timeZone, &createStmt, &charSet, &collation, &dbCollation); err != nil {
+			return nil, fmt.Errorf("failed to get CREATE EVENT for %s: %w", ev.Name, err)
+		}
+		ev.CreateStmt, ev.Disabled = disableEvent(e.Definer.rewriteRoutine(ev.Kind, createStmt))
+	}
+
+	return events, nil
+}
+
+// disableEvent rewrites a CREATE EVENT statement so the event is created DISABLE, and reports
+// whether the source runs it; the source keeps running its events until the switch-over, so
+// copies that fire on the destination too would apply their changes twice
+func disableEvent(stmt string) (string, bool) {
+	tokens := significantTokens(tokenizeMySQL(stmt, ""))
+	afterName := -1
+	for n, t := range tokens {
+		switch {
+		case afterName < 0:
+			if t.isKeyword("EVENT") {
+				afterName = n + 2
+			}
+		case n < afterName:
+		case t.isKeyword("ENABLE"):
+			return stmt[:t.Start] + "DISABLE" + stmt[t.End:], true
+		case t.isKeyword("DISABLE"):
+			// DISABLE ON SLAVE (or REPLICA) still runs the event on the server it's created on
+			if n+2 < len(tokens) && tokens[n+1].isKeyword("ON") &&
+				(tokens[n+2].isKeyword("SLAVE") || tokens[n+2].isKeyword("REPLICA")) {
+				return stmt[:t.Start] + "DISABLE" + stmt[tokens[n+2].End:], true
+			}
+			return stmt, false
+		case t.isKeyword("COMMENT") || t.isKeyword("DO"):
+			// Without a status the event is created ENABLE
+			return stmt[:t.Start] + "DISABLE " + stmt[t.Start:], true
+		}
+	}
+	return stmt, false
+}
+
+// ExtractTriggers extracts triggers using SHOW CREATE TRIGGER, in firing order per table
+func (e *MySQLExtractor) ExtractTriggers(db *sql.DB, database string) ([]TriggerDef, error) {
+	rows, err := db.Query(`
+		SELECT trigger_name, event_object_table
+		FROM information_schema.triggers
+		WHERE trigger_schema = ?
+		ORDER BY event_object_table, action_timing, event_manipulation, action_order
+	`, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list triggers: %w", err)
+	}
+
+	var triggers []TriggerDef
+	for rows.Next() {
+		var trigger TriggerDef
+		if err := rows.Scan(&trigger.Name, &trigger.Table); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		triggers = append(triggers, trigger)
+	}
+	rows.Close()
+
+	for i := range triggers {
+		t := &triggers[i]
+		// SHOW CREATE TRIGGER: name, sql_mode, statement, character_set_client, collation_connection, database collation, created
+		var name, charSet, collation, dbCollation string
+		var createStmt string
+		var created sql.NullString
+		query := fmt.Sprintf("SHOW CREATE TRIGGER `%s`.`%s`", database, t.Name)
+		if err := db.QueryRow(query).Scan(&name, &t.SQLMode, &createStmt, &charSet, &collation, &dbCollation, &created); err != nil {
+			return nil, fmt.Errorf("failed to get CREATE TRIGGER for %s: %w", t.Name, err)
+		}
+		t.CreateStmt = e.Definer.rewriteDefiner(createStmt)
+	}
+
+	return triggers, nil
+}
+
+// CreateRoutine creates a procedure, function or event under the sql_mode it was defined with
+func (a *MySQLApplier) CreateRoutine(db *sql.DB, routine RoutineDef) error {
+	stmt, err := a.qualifyForDestination(db, routine.Kind+" "+routine.Name, routine.SQLMode, routine.CreateStmt)
+	if err != nil {
+		return err
+	}
+	if err := execWithSQLMode(db, routine.SQLMode, stmt); err != nil {
+		return fmt.Errorf("failed to create %s %s: %w", routine.Kind, routine.Signature(), err)
+	}
+	return nil
+}
+
+// CreateTrigger creates a trigger under the sql_mode it was defined with
+func (a *MySQLApplier) CreateTrigger(db *sql.DB, trigger TriggerDef) error {
+	stmt, err := a.qualifyForDestination(db, "trigger "+trigger.Name, trigger.SQLMode, trigger.CreateStmt)
+	if err != nil {
+		return err
+	}
+	if err := execWithSQLMode(db, trigger.SQLMode, stmt); err != nil {
+		return fmt.Errorf("failed to create trigger %s on %s: %w", trigger.Name, trigger.Table, err)
+	}
+	return nil
+}
+
+// execWithSQLMode runs stmt on a single connection with the session sql_mode temporarily set
+// The sql_mode is captured into the object's definition, so it must match the source's
+func execWithSQLMode(db *sql.DB, mode, stmt string) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var previous string
+	if err := conn.QueryRowContext(ctx, "SELECT @@SESSION.sql_mode").Scan(&previous); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "SET SESSION sql_mode = ?", mode); err != nil {
+		return fmt.Errorf("failed to set sql_mode: %w", err)
+	}
+	_, execErr := conn.ExecContext(ctx, stmt)
+	if _, err := conn.ExecContext(ctx, "SET SESSION sql_mode = ?", previous); err != nil && execErr == nil {
+		return fmt.Errorf("failed to restore sql_mode: %w", err)
+	}
+	return execErr
+}
+
+// routineKeyword returns the SHOW CREATE keyword for a routine kind
+func routineKeyword(kind string) string {
+	if kind == "procedure" {
+		return "PROCEDURE"
+	}
+	return "FUNCTION"
+}
@@ -0,0 +1,268 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMySQLExtractor_ExtractRoutines(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewMySQLExtractor()
+
+	mock.ExpectQuery("SELECT routine_name, routine_type.*FROM information_schema.routines").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"routine_name", "routine_type"}).
+			AddRow("order_total", "FUNCTION").
+			AddRow("archive_orders", "PROCEDURE"))
+
+	mock.ExpectQuery("SHOW CREATE FUNCTION `testdb`.`order_total`").
+		WillReturnRows(sqlmock.NewRows([]string{"Function", "sql_mode", "Create Function", "character_set_client", "collation_connection", "Database Collation"}).
+			AddRow("order_total", "STRICT_TRANS_TABLES", "CREATE DEFINER=`app`@`%` FUNCTION `order_total`(id INT) RETURNS decimal(10,2) DETERMINISTIC RETURN 0", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_0900_ai_ci"))
+
+	mock.ExpectQuery("SHOW CREATE PROCEDURE `testdb`.`archive_orders`").
+		WillReturnRows(sqlmock.NewRows([]string{"Procedure", "sql_mode", "Create Procedure", "character_set_client", "collation_connection", "Database Collation"}).
+			AddRow("archive_orders", "", "CREATE DEFINER=`admin`@`localhost` PROCEDURE `archive_orders`() BEGIN END", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_0900_ai_ci"))
+
+	mock.ExpectQuery("SELECT event_name.*FROM information_schema.events").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"event_name"}).AddRow("nightly_archive"))
+
+	mock.ExpectQuery("SHOW CREATE EVENT `testdb`.`nightly_archive`").
+		WillReturnRows(sqlmock.NewRows([]string{"Event", "sql_mode", "time_zone", "Create Event", "character_set_client", "collation_connection", "Database Collation"}).
+			AddRow("nightly_archive", "NO_ZERO_DATE", "SYSTEM", "CREATE DEFINER=`app`@`%` EVENT `nightly_archive` ON SCHEDULE EVERY 1 DAY DO CALL archive_orders()", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_0900_ai_ci"))
+
+	routines, err := ext.ExtractRoutines(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractRoutines() error = %v", err)
+	}
+	if len(routines) != 3 {
+		t.Fatalf("ExtractRoutines() returned %d routines, want 3", len(routines))
+	}
+
+	wantKinds := []string{"function", "procedure", "event"}
+	for i, r := range routines {
+		if r.Kind != wantKinds[i] {
+			t.Errorf("routines[%d].Kind = %q, want %q", i, r.Kind, wantKinds[i])
+		}
+		if strings.Contains(r.CreateStmt, "DEFINER") {
+			t.Errorf("routines[%d].CreateStmt still has DEFINER: %s", i, r.CreateStmt)
+		}
+	}
+	if routines[0].SQLMode != "STRICT_TRANS_TABLES" {
+		t.Errorf("routines[0].SQLMode = %q, want STRICT_TRANS_TABLES", routines[0].SQLMode)
+	}
+	if got := routines[2].Signature(); got != "nightly_archive" {
+		t.Errorf("event Signature() = %q, want nightly_archive", got)
+	}
+	if want := "CREATE EVENT `nightly_archive` ON SCHEDULE EVERY 1 DAY DISABLE DO CALL archive_orders()"; routines[2].CreateStmt != want || !routines[2].Disabled {
+		t.Errorf("event = %q (Disabled %v), want %q created disabled", routines[2].CreateStmt, routines[2].Disabled, want)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLExtractor_ExtractRoutines_HiddenDefinition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewMySQLExtractor()
+
+	mock.ExpectQuery("SELECT routine_name, routine_type.*FROM information_schema.routines").
+		WillReturnRows(sqlmock.NewRows([]string{"routine_name", "routine_type"}).AddRow("secret", "PROCEDURE"))
+	mock.ExpectQuery("SHOW CREATE PROCEDURE `testdb`.`secret`").
+		WillReturnRows(sqlmock.NewRows([]string{"Procedure", "sql_mode", "Create Procedure", "character_set_client", "collation_connection", "Database Collation"}).
+			AddRow("secret", "", nil, "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_0900_ai_ci"))
+
+	if _, err := ext.ExtractRoutines(db, "testdb"); err == nil {
+		t.Errorf("ExtractRoutines() expected error for hidden definition, got nil")
+	}
+}
+
+func TestMySQLExtractor_ExtractTriggers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewMySQLExtractor()
+
+	mock.ExpectQuery("SELECT trigger_name, event_object_table.*FROM information_schema.triggers").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"trigger_name", "event_object_table"}).AddRow("orders_audit", "orders"))
+	mock.ExpectQuery("SHOW CREATE TRIGGER `testdb`.`orders_audit`").
+		WillReturnRows(sqlmock.NewRows([]string{"Trigger", "sql_mode", "SQL Original Statement", "character_set_client", "collation_connection", "Database Collation", "Created"}).
+			AddRow("orders_audit", "ONLY_FULL_GROUP_BY", "CREATE DEFINER=`app`@`%` TRIGGER `orders_audit` AFTER INSERT ON `orders` FOR EACH ROW INSERT INTO audit VALUES (NEW.id)", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_0900_ai_ci", "2024-01-01 00:00:00.00"))
+
+	triggers, err := ext.ExtractTriggers(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTriggers() error = %v", err)
+	}
+	if len(triggers) != 1 {
+		t.Fatalf("ExtractTriggers() returned %d triggers, want 1", len(triggers))
+	}
+	want := "CREATE TRIGGER `orders_audit` AFTER INSERT ON `orders` FOR EACH ROW INSERT INTO audit VALUES (NEW.id)"
+	if triggers[0].CreateStmt != want {
+		t.Errorf("CreateStmt = %q, want %q", triggers[0].CreateStmt, want)
+	}
+	if triggers[0].Table != "orders" || triggers[0].SQLMode != "ONLY_FULL_GROUP_BY" {
+		t.Errorf("triggers[0] = %+v", triggers[0])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLApplier_CreateRoutine_UsesSourceSQLMode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewMySQLApplier()
+	routine := RoutineDef{
+		Name:       "archive_orders",
+		Kind:       "procedure",
+		SQLMode:    "STRICT_TRANS_TABLES",
+		CreateStmt: "CREATE PROCEDURE `archive_orders`() BEGIN END",
+	}
+
+	mock.ExpectQuery("SELECT @@SESSION.sql_mode").
+		WillReturnRows(sqlmock.NewRows([]string{"sql_mode"}).AddRow("ONLY_FULL_GROUP_BY"))
+	mock.ExpectExec("SET SESSION sql_mode = ?").
+		WithArgs("STRICT_TRANS_TABLES").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE PROCEDURE `archive_orders`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET SESSION sql_mode = ?").
+		WithArgs("ONLY_FULL_GROUP_BY").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.CreateRoutine(db, routine); err != nil {
+		t.Errorf("CreateRoutine() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLApplier_CreateTrigger_RestoresSQLModeOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewMySQLApplier()
+	trigger := TriggerDef{
+		Table:      "orders",
+		Name:       "orders_audit",
+		CreateStmt: "CREATE TRIGGER `orders_audit` AFTER INSERT ON `orders` FOR EACH ROW BEGIN END",
+	}
+
+	mock.ExpectQuery("SELECT @@SESSION.sql_mode").
+		WillReturnRows(sqlmock.NewRows([]string{"sql_mode"}).AddRow("STRICT_TRANS_TABLES"))
+	mock.ExpectExec("SET SESSION sql_mode = ?").
+		WithArgs("").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TRIGGER `orders_audit`").
+		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectExec("SET SESSION sql_mode = ?").
+		WithArgs("STRICT_TRANS_TABLES").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = app.CreateTrigger(db, trigger)
+	if err == nil || !strings.Contains(err.Error(), "orders_audit on orders") {
+		t.Errorf("CreateTrigger() error = %v, want it to name the trigger and table", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestDisableEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     string
+		disabled bool
+	}{
+		{
+			"enabled",
+			"CREATE EVENT `purge` ON SCHEDULE EVERY 1 HOUR STARTS '2024-01-01 00:00:00' ON COMPLETION NOT PRESERVE ENABLE DO DELETE FROM jobs WHERE status = 'ENABLE'",
+			"CREATE EVENT `purge` ON SCHEDULE EVERY 1 HOUR STARTS '2024-01-01 00:00:00' ON COMPLETION NOT PRESERVE DISABLE DO DELETE FROM jobs WHERE status = 'ENABLE'",
+			true,
+		},
+		{
+			"disabled on replicas",
+			"CREATE EVENT `purge` ON SCHEDULE EVERY 1 HOUR ON COMPLETION PRESERVE DISABLE ON SLAVE COMMENT 'hourly' DO DELETE FROM jobs",
+			"CREATE EVENT `purge` ON SCHEDULE EVERY 1 HOUR ON COMPLETION PRESERVE DISABLE COMMENT 'hourly' DO DELETE FROM jobs",
+			true,
+		},
+		{
+			"already disabled",
+			"CREATE EVENT `purge` ON SCHEDULE EVERY 1 HOUR ON COMPLETION NOT PRESERVE DISABLE DO BEGIN UPDATE flags SET enable = 1; END",
+			"CREATE EVENT `purge` ON SCHEDULE EVERY 1 HOUR ON COMPLETION NOT PRESERVE DISABLE DO BEGIN UPDATE flags SET enable = 1; END",
+			false,
+		},
+		{
+			"no status",
+			"CREATE EVENT `purge` ON SCHEDULE EVERY 1 HOUR COMMENT 'hourly' DO DELETE FROM jobs",
+			"CREATE EVENT `purge` ON SCHEDULE EVERY 1 HOUR DISABLE COMMENT 'hourly' DO DELETE FROM jobs",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, disabled := disableEvent(tt.input)
+			if got != tt.want || disabled != tt.disabled {
+				t.Errorf("disableEvent() = %q, %v, want %q, %v", got, disabled, tt.want, tt.disabled)
+			}
+		})
+	}
+}
+
+func TestReplaceDefiner(t *testing.T) {
+	tests := []struct {
+		input   string
+		account string
+		want    string
+	}{
+		{"CREATE DEFINER=`app`@`%` PROCEDURE `p`() BEGIN END", "", "CREATE PROCEDURE `p`() BEGIN END"},
+		{"CREATE DEFINER='root'@'localhost' FUNCTION f() RETURNS INT RETURN 1", "", "CREATE FUNCTION f() RETURNS INT RETURN 1"},
+		{"CREATE DEFINER=CURRENT_USER EVENT e ON SCHEDULE EVERY 1 DAY DO SELECT 1", "", "CREATE EVENT e ON SCHEDULE EVERY 1 DAY DO SELECT 1"},
+		{"CREATE TRIGGER t BEFORE INSERT ON x FOR EACH ROW SET NEW.a = 1", "", "CREATE TRIGGER t BEFORE INSERT ON x FOR EACH ROW SET NEW.a = 1"},
+		{"CREATE DEFINER = `app`@`%` TRIGGER t BEFORE INSERT ON x FOR EACH ROW SET NEW.a = 1", "`ops`@`%`", "CREATE DEFINER=`ops`@`%` TRIGGER t BEFORE INSERT ON x FOR EACH ROW SET NEW.a = 1"},
+		{
+			"CREATE DEFINER=`app`@`%` PROCEDURE `p`()\nBEGIN\n  -- DEFINER=`old`@`%`\n  INSERT INTO grants (note) VALUES ('DEFINER=`x`@`y`');\nEND",
+			"CURRENT_USER",
+			"CREATE DEFINER=CURRENT_USER PROCEDURE `p`()\nBEGIN\n  -- DEFINER=`old`@`%`\n  INSERT INTO grants (note) VALUES ('DEFINER=`x`@`y`');\nEND",
+		},
+		{
+			"CREATE PROCEDURE `p`() SELECT DEFINER = 1 FROM `accounts`",
+			"",
+			"CREATE PROCEDURE `p`() SELECT DEFINER = 1 FROM `accounts`",
+		},
+	}
+	for _, tt := range tests {
+		if got := replaceDefiner(tt.input, tt.account); got != tt.want {
+			t.Errorf("replaceDefiner(%q, %q) = %q, want %q", tt.input, tt.account, got, tt.want)
+		}
+	}
+}
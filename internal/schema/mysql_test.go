@@ -1,6 +1,9 @@
 package schema
 
 import (
+	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -212,7 +215,10 @@ func TestSortViewsByDependency(t *testing.T) {
 		{Name: "view1", Dependencies: []string{}},
 	}
 
-	sorted := sortViewsByDependency(views)
+	sorted, err := sortViewsByDependency(views)
+	if err != nil {
+		t.Fatalf("sortViewsByDependency() error = %v", err)
+	}
 
 	// view1 should come before view2
 	if len(sorted) != 2 {
@@ -232,7 +238,10 @@ func TestSortViewsByDependency_NoDependencies(t *testing.T) {
 		{Name: "view2", Dependencies: []string{}},
 	}
 
-	sorted := sortViewsByDependency(views)
+	sorted, err := sortViewsByDependency(views)
+	if err != nil {
+		t.Fatalf("sortViewsByDependency() error = %v", err)
+	}
 	if len(sorted) != 2 {
 		t.Errorf("sortViewsByDependency() returned %d views, want 2", len(sorted))
 	}
@@ -246,7 +255,10 @@ func TestSortViewsByDependency_ChainedDependencies(t *testing.T) {
 		{Name: "view2", Dependencies: []string{"view1"}},
 	}
 
-	sorted := sortViewsByDependency(views)
+	sorted, err := sortViewsByDependency(views)
+	if err != nil {
+		t.Fatalf("sortViewsByDependency() error = %v", err)
+	}
 
 	if len(sorted) != 3 {
 		t.Errorf("sortViewsByDependency() returned %d views, want 3", len(sorted))
@@ -266,52 +278,167 @@ func TestSortViewsByDependency_ChainedDependencies(t *testing.T) {
 	}
 }
 
-func TestMySQLExtractor_buildCreateIndexStmt(t *testing.T) {
+func TestSortViewsByDependency_Cycle(t *testing.T) {
+	views := []ViewDef{
+		{Name: "standalone"},
+		{Name: "view_a", Dependencies: []string{"view_b"}},
+		{Name: "view_b", Dependencies: []string{"users", "view_c"}},
+		{Name: "view_c", Dependencies: []string{"view_a"}},
+	}
+
+	_, err := sortViewsByDependency(views)
+	if err == nil {
+		t.Fatal("sortViewsByDependency() expected cycle error, got nil")
+	}
+	if !strings.Contains(err.Error(), "view_a -> view_b -> view_c -> view_a") {
+		t.Errorf("error = %q, want the cycle view_a -> view_b -> view_c -> view_a", err)
+	}
+}
+
+func TestSortViewsByDependency_SelfReference(t *testing.T) {
+	// A CTE that shares the view's name shouldn't count as a cycle
+	views := []ViewDef{{Name: "recent", Dependencies: []string{"recent", "orders"}}}
+
+	sorted, err := sortViewsByDependency(views)
+	if err != nil {
+		t.Fatalf("sortViewsByDependency() error = %v", err)
+	}
+	if len(sorted) != 1 {
+		t.Errorf("sortViewsByDependency() returned %d views, want 1", len(sorted))
+	}
+}
+
+func TestMySQLExtractor_ExtractViews_CatalogDependencies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewMySQLExtractor()
+
+	// active_totals only reaches order_totals through a subquery, which the regex misses
+	mock.ExpectQuery("SELECT table_name, view_definition FROM information_schema.views").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "view_definition"}).
+			AddRow("active_totals", "select `t`.`total` AS `total` from (select * from `testdb`.`order_totals`) `t`").
+			AddRow("order_totals", "select sum(`testdb`.`orders`.`total`) AS `total` from `testdb`.`orders`"))
+	for _, name := range []string{"active_totals", "order_totals"} {
+		mock.ExpectQuery("SHOW CREATE VIEW `testdb`.`" + name + "`").
+			WillReturnRows(sqlmock.NewRows([]string{"View", "Create View", "character_set_client", "collation_connection"}).
+				AddRow(name, "CREATE VIEW `"+name+"` AS SELECT 1", "utf8mb4", "utf8mb4_unicode_ci"))
+	}
+	mock.ExpectQuery("SELECT view_name, table_name.*FROM information_schema.view_table_usage").
+		WithArgs("testdb", "testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"view_name", "table_name"}).
+			AddRow("active_totals", "order_totals").
+			AddRow("order_totals", "orders"))
+
+	views, err := ext.ExtractViews(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractViews() error = %v", err)
+	}
+	if len(views) != 2 || views[0].Name != "order_totals" || views[1].Name != "active_totals" {
+		t.Errorf("ExtractViews() order = %v, want order_totals before active_totals", viewNames(views))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLExtractor_ExtractViews_RegexFallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
 	ext := NewMySQLExtractor()
 
+	mock.ExpectQuery("SELECT table_name, view_definition FROM information_schema.views").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "view_definition"}).
+			AddRow("top_users", "select * from `user_stats`").
+			AddRow("user_stats", "select * from `users`"))
+	for _, name := range []string{"top_users", "user_stats"} {
+		mock.ExpectQuery("SHOW CREATE VIEW `testdb`.`" + name + "`").
+			WillReturnRows(sqlmock.NewRows([]string{"View", "Create View", "character_set_client", "collation_connection"}).
+				AddRow(name, "CREATE VIEW `"+name+"` AS SELECT 1", "utf8mb4", "utf8mb4_unicode_ci"))
+	}
+	// MySQL 5.7 has no VIEW_TABLE_USAGE
+	mock.ExpectQuery("SELECT view_name, table_name.*FROM information_schema.view_table_usage").
+		WillReturnError(fmt.Errorf("Error 1109 (42S02): Unknown table 'VIEW_TABLE_USAGE' in information_schema"))
+
+	views, err := ext.ExtractViews(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractViews() error = %v", err)
+	}
+	if len(views) != 2 || views[0].Name != "user_stats" {
+		t.Errorf("ExtractViews() order = %v, want user_stats first", viewNames(views))
+	}
+}
+
+func viewNames(views []ViewDef) []string {
+	names := make([]string, len(views))
+	for i, v := range views {
+		names[i] = v.QualifiedName()
+	}
+	return names
+}
+
+func TestMySQLIndex_Statements(t *testing.T) {
 	tests := []struct {
 		name      string
 		tableName string
-		idx       IndexDef
+		idx       mysqlIndex
 		want      string
+		wantDef   string
 	}{
 		{
 			name:      "non-unique index",
 			tableName: "users",
-			idx: IndexDef{
-				Name:     "idx_name",
-				Columns:  []string{"name"},
-				IsUnique: false,
-			},
-			want: "CREATE INDEX `idx_name` ON `users` (`name`)",
+			idx:       mysqlIndex{name: "idx_name", indexType: "BTREE", visible: true, parts: []string{"`name`"}},
+			want:      "CREATE INDEX `idx_name` ON `users` (`name`)",
+			wantDef:   "ADD INDEX `idx_name` (`name`)",
 		},
 		{
 			name:      "unique index",
 			tableName: "users",
-			idx: IndexDef{
-				Name:     "idx_email",
-				Columns:  []string{"email"},
-				IsUnique: true,
-			},
-			want: "CREATE UNIQUE INDEX `idx_email` ON `users` (`email`)",
+			idx:       mysqlIndex{name: "idx_email", unique: true, indexType: "BTREE", visible: true, parts: []string{"`email`"}},
+			want:      "CREATE UNIQUE INDEX `idx_email` ON `users` (`email`)",
+			wantDef:   "ADD UNIQUE INDEX `idx_email` (`email`)",
 		},
 		{
 			name:      "multi-column index",
 			tableName: "orders",
-			idx: IndexDef{
-				Name:     "idx_user_date",
-				Columns:  []string{"user_id", "order_date"},
-				IsUnique: false,
-			},
-			want: "CREATE INDEX `idx_user_date` ON `orders` (`user_id`, `order_date`)",
+			idx:       mysqlIndex{name: "idx_user_date", indexType: "BTREE", visible: true, parts: []string{"`user_id`", "`order_date`"}},
+			want:      "CREATE INDEX `idx_user_date` ON `orders` (`user_id`, `order_date`)",
+			wantDef:   "ADD INDEX `idx_user_date` (`user_id`, `order_date`)",
+		},
+		{
+			name:      "fulltext with comment",
+			tableName: "posts",
+			idx:       mysqlIndex{name: "ft_body", indexType: "FULLTEXT", comment: "search's index", visible: true, parts: []string{"`title`", "`body`"}},
+			want:      "CREATE FULLTEXT INDEX `ft_body` ON `posts` (`title`, `body`) COMMENT 'search''s index'",
+			wantDef:   "ADD FULLTEXT INDEX `ft_body` (`title`, `body`) COMMENT 'search''s index'",
+		},
+		{
+			name:      "invisible hash index",
+			tableName: "sessions",
+			idx:       mysqlIndex{name: "idx_token", indexType: "HASH", parts: []string{"`token`"}},
+			want:      "CREATE INDEX `idx_token` ON `sessions` (`token`) USING HASH INVISIBLE",
+			wantDef:   "ADD INDEX `idx_token` (`token`) USING HASH INVISIBLE",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := ext.buildCreateIndexStmt(tt.tableName, tt.idx)
-			if got != tt.want {
-				t.Errorf("buildCreateIndexStmt() = %q, want %q", got, tt.want)
+			if got := tt.idx.createStmt(tt.tableName); got != tt.want {
+				t.Errorf("createStmt() = %q, want %q", got, tt.want)
+			}
+			if got := tt.idx.definition(); got != tt.wantDef {
+				t.Errorf("definition() = %q, want %q", got, tt.wantDef)
 			}
 		})
 	}
@@ -382,6 +509,9 @@ func findSubstring(s, substr string) bool {
 	return false
 }
 
+// mysqlStatisticsColumns are the columns of the secondary index query on information_schema.statistics
+var mysqlStatisticsColumns = []string{"index_name", "column_name", "sub_part", "collation", "non_unique", "index_type", "index_comment", "is_visible", "expression"}
+
 func TestMySQLExtractor_ExtractTables(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -405,9 +535,9 @@ func TestMySQLExtractor_ExtractTables(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow("users", createTableUsers))
 
 	// Mock index query for users
-	mock.ExpectQuery("SELECT index_name, GROUP_CONCAT.*FROM information_schema.statistics").
+	mock.ExpectQuery("SELECT index_name, column_name.*FROM information_schema.statistics").
 		WithArgs("testdb", "users").
-		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "non_unique"}))
+		WillReturnRows(sqlmock.NewRows(mysqlStatisticsColumns))
 
 	// Mock foreign key query for users
 	mock.ExpectQuery("SELECT.*kcu.constraint_name.*FROM information_schema.key_column_usage").
@@ -420,9 +550,9 @@ func TestMySQLExtractor_ExtractTables(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow("orders", createTableOrders))
 
 	// Mock index query for orders
-	mock.ExpectQuery("SELECT index_name, GROUP_CONCAT.*FROM information_schema.statistics").
+	mock.ExpectQuery("SELECT index_name, column_name.*FROM information_schema.statistics").
 		WithArgs("testdb", "orders").
-		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "non_unique"}))
+		WillReturnRows(sqlmock.NewRows(mysqlStatisticsColumns))
 
 	// Mock foreign key query for orders
 	mock.ExpectQuery("SELECT.*kcu.constraint_name.*FROM information_schema.key_column_usage").
@@ -483,6 +613,9 @@ func TestMySQLExtractor_ExtractViews(t *testing.T) {
 	mock.ExpectQuery("SHOW CREATE VIEW `testdb`.`user_stats`").
 		WillReturnRows(sqlmock.NewRows([]string{"View", "Create View", "character_set_client", "collation_connection"}).
 			AddRow("user_stats", createViewStmt, "utf8mb4", "utf8mb4_unicode_ci"))
+	mock.ExpectQuery("SELECT view_name, table_name.*FROM information_schema.view_table_usage").
+		WithArgs("testdb", "testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"view_name", "table_name"}).AddRow("user_stats", "users"))
 
 	views, err := ext.ExtractViews(db, "testdb")
 	if err != nil {
@@ -537,11 +670,11 @@ func TestMySQLExtractor_ExtractTables_WithIndexesAndFKs(t *testing.T) {
 			AddRow("users", "CREATE TABLE `users` (`id` int PRIMARY KEY, `email` varchar(255))"))
 
 	// Mock indexes
-	mock.ExpectQuery("SELECT index_name.*FROM information_schema.statistics").
+	mock.ExpectQuery("SELECT index_name, column_name.*FROM information_schema.statistics").
 		WithArgs("testdb", "users").
-		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "non_unique"}).
-			AddRow("idx_email", "email", 1).
-			AddRow("idx_unique", "email", 0))
+		WillReturnRows(sqlmock.NewRows(mysqlStatisticsColumns).
+			AddRow("idx_email", "email", nil, "A", 1, "BTREE", "", "YES", nil).
+			AddRow("idx_unique", "email", nil, "A", 0, "BTREE", "", "YES", nil))
 
 	// Mock foreign keys
 	mock.ExpectQuery("SELECT.*kcu.constraint_name.*FROM information_schema.key_column_usage").
@@ -632,7 +765,7 @@ func TestMySQLExtractor_ExtractTable_IndexQueryError(t *testing.T) {
 			AddRow("users", "CREATE TABLE `users` (`id` int)"))
 
 	// Mock index query failure
-	mock.ExpectQuery("SELECT index_name.*FROM information_schema.statistics").
+	mock.ExpectQuery("SELECT index_name, column_name.*FROM information_schema.statistics").
 		WithArgs("testdb", "users").
 		WillReturnError(sqlmock.ErrCancelled)
 
@@ -662,9 +795,9 @@ func TestMySQLExtractor_ExtractTable_FKQueryError(t *testing.T) {
 			AddRow("users", "CREATE TABLE `users` (`id` int)"))
 
 	// Mock indexes - empty
-	mock.ExpectQuery("SELECT index_name.*FROM information_schema.statistics").
+	mock.ExpectQuery("SELECT index_name, column_name.*FROM information_schema.statistics").
 		WithArgs("testdb", "users").
-		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "non_unique"}))
+		WillReturnRows(sqlmock.NewRows(mysqlStatisticsColumns))
 
 	// Mock FK query failure
 	mock.ExpectQuery("SELECT.*kcu.constraint_name.*FROM information_schema.key_column_usage").
@@ -701,3 +834,191 @@ func TestMySQLExtractor_ExtractViews_ShowCreateViewError(t *testing.T) {
 		t.Errorf("ExtractViews() expected error for SHOW CREATE VIEW failure")
 	}
 }
+
+func TestMySQLExtractor_ExtractTables_IndexFidelity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewMySQLExtractor()
+
+	createStmt := "CREATE TABLE `events` (\n" +
+		"  `tenant_id` int NOT NULL,\n" +
+		"  `seq` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `title` varchar(255) NOT NULL,\n" +
+		"  `body` text,\n" +
+		"  `price` decimal(10,2) DEFAULT NULL,\n" +
+		"  `qty` int DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`tenant_id`,`seq`),\n" +
+		"  KEY `idx_seq` (`seq`),\n" +
+		"  KEY `idx_title` (`title`(20),`seq` DESC) COMMENT 'lookup',\n" +
+		"  KEY `idx_total` (((`price` * `qty`))) /*!80000 INVISIBLE */,\n" +
+		"  FULLTEXT KEY `ft_body` (`body`)\n" +
+		") ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4"
+
+	mock.ExpectQuery("SELECT table_name FROM information_schema.tables").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("events"))
+	mock.ExpectQuery("SHOW CREATE TABLE `testdb`.`events`").
+		WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow("events", createStmt))
+	mock.ExpectQuery("SELECT index_name, column_name.*is_visible, expression.*FROM information_schema.statistics").
+		WithArgs("testdb", "events").
+		WillReturnRows(sqlmock.NewRows(mysqlStatisticsColumns).
+			AddRow("ft_body", "body", nil, nil, 1, "FULLTEXT", "", "YES", nil).
+			AddRow("idx_seq", "seq", nil, "A", 1, "BTREE", "", "YES", nil).
+			AddRow("idx_title", "title", 20, "A", 1, "BTREE", "lookup", "YES", nil).
+			AddRow("idx_title", "seq", nil, "D", 1, "BTREE", "lookup", "YES", nil).
+			AddRow("idx_total", nil, nil, "A", 1, "BTREE", "", "NO", "(`price` * `qty`)"))
+	mock.ExpectQuery("SELECT.*kcu.constraint_name.*FROM information_schema.key_column_usage").
+		WithArgs("testdb", "events").
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "referenced_table_name", "ref_columns", "delete_rule", "update_rule"}))
+
+	tables, err := ext.ExtractTables(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTables() error = %v", err)
+	}
+
+	// idx_seq keys the AUTO_INCREMENT column, which the primary key doesn't lead with, so it stays inline
+	got := make(map[string]string)
+	for _, idx := range tables[0].Indexes {
+		got[idx.Name] = idx.Definition
+	}
+	want := map[string]string{
+		"ft_body":   "ADD FULLTEXT INDEX `ft_body` (`body`)",
+		"idx_title": "ADD INDEX `idx_title` (`title`(20), `seq` DESC) COMMENT 'lookup'",
+		"idx_total": "ADD INDEX `idx_total` (((`price` * `qty`))) INVISIBLE",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("index definitions = %v, want %v", got, want)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLExtractor_ExtractTables_LegacyStatistics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewMySQLExtractor()
+
+	mock.ExpectQuery("SELECT table_name FROM information_schema.tables").
+		WithArgs("testdb").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("a").AddRow("b"))
+	for i, name := range []string{"a", "b"} {
+		mock.ExpectQuery("SHOW CREATE TABLE `testdb`.`" + name + "`").
+			WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow(name, "CREATE TABLE `"+name+"` (\n  `id` int\n)"))
+		// MySQL 5.7 rejects IS_VISIBLE and EXPRESSION once; later tables go straight to the legacy query
+		if i == 0 {
+			mock.ExpectQuery("SELECT index_name, column_name.*is_visible, expression.*FROM information_schema.statistics").
+				WillReturnError(fmt.Errorf("Error 1054 (42S22): Unknown column 'is_visible' in 'field list'"))
+		}
+		mock.ExpectQuery("SELECT index_name, column_name.*'YES' AS is_visible, NULL AS expression.*FROM information_schema.statistics").
+			WithArgs("testdb", name).
+			WillReturnRows(sqlmock.NewRows(mysqlStatisticsColumns).
+				AddRow("idx_id", "id", nil, "A", 1, "BTREE", "", "YES", nil))
+		mock.ExpectQuery("SELECT.*kcu.constraint_name.*FROM information_schema.key_column_usage").
+			WithArgs("testdb", name).
+			WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "referenced_table_name", "ref_columns", "delete_rule", "update_rule"}))
+	}
+
+	tables, err := ext.ExtractTables(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTables() error = %v", err)
+	}
+	if len(tables) != 2 || len(tables[1].Indexes) != 1 {
+		t.Errorf("ExtractTables() = %+v, want 2 tables with an index each", tables)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLApplier_CreateTable_DefersIndexes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewMySQLApplier()
+	table := TableSchema{
+		Name: "orders",
+		CreateStmt: "CREATE TABLE `orders` (\n" +
+			"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+			"  `user_id` int NOT NULL,\n" +
+			"  `note` text,\n" +
+			"  PRIMARY KEY (`id`),\n" +
+			"  UNIQUE KEY `uk_user` (`user_id`),\n" +
+			"  FULLTEXT KEY `ft_note` (`note`),\n" +
+			"  CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n" +
+			") ENGINE=InnoDB",
+		Indexes: []IndexDef{
+			{Name: "uk_user", Definition: "ADD UNIQUE INDEX `uk_user` (`user_id`)"},
+			{Name: "ft_note", Definition: "ADD FULLTEXT INDEX `ft_note` (`note`)"},
+		},
+	}
+
+	wantCreate := "CREATE TABLE `orders` (\n" +
+		"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` int NOT NULL,\n" +
+		"  `note` text,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB"
+	mock.ExpectExec(regexp.QuoteMeta(wantCreate)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `orders` ADD UNIQUE INDEX `uk_user` (`user_id`)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `orders` ADD FULLTEXT INDEX `ft_note` (`note`)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.CreateTable(db, table); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if err := app.CreateTableIndexes(db, table); err != nil {
+		t.Fatalf("CreateTableIndexes() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLApplier_CreateTableIndexes_FulltextSeparately(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	table := TableSchema{
+		Name: "articles",
+		Indexes: []IndexDef{
+			{Name: "ft_title", Definition: "ADD FULLTEXT INDEX `ft_title` (`title`)"},
+			{Name: "idx_author", Definition: "ADD INDEX `idx_author` (`author_id`)"},
+			{Name: "ft_body", Definition: "ADD FULLTEXT INDEX `ft_body` (`body`)"},
+			{Name: "idx_published", Definition: "ADD INDEX `idx_published` (`published_at`)"},
+		},
+	}
+
+	// InnoDB rejects two FULLTEXT indexes in one ALTER TABLE (error 1795)
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `articles` ADD INDEX `idx_author` (`author_id`), ADD INDEX `idx_published` (`published_at`)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `articles` ADD FULLTEXT INDEX `ft_title` (`title`)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `articles` ADD FULLTEXT INDEX `ft_body` (`body`)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := NewMySQLApplier().CreateTableIndexes(db, table); err != nil {
+		t.Fatalf("CreateTableIndexes() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
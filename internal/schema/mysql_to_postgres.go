@@ -0,0 +1,801 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Enum modes for MySQL ENUM columns migrated to PostgreSQL
+const (
+	EnumCheck = "check" // varchar column with a CHECK constraint listing the values
+	EnumType  = "type"  // PostgreSQL enum type per column, which keeps MySQL's sort order
+)
+
+// pgMaxIdentifierLength is the longest identifier PostgreSQL keeps (NAMEDATALEN - 1)
+const pgMaxIdentifierLength = 63
+
+// ParseEnumMode validates an --enums value, defaulting to EnumCheck
+func ParseEnumMode(mode string) (string, error) {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case "":
+		return EnumCheck, nil
+	case EnumCheck, EnumType:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid enum mode %q: want check or type", mode)
+	}
+}
+
+// LossyConversion is a source feature that doesn't carry over to the destination engine exactly
+type LossyConversion struct {
+	Object string // Table, table.column or index the conversion applies to
+	Detail string // What changes or is left behind
+}
+
+// String formats the conversion for the migration report
+func (c LossyConversion) String() string {
+	return c.Object + ": " + c.Detail
+}
+
+// Translator rewrites tables extracted from one engine for another
+type Translator interface {
+	// TranslateTables returns the tables in the destination engine's DDL, with any types their
+	// columns need
+	TranslateTables(db *sql.DB, database string, tables []TableSchema) ([]TableSchema, []TypeDef, error)
+
+	// LossyConversions lists what the last translation couldn't carry over exactly
+	LossyConversions() []LossyConversion
+}
+
+// IdentitySyncer is implemented by appliers that can move an identity column's sequence past
+// the rows copied into it, for tables translated from an engine without sequences
+type IdentitySyncer interface {
+	SyncIdentity(db *sql.DB, table TableSchema, column ColumnDef) error
+}
+
+// SyncIdentity makes an identity column's next value follow the highest copied value
+func (a *PostgresApplier) SyncIdentity(db *sql.DB, table TableSchema, column ColumnDef) error {
+	quoted := a.Dialect.QuoteQualifiedIdentifier(table.Schema, table.Name)
+	_, err := db.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), COALESCE(MAX(%s), 0) + 1, false) FROM %s",
+		a.Dialect.QuoteLiteral(quoted), a.Dialect.QuoteLiteral(column.Name), a.Dialect.QuoteIdentifier(column.Name), quoted))
+	if err != nil {
+		return fmt.Errorf("failed to set identity value for %s.%s: %w", table.QualifiedName(), column.Name, err)
+	}
+	return nil
+}
+
+// MySQLToPostgresTranslator rewrites MySQL tables as PostgreSQL tables in the default schema
+// Column types come from information_schema.columns; indexes and foreign keys from what
+// the MySQL extractor read
+type MySQLToPostgresTranslator struct {
+	EnumMode string
+	MariaDB  bool // The source is MariaDB, whose column defaults are written as SQL
+	lossy    []LossyConversion
+}
+
+// NewMySQLToPostgresTranslator creates a MySQL to PostgreSQL translator
+func NewMySQLToPostgresTranslator(enumMode string) *MySQLToPostgresTranslator {
+	if enumMode == "" {
+		enumMode = EnumCheck
+	}
+	return &MySQLToPostgresTranslator{EnumMode: enumMode}
+}
+
+// LossyConversions lists what the last translation couldn't carry over exactly
+func (t *MySQLToPostgresTranslator) LossyConversions() []LossyConversion {
+	return t.lossy
+}
+
+// report records a lossy conversion
+func (t *MySQLToPostgresTranslator) report(object, format string, args ...interface{}) {
+	t.lossy = append(t.lossy, LossyConversion{Object: object, Detail: fmt.Sprintf(format, args...)})
+}
+
+// mysqlColumn is a column read from information_schema.columns
+type mysqlColumn struct {
+	name         string
+	columnType   string // Full type, e.g. "int unsigned" or "enum('a','b')"
+	dataType     string // Type name alone, e.g. "int" or "enum"
+	nullable     bool
+	defaultValue sql.NullString
+	extra        string // auto_increment, on update CURRENT_TIMESTAMP, DEFAULT_GENERATED, VIRTUAL GENERATED, ...
+	comment      string
+	generation   string // Generated column expression
+}
+
+// TranslateTables rewrites MySQL tables as PostgreSQL tables
+func (t *MySQLToPostgresTranslator) TranslateTables(db *sql.DB, database string, tables []TableSchema) ([]TableSchema, []TypeDef, error) {
+	t.lossy = nil
+
+	columns, err := t.queryColumns(db, database)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Tables, indexes, sequences and types share one namespace per schema in PostgreSQL,
+	// while MySQL index names only have to be unique per table
+	used := make(map[string]bool)
+	for _, table := range tables {
+		used[table.Name] = true
+	}
+
+	var types []TypeDef
+	translated := make([]TableSchema, 0, len(tables))
+	for _, table := range tables {
+		out, tableTypes, err := t.translateTable(table, columns[table.Name], used)
+		if err != nil {
+			return nil, nil, err
+		}
+		translated = append(translated, out)
+		types = append(types, tableTypes...)
+	}
+
+	// Foreign keys need the unique keys of the tables they reference
+	for i, table := range tables {
+		translated[i].ForeignKeys = t.translateForeignKeys(table, translated)
+	}
+
+	return translated, types, nil
+}
+
+// queryColumns reads the columns of every table in the database, keyed by table name
+func (t *MySQLToPostgresTranslator) queryColumns(db *sql.DB, database string) (map[string][]mysqlColumn, error) {
+	rows, err := db.Query(`
+		SELECT
+			table_name,
+			column_name,
+			column_type,
+			data_type,
+			is_nullable,
+			column_default,
+			extra,
+			column_comment,
+			COALESCE(generation_expression, '')
+		FROM information_schema.columns
+		WHERE table_schema = ?
+		ORDER BY table_name, ordinal_position
+	`, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]mysqlColumn)
+	for rows.Next() {
+		var table, nullable string
+		var c mysqlColumn
+		if err := rows.Scan(&table, &c.name, &c.columnType, &c.dataType, &nullable, &c.defaultValue, &c.extra, &c.comment, &c.generation); err != nil {
+			return nil, err
+		}
+		c.nullable = nullable == "YES"
+		c.dataType = strings.ToLower(c.dataType)
+		if t.MariaDB {
+			c.defaultValue, c.extra = mariaDBColumnDefault(c.defaultValue, c.extra)
+		}
+		columns[table] = append(columns[table], c)
+	}
+	return columns, rows.Err()
+}
+
+// translateTable builds the PostgreSQL CREATE TABLE statement and indexes for a MySQL table
+func (t *MySQLToPostgresTranslator) translateTable(table TableSchema, columns []mysqlColumn, used map[string]bool) (TableSchema, []TypeDef, error) {
+	ddl, err := parseCreateTable(table.CreateStmt, mysqlSyntax(""))
+	if err != nil {
+		return table, nil, fmt.Errorf("failed to parse CREATE TABLE %s: %w", table.Name, err)
+	}
+
+	out := TableSchema{
+		Name:       table.Name,
+		Partitions: table.Partitions,
+	}
+	if len(table.Partitions) > 0 {
+		// The rows are still read partition by partition
+		out.PartitioningDropped = true
+		t.report(table.Name, "%s partitioning is dropped; the table is created unpartitioned", table.Partitions[0].Method)
+	}
+
+	var lines []string
+	var types []TypeDef
+	for _, c := range columns {
+		col, definition, typ := t.translateColumn(table.Name, c, used)
+		out.Columns = append(out.Columns, col)
+		lines = append(lines, "    "+definition)
+		if typ != nil {
+			types = append(types, *typ)
+		}
+		if col.Extra == "IDENTITY BY DEFAULT" {
+			used[truncateIdentifier(table.Name+"_"+c.name+"_seq", pgMaxIdentifierLength)] = true
+		}
+		if c.comment != "" {
+			out.PostCreateStmts = append(out.PostCreateStmts, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s",
+				quoteIdentifier(table.Name), quoteIdentifier(c.name), quoteLiteral(c.comment)))
+		}
+	}
+
+	for _, c := range ddl.Clauses {
+		switch c.Kind {
+		case clausePrimaryKey:
+			out.PrimaryKey = c.keyColumns()
+		case clauseCheck:
+			name := c.Name
+			if name == "" {
+				name = "CHECK"
+			}
+			t.report(table.Name, "CHECK constraint %s isn't migrated; recreate it in PostgreSQL syntax", name)
+		}
+	}
+	if len(out.PrimaryKey) > 0 {
+		lines = append(lines, fmt.Sprintf("    PRIMARY KEY (%s)", quoteIdentifierList(out.PrimaryKey)))
+		used[truncateIdentifier(table.Name+"_pkey", pgMaxIdentifierLength)] = true
+	}
+
+	out.CreateStmt = fmt.Sprintf("CREATE TABLE %s (\n%s\n)", quoteIdentifier(table.Name), strings.Join(lines, ",\n"))
+	if comment, ok := tableComment(ddl.Suffix); ok {
+		out.PostCreateStmts = append(out.PostCreateStmts, fmt.Sprintf("COMMENT ON TABLE %s IS %s", quoteIdentifier(table.Name), quoteLiteral(comment)))
+	}
+
+	for _, ix := range table.Indexes {
+		if index, ok := t.translateIndex(table.Name, ix, used); ok {
+			out.Indexes = append(out.Indexes, index)
+		}
+	}
+
+	return out, types, nil
+}
+
+// translateColumn returns the PostgreSQL column, its definition in CREATE TABLE and, for an
+// ENUM column in EnumType mode, the type it uses
+func (t *MySQLToPostgresTranslator) translateColumn(table string, c mysqlColumn, used map[string]bool) (ColumnDef, string, *TypeDef) {
+	object := table + "." + c.name
+	pgType, check, typ := t.columnType(table, c, used)
+
+	col := ColumnDef{
+		Name:       c.name,
+		DataType:   pgType,
+		IsNullable: c.nullable,
+		SourceType: c.columnType,
+	}
+	definition := quoteIdentifier(c.name) + " " + pgType
+
+	extra := strings.ToLower(c.extra)
+	switch {
+	case strings.Contains(extra, "auto_increment"):
+		if pgType == "smallint" || pgType == "integer" || pgType == "bigint" {
+			definition += " GENERATED BY DEFAULT AS IDENTITY"
+			col.Extra = "IDENTITY BY DEFAULT"
+		} else {
+			t.report(object, "AUTO_INCREMENT on %s isn't migrated", c.columnType)
+		}
+	case c.generation != "":
+		t.report(object, "generated column (%s) becomes a regular column holding the copied values", c.generation)
+	default:
+		if value, ok := t.columnDefault(object, c, pgType); ok {
+			definition += " DEFAULT " + value
+			col.DefaultValue = sql.NullString{String: value, Valid: true}
+		}
+	}
+	if strings.Contains(extra, "on update current_timestamp") {
+		t.report(object, "ON UPDATE CURRENT_TIMESTAMP isn't supported; set the column from the application or a trigger")
+	}
+
+	if !c.nullable {
+		definition += " NOT NULL"
+	}
+	if check != "" {
+		definition += " CHECK (" + check + ")"
+	}
+	return col, definition, typ
+}
+
+// columnType maps a MySQL column type to PostgreSQL, with a CHECK expression for what the
+// type alone doesn't enforce
+func (t *MySQLToPostgresTranslator) columnType(table string, c mysqlColumn, used map[string]bool) (string, string, *TypeDef) {
+	object := table + "." + c.name
+	columnType := strings.ToLower(c.columnType)
+	unsigned := ""
+	if strings.Contains(columnType, "unsigned") {
+		unsigned = quoteIdentifier(c.name) + " >= 0"
+	}
+
+	switch c.dataType {
+	case "tinyint":
+		if strings.HasPrefix(columnType, "tinyint(1)") {
+			t.report(object, "tinyint(1) becomes boolean; values other than 0 and 1 become true")
+			return "boolean", "", nil
+		}
+		return "smallint", unsigned, nil
+	case "smallint":
+		if unsigned != "" {
+			return "integer", unsigned, nil
+		}
+		return "smallint", "", nil
+	case "mediumint":
+		return "integer", unsigned, nil
+	case "int", "integer":
+		if unsigned != "" {
+			return "bigint", unsigned, nil
+		}
+		return "integer", "", nil
+	case "bigint":
+		if unsigned != "" {
+			t.report(object, "bigint unsigned becomes bigint; values above 9223372036854775807 don't fit")
+		}
+		return "bigint", unsigned, nil
+	case "decimal", "numeric":
+		return "numeric" + typeArguments(columnType), unsigned, nil
+	case "float":
+		return "real", unsigned, nil
+	case "double", "real":
+		return "double precision", unsigned, nil
+	case "bit":
+		return fmt.Sprintf("bit(%d)", typeLength(columnType, 1)), "", nil
+	case "char":
+		return "char" + typeArguments(columnType), "", nil
+	case "varchar":
+		return "varchar" + typeArguments(columnType), "", nil
+	case "tinytext", "text", "mediumtext", "longtext":
+		return "text", "", nil
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return "bytea", "", nil
+	case "date":
+		return "date", "", nil
+	case "datetime":
+		return fmt.Sprintf("timestamp(%d)", typeLength(columnType, 0)), "", nil
+	case "timestamp":
+		t.report(object, "timestamp becomes timestamp without time zone, holding the source session's local time")
+		return fmt.Sprintf("timestamp(%d)", typeLength(columnType, 0)), "", nil
+	case "time":
+		t.report(object, "time values outside 00:00:00 to 24:00:00 don't fit PostgreSQL's time")
+		return fmt.Sprintf("time(%d)", typeLength(columnType, 0)), "", nil
+	case "year":
+		return "smallint", "", nil
+	case "json":
+		return "jsonb", "", nil
+	case "uuid":
+		// MariaDB 10.7 and later
+		return "uuid", "", nil
+	case "inet4", "inet6":
+		// MariaDB 10.5 and later
+		return "inet", "", nil
+	case "enum":
+		values := typeValues(c.columnType)
+		if t.EnumMode == EnumType {
+			name := uniqueIdentifier(table+"_"+c.name, pgMaxIdentifierLength, used)
+			return quoteIdentifier(name), "", &TypeDef{
+				Name:       name,
+				Kind:       "enum",
+				CreateStmt: fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", quoteIdentifier(name), quoteLiteralList(values)),
+			}
+		}
+		t.report(object, "enum becomes varchar with a CHECK constraint; it sorts alphabetically rather than in declaration order")
+		return enumVarchar(values), fmt.Sprintf("%s IN (%s)", quoteIdentifier(c.name), quoteLiteralList(values)), nil
+	case "set":
+		values := typeValues(c.columnType)
+		return "text[]", fmt.Sprintf("%s <@ ARRAY[%s]::text[]", quoteIdentifier(c.name), quoteLiteralList(values)), nil
+	case "geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection":
+		t.report(object, "%s becomes bytea holding MySQL's internal geometry format; convert it with PostGIS", c.dataType)
+		return "bytea", "", nil
+	default:
+		t.report(object, "unknown type %s becomes text", c.columnType)
+		return "text", "", nil
+	}
+}
+
+// mariaDBColumnDefault reads a MariaDB 10.2.7+ column_default the way MySQL writes it
+// MariaDB quotes literal defaults, writes the bare word NULL for columns without a default
+// and leaves expressions unquoted; expressions are marked DEFAULT_GENERATED as MySQL marks them
+func mariaDBColumnDefault(value sql.NullString, extra string) (sql.NullString, string) {
+	if !value.Valid || value.String == "NULL" {
+		return sql.NullString{}, extra
+	}
+	v := value.String
+	if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		unquoted := strings.ReplaceAll(v[1:len(v)-1], "''", "'")
+		return sql.NullString{String: strings.ReplaceAll(unquoted, `\\`, `\`), Valid: true}, extra
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return value, extra
+	}
+	lower := strings.ToLower(v)
+	if strings.HasPrefix(lower, "b'") || strings.HasPrefix(lower, "x'") || strings.HasPrefix(lower, "0x") {
+		return value, extra
+	}
+	if !strings.Contains(strings.ToLower(extra), "default_generated") {
+		extra = strings.TrimSpace(extra + " DEFAULT_GENERATED")
+	}
+	return value, extra
+}
+
+// columnDefault translates a column's MySQL default, reporting defaults that can't be kept
+func (t *MySQLToPostgresTranslator) columnDefault(object string, c mysqlColumn, pgType string) (string, bool) {
+	if !c.defaultValue.Valid {
+		return "", false
+	}
+	value := c.defaultValue.String
+
+	upper := strings.ToUpper(value)
+	if strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || strings.HasPrefix(upper, "NOW(") {
+		return "CURRENT_TIMESTAMP", true
+	}
+	if strings.Contains(strings.ToLower(c.extra), "default_generated") {
+		t.report(object, "expression default %s isn't migrated", value)
+		return "", false
+	}
+
+	switch {
+	case pgType == "boolean":
+		if value == "0" {
+			return "false", true
+		}
+		return "true", true
+	case strings.HasPrefix(pgType, "bit("):
+		bits := strings.TrimSuffix(strings.TrimPrefix(value, "b'"), "'")
+		if n, err := strconv.ParseUint(bits, 2, 64); err == nil {
+			return fmt.Sprintf("B'%0*b'", typeLength(pgType, 1), n), true
+		}
+		t.report(object, "default %s isn't migrated", value)
+		return "", false
+	case strings.HasPrefix(pgType, "date") || strings.HasPrefix(pgType, "timestamp"):
+		if IsZeroDate(value) {
+			t.report(object, "zero date default %s isn't migrated", value)
+			return "", false
+		}
+		return quoteLiteral(value), true
+	case pgType == "text[]":
+		return quoteLiteral(SetArrayLiteral(value)), true
+	case isNumericType(pgType):
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value, true
+		}
+		return quoteLiteral(value), true
+	default:
+		return quoteLiteral(value), true
+	}
+}
+
+// mysqlKeyPart is one key part of a MySQL index definition
+type mysqlKeyPart struct {
+	column     string
+	prefix     bool // Indexes a prefix of the column, e.g. `name`(10)
+	descending bool
+	expression bool // Functional key part, e.g. ((lower(`email`)))
+}
+
+// translateIndex builds a PostgreSQL CREATE INDEX statement from a MySQL index definition
+func (t *MySQLToPostgresTranslator) translateIndex(table string, ix IndexDef, used map[string]bool) (IndexDef, bool) {
+	kind, parts, options := parseMySQLIndexDefinition(ix.Definition)
+	object := table + "." + ix.Name
+	if kind == "SPATIAL" {
+		t.report(object, "SPATIAL index isn't migrated; recreate it with PostGIS")
+		return IndexDef{}, false
+	}
+
+	keyParts := make([]string, len(parts))
+	for i, p := range parts {
+		if p.expression {
+			t.report(object, "functional index isn't migrated; recreate it in PostgreSQL syntax")
+			return IndexDef{}, false
+		}
+		if p.prefix {
+			t.report(object, "prefix index on %s now covers the whole column", p.column)
+		}
+		keyParts[i] = quoteIdentifier(p.column)
+		if p.descending {
+			keyParts[i] += " DESC"
+		}
+	}
+	if len(keyParts) == 0 {
+		t.report(object, "index definition %q isn't understood", ix.Definition)
+		return IndexDef{}, false
+	}
+	if options["INVISIBLE"] {
+		t.report(object, "invisible index is created visible")
+	}
+
+	name := uniqueIdentifier(ix.Name, pgMaxIdentifierLength, used)
+	if name != ix.Name {
+		t.report(object, "index is renamed to %s; PostgreSQL index names are unique per schema", name)
+	}
+
+	index := IndexDef{Name: name, Columns: ix.Columns, IsUnique: kind == "UNIQUE"}
+	switch kind {
+	case "FULLTEXT":
+		// The same expression Laravel's fulltext indexes and whereFullText use on PostgreSQL
+		vectors := make([]string, len(parts))
+		for i, p := range parts {
+			vectors[i] = fmt.Sprintf("to_tsvector('english', %s)", quoteIdentifier(p.column))
+		}
+		index.CreateStmt = fmt.Sprintf("CREATE INDEX %s ON %s USING gin ((%s))",
+			quoteIdentifier(name), quoteIdentifier(table), strings.Join(vectors, " || "))
+		t.report(object, "FULLTEXT index becomes a GIN index on to_tsvector('english', ...); MATCH ... AGAINST queries need rewriting")
+	case "UNIQUE":
+		index.CreateStmt = fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", quoteIdentifier(name), quoteIdentifier(table), strings.Join(keyParts, ", "))
+	default:
+		index.CreateStmt = fmt.Sprintf("CREATE INDEX %s ON %s (%s)", quoteIdentifier(name), quoteIdentifier(table), strings.Join(keyParts, ", "))
+	}
+	return index, true
+}
+
+// parseMySQLIndexDefinition splits an ADD [UNIQUE|FULLTEXT|SPATIAL] INDEX clause into its kind,
+// key parts and the option keywords that follow them
+func parseMySQLIndexDefinition(definition string) (string, []mysqlKeyPart, map[string]bool) {
+	var kind string
+	var parts []mysqlKeyPart
+	options := make(map[string]bool)
+
+	depth := 0
+	var part *mysqlKeyPart
+	for _, tok := range tokenizeMySQL(definition, "") {
+		switch {
+		case tok.Kind == tokSpace || tok.Kind == tokComment:
+		case tok.isPunct(definition, '('):
+			depth++
+			switch {
+			case depth == 1:
+				parts = append(parts, mysqlKeyPart{})
+				part = &parts[len(parts)-1]
+			case depth == 2 && part.column == "":
+				part.expression = true
+			case depth == 2:
+				part.prefix = true
+			}
+		case tok.isPunct(definition, ')'):
+			depth--
+			if depth == 0 {
+				part = nil
+			}
+		case tok.isPunct(definition, ',') && depth == 1:
+			parts = append(parts, mysqlKeyPart{})
+			part = &parts[len(parts)-1]
+		case depth == 1 && tok.isKeyword("DESC"):
+			part.descending = true
+		case depth == 1 && tok.isIdent() && part.column == "" && !part.expression:
+			part.column = tok.Value
+		case depth == 0 && part == nil && len(parts) == 0:
+			for _, k := range []string{"UNIQUE", "FULLTEXT", "SPATIAL"} {
+				if tok.isKeyword(k) {
+					kind = k
+				}
+			}
+		case depth == 0 && tok.Kind == tokIdent:
+			options[strings.ToUpper(tok.Value)] = true
+		}
+	}
+	return kind, parts, options
+}
+
+// translateForeignKeys builds PostgreSQL ALTER TABLE statements for a MySQL table's foreign keys
+// MySQL lets a foreign key reference any indexed columns; PostgreSQL needs a unique key there
+func (t *MySQLToPostgresTranslator) translateForeignKeys(table TableSchema, translated []TableSchema) []ForeignKeyDef {
+	var fks []ForeignKeyDef
+	for _, fk := range table.ForeignKeys {
+		object := table.Name + "." + fk.Name
+		if !hasUniqueKey(translated, fk.RefTable, fk.RefColumns) {
+			t.report(object, "foreign key isn't migrated; PostgreSQL needs a unique key on %s (%s)", fk.RefTable, strings.Join(fk.RefColumns, ", "))
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			quoteIdentifier(table.Name), quoteIdentifier(fk.Name), quoteIdentifierList(fk.Columns),
+			quoteIdentifier(fk.RefTable), quoteIdentifierList(fk.RefColumns))
+		if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+			stmt += " ON DELETE " + fk.OnDelete
+		}
+		if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+			stmt += " ON UPDATE " + fk.OnUpdate
+		}
+		fk.ConstraintStmt = stmt
+		fks = append(fks, fk)
+	}
+	return fks
+}
+
+// hasUniqueKey reports whether a translated table has a primary key or unique index on exactly
+// the given columns
+func hasUniqueKey(tables []TableSchema, tableName string, columns []string) bool {
+	want := sortedCopy(columns)
+	for _, table := range tables {
+		if table.Name != tableName {
+			continue
+		}
+		if equalStrings(sortedCopy(table.PrimaryKey), want) {
+			return true
+		}
+		for _, ix := range table.Indexes {
+			if ix.IsUnique && equalStrings(sortedCopy(ix.Columns), want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tableComment returns the COMMENT table option of a MySQL CREATE TABLE statement's suffix
+func tableComment(suffix string) (string, bool) {
+	tokens := tokenizeMySQL(suffix, "")
+	for i, tok := range tokens {
+		if !tok.isKeyword("COMMENT") {
+			continue
+		}
+		// COMMENT [=] 'text'
+		for _, next := range tokens[i+1:] {
+			if next.Kind == tokSpace || next.isPunct(suffix, '=') {
+				continue
+			}
+			if next.Kind == tokString {
+				return mysqlStringValue(suffix[next.Start:next.End]), true
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// typeValues returns the values of an enum('a','b') or set('a','b') column type
+func typeValues(columnType string) []string {
+	var values []string
+	for _, tok := range tokenizeMySQL(columnType, "") {
+		if tok.Kind == tokString {
+			values = append(values, mysqlStringValue(columnType[tok.Start:tok.End]))
+		}
+	}
+	return values
+}
+
+// mysqlStringValue unquotes a single-quoted MySQL string literal
+func mysqlStringValue(literal string) string {
+	body := literal[1 : len(literal)-1]
+	var sb strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			i++
+		case c == '\\' && i+1 < len(body):
+			i++
+			switch body[i] {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case '0':
+				c = 0
+			default:
+				c = body[i]
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// typeArguments returns the parenthesized arguments of a column type, e.g. "(10,2)", if any
+func typeArguments(columnType string) string {
+	start := strings.IndexByte(columnType, '(')
+	end := strings.IndexByte(columnType, ')')
+	if start < 0 || end < start {
+		return ""
+	}
+	return columnType[start : end+1]
+}
+
+// typeLength returns the single numeric argument of a column type, e.g. 6 for datetime(6)
+func typeLength(columnType string, fallback int) int {
+	args := typeArguments(columnType)
+	if args == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(args[1 : len(args)-1])
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// enumVarchar returns a varchar type wide enough for every enum value, like Laravel's varchar(255)
+func enumVarchar(values []string) string {
+	width := 255
+	for _, v := range values {
+		if n := len([]rune(v)); n > width {
+			width = n
+		}
+	}
+	return fmt.Sprintf("varchar(%d)", width)
+}
+
+// isNumericType reports whether a translated PostgreSQL column type holds numbers
+func isNumericType(pgType string) bool {
+	switch {
+	case pgType == "smallint", pgType == "integer", pgType == "bigint", pgType == "real", pgType == "double precision":
+		return true
+	default:
+		return strings.HasPrefix(pgType, "numeric")
+	}
+}
+
+// IsZeroDate reports whether a MySQL date or datetime value has a zero year, month or day,
+// which PostgreSQL can't store
+func IsZeroDate(value string) bool {
+	if len(value) < 10 || value[4] != '-' || value[7] != '-' {
+		return false
+	}
+	return value[:4] == "0000" || value[5:7] == "00" || value[8:10] == "00"
+}
+
+// SetArrayLiteral converts a MySQL SET value ("a,b") to a PostgreSQL text[] literal ({"a","b"})
+func SetArrayLiteral(value string) string {
+	if value == "" {
+		return "{}"
+	}
+	members := strings.Split(value, ",")
+	for i, m := range members {
+		m = strings.ReplaceAll(m, `\`, `\\`)
+		members[i] = `"` + strings.ReplaceAll(m, `"`, `\"`) + `"`
+	}
+	return "{" + strings.Join(members, ",") + "}"
+}
+
+// uniqueIdentifier returns name, shortened to the destination's identifier limit, or a numbered
+// variant of it when that's taken, and marks the result as used
+func uniqueIdentifier(name string, limit int, used map[string]bool) string {
+	candidate := truncateIdentifier(name, limit)
+	for n := 2; used[candidate]; n++ {
+		suffix := fmt.Sprintf("_%d", n)
+		candidate = truncateIdentifier(name, limit-len(suffix)) + suffix
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// truncateIdentifier shortens a name to at most limit bytes without splitting a character
+func truncateIdentifier(name string, limit int) string {
+	if len(name) <= limit {
+		return name
+	}
+	for limit > 0 && name[limit]&0xC0 == 0x80 {
+		limit--
+	}
+	return name[:limit]
+}
+
+// quoteIdentifierList quotes and comma-separates PostgreSQL identifiers
+func quoteIdentifierList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = quoteIdentifier(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteLiteralList quotes and comma-separates PostgreSQL string literals
+func quoteLiteralList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quoteLiteral(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// sortedCopy returns a sorted copy of a string slice
+func sortedCopy(s []string) []string {
+	out := append([]string(nil), s...)
+	sort.Strings(out)
+	return out
+}
+
+// equalStrings reports whether two string slices hold the same elements in the same order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) || len(a) == 0 {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
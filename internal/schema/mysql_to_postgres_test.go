@@ -0,0 +1,408 @@
+package schema
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var mysqlColumnsColumns = []string{"table_name", "column_name", "column_type", "data_type", "is_nullable", "column_default", "extra", "column_comment", "generation_expression"}
+
+// mysqlShopTables is a small Laravel-style schema as the MySQL extractor returns it
+func mysqlShopTables() []TableSchema {
+	return []TableSchema{
+		{
+			Name: "users",
+			CreateStmt: "CREATE TABLE `users` (\n" +
+				"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+				"  `email` varchar(255) NOT NULL COMMENT 'Login address',\n" +
+				"  `active` tinyint(1) NOT NULL DEFAULT '1',\n" +
+				"  `role` enum('admin','member') NOT NULL DEFAULT 'member',\n" +
+				"  `bio` text,\n" +
+				"  `created_at` timestamp NULL DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Application users'",
+			Indexes: []IndexDef{
+				{Name: "users_email_unique", Columns: []string{"email"}, IsUnique: true, Definition: "ADD UNIQUE INDEX `users_email_unique` (`email`)"},
+				{Name: "users_bio", Columns: []string{"bio"}, Definition: "ADD INDEX `users_bio` (`bio`(100))"},
+				{Name: "users_search", Columns: []string{"email", "bio"}, Definition: "ADD FULLTEXT INDEX `users_search` (`email`, `bio`)"},
+			},
+		},
+		{
+			Name: "orders",
+			CreateStmt: "CREATE TABLE `orders` (\n" +
+				"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+				"  `user_id` bigint unsigned NOT NULL,\n" +
+				"  `tags` set('gift','rush') NOT NULL DEFAULT '',\n" +
+				"  `total` decimal(10,2) GENERATED ALWAYS AS (0) STORED,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  CONSTRAINT `orders_chk` CHECK ((`id` > 0))\n" +
+				") ENGINE=InnoDB",
+			Indexes: []IndexDef{
+				{Name: "users_email_unique", Columns: []string{"user_id"}, Definition: "ADD INDEX `users_email_unique` (`user_id` DESC)"},
+				{Name: "orders_lower", Columns: []string{"lower(`tags`)"}, Definition: "ADD INDEX `orders_lower` ((lower(`tags`)))"},
+			},
+			ForeignKeys: []ForeignKeyDef{
+				{Name: "orders_user_id_foreign", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+				{Name: "orders_bio_foreign", Columns: []string{"tags"}, RefTable: "users", RefColumns: []string{"bio"}, OnDelete: "RESTRICT", OnUpdate: "NO ACTION"},
+			},
+		},
+	}
+}
+
+func expectMySQLShopColumns(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT.*column_type.*FROM information_schema.columns").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows(mysqlColumnsColumns).
+			AddRow("orders", "id", "int unsigned", "int", "NO", nil, "auto_increment", "", "").
+			AddRow("orders", "user_id", "bigint unsigned", "bigint", "NO", nil, "", "", "").
+			AddRow("orders", "tags", "set('gift','rush')", "set", "NO", "", "", "", "").
+			AddRow("orders", "total", "decimal(10,2)", "decimal", "YES", nil, "STORED GENERATED", "", "0").
+			AddRow("users", "id", "bigint unsigned", "bigint", "NO", nil, "auto_increment", "", "").
+			AddRow("users", "email", "varchar(255)", "varchar", "NO", nil, "", "Login address", "").
+			AddRow("users", "active", "tinyint(1)", "tinyint", "NO", "1", "", "", "").
+			AddRow("users", "role", "enum('admin','member')", "enum", "NO", "member", "", "", "").
+			AddRow("users", "bio", "text", "text", "YES", nil, "", "", "").
+			AddRow("users", "created_at", "timestamp", "timestamp", "YES", nil, "", "", ""))
+}
+
+func TestParseEnumMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    string
+		wantErr bool
+	}{
+		{"", EnumCheck, false},
+		{"check", EnumCheck, false},
+		{" TYPE ", EnumType, false},
+		{"domain", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseEnumMode(tt.mode)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseEnumMode(%q) = %q, %v, want %q, wantErr %v", tt.mode, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMySQLToPostgresTranslator_TranslateTables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+	expectMySQLShopColumns(mock)
+
+	tr := NewMySQLToPostgresTranslator(EnumCheck)
+	tables, types, err := tr.TranslateTables(db, "shop", mysqlShopTables())
+	if err != nil {
+		t.Fatalf("TranslateTables() error = %v", err)
+	}
+	if len(tables) != 2 || len(types) != 0 {
+		t.Fatalf("TranslateTables() = %d tables, %d types, want 2 and 0", len(tables), len(types))
+	}
+	users, orders := tables[0], tables[1]
+
+	wantCreate := "CREATE TABLE \"users\" (\n" +
+		"    \"id\" bigint GENERATED BY DEFAULT AS IDENTITY NOT NULL CHECK (\"id\" >= 0),\n" +
+		"    \"email\" varchar(255) NOT NULL,\n" +
+		"    \"active\" boolean DEFAULT true NOT NULL,\n" +
+		"    \"role\" varchar(255) DEFAULT 'member' NOT NULL CHECK (\"role\" IN ('admin', 'member')),\n" +
+		"    \"bio\" text,\n" +
+		"    \"created_at\" timestamp(0),\n" +
+		"    PRIMARY KEY (\"id\")\n" +
+		")"
+	if users.CreateStmt != wantCreate {
+		t.Errorf("CreateStmt = %q, want %q", users.CreateStmt, wantCreate)
+	}
+	wantPost := []string{
+		`COMMENT ON COLUMN "users"."email" IS 'Login address'`,
+		`COMMENT ON TABLE "users" IS 'Application users'`,
+	}
+	if !reflect.DeepEqual(users.PostCreateStmts, wantPost) {
+		t.Errorf("PostCreateStmts = %q, want %q", users.PostCreateStmts, wantPost)
+	}
+	if users.Columns[0].Extra != "IDENTITY BY DEFAULT" || users.Columns[2].SourceType != "tinyint(1)" {
+		t.Errorf("Columns = %+v, want an identity id and the source types", users.Columns)
+	}
+
+	var indexes []string
+	for _, table := range tables {
+		for _, ix := range table.Indexes {
+			indexes = append(indexes, ix.CreateStmt)
+		}
+	}
+	wantIndexes := []string{
+		`CREATE UNIQUE INDEX "users_email_unique" ON "users" ("email")`,
+		`CREATE INDEX "users_bio" ON "users" ("bio")`,
+		`CREATE INDEX "users_search" ON "users" USING gin ((to_tsvector('english', "email") || to_tsvector('english', "bio")))`,
+		`CREATE INDEX "users_email_unique_2" ON "orders" ("user_id" DESC)`,
+	}
+	if !reflect.DeepEqual(indexes, wantIndexes) {
+		t.Errorf("indexes = %q, want %q", indexes, wantIndexes)
+	}
+
+	if !strings.Contains(orders.CreateStmt, `"tags" text[] DEFAULT '{}' NOT NULL CHECK ("tags" <@ ARRAY['gift', 'rush']::text[])`) ||
+		!strings.Contains(orders.CreateStmt, `"total" numeric(10,2),`) {
+		t.Errorf("orders CreateStmt = %q", orders.CreateStmt)
+	}
+	if len(orders.ForeignKeys) != 1 {
+		t.Fatalf("orders foreign keys = %+v, want only the one referencing a unique key", orders.ForeignKeys)
+	}
+	wantFK := `ALTER TABLE "orders" ADD CONSTRAINT "orders_user_id_foreign" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE`
+	if orders.ForeignKeys[0].ConstraintStmt != wantFK {
+		t.Errorf("ConstraintStmt = %q, want %q", orders.ForeignKeys[0].ConstraintStmt, wantFK)
+	}
+
+	var report []string
+	for _, c := range tr.LossyConversions() {
+		report = append(report, c.String())
+	}
+	for _, want := range []string{
+		"users.id: bigint unsigned becomes bigint",
+		"users.active: tinyint(1) becomes boolean",
+		"users.role: enum becomes varchar",
+		"users.created_at: timestamp becomes timestamp without time zone",
+		"users.users_bio: prefix index on bio",
+		"users.users_search: FULLTEXT index",
+		"orders.users_email_unique: index is renamed to users_email_unique_2",
+		"orders.orders_lower: functional index isn't migrated",
+		"orders.total: generated column (0)",
+		"orders: CHECK constraint orders_chk isn't migrated",
+		"orders.orders_bio_foreign: foreign key isn't migrated",
+	} {
+		found := false
+		for _, r := range report {
+			found = found || strings.HasPrefix(r, want)
+		}
+		if !found {
+			t.Errorf("LossyConversions() = %q, missing %q", report, want)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMySQLToPostgresTranslator_MariaDBDefaults(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// MariaDB quotes literal defaults and writes NULL for columns without one
+	mock.ExpectQuery("SELECT.*column_type.*FROM information_schema.columns").
+		WithArgs("app").
+		WillReturnRows(sqlmock.NewRows(mysqlColumnsColumns).
+			AddRow("settings", "id", "int(11)", "int", "NO", nil, "auto_increment", "", "").
+			AddRow("settings", "name", "varchar(50)", "varchar", "YES", "NULL", "", "", "").
+			AddRow("settings", "label", "varchar(50)", "varchar", "NO", "'it''s'", "", "", "").
+			AddRow("settings", "word", "varchar(50)", "varchar", "NO", "'NULL'", "", "", "").
+			AddRow("settings", "retries", "int(11)", "int", "YES", "NULL", "", "", "").
+			AddRow("settings", "ratio", "decimal(5,2)", "decimal", "NO", "1.50", "", "", "").
+			AddRow("settings", "token", "char(36)", "char", "YES", "uuid()", "", "", "").
+			AddRow("settings", "created_at", "timestamp", "timestamp", "NO", "current_timestamp()", "", "", ""))
+
+	tables := []TableSchema{{
+		Name: "settings",
+		CreateStmt: "CREATE TABLE `settings` (\n" +
+			"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+			"  `name` varchar(50) DEFAULT NULL,\n" +
+			"  `label` varchar(50) NOT NULL DEFAULT 'it''s',\n" +
+			"  `word` varchar(50) NOT NULL DEFAULT 'NULL',\n" +
+			"  `retries` int(11) DEFAULT NULL,\n" +
+			"  `ratio` decimal(5,2) NOT NULL DEFAULT 1.50,\n" +
+			"  `token` char(36) DEFAULT uuid(),\n" +
+			"  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),\n" +
+			"  PRIMARY KEY (`id`)\n" +
+			") ENGINE=InnoDB",
+	}}
+
+	tr := NewMySQLToPostgresTranslator("")
+	tr.MariaDB = true
+	translated, _, err := tr.TranslateTables(db, "app", tables)
+	if err != nil {
+		t.Fatalf("TranslateTables() error = %v", err)
+	}
+
+	want := "CREATE TABLE \"settings\" (\n" +
+		"    \"id\" integer GENERATED BY DEFAULT AS IDENTITY NOT NULL,\n" +
+		"    \"name\" varchar(50),\n" +
+		"    \"label\" varchar(50) DEFAULT 'it''s' NOT NULL,\n" +
+		"    \"word\" varchar(50) DEFAULT 'NULL' NOT NULL,\n" +
+		"    \"retries\" integer,\n" +
+		"    \"ratio\" numeric(5,2) DEFAULT 1.50 NOT NULL,\n" +
+		"    \"token\" char(36),\n" +
+		"    \"created_at\" timestamp(0) DEFAULT CURRENT_TIMESTAMP NOT NULL,\n" +
+		"    PRIMARY KEY (\"id\")\n" +
+		")"
+	if translated[0].CreateStmt != want {
+		t.Errorf("CreateStmt =\n%s\nwant\n%s", translated[0].CreateStmt, want)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestMariaDBColumnDefault(t *testing.T) {
+	tests := []struct {
+		value     sql.NullString
+		want      sql.NullString
+		generated bool
+	}{
+		{sql.NullString{}, sql.NullString{}, false},
+		{nullString("NULL"), sql.NullString{}, false},
+		{nullString("'NULL'"), nullString("NULL"), false},
+		{nullString("'abc'"), nullString("abc"), false},
+		{nullString("'it''s'"), nullString("it's"), false},
+		{nullString("''"), nullString(""), false},
+		{nullString("-3"), nullString("-3"), false},
+		{nullString("b'101'"), nullString("b'101'"), false},
+		{nullString("uuid()"), nullString("uuid()"), true},
+	}
+	for _, tt := range tests {
+		got, extra := mariaDBColumnDefault(tt.value, "")
+		if got != tt.want || (extra == "DEFAULT_GENERATED") != tt.generated {
+			t.Errorf("mariaDBColumnDefault(%q) = %q, %q, want %q, generated %v", tt.value.String, got.String, extra, tt.want.String, tt.generated)
+		}
+	}
+}
+
+func TestMySQLToPostgresTranslator_EnumType(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+	expectMySQLShopColumns(mock)
+
+	tables, types, err := NewMySQLToPostgresTranslator(EnumType).TranslateTables(db, "shop", mysqlShopTables())
+	if err != nil {
+		t.Fatalf("TranslateTables() error = %v", err)
+	}
+	want := []TypeDef{{Name: "users_role", Kind: "enum", CreateStmt: `CREATE TYPE "users_role" AS ENUM ('admin', 'member')`}}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("types = %+v, want %+v", types, want)
+	}
+	if !strings.Contains(tables[0].CreateStmt, `"role" "users_role" DEFAULT 'member' NOT NULL,`) {
+		t.Errorf("CreateStmt = %q, want the role column to use the enum type", tables[0].CreateStmt)
+	}
+}
+
+func TestMySQLToPostgresTranslator_ColumnDefault(t *testing.T) {
+	tests := []struct {
+		column mysqlColumn
+		pgType string
+		want   string
+		ok     bool
+	}{
+		{mysqlColumn{defaultValue: nullString("CURRENT_TIMESTAMP(6)"), extra: "DEFAULT_GENERATED"}, "timestamp(6)", "CURRENT_TIMESTAMP", true},
+		{mysqlColumn{defaultValue: nullString("(uuid())"), extra: "DEFAULT_GENERATED"}, "char(36)", "", false},
+		{mysqlColumn{defaultValue: nullString("0000-00-00 00:00:00")}, "timestamp(0)", "", false},
+		{mysqlColumn{defaultValue: nullString("2024-01-01")}, "date", "'2024-01-01'", true},
+		{mysqlColumn{defaultValue: nullString("b'101'")}, "bit(4)", "B'0101'", true},
+		{mysqlColumn{defaultValue: nullString("1.50")}, "numeric(5,2)", "1.50", true},
+		{mysqlColumn{defaultValue: nullString("it's")}, "varchar(10)", "'it''s'", true},
+		{mysqlColumn{}, "text", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NewMySQLToPostgresTranslator("").columnDefault("t.c", tt.column, tt.pgType)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("columnDefault(%q, %s) = %q, %v, want %q, %v", tt.column.defaultValue.String, tt.pgType, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+func TestParseMySQLIndexDefinition(t *testing.T) {
+	kind, parts, options := parseMySQLIndexDefinition("ADD UNIQUE INDEX `a``b` (`name`(10), `created_at` DESC, (lower(`x`))) USING HASH COMMENT 'why (not)' INVISIBLE")
+	if kind != "UNIQUE" {
+		t.Errorf("kind = %q, want UNIQUE", kind)
+	}
+	want := []mysqlKeyPart{
+		{column: "name", prefix: true},
+		{column: "created_at", descending: true},
+		{expression: true},
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Errorf("parts = %+v, want %+v", parts, want)
+	}
+	if !options["INVISIBLE"] || !options["HASH"] {
+		t.Errorf("options = %v, want HASH and INVISIBLE", options)
+	}
+}
+
+func TestIsZeroDate(t *testing.T) {
+	for value, want := range map[string]bool{
+		"0000-00-00":          true,
+		"0000-00-00 00:00:00": true,
+		"2024-00-10":          true,
+		"2024-03-00 10:00:00": true,
+		"2024-03-10":          false,
+		"12:00:00":            false,
+	} {
+		if got := IsZeroDate(value); got != want {
+			t.Errorf("IsZeroDate(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestSetArrayLiteral(t *testing.T) {
+	for value, want := range map[string]string{
+		"":         "{}",
+		"gift":     `{"gift"}`,
+		"a,b":      `{"a","b"}`,
+		`say "hi"`: `{"say \"hi\""}`,
+	} {
+		if got := SetArrayLiteral(value); got != want {
+			t.Errorf("SetArrayLiteral(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestUniqueIdentifier(t *testing.T) {
+	used := map[string]bool{"idx": true}
+	if got := uniqueIdentifier("idx", pgMaxIdentifierLength, used); got != "idx_2" {
+		t.Errorf("uniqueIdentifier(idx) = %q, want idx_2", got)
+	}
+	if got := uniqueIdentifier("idx", pgMaxIdentifierLength, used); got != "idx_3" {
+		t.Errorf("uniqueIdentifier(idx) again = %q, want idx_3", got)
+	}
+
+	long := strings.Repeat("é", 40) // 80 bytes
+	got := uniqueIdentifier(long, pgMaxIdentifierLength, used)
+	if len(got) > pgMaxIdentifierLength || !strings.HasPrefix(long, got) {
+		t.Errorf("uniqueIdentifier(long) = %q, want a whole-character prefix of at most 63 bytes", got)
+	}
+	if again := uniqueIdentifier(long, pgMaxIdentifierLength, used); len(again) > pgMaxIdentifierLength || !strings.HasSuffix(again, "_2") {
+		t.Errorf("uniqueIdentifier(long) again = %q, want a numbered variant within 63 bytes", again)
+	}
+}
+
+func TestPostgresApplier_SyncIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`SELECT setval\(pg_get_serial_sequence\('"users"', 'id'\), COALESCE\(MAX\("id"\), 0\) \+ 1, false\) FROM "users"`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = NewPostgresApplier().SyncIdentity(db, TableSchema{Name: "users"}, ColumnDef{Name: "id"})
+	if err != nil {
+		t.Errorf("SyncIdentity() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
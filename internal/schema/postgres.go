@@ -2,8 +2,8 @@ package schema
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/DGarbs51/lcmigrate/internal/dialect"
@@ -12,6 +12,7 @@ import (
 // PostgresExtractor extracts schema from PostgreSQL databases
 type PostgresExtractor struct {
 	Dialect dialect.Dialect
+	Filter  SchemaFilter
 }
 
 // NewPostgresExtractor creates a new PostgreSQL schema extractor
@@ -21,80 +22,241 @@ func NewPostgresExtractor() *PostgresExtractor {
 	}
 }
 
-// ExtractTables extracts all table schemas from the database
+// SetSchemaFilter restricts extraction to the schemas allowed by filter
+func (e *PostgresExtractor) SetSchemaFilter(filter SchemaFilter) {
+	e.Filter = filter
+}
+
+// userSchemaCondition returns a WHERE fragment excluding system schemas for the given column
+func userSchemaCondition(column string) string {
+	return fmt.Sprintf(`%s NOT IN ('pg_catalog', 'information_schema') AND %s NOT LIKE 'pg\_%%'`, column, column)
+}
+
+// hasCatalogColumn reports whether a system catalog has a column, for the ones newer
+// PostgreSQL versions added
+func hasCatalogColumn(db *sql.DB, catalog, column string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM pg_attribute
+			WHERE attrelid = $1::regclass AND attname = $2 AND NOT attisdropped
+		)
+	`, catalog, column).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s columns: %w", catalog, err)
+	}
+	return exists, nil
+}
+
+// notExtensionMember returns a WHERE fragment excluding objects owned by an extension,
+// given the catalog holding them and the object's oid; CREATE EXTENSION creates those
+func notExtensionMember(catalog, oid string) string {
+	return fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM pg_depend ext
+			WHERE ext.classid = '%s'::regclass AND ext.objid = %s AND ext.deptype = 'e'
+		)`, catalog, oid)
+}
+
+// ExtractSchemas lists the non-system schemas that pass the extractor's filter
+func (e *PostgresExtractor) ExtractSchemas(db *sql.DB, database string) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT nspname
+		FROM pg_namespace
+		WHERE ` + userSchemaCondition("nspname") + `
+		ORDER BY nspname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var schemas []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		if e.Filter.Allows(name) {
+			schemas = append(schemas, name)
+		}
+	}
+
+	return schemas, nil
+}
+
+// ExtractExtensions lists installed extensions and their versions
+// plpgsql is skipped since every database already has it
+func (e *PostgresExtractor) ExtractExtensions(db *sql.DB, database string) ([]ExtensionDef, error) {
+	rows, err := db.Query(`
+		SELECT e.extname, n.nspname, e.extversion
+		FROM pg_extension e
+		JOIN pg_namespace n ON n.oid = e.extnamespace
+		WHERE e.extname != 'plpgsql'
+		ORDER BY e.extname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list extensions: %w", err)
+	}
+	defer rows.Close()
+
+	var extensions []ExtensionDef
+	for rows.Next() {
+		var ext ExtensionDef
+		if err := rows.Scan(&ext.Name, &ext.Schema, &ext.Version); err != nil {
+			return nil, err
+		}
+		ext.CreateStmt = fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s WITH SCHEMA %s",
+			quoteIdentifier(ext.Name), quoteIdentifier(ext.Schema))
+		extensions = append(extensions, ext)
+	}
+
+	return extensions, nil
+}
+
+// ExtractTables extracts all table schemas, including partitioned parents, partitions and
+// inheritance children; tables are returned with parents ahead of their children
 func (e *PostgresExtractor) ExtractTables(db *sql.DB, database string) ([]TableSchema, error) {
 	rows, err := db.Query(`
-		SELECT table_name
-		FROM information_schema.tables
-		WHERE table_schema = 'public' AND table_type = 'BASE TABLE'
-		ORDER BY table_name
+		SELECT n.nspname, c.relname,
+			CASE WHEN c.relkind = 'p' THEN pg_get_partkeydef(c.oid) ELSE '' END as partition_key,
+			COALESCE(pg_get_expr(c.relpartbound, c.oid), '') as partition_bound,
+			COALESCE((
+				SELECT json_agg(json_build_array(pn.nspname, p.relname) ORDER BY i.inhseqno)::text
+				FROM pg_inherits i
+				JOIN pg_class p ON p.oid = i.inhparent
+				JOIN pg_namespace pn ON pn.oid = p.relnamespace
+				WHERE i.inhrelid = c.oid
+			), '[]') as parents,
+			c.relkind = 'r' AND EXISTS (SELECT 1 FROM pg_inherits ch WHERE ch.inhparent = c.oid) as has_children
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p')
+			AND ` + userSchemaCondition("n.nspname") + `
+			AND ` + notExtensionMember("pg_class", "c.oid") + `
+		ORDER BY n.nspname, c.relname
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tables: %w", err)
 	}
-	defer rows.Close()
 
 	var tables []TableSchema
+	var parents [][]pgTableRef
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
+		var table TableSchema
+		var parentsJSON string
+		if err := rows.Scan(&table.Schema, &table.Name, &table.PartitionKey, &table.PartitionBound, &parentsJSON, &table.HasChildren); err != nil {
+			rows.Close()
 			return nil, err
 		}
-
-		table, err := e.extractTable(db, tableName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to extract table %s: %w", tableName, err)
+		if !e.Filter.Allows(table.Schema) {
+			continue
+		}
+		var pairs [][2]string
+		if err := json.Unmarshal([]byte(parentsJSON), &pairs); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to parse parents of %s: %w", table.QualifiedName(), err)
+		}
+		var refs []pgTableRef
+		for _, pair := range pairs {
+			refs = append(refs, pgTableRef{schema: pair[0], name: pair[1]})
 		}
 		tables = append(tables, table)
+		parents = append(parents, refs)
 	}
+	rows.Close()
+
+	for i := range tables {
+		if err := e.extractTable(db, &tables[i], parents[i]); err != nil {
+			return nil, fmt.Errorf("failed to extract table %s: %w", tables[i].QualifiedName(), err)
+		}
+	}
+
+	return sortTablesByInheritance(tables), nil
+}
+
+// pgTableRef identifies a table by schema and name
+type pgTableRef struct{ schema, name string }
 
-	return tables, nil
+func (r pgTableRef) quoted() string {
+	return quoteQualified(r.schema, r.name)
+}
+
+// cascadeIndexToPartitions drops the ONLY from the CREATE INDEX ... ON ONLY pg_get_indexdef
+// writes for a partitioned parent, so creating the index also indexes every partition rather
+// than leaving the parent's index invalid until partition indexes are attached to it
+func cascadeIndexToPartitions(def string) string {
+	tokens := significantTokens(tokenizeSQL(def, postgresSyntax))
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].isKeyword("ON") && tokens[i+1].isKeyword("ONLY") {
+			return def[:tokens[i].End] + def[tokens[i+1].End:]
+		}
+	}
+	return def
 }
 
 // extractTable extracts the schema for a single table
-func (e *PostgresExtractor) extractTable(db *sql.DB, tableName string) (TableSchema, error) {
-	table := TableSchema{
-		Name: tableName,
+func (e *PostgresExtractor) extractTable(db *sql.DB, table *TableSchema, parents []pgTableRef) error {
+	schemaName, tableName := table.Schema, table.Name
+	if table.PartitionBound != "" && len(parents) == 1 {
+		table.PartitionOf = qualifiedName(parents[0].schema, parents[0].name)
+	} else {
+		for _, p := range parents {
+			table.Inherits = append(table.Inherits, qualifiedName(p.schema, p.name))
+		}
 	}
 
 	// Build CREATE TABLE statement from column information
-	createStmt, err := e.buildCreateTableStmt(db, tableName)
-	if err != nil {
-		return table, err
+	if err := e.buildCreateTableStmt(db, table, parents); err != nil {
+		return err
 	}
-	table.CreateStmt = createStmt
 
 	// Extract indexes (excluding primary key)
+	// Indexes backing UNIQUE and EXCLUDE constraints are recreated as constraints, so
+	// foreign keys that depend on them and constraint-level options carry over
+	// Partition indexes attached to a parent's index are left out; the parent's index creates them
 	rows, err := db.Query(`
 		SELECT
 			i.relname as index_name,
 			array_to_string(array_agg(a.attname ORDER BY k.n), ',') as columns,
 			ix.indisunique as is_unique,
-			pg_get_indexdef(ix.indexrelid) as index_def
+			pg_get_indexdef(ix.indexrelid) as index_def,
+			COALESCE(pg_get_constraintdef(c.oid), '') as constraint_def
 		FROM pg_index ix
 		JOIN pg_class i ON i.oid = ix.indexrelid
 		JOIN pg_class t ON t.oid = ix.indrelid
 		JOIN pg_namespace n ON n.oid = t.relnamespace
 		JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, n) ON true
-		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		LEFT JOIN pg_constraint c ON c.conindid = ix.indexrelid AND c.conrelid = t.oid AND c.contype IN ('u', 'x')
 		WHERE t.relname = $1
-			AND n.nspname = 'public'
+			AND n.nspname = $2
 			AND NOT ix.indisprimary
-		GROUP BY i.relname, ix.indisunique, ix.indexrelid
+			AND NOT EXISTS (SELECT 1 FROM pg_inherits pi WHERE pi.inhrelid = ix.indexrelid)
+		GROUP BY i.relname, ix.indisunique, ix.indexrelid, c.oid
 		ORDER BY i.relname
-	`, tableName)
+	`, tableName, schemaName)
 	if err != nil {
-		return table, fmt.Errorf("failed to get indexes: %w", err)
+		return fmt.Errorf("failed to get indexes: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var idx IndexDef
-		var columns string
-		if err := rows.Scan(&idx.Name, &columns, &idx.IsUnique, &idx.CreateStmt); err != nil {
-			return table, err
+		var columns, constraintDef string
+		if err := rows.Scan(&idx.Name, &columns, &idx.IsUnique, &idx.CreateStmt, &constraintDef); err != nil {
+			return err
+		}
+		if columns != "" {
+			idx.Columns = strings.Split(columns, ",")
+		}
+		if table.IsPartitioned() {
+			idx.CreateStmt = cascadeIndexToPartitions(idx.CreateStmt)
+		}
+		if constraintDef != "" {
+			idx.IsConstraint = true
+			idx.CreateStmt = fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s",
+				quoteQualified(schemaName, tableName), quoteIdentifier(idx.Name), constraintDef)
 		}
-		idx.Columns = strings.Split(columns, ",")
 		table.Indexes = append(table.Indexes, idx)
 	}
 
@@ -103,6 +265,7 @@ func (e *PostgresExtractor) extractTable(db *sql.DB, tableName string) (TableSch
 		SELECT
 			tc.constraint_name,
 			string_agg(kcu.column_name, ',' ORDER BY kcu.ordinal_position) as columns,
+			ccu.table_schema as ref_schema,
 			ccu.table_name as ref_table,
 			string_agg(ccu.column_name, ',' ORDER BY kcu.ordinal_position) as ref_columns,
 			rc.delete_rule,
@@ -113,37 +276,53 @@ func (e *PostgresExtractor) extractTable(db *sql.DB, tableName string) (TableSch
 			AND tc.table_schema = kcu.table_schema
 		JOIN information_schema.constraint_column_usage ccu
 			ON tc.constraint_name = ccu.constraint_name
-			AND tc.table_schema = ccu.table_schema
+			AND tc.constraint_schema = ccu.constraint_schema
 		JOIN information_schema.referential_constraints rc
 			ON tc.constraint_name = rc.constraint_name
 			AND tc.table_schema = rc.constraint_schema
+		JOIN pg_constraint pc
+			ON pc.conname = tc.constraint_name
+			AND pc.conrelid = format('%I.%I', tc.table_schema, tc.table_name)::regclass
 		WHERE tc.constraint_type = 'FOREIGN KEY'
 			AND tc.table_name = $1
-			AND tc.table_schema = 'public'
-		GROUP BY tc.constraint_name, ccu.table_name, rc.delete_rule, rc.update_rule
-	`, tableName)
+			AND tc.table_schema = $2
+			AND pc.conparentid = 0
+		GROUP BY tc.constraint_name, ccu.table_schema, ccu.table_name, rc.delete_rule, rc.update_rule
+	`, tableName, schemaName)
 	if err != nil {
-		return table, fmt.Errorf("failed to get foreign keys: %w", err)
+		return fmt.Errorf("failed to get foreign keys: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var fk ForeignKeyDef
 		var columns, refColumns string
-		if err := rows.Scan(&fk.Name, &columns, &fk.RefTable, &refColumns, &fk.OnDelete, &fk.OnUpdate); err != nil {
-			return table, err
+		if err := rows.Scan(&fk.Name, &columns, &fk.RefSchema, &fk.RefTable, &refColumns, &fk.OnDelete, &fk.OnUpdate); err != nil {
+			return err
 		}
 		fk.Columns = strings.Split(columns, ",")
 		fk.RefColumns = strings.Split(refColumns, ",")
-		fk.ConstraintStmt = e.buildAddForeignKeyStmt(tableName, fk)
+		fk.ConstraintStmt = e.buildAddForeignKeyStmt(schemaName, tableName, fk)
 		table.ForeignKeys = append(table.ForeignKeys, fk)
 	}
 
-	return table, nil
+	return nil
+}
+
+// pgStorageNames maps pg_attribute.attstorage codes to their SET STORAGE keywords
+var pgStorageNames = map[string]string{
+	"p": "PLAIN",
+	"e": "EXTERNAL",
+	"m": "MAIN",
+	"x": "EXTENDED",
 }
 
 // buildCreateTableStmt builds a CREATE TABLE statement from pg_catalog information
-func (e *PostgresExtractor) buildCreateTableStmt(db *sql.DB, tableName string) (string, error) {
+// Column storage and comments that CREATE TABLE can't express go into table.PostCreateStmts,
+// and NOT VALID checks are deferred to table.Indexes so they're added after the data load
+func (e *PostgresExtractor) buildCreateTableStmt(db *sql.DB, table *TableSchema, parents []pgTableRef) error {
+	regclass := quoteQualified(table.Schema, table.Name)
+
 	// Get column definitions
 	rows, err := db.Query(`
 		SELECT
@@ -151,79 +330,229 @@ func (e *PostgresExtractor) buildCreateTableStmt(db *sql.DB, tableName string) (
 			pg_catalog.format_type(a.atttypid, a.atttypmod) as data_type,
 			COALESCE(pg_get_expr(d.adbin, d.adrelid), '') as column_default,
 			a.attnotnull as not_null,
-			CASE WHEN a.attidentity != '' THEN true ELSE false END as is_identity
+			a.attidentity::text as identity,
+			COALESCE(ids.options, '') as identity_options,
+			a.attgenerated::text as generated,
+			CASE WHEN a.attcollation <> ty.typcollation
+				THEN format('%I.%I', cn.nspname, co.collname) ELSE '' END as collation,
+			CASE WHEN a.attstorage <> ty.typstorage THEN a.attstorage::text ELSE '' END as storage,
+			COALESCE(col_description(a.attrelid, a.attnum), '') as comment,
+			a.attislocal as is_local
 		FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_type ty ON ty.oid = a.atttypid
 		LEFT JOIN pg_catalog.pg_attrdef d ON (a.attrelid, a.attnum) = (d.adrelid, d.adnum)
+		LEFT JOIN pg_catalog.pg_collation co ON co.oid = a.attcollation
+		LEFT JOIN pg_catalog.pg_namespace cn ON cn.oid = co.collnamespace
+		LEFT JOIN LATERAL (
+			SELECT format('SEQUENCE NAME %I.%I START WITH %s INCREMENT BY %s MINVALUE %s MAXVALUE %s CACHE %s%s',
+				sn.nspname, sc.relname, sq.seqstart, sq.seqincrement, sq.seqmin, sq.seqmax, sq.seqcache,
+				CASE WHEN sq.seqcycle THEN ' CYCLE' ELSE '' END) as options
+			FROM pg_catalog.pg_depend dep
+			JOIN pg_catalog.pg_class sc ON sc.oid = dep.objid
+			JOIN pg_catalog.pg_namespace sn ON sn.oid = sc.relnamespace
+			JOIN pg_catalog.pg_sequence sq ON sq.seqrelid = sc.oid
+			WHERE dep.classid = 'pg_class'::regclass
+				AND dep.refobjid = a.attrelid
+				AND dep.refobjsubid = a.attnum
+				AND dep.deptype = 'i'
+		) ids ON a.attidentity != ''
 		WHERE a.attrelid = $1::regclass
 			AND a.attnum > 0
 			AND NOT a.attisdropped
 		ORDER BY a.attnum
-	`, tableName)
+	`, regclass)
 	if err != nil {
-		return "", fmt.Errorf("failed to get columns: %w", err)
+		return fmt.Errorf("failed to get columns: %w", err)
 	}
 	defer rows.Close()
 
 	var columns []string
 	for rows.Next() {
-		var colName, dataType, colDefault string
-		var notNull, isIdentity bool
-		if err := rows.Scan(&colName, &dataType, &colDefault, &notNull, &isIdentity); err != nil {
-			return "", err
-		}
-
-		// Convert integer + nextval default to SERIAL/BIGSERIAL types
-		// This avoids needing to create sequences separately
-		if strings.Contains(colDefault, "nextval(") {
-			switch dataType {
-			case "bigint":
-				dataType = "BIGSERIAL"
-			case "integer":
-				dataType = "SERIAL"
-			case "smallint":
-				dataType = "SMALLSERIAL"
-			}
-			colDefault = "" // SERIAL types handle their own sequence
+		var colName, dataType, colDefault, identity, identityOptions, generated, collation, storage, comment string
+		var notNull, isLocal bool
+		if err := rows.Scan(&colName, &dataType, &colDefault, &notNull, &identity, &identityOptions, &generated, &collation, &storage, &comment, &isLocal); err != nil {
+			return err
+		}
+
+		col := ColumnDef{
+			Name:       colName,
+			DataType:   dataType,
+			IsNullable: !notNull,
 		}
 
+		// nextval defaults are kept verbatim; their sequences are created before the table
 		colDef := fmt.Sprintf("    %s %s", quoteIdentifier(colName), dataType)
-		if colDefault != "" && !isIdentity {
+		if collation != "" {
+			colDef += " COLLATE " + collation
+		}
+		switch {
+		case identity != "":
+			// The identity sequence is named explicitly so it matches the source
+			mode := "BY DEFAULT"
+			if identity == "a" {
+				mode = "ALWAYS"
+			}
+			colDef += fmt.Sprintf(" GENERATED %s AS IDENTITY (%s)", mode, identityOptions)
+			col.Extra = "IDENTITY " + mode
+		case generated == "s":
+			// pg_attrdef holds the generation expression rather than a default
+			colDef += fmt.Sprintf(" GENERATED ALWAYS AS (%s) STORED", colDefault)
+			col.Extra = "STORED GENERATED"
+		case colDefault != "":
 			colDef += " DEFAULT " + colDefault
+			col.DefaultValue = sql.NullString{String: colDefault, Valid: true}
 		}
-		if notNull && !strings.Contains(dataType, "SERIAL") {
-			// SERIAL types are implicitly NOT NULL
+		if notNull {
 			colDef += " NOT NULL"
 		}
-		columns = append(columns, colDef)
+		// Inherited columns come from the parent's definition
+		if isLocal {
+			columns = append(columns, colDef)
+		}
+		table.Columns = append(table.Columns, col)
+
+		if name, ok := pgStorageNames[storage]; ok {
+			table.PostCreateStmts = append(table.PostCreateStmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET STORAGE %s",
+				regclass, quoteIdentifier(colName), name))
+		}
+		if comment != "" {
+			table.PostCreateStmts = append(table.PostCreateStmts, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s",
+				regclass, quoteIdentifier(colName), quoteLiteral(comment)))
+		}
+	}
+
+	// Get primary key and CHECK constraints (inherited checks come from the parent)
+	rows, err = db.Query(`
+		SELECT conname, contype::text, pg_get_constraintdef(oid) as definition, convalidated
+		FROM pg_constraint
+		WHERE conrelid = $1::regclass
+			AND contype IN ('p', 'c')
+			AND conislocal
+		ORDER BY contype = 'p' DESC, conname
+	`, regclass)
+	if err != nil {
+		return fmt.Errorf("failed to get constraints: %w", err)
 	}
+	defer rows.Close()
 
-	// Get primary key
-	var pkColumns string
-	err = db.QueryRow(`
-		SELECT string_agg(a.attname, ', ' ORDER BY array_position(i.indkey, a.attnum))
-		FROM pg_index i
-		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
-		WHERE i.indrelid = $1::regclass AND i.indisprimary
-	`, tableName).Scan(&pkColumns)
-	if err != nil && err != sql.ErrNoRows {
-		return "", fmt.Errorf("failed to get primary key: %w", err)
+	for rows.Next() {
+		var name, contype, definition string
+		var validated bool
+		if err := rows.Scan(&name, &contype, &definition, &validated); err != nil {
+			return err
+		}
+		if !validated {
+			// Existing rows may violate a NOT VALID check, so add it once the data is in
+			table.Indexes = append(table.Indexes, IndexDef{
+				Name:         name,
+				IsConstraint: true,
+				CreateStmt:   fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s", regclass, quoteIdentifier(name), definition),
+			})
+			continue
+		}
+		if contype == "p" {
+			table.PrimaryKey = parseConstraintColumns(definition)
+		}
+		columns = append(columns, fmt.Sprintf("    CONSTRAINT %s %s", quoteIdentifier(name), definition))
 	}
 
-	var pkConstraint string
-	if pkColumns != "" {
-		pkConstraint = fmt.Sprintf(",\n    PRIMARY KEY (%s)", pkColumns)
+	// Get table comment
+	var tableComment string
+	err = db.QueryRow(`SELECT COALESCE(obj_description($1::regclass, 'pg_class'), '')`, regclass).Scan(&tableComment)
+	if err != nil {
+		return fmt.Errorf("failed to get table comment: %w", err)
+	}
+	if tableComment != "" {
+		table.PostCreateStmts = append(table.PostCreateStmts, fmt.Sprintf("COMMENT ON TABLE %s IS %s", regclass, quoteLiteral(tableComment)))
 	}
 
-	stmt := fmt.Sprintf("CREATE TABLE %s (\n%s%s\n)",
-		quoteIdentifier(tableName),
-		strings.Join(columns, ",\n"),
-		pkConstraint)
+	switch {
+	case table.PartitionOf != "":
+		// Partitions take their columns from the parent; only local constraints are listed
+		stmt := fmt.Sprintf("CREATE TABLE %s PARTITION OF %s", regclass, parents[0].quoted())
+		if len(columns) > 0 {
+			stmt += fmt.Sprintf(" (\n%s\n)", strings.Join(columns, ",\n"))
+		}
+		table.CreateStmt = stmt + " " + table.PartitionBound
+	default:
+		table.CreateStmt = fmt.Sprintf("CREATE TABLE %s (\n%s\n)",
+			regclass,
+			strings.Join(columns, ",\n"))
+		if len(parents) > 0 {
+			quoted := make([]string, len(parents))
+			for i, p := range parents {
+				quoted[i] = p.quoted()
+			}
+			table.CreateStmt += fmt.Sprintf(" INHERITS (%s)", strings.Join(quoted, ", "))
+		}
+	}
+	if table.PartitionKey != "" {
+		table.CreateStmt += " PARTITION BY " + table.PartitionKey
+	}
 
-	return stmt, nil
+	return nil
+}
+
+// sortTablesByInheritance orders tables so partitioned parents and inheritance parents
+// are created before their children, keeping the original order otherwise
+func sortTablesByInheritance(tables []TableSchema) []TableSchema {
+	present := make(map[string]bool)
+	for _, t := range tables {
+		present[t.QualifiedName()] = true
+	}
+
+	sorted := make([]TableSchema, 0, len(tables))
+	added := make(map[string]bool)
+	for len(sorted) < len(tables) {
+		// Take the first table whose parents are all in place, then rescan from the top
+		next := -1
+		for i, t := range tables {
+			if added[t.QualifiedName()] {
+				continue
+			}
+			ready := true
+			for _, parent := range t.Parents() {
+				if present[parent] && !added[parent] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		// Inheritance can't be cyclic, but never spin on bad input
+		if next < 0 {
+			for _, t := range tables {
+				if !added[t.QualifiedName()] {
+					sorted = append(sorted, t)
+				}
+			}
+			break
+		}
+		sorted = append(sorted, tables[next])
+		added[tables[next].QualifiedName()] = true
+	}
+	return sorted
+}
+
+// parseConstraintColumns returns the column list of a PRIMARY KEY (...) or UNIQUE (...) definition
+func parseConstraintColumns(definition string) []string {
+	open := strings.Index(definition, "(")
+	end := strings.Index(definition, ")")
+	if open < 0 || end < open {
+		return nil
+	}
+	var cols []string
+	for _, col := range strings.Split(definition[open+1:end], ",") {
+		cols = append(cols, strings.Trim(strings.TrimSpace(col), `"`))
+	}
+	return cols
 }
 
 // buildAddForeignKeyStmt builds an ALTER TABLE ADD CONSTRAINT statement
-func (e *PostgresExtractor) buildAddForeignKeyStmt(tableName string, fk ForeignKeyDef) string {
+func (e *PostgresExtractor) buildAddForeignKeyStmt(schemaName, tableName string, fk ForeignKeyDef) string {
 	quotedCols := make([]string, len(fk.Columns))
 	for i, col := range fk.Columns {
 		quotedCols[i] = quoteIdentifier(col)
@@ -234,10 +563,10 @@ func (e *PostgresExtractor) buildAddForeignKeyStmt(tableName string, fk ForeignK
 	}
 
 	stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
-		quoteIdentifier(tableName),
+		quoteQualified(schemaName, tableName),
 		quoteIdentifier(fk.Name),
 		strings.Join(quotedCols, ", "),
-		quoteIdentifier(fk.RefTable),
+		quoteQualified(fk.RefSchema, fk.RefTable),
 		strings.Join(quotedRefCols, ", "))
 
 	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
@@ -253,10 +582,12 @@ func (e *PostgresExtractor) buildAddForeignKeyStmt(tableName string, fk ForeignK
 // ExtractViews extracts all view definitions
 func (e *PostgresExtractor) ExtractViews(db *sql.DB, database string) ([]ViewDef, error) {
 	rows, err := db.Query(`
-		SELECT viewname, pg_get_viewdef(viewname::regclass, true) as view_def
+		SELECT schemaname, viewname,
+			pg_get_viewdef(format('%I.%I', schemaname, viewname)::regclass, true) as view_def
 		FROM pg_views
-		WHERE schemaname = 'public'
-		ORDER BY viewname
+		WHERE ` + userSchemaCondition("schemaname") + `
+			AND ` + notExtensionMember("pg_class", "format('%I.%I', schemaname, viewname)::regclass") + `
+		ORDER BY schemaname, viewname
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list views: %w", err)
@@ -267,35 +598,165 @@ func (e *PostgresExtractor) ExtractViews(db *sql.DB, database string) ([]ViewDef
 	for rows.Next() {
 		var view ViewDef
 		var viewDef string
-		if err := rows.Scan(&view.Name, &viewDef); err != nil {
+		if err := rows.Scan(&view.Schema, &view.Name, &viewDef); err != nil {
 			return nil, err
 		}
-		view.CreateStmt = fmt.Sprintf("CREATE VIEW %s AS\n%s", quoteIdentifier(view.Name), viewDef)
-		view.Dependencies = extractPgViewDependencies(viewDef)
+		if !e.Filter.Allows(view.Schema) {
+			continue
+		}
+		view.CreateStmt = fmt.Sprintf("CREATE VIEW %s AS\n%s", quoteQualified(view.Schema, view.Name), viewDef)
 		views = append(views, view)
 	}
+	rows.Close()
+
+	matviews, err := e.extractMaterializedViews(db)
+	if err != nil {
+		return nil, err
+	}
+	views = append(views, matviews...)
+
+	// The rewrite rules record exactly which relations each view reads, including
+	// ones only referenced in CTEs, subqueries or function arguments
+	deps, err := e.extractViewDependencyGraph(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range views {
+		views[i].Dependencies = deps[views[i].QualifiedName()]
+	}
+
+	// Sort by dependency order (views and materialized views can depend on each other)
+	return sortViewsByDependency(views)
+}
+
+// extractViewDependencyGraph maps each view and materialized view to the relations it reads,
+// using the dependencies of its _RETURN rule in pg_depend
+func (e *PostgresExtractor) extractViewDependencyGraph(db *sql.DB) (map[string][]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT vn.nspname, v.relname, rn.nspname, r.relname
+		FROM pg_depend d
+		JOIN pg_rewrite rw ON rw.oid = d.objid
+		JOIN pg_class v ON v.oid = rw.ev_class
+		JOIN pg_namespace vn ON vn.oid = v.relnamespace
+		JOIN pg_class r ON r.oid = d.refobjid
+		JOIN pg_namespace rn ON rn.oid = r.relnamespace
+		WHERE d.classid = 'pg_rewrite'::regclass
+			AND d.refclassid = 'pg_class'::regclass
+			AND d.deptype = 'n'
+			AND v.relkind IN ('v', 'm')
+			AND r.oid <> v.oid
+			AND ` + userSchemaCondition("vn.nspname") + `
+		ORDER BY 1, 2, 3, 4
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read view dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	deps := make(map[string][]string)
+	for rows.Next() {
+		var viewSchema, viewName, refSchema, refName string
+		if err := rows.Scan(&viewSchema, &viewName, &refSchema, &refName); err != nil {
+			return nil, err
+		}
+		view := qualifiedName(viewSchema, viewName)
+		deps[view] = append(deps[view], qualifiedName(refSchema, refName))
+	}
+	return deps, rows.Err()
+}
+
+// extractMaterializedViews extracts materialized views and their indexes
+// They're created WITH NO DATA and populated by a refresh once everything else exists
+func (e *PostgresExtractor) extractMaterializedViews(db *sql.DB) ([]ViewDef, error) {
+	rows, err := db.Query(`
+		SELECT schemaname, matviewname,
+			pg_get_viewdef(format('%I.%I', schemaname, matviewname)::regclass, true) as view_def,
+			ispopulated
+		FROM pg_matviews
+		WHERE ` + userSchemaCondition("schemaname") + `
+			AND ` + notExtensionMember("pg_class", "format('%I.%I', schemaname, matviewname)::regclass") + `
+		ORDER BY schemaname, matviewname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list materialized views: %w", err)
+	}
 
-	// Sort by dependency order
-	views = sortViewsByDependency(views)
+	var views []ViewDef
+	for rows.Next() {
+		view := ViewDef{Materialized: true}
+		var viewDef string
+		if err := rows.Scan(&view.Schema, &view.Name, &viewDef, &view.Populated); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !e.Filter.Allows(view.Schema) {
+			continue
+		}
+		// pg_get_viewdef ends with a semicolon, which can't precede WITH NO DATA
+		viewDef = strings.TrimSuffix(strings.TrimSpace(viewDef), ";")
+		view.CreateStmt = fmt.Sprintf("CREATE MATERIALIZED VIEW %s AS\n%s\nWITH NO DATA", quoteQualified(view.Schema, view.Name), viewDef)
+		views = append(views, view)
+	}
+	rows.Close()
+
+	for i := range views {
+		view := &views[i]
+		rows, err := db.Query(`
+			SELECT i.relname, pg_get_indexdef(ix.indexrelid)
+			FROM pg_index ix
+			JOIN pg_class i ON i.oid = ix.indexrelid
+			WHERE ix.indrelid = $1::regclass
+			ORDER BY i.relname
+		`, quoteQualified(view.Schema, view.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get indexes for materialized view %s: %w", view.QualifiedName(), err)
+		}
+		for rows.Next() {
+			var idx IndexDef
+			if err := rows.Scan(&idx.Name, &idx.CreateStmt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			view.Indexes = append(view.Indexes, idx)
+		}
+		rows.Close()
+	}
 
 	return views, nil
 }
 
-// ExtractSequences extracts all sequence definitions
+// ExtractSequences extracts all sequence definitions, options, ownership and current values
 func (e *PostgresExtractor) ExtractSequences(db *sql.DB, database string) ([]SequenceDef, error) {
 	// Use pg_catalog directly to avoid information_schema compatibility issues
 	// with some PostgreSQL providers (e.g., Neon, Laravel Cloud)
+	// last_value is NULL until the sequence is first used (is_called = false)
 	rows, err := db.Query(`
 		SELECT
+			s.schemaname,
 			s.sequencename,
-			d.refobjid::regclass::text as owned_by,
-			COALESCE(s.last_value, 1) as current_val
+			s.data_type::text,
+			s.start_value,
+			s.min_value,
+			s.max_value,
+			s.increment_by,
+			s.cache_size,
+			s.cycle,
+			s.last_value,
+			COALESCE(tn.nspname, '') as owner_schema,
+			COALESCE(t.relname, '') as owner_table,
+			COALESCE(a.attname, '') as owner_column,
+			COALESCE(d.deptype::text, '') as dep_type
 		FROM pg_sequences s
-		LEFT JOIN pg_depend d ON d.objid = (s.schemaname || '.' || s.sequencename)::regclass
-			AND d.deptype = 'a'
+		LEFT JOIN pg_depend d ON d.objid = format('%I.%I', s.schemaname, s.sequencename)::regclass
+			AND d.deptype IN ('a', 'i')
 			AND d.classid = 'pg_class'::regclass
-		WHERE s.schemaname = 'public'
-		ORDER BY s.sequencename
+			AND d.refclassid = 'pg_class'::regclass
+		LEFT JOIN pg_class t ON t.oid = d.refobjid
+		LEFT JOIN pg_namespace tn ON tn.oid = t.relnamespace
+		LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE ` + userSchemaCondition("s.schemaname") + `
+			AND ` + notExtensionMember("pg_class", "format('%I.%I', s.schemaname, s.sequencename)::regclass") + `
+		ORDER BY s.schemaname, s.sequencename
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sequences: %w", err)
@@ -305,12 +766,39 @@ func (e *PostgresExtractor) ExtractSequences(db *sql.DB, database string) ([]Seq
 	var sequences []SequenceDef
 	for rows.Next() {
 		var seq SequenceDef
-		var ownedBy sql.NullString
-		if err := rows.Scan(&seq.Name, &ownedBy, &seq.CurrentVal); err != nil {
+		var dataType, ownerSchema, ownerTable, ownerColumn, depType string
+		var start, minValue, maxValue, increment, cache int64
+		var cycle bool
+		var lastValue sql.NullInt64
+		if err := rows.Scan(&seq.Schema, &seq.Name, &dataType, &start, &minValue, &maxValue, &increment, &cache, &cycle,
+			&lastValue, &ownerSchema, &ownerTable, &ownerColumn, &depType); err != nil {
 			return nil, err
 		}
-		seq.OwnedBy = ownedBy.String
-		seq.CreateStmt = fmt.Sprintf("CREATE SEQUENCE %s", quoteIdentifier(seq.Name))
+		if !e.Filter.Allows(seq.Schema) {
+			continue
+		}
+
+		seq.CurrentVal = start
+		if lastValue.Valid {
+			seq.CurrentVal = lastValue.Int64
+			seq.IsCalled = true
+		}
+		seq.Identity = depType == "i"
+
+		cycleStr := "NO CYCLE"
+		if cycle {
+			cycleStr = "CYCLE"
+		}
+		seq.CreateStmt = fmt.Sprintf("CREATE SEQUENCE %s AS %s START WITH %d INCREMENT BY %d MINVALUE %d MAXVALUE %d CACHE %d %s",
+			quoteQualified(seq.Schema, seq.Name), dataType, start, increment, minValue, maxValue, cache, cycleStr)
+
+		if ownerTable != "" && ownerColumn != "" {
+			seq.OwnedBy = qualifiedName(ownerSchema, ownerTable) + "." + ownerColumn
+		}
+		if seq.OwnedBy != "" && !seq.Identity {
+			seq.OwnedByStmt = fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.%s",
+				quoteQualified(seq.Schema, seq.Name), quoteQualified(ownerSchema, ownerTable), quoteIdentifier(ownerColumn))
+		}
 		sequences = append(sequences, seq)
 	}
 
@@ -335,7 +823,35 @@ func NewPostgresApplier() *PostgresApplier {
 func (a *PostgresApplier) CreateTable(db *sql.DB, table TableSchema) error {
 	_, err := db.Exec(table.CreateStmt)
 	if err != nil {
-		return fmt.Errorf("failed to create table %s: %w", table.Name, err)
+		return fmt.Errorf("failed to create table %s: %w", table.DisplayName(), err)
+	}
+	for _, stmt := range table.PostCreateStmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to configure table %s: %w", table.DisplayName(), err)
+		}
+	}
+	return nil
+}
+
+// CreateSchema creates a schema if it does not already exist
+func (a *PostgresApplier) CreateSchema(db *sql.DB, name string) error {
+	_, err := db.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", a.Dialect.QuoteIdentifier(name)))
+	if err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", name, err)
+	}
+	return nil
+}
+
+// CreateExtension creates an extension, along with the schema it installs into
+func (a *PostgresApplier) CreateExtension(db *sql.DB, ext ExtensionDef) error {
+	if ext.Schema != "" {
+		if err := a.CreateSchema(db, ext.Schema); err != nil {
+			return err
+		}
+	}
+	_, err := db.Exec(ext.CreateStmt)
+	if err != nil {
+		return fmt.Errorf("failed to create extension %s: %w", ext.Name, err)
 	}
 	return nil
 }
@@ -343,8 +859,10 @@ func (a *PostgresApplier) CreateTable(db *sql.DB, table TableSchema) error {
 // CreateSequence creates a sequence
 // PostgreSQL-specific: handles SERIAL auto-creation gracefully
 func (a *PostgresApplier) CreateSequence(db *sql.DB, seq SequenceDef) error {
-	// Sequences are typically created automatically with SERIAL/BIGSERIAL columns
-	// This handles standalone sequences
+	// Identity sequences are created by their table's CREATE TABLE
+	if seq.Identity {
+		return nil
+	}
 	_, err := db.Exec(seq.CreateStmt)
 	if err != nil {
 		// Ignore if already exists (likely auto-created)
@@ -355,12 +873,47 @@ func (a *PostgresApplier) CreateSequence(db *sql.DB, seq SequenceDef) error {
 	return nil
 }
 
+// CreateView creates a view, or a materialized view along with its indexes
+func (a *PostgresApplier) CreateView(db *sql.DB, view ViewDef) error {
+	if !view.Materialized {
+		return a.BaseApplier.CreateView(db, view)
+	}
+	if _, err := db.Exec(view.CreateStmt); err != nil {
+		return fmt.Errorf("failed to create materialized view %s: %w", view.QualifiedName(), err)
+	}
+	for _, idx := range view.Indexes {
+		if err := a.CreateIndex(db, idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshMaterializedView populates a materialized view created WITH NO DATA
+func (a *PostgresApplier) RefreshMaterializedView(db *sql.DB, view ViewDef) error {
+	if _, err := db.Exec("REFRESH MATERIALIZED VIEW " + quoteQualified(view.Schema, view.Name)); err != nil {
+		return fmt.Errorf("failed to refresh materialized view %s: %w", view.QualifiedName(), err)
+	}
+	return nil
+}
+
+// SetSequenceOwner ties a sequence to its owning column (OWNED BY), so it's dropped with the column
+func (a *PostgresApplier) SetSequenceOwner(db *sql.DB, seq SequenceDef) error {
+	if seq.OwnedByStmt == "" {
+		return nil
+	}
+	if _, err := db.Exec(seq.OwnedByStmt); err != nil {
+		return fmt.Errorf("failed to set owner of sequence %s: %w", seq.QualifiedName(), err)
+	}
+	return nil
+}
+
 // SetSequenceValue sets the current value of a sequence
 func (a *PostgresApplier) SetSequenceValue(db *sql.DB, seq SequenceDef) error {
-	_, err := db.Exec(fmt.Sprintf("SELECT setval(%s, $1, true)",
-		a.Dialect.QuoteLiteral(seq.Name)), seq.CurrentVal)
+	_, err := db.Exec(fmt.Sprintf("SELECT setval(%s, $1, $2)",
+		a.Dialect.QuoteLiteral(a.Dialect.QuoteQualifiedIdentifier(seq.Schema, seq.Name))), seq.CurrentVal, seq.IsCalled)
 	if err != nil {
-		return fmt.Errorf("failed to set sequence value for %s: %w", seq.Name, err)
+		return fmt.Errorf("failed to set sequence value for %s: %w", seq.QualifiedName(), err)
 	}
 	return nil
 }
@@ -370,26 +923,15 @@ func quoteIdentifier(name string) string {
 	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
 }
 
+// quoteQualified quotes a schema-qualified PostgreSQL identifier
+func quoteQualified(schema, name string) string {
+	if schema == "" {
+		return quoteIdentifier(name)
+	}
+	return quoteIdentifier(schema) + "." + quoteIdentifier(name)
+}
+
 // quoteLiteral quotes a PostgreSQL string literal
 func quoteLiteral(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
-
-// extractPgViewDependencies extracts table/view names from a view definition
-func extractPgViewDependencies(viewDef string) []string {
-	re := regexp.MustCompile(`(?i)(?:FROM|JOIN)\s+"?([a-zA-Z_][a-zA-Z0-9_]*)"?`)
-	matches := re.FindAllStringSubmatch(viewDef, -1)
-
-	deps := make(map[string]bool)
-	for _, match := range matches {
-		if len(match) > 1 {
-			deps[match[1]] = true
-		}
-	}
-
-	var result []string
-	for dep := range deps {
-		result = append(result, dep)
-	}
-	return result
-}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ExtractRoutines extracts functions and procedures using pg_get_functiondef
+// Aggregates, window functions and extension-owned routines are skipped
+func (e *PostgresExtractor) ExtractRoutines(db *sql.DB, database string) ([]RoutineDef, error) {
+	rows, err := db.Query(`
+		SELECT n.nspname, p.proname,
+			pg_get_function_identity_arguments(p.oid) as arguments,
+			p.prokind,
+			pg_get_functiondef(p.oid) as definition
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE ` + userSchemaCondition("n.nspname") + `
+			AND p.prokind IN ('f', 'p')
+			AND ` + notExtensionMember("pg_proc", "p.oid") + `
+		ORDER BY n.nspname, p.proname, arguments
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list functions: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []RoutineDef
+	for rows.Next() {
+		var routine RoutineDef
+		var kind string
+		if err := rows.Scan(&routine.Schema, &routine.Name, &routine.Arguments, &kind, &routine.CreateStmt); err != nil {
+			return nil, err
+		}
+		if !e.Filter.Allows(routine.Schema) {
+			continue
+		}
+		routine.Kind = "function"
+		if kind == "p" {
+			routine.Kind = "procedure"
+		}
+		routines = append(routines, routine)
+	}
+
+	return routines, nil
+}
+
+// ExtractTriggers extracts user triggers using pg_get_triggerdef
+// Internal triggers (such as those backing foreign keys) are skipped, and so are the clones
+// PostgreSQL 13+ makes of a partitioned table's triggers: creating the parent's makes them again
+func (e *PostgresExtractor) ExtractTriggers(db *sql.DB, database string) ([]TriggerDef, error) {
+	hasParent, err := hasCatalogColumn(db, "pg_trigger", "tgparentid")
+	if err != nil {
+		return nil, err
+	}
+	notClone := ""
+	if hasParent {
+		notClone = "\n\t\t\tAND t.tgparentid = 0"
+	}
+
+	rows, err := db.Query(`
+		SELECT n.nspname, c.relname, t.tgname, pg_get_triggerdef(t.oid) as definition
+		FROM pg_trigger t
+		JOIN pg_class c ON c.oid = t.tgrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE NOT t.tgisinternal
+			AND ` + userSchemaCondition("n.nspname") + `
+			AND ` + notExtensionMember("pg_class", "c.oid") + notClone + `
+		ORDER BY n.nspname, c.relname, t.tgname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []TriggerDef
+	for rows.Next() {
+		var trigger TriggerDef
+		if err := rows.Scan(&trigger.Schema, &trigger.Table, &trigger.Name, &trigger.CreateStmt); err != nil {
+			return nil, err
+		}
+		if !e.Filter.Allows(trigger.Schema) {
+			continue
+		}
+		triggers = append(triggers, trigger)
+	}
+
+	return triggers, nil
+}
+
+// CreateRoutine creates a function or procedure with check_function_bodies off, as pg_dump
+// does, so SQL functions that read tables can be created before the tables exist
+func (a *PostgresApplier) CreateRoutine(db *sql.DB, routine RoutineDef) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SET LOCAL check_function_bodies = off"); err != nil {
+		return fmt.Errorf("failed to turn off check_function_bodies: %w", err)
+	}
+	if _, err := tx.Exec(routine.CreateStmt); err != nil {
+		return fmt.Errorf("failed to create %s %s: %w", routine.Kind, routine.Signature(), err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,208 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestPostgresExtractor_ExtractRoutines(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	mock.ExpectQuery("SELECT n.nspname, p.proname.*FROM pg_proc p").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "proname", "arguments", "prokind", "definition"}).
+			AddRow("public", "touch_updated_at", "", "f", "CREATE OR REPLACE FUNCTION public.touch_updated_at()\n RETURNS trigger ...").
+			AddRow("audit", "record", "tbl text, payload jsonb", "p", "CREATE OR REPLACE PROCEDURE audit.record(tbl text, payload jsonb) ..."))
+
+	routines, err := ext.ExtractRoutines(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractRoutines() error = %v", err)
+	}
+	if len(routines) != 2 {
+		t.Fatalf("ExtractRoutines() returned %d routines, want 2", len(routines))
+	}
+	if routines[0].Kind != "function" || routines[1].Kind != "procedure" {
+		t.Errorf("kinds = %q, %q, want function, procedure", routines[0].Kind, routines[1].Kind)
+	}
+	if got := routines[1].Signature(); got != "audit.record(tbl text, payload jsonb)" {
+		t.Errorf("Signature() = %q, want %q", got, "audit.record(tbl text, payload jsonb)")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractRoutines_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	mock.ExpectQuery("SELECT n.nspname, p.proname.*FROM pg_proc p").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	if _, err := ext.ExtractRoutines(db, "testdb"); err == nil {
+		t.Errorf("ExtractRoutines() expected error, got nil")
+	}
+}
+
+func TestPostgresExtractor_ExtractTriggers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+	ext.SetSchemaFilter(SchemaFilter{Exclude: []string{"scratch"}})
+
+	mock.ExpectQuery("SELECT EXISTS.*FROM pg_attribute").
+		WithArgs("pg_trigger", "tgparentid").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery("SELECT n.nspname, c.relname, t.tgname.*FROM pg_trigger t").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "tgname", "definition"}).
+			AddRow("public", "users", "users_touch", "CREATE TRIGGER users_touch BEFORE UPDATE ON public.users FOR EACH ROW EXECUTE FUNCTION touch_updated_at()").
+			AddRow("scratch", "tmp", "tmp_touch", "CREATE TRIGGER tmp_touch ..."))
+
+	triggers, err := ext.ExtractTriggers(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTriggers() error = %v", err)
+	}
+	if len(triggers) != 1 {
+		t.Fatalf("ExtractTriggers() returned %d triggers, want 1", len(triggers))
+	}
+	if triggers[0].Table != "users" || triggers[0].Name != "users_touch" {
+		t.Errorf("triggers[0] = %+v", triggers[0])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractTriggers_PartitionClones(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	// The row trigger on the measurements parent is cloned onto each partition; only the
+	// parent's is returned, since creating it on the destination clones it again
+	mock.ExpectQuery("SELECT EXISTS.*FROM pg_attribute").
+		WithArgs("pg_trigger", "tgparentid").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT n.nspname, c.relname, t.tgname.*FROM pg_trigger t.*AND t.tgparentid = 0").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "tgname", "definition"}).
+			AddRow("public", "measurements", "measurements_touch", "CREATE TRIGGER measurements_touch BEFORE UPDATE ON public.measurements FOR EACH ROW EXECUTE FUNCTION touch_updated_at()"))
+
+	triggers, err := ext.ExtractTriggers(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTriggers() error = %v", err)
+	}
+	if len(triggers) != 1 || triggers[0].Table != "measurements" {
+		t.Errorf("ExtractTriggers() = %+v, want only the measurements trigger", triggers)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresApplier_CreateRoutine_ErrorNamesSignature(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewPostgresApplier()
+	routine := RoutineDef{
+		Schema:     "public",
+		Name:       "add",
+		Kind:       "function",
+		Arguments:  "a integer, b integer",
+		CreateStmt: "CREATE OR REPLACE FUNCTION public.add(a integer, b integer) ...",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL check_function_bodies = off").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE OR REPLACE FUNCTION public.add").
+		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+
+	err = app.CreateRoutine(db, routine)
+	if err == nil {
+		t.Fatalf("CreateRoutine() expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "function public.add(a integer, b integer)") {
+		t.Errorf("CreateRoutine() error = %q, want it to include the signature", err)
+	}
+}
+
+func TestPostgresApplier_CreateRoutine_WithoutBodyChecks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// SQL functions reading tables are created along with the schema, before the tables
+	routine := RoutineDef{
+		Schema:     "public",
+		Name:       "order_count",
+		Kind:       "function",
+		CreateStmt: "CREATE OR REPLACE FUNCTION public.order_count() RETURNS bigint LANGUAGE sql AS $$ SELECT count(*) FROM orders $$",
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL check_function_bodies = off").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE OR REPLACE FUNCTION public.order_count").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	if err := NewPostgresApplier().CreateRoutine(db, routine); err != nil {
+		t.Fatalf("CreateRoutine() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestBaseApplier_CreateTrigger(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewPostgresApplier()
+	trigger := TriggerDef{
+		Schema:     "public",
+		Table:      "users",
+		Name:       "users_touch",
+		CreateStmt: "CREATE TRIGGER users_touch BEFORE UPDATE ON public.users FOR EACH ROW EXECUTE FUNCTION touch_updated_at()",
+	}
+
+	mock.ExpectExec("CREATE TRIGGER users_touch").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.CreateTrigger(db, trigger); err != nil {
+		t.Errorf("CreateTrigger() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
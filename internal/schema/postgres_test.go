@@ -1,6 +1,11 @@
 package schema
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -137,10 +142,11 @@ func TestPostgresApplier_SetSequenceValue(t *testing.T) {
 	seq := SequenceDef{
 		Name:       "users_id_seq",
 		CurrentVal: 100,
+		IsCalled:   true,
 	}
 
-	mock.ExpectExec(`SELECT setval\('users_id_seq', \$1, true\)`).
-		WithArgs(int64(100)).
+	mock.ExpectExec(`SELECT setval\('"users_id_seq"', \$1, \$2\)`).
+		WithArgs(int64(100), true).
 		WillReturnResult(sqlmock.NewResult(0, 0))
 
 	err = app.SetSequenceValue(db, seq)
@@ -166,8 +172,8 @@ func TestPostgresApplier_SetSequenceValue_Error(t *testing.T) {
 		CurrentVal: 100,
 	}
 
-	mock.ExpectExec(`SELECT setval\('missing_seq', \$1, true\)`).
-		WithArgs(int64(100)).
+	mock.ExpectExec(`SELECT setval\('"missing_seq"', \$1, \$2\)`).
+		WithArgs(int64(100), false).
 		WillReturnError(sqlmock.ErrCancelled)
 
 	err = app.SetSequenceValue(db, seq)
@@ -214,39 +220,6 @@ func TestQuoteLiteral(t *testing.T) {
 	}
 }
 
-func TestExtractPgViewDependencies(t *testing.T) {
-	tests := []struct {
-		name    string
-		viewDef string
-		want    int
-	}{
-		{
-			name:    "simple FROM",
-			viewDef: `SELECT * FROM "users"`,
-			want:    1,
-		},
-		{
-			name:    "with JOIN",
-			viewDef: `SELECT * FROM "users" JOIN "orders" ON users.id = orders.user_id`,
-			want:    2,
-		},
-		{
-			name:    "unquoted tables",
-			viewDef: "SELECT * FROM users JOIN orders ON users.id = orders.user_id",
-			want:    2,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			deps := extractPgViewDependencies(tt.viewDef)
-			if len(deps) != tt.want {
-				t.Errorf("extractPgViewDependencies() returned %d deps, want %d", len(deps), tt.want)
-			}
-		})
-	}
-}
-
 func TestPostgresExtractor_buildAddForeignKeyStmt(t *testing.T) {
 	ext := NewPostgresExtractor()
 
@@ -259,7 +232,7 @@ func TestPostgresExtractor_buildAddForeignKeyStmt(t *testing.T) {
 		OnUpdate:   "NO ACTION",
 	}
 
-	got := ext.buildAddForeignKeyStmt("orders", fk)
+	got := ext.buildAddForeignKeyStmt("", "orders", fk)
 
 	// Check essential parts
 	if !strings.Contains(got, `ALTER TABLE "orders"`) {
@@ -291,7 +264,7 @@ func TestPostgresExtractor_buildAddForeignKeyStmt_NoActions(t *testing.T) {
 		OnUpdate:   "NO ACTION",
 	}
 
-	got := ext.buildAddForeignKeyStmt("test", fk)
+	got := ext.buildAddForeignKeyStmt("", "test", fk)
 
 	// NO ACTION is the default, so it shouldn't be included
 	if strings.Contains(got, "ON DELETE") {
@@ -312,33 +285,39 @@ func TestPostgresExtractor_ExtractTables(t *testing.T) {
 	ext := NewPostgresExtractor()
 
 	// Mock table list query
-	tableRows := sqlmock.NewRows([]string{"table_name"}).
-		AddRow("users")
-	mock.ExpectQuery("SELECT table_name FROM information_schema.tables").
+	tableRows := sqlmock.NewRows([]string{"nspname", "relname", "partition_key", "partition_bound", "parents", "has_children"}).
+		AddRow("public", "users", "", "", "[]", false)
+	mock.ExpectQuery("SELECT n.nspname, c.relname.*FROM pg_class c").
 		WillReturnRows(tableRows)
 
 	// Mock column query for users
-	colRows := sqlmock.NewRows([]string{"column_name", "data_type", "column_default", "not_null", "is_identity"}).
-		AddRow("id", "integer", "nextval('users_id_seq'::regclass)", true, false).
-		AddRow("name", "varchar", "", false, false)
+	colRows := sqlmock.NewRows([]string{"column_name", "data_type", "column_default", "not_null", "identity", "identity_options", "generated", "collation", "storage", "comment", "is_local"}).
+		AddRow("id", "integer", "nextval('users_id_seq'::regclass)", true, "", "", "", "", "", "", true).
+		AddRow("name", "varchar", "", false, "", "", "", "", "", "", true)
 	mock.ExpectQuery("SELECT.*FROM pg_catalog.pg_attribute").
-		WithArgs("users").
+		WithArgs(`"public"."users"`).
 		WillReturnRows(colRows)
 
-	// Mock primary key query
-	mock.ExpectQuery("SELECT string_agg.*FROM pg_index").
-		WithArgs("users").
-		WillReturnRows(sqlmock.NewRows([]string{"pk_columns"}).AddRow("id"))
+	// Mock primary key and check constraint query
+	mock.ExpectQuery("SELECT conname.*FROM pg_constraint").
+		WithArgs(`"public"."users"`).
+		WillReturnRows(sqlmock.NewRows([]string{"conname", "contype", "definition", "convalidated"}).
+			AddRow("users_pkey", "p", "PRIMARY KEY (id)", true))
+
+	// Mock table comment query
+	mock.ExpectQuery("SELECT COALESCE\\(obj_description").
+		WithArgs(`"public"."users"`).
+		WillReturnRows(sqlmock.NewRows([]string{"comment"}).AddRow(""))
 
 	// Mock indexes query
 	mock.ExpectQuery("SELECT.*i.relname.*FROM pg_index").
-		WithArgs("users").
-		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "is_unique", "index_def"}))
+		WithArgs("users", "public").
+		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "is_unique", "index_def", "constraint_def"}))
 
 	// Mock foreign keys query
 	mock.ExpectQuery("SELECT.*tc.constraint_name.*FROM information_schema.table_constraints").
-		WithArgs("users").
-		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "ref_table", "ref_columns", "delete_rule", "update_rule"}))
+		WithArgs("users", "public").
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "ref_schema", "ref_table", "ref_columns", "delete_rule", "update_rule"}))
 
 	tables, err := ext.ExtractTables(db, "testdb")
 	if err != nil {
@@ -363,7 +342,7 @@ func TestPostgresExtractor_ExtractTables_Error(t *testing.T) {
 
 	ext := NewPostgresExtractor()
 
-	mock.ExpectQuery("SELECT table_name FROM information_schema.tables").
+	mock.ExpectQuery("SELECT n.nspname, c.relname.*FROM pg_class c").
 		WillReturnError(sqlmock.ErrCancelled)
 
 	_, err = ext.ExtractTables(db, "testdb")
@@ -382,10 +361,15 @@ func TestPostgresExtractor_ExtractViews(t *testing.T) {
 	ext := NewPostgresExtractor()
 
 	// Mock views query
-	viewRows := sqlmock.NewRows([]string{"viewname", "view_def"}).
-		AddRow("user_stats", " SELECT * FROM users")
-	mock.ExpectQuery("SELECT viewname, pg_get_viewdef.*FROM pg_views").
+	viewRows := sqlmock.NewRows([]string{"schemaname", "viewname", "view_def"}).
+		AddRow("public", "user_stats", " SELECT * FROM users")
+	mock.ExpectQuery("SELECT schemaname, viewname,.*pg_get_viewdef.*FROM pg_views").
 		WillReturnRows(viewRows)
+	mock.ExpectQuery("SELECT schemaname, matviewname.*FROM pg_matviews").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "matviewname", "view_def", "ispopulated"}))
+	mock.ExpectQuery("SELECT DISTINCT vn.nspname, v.relname.*FROM pg_depend").
+		WillReturnRows(sqlmock.NewRows([]string{"view_schema", "view_name", "ref_schema", "ref_name"}).
+			AddRow("public", "user_stats", "public", "users"))
 
 	views, err := ext.ExtractViews(db, "testdb")
 	if err != nil {
@@ -410,7 +394,7 @@ func TestPostgresExtractor_ExtractViews_Error(t *testing.T) {
 
 	ext := NewPostgresExtractor()
 
-	mock.ExpectQuery("SELECT viewname, pg_get_viewdef.*FROM pg_views").
+	mock.ExpectQuery("SELECT schemaname, viewname,.*pg_get_viewdef.*FROM pg_views").
 		WillReturnError(sqlmock.ErrCancelled)
 
 	_, err = ext.ExtractViews(db, "testdb")
@@ -428,27 +412,96 @@ func TestPostgresExtractor_ExtractSequences(t *testing.T) {
 
 	ext := NewPostgresExtractor()
 
-	// Mock sequences query
-	seqRows := sqlmock.NewRows([]string{"sequencename", "owned_by", "current_val"}).
-		AddRow("users_id_seq", "users.id", int64(100))
+	// Mock sequences query: a serial-style owned sequence, an identity sequence and an unused standalone one
+	seqRows := sqlmock.NewRows([]string{"schemaname", "sequencename", "data_type", "start_value", "min_value", "max_value",
+		"increment_by", "cache_size", "cycle", "last_value", "owner_schema", "owner_table", "owner_column", "dep_type"}).
+		AddRow("public", "invoice_no", "integer", int64(1000), int64(1000), int64(999999), int64(10), int64(20), true, nil, "", "", "", "").
+		AddRow("public", "orders_id_seq", "bigint", int64(1), int64(1), int64(9223372036854775807), int64(1), int64(1), false, int64(42), "public", "orders", "id", "i").
+		AddRow("public", "users_id_seq", "integer", int64(1), int64(1), int64(2147483647), int64(1), int64(1), false, int64(100), "public", "users", "id", "a")
 	mock.ExpectQuery("SELECT.*s.sequencename.*FROM pg_sequences").
 		WillReturnRows(seqRows)
 
 	sequences, err := ext.ExtractSequences(db, "testdb")
 	if err != nil {
-		t.Errorf("ExtractSequences() error = %v", err)
+		t.Fatalf("ExtractSequences() error = %v", err)
+	}
+	if len(sequences) != 3 {
+		t.Fatalf("ExtractSequences() returned %d sequences, want 3", len(sequences))
+	}
+
+	standalone := sequences[0]
+	wantCreate := `CREATE SEQUENCE "public"."invoice_no" AS integer START WITH 1000 INCREMENT BY 10 MINVALUE 1000 MAXVALUE 999999 CACHE 20 CYCLE`
+	if standalone.CreateStmt != wantCreate {
+		t.Errorf("CreateStmt = %q, want %q", standalone.CreateStmt, wantCreate)
+	}
+	if standalone.IsCalled || standalone.CurrentVal != 1000 {
+		t.Errorf("unused sequence: CurrentVal = %d, IsCalled = %v, want 1000, false", standalone.CurrentVal, standalone.IsCalled)
+	}
+	if standalone.OwnedByStmt != "" {
+		t.Errorf("unowned sequence OwnedByStmt = %q, want empty", standalone.OwnedByStmt)
+	}
+
+	identity := sequences[1]
+	if !identity.Identity || identity.OwnedByStmt != "" {
+		t.Errorf("identity sequence = %+v, want Identity without OwnedByStmt", identity)
+	}
+
+	owned := sequences[2]
+	if owned.CurrentVal != 100 || !owned.IsCalled {
+		t.Errorf("owned sequence: CurrentVal = %d, IsCalled = %v, want 100, true", owned.CurrentVal, owned.IsCalled)
+	}
+	if owned.OwnedBy != "public.users.id" {
+		t.Errorf("OwnedBy = %q, want public.users.id", owned.OwnedBy)
+	}
+	wantOwner := `ALTER SEQUENCE "public"."users_id_seq" OWNED BY "public"."users"."id"`
+	if owned.OwnedByStmt != wantOwner {
+		t.Errorf("OwnedByStmt = %q, want %q", owned.OwnedByStmt, wantOwner)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresApplier_CreateSequence_SkipsIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
 	}
+	defer db.Close()
 
-	if len(sequences) != 1 {
-		t.Errorf("ExtractSequences() returned %d sequences, want 1", len(sequences))
+	seq := SequenceDef{Schema: "public", Name: "orders_id_seq", Identity: true, CreateStmt: "CREATE SEQUENCE ..."}
+	if err := NewPostgresApplier().CreateSequence(db, seq); err != nil {
+		t.Errorf("CreateSequence() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("identity sequence should not be created separately: %v", err)
 	}
+}
 
-	if sequences[0].Name != "users_id_seq" {
-		t.Errorf("sequences[0].Name = %q, want %q", sequences[0].Name, "users_id_seq")
+func TestPostgresApplier_SetSequenceOwner(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
 	}
+	defer db.Close()
 
-	if sequences[0].CurrentVal != 100 {
-		t.Errorf("sequences[0].CurrentVal = %d, want 100", sequences[0].CurrentVal)
+	app := NewPostgresApplier()
+	seq := SequenceDef{
+		Schema:      "public",
+		Name:        "users_id_seq",
+		OwnedByStmt: `ALTER SEQUENCE "public"."users_id_seq" OWNED BY "public"."users"."id"`,
+	}
+
+	mock.ExpectExec(`ALTER SEQUENCE "public"."users_id_seq" OWNED BY "public"."users"."id"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.SetSequenceOwner(db, seq); err != nil {
+		t.Errorf("SetSequenceOwner() error = %v", err)
+	}
+	// No owner, nothing to run
+	if err := app.SetSequenceOwner(db, SequenceDef{Name: "invoice_no"}); err != nil {
+		t.Errorf("SetSequenceOwner() without owner error = %v", err)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -473,3 +526,913 @@ func TestPostgresExtractor_ExtractSequences_Error(t *testing.T) {
 		t.Errorf("ExtractSequences() expected error, got nil")
 	}
 }
+
+func TestPostgresExtractor_ExtractSchemas(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+	ext.SetSchemaFilter(SchemaFilter{Exclude: []string{"scratch"}})
+
+	mock.ExpectQuery("SELECT nspname FROM pg_namespace").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname"}).
+			AddRow("audit").AddRow("public").AddRow("scratch"))
+
+	schemas, err := ext.ExtractSchemas(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractSchemas() error = %v", err)
+	}
+
+	want := []string{"audit", "public"}
+	if strings.Join(schemas, ",") != strings.Join(want, ",") {
+		t.Errorf("ExtractSchemas() = %v, want %v", schemas, want)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractTables_SchemaFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+	ext.SetSchemaFilter(SchemaFilter{Include: []string{"audit"}})
+
+	mock.ExpectQuery("SELECT n.nspname, c.relname.*FROM pg_class c").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "partition_key", "partition_bound", "parents", "has_children"}).
+			AddRow("audit", "log", "", "", "[]", false).
+			AddRow("public", "users", "", "", "[]", false))
+
+	mock.ExpectQuery("SELECT.*FROM pg_catalog.pg_attribute").
+		WithArgs(`"audit"."log"`).
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "column_default", "not_null", "identity", "identity_options", "generated", "collation", "storage", "comment", "is_local"}).
+			AddRow("user_id", "bigint", "", true, "", "", "", "", "", "", true))
+	mock.ExpectQuery("SELECT conname.*FROM pg_constraint").
+		WithArgs(`"audit"."log"`).
+		WillReturnRows(sqlmock.NewRows([]string{"conname", "contype", "definition", "convalidated"}))
+	mock.ExpectQuery("SELECT COALESCE\\(obj_description").
+		WithArgs(`"audit"."log"`).
+		WillReturnRows(sqlmock.NewRows([]string{"comment"}).AddRow(""))
+	mock.ExpectQuery("SELECT.*i.relname.*FROM pg_index").
+		WithArgs("log", "audit").
+		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "is_unique", "index_def", "constraint_def"}))
+	mock.ExpectQuery("SELECT.*tc.constraint_name.*FROM information_schema.table_constraints").
+		WithArgs("log", "audit").
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "ref_schema", "ref_table", "ref_columns", "delete_rule", "update_rule"}).
+			AddRow("log_user_fk", "user_id", "public", "users", "id", "CASCADE", "NO ACTION"))
+
+	tables, err := ext.ExtractTables(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTables() error = %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("ExtractTables() returned %d tables, want 1", len(tables))
+	}
+
+	table := tables[0]
+	if table.QualifiedName() != "audit.log" {
+		t.Errorf("QualifiedName() = %q, want %q", table.QualifiedName(), "audit.log")
+	}
+	if !strings.HasPrefix(table.CreateStmt, `CREATE TABLE "audit"."log"`) {
+		t.Errorf("CreateStmt should be schema-qualified, got %q", table.CreateStmt)
+	}
+	wantFK := `ALTER TABLE "audit"."log" ADD CONSTRAINT "log_user_fk" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE CASCADE`
+	if len(table.ForeignKeys) != 1 || table.ForeignKeys[0].ConstraintStmt != wantFK {
+		t.Errorf("ForeignKeys = %+v, want cross-schema statement %q", table.ForeignKeys, wantFK)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractViews_CrossSchemaOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	mock.ExpectQuery("SELECT schemaname, viewname,.*pg_get_viewdef.*FROM pg_views").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "viewname", "view_def"}).
+			AddRow("public", "active_audit", " SELECT * FROM reporting.audit_summary").
+			AddRow("reporting", "audit_summary", " SELECT * FROM audit.log"))
+	mock.ExpectQuery("SELECT schemaname, matviewname.*FROM pg_matviews").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "matviewname", "view_def", "ispopulated"}))
+	mock.ExpectQuery("SELECT DISTINCT vn.nspname, v.relname.*FROM pg_depend").
+		WillReturnRows(sqlmock.NewRows([]string{"view_schema", "view_name", "ref_schema", "ref_name"}).
+			AddRow("public", "active_audit", "reporting", "audit_summary").
+			AddRow("reporting", "audit_summary", "audit", "log"))
+
+	views, err := ext.ExtractViews(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractViews() error = %v", err)
+	}
+	if len(views) != 2 {
+		t.Fatalf("ExtractViews() returned %d views, want 2", len(views))
+	}
+	if views[0].QualifiedName() != "reporting.audit_summary" {
+		t.Errorf("views[0] = %q, want reporting.audit_summary first", views[0].QualifiedName())
+	}
+	if views[1].CreateStmt != "CREATE VIEW \"public\".\"active_audit\" AS\n SELECT * FROM reporting.audit_summary" {
+		t.Errorf("views[1].CreateStmt = %q", views[1].CreateStmt)
+	}
+}
+
+func TestPostgresExtractor_ExtractViews_DependencyError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	mock.ExpectQuery("SELECT schemaname, viewname,.*pg_get_viewdef.*FROM pg_views").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "viewname", "view_def"}).
+			AddRow("public", "active_users", " SELECT * FROM users"))
+	mock.ExpectQuery("SELECT schemaname, matviewname.*FROM pg_matviews").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "matviewname", "view_def", "ispopulated"}))
+	mock.ExpectQuery("SELECT DISTINCT vn.nspname, v.relname.*FROM pg_depend").
+		WillReturnError(errors.New("permission denied for table pg_depend"))
+
+	if _, err := ext.ExtractViews(db, "testdb"); err == nil || !strings.Contains(err.Error(), "failed to read view dependencies") {
+		t.Errorf("ExtractViews() error = %v, want the view dependency error", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractViews_Materialized(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	mock.ExpectQuery("SELECT schemaname, viewname,.*pg_get_viewdef.*FROM pg_views").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "viewname", "view_def"}).
+			AddRow("public", "top_customers", " SELECT * FROM reporting.daily_totals"))
+	mock.ExpectQuery("SELECT schemaname, matviewname.*FROM pg_matviews").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "matviewname", "view_def", "ispopulated"}).
+			AddRow("reporting", "daily_totals", " SELECT day, sum(total) AS total FROM orders GROUP BY day;", true))
+	mock.ExpectQuery("SELECT i.relname, pg_get_indexdef.*FROM pg_index ix").
+		WithArgs(`"reporting"."daily_totals"`).
+		WillReturnRows(sqlmock.NewRows([]string{"relname", "pg_get_indexdef"}).
+			AddRow("daily_totals_day_idx", `CREATE UNIQUE INDEX daily_totals_day_idx ON reporting.daily_totals USING btree (day)`))
+	mock.ExpectQuery("SELECT DISTINCT vn.nspname, v.relname.*FROM pg_depend").
+		WillReturnRows(sqlmock.NewRows([]string{"view_schema", "view_name", "ref_schema", "ref_name"}).
+			AddRow("public", "top_customers", "reporting", "daily_totals").
+			AddRow("reporting", "daily_totals", "public", "orders"))
+
+	views, err := ext.ExtractViews(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractViews() error = %v", err)
+	}
+	if len(views) != 2 {
+		t.Fatalf("ExtractViews() returned %d views, want 2", len(views))
+	}
+
+	// The plain view reads from the matview, so the matview comes first
+	mv := views[0]
+	if mv.QualifiedName() != "reporting.daily_totals" || !mv.Materialized || !mv.Populated {
+		t.Fatalf("views[0] = %+v, want populated matview reporting.daily_totals", mv)
+	}
+	wantCreate := "CREATE MATERIALIZED VIEW \"reporting\".\"daily_totals\" AS\nSELECT day, sum(total) AS total FROM orders GROUP BY day\nWITH NO DATA"
+	if mv.CreateStmt != wantCreate {
+		t.Errorf("CreateStmt = %q, want %q", mv.CreateStmt, wantCreate)
+	}
+	if len(mv.Indexes) != 1 || mv.Indexes[0].Name != "daily_totals_day_idx" {
+		t.Errorf("Indexes = %+v, want daily_totals_day_idx", mv.Indexes)
+	}
+	if views[1].Materialized {
+		t.Errorf("views[1] should be a plain view")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresApplier_CreateView_Materialized(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewPostgresApplier()
+	view := ViewDef{
+		Schema:       "reporting",
+		Name:         "daily_totals",
+		Materialized: true,
+		CreateStmt:   "CREATE MATERIALIZED VIEW \"reporting\".\"daily_totals\" AS\nSELECT 1\nWITH NO DATA",
+		Indexes: []IndexDef{
+			{Name: "daily_totals_day_idx", CreateStmt: "CREATE UNIQUE INDEX daily_totals_day_idx ON reporting.daily_totals USING btree (day)"},
+		},
+	}
+
+	mock.ExpectExec(`CREATE MATERIALIZED VIEW "reporting"."daily_totals"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE UNIQUE INDEX daily_totals_day_idx").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.CreateView(db, view); err != nil {
+		t.Errorf("CreateView() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresApplier_RefreshMaterializedView(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewPostgresApplier()
+
+	mock.ExpectExec(`REFRESH MATERIALIZED VIEW "reporting"."daily_totals"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.RefreshMaterializedView(db, ViewDef{Schema: "reporting", Name: "daily_totals", Materialized: true}); err != nil {
+		t.Errorf("RefreshMaterializedView() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractViews_CatalogDependencies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	// big_spenders reads customer_totals inside a CTE and a function call; only pg_depend records both
+	mock.ExpectQuery("SELECT schemaname, viewname,.*pg_get_viewdef.*FROM pg_views").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "viewname", "view_def"}).
+			AddRow("public", "big_spenders", " WITH t AS (SELECT * FROM unnest(ARRAY(SELECT id FROM customer_totals)) x) SELECT * FROM t;").
+			AddRow("public", "customer_totals", " SELECT customer_id FROM orders;"))
+	mock.ExpectQuery("SELECT schemaname, matviewname.*FROM pg_matviews").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "matviewname", "view_def", "ispopulated"}))
+	mock.ExpectQuery("SELECT DISTINCT vn.nspname, v.relname.*FROM pg_depend").
+		WillReturnRows(sqlmock.NewRows([]string{"view_schema", "view_name", "ref_schema", "ref_name"}).
+			AddRow("public", "big_spenders", "public", "customer_totals").
+			AddRow("public", "customer_totals", "public", "orders"))
+
+	views, err := ext.ExtractViews(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractViews() error = %v", err)
+	}
+	if len(views) != 2 || views[0].Name != "customer_totals" {
+		t.Errorf("ExtractViews() order = %v, want customer_totals first", viewNames(views))
+	}
+	if deps := views[1].Dependencies; len(deps) != 1 || deps[0] != "public.customer_totals" {
+		t.Errorf("big_spenders.Dependencies = %v, want [public.customer_totals]", deps)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractViews_Cycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	mock.ExpectQuery("SELECT schemaname, viewname,.*pg_get_viewdef.*FROM pg_views").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "viewname", "view_def"}).
+			AddRow("public", "a", " SELECT * FROM b;").
+			AddRow("public", "b", " SELECT * FROM a;"))
+	mock.ExpectQuery("SELECT schemaname, matviewname.*FROM pg_matviews").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "matviewname", "view_def", "ispopulated"}))
+	mock.ExpectQuery("SELECT DISTINCT vn.nspname, v.relname.*FROM pg_depend").
+		WillReturnRows(sqlmock.NewRows([]string{"view_schema", "view_name", "ref_schema", "ref_name"}).
+			AddRow("public", "a", "public", "b").
+			AddRow("public", "b", "public", "a"))
+
+	_, err = ext.ExtractViews(db, "testdb")
+	if err == nil || !strings.Contains(err.Error(), "public.a -> public.b -> public.a") {
+		t.Errorf("ExtractViews() error = %v, want circular dependency public.a -> public.b -> public.a", err)
+	}
+}
+
+func TestPostgresApplier_CreateSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewPostgresApplier()
+
+	mock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "audit"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.CreateSchema(db, "audit"); err != nil {
+		t.Errorf("CreateSchema() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresApplier_SetSequenceValue_SchemaQualified(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewPostgresApplier()
+	seq := SequenceDef{Schema: "audit", Name: "log_id_seq", CurrentVal: 7, IsCalled: true}
+
+	mock.ExpectExec(`SELECT setval\('"audit"."log_id_seq"', \$1, \$2\)`).
+		WithArgs(int64(7), true).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.SetSequenceValue(db, seq); err != nil {
+		t.Errorf("SetSequenceValue() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractExtensions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	mock.ExpectQuery("SELECT e.extname, n.nspname, e.extversion FROM pg_extension").
+		WillReturnRows(sqlmock.NewRows([]string{"extname", "nspname", "extversion"}).
+			AddRow("citext", "public", "1.6").
+			AddRow("postgis", "extensions", "3.4.2"))
+
+	extensions, err := ext.ExtractExtensions(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractExtensions() error = %v", err)
+	}
+	if len(extensions) != 2 {
+		t.Fatalf("ExtractExtensions() returned %d extensions, want 2", len(extensions))
+	}
+	if extensions[1].Version != "3.4.2" {
+		t.Errorf("extensions[1].Version = %q, want %q", extensions[1].Version, "3.4.2")
+	}
+	want := `CREATE EXTENSION IF NOT EXISTS "postgis" WITH SCHEMA "extensions"`
+	if extensions[1].CreateStmt != want {
+		t.Errorf("extensions[1].CreateStmt = %q, want %q", extensions[1].CreateStmt, want)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresApplier_CreateExtension(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewPostgresApplier()
+	ext := ExtensionDef{
+		Name:       "postgis",
+		Schema:     "extensions",
+		CreateStmt: `CREATE EXTENSION IF NOT EXISTS "postgis" WITH SCHEMA "extensions"`,
+	}
+
+	mock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "extensions"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE EXTENSION IF NOT EXISTS "postgis" WITH SCHEMA "extensions"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := app.CreateExtension(db, ext); err != nil {
+		t.Errorf("CreateExtension() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+// expectOrdersCatalog mocks the catalog queries for a table using every DDL feature
+// buildCreateTableStmt and extractTable reconstruct
+func expectOrdersCatalog(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT n.nspname, c.relname.*FROM pg_class c").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "partition_key", "partition_bound", "parents", "has_children"}).
+			AddRow("public", "orders", "", "", "[]", false))
+
+	mock.ExpectQuery("SELECT.*FROM pg_catalog.pg_attribute").
+		WithArgs(`"public"."orders"`).
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "column_default", "not_null", "identity", "identity_options", "generated", "collation", "storage", "comment", "is_local"}).
+			AddRow("id", "bigint", "", true, "a", "SEQUENCE NAME public.orders_id_seq START WITH 1 INCREMENT BY 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1", "", "", "", "", true).
+			AddRow("number", "integer", "nextval('order_number_seq'::regclass)", true, "", "", "", "", "", "", true).
+			AddRow("code", "text", "", true, "", "", "", `pg_catalog."C"`, "", "Customer-facing order code", true).
+			AddRow("price", "numeric(10,2)", "0", true, "", "", "", "", "", "", true).
+			AddRow("qty", "integer", "1", true, "", "", "", "", "", "", true).
+			AddRow("total", "numeric", "(price * (qty)::numeric)", false, "", "", "s", "", "", "", true).
+			AddRow("during", "tstzrange", "", false, "", "", "", "", "", "", true).
+			AddRow("payload", "jsonb", "", false, "", "", "", "", "e", "", true))
+
+	mock.ExpectQuery("SELECT conname.*FROM pg_constraint").
+		WithArgs(`"public"."orders"`).
+		WillReturnRows(sqlmock.NewRows([]string{"conname", "contype", "definition", "convalidated"}).
+			AddRow("orders_pkey", "p", "PRIMARY KEY (id)", true).
+			AddRow("orders_qty_check", "c", "CHECK ((qty > 0))", true).
+			AddRow("orders_price_check", "c", "CHECK ((price >= (0)::numeric)) NOT VALID", false))
+
+	mock.ExpectQuery("SELECT COALESCE\\(obj_description").
+		WithArgs(`"public"."orders"`).
+		WillReturnRows(sqlmock.NewRows([]string{"comment"}).AddRow("Customer's orders"))
+
+	mock.ExpectQuery("SELECT.*i.relname.*FROM pg_index").
+		WithArgs("orders", "public").
+		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "is_unique", "index_def", "constraint_def"}).
+			AddRow("orders_code_key", "code", true, "CREATE UNIQUE INDEX orders_code_key ON public.orders USING btree (code)", "UNIQUE (code)").
+			AddRow("orders_during_excl", "during", false, "CREATE INDEX orders_during_excl ON public.orders USING gist (during)", "EXCLUDE USING gist (during WITH &&)").
+			AddRow("orders_lower_code_idx", "", false, "CREATE INDEX orders_lower_code_idx ON public.orders USING btree (lower(code))", ""))
+
+	mock.ExpectQuery("SELECT.*tc.constraint_name.*FROM information_schema.table_constraints").
+		WithArgs("orders", "public").
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "ref_schema", "ref_table", "ref_columns", "delete_rule", "update_rule"}))
+}
+
+func TestPostgresExtractor_ExtractTables_FullDDL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	expectOrdersCatalog(mock)
+
+	tables, err := NewPostgresExtractor().ExtractTables(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTables() error = %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("ExtractTables() returned %d tables, want 1", len(tables))
+	}
+	table := tables[0]
+
+	wantCreate := `CREATE TABLE "public"."orders" (
+    "id" bigint GENERATED ALWAYS AS IDENTITY (SEQUENCE NAME public.orders_id_seq START WITH 1 INCREMENT BY 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1) NOT NULL,
+    "number" integer DEFAULT nextval('order_number_seq'::regclass) NOT NULL,
+    "code" text COLLATE pg_catalog."C" NOT NULL,
+    "price" numeric(10,2) DEFAULT 0 NOT NULL,
+    "qty" integer DEFAULT 1 NOT NULL,
+    "total" numeric GENERATED ALWAYS AS ((price * (qty)::numeric)) STORED,
+    "during" tstzrange,
+    "payload" jsonb,
+    CONSTRAINT "orders_pkey" PRIMARY KEY (id),
+    CONSTRAINT "orders_qty_check" CHECK ((qty > 0))
+)`
+	if table.CreateStmt != wantCreate {
+		t.Errorf("CreateStmt =\n%s\nwant\n%s", table.CreateStmt, wantCreate)
+	}
+
+	wantPost := []string{
+		`COMMENT ON COLUMN "public"."orders"."code" IS 'Customer-facing order code'`,
+		`ALTER TABLE "public"."orders" ALTER COLUMN "payload" SET STORAGE EXTERNAL`,
+		`COMMENT ON TABLE "public"."orders" IS 'Customer''s orders'`,
+	}
+	if !reflect.DeepEqual(table.PostCreateStmts, wantPost) {
+		t.Errorf("PostCreateStmts = %q, want %q", table.PostCreateStmts, wantPost)
+	}
+
+	wantIndexes := []string{
+		`ALTER TABLE "public"."orders" ADD CONSTRAINT "orders_price_check" CHECK ((price >= (0)::numeric)) NOT VALID`,
+		`ALTER TABLE "public"."orders" ADD CONSTRAINT "orders_code_key" UNIQUE (code)`,
+		`ALTER TABLE "public"."orders" ADD CONSTRAINT "orders_during_excl" EXCLUDE USING gist (during WITH &&)`,
+		`CREATE INDEX orders_lower_code_idx ON public.orders USING btree (lower(code))`,
+	}
+	var gotIndexes []string
+	for _, idx := range table.Indexes {
+		gotIndexes = append(gotIndexes, idx.CreateStmt)
+	}
+	if !reflect.DeepEqual(gotIndexes, wantIndexes) {
+		t.Errorf("index statements = %q, want %q", gotIndexes, wantIndexes)
+	}
+	if table.Indexes[3].IsConstraint || !table.Indexes[1].IsConstraint {
+		t.Errorf("IsConstraint should be set only for constraint-backed statements")
+	}
+	if table.Indexes[3].Columns != nil {
+		t.Errorf("expression index Columns = %v, want nil", table.Indexes[3].Columns)
+	}
+
+	if !reflect.DeepEqual(table.PrimaryKey, []string{"id"}) {
+		t.Errorf("PrimaryKey = %v, want [id]", table.PrimaryKey)
+	}
+	if !table.Columns[5].IsGenerated() {
+		t.Errorf("total column should be marked generated")
+	}
+	if table.Columns[0].Extra != "IDENTITY ALWAYS" {
+		t.Errorf("id column Extra = %q, want IDENTITY ALWAYS", table.Columns[0].Extra)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractTables_RoundTrip(t *testing.T) {
+	sourceDB, sourceMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create source mock: %v", err)
+	}
+	defer sourceDB.Close()
+
+	destDB, destMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("failed to create dest mock: %v", err)
+	}
+	defer destDB.Close()
+
+	expectOrdersCatalog(sourceMock)
+	sourceTables, err := NewPostgresExtractor().ExtractTables(sourceDB, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTables() error = %v", err)
+	}
+	table := sourceTables[0]
+
+	// Apply to the destination exactly as the migrator does: table, then indexes and constraints
+	destMock.ExpectExec(table.CreateStmt).WillReturnResult(sqlmock.NewResult(0, 0))
+	for _, stmt := range table.PostCreateStmts {
+		destMock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	for _, idx := range table.Indexes {
+		destMock.ExpectExec(idx.CreateStmt).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	app := NewPostgresApplier()
+	if err := app.CreateTable(destDB, table); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	for _, idx := range table.Indexes {
+		if err := app.CreateIndex(destDB, idx); err != nil {
+			t.Fatalf("CreateIndex() error = %v", err)
+		}
+	}
+	if err := destMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("destination expectations not met: %v", err)
+	}
+
+	// A destination built from those statements reports the same catalog, so
+	// extracting it again must yield identical DDL
+	againDB, againMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer againDB.Close()
+	expectOrdersCatalog(againMock)
+	destTables, err := NewPostgresExtractor().ExtractTables(againDB, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTables() on destination error = %v", err)
+	}
+	if !reflect.DeepEqual(destTables, sourceTables) {
+		t.Errorf("destination DDL differs from source:\n%+v\n%+v", destTables, sourceTables)
+	}
+}
+
+func TestPostgresApplier_CreateTable_PostCreateError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	table := TableSchema{
+		Schema:          "public",
+		Name:            "orders",
+		CreateStmt:      `CREATE TABLE "public"."orders" ("id" bigint)`,
+		PostCreateStmts: []string{`COMMENT ON TABLE "public"."orders" IS 'x'`},
+	}
+
+	mock.ExpectExec("CREATE TABLE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("COMMENT ON TABLE").WillReturnError(sqlmock.ErrCancelled)
+
+	if err := NewPostgresApplier().CreateTable(db, table); err == nil {
+		t.Errorf("CreateTable() expected error from post-create statement, got nil")
+	}
+}
+
+func TestPostgresApplier_CreateTable_ErrorNamesTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	table := TableSchema{
+		Schema:     "sales",
+		Name:       "customers",
+		SourceName: "users",
+		CreateStmt: `CREATE TABLE "sales"."customers" ("id" bigint)`,
+	}
+	mock.ExpectExec("CREATE TABLE").WillReturnError(sqlmock.ErrCancelled)
+
+	err = NewPostgresApplier().CreateTable(db, table)
+	if err == nil || !strings.Contains(err.Error(), "table sales.customers (sales.users on the source)") {
+		t.Errorf("CreateTable() error = %v, want it to name sales.customers and sales.users", err)
+	}
+}
+
+func TestParseConstraintColumns(t *testing.T) {
+	tests := []struct {
+		definition string
+		want       []string
+	}{
+		{"PRIMARY KEY (id)", []string{"id"}},
+		{`PRIMARY KEY (tenant_id, "Order Id")`, []string{"tenant_id", "Order Id"}},
+		{"EXCLUDE", nil},
+	}
+	for _, tt := range tests {
+		if got := parseConstraintColumns(tt.definition); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseConstraintColumns(%q) = %q, want %q", tt.definition, got, tt.want)
+		}
+	}
+}
+
+// expectSimpleTable mocks the per-table catalog queries for a table with the given columns,
+// where isLocal reports whether the columns are declared on the table itself
+func expectSimpleTable(mock sqlmock.Sqlmock, schemaName, tableName string, isLocal bool, columns ...string) {
+	regclass := quoteQualified(schemaName, tableName)
+	colRows := sqlmock.NewRows([]string{"column_name", "data_type", "column_default", "not_null", "identity", "identity_options", "generated", "collation", "storage", "comment", "is_local"})
+	for _, col := range columns {
+		colRows.AddRow(col, "integer", "", false, "", "", "", "", "", "", isLocal)
+	}
+	mock.ExpectQuery("SELECT.*FROM pg_catalog.pg_attribute").WithArgs(regclass).WillReturnRows(colRows)
+	mock.ExpectQuery("SELECT conname.*FROM pg_constraint").WithArgs(regclass).
+		WillReturnRows(sqlmock.NewRows([]string{"conname", "contype", "definition", "convalidated"}))
+	mock.ExpectQuery("SELECT COALESCE\\(obj_description").WithArgs(regclass).
+		WillReturnRows(sqlmock.NewRows([]string{"comment"}).AddRow(""))
+	mock.ExpectQuery("SELECT.*i.relname.*FROM pg_index").WithArgs(tableName, schemaName).
+		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "is_unique", "index_def", "constraint_def"}))
+	mock.ExpectQuery("SELECT.*tc.constraint_name.*FROM information_schema.table_constraints").WithArgs(tableName, schemaName).
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "ref_schema", "ref_table", "ref_columns", "delete_rule", "update_rule"}))
+}
+
+func TestPostgresExtractor_ExtractTables_Partitioned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT n.nspname, c.relname.*FROM pg_class c").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "partition_key", "partition_bound", "parents", "has_children"}).
+			AddRow("archive", "events_2023", "", "FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')", `[["public","events"]]`, false).
+			AddRow("public", "events", "RANGE (created_at)", "", "[]", false).
+			AddRow("public", "events_2024", "LIST (region)", "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')", `[["public","events"]]`, false).
+			AddRow("public", "events_2024_eu", "", "FOR VALUES IN ('eu')", `[["public","events_2024"]]`, false))
+
+	expectSimpleTable(mock, "archive", "events_2023", false, "id", "created_at", "region")
+	expectSimpleTable(mock, "public", "events", true, "id", "created_at", "region")
+	expectSimpleTable(mock, "public", "events_2024", false, "id", "created_at", "region")
+	expectSimpleTable(mock, "public", "events_2024_eu", false, "id", "created_at", "region")
+
+	tables, err := NewPostgresExtractor().ExtractTables(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTables() error = %v", err)
+	}
+
+	var order []string
+	for _, table := range tables {
+		order = append(order, table.QualifiedName())
+	}
+	wantOrder := []string{"public.events", "archive.events_2023", "public.events_2024", "public.events_2024_eu"}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Fatalf("table order = %v, want %v", order, wantOrder)
+	}
+
+	wantStmts := []string{
+		"CREATE TABLE \"public\".\"events\" (\n    \"id\" integer,\n    \"created_at\" integer,\n    \"region\" integer\n) PARTITION BY RANGE (created_at)",
+		`CREATE TABLE "archive"."events_2023" PARTITION OF "public"."events" FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')`,
+		`CREATE TABLE "public"."events_2024" PARTITION OF "public"."events" FOR VALUES FROM ('2024-01-01') TO ('2025-01-01') PARTITION BY LIST (region)`,
+		`CREATE TABLE "public"."events_2024_eu" PARTITION OF "public"."events_2024" FOR VALUES IN ('eu')`,
+	}
+	for i, table := range tables {
+		if table.CreateStmt != wantStmts[i] {
+			t.Errorf("%s CreateStmt =\n%s\nwant\n%s", table.QualifiedName(), table.CreateStmt, wantStmts[i])
+		}
+	}
+
+	if !tables[0].IsPartitioned() || !tables[2].IsPartitioned() || tables[3].IsPartitioned() {
+		t.Errorf("IsPartitioned should be true only for partitioned parents")
+	}
+	if tables[1].PartitionOf != "public.events" {
+		t.Errorf("PartitionOf = %q, want public.events", tables[1].PartitionOf)
+	}
+	// Inherited columns are still known so the transfer can skip generated ones
+	if len(tables[3].Columns) != 3 {
+		t.Errorf("partition Columns = %d, want 3", len(tables[3].Columns))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractTables_PartitionedIndexes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT n.nspname, c.relname.*FROM pg_class c").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "partition_key", "partition_bound", "parents", "has_children"}).
+			AddRow("public", "events", "RANGE (created_at)", "", "[]", false).
+			AddRow("public", "events_2024", "", "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')", `[["public","events"]]`, false))
+
+	regclass := `"public"."events"`
+	mock.ExpectQuery("SELECT.*FROM pg_catalog.pg_attribute").WithArgs(regclass).
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "column_default", "not_null", "identity", "identity_options", "generated", "collation", "storage", "comment", "is_local"}).
+			AddRow("id", "integer", "", false, "", "", "", "", "", "", true).
+			AddRow("created_at", "date", "", false, "", "", "", "", "", "", true))
+	mock.ExpectQuery("SELECT conname.*FROM pg_constraint").WithArgs(regclass).
+		WillReturnRows(sqlmock.NewRows([]string{"conname", "contype", "definition", "convalidated"}))
+	mock.ExpectQuery("SELECT COALESCE\\(obj_description").WithArgs(regclass).
+		WillReturnRows(sqlmock.NewRows([]string{"comment"}).AddRow(""))
+	// The partitions' own copies of the index are attached to it, so the query leaves them out
+	mock.ExpectQuery("SELECT.*i.relname.*FROM pg_index.*NOT EXISTS \\(SELECT 1 FROM pg_inherits").WithArgs("events", "public").
+		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "is_unique", "index_def", "constraint_def"}).
+			AddRow("events_created_at_idx", "created_at", false, "CREATE INDEX events_created_at_idx ON ONLY public.events USING btree (created_at)", ""))
+	mock.ExpectQuery("SELECT.*tc.constraint_name.*FROM information_schema.table_constraints").WithArgs("events", "public").
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "ref_schema", "ref_table", "ref_columns", "delete_rule", "update_rule"}))
+	expectSimpleTable(mock, "public", "events_2024", false, "id", "created_at")
+
+	tables, err := NewPostgresExtractor().ExtractTables(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTables() error = %v", err)
+	}
+
+	// Without ONLY the index is created on every partition too, instead of staying invalid
+	want := "CREATE INDEX events_created_at_idx ON public.events USING btree (created_at)"
+	if len(tables[0].Indexes) != 1 || tables[0].Indexes[0].CreateStmt != want {
+		t.Errorf("events indexes = %+v, want %s", tables[0].Indexes, want)
+	}
+	if len(tables[1].Indexes) != 0 {
+		t.Errorf("events_2024 indexes = %+v, want none", tables[1].Indexes)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractTables_Inheritance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT n.nspname, c.relname.*FROM pg_class c").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "partition_key", "partition_bound", "parents", "has_children"}).
+			AddRow("public", "capitals", "", "", `[["public","cities"]]`, false).
+			AddRow("public", "cities", "", "", "[]", true))
+
+	colRows := sqlmock.NewRows([]string{"column_name", "data_type", "column_default", "not_null", "identity", "identity_options", "generated", "collation", "storage", "comment", "is_local"}).
+		AddRow("name", "text", "", false, "", "", "", "", "", "", false).
+		AddRow("state", "character(2)", "", false, "", "", "", "", "", "", true)
+	mock.ExpectQuery("SELECT.*FROM pg_catalog.pg_attribute").WithArgs(`"public"."capitals"`).WillReturnRows(colRows)
+	mock.ExpectQuery("SELECT conname.*FROM pg_constraint").WithArgs(`"public"."capitals"`).
+		WillReturnRows(sqlmock.NewRows([]string{"conname", "contype", "definition", "convalidated"}))
+	mock.ExpectQuery("SELECT COALESCE\\(obj_description").WithArgs(`"public"."capitals"`).
+		WillReturnRows(sqlmock.NewRows([]string{"comment"}).AddRow(""))
+	mock.ExpectQuery("SELECT.*i.relname.*FROM pg_index").WithArgs("capitals", "public").
+		WillReturnRows(sqlmock.NewRows([]string{"index_name", "columns", "is_unique", "index_def", "constraint_def"}))
+	mock.ExpectQuery("SELECT.*tc.constraint_name.*FROM information_schema.table_constraints").WithArgs("capitals", "public").
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "columns", "ref_schema", "ref_table", "ref_columns", "delete_rule", "update_rule"}))
+	expectSimpleTable(mock, "public", "cities", true, "name")
+
+	tables, err := NewPostgresExtractor().ExtractTables(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTables() error = %v", err)
+	}
+	if len(tables) != 2 || tables[0].Name != "cities" {
+		t.Fatalf("tables = %+v, want cities before capitals", tables)
+	}
+	if !tables[0].HasChildren {
+		t.Errorf("cities.HasChildren = false, want true")
+	}
+
+	want := "CREATE TABLE \"public\".\"capitals\" (\n    \"state\" character(2)\n) INHERITS (\"public\".\"cities\")"
+	if tables[1].CreateStmt != want {
+		t.Errorf("CreateStmt =\n%s\nwant\n%s", tables[1].CreateStmt, want)
+	}
+	if !reflect.DeepEqual(tables[1].Inherits, []string{"public.cities"}) {
+		t.Errorf("Inherits = %v, want [public.cities]", tables[1].Inherits)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExcludesExtensionMembers(t *testing.T) {
+	member := func(catalog, oid string) string {
+		return regexp.QuoteMeta(fmt.Sprintf("ext.classid = '%s'::regclass AND ext.objid = %s AND ext.deptype = 'e'", catalog, oid))
+	}
+	tests := []struct {
+		name    string
+		queries []string
+		extract func(*PostgresExtractor, *sql.DB) error
+	}{
+		{
+			"types",
+			[]string{
+				"FROM pg_type t.*JOIN pg_enum.*" + member("pg_type", "t.oid"),
+				"format_type\\(t.typbasetype.*" + member("pg_type", "t.oid"),
+				"relkind = 'c'.*" + member("pg_class", "c.oid"),
+				"FROM pg_range r.*" + member("pg_type", "t.oid"),
+			},
+			func(e *PostgresExtractor, db *sql.DB) error { _, err := e.ExtractTypes(db, "testdb"); return err },
+		},
+		{
+			"functions",
+			[]string{"FROM pg_proc p.*" + member("pg_proc", "p.oid")},
+			func(e *PostgresExtractor, db *sql.DB) error { _, err := e.ExtractRoutines(db, "testdb"); return err },
+		},
+		{
+			"tables",
+			[]string{"FROM pg_class c.*relkind IN \\('r', 'p'\\).*" + member("pg_class", "c.oid")},
+			func(e *PostgresExtractor, db *sql.DB) error { _, err := e.ExtractTables(db, "testdb"); return err },
+		},
+		{
+			"sequences",
+			[]string{"FROM pg_sequences s.*" + member("pg_class", "format('%I.%I', s.schemaname, s.sequencename)::regclass")},
+			func(e *PostgresExtractor, db *sql.DB) error { _, err := e.ExtractSequences(db, "testdb"); return err },
+		},
+		{
+			"views",
+			[]string{
+				"FROM pg_views.*" + member("pg_class", "format('%I.%I', schemaname, viewname)::regclass"),
+				"FROM pg_matviews.*" + member("pg_class", "format('%I.%I', schemaname, matviewname)::regclass"),
+				"FROM pg_depend",
+			},
+			func(e *PostgresExtractor, db *sql.DB) error { _, err := e.ExtractViews(db, "testdb"); return err },
+		},
+		{
+			"triggers",
+			[]string{"SELECT EXISTS.*FROM pg_attribute", "FROM pg_trigger t.*" + member("pg_class", "c.oid")},
+			func(e *PostgresExtractor, db *sql.DB) error { _, err := e.ExtractTriggers(db, "testdb"); return err },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock: %v", err)
+			}
+			defer db.Close()
+
+			for _, query := range tt.queries {
+				if strings.HasPrefix(query, "SELECT EXISTS") {
+					mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+					continue
+				}
+				mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"nspname"}))
+			}
+			if err := tt.extract(NewPostgresExtractor(), db); err != nil {
+				t.Fatalf("extract error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("expectations not met: %v", err)
+			}
+		})
+	}
+}
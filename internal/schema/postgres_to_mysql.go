@@ -0,0 +1,982 @@
+package schema
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// mysqlMaxIdentifierLength is the longest table, column, index or constraint name MySQL allows
+const mysqlMaxIdentifierLength = 64
+
+// mysqlIndexPrefixLength is how many characters of a text column are indexed, as MySQL can't
+// index TEXT and BLOB columns whole; 191 utf8mb4 characters fit the 767-byte key limit of older
+// row formats, the same length Laravel's defaultStringLength uses
+const mysqlIndexPrefixLength = 191
+
+// ViewTranslator is implemented by translators that can rewrite view definitions for the
+// destination engine
+type ViewTranslator interface {
+	TranslateView(view ViewDef) (ViewDef, error)
+}
+
+// PostgresToMySQLTranslator rewrites PostgreSQL tables as MySQL tables in the destination database
+// Tables from every migrated schema end up side by side, so their names must not collide
+type PostgresToMySQLTranslator struct {
+	lossy   []LossyConversion
+	schemas map[string]bool // Source schemas, whose qualifiers are dropped from view definitions
+}
+
+// NewPostgresToMySQLTranslator creates a PostgreSQL to MySQL translator
+func NewPostgresToMySQLTranslator() *PostgresToMySQLTranslator {
+	return &PostgresToMySQLTranslator{}
+}
+
+// LossyConversions lists what the last translation couldn't carry over exactly
+func (t *PostgresToMySQLTranslator) LossyConversions() []LossyConversion {
+	return t.lossy
+}
+
+// report records a lossy conversion
+func (t *PostgresToMySQLTranslator) report(object, format string, args ...interface{}) {
+	t.lossy = append(t.lossy, LossyConversion{Object: object, Detail: fmt.Sprintf(format, args...)})
+}
+
+// pgCommentRef identifies the table or column a comment belongs to; column is empty for tables
+type pgCommentRef struct{ schema, table, column string }
+
+// TranslateTables rewrites PostgreSQL tables as MySQL tables
+// Partitions are folded into their root table, whose rows are read through the parent
+func (t *PostgresToMySQLTranslator) TranslateTables(db *sql.DB, database string, tables []TableSchema) ([]TableSchema, []TypeDef, error) {
+	t.lossy = nil
+	t.schemas = make(map[string]bool)
+
+	enums, err := queryEnumLabels(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	comments, err := queryComments(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make(map[string]string)
+	var sources, translated []TableSchema
+	for _, table := range tables {
+		t.schemas[table.Schema] = true
+		if table.PartitionOf != "" {
+			continue
+		}
+		if other, ok := names[table.Name]; ok {
+			return nil, nil, fmt.Errorf("tables %s and %s would both become MySQL table %s; migrate their schemas separately with --schema",
+				other, table.QualifiedName(), table.Name)
+		}
+		names[table.Name] = table.QualifiedName()
+
+		sources = append(sources, table)
+		translated = append(translated, t.translateTable(table, enums, comments))
+	}
+
+	// Foreign key names are unique per database in MySQL, but only per table in PostgreSQL
+	used := make(map[string]bool)
+	for i, table := range sources {
+		translated[i].ForeignKeys = t.translateForeignKeys(table, translated, used)
+	}
+
+	return translated, nil, nil
+}
+
+// queryEnumLabels reads the labels of every enum type, in sort order, keyed by type name as
+// format_type writes it in column types
+func queryEnumLabels(db *sql.DB) (map[string][]string, error) {
+	rows, err := db.Query(`
+		SELECT format_type(e.enumtypid, NULL), e.enumlabel
+		FROM pg_enum e
+		ORDER BY e.enumtypid, e.enumsortorder
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enum labels: %w", err)
+	}
+	defer rows.Close()
+
+	enums := make(map[string][]string)
+	for rows.Next() {
+		var typeName, label string
+		if err := rows.Scan(&typeName, &label); err != nil {
+			return nil, err
+		}
+		enums[typeName] = append(enums[typeName], label)
+	}
+	return enums, rows.Err()
+}
+
+// queryComments reads the comments on tables and their columns
+func queryComments(db *sql.DB) (map[pgCommentRef]string, error) {
+	rows, err := db.Query(`
+		SELECT n.nspname, c.relname, COALESCE(a.attname, ''), d.description
+		FROM pg_description d
+		JOIN pg_class c ON c.oid = d.objoid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = d.objsubid AND d.objsubid > 0
+		WHERE d.classoid = 'pg_class'::regclass
+			AND c.relkind IN ('r', 'p')
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make(map[pgCommentRef]string)
+	for rows.Next() {
+		var ref pgCommentRef
+		var comment string
+		if err := rows.Scan(&ref.schema, &ref.table, &ref.column, &comment); err != nil {
+			return nil, err
+		}
+		comments[ref] = comment
+	}
+	return comments, rows.Err()
+}
+
+// translateTable builds the MySQL CREATE TABLE statement and indexes for a PostgreSQL table
+// The result keeps the source schema, which the rows are read from
+func (t *PostgresToMySQLTranslator) translateTable(table TableSchema, enums map[string][]string, comments map[pgCommentRef]string) TableSchema {
+	object := table.QualifiedName()
+	out := TableSchema{
+		Schema:      table.Schema,
+		Name:        table.Name,
+		PrimaryKey:  table.PrimaryKey,
+		HasChildren: table.HasChildren,
+	}
+	if table.IsPartitioned() {
+		t.report(object, "PARTITION BY %s is dropped; the table holds the rows of every partition", table.PartitionKey)
+	}
+	if len(table.Inherits) > 0 {
+		t.report(object, "inheritance from %s is dropped; the table is created with all its columns", strings.Join(table.Inherits, ", "))
+	}
+
+	types := make(map[string]string, len(table.Columns))
+	var lines []string
+	for _, c := range table.Columns {
+		col, definition := t.translateColumn(table, c, enums)
+		if comment, ok := comments[pgCommentRef{table.Schema, table.Name, c.Name}]; ok {
+			definition += " COMMENT " + quoteMySQLLiteral(comment)
+		}
+		out.Columns = append(out.Columns, col)
+		types[c.Name] = col.DataType
+		lines = append(lines, "  "+definition)
+	}
+
+	if len(table.PrimaryKey) > 0 {
+		parts := make([]string, len(table.PrimaryKey))
+		for i, column := range table.PrimaryKey {
+			parts[i] = t.keyPart(object+" primary key", column, types[column], true)
+		}
+		lines = append(lines, fmt.Sprintf("  PRIMARY KEY (%s)", strings.Join(parts, ", ")))
+	}
+
+	// CHECK constraints are written in PostgreSQL's expression syntax
+	if ddl, err := parseCreateTable(table.CreateStmt, postgresSyntax); err == nil {
+		for _, c := range ddl.Clauses {
+			if c.Kind == clauseCheck {
+				t.report(object, "CHECK constraint %s isn't migrated; recreate it in MySQL syntax", c.Name)
+			}
+		}
+	}
+
+	out.CreateStmt = fmt.Sprintf("CREATE TABLE %s (\n%s\n) DEFAULT CHARSET=utf8mb4", quoteMySQLIdent(table.Name), strings.Join(lines, ",\n"))
+	if comment, ok := comments[pgCommentRef{table.Schema, table.Name, ""}]; ok {
+		out.CreateStmt += " COMMENT=" + quoteMySQLLiteral(comment)
+	}
+
+	for _, ix := range table.Indexes {
+		if index, ok := t.translateIndex(table, ix, types); ok {
+			out.Indexes = append(out.Indexes, index)
+		}
+	}
+
+	return out
+}
+
+// translateColumn returns the MySQL column and its definition in CREATE TABLE
+func (t *PostgresToMySQLTranslator) translateColumn(table TableSchema, c ColumnDef, enums map[string][]string) (ColumnDef, string) {
+	object := table.QualifiedName() + "." + c.Name
+	mysqlType := t.columnType(object, c.DataType, enums)
+
+	col := ColumnDef{
+		Name:       c.Name,
+		DataType:   mysqlType,
+		IsNullable: c.IsNullable,
+		SourceType: c.DataType,
+	}
+	definition := quoteMySQLIdent(c.Name) + " " + mysqlType
+	if !c.IsNullable {
+		definition += " NOT NULL"
+	}
+
+	serial := c.DefaultValue.Valid && strings.HasPrefix(c.DefaultValue.String, "nextval(")
+	switch {
+	case strings.HasPrefix(c.Extra, "IDENTITY") || serial:
+		// MySQL needs the AUTO_INCREMENT column to lead a key, and allows one per table
+		if len(table.PrimaryKey) > 0 && table.PrimaryKey[0] == c.Name && isMySQLIntegerType(mysqlType) {
+			definition += " AUTO_INCREMENT"
+			col.Extra = "auto_increment"
+			if c.Extra == "IDENTITY ALWAYS" {
+				t.report(object, "GENERATED ALWAYS becomes AUTO_INCREMENT, which accepts explicit values")
+			}
+		} else {
+			t.report(object, "sequence default isn't migrated; MySQL only allows AUTO_INCREMENT on the first primary key column")
+		}
+	case c.Extra == "STORED GENERATED":
+		t.report(object, "generated column becomes a regular column holding the copied values")
+	case c.DefaultValue.Valid:
+		if value, ok := t.columnDefault(object, c, mysqlType); ok {
+			definition += " DEFAULT " + value
+			col.DefaultValue = sql.NullString{String: value, Valid: true}
+		}
+	}
+	return col, definition
+}
+
+// columnType maps a PostgreSQL column type, as format_type writes it, to MySQL
+func (t *PostgresToMySQLTranslator) columnType(object, pgType string, enums map[string][]string) string {
+	if strings.HasSuffix(pgType, "[]") {
+		t.report(object, "%s becomes a JSON array", pgType)
+		return "json"
+	}
+	if labels, ok := enums[pgType]; ok {
+		values := make([]string, len(labels))
+		for i, l := range labels {
+			values[i] = quoteMySQLLiteral(l)
+		}
+		return "enum(" + strings.Join(values, ",") + ")"
+	}
+
+	base, args := splitPostgresType(pgType)
+	switch base {
+	case "smallint", "bigint":
+		return base
+	case "integer":
+		return "int"
+	case "boolean":
+		return "tinyint(1)"
+	case "real":
+		return "float"
+	case "double precision":
+		return "double"
+	case "numeric":
+		precision, scale, ok := numericArguments(args)
+		if !ok {
+			t.report(object, "numeric without a precision becomes decimal(65,30)")
+			return "decimal(65,30)"
+		}
+		if precision > 65 || scale > 30 {
+			t.report(object, "numeric%s is narrowed to MySQL's largest decimal", args)
+			precision, scale = min(precision, 65), min(scale, 30)
+		}
+		return fmt.Sprintf("decimal(%d,%d)", precision, scale)
+	case "money":
+		return "decimal(19,2)"
+	case "character varying":
+		// Longer varchars wouldn't fit MySQL's 65,535-byte row limit in utf8mb4
+		if n := typeLength(pgType, 0); n > 0 && n <= 16383 {
+			return fmt.Sprintf("varchar(%d)", n)
+		}
+		return "longtext"
+	case "character":
+		if n := typeLength(pgType, 1); n > 255 {
+			return fmt.Sprintf("varchar(%d)", n)
+		}
+		return fmt.Sprintf("char(%d)", typeLength(pgType, 1))
+	case "text", "citext", "xml":
+		return "longtext"
+	case "bytea":
+		return "longblob"
+	case "json", "jsonb":
+		return "json"
+	case "uuid":
+		return "char(36)"
+	case "date":
+		return "date"
+	case "timestamp without time zone":
+		return fmt.Sprintf("datetime(%d)", typeLength(pgType, 6))
+	case "timestamp with time zone":
+		t.report(object, "timestamptz becomes DATETIME holding UTC values")
+		return fmt.Sprintf("datetime(%d)", typeLength(pgType, 6))
+	case "time without time zone":
+		return fmt.Sprintf("time(%d)", typeLength(pgType, 6))
+	case "time with time zone":
+		t.report(object, "time zone offset of timetz is dropped")
+		return fmt.Sprintf("time(%d)", typeLength(pgType, 6))
+	case "interval":
+		t.report(object, "interval is stored as text")
+		return "varchar(255)"
+	case "bit":
+		if n := typeLength(pgType, 1); n <= 64 {
+			return fmt.Sprintf("bit(%d)", n)
+		}
+		t.report(object, "%s is stored as a string of 0s and 1s", pgType)
+		return "longtext"
+	case "bit varying":
+		t.report(object, "%s is stored as a string of 0s and 1s", pgType)
+		return "longtext"
+	case "inet", "cidr":
+		return "varchar(43)"
+	case "macaddr", "macaddr8":
+		return "varchar(23)"
+	case "tsvector", "tsquery":
+		t.report(object, "%s is stored as text; search it with a FULLTEXT index instead", pgType)
+		return "longtext"
+	default:
+		t.report(object, "type %s has no MySQL equivalent; the column becomes LONGTEXT", pgType)
+		return "longtext"
+	}
+}
+
+// columnDefault translates a PostgreSQL default expression, as pg_get_expr writes it
+func (t *PostgresToMySQLTranslator) columnDefault(object string, c ColumnDef, mysqlType string) (string, bool) {
+	expr := stripPostgresCast(c.DefaultValue.String)
+	lower := strings.ToLower(expr)
+
+	switch {
+	case lower == "null":
+		return "", false
+	case mysqlType == "tinyint(1)" && (lower == "true" || lower == "false"):
+		return mysqlBool(lower), true
+	case strings.HasPrefix(mysqlType, "datetime") && isPostgresNow(lower):
+		if fsp := typeLength(mysqlType, 0); fsp > 0 {
+			return fmt.Sprintf("CURRENT_TIMESTAMP(%d)", fsp), true
+		}
+		return "CURRENT_TIMESTAMP", true
+	case mysqlType == "date" && lower == "current_date":
+		return "(CURRENT_DATE)", true
+	case mysqlType == "char(36)" && (lower == "gen_random_uuid()" || lower == "uuid_generate_v4()"):
+		return "(uuid())", true
+	}
+
+	if value, ok := postgresStringValue(expr); ok {
+		switch {
+		case strings.HasSuffix(c.DataType, "[]"):
+			if array, err := PostgresArrayJSON(value, strings.TrimSuffix(c.DataType, "[]")); err == nil {
+				return "(" + quoteMySQLLiteral(array) + ")", true
+			}
+		case mysqlType == "tinyint(1)":
+			return mysqlBool(value), true
+		case isMySQLNumericType(mysqlType):
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				return value, true
+			}
+		case mysqlType == "longblob" || strings.HasPrefix(mysqlType, "bit"):
+		case mysqlType == "json" || mysqlType == "longtext":
+			// TEXT and JSON columns only take expression defaults
+			return "(" + quoteMySQLLiteral(value) + ")", true
+		default:
+			return quoteMySQLLiteral(value), true
+		}
+	} else if _, err := strconv.ParseFloat(expr, 64); err == nil && isMySQLNumericType(mysqlType) {
+		return expr, true
+	}
+
+	t.report(object, "default %s isn't migrated", c.DefaultValue.String)
+	return "", false
+}
+
+// pgKeyPart is one key part of a PostgreSQL index
+type pgKeyPart struct {
+	column     string
+	descending bool
+	expression bool // Anything but a plain column, e.g. lower((email)::text)
+}
+
+// translateIndex builds a MySQL ADD INDEX clause from a PostgreSQL index or UNIQUE constraint
+func (t *PostgresToMySQLTranslator) translateIndex(table TableSchema, ix IndexDef, types map[string]string) (IndexDef, bool) {
+	object := table.QualifiedName() + "." + ix.Name
+
+	var parts []pgKeyPart
+	if ix.IsConstraint {
+		switch kind := postgresConstraintKind(ix.CreateStmt); kind {
+		case "UNIQUE":
+			for _, column := range ix.Columns {
+				parts = append(parts, pgKeyPart{column: column})
+			}
+		case "EXCLUDE":
+			t.report(object, "EXCLUDE constraint has no MySQL equivalent")
+			return IndexDef{}, false
+		default:
+			t.report(object, "%s constraint isn't migrated; recreate it in MySQL syntax", kind)
+			return IndexDef{}, false
+		}
+	} else {
+		method, keyParts, include, partial := parsePostgresIndexDefinition(ix.CreateStmt)
+		if method != "btree" && method != "hash" {
+			t.report(object, "%s index has no MySQL equivalent", method)
+			return IndexDef{}, false
+		}
+		for _, p := range keyParts {
+			if p.expression {
+				t.report(object, "expression index has no MySQL equivalent")
+				return IndexDef{}, false
+			}
+		}
+		if partial {
+			if ix.IsUnique {
+				t.report(object, "partial unique index isn't migrated; MySQL would enforce it on every row")
+				return IndexDef{}, false
+			}
+			t.report(object, "partial index covers every row")
+		}
+		if include {
+			t.report(object, "INCLUDE columns are dropped from the index")
+		}
+		parts = keyParts
+	}
+	if len(parts) == 0 {
+		t.report(object, "index definition %q isn't understood", ix.CreateStmt)
+		return IndexDef{}, false
+	}
+
+	keyParts := make([]string, len(parts))
+	for i, p := range parts {
+		if types[p.column] == "json" {
+			t.report(object, "JSON column %s can't be indexed in MySQL", p.column)
+			return IndexDef{}, false
+		}
+		keyParts[i] = t.keyPart(object, p.column, types[p.column], ix.IsUnique)
+		if p.descending {
+			keyParts[i] += " DESC"
+		}
+	}
+
+	kind := "INDEX"
+	if ix.IsUnique {
+		kind = "UNIQUE INDEX"
+	}
+	definition := fmt.Sprintf("ADD %s %s (%s)", kind, quoteMySQLIdent(ix.Name), strings.Join(keyParts, ", "))
+	return IndexDef{
+		Name:       ix.Name,
+		Columns:    ix.Columns,
+		IsUnique:   ix.IsUnique,
+		Definition: definition,
+		CreateStmt: fmt.Sprintf("ALTER TABLE %s %s", quoteMySQLIdent(table.Name), definition),
+	}, true
+}
+
+// keyPart quotes an index column, indexing a prefix of columns MySQL can't index whole
+func (t *PostgresToMySQLTranslator) keyPart(object, column, mysqlType string, unique bool) string {
+	if !needsIndexPrefix(mysqlType) {
+		return quoteMySQLIdent(column)
+	}
+	if unique {
+		t.report(object, "uniqueness of %s is only enforced on its first %d characters", column, mysqlIndexPrefixLength)
+	}
+	return fmt.Sprintf("%s(%d)", quoteMySQLIdent(column), mysqlIndexPrefixLength)
+}
+
+// parsePostgresIndexDefinition reads the access method and key parts of a pg_get_indexdef
+// statement, and whether it has INCLUDE columns or a WHERE clause
+func parsePostgresIndexDefinition(definition string) (string, []pgKeyPart, bool, bool) {
+	method := "btree"
+	var parts []pgKeyPart
+	var include, partial bool
+
+	depth := 0
+	listDone := false
+	expectMethod := false
+	var part *pgKeyPart
+	for _, tok := range tokenizeSQL(definition, postgresSyntax) {
+		switch {
+		case tok.Kind == tokSpace || tok.Kind == tokComment:
+		case tok.isPunct(definition, '('):
+			depth++
+			switch {
+			case listDone:
+			case depth == 1:
+				parts = append(parts, pgKeyPart{})
+				part = &parts[len(parts)-1]
+			default:
+				part.expression = true
+			}
+		case tok.isPunct(definition, ')'):
+			depth--
+			if depth == 0 && part != nil {
+				listDone = true
+				part = nil
+			}
+		case depth == 0:
+			switch {
+			case expectMethod:
+				method = strings.ToLower(tok.Value)
+				expectMethod = false
+			case tok.isKeyword("USING"):
+				expectMethod = true
+			case listDone && tok.isKeyword("INCLUDE"):
+				include = true
+			case listDone && tok.isKeyword("WHERE"):
+				partial = true
+			}
+		case listDone || part == nil:
+		case depth == 1 && tok.isPunct(definition, ','):
+			parts = append(parts, pgKeyPart{})
+			part = &parts[len(parts)-1]
+		case depth == 1 && tok.isIdent() && part.column == "" && !part.expression:
+			part.column = tok.Value
+		case depth == 1 && tok.isKeyword("DESC"):
+			part.descending = true
+		case depth == 1 && tok.isIdent():
+			// Operator class, collation, ASC or NULLS FIRST/LAST
+		default:
+			part.expression = true
+		}
+	}
+	return method, parts, include, partial
+}
+
+// postgresConstraintKind returns the kind of an ALTER TABLE ... ADD CONSTRAINT statement:
+// UNIQUE, EXCLUDE or CHECK
+func postgresConstraintKind(stmt string) string {
+	tokens := tokenizeSQL(stmt, postgresSyntax)
+	for i, tok := range tokens {
+		if !tok.isKeyword("CONSTRAINT") {
+			continue
+		}
+		// The name follows CONSTRAINT, then the kind
+		seen := 0
+		for _, next := range tokens[i+1:] {
+			if next.Kind == tokSpace || next.Kind == tokComment {
+				continue
+			}
+			if seen++; seen == 2 {
+				return strings.ToUpper(next.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// translateForeignKeys builds MySQL ALTER TABLE statements for a PostgreSQL table's foreign keys
+func (t *PostgresToMySQLTranslator) translateForeignKeys(table TableSchema, translated []TableSchema, used map[string]bool) []ForeignKeyDef {
+	types := make(map[string]map[string]string)
+	for _, tt := range translated {
+		types[tt.Name] = make(map[string]string, len(tt.Columns))
+		for _, c := range tt.Columns {
+			types[tt.Name][c.Name] = c.DataType
+		}
+	}
+
+	var fks []ForeignKeyDef
+	for _, fk := range table.ForeignKeys {
+		object := table.QualifiedName() + "." + fk.Name
+		if _, ok := types[fk.RefTable]; !ok {
+			t.report(object, "foreign key isn't migrated; %s.%s isn't part of the migration", fk.RefSchema, fk.RefTable)
+			continue
+		}
+		if column, ok := unindexableColumn(fk.Columns, types[table.Name]); ok {
+			t.report(object, "foreign key isn't migrated; MySQL can't reference %s column %s", types[table.Name][column], column)
+			continue
+		}
+		if column, ok := unindexableColumn(fk.RefColumns, types[fk.RefTable]); ok {
+			t.report(object, "foreign key isn't migrated; MySQL can't reference %s column %s.%s", types[fk.RefTable][column], fk.RefTable, column)
+			continue
+		}
+
+		name := uniqueIdentifier(fk.Name, mysqlMaxIdentifierLength, used)
+		if name != fk.Name {
+			t.report(object, "foreign key is renamed to %s; MySQL constraint names are unique per database", name)
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			quoteMySQLIdent(table.Name), quoteMySQLIdent(name), quoteMySQLIdentList(fk.Columns),
+			quoteMySQLIdent(fk.RefTable), quoteMySQLIdentList(fk.RefColumns))
+		for _, rule := range []struct{ event, action string }{{"DELETE", fk.OnDelete}, {"UPDATE", fk.OnUpdate}} {
+			switch rule.action {
+			case "", "NO ACTION":
+			case "SET DEFAULT":
+				// InnoDB rejects SET DEFAULT
+				t.report(object, "ON %s SET DEFAULT becomes NO ACTION", rule.event)
+			default:
+				stmt += fmt.Sprintf(" ON %s %s", rule.event, rule.action)
+			}
+		}
+
+		fk.Name = name
+		fk.RefSchema = ""
+		fk.ConstraintStmt = stmt
+		fks = append(fks, fk)
+	}
+	return fks
+}
+
+// unindexableColumn returns the first of the columns whose MySQL type can't back a foreign key
+func unindexableColumn(columns []string, types map[string]string) (string, bool) {
+	for _, c := range columns {
+		if types[c] == "json" || needsIndexPrefix(types[c]) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// TranslateView rewrites a PostgreSQL view definition, as pg_get_viewdef writes it, for MySQL
+// Identifiers are requoted, schema qualifiers dropped and casts to string and numeric types
+// removed; anything else PostgreSQL-specific is an error, so the view is left for rewriting by hand
+func (t *PostgresToMySQLTranslator) TranslateView(view ViewDef) (ViewDef, error) {
+	if view.Materialized {
+		return ViewDef{}, fmt.Errorf("MySQL has no materialized views")
+	}
+	_, body, ok := strings.Cut(view.CreateStmt, " AS\n")
+	if !ok {
+		return ViewDef{}, fmt.Errorf("view definition isn't understood")
+	}
+	body = strings.TrimRight(strings.TrimSpace(body), ";")
+
+	tokens := tokenizeSQL(body, postgresSyntax)
+	var sb strings.Builder
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		text := body[tok.Start:tok.End]
+		next := nextSignificant(tokens, i)
+		switch {
+		case tok.Kind == tokQuotedIdent:
+			if t.schemas[tok.Value] && isPunctAt(body, tokens, next, '.') {
+				i = next
+				continue
+			}
+			sb.WriteString(quoteMySQLIdent(tok.Value))
+		case tok.Kind == tokIdent:
+			if t.schemas[tok.Value] && isPunctAt(body, tokens, next, '.') {
+				i = next
+				continue
+			}
+			for _, k := range []string{"ILIKE", "SIMILAR", "ARRAY", "FILTER"} {
+				if tok.isKeyword(k) {
+					return ViewDef{}, fmt.Errorf("%s has no MySQL equivalent", k)
+				}
+			}
+			if tok.isKeyword("DISTINCT") && next < len(tokens) && tokens[next].isKeyword("ON") {
+				return ViewDef{}, fmt.Errorf("DISTINCT ON has no MySQL equivalent")
+			}
+			sb.WriteString(text)
+		case tok.Kind == tokString:
+			if text[0] != '\'' {
+				return ViewDef{}, fmt.Errorf("escape and dollar-quoted strings aren't translated")
+			}
+			// Backslashes are plain characters in PostgreSQL strings but escapes in MySQL's
+			sb.WriteString(strings.ReplaceAll(text, `\`, `\\`))
+		case tok.isPunct(body, ':') && isPunctAt(body, tokens, i+1, ':'):
+			typeName, end := readPostgresCastType(body, tokens, i+2)
+			if !droppableCast(typeName) {
+				return ViewDef{}, fmt.Errorf("cast to %s isn't translated", typeName)
+			}
+			i = end - 1
+		case tok.Kind == tokOther && strings.ContainsAny(text, "~@?#[|"):
+			return ViewDef{}, fmt.Errorf("operator %s has no MySQL equivalent", text)
+		case tok.isPunct(body, '-') && isPunctAt(body, tokens, i+1, '>'):
+			return ViewDef{}, fmt.Errorf("JSON operators aren't translated")
+		default:
+			sb.WriteString(text)
+		}
+	}
+
+	return ViewDef{
+		Schema:       view.Schema,
+		Name:         view.Name,
+		CreateStmt:   fmt.Sprintf("CREATE VIEW %s AS\n%s", quoteMySQLIdent(view.Name), sb.String()),
+		Dependencies: view.Dependencies,
+	}, nil
+}
+
+// nextSignificant returns the index of the first token after i that isn't whitespace or a comment
+func nextSignificant(tokens []sqlToken, i int) int {
+	for i++; i < len(tokens); i++ {
+		if tokens[i].Kind != tokSpace && tokens[i].Kind != tokComment {
+			break
+		}
+	}
+	return i
+}
+
+// isPunctAt reports whether tokens[i] exists and is the given punctuation character
+func isPunctAt(text string, tokens []sqlToken, i int, punct byte) bool {
+	return i < len(tokens) && tokens[i].isPunct(text, punct)
+}
+
+// readPostgresCastType reads the type name of a :: cast starting at tokens[i], including
+// multi-word names and type arguments, and returns it with the index of the token after it
+func readPostgresCastType(text string, tokens []sqlToken, i int) (string, int) {
+	if i >= len(tokens) || !tokens[i].isIdent() {
+		return "", i
+	}
+	words := []string{strings.ToLower(tokens[i].Value)}
+	end := i + 1
+	for {
+		next := nextSignificant(tokens, end-1)
+		if next >= len(tokens) || tokens[next].Kind != tokIdent {
+			break
+		}
+		word := strings.ToLower(tokens[next].Value)
+		prev := words[len(words)-1]
+		continues := (prev == "character" && word == "varying") || (prev == "double" && word == "precision") ||
+			((prev == "timestamp" || prev == "time") && (word == "with" || word == "without")) ||
+			((prev == "with" || prev == "without") && word == "time") || (prev == "time" && word == "zone" && len(words) > 1)
+		if !continues {
+			break
+		}
+		words = append(words, word)
+		end = next + 1
+	}
+	typeName := strings.Join(words, " ")
+
+	// Type arguments and array brackets directly follow the name
+	if isPunctAt(text, tokens, end, '(') {
+		for depth := 0; end < len(tokens); end++ {
+			if tokens[end].isPunct(text, '(') {
+				depth++
+			} else if tokens[end].isPunct(text, ')') {
+				if depth--; depth == 0 {
+					end++
+					break
+				}
+			}
+		}
+	}
+	if isPunctAt(text, tokens, end, '[') {
+		typeName += "[]"
+	}
+	return typeName, end
+}
+
+// droppableCast reports whether a cast can be left out of a MySQL view, as MySQL converts
+// between strings and numbers implicitly
+func droppableCast(typeName string) bool {
+	switch typeName {
+	case "text", "character varying", "varchar", "character", "bpchar", "name", "unknown",
+		"smallint", "integer", "bigint", "numeric", "real", "double precision":
+		return true
+	default:
+		return false
+	}
+}
+
+// PostgresArrayJSON converts a PostgreSQL array literal, e.g. {1,2} or {{"a",NULL}}, to a JSON
+// array; elements of numeric, boolean and JSON arrays keep their JSON types, others become strings
+func PostgresArrayJSON(literal, elementType string) (string, error) {
+	// Arrays with non-default bounds are prefixed with their dimensions, e.g. [0:1]={1,2}
+	if strings.HasPrefix(literal, "[") {
+		if _, rest, ok := strings.Cut(literal, "="); ok {
+			literal = rest
+		}
+	}
+	p := pgArrayParser{input: literal, elementType: elementType}
+	value, err := p.parseArray()
+	if err != nil {
+		return "", err
+	}
+	if p.pos != len(p.input) {
+		return "", fmt.Errorf("unexpected %q after array", p.input[p.pos:])
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// pgArrayParser reads the text form of a PostgreSQL array
+type pgArrayParser struct {
+	input       string
+	pos         int
+	elementType string
+}
+
+// parseArray reads a {...} array, nested or not
+func (p *pgArrayParser) parseArray() ([]interface{}, error) {
+	if p.pos >= len(p.input) || p.input[p.pos] != '{' {
+		return nil, fmt.Errorf("array literal %q doesn't start with {", p.input)
+	}
+	p.pos++
+	values := []interface{}{}
+	for p.pos < len(p.input) {
+		switch c := p.input[p.pos]; {
+		case c == '}':
+			p.pos++
+			return values, nil
+		case c == ',' || isSpaceByte(c):
+			p.pos++
+		case c == '{':
+			nested, err := p.parseArray()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, nested)
+		case c == '"':
+			values = append(values, p.element(p.quoted(), true))
+		default:
+			values = append(values, p.element(p.unquoted(), false))
+		}
+	}
+	return nil, fmt.Errorf("array literal %q isn't terminated", p.input)
+}
+
+// quoted reads a double-quoted element, where backslashes escape the next character
+func (p *pgArrayParser) quoted() string {
+	var sb strings.Builder
+	for p.pos++; p.pos < len(p.input); p.pos++ {
+		c := p.input[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input):
+			p.pos++
+			sb.WriteByte(p.input[p.pos])
+		case c == '"':
+			p.pos++
+			return sb.String()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// unquoted reads an element up to the next comma or closing brace
+func (p *pgArrayParser) unquoted() string {
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] != ',' && p.input[p.pos] != '}' {
+		p.pos++
+	}
+	return strings.TrimSpace(p.input[start:p.pos])
+}
+
+// element converts an array element to its JSON value
+func (p *pgArrayParser) element(value string, quoted bool) interface{} {
+	if !quoted && strings.EqualFold(value, "NULL") {
+		return nil
+	}
+	base, _ := splitPostgresType(p.elementType)
+	switch base {
+	case "smallint", "integer", "bigint", "real", "double precision", "numeric":
+		// NaN and Infinity have no JSON number form
+		if _, err := strconv.ParseFloat(value, 64); err == nil && !strings.ContainsAny(value, "nN") {
+			return json.Number(value)
+		}
+	case "boolean":
+		return value == "t" || value == "true"
+	case "json", "jsonb":
+		if json.Valid([]byte(value)) {
+			return json.RawMessage(value)
+		}
+	}
+	return value
+}
+
+// splitPostgresType splits a format_type type into its name and parenthesized arguments,
+// e.g. "timestamp(3) without time zone" into "timestamp without time zone" and "(3)"
+func splitPostgresType(pgType string) (string, string) {
+	start := strings.IndexByte(pgType, '(')
+	end := strings.IndexByte(pgType, ')')
+	if start < 0 || end < start {
+		return pgType, ""
+	}
+	return pgType[:start] + pgType[end+1:], pgType[start : end+1]
+}
+
+// numericArguments parses the (precision,scale) or (precision) arguments of a numeric type
+func numericArguments(args string) (int, int, bool) {
+	if args == "" {
+		return 0, 0, false
+	}
+	precision, scale, _ := strings.Cut(args[1:len(args)-1], ",")
+	p, err := strconv.Atoi(strings.TrimSpace(precision))
+	if err != nil {
+		return 0, 0, false
+	}
+	s, _ := strconv.Atoi(strings.TrimSpace(scale))
+	return p, s, true
+}
+
+// stripPostgresCast removes a trailing top-level ::type cast from an expression
+func stripPostgresCast(expr string) string {
+	tokens := tokenizeSQL(expr, postgresSyntax)
+	depth := 0
+	for i, tok := range tokens {
+		switch {
+		case tok.isPunct(expr, '('):
+			depth++
+		case tok.isPunct(expr, ')'):
+			depth--
+		case depth == 0 && tok.isPunct(expr, ':') && isPunctAt(expr, tokens, i+1, ':'):
+			return strings.TrimSpace(expr[:tok.Start])
+		}
+	}
+	return strings.TrimSpace(expr)
+}
+
+// postgresStringValue unquotes a standard PostgreSQL string literal
+func postgresStringValue(literal string) (string, bool) {
+	tokens := tokenizeSQL(literal, postgresSyntax)
+	if len(tokens) != 1 || tokens[0].Kind != tokString || literal[0] != '\'' {
+		return "", false
+	}
+	return strings.ReplaceAll(literal[1:len(literal)-1], "''", "'"), true
+}
+
+// isPostgresNow reports whether a default expression is the current timestamp
+func isPostgresNow(expr string) bool {
+	switch expr {
+	case "now()", "current_timestamp", "transaction_timestamp()", "statement_timestamp()", "clock_timestamp()", "localtimestamp":
+		return true
+	default:
+		return false
+	}
+}
+
+// mysqlBool converts a PostgreSQL boolean literal to MySQL's 1 or 0
+func mysqlBool(value string) string {
+	switch strings.ToLower(value) {
+	case "t", "true", "y", "yes", "on", "1":
+		return "1"
+	default:
+		return "0"
+	}
+}
+
+// isMySQLIntegerType reports whether a translated MySQL column type can be AUTO_INCREMENT
+func isMySQLIntegerType(mysqlType string) bool {
+	return mysqlType == "smallint" || mysqlType == "int" || mysqlType == "bigint"
+}
+
+// isMySQLNumericType reports whether a translated MySQL column type holds numbers
+func isMySQLNumericType(mysqlType string) bool {
+	return isMySQLIntegerType(mysqlType) || mysqlType == "float" || mysqlType == "double" || strings.HasPrefix(mysqlType, "decimal")
+}
+
+// needsIndexPrefix reports whether MySQL can only index a prefix of a translated column type
+func needsIndexPrefix(mysqlType string) bool {
+	switch {
+	case mysqlType == "longtext" || mysqlType == "longblob":
+		return true
+	case strings.HasPrefix(mysqlType, "varchar("):
+		// 768 utf8mb4 characters fill InnoDB's 3072-byte key limit
+		return typeLength(mysqlType, 0) > 768
+	default:
+		return false
+	}
+}
+
+// quoteMySQLLiteral quotes a MySQL string literal, escaping backslashes as the default sql_mode requires
+func quoteMySQLLiteral(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteMySQLIdentList quotes and comma-separates MySQL identifiers
+func quoteMySQLIdentList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = quoteMySQLIdent(n)
+	}
+	return strings.Join(quoted, ", ")
+}
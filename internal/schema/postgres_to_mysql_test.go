@@ -0,0 +1,341 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// pgShopTables is a small schema spread over two schemas as the PostgreSQL extractor returns it
+func pgShopTables() []TableSchema {
+	return []TableSchema{
+		{
+			Schema: "public",
+			Name:   "users",
+			CreateStmt: "CREATE TABLE \"public\".\"users\" (\n" +
+				"    \"id\" bigint GENERATED BY DEFAULT AS IDENTITY (SEQUENCE NAME public.users_id_seq) NOT NULL,\n" +
+				"    \"balance\" numeric,\n" +
+				"    CONSTRAINT \"users_balance_check\" CHECK ((balance >= (0)::numeric))\n" +
+				")",
+			Columns: []ColumnDef{
+				{Name: "id", DataType: "bigint", Extra: "IDENTITY BY DEFAULT"},
+				{Name: "email", DataType: "character varying(255)"},
+				{Name: "active", DataType: "boolean", DefaultValue: nullString("true")},
+				{Name: "status", DataType: "mood", IsNullable: true, DefaultValue: nullString("'happy'::mood")},
+				{Name: "bio", DataType: "text", IsNullable: true, DefaultValue: nullString("''::text")},
+				{Name: "settings", DataType: "jsonb", IsNullable: true, DefaultValue: nullString("'{}'::jsonb")},
+				{Name: "tags", DataType: "text[]", IsNullable: true, DefaultValue: nullString("'{}'::text[]")},
+				{Name: "external_id", DataType: "uuid", DefaultValue: nullString("gen_random_uuid()")},
+				{Name: "created_at", DataType: "timestamp(0) with time zone", IsNullable: true, DefaultValue: nullString("now()")},
+				{Name: "balance", DataType: "numeric", IsNullable: true},
+			},
+			PrimaryKey: []string{"id"},
+			Indexes: []IndexDef{
+				{Name: "users_email_key", Columns: []string{"email"}, IsUnique: true, IsConstraint: true,
+					CreateStmt: `ALTER TABLE "public"."users" ADD CONSTRAINT "users_email_key" UNIQUE (email)`},
+				{Name: "users_bio_idx", Columns: []string{"bio"}, CreateStmt: "CREATE INDEX users_bio_idx ON public.users USING btree (bio)"},
+				{Name: "users_lower_email_idx", IsUnique: true, CreateStmt: "CREATE UNIQUE INDEX users_lower_email_idx ON public.users USING btree (lower((email)::text))"},
+				{Name: "users_recent_idx", Columns: []string{"created_at"}, CreateStmt: "CREATE INDEX users_recent_idx ON public.users USING btree (created_at DESC) WHERE active"},
+				{Name: "users_tags_idx", Columns: []string{"tags"}, CreateStmt: "CREATE INDEX users_tags_idx ON public.users USING gin (tags)"},
+				{Name: "users_bio_key", Columns: []string{"bio"}, IsUnique: true, CreateStmt: "CREATE UNIQUE INDEX users_bio_key ON public.users USING btree (bio)"},
+			},
+		},
+		{
+			Schema:     "audit",
+			Name:       "events",
+			CreateStmt: "CREATE TABLE \"audit\".\"events\" (\n    \"id\" integer NOT NULL\n)",
+			Columns: []ColumnDef{
+				{Name: "id", DataType: "integer", DefaultValue: nullString("nextval('audit.events_id_seq'::regclass)")},
+				{Name: "user_id", DataType: "bigint", IsNullable: true},
+				{Name: "payload", DataType: "json", IsNullable: true},
+				{Name: "happened_at", DataType: "timestamp without time zone"},
+				{Name: "duration", DataType: "interval", IsNullable: true},
+			},
+			PrimaryKey: []string{"id"},
+			ForeignKeys: []ForeignKeyDef{
+				{Name: "fk_user", Columns: []string{"user_id"}, RefSchema: "public", RefTable: "users", RefColumns: []string{"id"}, OnDelete: "SET DEFAULT", OnUpdate: "NO ACTION"},
+			},
+		},
+		{
+			Schema:       "public",
+			Name:         "orders",
+			CreateStmt:   "CREATE TABLE \"public\".\"orders\" (\n    \"id\" bigint NOT NULL\n) PARTITION BY RANGE (placed_at)",
+			PartitionKey: "RANGE (placed_at)",
+			Columns: []ColumnDef{
+				{Name: "id", DataType: "bigint", DefaultValue: nullString("nextval('orders_id_seq'::regclass)")},
+				{Name: "user_id", DataType: "bigint"},
+				{Name: "placed_at", DataType: "date"},
+			},
+			PrimaryKey: []string{"id", "placed_at"},
+			ForeignKeys: []ForeignKeyDef{
+				{Name: "fk_user", Columns: []string{"user_id"}, RefSchema: "public", RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+			},
+		},
+		{
+			Schema:         "public",
+			Name:           "orders_2024",
+			PartitionOf:    "public.orders",
+			PartitionBound: "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+		},
+	}
+}
+
+func expectPostgresShopCatalog(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT format_type.*FROM pg_enum").
+		WillReturnRows(sqlmock.NewRows([]string{"format_type", "enumlabel"}).
+			AddRow("mood", "sad").
+			AddRow("mood", "happy"))
+	mock.ExpectQuery("SELECT.*FROM pg_description").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "attname", "description"}).
+			AddRow("public", "users", "email", "Login address").
+			AddRow("public", "users", "", "Application users"))
+}
+
+func TestPostgresToMySQLTranslator_TranslateTables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+	expectPostgresShopCatalog(mock)
+
+	tr := NewPostgresToMySQLTranslator()
+	tables, types, err := tr.TranslateTables(db, "shop", pgShopTables())
+	if err != nil {
+		t.Fatalf("TranslateTables() error = %v", err)
+	}
+	// The partition is folded into its root table
+	if len(tables) != 3 || len(types) != 0 {
+		t.Fatalf("TranslateTables() = %d tables, %d types, want 3 and 0", len(tables), len(types))
+	}
+	users, events, orders := tables[0], tables[1], tables[2]
+
+	wantCreate := "CREATE TABLE `users` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `email` varchar(255) NOT NULL COMMENT 'Login address',\n" +
+		"  `active` tinyint(1) NOT NULL DEFAULT 1,\n" +
+		"  `status` enum('sad','happy') DEFAULT 'happy',\n" +
+		"  `bio` longtext DEFAULT (''),\n" +
+		"  `settings` json DEFAULT ('{}'),\n" +
+		"  `tags` json DEFAULT ('[]'),\n" +
+		"  `external_id` char(36) NOT NULL DEFAULT (uuid()),\n" +
+		"  `created_at` datetime(0) DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  `balance` decimal(65,30),\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") DEFAULT CHARSET=utf8mb4 COMMENT='Application users'"
+	if users.CreateStmt != wantCreate {
+		t.Errorf("CreateStmt = %q, want %q", users.CreateStmt, wantCreate)
+	}
+	if users.Schema != "public" || users.Columns[0].Extra != "auto_increment" || users.Columns[6].SourceType != "text[]" {
+		t.Errorf("users = %+v, want the source schema, an auto_increment id and the source types", users)
+	}
+
+	var indexes []string
+	for _, ix := range users.Indexes {
+		indexes = append(indexes, ix.Definition)
+	}
+	wantIndexes := []string{
+		"ADD UNIQUE INDEX `users_email_key` (`email`)",
+		"ADD INDEX `users_bio_idx` (`bio`(191))",
+		"ADD INDEX `users_recent_idx` (`created_at` DESC)",
+		"ADD UNIQUE INDEX `users_bio_key` (`bio`(191))",
+	}
+	if !reflect.DeepEqual(indexes, wantIndexes) {
+		t.Errorf("indexes = %q, want %q", indexes, wantIndexes)
+	}
+
+	if !strings.Contains(events.CreateStmt, "`id` int NOT NULL AUTO_INCREMENT,") ||
+		!strings.Contains(events.CreateStmt, "`happened_at` datetime(6) NOT NULL,") {
+		t.Errorf("events CreateStmt = %q", events.CreateStmt)
+	}
+	if orders.IsPartitioned() || !strings.Contains(orders.CreateStmt, "PRIMARY KEY (`id`, `placed_at`)") {
+		t.Errorf("orders = %+v, want an unpartitioned table", orders)
+	}
+
+	wantFKs := []string{
+		"ALTER TABLE `events` ADD CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)",
+		"ALTER TABLE `orders` ADD CONSTRAINT `fk_user_2` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE",
+	}
+	if len(events.ForeignKeys) != 1 || len(orders.ForeignKeys) != 1 {
+		t.Fatalf("foreign keys = %+v and %+v, want one each", events.ForeignKeys, orders.ForeignKeys)
+	}
+	if got := []string{events.ForeignKeys[0].ConstraintStmt, orders.ForeignKeys[0].ConstraintStmt}; !reflect.DeepEqual(got, wantFKs) {
+		t.Errorf("foreign keys = %q, want %q", got, wantFKs)
+	}
+
+	var report []string
+	for _, c := range tr.LossyConversions() {
+		report = append(report, c.String())
+	}
+	for _, want := range []string{
+		"public.users.tags: text[] becomes a JSON array",
+		"public.users.created_at: timestamptz becomes DATETIME holding UTC values",
+		"public.users.balance: numeric without a precision",
+		"public.users: CHECK constraint users_balance_check isn't migrated",
+		"public.users.users_lower_email_idx: expression index",
+		"public.users.users_recent_idx: partial index covers every row",
+		"public.users.users_tags_idx: gin index",
+		"public.users.users_bio_key: uniqueness of bio is only enforced on its first 191 characters",
+		"audit.events.duration: interval is stored as text",
+		"audit.events.fk_user: ON DELETE SET DEFAULT becomes NO ACTION",
+		"public.orders: PARTITION BY RANGE (placed_at) is dropped",
+		"public.orders.fk_user: foreign key is renamed to fk_user_2",
+	} {
+		found := false
+		for _, r := range report {
+			found = found || strings.HasPrefix(r, want)
+		}
+		if !found {
+			t.Errorf("LossyConversions() = %q, missing %q", report, want)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresToMySQLTranslator_TableNameCollision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+	expectPostgresShopCatalog(mock)
+
+	tables := []TableSchema{
+		{Schema: "public", Name: "events"},
+		{Schema: "audit", Name: "events"},
+	}
+	_, _, err = NewPostgresToMySQLTranslator().TranslateTables(db, "shop", tables)
+	if err == nil || !strings.Contains(err.Error(), "public.events and audit.events") {
+		t.Errorf("TranslateTables() error = %v, want a collision between public.events and audit.events", err)
+	}
+}
+
+func TestPostgresToMySQLTranslator_ColumnDefault(t *testing.T) {
+	tests := []struct {
+		column    ColumnDef
+		mysqlType string
+		want      string
+		ok        bool
+	}{
+		{ColumnDef{DefaultValue: nullString("CURRENT_TIMESTAMP")}, "datetime(6)", "CURRENT_TIMESTAMP(6)", true},
+		{ColumnDef{DefaultValue: nullString("CURRENT_DATE")}, "date", "(CURRENT_DATE)", true},
+		{ColumnDef{DefaultValue: nullString("'-1'::integer")}, "int", "-1", true},
+		{ColumnDef{DefaultValue: nullString("1.5")}, "decimal(5,2)", "1.5", true},
+		{ColumnDef{DefaultValue: nullString(`'C:\temp'::character varying`)}, "varchar(20)", `'C:\\temp'`, true},
+		{ColumnDef{DefaultValue: nullString("'it''s'::text")}, "longtext", "('it''s')", true},
+		{ColumnDef{DefaultValue: nullString("'f'::boolean")}, "tinyint(1)", "0", true},
+		{ColumnDef{DefaultValue: nullString("NULL::character varying")}, "varchar(10)", "", false},
+		{ColumnDef{DefaultValue: nullString("'\\x00'::bytea")}, "longblob", "", false},
+		{ColumnDef{DefaultValue: nullString("(now() + '1 day'::interval)")}, "datetime(6)", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NewPostgresToMySQLTranslator().columnDefault("t.c", tt.column, tt.mysqlType)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("columnDefault(%q, %s) = %q, %v, want %q, %v", tt.column.DefaultValue.String, tt.mysqlType, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParsePostgresIndexDefinition(t *testing.T) {
+	method, parts, include, partial := parsePostgresIndexDefinition(
+		`CREATE UNIQUE INDEX "a""b" ON ONLY public.t USING hash ("Name" text_pattern_ops, created_at DESC NULLS LAST, lower(email)) INCLUDE (x) WHERE (deleted_at IS NULL)`)
+	if method != "hash" || !include || !partial {
+		t.Errorf("method, include, partial = %q, %v, %v, want hash, true, true", method, include, partial)
+	}
+	want := []pgKeyPart{
+		{column: "Name"},
+		{column: "created_at", descending: true},
+		{column: "lower", expression: true},
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Errorf("parts = %+v, want %+v", parts, want)
+	}
+}
+
+func TestPostgresToMySQLTranslator_TranslateView(t *testing.T) {
+	tr := NewPostgresToMySQLTranslator()
+	tr.schemas = map[string]bool{"public": true, "audit": true}
+
+	tests := []struct {
+		name    string
+		view    ViewDef
+		want    string
+		wantErr string
+	}{
+		{
+			name: "plain select",
+			view: ViewDef{Schema: "public", Name: "active_users", CreateStmt: "CREATE VIEW \"public\".\"active_users\" AS\n" +
+				" SELECT users.id,\n    (users.email)::text AS \"Email\",\n    'C:\\' AS path\n   FROM audit.events e\n     JOIN users ON users.id = e.user_id\n  WHERE users.active = true;"},
+			want: "CREATE VIEW `active_users` AS\n" +
+				"SELECT users.id,\n    (users.email) AS `Email`,\n    'C:\\\\' AS path\n   FROM events e\n     JOIN users ON users.id = e.user_id\n  WHERE users.active = true",
+		},
+		{
+			name:    "materialized",
+			view:    ViewDef{Name: "totals", Materialized: true},
+			wantErr: "materialized",
+		},
+		{
+			name:    "unsupported cast",
+			view:    ViewDef{Name: "recent", CreateStmt: "CREATE VIEW \"public\".\"recent\" AS\n SELECT (now() - '1 day'::interval) AS since;"},
+			wantErr: "cast to interval",
+		},
+		{
+			name:    "string concatenation",
+			view:    ViewDef{Name: "names", CreateStmt: "CREATE VIEW \"public\".\"names\" AS\n SELECT (first || last) AS name\n   FROM users;"},
+			wantErr: "operator |",
+		},
+		{
+			name:    "distinct on",
+			view:    ViewDef{Name: "latest", CreateStmt: "CREATE VIEW \"public\".\"latest\" AS\n SELECT DISTINCT ON (user_id) user_id\n   FROM events;"},
+			wantErr: "DISTINCT ON",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tr.TranslateView(tt.view)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("TranslateView() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TranslateView() error = %v", err)
+			}
+			if got.CreateStmt != tt.want {
+				t.Errorf("CreateStmt = %q, want %q", got.CreateStmt, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostgresArrayJSON(t *testing.T) {
+	tests := []struct {
+		literal, elementType string
+		want                 string
+	}{
+		{"{}", "text", "[]"},
+		{`{a,"b c","d\"e",NULL,"NULL"}`, "text", `["a","b c","d\"e",null,"NULL"]`},
+		{"{{1,2},{3,NULL}}", "integer", "[[1,2],[3,null]]"},
+		{"{1.5,NaN}", "numeric(5,2)", `[1.5,"NaN"]`},
+		{"{t,f}", "boolean", "[true,false]"},
+		{`{"{\"a\":1}"}`, "jsonb", `[{"a":1}]`},
+		{"[0:1]={x,y}", "character varying(5)", `["x","y"]`},
+		{"{<b>}", "text", `["<b>"]`},
+	}
+	for _, tt := range tests {
+		got, err := PostgresArrayJSON(tt.literal, tt.elementType)
+		if err != nil || got != tt.want {
+			t.Errorf("PostgresArrayJSON(%q, %q) = %q, %v, want %q", tt.literal, tt.elementType, got, err, tt.want)
+		}
+	}
+
+	if _, err := PostgresArrayJSON("{a,b", "text"); err == nil {
+		t.Error("PostgresArrayJSON() should reject an unterminated array")
+	}
+}
@@ -0,0 +1,234 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ExtractTypes extracts enum, domain, composite and range types in dependency order
+func (e *PostgresExtractor) ExtractTypes(db *sql.DB, database string) ([]TypeDef, error) {
+	var types []TypeDef
+
+	for _, extract := range []func(*sql.DB) ([]TypeDef, error){
+		e.extractEnums,
+		e.extractDomains,
+		e.extractComposites,
+		e.extractRanges,
+	} {
+		extracted, err := extract(db)
+		if err != nil {
+			return nil, err
+		}
+		for _, typ := range extracted {
+			if e.Filter.Allows(typ.Schema) {
+				types = append(types, typ)
+			}
+		}
+	}
+
+	return sortTypesByDependency(types), nil
+}
+
+// extractEnums extracts enum types with their labels in sort order
+func (e *PostgresExtractor) extractEnums(db *sql.DB) ([]TypeDef, error) {
+	rows, err := db.Query(`
+		SELECT n.nspname, t.typname,
+			string_agg(quote_literal(en.enumlabel), ', ' ORDER BY en.enumsortorder) as labels
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_enum en ON en.enumtypid = t.oid
+		WHERE ` + userSchemaCondition("n.nspname") + `
+			AND ` + notExtensionMember("pg_type", "t.oid") + `
+		GROUP BY n.nspname, t.typname
+		ORDER BY n.nspname, t.typname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enum types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []TypeDef
+	for rows.Next() {
+		typ := TypeDef{Kind: "enum"}
+		var labels string
+		if err := rows.Scan(&typ.Schema, &typ.Name, &labels); err != nil {
+			return nil, err
+		}
+		typ.CreateStmt = fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", quoteQualified(typ.Schema, typ.Name), labels)
+		types = append(types, typ)
+	}
+
+	return types, nil
+}
+
+// extractDomains extracts domains with their base type, default, NOT NULL and CHECK constraints
+func (e *PostgresExtractor) extractDomains(db *sql.DB) ([]TypeDef, error) {
+	rows, err := db.Query(`
+		SELECT n.nspname, t.typname,
+			format_type(t.typbasetype, t.typtypmod) as base_type,
+			bn.nspname || '.' || bt.typname as base_name,
+			COALESCE(t.typdefault, '') as default_value,
+			t.typnotnull,
+			COALESCE((
+				SELECT string_agg('CONSTRAINT ' || quote_ident(c.conname) || ' ' || pg_get_constraintdef(c.oid), ' ' ORDER BY c.conname)
+				FROM pg_constraint c
+				WHERE c.contypid = t.oid
+			), '') as constraints
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_type bt ON bt.oid = t.typbasetype
+		JOIN pg_namespace bn ON bn.oid = bt.typnamespace
+		WHERE t.typtype = 'd'
+			AND ` + userSchemaCondition("n.nspname") + `
+			AND ` + notExtensionMember("pg_type", "t.oid") + `
+		ORDER BY n.nspname, t.typname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	defer rows.Close()
+
+	var types []TypeDef
+	for rows.Next() {
+		typ := TypeDef{Kind: "domain"}
+		var baseType, baseName, defaultValue, constraints string
+		var notNull bool
+		if err := rows.Scan(&typ.Schema, &typ.Name, &baseType, &baseName, &defaultValue, &notNull, &constraints); err != nil {
+			return nil, err
+		}
+
+		stmt := fmt.Sprintf("CREATE DOMAIN %s AS %s", quoteQualified(typ.Schema, typ.Name), baseType)
+		if defaultValue != "" {
+			stmt += " DEFAULT " + defaultValue
+		}
+		if notNull {
+			stmt += " NOT NULL"
+		}
+		if constraints != "" {
+			stmt += " " + constraints
+		}
+		typ.CreateStmt = stmt
+		typ.Dependencies = []string{baseName}
+		types = append(types, typ)
+	}
+
+	return types, nil
+}
+
+// extractComposites extracts standalone composite types (not the row types of tables)
+func (e *PostgresExtractor) extractComposites(db *sql.DB) ([]TypeDef, error) {
+	rows, err := db.Query(`
+		SELECT n.nspname, t.typname,
+			string_agg(quote_ident(a.attname) || ' ' || format_type(a.atttypid, a.atttypmod), ', ' ORDER BY a.attnum) as attributes,
+			string_agg(an.nspname || '.' || at.typname, ',' ORDER BY a.attnum) as attribute_types
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_class c ON c.oid = t.typrelid AND c.relkind = 'c'
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+		JOIN pg_type at ON at.oid = a.atttypid
+		JOIN pg_namespace an ON an.oid = at.typnamespace
+		WHERE t.typtype = 'c'
+			AND ` + userSchemaCondition("n.nspname") + `
+			AND ` + notExtensionMember("pg_class", "c.oid") + `
+		GROUP BY n.nspname, t.typname
+		ORDER BY n.nspname, t.typname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list composite types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []TypeDef
+	for rows.Next() {
+		typ := TypeDef{Kind: "composite"}
+		var attributes, attributeTypes string
+		if err := rows.Scan(&typ.Schema, &typ.Name, &attributes, &attributeTypes); err != nil {
+			return nil, err
+		}
+		typ.CreateStmt = fmt.Sprintf("CREATE TYPE %s AS (%s)", quoteQualified(typ.Schema, typ.Name), attributes)
+		typ.Dependencies = strings.Split(attributeTypes, ",")
+		types = append(types, typ)
+	}
+
+	return types, nil
+}
+
+// extractRanges extracts range types and their subtype
+func (e *PostgresExtractor) extractRanges(db *sql.DB) ([]TypeDef, error) {
+	rows, err := db.Query(`
+		SELECT n.nspname, t.typname,
+			format_type(r.rngsubtype, NULL) as subtype,
+			sn.nspname || '.' || st.typname as subtype_name
+		FROM pg_range r
+		JOIN pg_type t ON t.oid = r.rngtypid
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_type st ON st.oid = r.rngsubtype
+		JOIN pg_namespace sn ON sn.oid = st.typnamespace
+		WHERE ` + userSchemaCondition("n.nspname") + `
+			AND ` + notExtensionMember("pg_type", "t.oid") + `
+		ORDER BY n.nspname, t.typname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list range types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []TypeDef
+	for rows.Next() {
+		typ := TypeDef{Kind: "range"}
+		var subtype, subtypeName string
+		if err := rows.Scan(&typ.Schema, &typ.Name, &subtype, &subtypeName); err != nil {
+			return nil, err
+		}
+		typ.CreateStmt = fmt.Sprintf("CREATE TYPE %s AS RANGE (SUBTYPE = %s)", quoteQualified(typ.Schema, typ.Name), subtype)
+		typ.Dependencies = []string{subtypeName}
+		types = append(types, typ)
+	}
+
+	return types, nil
+}
+
+// sortTypesByDependency sorts types so that the types they are built from come first
+// Dependencies on built-in or extension types are ignored
+func sortTypesByDependency(types []TypeDef) []TypeDef {
+	typeSet := make(map[string]bool)
+	for _, t := range types {
+		typeSet[t.QualifiedName()] = true
+	}
+
+	sorted := make([]TypeDef, 0, len(types))
+	added := make(map[string]bool)
+
+	for len(sorted) < len(types) {
+		progress := false
+		for _, t := range types {
+			if added[t.QualifiedName()] {
+				continue
+			}
+			canAdd := true
+			for _, dep := range t.Dependencies {
+				if typeSet[dep] && !added[dep] && dep != t.QualifiedName() {
+					canAdd = false
+					break
+				}
+			}
+			if canAdd {
+				sorted = append(sorted, t)
+				added[t.QualifiedName()] = true
+				progress = true
+			}
+		}
+		// Composite types can't form cycles, but guard against bad catalog data
+		if !progress {
+			for _, t := range types {
+				if !added[t.QualifiedName()] {
+					sorted = append(sorted, t)
+				}
+			}
+			break
+		}
+	}
+
+	return sorted
+}
@@ -0,0 +1,171 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectNoTypes(mock sqlmock.Sqlmock, skip string) {
+	if skip != "enum" {
+		mock.ExpectQuery("SELECT.*FROM pg_type t.*JOIN pg_enum").
+			WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "labels"}))
+	}
+	if skip != "domain" {
+		mock.ExpectQuery("SELECT.*format_type\\(t.typbasetype.*FROM pg_type t").
+			WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "base_type", "base_name", "default_value", "typnotnull", "constraints"}))
+	}
+	if skip != "composite" {
+		mock.ExpectQuery("SELECT.*FROM pg_type t.*relkind = 'c'").
+			WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "attributes", "attribute_types"}))
+	}
+	if skip != "range" {
+		mock.ExpectQuery("SELECT.*FROM pg_range r").
+			WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "subtype", "subtype_name"}))
+	}
+}
+
+func TestPostgresExtractor_ExtractTypes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	mock.ExpectQuery("SELECT.*FROM pg_type t.*JOIN pg_enum").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "labels"}).
+			AddRow("public", "order_status", "'pending', 'paid', 'shipped'"))
+	mock.ExpectQuery("SELECT.*format_type\\(t.typbasetype.*FROM pg_type t").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "base_type", "base_name", "default_value", "typnotnull", "constraints"}).
+			AddRow("public", "positive_price", "numeric(10,2)", "pg_catalog.numeric", "0", true, "CONSTRAINT positive_price_check CHECK ((VALUE >= (0)::numeric))"))
+	mock.ExpectQuery("SELECT.*FROM pg_type t.*relkind = 'c'").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "attributes", "attribute_types"}).
+			AddRow("public", "line_item", `sku text, price positive_price, status order_status`, "pg_catalog.text,public.positive_price,public.order_status"))
+	mock.ExpectQuery("SELECT.*FROM pg_range r").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "subtype", "subtype_name"}).
+			AddRow("public", "price_range", "positive_price", "public.positive_price"))
+
+	types, err := ext.ExtractTypes(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTypes() error = %v", err)
+	}
+	if len(types) != 4 {
+		t.Fatalf("ExtractTypes() returned %d types, want 4", len(types))
+	}
+
+	want := map[string]string{
+		"order_status":   `CREATE TYPE "public"."order_status" AS ENUM ('pending', 'paid', 'shipped')`,
+		"positive_price": `CREATE DOMAIN "public"."positive_price" AS numeric(10,2) DEFAULT 0 NOT NULL CONSTRAINT positive_price_check CHECK ((VALUE >= (0)::numeric))`,
+		"line_item":      `CREATE TYPE "public"."line_item" AS (sku text, price positive_price, status order_status)`,
+		"price_range":    `CREATE TYPE "public"."price_range" AS RANGE (SUBTYPE = positive_price)`,
+	}
+	position := make(map[string]int)
+	for i, typ := range types {
+		position[typ.Name] = i
+		if typ.CreateStmt != want[typ.Name] {
+			t.Errorf("%s CreateStmt = %q, want %q", typ.Name, typ.CreateStmt, want[typ.Name])
+		}
+	}
+
+	// line_item is built from both the domain and the enum
+	if position["line_item"] < position["positive_price"] || position["line_item"] < position["order_status"] {
+		t.Errorf("line_item created before its attribute types: %v", position)
+	}
+	if position["price_range"] < position["positive_price"] {
+		t.Errorf("price_range created before its subtype: %v", position)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestPostgresExtractor_ExtractTypes_SchemaFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+	ext.SetSchemaFilter(SchemaFilter{Exclude: []string{"scratch"}})
+
+	mock.ExpectQuery("SELECT.*FROM pg_type t.*JOIN pg_enum").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "labels"}).
+			AddRow("public", "mood", "'happy'").
+			AddRow("scratch", "tmp_mood", "'sad'"))
+	expectNoTypes(mock, "enum")
+
+	types, err := ext.ExtractTypes(db, "testdb")
+	if err != nil {
+		t.Fatalf("ExtractTypes() error = %v", err)
+	}
+	if len(types) != 1 || types[0].QualifiedName() != "public.mood" {
+		t.Errorf("ExtractTypes() = %+v, want only public.mood", types)
+	}
+}
+
+func TestPostgresExtractor_ExtractTypes_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	ext := NewPostgresExtractor()
+
+	mock.ExpectQuery("SELECT.*FROM pg_type t.*JOIN pg_enum").
+		WillReturnError(sqlmock.ErrCancelled)
+
+	if _, err := ext.ExtractTypes(db, "testdb"); err == nil {
+		t.Errorf("ExtractTypes() expected error, got nil")
+	}
+}
+
+func TestSortTypesByDependency(t *testing.T) {
+	types := []TypeDef{
+		{Schema: "public", Name: "outer", Dependencies: []string{"public.inner", "pg_catalog.text"}},
+		{Schema: "public", Name: "inner", Dependencies: []string{"public.base"}},
+		{Schema: "public", Name: "base", Dependencies: []string{"pg_catalog.int4"}},
+	}
+
+	sorted := sortTypesByDependency(types)
+	if len(sorted) != 3 {
+		t.Fatalf("sortTypesByDependency() returned %d types, want 3", len(sorted))
+	}
+	for i, want := range []string{"base", "inner", "outer"} {
+		if sorted[i].Name != want {
+			t.Errorf("sorted[%d] = %q, want %q", i, sorted[i].Name, want)
+		}
+	}
+}
+
+func TestBaseApplier_CreateType(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	app := NewPostgresApplier()
+	typ := TypeDef{
+		Schema:     "public",
+		Name:       "mood",
+		Kind:       "enum",
+		CreateStmt: `CREATE TYPE "public"."mood" AS ENUM ('happy', 'sad')`,
+	}
+
+	mock.ExpectExec(`CREATE TYPE "public"."mood" AS ENUM`).
+		WillReturnError(sqlmock.ErrCancelled)
+
+	err = app.CreateType(db, typ)
+	if err == nil {
+		t.Fatalf("CreateType() expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "failed to create enum type public.mood") {
+		t.Errorf("CreateType() error = %q, want it to name the type", err)
+	}
+}
@@ -0,0 +1,359 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Statement kinds a rewrite rule can apply to
+const (
+	RuleOnTable      = "table"       // CREATE TABLE statements
+	RuleOnIndex      = "index"       // CREATE INDEX statements and MySQL ADD INDEX clauses
+	RuleOnForeignKey = "foreign_key" // ALTER TABLE ... ADD CONSTRAINT ... FOREIGN KEY statements
+	RuleOnView       = "view"        // CREATE VIEW and CREATE MATERIALIZED VIEW statements
+)
+
+// RewriteRule is one declarative rewrite of the DDL sent to the destination
+// The match is a case-insensitive regular expression; every match is replaced, or removed along
+// with nothing else, so a rule that removes a clause should match the space in front of it too.
+type RewriteRule struct {
+	Name       string   `json:"name"`
+	On         []string `json:"on"`          // Statement kinds the rule applies to; all of them when empty
+	Table      string   `json:"table"`       // Glob matched against the table or view name; every table when empty
+	Column     string   `json:"column"`      // Glob matched against column names; rewrites column definitions only
+	ColumnType string   `json:"column_type"` // Glob matched against column types such as varchar(255); rewrites column definitions only
+	Match      string   `json:"match"`
+	Replace    string   `json:"replace"` // Replacement text, which can refer to groups as $1 or ${name}
+	Remove     bool     `json:"remove"`  // Remove what the rule matches
+
+	match *regexp.Regexp
+	on    map[string]bool
+}
+
+// rewritesColumns reports whether the rule rewrites column definitions rather than whole statements
+func (r *RewriteRule) rewritesColumns() bool {
+	return r.Column != "" || r.ColumnType != ""
+}
+
+// appliesTo reports whether the rule applies to a kind of statement on the named table or view
+func (r *RewriteRule) appliesTo(kind, schemaName, name string) bool {
+	if len(r.on) > 0 && !r.on[kind] {
+		return false
+	}
+	if r.Table == "" {
+		return true
+	}
+	if ok, _ := path.Match(r.Table, name); ok {
+		return true
+	}
+	ok, _ := path.Match(r.Table, qualifiedName(schemaName, name))
+	return ok
+}
+
+// rewrite applies the rule to text
+func (r *RewriteRule) rewrite(text string) string {
+	return r.match.ReplaceAllString(text, r.Replace)
+}
+
+// RuleChange is a statement that rewrite rules changed
+type RuleChange struct {
+	Object string   // Table, index, foreign key or view the statement creates
+	Rules  []string // Names of the rules that changed it
+	Before string
+	After  string
+}
+
+// Diff returns the lines of the statement before and after, with removed lines prefixed "- ",
+// added lines "+ " and unchanged ones "  "
+func (c RuleChange) Diff() []string {
+	return lineDiff(strings.Split(c.Before, "\n"), strings.Split(c.After, "\n"))
+}
+
+// RuleSet rewrites tables, indexes, foreign keys and views with declarative rules before they're
+// created on the destination, recording every statement it changes
+type RuleSet struct {
+	rules   []RewriteRule
+	syntax  sqlSyntax
+	changes []RuleChange
+}
+
+// rulesFile is the layout of a rules file
+type rulesFile struct {
+	Rules []RewriteRule `json:"rules"`
+}
+
+// LoadRewriteRules reads a JSON rules file, or returns nil when path is empty
+func LoadRewriteRules(path string) (*RuleSet, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	rules, err := ParseRewriteRules(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseRewriteRules validates and compiles the rules in a JSON rules file
+func ParseRewriteRules(content []byte) (*RuleSet, error) {
+	var file rulesFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+
+	set := &RuleSet{syntax: mysqlSyntax("")}
+	for i, r := range file.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if r.Match == "" {
+			return nil, fmt.Errorf("%s: match is required", r.Name)
+		}
+		if r.Remove == (r.Replace != "") {
+			return nil, fmt.Errorf("%s: set either replace or remove", r.Name)
+		}
+		match, err := regexp.Compile("(?i)" + r.Match)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		r.match = match
+
+		for _, glob := range []string{r.Table, r.Column, r.ColumnType} {
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("%s: invalid pattern %q", r.Name, glob)
+			}
+		}
+
+		if len(r.On) > 0 {
+			r.on = make(map[string]bool, len(r.On))
+		}
+		for _, kind := range r.On {
+			switch kind {
+			case RuleOnTable, RuleOnIndex, RuleOnForeignKey, RuleOnView:
+				r.on[kind] = true
+			default:
+				return nil, fmt.Errorf("%s: unknown statement kind %q: use table, index, foreign_key or view", r.Name, kind)
+			}
+		}
+		if r.rewritesColumns() && (len(r.on) > 1 || len(r.on) == 1 && !r.on[RuleOnTable]) {
+			return nil, fmt.Errorf("%s: column and column_type rules only apply to tables", r.Name)
+		}
+		set.rules = append(set.rules, r)
+	}
+	return set, nil
+}
+
+// ForEngine sets the engine whose DDL the rules rewrite, for finding column definitions
+func (s *RuleSet) ForEngine(engine string) *RuleSet {
+	if engine == "pgsql" {
+		s.syntax = postgresSyntax
+	} else {
+		s.syntax = mysqlSyntax("")
+	}
+	return s
+}
+
+// Changes lists the statements the rules changed, in the order they were rewritten
+func (s *RuleSet) Changes() []RuleChange {
+	return s.changes
+}
+
+// RewriteTable applies the rules to a table's CREATE TABLE statement and to its indexes and foreign keys
+func (s *RuleSet) RewriteTable(table TableSchema) (TableSchema, error) {
+	name := table.QualifiedName()
+	stmt, err := s.rewriteCreateTable(table)
+	if err != nil {
+		return table, err
+	}
+	table.CreateStmt = stmt
+
+	// Copies, so the rewrites don't reach the extracted table's slices
+	if len(table.Indexes) > 0 {
+		indexes := make([]IndexDef, len(table.Indexes))
+		for i, ix := range table.Indexes {
+			// MySQL indexes carry both forms of the same index; the diff shows the CREATE INDEX one
+			object := fmt.Sprintf("index %s on %s", ix.Name, name)
+			createStmt, applied := s.apply(RuleOnIndex, table.Schema, table.Name, ix.CreateStmt)
+			s.record(object, applied, ix.CreateStmt, createStmt)
+			definition, applied := s.apply(RuleOnIndex, table.Schema, table.Name, ix.Definition)
+			if ix.CreateStmt == "" {
+				s.record(object, applied, ix.Definition, definition)
+			}
+			ix.CreateStmt, ix.Definition = createStmt, definition
+			indexes[i] = ix
+		}
+		table.Indexes = indexes
+	}
+	if len(table.ForeignKeys) > 0 {
+		foreignKeys := make([]ForeignKeyDef, len(table.ForeignKeys))
+		for i, fk := range table.ForeignKeys {
+			object := fmt.Sprintf("foreign key %s on %s", fk.Name, name)
+			fk.ConstraintStmt = s.rewrite(RuleOnForeignKey, table.Schema, table.Name, object, fk.ConstraintStmt)
+			foreignKeys[i] = fk
+		}
+		table.ForeignKeys = foreignKeys
+	}
+	return table, nil
+}
+
+// RewriteView applies the rules to a view's definition and, for materialized views, its indexes
+func (s *RuleSet) RewriteView(view ViewDef) ViewDef {
+	name := view.QualifiedName()
+	view.CreateStmt = s.rewrite(RuleOnView, view.Schema, view.Name, "view "+name, view.CreateStmt)
+	if len(view.Indexes) > 0 {
+		indexes := make([]IndexDef, len(view.Indexes))
+		for i, ix := range view.Indexes {
+			ix.CreateStmt = s.rewrite(RuleOnIndex, view.Schema, view.Name, fmt.Sprintf("index %s on %s", ix.Name, name), ix.CreateStmt)
+			indexes[i] = ix
+		}
+		view.Indexes = indexes
+	}
+	return view
+}
+
+// rewrite applies the statement rules for a kind of statement, recording the change if there is one
+func (s *RuleSet) rewrite(kind, schemaName, name, object, stmt string) string {
+	after, applied := s.apply(kind, schemaName, name, stmt)
+	s.record(object, applied, stmt, after)
+	return after
+}
+
+// apply applies the statement rules for a kind of statement, returning the names of those that changed it
+func (s *RuleSet) apply(kind, schemaName, name, stmt string) (string, []string) {
+	var applied []string
+	for i := range s.rules {
+		r := &s.rules[i]
+		if stmt == "" || r.rewritesColumns() || !r.appliesTo(kind, schemaName, name) {
+			continue
+		}
+		if rewritten := r.rewrite(stmt); rewritten != stmt {
+			stmt = rewritten
+			applied = appendOnce(applied, r.Name)
+		}
+	}
+	return stmt, applied
+}
+
+// record adds a change when rules changed a statement
+func (s *RuleSet) record(object string, rules []string, before, after string) {
+	if len(rules) > 0 {
+		s.changes = append(s.changes, RuleChange{Object: object, Rules: rules, Before: before, After: after})
+	}
+}
+
+// rewriteCreateTable applies the column rules to matching column definitions, then the
+// statement rules to the whole CREATE TABLE statement
+func (s *RuleSet) rewriteCreateTable(table TableSchema) (string, error) {
+	stmt := table.CreateStmt
+	var columnRules []*RewriteRule
+	for i := range s.rules {
+		if r := &s.rules[i]; r.rewritesColumns() && r.appliesTo(RuleOnTable, table.Schema, table.Name) {
+			columnRules = append(columnRules, r)
+		}
+	}
+
+	after := stmt
+	var applied []string
+	if len(columnRules) > 0 {
+		ddl, err := parseCreateTable(stmt, s.syntax)
+		if err != nil {
+			return stmt, fmt.Errorf("failed to parse CREATE TABLE %s: %w", table.QualifiedName(), err)
+		}
+		for i, c := range ddl.Clauses {
+			if c.Kind != clauseColumn || len(c.tokens) < 2 {
+				continue
+			}
+			columnType := strings.ToLower(columnTypeText(c))
+			for _, r := range columnRules {
+				if !globMatch(r.Column, c.Name) || !globMatch(strings.ToLower(r.ColumnType), columnType) {
+					continue
+				}
+				if rewritten := r.rewrite(ddl.Clauses[i].Text); rewritten != ddl.Clauses[i].Text {
+					ddl.Clauses[i].Text = rewritten
+					applied = appendOnce(applied, r.Name)
+				}
+			}
+		}
+		after = ddl.String()
+	}
+
+	after, statementRules := s.apply(RuleOnTable, table.Schema, table.Name, after)
+	for _, name := range statementRules {
+		applied = appendOnce(applied, name)
+	}
+	s.record("table "+table.QualifiedName(), applied, stmt, after)
+	return after, nil
+}
+
+// columnTypeText returns a column definition's type name with its length or precision, e.g. varchar(255)
+func columnTypeText(c ddlClause) string {
+	tokens := c.tokens
+	end := 1
+	if len(tokens) > 2 && adjacent(tokens, 1) && tokens[2].isPunct(c.Text, '(') {
+		end = closingParen(c.Text, tokens, 2)
+	}
+	return c.Text[tokens[1].Start:tokens[end].End]
+}
+
+// globMatch reports whether name matches pattern; an empty pattern matches everything
+func globMatch(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// appendOnce appends name to names unless it's already there
+func appendOnce(names []string, name string) []string {
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
+
+// lineDiff returns a line diff of two texts from their longest common subsequence of lines
+func lineDiff(before, after []string) []string {
+	// common[i][j] is the length of the longest common subsequence of before[i:] and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			diff = append(diff, "  "+before[i])
+			i++
+			j++
+		case j == len(after) || i < len(before) && common[i+1][j] >= common[i][j+1]:
+			diff = append(diff, "- "+before[i])
+			i++
+		default:
+			diff = append(diff, "+ "+after[j])
+			j++
+		}
+	}
+	return diff
+}
@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testRules = `{
+  "rules": [
+    {"name": "innodb", "on": ["table"], "match": "ENGINE=MyISAM", "replace": "ENGINE=InnoDB"},
+    {"name": "no-compression", "on": ["table"], "match": "\\s*ROW_FORMAT=COMPRESSED", "remove": true},
+    {"name": "no-on-update", "column_type": "timestamp*", "match": "\\s*ON UPDATE CURRENT_TIMESTAMP(\\(\\d\\))?", "remove": true},
+    {"name": "cascade", "on": ["foreign_key"], "table": "order_*", "match": "ON DELETE RESTRICT", "replace": "ON DELETE CASCADE"},
+    {"name": "btree", "on": ["index"], "match": "\\s*USING HASH", "remove": true},
+    {"name": "no-algorithm", "on": ["view"], "match": "ALGORITHM=\\w+ ", "remove": true}
+  ]
+}`
+
+func TestParseRewriteRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"no match", `{"rules": [{"name": "r", "replace": "x"}]}`},
+		{"replace and remove", `{"rules": [{"name": "r", "match": "x", "replace": "y", "remove": true}]}`},
+		{"neither", `{"rules": [{"name": "r", "match": "x"}]}`},
+		{"bad regexp", `{"rules": [{"name": "r", "match": "(", "remove": true}]}`},
+		{"bad glob", `{"rules": [{"name": "r", "table": "[", "match": "x", "remove": true}]}`},
+		{"unknown kind", `{"rules": [{"name": "r", "on": ["trigger"], "match": "x", "remove": true}]}`},
+		{"column rule on views", `{"rules": [{"name": "r", "on": ["view"], "column": "a", "match": "x", "remove": true}]}`},
+		{"not json", `rules:`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRewriteRules([]byte(tt.content)); err == nil {
+				t.Error("ParseRewriteRules() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestRuleSet_RewriteTable(t *testing.T) {
+	rules, err := ParseRewriteRules([]byte(testRules))
+	if err != nil {
+		t.Fatalf("ParseRewriteRules() error: %v", err)
+	}
+
+	table := TableSchema{
+		Name: "order_items",
+		CreateStmt: "CREATE TABLE `order_items` (\n" +
+			"  `id` int NOT NULL,\n" +
+			"  `note` varchar(50) DEFAULT 'on update current_timestamp',\n" +
+			"  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+			"  PRIMARY KEY (`id`)\n" +
+			") ENGINE=MyISAM DEFAULT CHARSET=utf8mb4 ROW_FORMAT=COMPRESSED",
+		Indexes: []IndexDef{
+			{Name: "order_items_note_index", Definition: "ADD INDEX `order_items_note_index` (`note`) USING HASH"},
+		},
+		ForeignKeys: []ForeignKeyDef{
+			{Name: "order_items_order_id_foreign", ConstraintStmt: "ALTER TABLE `order_items` ADD CONSTRAINT `order_items_order_id_foreign` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`) ON DELETE RESTRICT"},
+		},
+	}
+
+	got, err := rules.ForEngine("mysql").RewriteTable(table)
+	if err != nil {
+		t.Fatalf("RewriteTable() error: %v", err)
+	}
+
+	want := "CREATE TABLE `order_items` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `note` varchar(50) DEFAULT 'on update current_timestamp',\n" +
+		"  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+	if got.CreateStmt != want {
+		t.Errorf("CreateStmt =\n%s\nwant\n%s", got.CreateStmt, want)
+	}
+	if want := "ADD INDEX `order_items_note_index` (`note`)"; got.Indexes[0].Definition != want {
+		t.Errorf("Indexes[0].Definition = %q, want %q", got.Indexes[0].Definition, want)
+	}
+	if !strings.HasSuffix(got.ForeignKeys[0].ConstraintStmt, "ON DELETE CASCADE") {
+		t.Errorf("ForeignKeys[0].ConstraintStmt = %q, want ON DELETE CASCADE", got.ForeignKeys[0].ConstraintStmt)
+	}
+	if table.Indexes[0].Definition == got.Indexes[0].Definition {
+		t.Error("RewriteTable() changed the original table's indexes")
+	}
+
+	changes := rules.Changes()
+	if len(changes) != 3 {
+		t.Fatalf("Changes() = %d changes, want 3", len(changes))
+	}
+	if want := []string{"no-on-update", "innodb", "no-compression"}; !reflect.DeepEqual(changes[0].Rules, want) {
+		t.Errorf("Changes()[0].Rules = %v, want %v", changes[0].Rules, want)
+	}
+	if changes[1].Object != "index order_items_note_index on order_items" {
+		t.Errorf("Changes()[1].Object = %q", changes[1].Object)
+	}
+}
+
+func TestRuleSet_RewriteTable_OtherTables(t *testing.T) {
+	rules, err := ParseRewriteRules([]byte(testRules))
+	if err != nil {
+		t.Fatalf("ParseRewriteRules() error: %v", err)
+	}
+	table := TableSchema{
+		Name:        "users",
+		CreateStmt:  "CREATE TABLE `users` (\n  `id` int NOT NULL\n) ENGINE=InnoDB",
+		ForeignKeys: []ForeignKeyDef{{Name: "fk", ConstraintStmt: "ALTER TABLE `users` ADD CONSTRAINT `fk` FOREIGN KEY (`team_id`) REFERENCES `teams` (`id`) ON DELETE RESTRICT"}},
+	}
+	got, err := rules.RewriteTable(table)
+	if err != nil {
+		t.Fatalf("RewriteTable() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, table) || len(rules.Changes()) != 0 {
+		t.Errorf("RewriteTable() = %+v with changes %v, want the table unchanged", got, rules.Changes())
+	}
+}
+
+func TestRuleSet_RewriteView(t *testing.T) {
+	rules, err := ParseRewriteRules([]byte(testRules))
+	if err != nil {
+		t.Fatalf("ParseRewriteRules() error: %v", err)
+	}
+	view := ViewDef{Name: "active_users", CreateStmt: "CREATE ALGORITHM=UNDEFINED VIEW `active_users` AS select 1"}
+	got := rules.RewriteView(view)
+	if want := "CREATE VIEW `active_users` AS select 1"; got.CreateStmt != want {
+		t.Errorf("RewriteView() = %q, want %q", got.CreateStmt, want)
+	}
+	if len(rules.Changes()) != 1 || rules.Changes()[0].Object != "view active_users" {
+		t.Errorf("Changes() = %v, want the view", rules.Changes())
+	}
+}
+
+func TestRuleChange_Diff(t *testing.T) {
+	c := RuleChange{
+		Before: "CREATE TABLE `t` (\n  `id` int\n) ENGINE=MyISAM",
+		After:  "CREATE TABLE `t` (\n  `id` int\n) ENGINE=InnoDB",
+	}
+	want := []string{
+		"  CREATE TABLE `t` (",
+		"    `id` int",
+		"- ) ENGINE=MyISAM",
+		"+ ) ENGINE=InnoDB",
+	}
+	if got := c.Diff(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadRewriteRules_NoFile(t *testing.T) {
+	rules, err := LoadRewriteRules("")
+	if rules != nil || err != nil {
+		t.Errorf("LoadRewriteRules(\"\") = %v, %v, want nil, nil", rules, err)
+	}
+	if _, err := LoadRewriteRules("testdata/missing.json"); err == nil {
+		t.Error("LoadRewriteRules() of a missing file should fail")
+	}
+}
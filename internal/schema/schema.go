@@ -3,18 +3,48 @@ package schema
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/DGarbs51/lcmigrate/internal/dialect"
 )
 
 // TableSchema represents the schema of a database table
 type TableSchema struct {
-	Name        string
-	CreateStmt  string // Full CREATE TABLE statement
-	Columns     []ColumnDef
-	PrimaryKey  []string
-	Indexes     []IndexDef
-	ForeignKeys []ForeignKeyDef
+	Schema          string // PostgreSQL schema (namespace), empty for MySQL and SQLite
+	Name            string
+	CreateStmt      string   // Full CREATE TABLE statement
+	PostCreateStmts []string // Statements run right after CREATE TABLE (storage, comments)
+	Columns         []ColumnDef
+	PrimaryKey      []string
+	Indexes         []IndexDef
+	ForeignKeys     []ForeignKeyDef
+	PartitionKey    string   // PARTITION BY clause of a partitioned parent, e.g. "RANGE (created_at)" (PostgreSQL)
+	PartitionOf     string   // Schema-qualified parent of a partition (PostgreSQL)
+	PartitionBound  string   // FOR VALUES ... or DEFAULT for a partition (PostgreSQL)
+	Inherits        []string // Schema-qualified parents under legacy inheritance (PostgreSQL)
+	HasChildren     bool     // Has legacy inheritance children, so its own rows are read with ONLY (PostgreSQL)
+
+	Partitions          []PartitionDef // Partitions of a partitioned table, copied one at a time (MySQL)
+	PartitioningDropped bool           // The destination table is created without partitioning (MySQL)
+
+	SourceName     string            // Name on the source, when the migration renames the table
+	ColumnRenames  map[string]string // Source column names to their destination names, for renamed columns
+	OmittedColumns []string          // Source columns the destination table leaves out
+
+	SchemaOnly bool // Created without its rows (--schema-only-tables)
+}
+
+// IsPartitioned reports whether the table is a partitioned parent, which holds no rows itself
+func (t TableSchema) IsPartitioned() bool {
+	return t.PartitionKey != ""
+}
+
+// Parents returns the schema-qualified tables this table is a partition or child of
+func (t TableSchema) Parents() []string {
+	if t.PartitionOf != "" {
+		return []string{t.PartitionOf}
+	}
+	return t.Inherits
 }
 
 // ColumnDef represents a column definition
@@ -23,22 +53,32 @@ type ColumnDef struct {
 	DataType     string
 	IsNullable   bool
 	DefaultValue sql.NullString
-	Extra        string // AUTO_INCREMENT, etc.
+	Extra        string // AUTO_INCREMENT, STORED GENERATED, ROW START (MariaDB), etc.
+	SourceType   string // Column type on the source engine, for tables translated from another engine
+}
+
+// IsGenerated reports whether the column's value is computed by the database, including the
+// row start and end columns of a MariaDB system-versioned table
+func (c ColumnDef) IsGenerated() bool {
+	return strings.Contains(c.Extra, "GENERATED") || c.Extra == "ROW START" || c.Extra == "ROW END"
 }
 
 // IndexDef represents an index definition
 type IndexDef struct {
-	Name       string
-	Columns    []string
-	IsUnique   bool
-	IsPrimary  bool
-	CreateStmt string // Full CREATE INDEX statement
+	Name         string
+	Columns      []string
+	IsUnique     bool
+	IsPrimary    bool
+	IsConstraint bool   // Created with ALTER TABLE ADD CONSTRAINT (UNIQUE, EXCLUDE, NOT VALID CHECK)
+	CreateStmt   string // Full CREATE INDEX or ALTER TABLE statement
+	Definition   string // ADD clause for creating several indexes in one ALTER TABLE (MySQL)
 }
 
 // ForeignKeyDef represents a foreign key constraint
 type ForeignKeyDef struct {
 	Name           string
 	Columns        []string
+	RefSchema      string
 	RefTable       string
 	RefColumns     []string
 	OnDelete       string
@@ -46,60 +86,199 @@ type ForeignKeyDef struct {
 	ConstraintStmt string // Full ALTER TABLE ADD CONSTRAINT statement
 }
 
-// ViewDef represents a view definition
+// ViewDef represents a view or materialized view definition
 type ViewDef struct {
+	Schema       string
 	Name         string
 	CreateStmt   string
-	Dependencies []string // Other views this view depends on
+	Dependencies []string   // Other views this view depends on (schema-qualified on PostgreSQL)
+	Materialized bool       // Materialized view, created WITH NO DATA (PostgreSQL)
+	Populated    bool       // Materialized view held data on the source, so it's refreshed at the end
+	Indexes      []IndexDef // Indexes on a materialized view
 }
 
 // SequenceDef represents a sequence (PostgreSQL)
 type SequenceDef struct {
+	Schema      string
+	Name        string
+	CreateStmt  string // CREATE SEQUENCE with the source's type, bounds, increment, cache and cycle options
+	CurrentVal  int64
+	IsCalled    bool   // Whether CurrentVal has been handed out; false means nextval returns CurrentVal itself
+	OwnedBy     string // schema.table.column that owns this sequence
+	OwnedByStmt string // ALTER SEQUENCE ... OWNED BY, run once the owning table exists
+	Identity    bool   // Backs an identity column, so CREATE TABLE creates it
+}
+
+// ExtensionDef represents an installed extension (PostgreSQL)
+type ExtensionDef struct {
 	Name       string
+	Schema     string // Schema the extension's objects are installed into
+	Version    string
 	CreateStmt string
-	CurrentVal int64
-	OwnedBy    string // table.column that owns this sequence
+}
+
+// TypeDef represents a user-defined type (PostgreSQL enum, domain, composite or range)
+type TypeDef struct {
+	Schema       string
+	Name         string
+	Kind         string // "enum", "domain", "composite" or "range"
+	CreateStmt   string
+	Dependencies []string // Schema-qualified types this type is built from
+}
+
+// QualifiedName returns the type name prefixed with its schema, if any
+func (t TypeDef) QualifiedName() string {
+	return qualifiedName(t.Schema, t.Name)
+}
+
+// RoutineDef represents a stored function or procedure
+type RoutineDef struct {
+	Schema     string
+	Name       string
+	Kind       string // "function", "procedure" or "event" (MySQL)
+	Arguments  string // Identity argument list, e.g. "integer, text" (PostgreSQL)
+	SQLMode    string // sql_mode the routine was defined under (MySQL)
+	CreateStmt string
+	Disabled   bool // Event the source runs, created DISABLE on the destination (MySQL)
+}
+
+// Signature returns the qualified routine name with its argument list, identifying overloads
+func (r RoutineDef) Signature() string {
+	if r.Kind == "event" {
+		return qualifiedName(r.Schema, r.Name)
+	}
+	return fmt.Sprintf("%s(%s)", qualifiedName(r.Schema, r.Name), r.Arguments)
+}
+
+// TriggerDef represents a table trigger
+type TriggerDef struct {
+	Schema     string
+	Table      string
+	Name       string
+	SQLMode    string // sql_mode the trigger was defined under (MySQL)
+	CreateStmt string
+}
+
+// QualifiedName returns the table name prefixed with its schema, if any
+func (t TableSchema) QualifiedName() string {
+	return qualifiedName(t.Schema, t.Name)
+}
+
+// SourceTableName returns the table's name on the source
+func (t TableSchema) SourceTableName() string {
+	if t.SourceName != "" {
+		return t.SourceName
+	}
+	return t.Name
+}
+
+// SourceQualifiedName returns the table's name on the source prefixed with its schema, if any
+func (t TableSchema) SourceQualifiedName() string {
+	return qualifiedName(t.Schema, t.SourceTableName())
+}
+
+// DisplayName returns the table's qualified name for messages, followed by its name on the
+// source when the migration renames it
+func (t TableSchema) DisplayName() string {
+	if t.SourceName == "" || t.SourceName == t.Name {
+		return t.QualifiedName()
+	}
+	return fmt.Sprintf("%s (%s on the source)", t.QualifiedName(), t.SourceQualifiedName())
+}
+
+// DestinationColumn returns the destination name of a source column
+func (t TableSchema) DestinationColumn(column string) string {
+	if to, ok := t.ColumnRenames[column]; ok {
+		return to
+	}
+	return column
+}
+
+// IsOmitted reports whether a source column is left out of the destination table
+func (t TableSchema) IsOmitted(column string) bool {
+	for _, c := range t.OmittedColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// QualifiedName returns the view name prefixed with its schema, if any
+func (v ViewDef) QualifiedName() string {
+	return qualifiedName(v.Schema, v.Name)
+}
+
+// QualifiedName returns the sequence name prefixed with its schema, if any
+func (s SequenceDef) QualifiedName() string {
+	return qualifiedName(s.Schema, s.Name)
+}
+
+// qualifiedName joins a schema and object name with a dot (unquoted, for display and lookups)
+func qualifiedName(schema, name string) string {
+	if schema == "" {
+		return name
+	}
+	return schema + "." + name
+}
+
+// SchemaFilter restricts which schemas (namespaces) are migrated
+type SchemaFilter struct {
+	Include []string // Only these schemas (all non-system schemas when empty)
+	Exclude []string // Never these schemas
+}
+
+// Allows reports whether the named schema passes the filter
+func (f SchemaFilter) Allows(name string) bool {
+	for _, s := range f.Exclude {
+		if s == name {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, s := range f.Include {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaFilterer is implemented by extractors that support multiple schemas
+type SchemaFilterer interface {
+	SetSchemaFilter(filter SchemaFilter)
 }
 
 // Extractor defines the interface for extracting schema information
 type Extractor interface {
+	ExtractSchemas(db *sql.DB, database string) ([]string, error)
+	ExtractExtensions(db *sql.DB, database string) ([]ExtensionDef, error)
+	ExtractTypes(db *sql.DB, database string) ([]TypeDef, error)
 	ExtractTables(db *sql.DB, database string) ([]TableSchema, error)
 	ExtractViews(db *sql.DB, database string) ([]ViewDef, error)
 	ExtractSequences(db *sql.DB, database string) ([]SequenceDef, error)
+	ExtractRoutines(db *sql.DB, database string) ([]RoutineDef, error)
+	ExtractTriggers(db *sql.DB, database string) ([]TriggerDef, error)
 }
 
 // Applier defines the interface for applying schema to a database
 type Applier interface {
+	CreateSchema(db *sql.DB, name string) error
+	CreateExtension(db *sql.DB, ext ExtensionDef) error
+	CreateType(db *sql.DB, typ TypeDef) error
 	CreateTable(db *sql.DB, table TableSchema) error
 	CreateIndex(db *sql.DB, index IndexDef) error
+	CreateTableIndexes(db *sql.DB, table TableSchema) error
 	CreateForeignKey(db *sql.DB, fk ForeignKeyDef) error
 	CreateView(db *sql.DB, view ViewDef) error
+	RefreshMaterializedView(db *sql.DB, view ViewDef) error
 	CreateSequence(db *sql.DB, seq SequenceDef) error
+	SetSequenceOwner(db *sql.DB, seq SequenceDef) error
 	SetSequenceValue(db *sql.DB, seq SequenceDef) error
-}
-
-// NewExtractor creates a schema extractor for the given engine
-func NewExtractor(engine string) Extractor {
-	switch engine {
-	case "mysql":
-		return NewMySQLExtractor()
-	case "pgsql":
-		return NewPostgresExtractor()
-	default:
-		return nil
-	}
-}
-
-// NewApplier creates a schema applier for the given engine
-func NewApplier(engine string) Applier {
-	switch engine {
-	case "mysql":
-		return NewMySQLApplier()
-	case "pgsql":
-		return NewPostgresApplier()
-	default:
-		return nil
-	}
+	CreateRoutine(db *sql.DB, routine RoutineDef) error
+	CreateTrigger(db *sql.DB, trigger TriggerDef) error
 }
 
 // BaseApplier contains shared schema application logic
@@ -116,6 +295,16 @@ func (a *BaseApplier) CreateIndex(db *sql.DB, index IndexDef) error {
 	return nil
 }
 
+// CreateTableIndexes creates each of a table's indexes in turn
+func (a *BaseApplier) CreateTableIndexes(db *sql.DB, table TableSchema) error {
+	for _, idx := range table.Indexes {
+		if err := a.CreateIndex(db, idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CreateForeignKey adds a foreign key constraint to a table
 func (a *BaseApplier) CreateForeignKey(db *sql.DB, fk ForeignKeyDef) error {
 	_, err := db.Exec(fk.ConstraintStmt)
@@ -125,11 +314,38 @@ func (a *BaseApplier) CreateForeignKey(db *sql.DB, fk ForeignKeyDef) error {
 	return nil
 }
 
+// CreateType creates a user-defined type in the database
+func (a *BaseApplier) CreateType(db *sql.DB, typ TypeDef) error {
+	_, err := db.Exec(typ.CreateStmt)
+	if err != nil {
+		return fmt.Errorf("failed to create %s type %s: %w", typ.Kind, typ.QualifiedName(), err)
+	}
+	return nil
+}
+
+// CreateRoutine creates a function or procedure in the database
+func (a *BaseApplier) CreateRoutine(db *sql.DB, routine RoutineDef) error {
+	_, err := db.Exec(routine.CreateStmt)
+	if err != nil {
+		return fmt.Errorf("failed to create %s %s: %w", routine.Kind, routine.Signature(), err)
+	}
+	return nil
+}
+
+// CreateTrigger creates a trigger on a table
+func (a *BaseApplier) CreateTrigger(db *sql.DB, trigger TriggerDef) error {
+	_, err := db.Exec(trigger.CreateStmt)
+	if err != nil {
+		return fmt.Errorf("failed to create trigger %s on %s: %w", trigger.Name, qualifiedName(trigger.Schema, trigger.Table), err)
+	}
+	return nil
+}
+
 // CreateView creates a view in the database
 func (a *BaseApplier) CreateView(db *sql.DB, view ViewDef) error {
 	_, err := db.Exec(view.CreateStmt)
 	if err != nil {
-		return fmt.Errorf("failed to create view %s: %w", view.Name, err)
+		return fmt.Errorf("failed to create view %s: %w", view.QualifiedName(), err)
 	}
 	return nil
 }
@@ -7,56 +7,6 @@ import (
 	"github.com/DGarbs51/lcmigrate/internal/dialect"
 )
 
-func TestNewExtractor(t *testing.T) {
-	tests := []struct {
-		engine  string
-		wantNil bool
-	}{
-		{"mysql", false},
-		{"pgsql", false},
-		{"unknown", true},
-		{"", true},
-	}
-
-	for _, tt := range tests {
-		ext := NewExtractor(tt.engine)
-		if tt.wantNil {
-			if ext != nil {
-				t.Errorf("NewExtractor(%q) = %v, want nil", tt.engine, ext)
-			}
-		} else {
-			if ext == nil {
-				t.Errorf("NewExtractor(%q) = nil, want non-nil", tt.engine)
-			}
-		}
-	}
-}
-
-func TestNewApplier(t *testing.T) {
-	tests := []struct {
-		engine  string
-		wantNil bool
-	}{
-		{"mysql", false},
-		{"pgsql", false},
-		{"unknown", true},
-		{"", true},
-	}
-
-	for _, tt := range tests {
-		app := NewApplier(tt.engine)
-		if tt.wantNil {
-			if app != nil {
-				t.Errorf("NewApplier(%q) = %v, want nil", tt.engine, app)
-			}
-		} else {
-			if app == nil {
-				t.Errorf("NewApplier(%q) = nil, want non-nil", tt.engine)
-			}
-		}
-	}
-}
-
 func TestBaseApplier_CreateIndex(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -213,3 +163,54 @@ func TestBaseApplier_CreateView_Error(t *testing.T) {
 		t.Errorf("CreateView() expected error, got nil")
 	}
 }
+
+func TestSchemaFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter SchemaFilter
+		schema string
+		want   bool
+	}{
+		{"empty filter", SchemaFilter{}, "public", true},
+		{"included", SchemaFilter{Include: []string{"audit"}}, "audit", true},
+		{"not included", SchemaFilter{Include: []string{"audit"}}, "public", false},
+		{"excluded", SchemaFilter{Exclude: []string{"scratch"}}, "scratch", false},
+		{"exclude wins", SchemaFilter{Include: []string{"audit"}, Exclude: []string{"audit"}}, "audit", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Allows(tt.schema); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.schema, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualifiedName(t *testing.T) {
+	if got := (TableSchema{Name: "users"}).QualifiedName(); got != "users" {
+		t.Errorf("QualifiedName() = %q, want %q", got, "users")
+	}
+	if got := (TableSchema{Schema: "audit", Name: "log"}).QualifiedName(); got != "audit.log" {
+		t.Errorf("QualifiedName() = %q, want %q", got, "audit.log")
+	}
+	if got := (ViewDef{Schema: "reporting", Name: "daily"}).QualifiedName(); got != "reporting.daily" {
+		t.Errorf("QualifiedName() = %q, want %q", got, "reporting.daily")
+	}
+}
+
+func TestTableSchema_DisplayName(t *testing.T) {
+	tests := []struct {
+		table TableSchema
+		want  string
+	}{
+		{TableSchema{Schema: "public", Name: "users"}, "public.users"},
+		{TableSchema{Schema: "public", Name: "users", SourceName: "users"}, "public.users"},
+		{TableSchema{Schema: "public", Name: "customers", SourceName: "users"}, "public.customers (public.users on the source)"},
+	}
+	for _, tt := range tests {
+		if got := tt.table.DisplayName(); got != tt.want {
+			t.Errorf("DisplayName() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DGarbs51/lcmigrate/internal/dialect"
+)
+
+// SQLiteExtractor extracts schema from SQLite database files
+// Tables come from sqlite_master and the table_xinfo, index_list, index_xinfo and
+// foreign_key_list pragmas; column types are the declared types, which a Translator maps to
+// the destination engine
+type SQLiteExtractor struct {
+	Dialect dialect.Dialect
+}
+
+// NewSQLiteExtractor creates a new SQLite schema extractor
+func NewSQLiteExtractor() *SQLiteExtractor {
+	return &SQLiteExtractor{
+		Dialect: &dialect.SQLiteDialect{},
+	}
+}
+
+// ExtractTables extracts all table schemas from the database
+// Internal tables (sqlite_sequence, sqlite_stat1, ...) and virtual tables are skipped
+func (e *SQLiteExtractor) ExtractTables(db *sql.DB, database string) ([]TableSchema, error) {
+	rows, err := db.Query(`
+		SELECT name, sql
+		FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\'
+			AND sql NOT LIKE 'CREATE VIRTUAL TABLE%'
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	var tables []TableSchema
+	for rows.Next() {
+		var table TableSchema
+		if err := rows.Scan(&table.Name, &table.CreateStmt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+
+	for i := range tables {
+		if err := e.extractTable(db, &tables[i]); err != nil {
+			return nil, fmt.Errorf("failed to extract table %s: %w", tables[i].Name, err)
+		}
+	}
+
+	// A foreign key without referenced columns references the other table's primary key
+	primaryKeys := make(map[string][]string, len(tables))
+	for _, table := range tables {
+		primaryKeys[table.Name] = table.PrimaryKey
+	}
+	for i := range tables {
+		for j := range tables[i].ForeignKeys {
+			if fk := &tables[i].ForeignKeys[j]; len(fk.RefColumns) == 0 {
+				fk.RefColumns = primaryKeys[fk.RefTable]
+			}
+		}
+	}
+
+	return tables, nil
+}
+
+// extractTable reads a table's columns, primary key, indexes and foreign keys
+func (e *SQLiteExtractor) extractTable(db *sql.DB, table *TableSchema) error {
+	// table_xinfo is table_info with generated columns, which SELECT * also returns
+	rows, err := db.Query(`
+		SELECT name, type, "notnull", dflt_value, pk, hidden
+		FROM pragma_table_xinfo(?)
+		ORDER BY cid
+	`, table.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+	pk := make(map[int]string)
+	for rows.Next() {
+		var col ColumnDef
+		var notNull bool
+		var pkPosition, hidden int
+		if err := rows.Scan(&col.Name, &col.DataType, &notNull, &col.DefaultValue, &pkPosition, &hidden); err != nil {
+			rows.Close()
+			return err
+		}
+		switch hidden {
+		case 1:
+			// Hidden column of a virtual table
+			continue
+		case 2:
+			col.Extra = "VIRTUAL GENERATED"
+		case 3:
+			col.Extra = "STORED GENERATED"
+		}
+		col.IsNullable = !notNull
+		if pkPosition > 0 {
+			pk[pkPosition] = col.Name
+		}
+		table.Columns = append(table.Columns, col)
+	}
+	rows.Close()
+
+	positions := make([]int, 0, len(pk))
+	for p := range pk {
+		positions = append(positions, p)
+	}
+	sort.Ints(positions)
+	for _, p := range positions {
+		table.PrimaryKey = append(table.PrimaryKey, pk[p])
+	}
+
+	if table.Indexes, err = e.extractIndexes(db, table.Name); err != nil {
+		return err
+	}
+	if table.ForeignKeys, err = e.extractForeignKeys(db, table.Name); err != nil {
+		return err
+	}
+	return nil
+}
+
+// extractIndexes reads a table's indexes other than its primary key
+// Indexes behind UNIQUE constraints (sqlite_autoindex_*) get Laravel-style names and have no
+// CreateStmt; an expression key part is returned as an empty column name
+func (e *SQLiteExtractor) extractIndexes(db *sql.DB, tableName string) ([]IndexDef, error) {
+	rows, err := db.Query(`
+		SELECT l.name, l."unique", l.origin, COALESCE(m.sql, '')
+		FROM pragma_index_list(?) l
+		LEFT JOIN sqlite_master m ON m.type = 'index' AND m.name = l.name
+		WHERE l.origin <> 'pk'
+		ORDER BY l.name
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes: %w", err)
+	}
+	var indexes []IndexDef
+	for rows.Next() {
+		var ix IndexDef
+		var origin string
+		if err := rows.Scan(&ix.Name, &ix.IsUnique, &origin, &ix.CreateStmt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ix.IsConstraint = origin == "u"
+		indexes = append(indexes, ix)
+	}
+	rows.Close()
+
+	for i := range indexes {
+		ix := &indexes[i]
+		columns, err := db.Query(`
+			SELECT COALESCE(name, '')
+			FROM pragma_index_xinfo(?)
+			WHERE key = 1
+			ORDER BY seqno
+		`, ix.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get columns of index %s: %w", ix.Name, err)
+		}
+		for columns.Next() {
+			var column string
+			if err := columns.Scan(&column); err != nil {
+				columns.Close()
+				return nil, err
+			}
+			ix.Columns = append(ix.Columns, column)
+		}
+		columns.Close()
+
+		if ix.IsConstraint {
+			ix.Name = tableName + "_" + strings.Join(ix.Columns, "_") + "_unique"
+		}
+	}
+	return indexes, nil
+}
+
+// extractForeignKeys reads a table's foreign keys, which SQLite doesn't name; they get
+// Laravel-style names
+func (e *SQLiteExtractor) extractForeignKeys(db *sql.DB, tableName string) ([]ForeignKeyDef, error) {
+	rows, err := db.Query(`
+		SELECT id, "table", "from", "to", on_update, on_delete
+		FROM pragma_foreign_key_list(?)
+		ORDER BY id, seq
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	var fks []ForeignKeyDef
+	lastID := -1
+	for rows.Next() {
+		var id int
+		var refTable, column, onUpdate, onDelete string
+		var refColumn sql.NullString
+		if err := rows.Scan(&id, &refTable, &column, &refColumn, &onUpdate, &onDelete); err != nil {
+			return nil, err
+		}
+		if id != lastID {
+			fks = append(fks, ForeignKeyDef{RefTable: refTable, OnDelete: onDelete, OnUpdate: onUpdate})
+			lastID = id
+		}
+		fk := &fks[len(fks)-1]
+		fk.Columns = append(fk.Columns, column)
+		if refColumn.Valid {
+			fk.RefColumns = append(fk.RefColumns, refColumn.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range fks {
+		fks[i].Name = tableName + "_" + strings.Join(fks[i].Columns, "_") + "_foreign"
+	}
+	return fks, nil
+}
+
+// ExtractViews extracts view definitions as written in CREATE VIEW
+func (e *SQLiteExtractor) ExtractViews(db *sql.DB, database string) ([]ViewDef, error) {
+	rows, err := db.Query(`
+		SELECT name, sql
+		FROM sqlite_master
+		WHERE type = 'view'
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []ViewDef
+	for rows.Next() {
+		var view ViewDef
+		if err := rows.Scan(&view.Name, &view.CreateStmt); err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, rows.Err()
+}
+
+// ExtractTriggers extracts trigger definitions as written in CREATE TRIGGER
+func (e *SQLiteExtractor) ExtractTriggers(db *sql.DB, database string) ([]TriggerDef, error) {
+	rows, err := db.Query(`
+		SELECT name, tbl_name, sql
+		FROM sqlite_master
+		WHERE type = 'trigger'
+		ORDER BY tbl_name, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []TriggerDef
+	for rows.Next() {
+		var trigger TriggerDef
+		if err := rows.Scan(&trigger.Name, &trigger.Table, &trigger.CreateStmt); err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers, rows.Err()
+}
+
+// ExtractSchemas is a no-op for SQLite (attached databases aren't migrated)
+func (e *SQLiteExtractor) ExtractSchemas(db *sql.DB, database string) ([]string, error) {
+	return nil, nil
+}
+
+// ExtractExtensions is a no-op for SQLite (loadable extensions aren't recorded in the file)
+func (e *SQLiteExtractor) ExtractExtensions(db *sql.DB, database string) ([]ExtensionDef, error) {
+	return nil, nil
+}
+
+// ExtractTypes is a no-op for SQLite (SQLite has no user-defined types)
+func (e *SQLiteExtractor) ExtractTypes(db *sql.DB, database string) ([]TypeDef, error) {
+	return nil, nil
+}
+
+// ExtractSequences is a no-op for SQLite (AUTOINCREMENT counters are moved past the copied
+// rows by the destination)
+func (e *SQLiteExtractor) ExtractSequences(db *sql.DB, database string) ([]SequenceDef, error) {
+	return nil, nil
+}
+
+// ExtractRoutines is a no-op for SQLite (SQLite has no stored routines)
+func (e *SQLiteExtractor) ExtractRoutines(db *sql.DB, database string) ([]RoutineDef, error) {
+	return nil, nil
+}
@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSQLiteExtractor_ExtractTables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT name, sql\\s+FROM sqlite_master\\s+WHERE type = 'table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "sql"}).
+			AddRow("posts", `CREATE TABLE "posts" (...)`).
+			AddRow("users", `CREATE TABLE "users" (...)`))
+
+	// posts
+	mock.ExpectQuery("FROM pragma_table_xinfo").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "type", "notnull", "dflt_value", "pk", "hidden"}).
+			AddRow("id", "INTEGER", 1, nil, 1, 0).
+			AddRow("user_id", "INTEGER", 1, nil, 0, 0).
+			AddRow("title", "varchar", 1, "'untitled'", 0, 0).
+			AddRow("slug", "TEXT", 0, nil, 0, 3))
+	mock.ExpectQuery("FROM pragma_index_list").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "unique", "origin", "sql"}).
+			AddRow("posts_title_index", 0, "c", `CREATE INDEX "posts_title_index" on "posts" ("title")`).
+			AddRow("sqlite_autoindex_posts_1", 1, "u", ""))
+	mock.ExpectQuery("FROM pragma_index_xinfo").WithArgs("posts_title_index").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("title"))
+	mock.ExpectQuery("FROM pragma_index_xinfo").WithArgs("sqlite_autoindex_posts_1").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("user_id").AddRow("title"))
+	mock.ExpectQuery("FROM pragma_foreign_key_list").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "table", "from", "to", "on_update", "on_delete"}).
+			AddRow(0, "users", "user_id", nil, "NO ACTION", "CASCADE"))
+
+	// users
+	mock.ExpectQuery("FROM pragma_table_xinfo").WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "type", "notnull", "dflt_value", "pk", "hidden"}).
+			AddRow("tenant", "INTEGER", 1, nil, 2, 0).
+			AddRow("id", "INTEGER", 1, nil, 1, 0))
+	mock.ExpectQuery("FROM pragma_index_list").WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "unique", "origin", "sql"}))
+	mock.ExpectQuery("FROM pragma_foreign_key_list").WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "table", "from", "to", "on_update", "on_delete"}))
+
+	tables, err := NewSQLiteExtractor().ExtractTables(db, "database/database.sqlite")
+	if err != nil {
+		t.Fatalf("ExtractTables() error = %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("ExtractTables() returned %d tables, want 2", len(tables))
+	}
+	posts, users := tables[0], tables[1]
+
+	if len(posts.Columns) != 4 || posts.Columns[2].DefaultValue.String != "'untitled'" || posts.Columns[3].Extra != "STORED GENERATED" {
+		t.Errorf("posts columns = %+v", posts.Columns)
+	}
+	if !posts.Columns[3].IsNullable || posts.Columns[0].IsNullable {
+		t.Errorf("posts nullability = %+v", posts.Columns)
+	}
+
+	wantIndexes := []IndexDef{
+		{Name: "posts_title_index", Columns: []string{"title"}, CreateStmt: `CREATE INDEX "posts_title_index" on "posts" ("title")`},
+		{Name: "posts_user_id_title_unique", Columns: []string{"user_id", "title"}, IsUnique: true, IsConstraint: true},
+	}
+	if !reflect.DeepEqual(posts.Indexes, wantIndexes) {
+		t.Errorf("posts indexes = %+v, want %+v", posts.Indexes, wantIndexes)
+	}
+
+	// The foreign key references the primary key of users, in key order
+	wantFKs := []ForeignKeyDef{
+		{Name: "posts_user_id_foreign", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id", "tenant"},
+			OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+	}
+	if !reflect.DeepEqual(posts.ForeignKeys, wantFKs) {
+		t.Errorf("posts foreign keys = %+v, want %+v", posts.ForeignKeys, wantFKs)
+	}
+	if !reflect.DeepEqual(users.PrimaryKey, []string{"id", "tenant"}) {
+		t.Errorf("users primary key = %v, want [id tenant]", users.PrimaryKey)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
+
+func TestSQLiteExtractor_ExtractViewsAndTriggers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM sqlite_master\\s+WHERE type = 'view'").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "sql"}).
+			AddRow("active_users", "CREATE VIEW active_users AS SELECT * FROM users WHERE active = 1"))
+	mock.ExpectQuery("FROM sqlite_master\\s+WHERE type = 'trigger'").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "tbl_name", "sql"}).
+			AddRow("users_touch", "users", "CREATE TRIGGER users_touch AFTER UPDATE ON users BEGIN SELECT 1; END"))
+
+	e := NewSQLiteExtractor()
+	views, err := e.ExtractViews(db, "database/database.sqlite")
+	if err != nil {
+		t.Fatalf("ExtractViews() error = %v", err)
+	}
+	if len(views) != 1 || views[0].Name != "active_users" {
+		t.Errorf("ExtractViews() = %+v", views)
+	}
+	triggers, err := e.ExtractTriggers(db, "database/database.sqlite")
+	if err != nil {
+		t.Fatalf("ExtractTriggers() error = %v", err)
+	}
+	if len(triggers) != 1 || triggers[0].Table != "users" {
+		t.Errorf("ExtractTriggers() = %+v", triggers)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations not met: %v", err)
+	}
+}
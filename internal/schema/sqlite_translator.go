@@ -0,0 +1,524 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sqliteSyntax is SQLite's lexical rules: "..." and `...` both quote identifiers
+var sqliteSyntax = sqlSyntax{backticks: true, ansiQuotes: true}
+
+// SQLite type affinities, which decide how a column stores its values
+// (https://www.sqlite.org/datatype3.html#determination_of_column_affinity)
+const (
+	affinityInteger = "INTEGER"
+	affinityText    = "TEXT"
+	affinityBlob    = "BLOB"
+	affinityReal    = "REAL"
+	affinityNumeric = "NUMERIC"
+)
+
+// sqliteAffinity returns the affinity SQLite gives a column with the declared type
+func sqliteAffinity(declared string) string {
+	upper := strings.ToUpper(declared)
+	switch {
+	case strings.Contains(upper, "INT"):
+		return affinityInteger
+	case strings.Contains(upper, "CHAR"), strings.Contains(upper, "CLOB"), strings.Contains(upper, "TEXT"):
+		return affinityText
+	case strings.Contains(upper, "BLOB"), strings.TrimSpace(upper) == "":
+		return affinityBlob
+	case strings.Contains(upper, "REAL"), strings.Contains(upper, "FLOA"), strings.Contains(upper, "DOUB"):
+		return affinityReal
+	default:
+		return affinityNumeric
+	}
+}
+
+// SQLiteTranslator rewrites SQLite tables as MySQL or PostgreSQL tables
+// Column types are chosen from the declared type where it names one Laravel uses (boolean,
+// datetime, json, ...) and from its affinity otherwise
+type SQLiteTranslator struct {
+	Dest  string // Destination engine: mysql or pgsql
+	lossy []LossyConversion
+}
+
+// NewSQLiteTranslator creates a translator from SQLite to the destination engine
+func NewSQLiteTranslator(dest string) *SQLiteTranslator {
+	return &SQLiteTranslator{Dest: dest}
+}
+
+// LossyConversions lists what the last translation couldn't carry over exactly
+func (t *SQLiteTranslator) LossyConversions() []LossyConversion {
+	return t.lossy
+}
+
+// report records a lossy conversion
+func (t *SQLiteTranslator) report(object, format string, args ...interface{}) {
+	t.lossy = append(t.lossy, LossyConversion{Object: object, Detail: fmt.Sprintf(format, args...)})
+}
+
+// mysql reports whether the destination is MySQL rather than PostgreSQL
+func (t *SQLiteTranslator) mysql() bool {
+	return t.Dest == "mysql"
+}
+
+// pick returns the MySQL or PostgreSQL variant of a type or expression
+func (t *SQLiteTranslator) pick(mysql, pgsql string) string {
+	if t.mysql() {
+		return mysql
+	}
+	return pgsql
+}
+
+// quote quotes an identifier for the destination
+func (t *SQLiteTranslator) quote(name string) string {
+	if t.mysql() {
+		return quoteMySQLIdent(name)
+	}
+	return quoteIdentifier(name)
+}
+
+// quoteList quotes and comma-separates identifiers for the destination
+func (t *SQLiteTranslator) quoteList(names []string) string {
+	if t.mysql() {
+		return quoteMySQLIdentList(names)
+	}
+	return quoteIdentifierList(names)
+}
+
+// quoteLiteral quotes a string literal for the destination
+func (t *SQLiteTranslator) quoteLiteral(value string) string {
+	if t.mysql() {
+		return quoteMySQLLiteral(value)
+	}
+	return quoteLiteral(value)
+}
+
+// identifierLimit returns the longest identifier the destination allows
+func (t *SQLiteTranslator) identifierLimit() int {
+	if t.mysql() {
+		return mysqlMaxIdentifierLength
+	}
+	return pgMaxIdentifierLength
+}
+
+// TranslateTables rewrites SQLite tables for the destination engine
+func (t *SQLiteTranslator) TranslateTables(db *sql.DB, database string, tables []TableSchema) ([]TableSchema, []TypeDef, error) {
+	t.lossy = nil
+
+	// PostgreSQL tables, indexes and sequences share one namespace per schema; MySQL constraint
+	// names are unique per database
+	used := make(map[string]bool)
+	if !t.mysql() {
+		for _, table := range tables {
+			used[table.Name] = true
+		}
+	}
+
+	translated := make([]TableSchema, 0, len(tables))
+	for _, table := range tables {
+		translated = append(translated, t.translateTable(table, used))
+	}
+	for i, table := range tables {
+		translated[i].ForeignKeys = t.translateForeignKeys(table, translated, used)
+	}
+	return translated, nil, nil
+}
+
+// translateTable builds the destination CREATE TABLE statement and indexes for a SQLite table
+func (t *SQLiteTranslator) translateTable(table TableSchema, used map[string]bool) TableSchema {
+	out := TableSchema{
+		Name:       table.Name,
+		PrimaryKey: table.PrimaryKey,
+	}
+
+	ddl, err := parseCreateTable(table.CreateStmt, sqliteSyntax)
+	if err != nil {
+		ddl = &createTableDDL{}
+	}
+	rowid := isRowidAlias(table, ddl)
+
+	indent := t.pick("  ", "    ")
+	types := make(map[string]string, len(table.Columns))
+	var lines []string
+	for _, c := range table.Columns {
+		col, definition := t.translateColumn(table, c, rowid)
+		out.Columns = append(out.Columns, col)
+		types[c.Name] = col.DataType
+		lines = append(lines, indent+definition)
+		if col.Extra == "IDENTITY BY DEFAULT" {
+			used[truncateIdentifier(table.Name+"_"+c.Name+"_seq", pgMaxIdentifierLength)] = true
+		}
+	}
+
+	if len(table.PrimaryKey) > 0 {
+		parts := make([]string, len(table.PrimaryKey))
+		for i, column := range table.PrimaryKey {
+			parts[i] = t.keyPart(table.Name+" primary key", column, types[column], true)
+		}
+		lines = append(lines, fmt.Sprintf("%sPRIMARY KEY (%s)", indent, strings.Join(parts, ", ")))
+		if !t.mysql() {
+			used[truncateIdentifier(table.Name+"_pkey", pgMaxIdentifierLength)] = true
+		}
+	}
+
+	// CHECK constraints, including the ones Laravel writes for enum columns, are SQLite expressions
+	for _, c := range ddl.Clauses {
+		switch {
+		case c.Kind == clauseCheck:
+			name := c.Name
+			if name == "" {
+				name = "CHECK"
+			}
+			t.report(table.Name, "CHECK constraint %s isn't migrated; recreate it in %s syntax", name, t.Dest)
+		case c.Kind == clauseColumn && c.hasKeyword("CHECK"):
+			t.report(table.Name+"."+c.Name, "CHECK constraint isn't migrated; recreate it in %s syntax", t.Dest)
+		}
+	}
+
+	out.CreateStmt = fmt.Sprintf("CREATE TABLE %s (\n%s\n)", t.quote(table.Name), strings.Join(lines, ",\n"))
+	if t.mysql() {
+		out.CreateStmt += " DEFAULT CHARSET=utf8mb4"
+	}
+
+	for _, ix := range table.Indexes {
+		if index, ok := t.translateIndex(table.Name, ix, types, used); ok {
+			out.Indexes = append(out.Indexes, index)
+		}
+	}
+	return out
+}
+
+// isRowidAlias reports whether a table's primary key is an INTEGER PRIMARY KEY column, which
+// aliases the rowid and is assigned automatically
+func isRowidAlias(table TableSchema, ddl *createTableDDL) bool {
+	if len(table.PrimaryKey) != 1 {
+		return false
+	}
+	for _, tok := range tokenizeSQL(ddl.Suffix, sqliteSyntax) {
+		if tok.isKeyword("WITHOUT") {
+			return false
+		}
+	}
+	for _, c := range table.Columns {
+		if c.Name == table.PrimaryKey[0] {
+			return strings.EqualFold(strings.TrimSpace(c.DataType), "INTEGER")
+		}
+	}
+	return false
+}
+
+// translateColumn returns the destination column and its definition in CREATE TABLE
+func (t *SQLiteTranslator) translateColumn(table TableSchema, c ColumnDef, rowid bool) (ColumnDef, string) {
+	object := table.Name + "." + c.Name
+	destType := t.columnType(object, c.DataType)
+
+	col := ColumnDef{
+		Name:       c.Name,
+		DataType:   destType,
+		IsNullable: c.IsNullable,
+		SourceType: c.DataType,
+	}
+	definition := t.quote(c.Name) + " " + destType
+
+	if rowid && table.PrimaryKey[0] == c.Name {
+		if t.mysql() {
+			definition += " NOT NULL AUTO_INCREMENT"
+			col.Extra = "auto_increment"
+		} else {
+			definition += " GENERATED BY DEFAULT AS IDENTITY"
+			col.Extra = "IDENTITY BY DEFAULT"
+		}
+		col.IsNullable = false
+		return col, definition
+	}
+
+	if !c.IsNullable {
+		definition += " NOT NULL"
+	}
+	switch {
+	case c.IsGenerated():
+		t.report(object, "generated column becomes a regular column holding the copied values")
+	case c.DefaultValue.Valid:
+		if value, ok := t.columnDefault(object, c.DefaultValue.String, destType); ok {
+			definition += " DEFAULT " + value
+			col.DefaultValue = sql.NullString{String: value, Valid: true}
+		}
+	}
+	return col, definition
+}
+
+// columnType maps a declared SQLite column type to the destination engine
+func (t *SQLiteTranslator) columnType(object, declared string) string {
+	decl := strings.ToLower(strings.TrimSpace(declared))
+	name, args := splitPostgresType(decl)
+	name = strings.TrimSpace(name)
+
+	// Types Laravel's SQLite grammar writes, whose affinity alone would lose their meaning
+	switch {
+	case decl == "tinyint(1)", name == "boolean", name == "bool":
+		return t.pick("tinyint(1)", "boolean")
+	case name == "date":
+		return "date"
+	case name == "datetime", name == "timestamp":
+		return t.pick(fmt.Sprintf("datetime(%d)", typeLength(decl, 0)), fmt.Sprintf("timestamp(%d) without time zone", typeLength(decl, 0)))
+	case name == "time":
+		return t.pick(fmt.Sprintf("time(%d)", typeLength(decl, 0)), fmt.Sprintf("time(%d) without time zone", typeLength(decl, 0)))
+	case name == "json", name == "jsonb":
+		return t.pick("json", "jsonb")
+	case name == "uuid":
+		return t.pick("char(36)", "uuid")
+	}
+
+	switch sqliteAffinity(decl) {
+	case affinityInteger:
+		// SQLite integers are 64-bit whatever width the declared type names
+		return "bigint"
+	case affinityText:
+		if n := typeLength(decl, 0); n > 0 && strings.Contains(name, "char") {
+			if t.mysql() && n > 16383 {
+				return "longtext"
+			}
+			return fmt.Sprintf("varchar(%d)", n)
+		}
+		if name == "varchar" {
+			// Laravel's string columns; SQLite doesn't enforce a length
+			return t.pick("varchar(255)", "varchar")
+		}
+		return t.pick("longtext", "text")
+	case affinityBlob:
+		if decl == "" {
+			t.report(object, "column has no declared type; its values are copied as text")
+			return t.pick("longtext", "text")
+		}
+		return t.pick("longblob", "bytea")
+	case affinityReal:
+		return t.pick("double", "double precision")
+	default:
+		if precision, scale, ok := numericArguments(args); ok && (name == "decimal" || name == "numeric") {
+			if t.mysql() {
+				return fmt.Sprintf("decimal(%d,%d)", min(precision, 65), min(scale, 30))
+			}
+			return fmt.Sprintf("numeric(%d,%d)", precision, scale)
+		}
+		return t.pick("decimal(65,30)", "numeric")
+	}
+}
+
+// columnDefault converts a SQLite column default, as table_xinfo returns it, for the destination
+func (t *SQLiteTranslator) columnDefault(object, value, destType string) (string, bool) {
+	value = strings.TrimSpace(value)
+	boolean := destType == "boolean" || destType == "tinyint(1)"
+	blobOrText := destType == "json" || destType == "longtext" || destType == "longblob"
+
+	switch upper := strings.ToUpper(value); upper {
+	case "NULL":
+		return "", false
+	case "CURRENT_TIMESTAMP":
+		switch {
+		case !t.mysql():
+			return "CURRENT_TIMESTAMP", true
+		case strings.HasPrefix(destType, "datetime") && typeLength(destType, 0) > 0:
+			// MySQL needs the default's precision to match the column's
+			return fmt.Sprintf("CURRENT_TIMESTAMP(%d)", typeLength(destType, 0)), true
+		case strings.HasPrefix(destType, "datetime"):
+			return "CURRENT_TIMESTAMP", true
+		}
+	case "CURRENT_DATE", "CURRENT_TIME":
+		// MySQL only takes them as expression defaults
+		return t.pick("("+upper+")", upper), true
+	case "TRUE":
+		value = "1"
+	case "FALSE":
+		value = "0"
+	}
+
+	if s, ok := postgresStringValue(value); ok {
+		switch {
+		case boolean:
+			return t.boolean(mysqlBool(s)), true
+		case t.isNumericType(destType) && isNumber(s):
+			return s, true
+		case t.mysql() && blobOrText:
+			return "(" + quoteMySQLLiteral(s) + ")", true
+		default:
+			return t.quoteLiteral(s), true
+		}
+	}
+	if isNumber(value) {
+		switch {
+		case boolean:
+			return t.boolean(mysqlBool(value)), true
+		case t.isNumericType(destType):
+			return value, true
+		case t.mysql() && blobOrText:
+			return "(" + quoteMySQLLiteral(value) + ")", true
+		default:
+			return t.quoteLiteral(value), true
+		}
+	}
+
+	t.report(object, "default %s isn't migrated", value)
+	return "", false
+}
+
+// boolean converts 1 or 0 to the destination's boolean literal
+func (t *SQLiteTranslator) boolean(value string) string {
+	if t.mysql() {
+		return value
+	}
+	if value == "1" {
+		return "true"
+	}
+	return "false"
+}
+
+// isNumericType reports whether a translated column type holds numbers
+func (t *SQLiteTranslator) isNumericType(destType string) bool {
+	if t.mysql() {
+		return isMySQLNumericType(destType)
+	}
+	return isNumericType(destType)
+}
+
+// isNumber reports whether a default is a numeric literal
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// keyPart quotes an index column, indexing a prefix of columns MySQL can't index whole
+func (t *SQLiteTranslator) keyPart(object, column, destType string, unique bool) string {
+	if !t.mysql() || !needsIndexPrefix(destType) {
+		return t.quote(column)
+	}
+	if unique {
+		t.report(object, "uniqueness of %s is only enforced on its first %d characters", column, mysqlIndexPrefixLength)
+	}
+	return fmt.Sprintf("%s(%d)", quoteMySQLIdent(column), mysqlIndexPrefixLength)
+}
+
+// translateIndex builds the destination statement for a SQLite index
+func (t *SQLiteTranslator) translateIndex(table string, ix IndexDef, types map[string]string, used map[string]bool) (IndexDef, bool) {
+	object := table + "." + ix.Name
+	for _, column := range ix.Columns {
+		if column == "" {
+			t.report(object, "expression index isn't migrated; recreate it in %s syntax", t.Dest)
+			return IndexDef{}, false
+		}
+	}
+	if len(ix.Columns) == 0 {
+		t.report(object, "index definition %q isn't understood", ix.CreateStmt)
+		return IndexDef{}, false
+	}
+	if isPartialIndex(ix.CreateStmt) {
+		if ix.IsUnique {
+			t.report(object, "partial unique index isn't migrated; %s would enforce it on every row", t.Dest)
+			return IndexDef{}, false
+		}
+		t.report(object, "partial index covers every row")
+	}
+
+	keyParts := make([]string, len(ix.Columns))
+	for i, column := range ix.Columns {
+		if t.mysql() && types[column] == "json" {
+			t.report(object, "JSON column %s can't be indexed in MySQL", column)
+			return IndexDef{}, false
+		}
+		keyParts[i] = t.keyPart(object, column, types[column], ix.IsUnique)
+	}
+
+	index := IndexDef{Columns: ix.Columns, IsUnique: ix.IsUnique}
+	if t.mysql() {
+		// Index names only have to be unique per table
+		index.Name = truncateIdentifier(ix.Name, mysqlMaxIdentifierLength)
+		kind := "INDEX"
+		if ix.IsUnique {
+			kind = "UNIQUE INDEX"
+		}
+		index.Definition = fmt.Sprintf("ADD %s %s (%s)", kind, quoteMySQLIdent(index.Name), strings.Join(keyParts, ", "))
+		index.CreateStmt = fmt.Sprintf("ALTER TABLE %s %s", quoteMySQLIdent(table), index.Definition)
+		return index, true
+	}
+
+	index.Name = uniqueIdentifier(ix.Name, pgMaxIdentifierLength, used)
+	if index.Name != ix.Name {
+		t.report(object, "index is renamed to %s", index.Name)
+	}
+	kind := "INDEX"
+	if ix.IsUnique {
+		kind = "UNIQUE INDEX"
+	}
+	index.CreateStmt = fmt.Sprintf("CREATE %s %s ON %s (%s)", kind, quoteIdentifier(index.Name), quoteIdentifier(table), strings.Join(keyParts, ", "))
+	return index, true
+}
+
+// isPartialIndex reports whether a CREATE INDEX statement has a WHERE clause
+func isPartialIndex(stmt string) bool {
+	for _, tok := range tokenizeSQL(stmt, sqliteSyntax) {
+		if tok.isKeyword("WHERE") {
+			return true
+		}
+	}
+	return false
+}
+
+// translateForeignKeys builds the destination ALTER TABLE statements for a SQLite table's
+// foreign keys
+func (t *SQLiteTranslator) translateForeignKeys(table TableSchema, translated []TableSchema, used map[string]bool) []ForeignKeyDef {
+	types := make(map[string]map[string]string)
+	for _, tt := range translated {
+		types[tt.Name] = make(map[string]string, len(tt.Columns))
+		for _, c := range tt.Columns {
+			types[tt.Name][c.Name] = c.DataType
+		}
+	}
+
+	var fks []ForeignKeyDef
+	for _, fk := range table.ForeignKeys {
+		object := table.Name + "." + fk.Name
+		if _, ok := types[fk.RefTable]; !ok {
+			t.report(object, "foreign key isn't migrated; %s isn't part of the migration", fk.RefTable)
+			continue
+		}
+		if len(fk.RefColumns) != len(fk.Columns) {
+			t.report(object, "foreign key isn't migrated; %s has no primary key to reference", fk.RefTable)
+			continue
+		}
+		if t.mysql() {
+			if column, ok := unindexableColumn(fk.Columns, types[table.Name]); ok {
+				t.report(object, "foreign key isn't migrated; MySQL can't reference %s column %s", types[table.Name][column], column)
+				continue
+			}
+			if column, ok := unindexableColumn(fk.RefColumns, types[fk.RefTable]); ok {
+				t.report(object, "foreign key isn't migrated; MySQL can't reference %s column %s.%s", types[fk.RefTable][column], fk.RefTable, column)
+				continue
+			}
+		} else if !hasUniqueKey(translated, fk.RefTable, fk.RefColumns) {
+			t.report(object, "foreign key isn't migrated; PostgreSQL needs a unique key on %s (%s)", fk.RefTable, strings.Join(fk.RefColumns, ", "))
+			continue
+		}
+
+		name := uniqueIdentifier(fk.Name, t.identifierLimit(), used)
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			t.quote(table.Name), t.quote(name), t.quoteList(fk.Columns), t.quote(fk.RefTable), t.quoteList(fk.RefColumns))
+		for _, rule := range []struct{ event, action string }{{"DELETE", fk.OnDelete}, {"UPDATE", fk.OnUpdate}} {
+			switch {
+			case rule.action == "" || rule.action == "NO ACTION":
+			case rule.action == "SET DEFAULT" && t.mysql():
+				// InnoDB rejects SET DEFAULT
+				t.report(object, "ON %s SET DEFAULT becomes NO ACTION", rule.event)
+			default:
+				stmt += fmt.Sprintf(" ON %s %s", rule.event, rule.action)
+			}
+		}
+
+		fk.Name = name
+		fk.ConstraintStmt = stmt
+		fks = append(fks, fk)
+	}
+	return fks
+}
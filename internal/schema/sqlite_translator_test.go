@@ -0,0 +1,272 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sqliteBlogTables is a small Laravel schema as the SQLite extractor returns it
+func sqliteBlogTables() []TableSchema {
+	return []TableSchema{
+		{
+			Name: "users",
+			CreateStmt: `CREATE TABLE "users" ("id" integer primary key autoincrement not null, "name" varchar not null, ` +
+				`"email" varchar not null, "is_admin" tinyint(1) not null default '0', "settings" text, ` +
+				`"created_at" datetime default CURRENT_TIMESTAMP, "role" varchar check ("role" in ('admin', 'user')) not null default 'user')`,
+			Columns: []ColumnDef{
+				{Name: "id", DataType: "INTEGER"},
+				{Name: "name", DataType: "varchar"},
+				{Name: "email", DataType: "varchar"},
+				{Name: "is_admin", DataType: "tinyint(1)", DefaultValue: nullString("'0'")},
+				{Name: "settings", DataType: "TEXT", IsNullable: true},
+				{Name: "created_at", DataType: "datetime", IsNullable: true, DefaultValue: nullString("CURRENT_TIMESTAMP")},
+				{Name: "role", DataType: "varchar", DefaultValue: nullString("'user'")},
+			},
+			PrimaryKey: []string{"id"},
+			Indexes: []IndexDef{
+				{Name: "users_email_unique", Columns: []string{"email"}, IsUnique: true,
+					CreateStmt: `CREATE UNIQUE INDEX "users_email_unique" on "users" ("email")`},
+				{Name: "users_settings_index", Columns: []string{"settings"},
+					CreateStmt: `CREATE INDEX "users_settings_index" on "users" ("settings")`},
+				{Name: "users_lower_name_index", Columns: []string{""},
+					CreateStmt: `CREATE INDEX "users_lower_name_index" on "users" (lower("name"))`},
+				{Name: "users_admin_email_unique", Columns: []string{"email"}, IsUnique: true,
+					CreateStmt: `CREATE UNIQUE INDEX "users_admin_email_unique" on "users" ("email") where "is_admin" = 1`},
+			},
+		},
+		{
+			Name: "posts",
+			CreateStmt: `CREATE TABLE "posts" ("id" integer primary key autoincrement not null, "user_id" integer not null, ` +
+				`"body" text not null, "price" numeric(8, 2) not null default 0, "payload", ` +
+				`foreign key("user_id") references "users"("id") on delete cascade)`,
+			Columns: []ColumnDef{
+				{Name: "id", DataType: "INTEGER"},
+				{Name: "user_id", DataType: "INTEGER"},
+				{Name: "body", DataType: "TEXT"},
+				{Name: "price", DataType: "numeric(8, 2)", DefaultValue: nullString("0")},
+				{Name: "payload", DataType: "", IsNullable: true},
+			},
+			PrimaryKey: []string{"id"},
+			ForeignKeys: []ForeignKeyDef{
+				{Name: "posts_user_id_foreign", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"},
+					OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+				{Name: "posts_tag_id_foreign", Columns: []string{"user_id"}, RefTable: "tags", RefColumns: []string{"id"},
+					OnDelete: "NO ACTION", OnUpdate: "NO ACTION"},
+			},
+		},
+	}
+}
+
+func TestSQLiteTranslator_TranslateTables_MySQL(t *testing.T) {
+	tr := NewSQLiteTranslator("mysql")
+	tables, types, err := tr.TranslateTables(nil, "database/database.sqlite", sqliteBlogTables())
+	if err != nil {
+		t.Fatalf("TranslateTables() error = %v", err)
+	}
+	if len(tables) != 2 || len(types) != 0 {
+		t.Fatalf("TranslateTables() = %d tables, %d types, want 2 and 0", len(tables), len(types))
+	}
+	users, posts := tables[0], tables[1]
+
+	wantCreate := "CREATE TABLE `users` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `name` varchar(255) NOT NULL,\n" +
+		"  `email` varchar(255) NOT NULL,\n" +
+		"  `is_admin` tinyint(1) NOT NULL DEFAULT 0,\n" +
+		"  `settings` longtext,\n" +
+		"  `created_at` datetime(0) DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  `role` varchar(255) NOT NULL DEFAULT 'user',\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") DEFAULT CHARSET=utf8mb4"
+	if users.CreateStmt != wantCreate {
+		t.Errorf("CreateStmt = %q, want %q", users.CreateStmt, wantCreate)
+	}
+	if users.Columns[0].Extra != "auto_increment" || users.Columns[3].SourceType != "tinyint(1)" {
+		t.Errorf("users = %+v, want an auto_increment id and the source types", users)
+	}
+
+	var indexes []string
+	for _, ix := range users.Indexes {
+		indexes = append(indexes, ix.CreateStmt)
+	}
+	wantIndexes := []string{
+		"ALTER TABLE `users` ADD UNIQUE INDEX `users_email_unique` (`email`)",
+		"ALTER TABLE `users` ADD INDEX `users_settings_index` (`settings`(191))",
+	}
+	if !reflect.DeepEqual(indexes, wantIndexes) {
+		t.Errorf("indexes = %q, want %q", indexes, wantIndexes)
+	}
+
+	wantPosts := "CREATE TABLE `posts` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` bigint NOT NULL,\n" +
+		"  `body` longtext NOT NULL,\n" +
+		"  `price` decimal(8,2) NOT NULL DEFAULT 0,\n" +
+		"  `payload` longtext,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") DEFAULT CHARSET=utf8mb4"
+	if posts.CreateStmt != wantPosts {
+		t.Errorf("CreateStmt = %q, want %q", posts.CreateStmt, wantPosts)
+	}
+	wantFK := "ALTER TABLE `posts` ADD CONSTRAINT `posts_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE"
+	if len(posts.ForeignKeys) != 1 || posts.ForeignKeys[0].ConstraintStmt != wantFK {
+		t.Errorf("foreign keys = %+v, want %q", posts.ForeignKeys, wantFK)
+	}
+
+	var report []string
+	for _, c := range tr.LossyConversions() {
+		report = append(report, c.String())
+	}
+	for _, want := range []string{
+		"users.role: CHECK constraint isn't migrated",
+		"users.users_lower_name_index: expression index isn't migrated",
+		"users.users_admin_email_unique: partial unique index isn't migrated",
+		"posts.payload: column has no declared type",
+		"posts.posts_tag_id_foreign: foreign key isn't migrated; tags isn't part of the migration",
+	} {
+		found := false
+		for _, r := range report {
+			found = found || strings.HasPrefix(r, want)
+		}
+		if !found {
+			t.Errorf("LossyConversions() = %q, missing %q", report, want)
+		}
+	}
+}
+
+func TestSQLiteTranslator_TranslateTables_Postgres(t *testing.T) {
+	tr := NewSQLiteTranslator("pgsql")
+	tables, _, err := tr.TranslateTables(nil, "database/database.sqlite", sqliteBlogTables())
+	if err != nil {
+		t.Fatalf("TranslateTables() error = %v", err)
+	}
+	users, posts := tables[0], tables[1]
+
+	wantCreate := "CREATE TABLE \"users\" (\n" +
+		"    \"id\" bigint GENERATED BY DEFAULT AS IDENTITY,\n" +
+		"    \"name\" varchar NOT NULL,\n" +
+		"    \"email\" varchar NOT NULL,\n" +
+		"    \"is_admin\" boolean NOT NULL DEFAULT false,\n" +
+		"    \"settings\" text,\n" +
+		"    \"created_at\" timestamp(0) without time zone DEFAULT CURRENT_TIMESTAMP,\n" +
+		"    \"role\" varchar NOT NULL DEFAULT 'user',\n" +
+		"    PRIMARY KEY (\"id\")\n" +
+		")"
+	if users.CreateStmt != wantCreate {
+		t.Errorf("CreateStmt = %q, want %q", users.CreateStmt, wantCreate)
+	}
+	if users.Columns[0].Extra != "IDENTITY BY DEFAULT" {
+		t.Errorf("id Extra = %q, want %q", users.Columns[0].Extra, "IDENTITY BY DEFAULT")
+	}
+
+	var indexes []string
+	for _, ix := range users.Indexes {
+		indexes = append(indexes, ix.CreateStmt)
+	}
+	wantIndexes := []string{
+		`CREATE UNIQUE INDEX "users_email_unique" ON "users" ("email")`,
+		`CREATE INDEX "users_settings_index" ON "users" ("settings")`,
+	}
+	if !reflect.DeepEqual(indexes, wantIndexes) {
+		t.Errorf("indexes = %q, want %q", indexes, wantIndexes)
+	}
+
+	if !strings.Contains(posts.CreateStmt, `"price" numeric(8,2) NOT NULL DEFAULT 0,`) ||
+		!strings.Contains(posts.CreateStmt, `"payload" text`) {
+		t.Errorf("posts CreateStmt = %q", posts.CreateStmt)
+	}
+	wantFK := `ALTER TABLE "posts" ADD CONSTRAINT "posts_user_id_foreign" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE`
+	if len(posts.ForeignKeys) != 1 || posts.ForeignKeys[0].ConstraintStmt != wantFK {
+		t.Errorf("foreign keys = %+v, want %q", posts.ForeignKeys, wantFK)
+	}
+}
+
+func TestSQLiteTranslator_ColumnType(t *testing.T) {
+	tests := []struct {
+		declared string
+		mysql    string
+		pgsql    string
+	}{
+		{"INTEGER", "bigint", "bigint"},
+		{"unsigned big int", "bigint", "bigint"},
+		{"varchar", "varchar(255)", "varchar"},
+		{"varchar(100)", "varchar(100)", "varchar(100)"},
+		{"nvarchar(20000)", "longtext", "varchar(20000)"},
+		{"TEXT", "longtext", "text"},
+		{"clob", "longtext", "text"},
+		{"blob", "longblob", "bytea"},
+		{"REAL", "double", "double precision"},
+		{"float", "double", "double precision"},
+		{"numeric", "decimal(65,30)", "numeric"},
+		{"decimal(10, 5)", "decimal(10,5)", "numeric(10,5)"},
+		{"decimal(80, 40)", "decimal(65,30)", "numeric(80,40)"},
+		{"tinyint(1)", "tinyint(1)", "boolean"},
+		{"boolean", "tinyint(1)", "boolean"},
+		{"date", "date", "date"},
+		{"datetime", "datetime(0)", "timestamp(0) without time zone"},
+		{"datetime(6)", "datetime(6)", "timestamp(6) without time zone"},
+		{"time", "time(0)", "time(0) without time zone"},
+		{"json", "json", "jsonb"},
+		{"uuid", "char(36)", "uuid"},
+	}
+	for _, tt := range tests {
+		if got := NewSQLiteTranslator("mysql").columnType("t.c", tt.declared); got != tt.mysql {
+			t.Errorf("columnType(%q) for mysql = %q, want %q", tt.declared, got, tt.mysql)
+		}
+		if got := NewSQLiteTranslator("pgsql").columnType("t.c", tt.declared); got != tt.pgsql {
+			t.Errorf("columnType(%q) for pgsql = %q, want %q", tt.declared, got, tt.pgsql)
+		}
+	}
+}
+
+func TestSQLiteTranslator_ColumnDefault(t *testing.T) {
+	tests := []struct {
+		dest     string
+		value    string
+		destType string
+		want     string
+		ok       bool
+	}{
+		{"mysql", "CURRENT_TIMESTAMP", "datetime(3)", "CURRENT_TIMESTAMP(3)", true},
+		{"mysql", "CURRENT_TIMESTAMP", "longtext", "", false},
+		{"pgsql", "CURRENT_TIMESTAMP", "timestamp(0) without time zone", "CURRENT_TIMESTAMP", true},
+		{"mysql", "CURRENT_DATE", "date", "(CURRENT_DATE)", true},
+		{"mysql", "'1'", "tinyint(1)", "1", true},
+		{"pgsql", "TRUE", "boolean", "true", true},
+		{"mysql", "'-1'", "bigint", "-1", true},
+		{"mysql", "1.5", "varchar(255)", "'1.5'", true},
+		{"mysql", `'C:\temp'`, "varchar(255)", `'C:\\temp'`, true},
+		{"mysql", "'[]'", "json", "('[]')", true},
+		{"pgsql", "'it''s'", "text", "'it''s'", true},
+		{"mysql", "NULL", "varchar(255)", "", false},
+		{"pgsql", "(datetime('now'))", "timestamp(0) without time zone", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NewSQLiteTranslator(tt.dest).columnDefault("t.c", tt.value, tt.destType)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("columnDefault(%q, %s) for %s = %q, %v, want %q, %v", tt.value, tt.destType, tt.dest, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSQLiteAffinity(t *testing.T) {
+	tests := map[string]string{
+		"INTEGER":          affinityInteger,
+		"big int":          affinityInteger,
+		"varchar(255)":     affinityText,
+		"CLOB":             affinityText,
+		"":                 affinityBlob,
+		"blob":             affinityBlob,
+		"double precision": affinityReal,
+		"decimal(8,2)":     affinityNumeric,
+		"datetime":         affinityNumeric,
+		// "INT" wins over "CHAR", as in SQLite
+		"charint": affinityInteger,
+	}
+	for declared, want := range tests {
+		if got := sqliteAffinity(declared); got != want {
+			t.Errorf("sqliteAffinity(%q) = %q, want %q", declared, got, want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package schema
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// sqlTokenKind classifies a piece of SQL source text
+type sqlTokenKind int
+
+const (
+	tokOther       sqlTokenKind = iota // Operators, punctuation and anything unrecognized
+	tokSpace                           // Whitespace
+	tokComment                         // --, # (MySQL) and /* */ comments
+	tokString                          // '...', E'...' and $tag$...$tag$ literals, and "..." on MySQL without ANSI_QUOTES
+	tokIdent                           // Unquoted words, including keywords and numbers
+	tokQuotedIdent                     // `...` identifiers (MySQL), and "..." on PostgreSQL or with ANSI_QUOTES
+)
+
+// sqlToken is a span of the original text; Value is the unquoted identifier for identifier tokens
+type sqlToken struct {
+	Kind  sqlTokenKind
+	Start int
+	End   int
+	Value string
+}
+
+// sqlSyntax holds the lexical rules that differ between MySQL (and its sql_mode) and PostgreSQL
+type sqlSyntax struct {
+	backticks        bool // `...` quotes identifiers
+	ansiQuotes       bool // "..." quotes identifiers rather than strings
+	backslashEscapes bool // \ escapes the next character in '...' strings
+	hashComments     bool // # starts a comment
+	dollarQuotes     bool // $tag$...$tag$ strings and E'...' escape strings
+	nestedComments   bool // /* */ comments nest
+}
+
+// postgresSyntax is PostgreSQL with standard_conforming_strings on (the default since 9.1)
+var postgresSyntax = sqlSyntax{ansiQuotes: true, dollarQuotes: true, nestedComments: true}
+
+// mysqlSyntax returns the MySQL lexical rules in effect under the given sql_mode
+func mysqlSyntax(sqlMode string) sqlSyntax {
+	mode := strings.ToUpper(sqlMode)
+	return sqlSyntax{
+		backticks:        true,
+		ansiQuotes:       strings.Contains(mode, "ANSI_QUOTES"),
+		backslashEscapes: !strings.Contains(mode, "NO_BACKSLASH_ESCAPES"),
+		hashComments:     true,
+	}
+}
+
+// dollarTagRe matches the opening tag of a PostgreSQL dollar-quoted string
+var dollarTagRe = regexp.MustCompile(`^\$(?:[A-Za-z_\x80-\xff][A-Za-z0-9_\x80-\xff]*)?\$`)
+
+// tokenizeMySQL splits MySQL text into tokens, following the quoting rules of the given sql_mode
+func tokenizeMySQL(text, sqlMode string) []sqlToken {
+	return tokenizeSQL(text, mysqlSyntax(sqlMode))
+}
+
+// tokenizeSQL splits SQL text into tokens
+// Every byte of the input belongs to exactly one token, so the tokens can be reassembled losslessly
+func tokenizeSQL(text string, syntax sqlSyntax) []sqlToken {
+	var tokens []sqlToken
+	for i := 0; i < len(text); {
+		start := i
+		c := text[i]
+		kind := tokOther
+		value := ""
+
+		switch {
+		case isSpaceByte(c):
+			kind = tokSpace
+			for i < len(text) && isSpaceByte(text[i]) {
+				i++
+			}
+		case (c == '#' && syntax.hashComments) || (c == '-' && strings.HasPrefix(text[i:], "--") && startsLineComment(text, i, syntax)):
+			kind = tokComment
+			if nl := strings.IndexByte(text[i:], '\n'); nl >= 0 {
+				i += nl
+			} else {
+				i = len(text)
+			}
+		case c == '/' && strings.HasPrefix(text[i:], "/*!") && syntax.backticks:
+			// MySQL executable comments hold code: skip the marker and version, tokenize the rest normally
+			i += 3
+			for i < len(text) && text[i] >= '0' && text[i] <= '9' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(text[i:], "/*"):
+			kind = tokComment
+			i = scanBlockComment(text, i, syntax.nestedComments)
+		case (c == '`' && syntax.backticks) || (c == '"' && syntax.ansiQuotes):
+			kind = tokQuotedIdent
+			i, value = scanQuoted(text, i, false)
+		case c == '\'' || c == '"':
+			kind = tokString
+			i, _ = scanQuoted(text, i, syntax.backslashEscapes)
+		case c == '$' && syntax.dollarQuotes && dollarTagRe.MatchString(text[i:]):
+			kind = tokString
+			tag := dollarTagRe.FindString(text[i:])
+			if end := strings.Index(text[i+len(tag):], tag); end >= 0 {
+				i += len(tag) + end + len(tag)
+			} else {
+				i = len(text)
+			}
+		case (c == 'E' || c == 'e') && syntax.dollarQuotes && i+1 < len(text) && text[i+1] == '\'':
+			// PostgreSQL escape string: backslashes escape regardless of standard_conforming_strings
+			kind = tokString
+			i, _ = scanQuoted(text, i+1, true)
+		case isIdentByte(c) && !(c == '$' && syntax.dollarQuotes):
+			kind = tokIdent
+			for i < len(text) && isIdentByte(text[i]) {
+				_, size := utf8.DecodeRuneInString(text[i:])
+				i += size
+			}
+			value = text[start:i]
+		default:
+			i++
+		}
+
+		tokens = append(tokens, sqlToken{Kind: kind, Start: start, End: i, Value: value})
+	}
+	return tokens
+}
+
+// startsLineComment reports whether the -- at text[i] starts a comment
+// MySQL needs whitespace (or the end of the text) after the dashes; PostgreSQL doesn't
+func startsLineComment(text string, i int, syntax sqlSyntax) bool {
+	if !syntax.backticks {
+		return true
+	}
+	return i+2 == len(text) || isSpaceByte(text[i+2])
+}
+
+// scanBlockComment returns the end offset of the /* */ comment starting at text[start]
+func scanBlockComment(text string, start int, nested bool) int {
+	depth := 0
+	for i := start; i+1 < len(text); i++ {
+		switch {
+		case text[i] == '/' && text[i+1] == '*' && (nested || depth == 0):
+			depth++
+			i++
+		case text[i] == '*' && text[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	// Unterminated: the rest of the text is inside the comment
+	return len(text)
+}
+
+// scanQuoted reads a quoted token starting at text[start], where a doubled quote is an escaped quote
+// It returns the end offset and the unescaped contents
+func scanQuoted(text string, start int, backslashEscapes bool) (int, string) {
+	quote := text[start]
+	var b strings.Builder
+	for i := start + 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case backslashEscapes && c == '\\' && i+1 < len(text):
+			b.WriteByte(c)
+			b.WriteByte(text[i+1])
+			i++
+		case c == quote && i+1 < len(text) && text[i+1] == quote:
+			b.WriteByte(quote)
+			i++
+		case c == quote:
+			return i + 1, b.String()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	// Unterminated: the rest of the text is inside the quotes
+	return len(text), b.String()
+}
+
+// isSpaceByte reports whether c is SQL whitespace
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// isIdentByte reports whether c can be part of an unquoted identifier
+// Bytes of multi-byte UTF-8 characters are allowed, as both engines allow U+0080 and up
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+// isIdent reports whether a token is an identifier, quoted or not
+func (t sqlToken) isIdent() bool {
+	return t.Kind == tokIdent || t.Kind == tokQuotedIdent
+}
+
+// isKeyword reports whether a token is the given unquoted keyword
+func (t sqlToken) isKeyword(keyword string) bool {
+	return t.Kind == tokIdent && strings.EqualFold(t.Value, keyword)
+}
+
+// isPunct reports whether a token is the given punctuation character
+func (t sqlToken) isPunct(text string, punct byte) bool {
+	return t.Kind == tokOther && t.End-t.Start == 1 && text[t.Start] == punct
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// UpgradeFinding is a change between the source's and the destination's major version that
+// affects a migrated object
+type UpgradeFinding struct {
+	Object    string // Table, table.column, view, routine or trigger the finding applies to
+	Detail    string
+	Rewritten bool // The object was rewritten for the destination; otherwise it needs attention by hand
+}
+
+// String formats the finding for the migration report
+func (f UpgradeFinding) String() string {
+	return f.Object + ": " + f.Detail
+}
+
+// Upgrader rewrites objects extracted from one major version of an engine for a later one
+// Each rule applies from the major version that introduced the change, so an upgrade across
+// several versions picks up every rule in between. Rewrites are only made where they keep the
+// object's meaning; everything else is reported as an issue.
+type Upgrader struct {
+	engine   string
+	from, to int
+	findings []UpgradeFinding
+	seen     map[string]bool
+}
+
+// NewMySQLUpgrader returns the upgrader between two MySQL major versions, or nil when the
+// upgrade doesn't cross into MySQL 8
+func NewMySQLUpgrader(from, to int) *Upgrader {
+	u := newUpgrader("mysql", from, to)
+	if u == nil || !u.crosses(8) {
+		return nil
+	}
+	return u
+}
+
+// NewPostgresUpgrader returns the upgrader between two PostgreSQL major versions, or nil when
+// the source is older than 9
+func NewPostgresUpgrader(from, to int) *Upgrader {
+	u := newUpgrader("pgsql", from, to)
+	if u == nil || u.from < 9 {
+		return nil
+	}
+	return u
+}
+
+// newUpgrader returns an upgrader for the engine's rules, or nil when the versions are the
+// same or the destination is older
+func newUpgrader(engine string, from, to int) *Upgrader {
+	if from <= 0 || to <= from {
+		return nil
+	}
+	return &Upgrader{engine: engine, from: from, to: to, seen: make(map[string]bool)}
+}
+
+// crosses reports whether the upgrade goes from before the given major version to it or later
+func (u *Upgrader) crosses(version int) bool {
+	return u.from < version && version <= u.to
+}
+
+// String describes the upgrade, e.g. "MySQL 5 -> 8"
+func (u *Upgrader) String() string {
+	name := "PostgreSQL"
+	if u.engine == "mysql" {
+		name = "MySQL"
+	}
+	return fmt.Sprintf("%s %d -> %d", name, u.from, u.to)
+}
+
+// Findings lists everything the upgrader rewrote or found, in the order it was found
+func (u *Upgrader) Findings() []UpgradeFinding {
+	return u.findings
+}
+
+// Issues lists the findings that weren't rewritten and need attention by hand
+func (u *Upgrader) Issues() []UpgradeFinding {
+	var issues []UpgradeFinding
+	for _, f := range u.findings {
+		if !f.Rewritten {
+			issues = append(issues, f)
+		}
+	}
+	return issues
+}
+
+// rewrote records a rewrite, once per object and detail
+func (u *Upgrader) rewrote(object, format string, args ...interface{}) {
+	u.record(UpgradeFinding{Object: object, Detail: fmt.Sprintf(format, args...), Rewritten: true})
+}
+
+// issue records something that needs attention by hand, once per object and detail
+func (u *Upgrader) issue(object, format string, args ...interface{}) {
+	u.record(UpgradeFinding{Object: object, Detail: fmt.Sprintf(format, args...)})
+}
+
+// record adds a finding unless the same one was already recorded
+// Objects are scanned once in pre-flight and again as they're migrated
+func (u *Upgrader) record(f UpgradeFinding) {
+	key := fmt.Sprintf("%t\x00%s\x00%s", f.Rewritten, f.Object, f.Detail)
+	if u.seen[key] {
+		return
+	}
+	u.seen[key] = true
+	u.findings = append(u.findings, f)
+}
+
+// UpgradeTable rewrites a table's CREATE TABLE statement for the destination version
+func (u *Upgrader) UpgradeTable(table TableSchema) TableSchema {
+	if u.engine == "mysql" {
+		return u.upgradeMySQLTable(table)
+	}
+	return u.upgradePostgresTable(table)
+}
+
+// UpgradeView rewrites a view's definition for the destination version
+func (u *Upgrader) UpgradeView(view ViewDef) ViewDef {
+	object := "view " + view.QualifiedName()
+	if u.engine == "mysql" {
+		view.CreateStmt = u.upgradeMySQLBody(object, "", view.CreateStmt)
+	} else {
+		view.CreateStmt = u.upgradePostgresBody(object, view.CreateStmt)
+	}
+	return view
+}
+
+// UpgradeRoutine rewrites a function, procedure or event for the destination version
+func (u *Upgrader) UpgradeRoutine(routine RoutineDef) RoutineDef {
+	object := routine.Kind + " " + routine.Signature()
+	if u.engine == "mysql" {
+		routine.CreateStmt = u.upgradeMySQLBody(object, routine.SQLMode, routine.CreateStmt)
+		routine.SQLMode = u.upgradeMySQLSQLMode(object, routine.SQLMode)
+	} else {
+		routine.CreateStmt = u.upgradePostgresBody(object, routine.CreateStmt)
+	}
+	return routine
+}
+
+// UpgradeTrigger rewrites a trigger for the destination version
+func (u *Upgrader) UpgradeTrigger(trigger TriggerDef) TriggerDef {
+	object := fmt.Sprintf("trigger %s on %s", trigger.Name, qualifiedName(trigger.Schema, trigger.Table))
+	if u.engine == "mysql" {
+		trigger.CreateStmt = u.upgradeMySQLBody(object, trigger.SQLMode, trigger.CreateStmt)
+		trigger.SQLMode = u.upgradeMySQLSQLMode(object, trigger.SQLMode)
+	} else {
+		trigger.CreateStmt = u.upgradePostgresBody(object, trigger.CreateStmt)
+	}
+	return trigger
+}
+
+// significantTokens drops whitespace and comments from tokens
+func significantTokens(tokens []sqlToken) []sqlToken {
+	var significant []sqlToken
+	for _, t := range tokens {
+		if t.Kind != tokSpace && t.Kind != tokComment {
+			significant = append(significant, t)
+		}
+	}
+	return significant
+}
+
+// sortedKeys returns a set's members in alphabetical order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
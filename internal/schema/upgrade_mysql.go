@@ -0,0 +1,346 @@
+package schema
+
+import "strings"
+
+// mysql8ReservedWords are the words MySQL 8.0 made reserved, which 5.7 took as identifiers
+var mysql8ReservedWords = map[string]bool{
+	"ARRAY": true, "CUBE": true, "CUME_DIST": true, "DENSE_RANK": true, "EMPTY": true, "EXCEPT": true,
+	"FIRST_VALUE": true, "FUNCTION": true, "GROUPING": true, "GROUPS": true, "JSON_TABLE": true, "LAG": true,
+	"LAST_VALUE": true, "LATERAL": true, "LEAD": true, "MEMBER": true, "NTH_VALUE": true, "NTILE": true,
+	"OF": true, "OVER": true, "PERCENT_RANK": true, "RANK": true, "RECURSIVE": true, "ROW": true,
+	"ROWS": true, "ROW_NUMBER": true, "SYSTEM": true, "WINDOW": true,
+}
+
+// mysql8RemovedFunctions are the functions MySQL 8.0 removed without a drop-in replacement
+var mysql8RemovedFunctions = map[string]string{
+	"PASSWORD":    "PASSWORD() was removed in MySQL 8.0; hash passwords in the application",
+	"ENCRYPT":     "ENCRYPT() was removed in MySQL 8.0; use SHA2()",
+	"ENCODE":      "ENCODE() was removed in MySQL 8.0; use AES_ENCRYPT()",
+	"DECODE":      "DECODE() was removed in MySQL 8.0; use AES_DECRYPT()",
+	"DES_ENCRYPT": "DES_ENCRYPT() was removed in MySQL 8.0; use AES_ENCRYPT()",
+	"DES_DECRYPT": "DES_DECRYPT() was removed in MySQL 8.0; use AES_DECRYPT()",
+}
+
+// mysql8SpatialFunctions maps the spatial functions MySQL 8.0 removed to their ST_ and MBR
+// equivalents, which take the same arguments
+var mysql8SpatialFunctions = map[string]string{
+	"AREA": "ST_Area", "ASBINARY": "ST_AsBinary", "ASTEXT": "ST_AsText", "ASWKB": "ST_AsBinary",
+	"ASWKT": "ST_AsText", "BUFFER": "ST_Buffer", "CENTROID": "ST_Centroid", "CONTAINS": "MBRContains",
+	"CROSSES": "ST_Crosses", "DIMENSION": "ST_Dimension", "DISJOINT": "MBRDisjoint", "DISTANCE": "ST_Distance",
+	"ENDPOINT": "ST_EndPoint", "ENVELOPE": "ST_Envelope", "EQUALS": "MBREquals", "GEOMETRYTYPE": "ST_GeometryType",
+	"GEOMFROMTEXT": "ST_GeomFromText", "GEOMFROMWKB": "ST_GeomFromWKB", "GLENGTH": "ST_Length",
+	"INTERSECTS": "MBRIntersects", "ISEMPTY": "ST_IsEmpty", "ISSIMPLE": "ST_IsSimple", "NUMPOINTS": "ST_NumPoints",
+	"OVERLAPS": "MBROverlaps", "POINTFROMTEXT": "ST_PointFromText", "POINTN": "ST_PointN", "SRID": "ST_SRID",
+	"STARTPOINT": "ST_StartPoint", "TOUCHES": "ST_Touches", "WITHIN": "MBRWithin", "X": "ST_X", "Y": "ST_Y",
+}
+
+// mysqlIntegerTypes are the types MySQL 8.0.17 deprecated display widths for
+var mysqlIntegerTypes = map[string]bool{
+	"TINYINT": true, "SMALLINT": true, "MEDIUMINT": true, "INT": true, "INTEGER": true, "BIGINT": true,
+}
+
+// upgradeMySQLTable rewrites a MySQL 5.7 table for MySQL 8.0
+// utf8 is spelled utf8mb3 and integer display widths are dropped, except tinyint(1), which
+// applications and drivers read as a boolean, and widths that ZEROFILL pads to
+func (u *Upgrader) upgradeMySQLTable(table TableSchema) TableSchema {
+	ddl, err := parseCreateTable(table.CreateStmt, mysqlSyntax(""))
+	if err != nil {
+		u.issue(table.Name, "couldn't be checked for MySQL 8.0: %s", err)
+		return table
+	}
+	if mysql8ReservedWords[strings.ToUpper(table.Name)] {
+		u.issue(table.Name, "table name is reserved in MySQL 8.0; quote it in application queries")
+	}
+
+	utf8 := false
+	widths := 0
+	reserved := make(map[string]bool)
+	for i, c := range ddl.Clauses {
+		e := &ddlEditor{text: c.Text, tokens: c.tokens}
+		if rewriteUTF8MB3(e) {
+			utf8 = true
+		}
+		if c.Kind != clauseColumn || len(c.tokens) < 2 {
+			ddl.Clauses[i].Text = e.String()
+			continue
+		}
+		if mysql8ReservedWords[strings.ToUpper(c.Name)] {
+			reserved[c.Name] = true
+		}
+		if u.upgradeMySQLColumn(table.Name, c, e) {
+			widths++
+		}
+		ddl.Clauses[i].Text = e.String()
+	}
+	options := &ddlEditor{text: ddl.Suffix, tokens: significantTokens(tokenizeSQL(ddl.Suffix, mysqlSyntax("")))}
+	if rewriteUTF8MB3(options) {
+		utf8 = true
+	}
+	ddl.Suffix = options.String()
+
+	if utf8 {
+		u.rewrote(table.Name, "utf8 is written as utf8mb3, which MySQL 8.0 deprecates; consider converting to utf8mb4")
+	}
+	if widths > 0 {
+		u.rewrote(table.Name, "display widths dropped from %d integer columns, which MySQL 8.0 deprecates", widths)
+	}
+	if len(reserved) > 0 {
+		u.issue(table.Name, "columns %s are reserved in MySQL 8.0; quote them in application queries",
+			strings.Join(sortedKeys(reserved), ", "))
+	}
+
+	table.CreateStmt = ddl.String()
+	return table
+}
+
+// upgradeMySQLColumn checks a column definition, returning whether its display width was dropped
+func (u *Upgrader) upgradeMySQLColumn(table string, c ddlClause, e *ddlEditor) bool {
+	object := table + "." + c.Name
+	tokens := c.tokens
+	typeName := strings.ToUpper(tokens[1].Value)
+
+	zerofill := c.hasKeyword("ZEROFILL")
+	if zerofill {
+		u.issue(object, "ZEROFILL is deprecated in MySQL 8.0; pad values with LPAD() in queries instead")
+	}
+
+	for i := 2; i+1 < len(tokens); i++ {
+		if tokens[i].isKeyword("DEFAULT") && tokens[i+1].Kind == tokString && isZeroDate(c.Text[tokens[i+1].Start:tokens[i+1].End]) {
+			u.issue(object, "zero date default %s is rejected under MySQL 8.0's default NO_ZERO_DATE and strict sql_mode",
+				c.Text[tokens[i+1].Start:tokens[i+1].End])
+		}
+	}
+
+	if !mysqlIntegerTypes[typeName] || zerofill || len(tokens) < 5 || !adjacent(tokens, 1) ||
+		!tokens[2].isPunct(c.Text, '(') || !tokens[4].isPunct(c.Text, ')') {
+		return false
+	}
+	if typeName == "TINYINT" && tokens[3].Value == "1" {
+		return false
+	}
+	e.replace(1, 4, c.Text[tokens[1].Start:tokens[1].End])
+	return true
+}
+
+// isZeroDate reports whether a quoted literal is a date or datetime with a zero year, month or day
+func isZeroDate(literal string) bool {
+	value := strings.Trim(literal, `'"`)
+	if len(value) < 10 || value[4] != '-' || value[7] != '-' {
+		return false
+	}
+	return value[:4] == "0000" || value[5:7] == "00" || value[8:10] == "00"
+}
+
+// rewriteUTF8MB3 spells the utf8 character set and its collations as utf8mb3, after CHARSET,
+// CHARACTER SET, USING and COLLATE and in _utf8 introducers, returning whether anything changed
+func rewriteUTF8MB3(e *ddlEditor) bool {
+	changed := false
+	tokens := e.tokens
+	for i, tok := range tokens {
+		if tok.Kind == tokIdent && strings.EqualFold(tok.Value, "_utf8") && adjacent(tokens, i) && tokens[i+1].Kind == tokString {
+			e.replace(i, i, "_utf8mb3")
+			changed = true
+			continue
+		}
+		value := i + 1
+		switch {
+		case tok.isKeyword("CHARSET"), tok.isKeyword("USING"), tok.isKeyword("COLLATE"):
+		case tok.isKeyword("SET") && i > 0 && tokens[i-1].isKeyword("CHARACTER"):
+		default:
+			continue
+		}
+		if value < len(tokens) && tokens[value].isPunct(e.text, '=') {
+			value++
+		}
+		if value >= len(tokens) || !tokens[value].isIdent() {
+			continue
+		}
+		name := strings.ToLower(tokens[value].Value)
+		switch {
+		case name == "utf8":
+			e.replace(value, value, "utf8mb3")
+			changed = true
+		case strings.HasPrefix(name, "utf8_"):
+			e.replace(value, value, "utf8mb3_"+strings.TrimPrefix(name, "utf8_"))
+			changed = true
+		}
+	}
+	return changed
+}
+
+// upgradeMySQLBody rewrites a MySQL 5.7 view, routine or trigger definition for MySQL 8.0
+func (u *Upgrader) upgradeMySQLBody(object, sqlMode, stmt string) string {
+	tokens := significantTokens(tokenizeMySQL(stmt, sqlMode))
+	e := &ddlEditor{text: stmt, tokens: tokens}
+	isDot := func(i int) bool {
+		return i >= 0 && i < len(tokens) && tokens[i].isPunct(stmt, '.')
+	}
+
+	if rewriteUTF8MB3(e) {
+		u.rewrote(object, "utf8 is written as utf8mb3, which MySQL 8.0 deprecates")
+	}
+
+	var reserved, declared []string
+	for i, tok := range tokens {
+		if tok.Kind != tokIdent {
+			continue
+		}
+		name := strings.ToUpper(tok.Value)
+		call := i+1 < len(tokens) && tokens[i+1].isPunct(stmt, '(') && !isDot(i-1)
+		switch {
+		case call && mysql8RemovedFunctions[name] != "":
+			u.issue(object, "%s", mysql8RemovedFunctions[name])
+		case call && mysql8SpatialFunctions[name] != "":
+			e.replace(i, i, mysql8SpatialFunctions[name])
+			u.rewrote(object, "%s() is written as %s(), as MySQL 8.0 removed the old name", tok.Value, mysql8SpatialFunctions[name])
+		case !mysql8ReservedWords[name]:
+		case isDot(i-1) || isDot(i+1) || (i > 0 && tokens[i-1].isKeyword("AS") && !call):
+			// Qualified names and aliases can only be identifiers, so quoting them is safe
+			e.replace(i, i, quoteMySQLIdent(tok.Value))
+			reserved = append(reserved, tok.Value)
+		case i > 0 && tokens[i-1].isKeyword("DECLARE"):
+			declared = append(declared, tok.Value)
+		}
+	}
+	if len(reserved) > 0 {
+		u.rewrote(object, "quoted %s, reserved in MySQL 8.0", strings.Join(reserved, ", "))
+	}
+	for _, name := range declared {
+		u.issue(object, "variable %s is reserved in MySQL 8.0; rename it", name)
+	}
+
+	if rewriteGroupByOrder(e) {
+		u.rewrote(object, "GROUP BY ... ASC/DESC, removed in MySQL 8.0, is written as ORDER BY")
+	}
+	return e.String()
+}
+
+// groupByClauseEnd are keywords that end a GROUP BY list
+var groupByClauseEnd = []string{"HAVING", "ORDER", "LIMIT", "WITH", "UNION", "INTO", "FOR", "LOCK", "PROCEDURE"}
+
+// selectBlockEnd are keywords that end a SELECT block after its GROUP BY, which an ORDER BY goes before
+var selectBlockEnd = []string{"LIMIT", "UNION", "INTO", "FOR", "LOCK", "PROCEDURE"}
+
+// isAnyKeyword reports whether a token is one of the given keywords
+func isAnyKeyword(t sqlToken, keywords []string) bool {
+	for _, k := range keywords {
+		if t.isKeyword(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteGroupByOrder moves ASC and DESC out of GROUP BY lists, which MySQL 8.0 rejects
+// MySQL 5.7 sorted by the grouping, so an ORDER BY with the same list is added unless the block
+// already has one, which took precedence
+func rewriteGroupByOrder(e *ddlEditor) bool {
+	text, tokens := e.text, e.tokens
+	changed := false
+	for i := 0; i+1 < len(tokens); i++ {
+		if !tokens[i].isKeyword("GROUP") || !tokens[i+1].isKeyword("BY") {
+			continue
+		}
+
+		// Split the grouping list into its items
+		type item struct{ start, end, direction int }
+		var items []item
+		start, depth, j := i+2, 0, i+2
+	list:
+		for ; j < len(tokens); j++ {
+			t := tokens[j]
+			switch {
+			case t.isPunct(text, '('):
+				depth++
+			case t.isPunct(text, ')'):
+				if depth == 0 {
+					break list
+				}
+				depth--
+			case depth > 0:
+			case t.isPunct(text, ','):
+				items = append(items, item{start, j - 1, -1})
+				start = j + 1
+			case t.isPunct(text, ';'), isAnyKeyword(t, groupByClauseEnd):
+				break list
+			}
+		}
+		items = append(items, item{start, j - 1, -1})
+
+		directed := false
+		for n, it := range items {
+			if it.end > it.start && (tokens[it.end].isKeyword("ASC") || tokens[it.end].isKeyword("DESC")) {
+				items[n].direction = it.end
+				items[n].end = it.end - 1
+				directed = true
+			}
+		}
+		if !directed {
+			continue
+		}
+		for _, it := range items {
+			if it.direction >= 0 {
+				e.remove(it.direction, it.direction)
+			}
+		}
+		changed = true
+
+		// Find the block's ORDER BY, or where one would go
+		ordered := false
+		end := j
+		depth = 0
+	block:
+		for ; end < len(tokens); end++ {
+			t := tokens[end]
+			switch {
+			case t.isPunct(text, '('):
+				depth++
+			case t.isPunct(text, ')'):
+				if depth == 0 {
+					break block
+				}
+				depth--
+			case depth > 0:
+			case t.isKeyword("ORDER"):
+				ordered = true
+				break block
+			case t.isPunct(text, ';'), isAnyKeyword(t, selectBlockEnd):
+				break block
+			}
+		}
+		if ordered {
+			continue
+		}
+		var order []string
+		for _, it := range items {
+			column := text[tokens[it.start].Start:tokens[it.end].End]
+			if it.direction >= 0 {
+				column += " " + text[tokens[it.direction].Start:tokens[it.direction].End]
+			}
+			order = append(order, column)
+		}
+		at := tokens[end-1].End
+		e.edits = append(e.edits, ddlEdit{at, at, " ORDER BY " + strings.Join(order, ", ")})
+	}
+	return changed
+}
+
+// upgradeMySQLSQLMode drops the sql_mode flags MySQL 8.0 removed, which SET sql_mode rejects
+func (u *Upgrader) upgradeMySQLSQLMode(object, sqlMode string) string {
+	if sqlMode == "" {
+		return sqlMode
+	}
+	var kept, dropped []string
+	for _, mode := range strings.Split(sqlMode, ",") {
+		mode = strings.ToUpper(strings.TrimSpace(mode))
+		if mysqlSQLModes[mode] {
+			kept = append(kept, mode)
+		} else {
+			dropped = append(dropped, mode)
+		}
+	}
+	if len(dropped) > 0 {
+		u.rewrote(object, "sql_mode %s, removed in MySQL 8.0, is dropped", strings.Join(dropped, ","))
+	}
+	return strings.Join(kept, ",")
+}
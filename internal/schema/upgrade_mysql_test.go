@@ -0,0 +1,155 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewUpgrader(t *testing.T) {
+	tests := []struct {
+		name     string
+		new      func(from, to int) *Upgrader
+		from, to int
+		want     bool
+	}{
+		{"mysql", NewMySQLUpgrader, 5, 8, true},
+		{"mysql", NewMySQLUpgrader, 8, 8, false},
+		{"mysql", NewMySQLUpgrader, 8, 9, false},
+		{"mysql", NewMySQLUpgrader, 8, 5, false},
+		{"mysql", NewMySQLUpgrader, 0, 8, false},
+		{"pgsql", NewPostgresUpgrader, 11, 16, true},
+		{"pgsql", NewPostgresUpgrader, 16, 14, false},
+		{"pgsql", NewPostgresUpgrader, 8, 16, false},
+	}
+	for _, tt := range tests {
+		if got := tt.new(tt.from, tt.to) != nil; got != tt.want {
+			t.Errorf("%s upgrader %d -> %d != nil = %v, want %v", tt.name, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestUpgrader_MySQLTable(t *testing.T) {
+	table := TableSchema{
+		Name: "orders",
+		CreateStmt: "CREATE TABLE `orders` (\n" +
+			"  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,\n" +
+			"  `active` tinyint(1) NOT NULL DEFAULT '1',\n" +
+			"  `code` int(6) unsigned zerofill DEFAULT NULL,\n" +
+			"  `rank` bigint(20) DEFAULT NULL,\n" +
+			"  `note` varchar(50) CHARACTER SET utf8 COLLATE utf8_unicode_ci DEFAULT NULL,\n" +
+			"  `shipped_at` datetime NOT NULL DEFAULT '0000-00-00 00:00:00',\n" +
+			"  PRIMARY KEY (`id`)\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_general_ci",
+	}
+
+	u := NewMySQLUpgrader(5, 8)
+	got := u.UpgradeTable(table).CreateStmt
+
+	want := "CREATE TABLE `orders` (\n" +
+		"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `active` tinyint(1) NOT NULL DEFAULT '1',\n" +
+		"  `code` int(6) unsigned zerofill DEFAULT NULL,\n" +
+		"  `rank` bigint DEFAULT NULL,\n" +
+		"  `note` varchar(50) CHARACTER SET utf8mb3 COLLATE utf8mb3_unicode_ci DEFAULT NULL,\n" +
+		"  `shipped_at` datetime NOT NULL DEFAULT '0000-00-00 00:00:00',\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8mb3_general_ci"
+	if got != want {
+		t.Errorf("UpgradeTable() =\n%s\nwant\n%s", got, want)
+	}
+
+	var issues []string
+	for _, f := range u.Issues() {
+		issues = append(issues, f.String())
+	}
+	for _, want := range []string{"orders.code: ZEROFILL", "orders.shipped_at: zero date default", "orders: columns rank are reserved"} {
+		if !strings.Contains(strings.Join(issues, "\n"), want) {
+			t.Errorf("Issues() = %q, want one containing %q", issues, want)
+		}
+	}
+	if len(u.Findings()) != len(issues)+2 {
+		t.Errorf("Findings() = %v, want utf8mb3 and display width rewrites besides the issues", u.Findings())
+	}
+
+	// Scanning the same table again, as the migration does after pre-flight, adds nothing
+	u.UpgradeTable(table)
+	if len(u.Issues()) != len(issues) {
+		t.Errorf("Issues() after a second scan = %d, want %d", len(u.Issues()), len(issues))
+	}
+}
+
+func TestUpgrader_MySQLBody(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		want string
+	}{
+		{
+			name: "group by direction becomes order by",
+			stmt: "CREATE VIEW `v` AS select `t`.`a` AS `a`,count(0) AS `n` from `t` group by `t`.`a` desc",
+			want: "CREATE VIEW `v` AS select `t`.`a` AS `a`,count(0) AS `n` from `t` group by `t`.`a` ORDER BY `t`.`a` desc",
+		},
+		{
+			name: "order by goes before limit",
+			stmt: "select a, b from t group by a asc, b having count(*) > 1 limit 10",
+			want: "select a, b from t group by a, b having count(*) > 1 ORDER BY a asc, b limit 10",
+		},
+		{
+			name: "existing order by wins",
+			stmt: "select a from t group by a desc order by a",
+			want: "select a from t group by a order by a",
+		},
+		{
+			name: "subquery",
+			stmt: "select * from (select a from t group by a desc) x",
+			want: "select * from (select a from t group by a ORDER BY a desc) x",
+		},
+		{
+			name: "spatial functions and utf8",
+			stmt: "select AsText(g), X(p), CONVERT(n USING utf8) from t",
+			want: "select ST_AsText(g), ST_X(p), CONVERT(n USING utf8mb3) from t",
+		},
+		{
+			name: "reserved words in name positions",
+			stmt: "select t.rank, x AS row_number from t",
+			want: "select t.`rank`, x AS `row_number` from t",
+		},
+		{
+			name: "keywords left alone",
+			stmt: "CREATE TRIGGER tr BEFORE INSERT ON t FOR EACH ROW SET NEW.n = 'group by a desc'",
+			want: "CREATE TRIGGER tr BEFORE INSERT ON t FOR EACH ROW SET NEW.n = 'group by a desc'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewMySQLUpgrader(5, 8)
+			if got := u.upgradeMySQLBody("view v", "", tt.stmt); got != tt.want {
+				t.Errorf("upgradeMySQLBody() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if len(u.Issues()) != 0 {
+				t.Errorf("Issues() = %v, want none", u.Issues())
+			}
+		})
+	}
+}
+
+func TestUpgrader_MySQLRoutine(t *testing.T) {
+	u := NewMySQLUpgrader(5, 8)
+	routine := u.UpgradeRoutine(RoutineDef{
+		Name:       "hash",
+		Kind:       "function",
+		SQLMode:    "ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_AUTO_CREATE_USER,NO_ENGINE_SUBSTITUTION",
+		CreateStmt: "CREATE FUNCTION `hash`(s TEXT) RETURNS text BEGIN DECLARE rank INT; RETURN PASSWORD(s); END",
+	})
+
+	if want := "ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ENGINE_SUBSTITUTION"; routine.SQLMode != want {
+		t.Errorf("SQLMode = %q, want %q", routine.SQLMode, want)
+	}
+	issues := u.Issues()
+	if len(issues) != 2 {
+		t.Fatalf("Issues() = %v, want the PASSWORD() call and the rank variable", issues)
+	}
+	if !strings.Contains(issues[0].Detail, "PASSWORD()") || !strings.Contains(issues[1].Detail, "variable rank") {
+		t.Errorf("Issues() = %v", issues)
+	}
+}
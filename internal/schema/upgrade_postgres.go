@@ -0,0 +1,145 @@
+package schema
+
+import "strings"
+
+// postgresRename is a function PostgreSQL renamed in a major version without changing its arguments
+type postgresRename struct {
+	since    int
+	from, to string
+}
+
+// postgresRenamedFunctions are rewritten wherever they're called
+var postgresRenamedFunctions = []postgresRename{
+	{10, "pg_current_xlog_location", "pg_current_wal_lsn"},
+	{10, "pg_current_xlog_insert_location", "pg_current_wal_insert_lsn"},
+	{10, "pg_current_xlog_flush_location", "pg_current_wal_flush_lsn"},
+	{10, "pg_last_xlog_receive_location", "pg_last_wal_receive_lsn"},
+	{10, "pg_last_xlog_replay_location", "pg_last_wal_replay_lsn"},
+	{10, "pg_xlog_location_diff", "pg_wal_lsn_diff"},
+	{10, "pg_xlogfile_name", "pg_walfile_name"},
+	{10, "pg_xlogfile_name_offset", "pg_walfile_name_offset"},
+	{10, "pg_switch_xlog", "pg_switch_wal"},
+	{10, "pg_is_xlog_replay_paused", "pg_is_wal_replay_paused"},
+	{10, "pg_xlog_replay_pause", "pg_wal_replay_pause"},
+	{10, "pg_xlog_replay_resume", "pg_wal_replay_resume"},
+	{14, "numeric_fac", "factorial"},
+}
+
+// postgresRemoval is a function, type or catalog column PostgreSQL removed in a major version
+type postgresRemoval struct {
+	since  int
+	name   string
+	detail string
+}
+
+// postgresRemovedNames are reported wherever they appear as an identifier
+var postgresRemovedNames = []postgresRemoval{
+	{10, "sent_location", "pg_stat_replication.sent_location was renamed sent_lsn in PostgreSQL 10"},
+	{10, "write_location", "pg_stat_replication.write_location was renamed write_lsn in PostgreSQL 10"},
+	{10, "flush_location", "pg_stat_replication.flush_location was renamed flush_lsn in PostgreSQL 10"},
+	{10, "replay_location", "pg_stat_replication.replay_location was renamed replay_lsn in PostgreSQL 10"},
+	{11, "relhaspkey", "pg_class.relhaspkey was removed in PostgreSQL 11"},
+	{12, "adsrc", "pg_attrdef.adsrc was removed in PostgreSQL 12; use pg_get_expr(adbin, adrelid)"},
+	{12, "consrc", "pg_constraint.consrc was removed in PostgreSQL 12; use pg_get_constraintdef(oid)"},
+	{12, "relhasoids", "pg_class.relhasoids was removed in PostgreSQL 12 along with WITH OIDS"},
+	{12, "abstime", "the abstime type was removed in PostgreSQL 12; use timestamptz"},
+	{12, "reltime", "the reltime type was removed in PostgreSQL 12; use interval"},
+	{12, "tinterval", "the tinterval type was removed in PostgreSQL 12; use a tstzrange"},
+	{15, "pg_start_backup", "pg_start_backup() was replaced by pg_backup_start() in PostgreSQL 15, without exclusive backups"},
+	{15, "pg_stop_backup", "pg_stop_backup() was replaced by pg_backup_stop() in PostgreSQL 15, without exclusive backups"},
+	{15, "pg_is_in_backup", "pg_is_in_backup() was removed in PostgreSQL 15 along with exclusive backups"},
+	{15, "pg_backup_start_time", "pg_backup_start_time() was removed in PostgreSQL 15 along with exclusive backups"},
+	{17, "checkpoints_timed", "pg_stat_bgwriter.checkpoints_timed moved to pg_stat_checkpointer.num_timed in PostgreSQL 17"},
+	{17, "checkpoints_req", "pg_stat_bgwriter.checkpoints_req moved to pg_stat_checkpointer.num_requested in PostgreSQL 17"},
+	{17, "buffers_checkpoint", "pg_stat_bgwriter.buffers_checkpoint moved to pg_stat_checkpointer.buffers_written in PostgreSQL 17"},
+	{17, "buffers_backend", "pg_stat_bgwriter.buffers_backend was removed in PostgreSQL 17; use pg_stat_io"},
+}
+
+// postgresRemovedTypes maps column types PostgreSQL removed to the types their values are
+// copied into, which accept the old types' text output
+var postgresRemovedTypes = []postgresRename{
+	{12, "abstime", "timestamp with time zone"},
+	{12, "reltime", "interval"},
+}
+
+// upgradePostgresTable rewrites columns of types the destination version no longer has
+func (u *Upgrader) upgradePostgresTable(table TableSchema) TableSchema {
+	for _, r := range postgresRemovedTypes {
+		if !u.crosses(r.since) {
+			continue
+		}
+		for i, col := range table.Columns {
+			if strings.TrimSuffix(col.DataType, "[]") == r.from {
+				table.Columns[i].DataType = r.to + strings.TrimPrefix(col.DataType, r.from)
+			}
+		}
+	}
+
+	ddl, err := parseCreateTable(table.CreateStmt, postgresSyntax)
+	if err != nil {
+		u.issue(table.QualifiedName(), "couldn't be checked for PostgreSQL %d: %s", u.to, err)
+		return table
+	}
+	for i, c := range ddl.Clauses {
+		if c.Kind != clauseColumn || len(c.tokens) < 2 {
+			continue
+		}
+		object := table.QualifiedName() + "." + c.Name
+		e := &ddlEditor{text: c.Text, tokens: c.tokens}
+		typeName := c.tokens[1]
+		replaced := false
+		for _, r := range postgresRemovedTypes {
+			if u.crosses(r.since) && typeName.isKeyword(r.from) {
+				e.replace(1, 1, r.to)
+				u.rewrote(object, "%s, removed in PostgreSQL %d, becomes %s", r.from, r.since, r.to)
+				replaced = true
+			}
+		}
+		if !replaced && typeName.isKeyword("tinterval") && u.crosses(12) {
+			u.issue(object, "the tinterval type was removed in PostgreSQL 12; convert the column to a tstzrange before migrating")
+		}
+		ddl.Clauses[i].Text = e.String()
+	}
+	table.CreateStmt = ddl.String()
+	return table
+}
+
+// upgradePostgresBody rewrites renamed functions in a view, routine or trigger definition and
+// reports removed functions, types and catalog columns
+// Function bodies are dollar-quoted strings, so their contents are scanned too
+func (u *Upgrader) upgradePostgresBody(object, stmt string) string {
+	significant := significantTokens(tokenizeSQL(stmt, postgresSyntax))
+	var b strings.Builder
+
+	last := 0
+	for i, t := range significant {
+		text := stmt[t.Start:t.End]
+		switch {
+		case t.Kind == tokString && strings.HasPrefix(text, "$"):
+			tag := dollarTagRe.FindString(text)
+			if len(text) < 2*len(tag) || !strings.HasSuffix(text, tag) {
+				continue
+			}
+			b.WriteString(stmt[last:t.Start])
+			b.WriteString(tag + u.upgradePostgresBody(object, text[len(tag):len(text)-len(tag)]) + tag)
+			last = t.End
+		case t.Kind == tokIdent:
+			call := i+1 < len(significant) && significant[i+1].isPunct(stmt, '(')
+			for _, r := range postgresRenamedFunctions {
+				if call && u.crosses(r.since) && t.isKeyword(r.from) {
+					b.WriteString(stmt[last:t.Start])
+					b.WriteString(r.to)
+					last = t.End
+					u.rewrote(object, "%s() is written as %s(), as PostgreSQL %d renamed it", r.from, r.to, r.since)
+				}
+			}
+			for _, r := range postgresRemovedNames {
+				if u.crosses(r.since) && t.isKeyword(r.name) {
+					u.issue(object, "%s", r.detail)
+				}
+			}
+		}
+	}
+	b.WriteString(stmt[last:])
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpgrader_PostgresTable(t *testing.T) {
+	table := TableSchema{
+		Schema: "public",
+		Name:   "audit",
+		CreateStmt: "CREATE TABLE public.audit (\n" +
+			"    id integer NOT NULL,\n" +
+			"    logged abstime,\n" +
+			"    took reltime[],\n" +
+			"    span tinterval\n" +
+			");",
+		Columns: []ColumnDef{{Name: "id", DataType: "integer"}, {Name: "logged", DataType: "abstime"}, {Name: "took", DataType: "reltime[]"}},
+	}
+
+	u := NewPostgresUpgrader(11, 16)
+	got := u.UpgradeTable(table)
+
+	want := "CREATE TABLE public.audit (\n" +
+		"    id integer NOT NULL,\n" +
+		"    logged timestamp with time zone,\n" +
+		"    took interval[],\n" +
+		"    span tinterval\n" +
+		");"
+	if got.CreateStmt != want {
+		t.Errorf("UpgradeTable() =\n%s\nwant\n%s", got.CreateStmt, want)
+	}
+	if got.Columns[1].DataType != "timestamp with time zone" || got.Columns[2].DataType != "interval[]" {
+		t.Errorf("Columns = %+v", got.Columns)
+	}
+	if issues := u.Issues(); len(issues) != 1 || issues[0].Object != "public.audit.span" {
+		t.Errorf("Issues() = %v, want the tinterval column", issues)
+	}
+
+	// Nothing applies between versions that didn't remove the types
+	if got := NewPostgresUpgrader(12, 16).UpgradeTable(table); got.CreateStmt != table.CreateStmt {
+		t.Errorf("UpgradeTable() from 12 changed the table:\n%s", got.CreateStmt)
+	}
+}
+
+func TestUpgrader_PostgresRoutine(t *testing.T) {
+	routine := RoutineDef{
+		Schema:    "public",
+		Name:      "lag_bytes",
+		Kind:      "function",
+		Arguments: "",
+		CreateStmt: "CREATE OR REPLACE FUNCTION public.lag_bytes()\n" +
+			" RETURNS numeric\n LANGUAGE sql\nAS $function$\n" +
+			"  SELECT pg_xlog_location_diff(pg_current_xlog_location(), replay_location) FROM pg_stat_replication\n" +
+			"$function$\n",
+	}
+
+	u := NewPostgresUpgrader(9, 16)
+	got := u.UpgradeRoutine(routine).CreateStmt
+	if !strings.Contains(got, "SELECT pg_wal_lsn_diff(pg_current_wal_lsn(), replay_location)") {
+		t.Errorf("UpgradeRoutine() =\n%s", got)
+	}
+	if !strings.HasPrefix(got, "CREATE OR REPLACE FUNCTION public.lag_bytes()") || !strings.HasSuffix(got, "$function$\n") {
+		t.Errorf("UpgradeRoutine() changed the statement around the body:\n%s", got)
+	}
+	issues := u.Issues()
+	if len(issues) != 1 || !strings.Contains(issues[0].Detail, "replay_lsn") {
+		t.Errorf("Issues() = %v, want the renamed replay_location column", issues)
+	}
+}